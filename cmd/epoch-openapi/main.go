@@ -0,0 +1,232 @@
+// Command epoch-openapi writes the OpenAPI spec of every version of an Epoch API to a directory.
+// It builds and runs a small program calling a function of your package that returns the Epoch
+// with its handlers wrapped, so the specs always match the code:
+//
+//	epoch-openapi -pkg ./internal/api -func NewEpoch -base docs/swagger.json -out docs/versions
+//
+// The function must be a func() (*epoch.Epoch, error) or func() *epoch.Epoch in a non-main
+// package. With -watch, specs are regenerated whenever a .go file or the base spec changes.
+// Otherwise the exit status is 1 when generation or validation fails, for use in CI.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+type options struct {
+	pkg, fn, base, out, format, filename string
+	watch                                bool
+	watchDir                             string
+	interval                             time.Duration
+}
+
+func main() {
+	var opts options
+	flag.StringVar(&opts.pkg, "pkg", "", "package with the function returning the Epoch, as import path or directory")
+	flag.StringVar(&opts.fn, "func", "Epoch", "function returning the Epoch with its handlers wrapped")
+	flag.StringVar(&opts.base, "base", "", "HEAD spec to version, e.g. swag output; schemas are generated from Go types without one")
+	flag.StringVar(&opts.out, "out", "docs/openapi", "directory the versioned specs are written to")
+	flag.StringVar(&opts.format, "format", "yaml", "spec format: yaml or json")
+	flag.StringVar(&opts.filename, "filename", "", "file name pattern with %s for the version (default \"%s.<format>\")")
+	flag.BoolVar(&opts.watch, "watch", false, "regenerate when a .go file or the base spec changes")
+	flag.StringVar(&opts.watchDir, "watch-dir", ".", "directory watched for changes with -watch")
+	flag.DurationVar(&opts.interval, "interval", time.Second, "how often -watch checks for changes")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: epoch-openapi -pkg package [flags]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if opts.pkg == "" || flag.NArg() != 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	os.Exit(run(opts))
+}
+
+// run generates the specs once, or on every change with -watch, and returns the exit status
+func run(opts options) int {
+	importPath, err := resolvePackage(opts.pkg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	dir, err := os.MkdirTemp("", "epoch-openapi")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer os.RemoveAll(dir)
+	program := filepath.Join(dir, "main.go")
+	if err := writeProgram(program, importPath, opts); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if !opts.watch {
+		if err := generate(program); err != nil {
+			return 1
+		}
+		return 0
+	}
+
+	var last uint64
+	for {
+		current, err := fingerprint(opts.watchDir, opts.base)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if current != last {
+			last = current
+			if err := generate(program); err == nil {
+				fmt.Fprintln(os.Stderr, "epoch-openapi: specs are up to date, watching for changes")
+			} else {
+				fmt.Fprintln(os.Stderr, "epoch-openapi: generation failed, watching for changes")
+			}
+		}
+		time.Sleep(opts.interval)
+	}
+}
+
+// resolvePackage returns the import path of a package named by import path or directory
+func resolvePackage(pkg string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("go", "list", "-f", "{{.ImportPath}} {{.Name}}", pkg)
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to find package %s: %s", pkg, strings.TrimSpace(stderr.String()))
+	}
+	fields := strings.Fields(string(output))
+	if len(fields) != 2 {
+		return "", fmt.Errorf("failed to find package %s", pkg)
+	}
+	if fields[1] == "main" {
+		return "", fmt.Errorf("package %s is a main package, which can't be imported: move the function returning the Epoch to another package", pkg)
+	}
+	return fields[0], nil
+}
+
+var programTemplate = template.Must(template.New("main").Parse(`// Code generated by epoch-openapi. DO NOT EDIT.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/astronomer/epoch/epoch"
+	"github.com/astronomer/epoch/epoch/openapi"
+	api {{printf "%q" .Package}}
+)
+
+func main() {
+	instance, err := load(api.{{.Func}})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	paths, err := openapi.GenerateSpecFiles(instance, openapi.SpecFilesConfig{
+		BaseSpec:  {{printf "%q" .BaseSpec}},
+		OutputDir: {{printf "%q" .OutputDir}},
+		Format:    {{printf "%q" .Format}},
+		Filename:  {{printf "%q" .Filename}},
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	for _, path := range paths {
+		fmt.Println(path)
+	}
+}
+
+func load(fn interface{}) (*epoch.Epoch, error) {
+	switch fn := fn.(type) {
+	case func() (*epoch.Epoch, error):
+		return fn()
+	case func() *epoch.Epoch:
+		return fn(), nil
+	}
+	return nil, fmt.Errorf("%s.{{.Func}} must be a func() (*epoch.Epoch, error) or func() *epoch.Epoch", {{printf "%q" .Package}})
+}
+`))
+
+// writeProgram writes the program generating the specs of the package's Epoch
+func writeProgram(path, importPath string, opts options) error {
+	var source bytes.Buffer
+	err := programTemplate.Execute(&source, map[string]string{
+		"Package":   importPath,
+		"Func":      opts.fn,
+		"BaseSpec":  opts.base,
+		"OutputDir": opts.out,
+		"Format":    opts.format,
+		"Filename":  opts.filename,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write generator program: %w", err)
+	}
+	return os.WriteFile(path, source.Bytes(), 0644)
+}
+
+// generate runs the generator program in the current module, which prints the specs it wrote
+func generate(program string) error {
+	cmd := exec.Command("go", "run", program)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// fingerprint hashes the names, sizes and modification times of the .go files under dir and
+// of the base spec, so any change to them changes it
+func fingerprint(dir, base string) (uint64, error) {
+	hash := fnv.New64a()
+	add := func(path string, info fs.FileInfo) {
+		fmt.Fprintf(hash, "%s %d %d\n", path, info.Size(), info.ModTime().UnixNano())
+	}
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := entry.Name()
+		if entry.IsDir() {
+			if path != dir && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "vendor" || name == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(name, ".go") {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		add(path, info)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to watch %s: %w", dir, err)
+	}
+	if base != "" {
+		info, err := os.Stat(base)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return 0, fmt.Errorf("failed to watch %s: %w", base, err)
+		}
+		if err == nil {
+			add(base, info)
+		}
+	}
+	return hash.Sum64(), nil
+}
@@ -0,0 +1,52 @@
+// Command epoch-spec-diff compares two OpenAPI specs, e.g. the last published spec and the
+// current swag output, and prints skeleton VersionChangeBuilder code for the schemas that differ.
+//
+//	epoch-spec-diff -from v1 -to v2 published.yaml docs/swagger.json
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/astronomer/epoch/epoch/openapi"
+)
+
+func main() {
+	from := flag.String("from", "fromVersion", "Go expression for the older version")
+	to := flag.String("to", "toVersion", "Go expression for the newer version")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: epoch-spec-diff [-from expr] [-to expr] older-spec newer-spec")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	older, err := openapi.LoadSpecFile(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	newer, err := openapi.LoadSpecFile(flag.Arg(1))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	diff := openapi.DiffSpecs(older, newer)
+	if len(diff.AddedSchemas) > 0 {
+		fmt.Printf("// New schemas, which need no migration: %s\n", strings.Join(diff.AddedSchemas, ", "))
+	}
+	if len(diff.RemovedSchemas) > 0 {
+		fmt.Printf("// TODO: removed schemas: %s\n", strings.Join(diff.RemovedSchemas, ", "))
+	}
+	if diff.IsEmpty() {
+		fmt.Println("// No schema both specs have changed")
+		return
+	}
+	fmt.Print(diff.Code(*from, *to))
+}
@@ -0,0 +1,93 @@
+package epoch
+
+import (
+	"fmt"
+	"sync"
+)
+
+// AsOf returns a view of the Epoch as it was when version was the newest: the versions after it
+// and the changes leading to them are gone, so head means version and requests for version
+// aren't migrated at all. Use it in tests to check handlers as they behaved historically, or to
+// reproduce a bug an old client reports against the handler code of its time:
+//
+//	view, _ := epochInstance.AsOf(v2)
+//	router.Use(view.Middleware())
+//	router.GET("/users/:id", view.WrapHandler(legacyGetUser).Returns(User{}).ToHandlerFunc("GET", "/users/:id"))
+//
+// The view has its own endpoint registry, retirement state and migration stats, and no response
+// cache. Changes registered on it at runtime don't reach the Epoch, nor the other way around.
+func (c *Epoch) AsOf(version *Version) (*Epoch, error) {
+	if version == nil {
+		return nil, fmt.Errorf("cannot view a nil version")
+	}
+	asOf := c.findVersion(version)
+	if asOf == nil {
+		return nil, fmt.Errorf("cannot view %w '%s'", ErrUnknownVersion, version.String())
+	}
+	if asOf.IsHead {
+		return c, nil
+	}
+
+	var changes []*VersionChange
+	for _, change := range c.migrationChain.GetChanges() {
+		if !change.ToVersion().IsHead && !change.ToVersion().IsNewerThan(asOf) {
+			changes = append(changes, change)
+		}
+	}
+	migrationChain, err := NewMigrationChain(changes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migration chain as of '%s': %w", asOf.String(), err)
+	}
+
+	// Versions carry their changes for schema generation, so the view gets its own copies
+	var versions []*Version
+	c.versionBundle.changesMu.RLock() // Copying a version reads its changes
+	for _, v := range c.versionBundle.GetVersions() {
+		if v.IsHead {
+			versions = append(versions, NewHeadVersion()) // Listed like WithHeadVersion() does
+			continue
+		}
+		if v.IsNewerThan(asOf) {
+			continue
+		}
+		kept := *v
+		kept.Changes = nil
+		versions = append(versions, &kept)
+	}
+	c.versionBundle.changesMu.RUnlock()
+	versionBundle, err := NewVersionBundle(versions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create version bundle as of '%s': %w", asOf.String(), err)
+	}
+	// Attached after the bundle is validated, like Build() does
+	for _, change := range changes {
+		versionBundle.attachChange(change)
+	}
+
+	view := *c
+	view.versionBundle = versionBundle
+	view.migrationChain = migrationChain
+	view.endpointRegistry = NewEndpointRegistry()
+	view.responseCache = nil
+	view.mu = &sync.Mutex{}
+	view.versionKey = newInstanceVersionKey()
+	if def := c.versionConfig.DefaultVersion; def != nil && def.IsNewerThan(asOf) {
+		view.versionConfig.DefaultVersion = nil
+	}
+	view.retired = newRetiredVersions(c.clock)
+	for _, stats := range c.RetiredVersions() {
+		if versionBundle.IsVersionDefined(stats.Version) {
+			view.retired.stats[stats.Version] = &RetiredVersionStats{Version: stats.Version, RetiredAt: stats.RetiredAt}
+		}
+	}
+	if c.stats != nil {
+		view.stats = newMigrationStats()
+	}
+	view.unreleased = nil
+	for _, u := range c.unreleased {
+		if !u.version.IsNewerThan(asOf) {
+			view.unreleased = append(view.unreleased, u)
+		}
+	}
+	return &view, nil
+}
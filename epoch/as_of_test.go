@@ -0,0 +1,84 @@
+package epoch
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type asOfUser struct {
+	ID          int    `json:"id"`
+	DisplayName string `json:"display_name"`
+}
+
+var _ = Describe("AsOf", func() {
+	var (
+		v1, v2, v3    *Version
+		epochInstance *Epoch
+	)
+
+	serve := func(instance *Epoch, body, version string) string {
+		gin.SetMode(gin.TestMode)
+		router := setupRouterWithMiddleware(instance)
+		router.GET("/users/1", instance.WrapHandler(func(c *gin.Context) {
+			c.Data(http.StatusOK, "application/json", []byte(body))
+		}).Returns(asOfUser{}).ToHandlerFunc("GET", "/users/:id"))
+
+		recorder := serveVersioned(router, httptest.NewRequest("GET", "/users/1", nil), version)
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		return recorder.Body.String()
+	}
+
+	BeforeEach(func() {
+		v1, v2, v3 = newTestVersions()
+		changes := []*VersionChange{
+			NewVersionChangeBuilder(v1, v2).
+				ForType(asOfUser{}).
+				ResponseToPreviousVersion().
+				RenameField("full_name", "name").
+				Build(),
+			NewVersionChangeBuilder(v2, v3).
+				ForType(asOfUser{}).
+				ResponseToPreviousVersion().
+				RenameField("display_name", "full_name").
+				Build(),
+		}
+		var err error
+		epochInstance, err = setupHeadEpoch([]*Version{v1, v2, v3}, changes)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should treat the version as head and ignore later changes", func() {
+		view, err := epochInstance.AsOf(v2)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(view.VersionBundle().GetVersionValues()).To(Equal([]string{"2024-01-01", "2024-06-01", "head"}))
+		Expect(view.migrationChain.GetChanges()).To(HaveLen(1))
+
+		const legacyBody = `{"id":1,"full_name":"Ada"}`
+		Expect(serve(view, legacyBody, "2024-06-01")).To(MatchJSON(legacyBody))
+		Expect(serve(view, legacyBody, "head")).To(MatchJSON(legacyBody))
+		Expect(serve(view, legacyBody, "2024-01-01")).To(MatchJSON(`{"id":1,"name":"Ada"}`))
+	})
+
+	It("should leave the Epoch as it is", func() {
+		_, err := epochInstance.AsOf(v1)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(epochInstance.VersionBundle().GetVersionValues()).To(Equal([]string{"2024-01-01", "2024-06-01", "2025-01-01", "head"}))
+		Expect(v2.Changes).To(HaveLen(1))
+		Expect(serve(epochInstance, `{"id":1,"display_name":"Ada"}`, "2024-06-01")).To(MatchJSON(`{"id":1,"full_name":"Ada"}`))
+	})
+
+	It("should reject unknown versions and return the Epoch for head", func() {
+		v4, _ := NewDateVersion("2026-01-01")
+		_, err := epochInstance.AsOf(v4)
+		Expect(err).To(MatchError(ErrUnknownVersion))
+
+		view, err := epochInstance.AsOf(NewHeadVersion())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(view).To(BeIdenticalTo(epochInstance))
+	})
+})
@@ -2,15 +2,18 @@ package epoch
 
 import (
 	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 
-	"github.com/bytedance/sonic/ast"
+	"github.com/astronomer/epoch/epoch/jsonast"
 )
 
 // Helper functions for working with individual AST nodes in transformers
 // These are useful inside TransformArrayField callbacks and for direct node manipulation
 
 // SetNodeField sets a field on an AST node
-func SetNodeField(node *ast.Node, key string, value interface{}) error {
+func SetNodeField(node *jsonast.Node, key string, value interface{}) error {
 	if node == nil {
 		return errors.New("node is nil")
 	}
@@ -19,7 +22,7 @@ func SetNodeField(node *ast.Node, key string, value interface{}) error {
 }
 
 // DeleteNodeField deletes a field from an AST node
-func DeleteNodeField(node *ast.Node, key string) error {
+func DeleteNodeField(node *jsonast.Node, key string) error {
 	if node == nil {
 		return nil
 	}
@@ -28,7 +31,7 @@ func DeleteNodeField(node *ast.Node, key string) error {
 }
 
 // GetNodeField gets a field from an AST node
-func GetNodeField(node *ast.Node, key string) *ast.Node {
+func GetNodeField(node *jsonast.Node, key string) *jsonast.Node {
 	if node == nil {
 		return nil
 	}
@@ -36,7 +39,7 @@ func GetNodeField(node *ast.Node, key string) *ast.Node {
 }
 
 // GetNodeFieldString gets a field value as a string from an AST node
-func GetNodeFieldString(node *ast.Node, key string) (string, error) {
+func GetNodeFieldString(node *jsonast.Node, key string) (string, error) {
 	field := GetNodeField(node, key)
 	if field == nil || !field.Exists() {
 		return "", errors.New("field not found")
@@ -45,7 +48,7 @@ func GetNodeFieldString(node *ast.Node, key string) (string, error) {
 }
 
 // GetNodeFieldInt gets a field value as an int64 from an AST node
-func GetNodeFieldInt(node *ast.Node, key string) (int64, error) {
+func GetNodeFieldInt(node *jsonast.Node, key string) (int64, error) {
 	field := GetNodeField(node, key)
 	if field == nil || !field.Exists() {
 		return 0, errors.New("field not found")
@@ -54,7 +57,7 @@ func GetNodeFieldInt(node *ast.Node, key string) (int64, error) {
 }
 
 // GetNodeFieldFloat gets a field value as a float64 from an AST node
-func GetNodeFieldFloat(node *ast.Node, key string) (float64, error) {
+func GetNodeFieldFloat(node *jsonast.Node, key string) (float64, error) {
 	field := GetNodeField(node, key)
 	if field == nil || !field.Exists() {
 		return 0, errors.New("field not found")
@@ -63,7 +66,7 @@ func GetNodeFieldFloat(node *ast.Node, key string) (float64, error) {
 }
 
 // HasNodeField checks if a field exists on an AST node
-func HasNodeField(node *ast.Node, key string) bool {
+func HasNodeField(node *jsonast.Node, key string) bool {
 	if node == nil {
 		return false
 	}
@@ -71,8 +74,9 @@ func HasNodeField(node *ast.Node, key string) bool {
 	return field != nil && field.Exists()
 }
 
-// RenameNodeField renames a field on an AST node (moves old field to new key and deletes old)
-func RenameNodeField(node *ast.Node, oldKey, newKey string) error {
+// RenameNodeField renames a field on an AST node, keeping its position among the other fields
+// If newKey already exists, its value is replaced and oldKey is removed.
+func RenameNodeField(node *jsonast.Node, oldKey, newKey string) error {
 	if node == nil {
 		return errors.New("node is nil")
 	}
@@ -88,22 +92,35 @@ func RenameNodeField(node *ast.Node, oldKey, newKey string) error {
 		return nil // Field doesn't exist, nothing to rename
 	}
 
-	// Get the value and set it on the new key
-	value, err := oldField.Interface()
-	if err != nil {
-		return err
+	// Move the node itself, so numbers keep their exact literal and objects their field order
+	if node.Get(newKey).Exists() {
+		if _, err := node.Set(newKey, *oldField); err != nil {
+			return err
+		}
+		return DeleteNodeField(node, oldKey)
 	}
 
-	if err := SetNodeField(node, newKey, value); err != nil {
+	if err := node.LoadAll(); err != nil {
+		return fmt.Errorf("failed to load object: %w", err)
+	}
+	iter, err := node.Properties()
+	if err != nil {
 		return err
 	}
-
-	// Delete the old field
-	return DeleteNodeField(node, oldKey)
+	var pairs []jsonast.Pair
+	var pair jsonast.Pair
+	for iter.Next(&pair) {
+		if pair.Key == oldKey {
+			pair.Key = newKey
+		}
+		pairs = append(pairs, pair)
+	}
+	*node = jsonast.NewObject(pairs)
+	return nil
 }
 
 // CopyNodeField copies a field from one AST node to another
-func CopyNodeField(fromNode *ast.Node, toNode *ast.Node, key string) error {
+func CopyNodeField(fromNode *jsonast.Node, toNode *jsonast.Node, key string) error {
 	if fromNode == nil || toNode == nil {
 		return errors.New("source or destination node is nil")
 	}
@@ -113,7 +130,8 @@ func CopyNodeField(fromNode *ast.Node, toNode *ast.Node, key string) error {
 		return nil // Field doesn't exist, nothing to copy
 	}
 
-	value, err := field.Interface()
+	// Numbers are read as json.Number so large integers and decimals are copied exactly
+	value, err := field.InterfaceUseNumber()
 	if err != nil {
 		return err
 	}
@@ -121,26 +139,43 @@ func CopyNodeField(fromNode *ast.Node, toNode *ast.Node, key string) error {
 	return SetNodeField(toNode, key, value)
 }
 
+// CloneNode returns a deep copy of an AST node that shares nothing with the original
+// The copy is fully loaded, so it's safe for concurrent reads.
+func CloneNode(node *jsonast.Node) (*jsonast.Node, error) {
+	if node == nil {
+		return nil, nil
+	}
+	raw, err := node.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize node: %w", err)
+	}
+	clone := jsonast.NewRaw(string(raw))
+	if err := clone.LoadAll(); err != nil {
+		return nil, fmt.Errorf("failed to load cloned node: %w", err)
+	}
+	return &clone, nil
+}
+
 // GetNodeType returns the type of an AST node safely
-func GetNodeType(node *ast.Node) int {
+func GetNodeType(node *jsonast.Node) int {
 	if node == nil {
-		return ast.V_NULL
+		return jsonast.V_NULL
 	}
 	return node.TypeSafe()
 }
 
 // IsNodeArray checks if an AST node is an array
-func IsNodeArray(node *ast.Node) bool {
-	return GetNodeType(node) == ast.V_ARRAY
+func IsNodeArray(node *jsonast.Node) bool {
+	return GetNodeType(node) == jsonast.V_ARRAY
 }
 
 // IsNodeObject checks if an AST node is an object
-func IsNodeObject(node *ast.Node) bool {
-	return GetNodeType(node) == ast.V_OBJECT
+func IsNodeObject(node *jsonast.Node) bool {
+	return GetNodeType(node) == jsonast.V_OBJECT
 }
 
 // GetNodeArrayLength returns the length of an array node
-func GetNodeArrayLength(node *ast.Node) (int, error) {
+func GetNodeArrayLength(node *jsonast.Node) (int, error) {
 	if !IsNodeArray(node) {
 		return 0, errors.New("node is not an array")
 	}
@@ -148,7 +183,7 @@ func GetNodeArrayLength(node *ast.Node) (int, error) {
 }
 
 // GetNodeArrayItem returns an item from an array node at the specified index
-func GetNodeArrayItem(node *ast.Node, index int) (*ast.Node, error) {
+func GetNodeArrayItem(node *jsonast.Node, index int) (*jsonast.Node, error) {
 	if !IsNodeArray(node) {
 		return nil, errors.New("node is not an array")
 	}
@@ -164,3 +199,335 @@ func GetNodeArrayItem(node *ast.Node, index int) (*ast.Node, error) {
 
 	return node.Index(index), nil
 }
+
+// Dotted-path helpers
+// Paths use dot notation ("profile.address.city"). When the current node is an array,
+// a numeric segment indexes into it ("items.0.name"). An empty path refers to the root node.
+// A backslash escapes a dot or backslash that is part of a key (`config\.v2.enabled`);
+// EscapePathKey does this for arbitrary keys.
+
+// EscapePathKey escapes dots and backslashes in an object key for use as one path segment
+// e.g. EscapePathKey("config.v2") + ".enabled" addresses "enabled" inside the "config.v2" key
+func EscapePathKey(key string) string {
+	if !strings.ContainsAny(key, `.\`) {
+		return key
+	}
+	var b strings.Builder
+	b.Grow(len(key) + 2)
+	for _, r := range key {
+		if r == '.' || r == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// SplitPath splits a dotted path into its segments, unescaping keys
+// A trailing lone backslash is kept literally.
+func SplitPath(path string) []string {
+	if !strings.Contains(path, `\`) {
+		return strings.Split(path, ".")
+	}
+	var segments []string
+	var b strings.Builder
+	escaped := false
+	for _, r := range path {
+		switch {
+		case escaped:
+			b.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '.':
+			segments = append(segments, b.String())
+			b.Reset()
+		default:
+			b.WriteRune(r)
+		}
+	}
+	if escaped {
+		b.WriteByte('\\')
+	}
+	return append(segments, b.String())
+}
+
+// hasPathPrefix reports whether path starts with all segments of prefix
+func hasPathPrefix(path, prefix []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if path[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// GetNodeAtPath navigates to a nested node using a dotted path
+// Returns nil if any part of the path doesn't exist
+func GetNodeAtPath(root *jsonast.Node, path string) *jsonast.Node {
+	if root == nil || path == "" {
+		return root
+	}
+	return getNodeAtSegments(root, SplitPath(path))
+}
+
+// getNodeAtSegments navigates to a nested node by path segments, returning nil if it doesn't exist
+func getNodeAtSegments(root *jsonast.Node, segments []string) *jsonast.Node {
+	current := root
+	for _, part := range segments {
+		current = getNodeChild(current, part)
+		if current == nil || !current.Exists() {
+			return nil
+		}
+	}
+	return current
+}
+
+// getNodeChild returns the child of a node by object key or, for arrays, by numeric index
+func getNodeChild(node *jsonast.Node, part string) *jsonast.Node {
+	if node == nil {
+		return nil
+	}
+	if node.TypeSafe() == jsonast.V_ARRAY {
+		index, err := strconv.Atoi(part)
+		if err != nil {
+			return nil
+		}
+		length, err := node.Len()
+		if err != nil || index < 0 || index >= length {
+			return nil
+		}
+		return node.Index(index)
+	}
+	return node.Get(part)
+}
+
+// GetNodeStringAtPath gets a string value at a dotted path
+func GetNodeStringAtPath(root *jsonast.Node, path string) (string, error) {
+	field := GetNodeAtPath(root, path)
+	if field == nil {
+		return "", fmt.Errorf("field not found at path '%s'", path)
+	}
+	return field.String()
+}
+
+// GetNodeIntAtPath gets an int64 value at a dotted path
+func GetNodeIntAtPath(root *jsonast.Node, path string) (int64, error) {
+	field := GetNodeAtPath(root, path)
+	if field == nil {
+		return 0, fmt.Errorf("field not found at path '%s'", path)
+	}
+	return field.Int64()
+}
+
+// GetNodeFloatAtPath gets a float64 value at a dotted path
+func GetNodeFloatAtPath(root *jsonast.Node, path string) (float64, error) {
+	field := GetNodeAtPath(root, path)
+	if field == nil {
+		return 0, fmt.Errorf("field not found at path '%s'", path)
+	}
+	return field.Float64()
+}
+
+// GetNodeBoolAtPath gets a bool value at a dotted path
+func GetNodeBoolAtPath(root *jsonast.Node, path string) (bool, error) {
+	field := GetNodeAtPath(root, path)
+	if field == nil {
+		return false, fmt.Errorf("field not found at path '%s'", path)
+	}
+	return field.Bool()
+}
+
+// SetNodeAtPath sets a value at a dotted path, creating intermediate objects as needed
+func SetNodeAtPath(root *jsonast.Node, path string, value interface{}) error {
+	if root == nil {
+		return errors.New("node is nil")
+	}
+	if path == "" {
+		return errors.New("path is empty")
+	}
+
+	parent, key, err := ensureParentAtPath(root, path)
+	if err != nil {
+		return err
+	}
+
+	if parent.TypeSafe() == jsonast.V_ARRAY {
+		index, err := strconv.Atoi(key)
+		if err != nil {
+			return fmt.Errorf("invalid array index '%s' in path '%s'", key, path)
+		}
+		_, err = parent.SetAnyByIndex(index, value)
+		return err
+	}
+	return SetNodeField(parent, key, value)
+}
+
+// ensureParentAtPath walks to the parent of the last path segment,
+// creating empty objects for missing intermediate segments
+func ensureParentAtPath(root *jsonast.Node, path string) (*jsonast.Node, string, error) {
+	parts := SplitPath(path)
+	current := root
+
+	for _, part := range parts[:len(parts)-1] {
+		next := getNodeChild(current, part)
+		if next == nil || !next.Exists() {
+			if current.TypeSafe() != jsonast.V_OBJECT {
+				return nil, "", fmt.Errorf("cannot create '%s' in path '%s': parent is not an object", part, path)
+			}
+			if _, err := current.Set(part, jsonast.NewObject(nil)); err != nil {
+				return nil, "", err
+			}
+			next = current.Get(part)
+		}
+		current = next
+	}
+
+	return current, parts[len(parts)-1], nil
+}
+
+// DeleteNodeAtPath deletes the field at a dotted path (no-op if missing)
+func DeleteNodeAtPath(root *jsonast.Node, path string) error {
+	if root == nil || path == "" {
+		return nil
+	}
+	return deleteNodeAtSegments(root, SplitPath(path))
+}
+
+// deleteNodeAtSegments deletes the field at path segments (no-op if missing)
+func deleteNodeAtSegments(root *jsonast.Node, segments []string) error {
+	key := segments[len(segments)-1]
+	parent := getNodeAtSegments(root, segments[:len(segments)-1])
+	if parent == nil {
+		return nil
+	}
+	if parent.TypeSafe() == jsonast.V_ARRAY {
+		index, err := strconv.Atoi(key)
+		if err != nil {
+			return nil
+		}
+		_, err = parent.UnsetByIndex(index)
+		return err
+	}
+	return DeleteNodeField(parent, key)
+}
+
+// MoveNodeAtPath moves the value at one dotted path to another (no-op if the source is missing)
+// Intermediate objects are created at the destination, and source parents left empty are removed.
+func MoveNodeAtPath(root *jsonast.Node, from, to string) error {
+	if root == nil || from == "" || to == "" || from == to {
+		return nil
+	}
+	if fromParts, toParts := SplitPath(from), SplitPath(to); len(toParts) > len(fromParts) && hasPathPrefix(toParts, fromParts) {
+		return fmt.Errorf("cannot move '%s' into itself ('%s')", from, to)
+	}
+
+	source := GetNodeAtPath(root, from)
+	if source == nil {
+		return nil
+	}
+	if err := source.LoadAll(); err != nil {
+		return fmt.Errorf("failed to load '%s': %w", from, err)
+	}
+	value := *source
+
+	if err := DeleteNodeAtPath(root, from); err != nil {
+		return err
+	}
+	if err := pruneEmptyParents(root, from, to); err != nil {
+		return err
+	}
+
+	parent, key, err := ensureParentAtPath(root, to)
+	if err != nil {
+		return err
+	}
+	if parent.TypeSafe() == jsonast.V_ARRAY {
+		index, err := strconv.Atoi(key)
+		if err != nil {
+			return fmt.Errorf("invalid array index '%s' in path '%s'", key, to)
+		}
+		_, err = parent.SetByIndex(index, value)
+		return err
+	}
+	_, err = parent.Set(key, value)
+	return err
+}
+
+// pruneEmptyParents removes the objects along path that a move left empty,
+// keeping the ones the destination path runs through
+func pruneEmptyParents(root *jsonast.Node, path, keep string) error {
+	segments, keepSegments := SplitPath(path), SplitPath(keep)
+	for n := len(segments) - 1; n > 0; n-- {
+		parentSegments := segments[:n]
+		if hasPathPrefix(keepSegments, parentSegments) {
+			return nil
+		}
+		parent := getNodeAtSegments(root, parentSegments)
+		if !IsNodeObject(parent) {
+			return nil
+		}
+		if length, err := parent.Len(); err != nil || length > 0 {
+			return err
+		}
+		if err := deleteNodeAtSegments(root, parentSegments); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ForEachNodeArrayItem calls fn for each item of the array at a dotted path
+// Does nothing if the path doesn't exist or isn't an array
+func ForEachNodeArrayItem(root *jsonast.Node, path string, fn func(index int, item *jsonast.Node) error) error {
+	array := GetNodeAtPath(root, path)
+	if !IsNodeArray(array) {
+		return nil
+	}
+
+	length, err := array.Len()
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < length; i++ {
+		if err := fn(i, array.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MapNodeObject calls fn for each key/value pair of the object at a dotted path
+// Keys are visited in their original order. Does nothing if the path isn't an object.
+func MapNodeObject(root *jsonast.Node, path string, fn func(key string, value *jsonast.Node) error) error {
+	object := GetNodeAtPath(root, path)
+	if !IsNodeObject(object) {
+		return nil
+	}
+
+	// Collect keys first so fn may safely modify the object
+	iter, err := object.Properties()
+	if err != nil {
+		return err
+	}
+	var keys []string
+	var pair jsonast.Pair
+	for iter.Next(&pair) {
+		keys = append(keys, pair.Key)
+	}
+
+	for _, key := range keys {
+		value := object.Get(key)
+		if value == nil || !value.Exists() {
+			continue
+		}
+		if err := fn(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
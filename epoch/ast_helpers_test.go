@@ -3,14 +3,13 @@ package epoch
 import (
 	"strings"
 
-	"github.com/bytedance/sonic"
-	"github.com/bytedance/sonic/ast"
+	"github.com/astronomer/epoch/epoch/jsonast"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
 
 var _ = Describe("AST Helpers", func() {
-	var testNode *ast.Node
+	var testNode *jsonast.Node
 
 	BeforeEach(func() {
 		// Create a test JSON object with various field types
@@ -26,7 +25,7 @@ var _ = Describe("AST Helpers", func() {
 			"hobbies": ["reading", "gaming", "coding"],
 			"metadata": null
 		}`
-		node, err := sonic.Get([]byte(jsonData))
+		node, err := getNode([]byte(jsonData))
 		Expect(err).NotTo(HaveOccurred())
 		err = node.Load()
 		Expect(err).NotTo(HaveOccurred())
@@ -225,11 +224,11 @@ var _ = Describe("AST Helpers", func() {
 	})
 
 	Describe("CopyNodeField", func() {
-		var destNode *ast.Node
+		var destNode *jsonast.Node
 
 		BeforeEach(func() {
 			jsonData := `{"existing": "value"}`
-			node, err := sonic.Get([]byte(jsonData))
+			node, err := getNode([]byte(jsonData))
 			Expect(err).NotTo(HaveOccurred())
 			err = node.Load()
 			Expect(err).NotTo(HaveOccurred())
@@ -287,16 +286,16 @@ var _ = Describe("AST Helpers", func() {
 	Describe("Node Type Helpers", func() {
 		Describe("GetNodeType", func() {
 			It("should return correct types for different nodes", func() {
-				Expect(GetNodeType(testNode)).To(Equal(ast.V_OBJECT))
-				Expect(GetNodeType(GetNodeField(testNode, "name"))).To(Equal(ast.V_STRING))
-				Expect(GetNodeType(GetNodeField(testNode, "age"))).To(Equal(ast.V_NUMBER))
-				Expect(GetNodeType(GetNodeField(testNode, "active"))).To(Equal(ast.V_TRUE))
-				Expect(GetNodeType(GetNodeField(testNode, "hobbies"))).To(Equal(ast.V_ARRAY))
-				Expect(GetNodeType(GetNodeField(testNode, "metadata"))).To(Equal(ast.V_NULL))
+				Expect(GetNodeType(testNode)).To(Equal(jsonast.V_OBJECT))
+				Expect(GetNodeType(GetNodeField(testNode, "name"))).To(Equal(jsonast.V_STRING))
+				Expect(GetNodeType(GetNodeField(testNode, "age"))).To(Equal(jsonast.V_NUMBER))
+				Expect(GetNodeType(GetNodeField(testNode, "active"))).To(Equal(jsonast.V_TRUE))
+				Expect(GetNodeType(GetNodeField(testNode, "hobbies"))).To(Equal(jsonast.V_ARRAY))
+				Expect(GetNodeType(GetNodeField(testNode, "metadata"))).To(Equal(jsonast.V_NULL))
 			})
 
 			It("should return V_NULL for nil node", func() {
-				Expect(GetNodeType(nil)).To(Equal(ast.V_NULL))
+				Expect(GetNodeType(nil)).To(Equal(jsonast.V_NULL))
 			})
 		})
 
@@ -319,7 +318,7 @@ var _ = Describe("AST Helpers", func() {
 	})
 
 	Describe("Array Helpers", func() {
-		var arrayNode *ast.Node
+		var arrayNode *jsonast.Node
 
 		BeforeEach(func() {
 			arrayNode = GetNodeField(testNode, "hobbies")
@@ -414,7 +413,7 @@ var _ = Describe("AST Helpers", func() {
 
 	Describe("Edge Cases and Error Handling", func() {
 		Describe("Null and Empty Value Handling", func() {
-			var edgeTestNode *ast.Node
+			var edgeTestNode *jsonast.Node
 
 			BeforeEach(func() {
 				jsonData := `{
@@ -425,7 +424,7 @@ var _ = Describe("AST Helpers", func() {
 					"zero": 0,
 					"false_bool": false
 				}`
-				node, err := sonic.Get([]byte(jsonData))
+				node, err := getNode([]byte(jsonData))
 				Expect(err).NotTo(HaveOccurred())
 				err = node.Load()
 				Expect(err).NotTo(HaveOccurred())
@@ -437,7 +436,7 @@ var _ = Describe("AST Helpers", func() {
 				nullNode := GetNodeField(edgeTestNode, "null_field")
 				Expect(nullNode).NotTo(BeNil())
 				Expect(nullNode.Exists()).To(BeTrue())
-				Expect(GetNodeType(nullNode)).To(Equal(ast.V_NULL))
+				Expect(GetNodeType(nullNode)).To(Equal(jsonast.V_NULL))
 
 				// Sonic may or may not error when trying to get string/int/float from null
 				// Let's just verify the null type is detected correctly
@@ -492,12 +491,12 @@ var _ = Describe("AST Helpers", func() {
 				Expect(zeroFloat).To(Equal(float64(0)))
 
 				falseNode := GetNodeField(edgeTestNode, "false_bool")
-				Expect(GetNodeType(falseNode)).To(Equal(ast.V_FALSE))
+				Expect(GetNodeType(falseNode)).To(Equal(jsonast.V_FALSE))
 			})
 		})
 
 		Describe("Type Conversion Edge Cases", func() {
-			var typeTestNode *ast.Node
+			var typeTestNode *jsonast.Node
 
 			BeforeEach(func() {
 				jsonData := `{
@@ -508,7 +507,7 @@ var _ = Describe("AST Helpers", func() {
 					"boolean_true": true,
 					"boolean_false": false
 				}`
-				node, err := sonic.Get([]byte(jsonData))
+				node, err := getNode([]byte(jsonData))
 				Expect(err).NotTo(HaveOccurred())
 				err = node.Load()
 				Expect(err).NotTo(HaveOccurred())
@@ -555,10 +554,10 @@ var _ = Describe("AST Helpers", func() {
 
 			It("should handle boolean types correctly", func() {
 				trueNode := GetNodeField(typeTestNode, "boolean_true")
-				Expect(GetNodeType(trueNode)).To(Equal(ast.V_TRUE))
+				Expect(GetNodeType(trueNode)).To(Equal(jsonast.V_TRUE))
 
 				falseNode := GetNodeField(typeTestNode, "boolean_false")
-				Expect(GetNodeType(falseNode)).To(Equal(ast.V_FALSE))
+				Expect(GetNodeType(falseNode)).To(Equal(jsonast.V_FALSE))
 
 				// Sonic's behavior with boolean conversion may vary - don't assert errors
 				_, err := GetNodeFieldString(typeTestNode, "boolean_true")
@@ -573,11 +572,11 @@ var _ = Describe("AST Helpers", func() {
 		})
 
 		Describe("Array Index Edge Cases", func() {
-			var arrayNode *ast.Node
+			var arrayNode *jsonast.Node
 
 			BeforeEach(func() {
 				arrayJSON := `["first", "second", "third"]`
-				node, err := sonic.Get([]byte(arrayJSON))
+				node, err := getNode([]byte(arrayJSON))
 				Expect(err).NotTo(HaveOccurred())
 				err = node.Load()
 				Expect(err).NotTo(HaveOccurred())
@@ -620,11 +619,11 @@ var _ = Describe("AST Helpers", func() {
 		})
 
 		Describe("Helper Function Error Cases", func() {
-			var helperTestNode *ast.Node
+			var helperTestNode *jsonast.Node
 
 			BeforeEach(func() {
 				jsonData := `{"existing": "value", "another": 42}`
-				node, err := sonic.Get([]byte(jsonData))
+				node, err := getNode([]byte(jsonData))
 				Expect(err).NotTo(HaveOccurred())
 				err = node.Load()
 				Expect(err).NotTo(HaveOccurred())
@@ -662,7 +661,7 @@ var _ = Describe("AST Helpers", func() {
 				// Create deeply nested JSON
 				deepJSON := `{"level1": {"level2": {"level3": {"level4": {"level5": "deep_value"}}}}}`
 
-				node, err := sonic.Get([]byte(deepJSON))
+				node, err := getNode([]byte(deepJSON))
 				Expect(err).NotTo(HaveOccurred())
 				err = node.Load()
 				Expect(err).NotTo(HaveOccurred())
@@ -698,7 +697,7 @@ var _ = Describe("AST Helpers", func() {
 				}
 				manyFieldsJSON += `}`
 
-				node, err := sonic.Get([]byte(manyFieldsJSON))
+				node, err := getNode([]byte(manyFieldsJSON))
 				Expect(err).NotTo(HaveOccurred())
 				err = node.Load()
 				Expect(err).NotTo(HaveOccurred())
@@ -717,4 +716,141 @@ var _ = Describe("AST Helpers", func() {
 			})
 		})
 	})
+	Describe("Dotted path helpers", func() {
+		var pathNode *jsonast.Node
+
+		BeforeEach(func() {
+			node, err := getNode([]byte(`{
+				"profile": {"name": "Jane", "age": 41, "score": 9.5, "active": true},
+				"items": [{"sku": "a"}, {"sku": "b"}]
+			}`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(node.Load()).To(Succeed())
+			pathNode = &node
+		})
+
+		It("should read typed values at nested paths", func() {
+			name, err := GetNodeStringAtPath(pathNode, "profile.name")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(name).To(Equal("Jane"))
+
+			age, err := GetNodeIntAtPath(pathNode, "profile.age")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(age).To(Equal(int64(41)))
+
+			score, err := GetNodeFloatAtPath(pathNode, "profile.score")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(score).To(Equal(9.5))
+
+			active, err := GetNodeBoolAtPath(pathNode, "profile.active")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(active).To(BeTrue())
+		})
+
+		It("should index into arrays with numeric segments", func() {
+			sku, err := GetNodeStringAtPath(pathNode, "items.1.sku")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sku).To(Equal("b"))
+
+			Expect(GetNodeAtPath(pathNode, "items.5.sku")).To(BeNil())
+		})
+
+		It("should return an error for missing paths", func() {
+			_, err := GetNodeStringAtPath(pathNode, "profile.missing")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should set values and create intermediate objects", func() {
+			Expect(SetNodeAtPath(pathNode, "profile.address.city", "Boston")).To(Succeed())
+			Expect(SetNodeAtPath(pathNode, "items.0.sku", "z")).To(Succeed())
+
+			raw, err := pathNode.Raw()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(raw).To(ContainSubstring(`"address":{"city":"Boston"}`))
+			Expect(raw).To(ContainSubstring(`"sku":"z"`))
+		})
+
+		It("should delete values at nested paths", func() {
+			Expect(DeleteNodeAtPath(pathNode, "profile.age")).To(Succeed())
+			Expect(GetNodeAtPath(pathNode, "profile.age")).To(BeNil())
+			Expect(DeleteNodeAtPath(pathNode, "nope.nothing")).To(Succeed())
+		})
+
+		It("should move values between nesting levels", func() {
+			node, err := DefaultJSONEngine().Parse([]byte(`{"profile":{"theme":"dark"},"id":1}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(MoveNodeAtPath(node, "profile.theme", "settings.display.theme")).To(Succeed())
+			raw, err := node.MarshalJSON()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(raw)).To(Equal(`{"id":1,"settings":{"display":{"theme":"dark"}}}`))
+
+			Expect(MoveNodeAtPath(node, "settings.display.theme", "theme")).To(Succeed())
+			raw, err = node.MarshalJSON()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(raw)).To(Equal(`{"id":1,"theme":"dark"}`))
+		})
+
+		It("should keep source parents that still have fields or are on the destination path", func() {
+			Expect(MoveNodeAtPath(pathNode, "profile.name", "profile.display.name")).To(Succeed())
+			name, err := GetNodeStringAtPath(pathNode, "profile.display.name")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(name).NotTo(BeEmpty())
+			Expect(GetNodeAtPath(pathNode, "profile.age")).NotTo(BeNil())
+		})
+
+		It("should ignore missing sources and reject moves into the source", func() {
+			Expect(MoveNodeAtPath(pathNode, "profile.missing", "elsewhere")).To(Succeed())
+			Expect(GetNodeAtPath(pathNode, "elsewhere")).To(BeNil())
+			Expect(MoveNodeAtPath(pathNode, "profile", "profile.inner")).To(MatchError(ContainSubstring("into itself")))
+		})
+
+		It("should iterate array items and object entries in order", func() {
+			var skus []string
+			Expect(ForEachNodeArrayItem(pathNode, "items", func(_ int, item *jsonast.Node) error {
+				sku, err := GetNodeFieldString(item, "sku")
+				skus = append(skus, sku)
+				return err
+			})).To(Succeed())
+			Expect(skus).To(Equal([]string{"a", "b"}))
+
+			var keys []string
+			Expect(MapNodeObject(pathNode, "profile", func(key string, _ *jsonast.Node) error {
+				keys = append(keys, key)
+				return nil
+			})).To(Succeed())
+			Expect(keys).To(Equal([]string{"name", "age", "score", "active"}))
+		})
+
+		It("should escape and split keys containing dots and backslashes", func() {
+			Expect(EscapePathKey("config.v2")).To(Equal(`config\.v2`))
+			Expect(EscapePathKey(`C:\temp`)).To(Equal(`C:\\temp`))
+			Expect(EscapePathKey("名前")).To(Equal("名前"))
+
+			Expect(SplitPath(`config\.v2.enabled`)).To(Equal([]string{"config.v2", "enabled"}))
+			Expect(SplitPath(EscapePathKey(`a\.b`) + ".c")).To(Equal([]string{`a\.b`, "c"}))
+			Expect(SplitPath("a.b")).To(Equal([]string{"a", "b"}))
+			Expect(SplitPath(`trailing\`)).To(Equal([]string{`trailing\`}))
+		})
+
+		It("should address dotted, slashed and non-ASCII keys", func() {
+			node, err := DefaultJSONEngine().Parse([]byte(`{"config.v2":{"enabled":true},"a/b":1,"名前":{"姓":"山田"}}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			enabled, err := GetNodeBoolAtPath(node, `config\.v2.enabled`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(enabled).To(BeTrue())
+			family, err := GetNodeStringAtPath(node, "名前.姓")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(family).To(Equal("山田"))
+
+			Expect(MoveNodeAtPath(node, `config\.v2.enabled`, "settings.config.v2")).To(Succeed())
+			Expect(SetNodeAtPath(node, "a/b", 2)).To(Succeed())
+			Expect(DeleteNodeAtPath(node, "名前.姓")).To(Succeed())
+
+			raw, err := node.MarshalJSON()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(raw)).To(Equal(`{"a/b":2,"名前":{},"settings":{"config":{"v2":true}}}`))
+		})
+	})
 })
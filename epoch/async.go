@@ -0,0 +1,183 @@
+package epoch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/astronomer/epoch/epoch/jsonast"
+	"github.com/gin-gonic/gin"
+)
+
+// AsyncConfig describes how to absorb HEAD making an endpoint asynchronous: HEAD answers
+// with 202 Accepted and a job id, while older versions returned the final result directly.
+type AsyncConfig struct {
+	// Await blocks until the job completes and returns its result. It's called with the
+	// request context (bounded by Timeout), so it should stop polling when ctx is done.
+	Await func(ctx context.Context, jobID string) (*AsyncResult, error)
+
+	// JobIDField is the dotted path of the job id in HEAD's accepted response ("job_id")
+	JobIDField string
+
+	// AcceptedStatus is the status HEAD answers with while the job runs (202)
+	AcceptedStatus int
+
+	// Timeout bounds how long older clients wait; 0 waits as long as the request lives
+	Timeout time.Duration
+
+	// ResultType is the HEAD type of the job result. Its type migrations are applied to the
+	// result instead of the endpoint's response type; nil leaves the result unmigrated.
+	ResultType interface{}
+}
+
+// AsyncResult is the outcome of a completed job
+type AsyncResult struct {
+	StatusCode int         // Status older clients receive; 0 means 200
+	Body       interface{} // HEAD-shaped result; []byte and json.RawMessage are used as raw JSON
+}
+
+// ErrAsyncTimeout is returned when a job doesn't complete within AsyncConfig.Timeout
+var ErrAsyncTimeout = errors.New("timed out waiting for asynchronous job")
+
+// withDefaults returns the config with the default job id field and accepted status filled in
+func (cfg AsyncConfig) withDefaults() AsyncConfig {
+	if cfg.JobIDField == "" {
+		cfg.JobIDField = "job_id"
+	}
+	if cfg.AcceptedStatus == 0 {
+		cfg.AcceptedStatus = http.StatusAccepted
+	}
+	return cfg
+}
+
+// ResponseAwaitAsync waits for HEAD's asynchronous job and returns its result to older clients
+// The middleware resolves it before type migrations run; as an envelope operation it's a no-op.
+type ResponseAwaitAsync struct {
+	Config AsyncConfig
+}
+
+// ApplyToEnvelope returns the body unchanged; the job is awaited by the middleware
+func (op *ResponseAwaitAsync) ApplyToEnvelope(body *jsonast.Node) (*jsonast.Node, error) {
+	return body, nil
+}
+
+// resultType returns the reflect type of Config.ResultType, or nil
+func (op *ResponseAwaitAsync) resultType() reflect.Type {
+	t := reflect.TypeOf(op.Config.ResultType)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// AsyncOperation returns the enabled async operation between HEAD (from) and an older version (to), if any
+func (mc *MigrationChain) AsyncOperation(ctx context.Context, c *gin.Context, from, to *Version, method, pathPattern string) *ResponseAwaitAsync {
+	for _, change := range mc.envelopePath(from, to, method, pathPattern) {
+		if !change.isEnabled(ctx, c) {
+			continue
+		}
+		for _, op := range change.GetResponseEnvelopeOperations(method, pathPattern) {
+			if async, ok := op.(*ResponseAwaitAsync); ok {
+				return async
+			}
+		}
+	}
+	return nil
+}
+
+// Await waits for the job an accepted response describes and returns its status and raw JSON body
+func (op *ResponseAwaitAsync) Await(ctx context.Context, accepted *jsonast.Node) (int, []byte, error) {
+	cfg := op.Config.withDefaults()
+	if cfg.Await == nil {
+		return 0, nil, errors.New("no Await function configured for asynchronous endpoint")
+	}
+
+	idNode := GetNodeAtPath(accepted, cfg.JobIDField)
+	if idNode == nil {
+		return 0, nil, fmt.Errorf("accepted response has no %s field", cfg.JobIDField)
+	}
+	jobID, ok, err := referenceID(idNode)
+	if err != nil || !ok {
+		return 0, nil, fmt.Errorf("accepted response has an invalid %s field", cfg.JobIDField)
+	}
+
+	if cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, cfg.Timeout, ErrAsyncTimeout)
+		defer cancel()
+	}
+
+	result, err := cfg.Await(ctx, jobID)
+	if err != nil {
+		if cause := context.Cause(ctx); errors.Is(cause, ErrAsyncTimeout) {
+			err = cause
+		}
+		return 0, nil, fmt.Errorf("failed to await job %s: %w", jobID, err)
+	}
+	if result == nil {
+		return 0, nil, fmt.Errorf("job %s completed without a result", jobID)
+	}
+
+	status := result.StatusCode
+	if status == 0 {
+		status = http.StatusOK
+	}
+	var body []byte
+	switch raw := result.Body.(type) {
+	case nil:
+	case []byte:
+		body = raw
+	case json.RawMessage:
+		body = raw
+	default:
+		if body, err = json.Marshal(raw); err != nil {
+			return 0, nil, fmt.Errorf("failed to encode result of job %s: %w", jobID, err)
+		}
+	}
+	return status, body, nil
+}
+
+// AwaitAsync makes the endpoint synchronous again for older versions: when HEAD answers with
+// 202 Accepted and a job id, Epoch waits for config.Await and returns the job's result instead
+func (eb *endpointBuilder) AwaitAsync(config AsyncConfig) *endpointBuilder {
+	eb.parent.addEnvelopeOperation(eb.method, eb.pathPattern, &ResponseAwaitAsync{Config: config})
+	return eb
+}
+
+// awaitAsyncResponse replaces an accepted (202) response with the job's result for versions
+// before the endpoint became asynchronous. It reports whether it did and the result's type.
+func (vah *VersionAwareHandler) awaitAsyncResponse(
+	c *gin.Context,
+	endpointDef *EndpointDefinition,
+	version *Version,
+	responseCapture *ResponseCapture,
+) (bool, reflect.Type, error) {
+	ctx := c.Request.Context()
+	op := vah.migrationChain.AsyncOperation(ctx, c, vah.versionBundle.GetHeadVersion(), version,
+		endpointDef.Method, endpointDef.PathPattern)
+	if op == nil || responseCapture.statusCode != op.Config.withDefaults().AcceptedStatus {
+		return false, nil, nil
+	}
+
+	accepted, err := vah.jsonEngine.Parse(responseCapture.body)
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to parse accepted response: %w", err)
+	}
+	status, body, err := op.Await(ctx, accepted)
+	if err != nil {
+		return false, nil, err
+	}
+
+	// These describe the job, which older clients never see
+	header := responseCapture.Header()
+	header.Del("Location")
+	header.Del("Retry-After")
+
+	responseCapture.statusCode = status
+	responseCapture.body = append(responseCapture.body[:0], body...)
+	return true, op.resultType(), nil
+}
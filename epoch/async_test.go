@@ -0,0 +1,119 @@
+package epoch
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type asyncJob struct {
+	JobID  string `json:"job_id"`
+	Status string `json:"status"`
+}
+
+type asyncReport struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+}
+
+var _ = Describe("Asynchronous endpoint compatibility", func() {
+	var awaited []string
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		awaited = nil
+	})
+
+	serve := func(version string, status int, config AsyncConfig) *httptest.ResponseRecorder {
+		v1, v2, _ := newTestVersions()
+		change := NewVersionChangeBuilder(v1, v2).
+			ForEndpoint("POST", "/reports").
+			AwaitAsync(config).
+			ForType(asyncReport{}).
+			ResponseToPreviousVersion().
+			RenameField("title", "name").
+			Build()
+		epochInstance, err := setupHeadEpoch([]*Version{v1, v2}, []*VersionChange{change})
+		Expect(err).NotTo(HaveOccurred())
+
+		router := setupRouterWithMiddleware(epochInstance)
+		router.POST("/reports", epochInstance.WrapHandler(func(c *gin.Context) {
+			if status != 202 {
+				c.JSON(status, gin.H{"error": "invalid"})
+				return
+			}
+			c.Header("Location", "/jobs/j-1")
+			c.Header("Retry-After", "5")
+			c.JSON(202, asyncJob{JobID: "j-1", Status: "pending"})
+		}).Returns(asyncJob{}).ToHandlerFunc("POST", "/reports"))
+
+		return serveVersioned(router, httptest.NewRequest("POST", "/reports", nil), version)
+	}
+
+	await := func(ctx context.Context, jobID string) (*AsyncResult, error) {
+		awaited = append(awaited, jobID)
+		return &AsyncResult{StatusCode: 201, Body: asyncReport{ID: 9, Title: "Q3"}}, nil
+	}
+
+	It("should return the job result synchronously to older versions", func() {
+		recorder := serve("2024-01-01", 202, AsyncConfig{Await: await, ResultType: asyncReport{}})
+
+		Expect(awaited).To(Equal([]string{"j-1"}))
+		Expect(recorder.Code).To(Equal(201))
+		Expect(recorder.Body.String()).To(Equal(`{"id":9,"name":"Q3"}`))
+		Expect(recorder.Header().Values("Location")).To(BeEmpty())
+		Expect(recorder.Header().Values("Retry-After")).To(BeEmpty())
+	})
+
+	It("should keep the accepted response for newer versions", func() {
+		recorder := serve("2024-06-01", 202, AsyncConfig{Await: await, ResultType: asyncReport{}})
+
+		Expect(awaited).To(BeEmpty())
+		Expect(recorder.Code).To(Equal(202))
+		Expect(recorder.Body.String()).To(Equal(`{"job_id":"j-1","status":"pending"}`))
+		Expect(recorder.Header().Get("Location")).To(Equal("/jobs/j-1"))
+	})
+
+	It("should leave other statuses alone", func() {
+		recorder := serve("2024-01-01", 400, AsyncConfig{Await: await})
+
+		Expect(awaited).To(BeEmpty())
+		Expect(recorder.Code).To(Equal(400))
+	})
+
+	It("should answer 504 when the job doesn't complete in time", func() {
+		slow := func(ctx context.Context, jobID string) (*AsyncResult, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		recorder := serve("2024-01-01", 202, AsyncConfig{Await: slow, Timeout: 10 * time.Millisecond})
+
+		Expect(recorder.Code).To(Equal(504))
+		Expect(recorder.Body.String()).To(ContainSubstring("timed out"))
+	})
+
+	It("should answer 500 when the job fails", func() {
+		failing := func(ctx context.Context, jobID string) (*AsyncResult, error) {
+			return nil, errors.New("job crashed")
+		}
+		recorder := serve("2024-01-01", 202, AsyncConfig{Await: failing})
+
+		Expect(recorder.Code).To(Equal(500))
+		Expect(recorder.Body.String()).To(ContainSubstring("job crashed"))
+	})
+
+	It("should use raw JSON results as is", func() {
+		raw := func(ctx context.Context, jobID string) (*AsyncResult, error) {
+			return &AsyncResult{Body: []byte(`{"id":1,"title":"raw"}`)}, nil
+		}
+		recorder := serve("2024-01-01", 202, AsyncConfig{Await: raw})
+
+		Expect(recorder.Code).To(Equal(200))
+		Expect(recorder.Body.String()).To(Equal(`{"id":1,"title":"raw"}`))
+	})
+})
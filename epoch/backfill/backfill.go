@@ -0,0 +1,249 @@
+// Package backfill brings stored JSON documents written under older API versions up to a
+// newer shape (HEAD by default) by running them through an Epoch's VersionChanges, with
+// bounded concurrency, progress reporting and failures grouped into buckets by cause.
+package backfill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/astronomer/epoch/epoch"
+)
+
+// maxExamples bounds the failures kept per bucket
+const maxExamples = 5
+
+// Sink receives each migrated document; it is called concurrently from the workers
+type Sink func(ctx context.Context, doc Document) error
+
+// Backfiller migrates documents of one type from a Source into a Sink
+type Backfiller struct {
+	epoch         *epoch.Epoch
+	typ           reflect.Type
+	target        *epoch.Version
+	concurrency   int
+	progressEvery int
+	onProgress    func(Progress)
+	bucket        func(error) string
+}
+
+// New creates a backfiller for documents shaped like typ, e.g. reflect.TypeOf(Webhook{})
+func New(e *epoch.Epoch, typ reflect.Type) *Backfiller {
+	return &Backfiller{
+		epoch:       e,
+		typ:         typ,
+		target:      e.VersionBundle().GetHeadVersion(),
+		concurrency: 1,
+		bucket:      rootCause,
+	}
+}
+
+// WithTarget migrates documents up to target instead of HEAD
+func (b *Backfiller) WithTarget(target *epoch.Version) *Backfiller {
+	b.target = target
+	return b
+}
+
+// WithConcurrency migrates up to n documents at once
+func (b *Backfiller) WithConcurrency(n int) *Backfiller {
+	if n > 0 {
+		b.concurrency = n
+	}
+	return b
+}
+
+// OnProgress calls fn after every `every` processed documents and once when the run ends
+func (b *Backfiller) OnProgress(every int, fn func(Progress)) *Backfiller {
+	b.progressEvery = every
+	b.onProgress = fn
+	return b
+}
+
+// WithBucketer groups failures by bucket(err) instead of by the innermost error message
+func (b *Backfiller) WithBucketer(bucket func(error) string) *Backfiller {
+	b.bucket = bucket
+	return b
+}
+
+// Progress is a snapshot of a running backfill
+type Progress struct {
+	Processed int
+	Migrated  int
+	Skipped   int
+	Failed    int
+	Elapsed   time.Duration
+}
+
+// Result summarizes a backfill run
+type Result struct {
+	Progress
+	// Buckets groups failures by cause, largest bucket first
+	Buckets []*Bucket
+}
+
+// Bucket is a group of failures with the same cause
+type Bucket struct {
+	Reason   string
+	Count    int
+	Examples []Failure // the first few failures, in no particular order
+}
+
+// Failure is a document that couldn't be migrated or written
+type Failure struct {
+	ID      string
+	Version string
+	Err     error
+}
+
+// Run migrates every document from src and hands it to sink. Documents already at the
+// target version are skipped. Failures are bucketed in the Result rather than returned;
+// Run only returns an error when src fails or ctx is done, along with the partial result.
+func (b *Backfiller) Run(ctx context.Context, src Source, sink Sink) (*Result, error) {
+	run := &run{Backfiller: b, started: time.Now(), buckets: make(map[string]*Bucket)}
+
+	docs := make(chan Document)
+	var wg sync.WaitGroup
+	for i := 0; i < b.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for doc := range docs {
+				run.record(doc, b.migrate(ctx, doc, sink))
+			}
+		}()
+	}
+
+	var srcErr error
+	for srcErr == nil {
+		doc, err := src.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			srcErr = fmt.Errorf("failed to read source: %w", err)
+			break
+		}
+		select {
+		case docs <- doc:
+		case <-ctx.Done():
+			srcErr = ctx.Err()
+		}
+	}
+	close(docs)
+	wg.Wait()
+
+	result := run.result()
+	if b.onProgress != nil {
+		b.onProgress(result.Progress)
+	}
+	return result, srcErr
+}
+
+// errSkipped marks documents already at the target version
+var errSkipped = errors.New("already at target version")
+
+// migrate brings one document up to the target version and writes it to sink
+func (b *Backfiller) migrate(ctx context.Context, doc Document, sink Sink) error {
+	if doc.Version == nil {
+		return fmt.Errorf("document has no version")
+	}
+	if doc.Version.Equal(b.target) {
+		return errSkipped
+	}
+	if doc.Version.IsNewerThan(b.target) {
+		return fmt.Errorf("version %s is newer than target %s", doc.Version, b.target)
+	}
+	migrated, err := b.epoch.Transform(ctx, b.typ, epoch.DirectionRequest, doc.Version, b.target, doc.Data)
+	if err != nil {
+		return err
+	}
+	doc.Version = b.target
+	doc.Data = migrated
+	if err := sink(ctx, doc); err != nil {
+		return fmt.Errorf("failed to write document: %w", err)
+	}
+	return nil
+}
+
+// run accumulates the outcome of a Run across workers
+type run struct {
+	*Backfiller
+	started  time.Time
+	mu       sync.Mutex
+	progress Progress
+	buckets  map[string]*Bucket
+}
+
+// record counts one processed document and reports progress when due
+func (r *run) record(doc Document, err error) {
+	r.mu.Lock()
+	r.progress.Processed++
+	switch {
+	case err == nil:
+		r.progress.Migrated++
+	case errors.Is(err, errSkipped):
+		r.progress.Skipped++
+	default:
+		r.progress.Failed++
+		reason := r.bucket(err)
+		bucket, ok := r.buckets[reason]
+		if !ok {
+			bucket = &Bucket{Reason: reason}
+			r.buckets[reason] = bucket
+		}
+		bucket.Count++
+		if len(bucket.Examples) < maxExamples {
+			version := ""
+			if doc.Version != nil {
+				version = doc.Version.String()
+			}
+			bucket.Examples = append(bucket.Examples, Failure{ID: doc.ID, Version: version, Err: err})
+		}
+	}
+	var snapshot *Progress
+	if r.onProgress != nil && r.progressEvery > 0 && r.progress.Processed%r.progressEvery == 0 {
+		p := r.progress
+		p.Elapsed = time.Since(r.started)
+		snapshot = &p
+	}
+	r.mu.Unlock()
+
+	if snapshot != nil {
+		r.onProgress(*snapshot)
+	}
+}
+
+// result snapshots the final counts and sorts the buckets
+func (r *run) result() *Result {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := &Result{Progress: r.progress}
+	result.Elapsed = time.Since(r.started)
+	for _, bucket := range r.buckets {
+		result.Buckets = append(result.Buckets, bucket)
+	}
+	sort.Slice(result.Buckets, func(i, j int) bool {
+		if result.Buckets[i].Count != result.Buckets[j].Count {
+			return result.Buckets[i].Count > result.Buckets[j].Count
+		}
+		return result.Buckets[i].Reason < result.Buckets[j].Reason
+	})
+	return result
+}
+
+// rootCause buckets failures by their innermost error message
+func rootCause(err error) string {
+	for {
+		inner := errors.Unwrap(err)
+		if inner == nil {
+			return err.Error()
+		}
+		err = inner
+	}
+}
@@ -0,0 +1,161 @@
+package backfill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/astronomer/epoch/epoch"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type storedEvent struct {
+	ID    int    `json:"id"`
+	Email string `json:"email"`
+}
+
+var _ = Describe("Backfiller", func() {
+	var (
+		epochInstance *epoch.Epoch
+		v1, v2        *epoch.Version
+		mu            sync.Mutex
+		written       map[string]string
+		sink          Sink
+	)
+
+	BeforeEach(func() {
+		v1, _ = epoch.NewDateVersion("2024-01-01")
+		v2, _ = epoch.NewDateVersion("2024-06-01")
+		change := epoch.NewVersionChangeBuilder(v1, v2).
+			ForType(storedEvent{}).
+			RequestToNextVersion().
+			RenameField("mail", "email").
+			Build()
+		var err error
+		epochInstance, err = epoch.NewEpoch().WithVersions(v1, v2).WithHeadVersion().WithChanges(change).Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		written = make(map[string]string)
+		sink = func(ctx context.Context, doc Document) error {
+			mu.Lock()
+			defer mu.Unlock()
+			written[doc.ID] = string(doc.Data)
+			return nil
+		}
+	})
+
+	newBackfiller := func() *Backfiller {
+		return New(epochInstance, reflect.TypeOf(storedEvent{}))
+	}
+
+	It("should bring old documents up to HEAD concurrently", func() {
+		docs := make([]Document, 50)
+		for i := range docs {
+			docs[i] = Document{ID: fmt.Sprint(i), Version: v1, Data: []byte(fmt.Sprintf(`{"id":%d,"mail":"u%d@x.io"}`, i, i))}
+		}
+
+		result, err := newBackfiller().WithConcurrency(8).Run(context.Background(), FromSlice(docs...), sink)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Processed).To(Equal(50))
+		Expect(result.Migrated).To(Equal(50))
+		Expect(result.Buckets).To(BeEmpty())
+		Expect(written).To(HaveLen(50))
+		Expect(written["7"]).To(Equal(`{"id":7,"email":"u7@x.io"}`))
+	})
+
+	It("should skip documents already at the target version", func() {
+		head := epochInstance.VersionBundle().GetHeadVersion()
+		result, err := newBackfiller().Run(context.Background(), FromSlice(
+			Document{ID: "a", Version: head, Data: []byte(`{"email":"a@x.io"}`)},
+			Document{ID: "b", Version: v1, Data: []byte(`{"mail":"b@x.io"}`)},
+		), sink)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Skipped).To(Equal(1))
+		Expect(result.Migrated).To(Equal(1))
+		Expect(written).To(HaveKey("b"))
+		Expect(written).NotTo(HaveKey("a"))
+	})
+
+	It("should migrate up to a chosen target", func() {
+		result, err := newBackfiller().WithTarget(v2).Run(context.Background(), FromSlice(
+			Document{ID: "a", Version: v1, Data: []byte(`{"mail":"a@x.io"}`)},
+		), sink)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Migrated).To(Equal(1))
+		Expect(written["a"]).To(Equal(`{"email":"a@x.io"}`))
+	})
+
+	It("should bucket failures by cause, largest bucket first", func() {
+		errQuota := errors.New("write quota exceeded")
+		failingSink := func(ctx context.Context, doc Document) error {
+			if doc.ID == "quota-1" || doc.ID == "quota-2" {
+				return errQuota
+			}
+			return sink(ctx, doc)
+		}
+
+		result, err := newBackfiller().Run(context.Background(), FromSlice(
+			Document{ID: "quota-1", Version: v1, Data: []byte(`{"mail":"a@x.io"}`)},
+			Document{ID: "quota-2", Version: v1, Data: []byte(`{"mail":"b@x.io"}`)},
+			Document{ID: "unversioned", Data: []byte(`{}`)},
+			Document{ID: "ok", Version: v1, Data: []byte(`{"mail":"c@x.io"}`)},
+		), failingSink)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Failed).To(Equal(3))
+		Expect(result.Migrated).To(Equal(1))
+		Expect(result.Buckets).To(HaveLen(2))
+
+		Expect(result.Buckets[0].Reason).To(Equal("write quota exceeded"))
+		Expect(result.Buckets[0].Count).To(Equal(2))
+		Expect(result.Buckets[0].Examples[0].Version).To(Equal("2024-01-01"))
+		Expect(result.Buckets[0].Examples[0].Err).To(MatchError(errQuota))
+		Expect(result.Buckets[1].Reason).To(Equal("document has no version"))
+	})
+
+	It("should group failures with a custom bucketer", func() {
+		result, err := newBackfiller().
+			WithBucketer(func(err error) string { return "all" }).
+			Run(context.Background(), FromSlice(
+				Document{ID: "a", Data: []byte(`{}`)},
+				Document{ID: "b", Version: v1, Data: []byte(`{"mail":`)},
+			), sink)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Buckets).To(HaveLen(1))
+		Expect(result.Buckets[0].Count).To(Equal(2))
+	})
+
+	It("should report progress periodically and at the end", func() {
+		ch := make(chan Document)
+		go func() {
+			defer close(ch)
+			for i := 0; i < 5; i++ {
+				ch <- Document{ID: fmt.Sprint(i), Version: v1, Data: []byte(`{"mail":"a@x.io"}`)}
+			}
+		}()
+
+		var reports []int
+		_, err := newBackfiller().
+			OnProgress(2, func(p Progress) { reports = append(reports, p.Processed) }).
+			Run(context.Background(), FromChannel(ch), sink)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reports).To(Equal([]int{2, 4, 5}))
+	})
+
+	It("should stop and return the partial result when the source fails", func() {
+		calls := 0
+		src := SourceFunc(func(ctx context.Context) (Document, error) {
+			calls++
+			if calls > 2 {
+				return Document{}, errors.New("connection reset")
+			}
+			return Document{ID: fmt.Sprint(calls), Version: v1, Data: []byte(`{"mail":"a@x.io"}`)}, nil
+		})
+
+		result, err := newBackfiller().Run(context.Background(), src, sink)
+		Expect(err).To(MatchError("failed to read source: connection reset"))
+		Expect(result.Processed).To(Equal(2))
+	})
+})
@@ -0,0 +1,56 @@
+package backfill
+
+import (
+	"context"
+	"io"
+
+	"github.com/astronomer/epoch/epoch"
+)
+
+// Document is a stored JSON document and the version it was written under
+type Document struct {
+	ID      string
+	Version *epoch.Version
+	Data    []byte
+}
+
+// Source yields the documents to backfill. Next returns io.EOF once exhausted;
+// any other error aborts the run.
+type Source interface {
+	Next(ctx context.Context) (Document, error)
+}
+
+// SourceFunc adapts a function to a Source
+type SourceFunc func(ctx context.Context) (Document, error)
+
+// Next calls f(ctx)
+func (f SourceFunc) Next(ctx context.Context) (Document, error) {
+	return f(ctx)
+}
+
+// FromChannel reads documents from ch until it is closed
+func FromChannel(ch <-chan Document) Source {
+	return SourceFunc(func(ctx context.Context) (Document, error) {
+		select {
+		case doc, ok := <-ch:
+			if !ok {
+				return Document{}, io.EOF
+			}
+			return doc, nil
+		case <-ctx.Done():
+			return Document{}, ctx.Err()
+		}
+	})
+}
+
+// FromSlice yields docs in order
+func FromSlice(docs ...Document) Source {
+	i := 0
+	return SourceFunc(func(ctx context.Context) (Document, error) {
+		if i >= len(docs) {
+			return Document{}, io.EOF
+		}
+		i++
+		return docs[i-1], nil
+	})
+}
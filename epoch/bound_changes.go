@@ -0,0 +1,52 @@
+package epoch
+
+import (
+	"context"
+	"fmt"
+)
+
+// WithChanges binds changes directly to this endpoint: only they migrate its bodies, instead
+// of every change in the Epoch's chain whose types the endpoint uses. Behavior no longer
+// depends on changes added elsewhere, and each request considers only these changes.
+// Bound changes needn't be registered with the Epoch or cover every version.
+func (hw *HandlerWrapper) WithChanges(changes ...*VersionChange) *HandlerWrapper {
+	hw.changes = append(hw.changes, changes...)
+	return hw
+}
+
+// migrationChain returns the chain migrating the endpoint: its bound changes, or the Epoch's.
+// Bound changes apply their ForTypesMatching operations to the endpoint's types up front.
+func (hw *HandlerWrapper) migrationChain(def *EndpointDefinition) *MigrationChain {
+	if len(hw.changes) == 0 {
+		return hw.epoch.migrationChain
+	}
+	chain, err := NewMigrationChain(hw.changes)
+	if err != nil {
+		panic(fmt.Sprintf("epoch: invalid changes bound to %s %s: %v", def.Method, def.PathPattern, err))
+	}
+	types := def.types()
+	for _, change := range hw.changes {
+		change.BindTypes(types...)
+	}
+	chain.sparse = true
+	return chain
+}
+
+// migrateResponseThroughChanges applies, newest first, every change between to and from
+// Unlike the step-by-step walk in MigrateResponse, it doesn't need contiguous versions.
+func migrateResponseThroughChanges(ctx context.Context, responseInfo *ResponseInfo, changes []*VersionChange, from, to *Version) error {
+	for i := len(changes) - 1; i >= 0; i-- {
+		change := changes[i]
+		if change.FromVersion().IsOlderThan(to) || change.ToVersion().IsNewerThan(from) {
+			continue
+		}
+		if err := checkMigrationContext(ctx); err != nil {
+			return err
+		}
+		if err := change.MigrateResponse(ctx, responseInfo); err != nil {
+			return fmt.Errorf("reverse migration failed at %s->%s: %w",
+				change.ToVersion().String(), change.FromVersion().String(), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package epoch
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type boundWidget struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+	Color string `json:"color"`
+}
+
+var _ = Describe("Changes bound to an endpoint", func() {
+	var v1, v2, v3 *Version
+	var renameTitle, removeColor *VersionChange
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		v1, _ = NewDateVersion("2024-01-01")
+		v2, _ = NewDateVersion("2024-06-01")
+		v3, _ = NewDateVersion("2025-01-01")
+
+		renameTitle = NewVersionChangeBuilder(v1, v2).
+			Description("Rename name to title").
+			ForType(boundWidget{}).
+			RequestToNextVersion().
+			RenameField("name", "title").
+			ResponseToPreviousVersion().
+			RenameField("title", "name").
+			Build()
+		removeColor = NewVersionChangeBuilder(v2, v3).
+			Description("Add color").
+			ForType(boundWidget{}).
+			ResponseToPreviousVersion().
+			RemoveField("color").
+			Build()
+	})
+
+	serve := func(epochInstance *Epoch, register func(*gin.Engine), version, path, body string) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.Use(epochInstance.Middleware())
+		register(router)
+
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set("X-API-Version", version)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	It("should migrate the endpoint with its bound changes only", func() {
+		epochInstance, err := NewEpoch().WithVersions(v1, v2, v3).WithHeadVersion().
+			WithChanges(renameTitle, removeColor).
+			Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		var received boundWidget
+		handler := func(c *gin.Context) {
+			Expect(c.ShouldBindJSON(&received)).To(Succeed())
+			c.JSON(200, boundWidget{ID: 1, Title: received.Title, Color: "red"})
+		}
+		register := func(router *gin.Engine) {
+			router.POST("/widgets", epochInstance.WrapHandler(handler).
+				Accepts(boundWidget{}).Returns(boundWidget{}).ToHandlerFunc("POST", "/widgets"))
+			router.POST("/legacy-widgets", epochInstance.WrapHandler(handler).
+				Accepts(boundWidget{}).Returns(boundWidget{}).
+				WithChanges(renameTitle).
+				ToHandlerFunc("POST", "/legacy-widgets"))
+		}
+
+		recorder := serve(epochInstance, register, "2024-01-01", "/widgets", `{"name":"Box"}`)
+		Expect(recorder.Body.String()).To(MatchJSON(`{"id":1,"name":"Box"}`))
+
+		// removeColor isn't bound, so color stays even though the type matches
+		recorder = serve(epochInstance, register, "2024-01-01", "/legacy-widgets", `{"name":"Box"}`)
+		Expect(recorder.Code).To(Equal(200))
+		Expect(received.Title).To(Equal("Box"))
+		Expect(recorder.Body.String()).To(MatchJSON(`{"id":1,"name":"Box","color":"red"}`))
+	})
+
+	It("should skip versions no bound change connects", func() {
+		epochInstance, err := NewEpoch().WithVersions(v1, v2, v3).WithHeadVersion().
+			WithChanges(renameTitle, removeColor).
+			Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		register := func(router *gin.Engine) {
+			router.POST("/widgets", epochInstance.WrapHandler(func(c *gin.Context) {
+				c.JSON(200, boundWidget{ID: 1, Title: "Box", Color: "red"})
+			}).Returns(boundWidget{}).WithChanges(removeColor).ToHandlerFunc("POST", "/widgets"))
+		}
+
+		recorder := serve(epochInstance, register, "2024-01-01", "/widgets", ``)
+		Expect(recorder.Code).To(Equal(200))
+		Expect(recorder.Body.String()).To(MatchJSON(`{"id":1,"title":"Box"}`))
+
+		recorder = serve(epochInstance, register, "2025-01-01", "/widgets", ``)
+		Expect(recorder.Body.String()).To(MatchJSON(`{"id":1,"title":"Box","color":"red"}`))
+	})
+
+	It("should bind the endpoint's types to bound ForTypesMatching changes", func() {
+		epochInstance, err := NewEpoch().WithVersions(v1, v2).WithHeadVersion().Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		dropColor := NewVersionChangeBuilder(v1, v2).
+			Description("Add color to widgets").
+			ForTypesMatching(func(t reflect.Type) bool { return strings.HasSuffix(t.Name(), "Widget") }).
+			ResponseToPreviousVersion().
+			RemoveField("color").
+			Build()
+		register := func(router *gin.Engine) {
+			router.POST("/widgets", epochInstance.WrapHandler(func(c *gin.Context) {
+				c.JSON(200, boundWidget{ID: 1, Title: "Box", Color: "red"})
+			}).Returns(boundWidget{}).WithChanges(dropColor).ToHandlerFunc("POST", "/widgets"))
+		}
+
+		register(gin.New())
+		entries := dropColor.ChangelogEntries()
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Type).To(Equal(reflect.TypeOf(boundWidget{})))
+
+		recorder := serve(epochInstance, register, "2024-01-01", "/widgets", ``)
+		Expect(recorder.Body.String()).To(MatchJSON(`{"id":1,"title":"Box"}`))
+	})
+
+	It("should reject bound changes that form a cycle", func() {
+		epochInstance, err := NewEpoch().WithVersions(v1, v2).WithHeadVersion().Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		backwards := NewVersionChange("Backwards", v2, v1, &AlterResponseInstruction{Transformer: func(*ResponseInfo) error { return nil }})
+		Expect(func() {
+			epochInstance.WrapHandler(func(c *gin.Context) {}).
+				WithChanges(renameTitle, backwards).
+				ToHandlerFunc("GET", "/cycle")
+		}).To(PanicWith(ContainSubstring("invalid changes bound to GET /cycle")))
+	})
+})
@@ -0,0 +1,230 @@
+package epoch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Canary renders a sample of HEAD responses in the latest stable version too, and reports
+// which fields differ, so the shapes the next version will freeze can be watched in
+// production before it's cut
+type Canary struct {
+	// SampleRate is the fraction (0-1] of HEAD requests compared
+	SampleRate float64
+
+	// Version is the version responses are rendered in; nil means the newest released version
+	Version *Version
+
+	// Report receives every sampled comparison once the response was written. It runs on the
+	// request's goroutine, so it should only record metrics.
+	Report func(c *gin.Context, report CanaryReport)
+
+	// unreleased reports versions still behind a preview header (see WithUnreleasedVersion)
+	unreleased func(*Version) bool
+}
+
+// CanaryDifferenceKind says how a field differs between HEAD and the stable version
+type CanaryDifferenceKind string
+
+const (
+	CanaryFieldAdded   CanaryDifferenceKind = "added"   // Only HEAD has the field
+	CanaryFieldRemoved CanaryDifferenceKind = "removed" // Only the stable version has the field
+	CanaryFieldChanged CanaryDifferenceKind = "changed" // Both have it, with different values
+)
+
+// CanaryDifference is a field that differs. Array indices in Path are collapsed to [], so
+// paths are stable metric labels; values aren't reported.
+type CanaryDifference struct {
+	Path string
+	Kind CanaryDifferenceKind
+}
+
+// CanaryReport is one sampled comparison
+type CanaryReport struct {
+	Method      string   // Endpoint method
+	Path        string   // Endpoint route pattern
+	Version     *Version // Version the response was rendered in
+	Differences []CanaryDifference
+	Err         error // Set when the stable rendering failed
+}
+
+// WithCanary compares a sample of HEAD responses with their rendering in the latest stable
+// version and reports field-level differences (see Canary)
+func (cb *EpochBuilder) WithCanary(canary Canary) *EpochBuilder {
+	if canary.SampleRate <= 0 || canary.SampleRate > 1 || canary.Report == nil {
+		cb.errors = append(cb.errors, errors.New("canary needs a sample rate in (0, 1] and a Report function"))
+		return cb
+	}
+	if canary.Version != nil && canary.Version.IsHead {
+		cb.errors = append(cb.errors, errors.New("canary version must not be head"))
+		return cb
+	}
+	cb.canary = &canary
+	return cb
+}
+
+// WithCanary compares a sample of this handler's HEAD responses with their stable rendering
+func (vah *VersionAwareHandler) WithCanary(canary Canary) *VersionAwareHandler {
+	vah.canary = &canary
+	return vah
+}
+
+// sampleCanary decides whether a HEAD request is compared
+func (vah *VersionAwareHandler) sampleCanary() bool {
+	return vah.canary != nil && (vah.canary.SampleRate >= 1 || rand.Float64() < vah.canary.SampleRate)
+}
+
+// canaryVersion returns the version sampled responses are rendered in, or nil if there's none
+func (vah *VersionAwareHandler) canaryVersion() *Version {
+	if vah.canary.Version != nil {
+		return vah.canary.Version
+	}
+	var newest *Version
+	for _, v := range vah.versionBundle.GetVersions() {
+		if v.IsHead || (vah.canary.unreleased != nil && vah.canary.unreleased(v)) {
+			continue
+		}
+		if newest == nil || v.IsNewerThan(newest) {
+			newest = v
+		}
+	}
+	return newest
+}
+
+// canaryWriter writes through to the client while keeping a copy of the body
+type canaryWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *canaryWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *canaryWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// serveCanary serves a HEAD request unchanged, then reports how its successful JSON response
+// differs from the stable version's rendering
+func (vah *VersionAwareHandler) serveCanary(c *gin.Context) {
+	stable := vah.canaryVersion()
+	endpointDef, err := vah.endpointRegistry.Lookup(c.Request.Method, vah.stripVersionPrefix(c.Request.URL.Path))
+	if stable == nil || err != nil {
+		vah.handler(c)
+		return
+	}
+
+	writer := &canaryWriter{ResponseWriter: c.Writer}
+	c.Writer = writer
+	defer func() { c.Writer = writer.ResponseWriter }()
+	vah.handler(c)
+
+	if c.Writer.Status() >= http.StatusBadRequest || writer.body.Len() == 0 {
+		return
+	}
+	report := CanaryReport{Method: endpointDef.Method, Path: endpointDef.PathPattern, Version: stable}
+	rendered, err := vah.renderCanary(c, writer.body.Bytes(), endpointDef.ResponseType, stable)
+	if err != nil {
+		report.Err = err
+	} else {
+		report.Differences, report.Err = diffCanaryBodies(writer.body.Bytes(), rendered)
+	}
+	vah.canary.Report(c, report)
+}
+
+// renderCanary migrates a HEAD response body down to version, like a client of it would get
+func (vah *VersionAwareHandler) renderCanary(c *gin.Context, body []byte, responseType reflect.Type, version *Version) ([]byte, error) {
+	node, err := vah.jsonEngine.Parse(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	info := NewResponseInfo(c, node)
+	info.Headers = c.Writer.Header().Clone()
+	ctx, cancel := vah.migrationContext(c)
+	defer cancel()
+	opts := MigrateOptions{From: vah.versionBundle.GetHeadVersion(), To: version, Type: responseType}
+	if err := vah.migrationChain.Migrate(ctx, info, opts); err != nil {
+		return nil, fmt.Errorf("failed to render response for %s: %w", version, err)
+	}
+	return vah.jsonEngine.Serialize(info.Body)
+}
+
+// canaryIndexPattern matches array indices in a body path
+var canaryIndexPattern = regexp.MustCompile(`\[\d+\]`)
+
+// diffCanaryBodies lists the fields that differ between two JSON bodies, once per path
+func diffCanaryBodies(head, stable []byte) ([]CanaryDifference, error) {
+	var headValue, stableValue interface{}
+	if err := json.Unmarshal(head, &headValue); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if err := json.Unmarshal(stable, &stableValue); err != nil {
+		return nil, fmt.Errorf("failed to decode rendered response: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var differences []CanaryDifference
+	add := func(path string, kind CanaryDifferenceKind) {
+		path = canaryIndexPattern.ReplaceAllString(path, "[]")
+		if !seen[path] {
+			seen[path] = true
+			differences = append(differences, CanaryDifference{Path: path, Kind: kind})
+		}
+	}
+	diffCanaryValues("", headValue, stableValue, add)
+	sort.Slice(differences, func(i, j int) bool { return differences[i].Path < differences[j].Path })
+	return differences, nil
+}
+
+// joinCanaryPath appends an object key to a body path
+func joinCanaryPath(path, key string) string {
+	if path == "" {
+		return EscapePathKey(key)
+	}
+	return path + "." + EscapePathKey(key)
+}
+
+// diffCanaryValues walks two decoded JSON values, reporting every field that differs
+func diffCanaryValues(path string, head, stable interface{}, add func(string, CanaryDifferenceKind)) {
+	switch headValue := head.(type) {
+	case map[string]interface{}:
+		if stableValue, ok := stable.(map[string]interface{}); ok {
+			for key, headChild := range headValue {
+				stableChild, exists := stableValue[key]
+				if !exists {
+					add(joinCanaryPath(path, key), CanaryFieldAdded)
+					continue
+				}
+				diffCanaryValues(joinCanaryPath(path, key), headChild, stableChild, add)
+			}
+			for key := range stableValue {
+				if _, exists := headValue[key]; !exists {
+					add(joinCanaryPath(path, key), CanaryFieldRemoved)
+				}
+			}
+			return
+		}
+	case []interface{}:
+		if stableValue, ok := stable.([]interface{}); ok && len(stableValue) == len(headValue) {
+			for i := range headValue {
+				diffCanaryValues(fmt.Sprintf("%s[%d]", path, i), headValue[i], stableValue[i], add)
+			}
+			return
+		}
+	}
+	if !reflect.DeepEqual(head, stable) {
+		add(path, CanaryFieldChanged)
+	}
+}
@@ -0,0 +1,129 @@
+package epoch
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type canaryItem struct {
+	Name string `json:"name"`
+}
+
+type canaryOrder struct {
+	ID     int          `json:"id"`
+	Status string       `json:"status"`
+	Total  int          `json:"total"`
+	Items  []canaryItem `json:"items"`
+}
+
+var _ = Describe("Canary", func() {
+	var (
+		v1, v2, v3 *Version
+		reports    []CanaryReport
+		status     int
+	)
+
+	BeforeEach(func() {
+		v1, _ = NewDateVersion("2024-01-01")
+		v2, _ = NewDateVersion("2025-01-01")
+		v3, _ = NewDateVersion("2099-01-01")
+		reports = nil
+		status = http.StatusOK
+	})
+
+	newRouter := func(canary Canary) *gin.Engine {
+		canary.Report = func(c *gin.Context, report CanaryReport) {
+			reports = append(reports, report)
+		}
+		change := NewVersionChangeBuilder(v2, v3).
+			ForType(canaryOrder{}).
+			ResponseToPreviousVersion().
+			RemoveField("total").
+			RenameField("status", "state").
+			ForType(canaryItem{}).
+			ResponseToPreviousVersion().
+			RenameField("name", "title").
+			Build()
+		epochInstance, err := NewEpoch().WithVersions(v1, v2, v3).WithHeadVersion().
+			WithUnreleasedVersion(v3, "X-API-Preview").WithChanges(change).WithCanary(canary).Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.Use(epochInstance.Middleware())
+		router.GET("/orders", epochInstance.WrapHandler(func(c *gin.Context) {
+			c.JSON(status, canaryOrder{ID: 1, Status: "open", Total: 3, Items: []canaryItem{{Name: "a"}, {Name: "b"}}})
+		}).Returns(canaryOrder{}).ToHandlerFunc("GET", "/orders"))
+		return router
+	}
+
+	get := func(router *gin.Engine, version string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+		req.Header.Set("X-API-Version", version)
+		req.Header.Set("X-API-Preview", "partner")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	It("should report fields that differ from the newest released version", func() {
+		router := newRouter(Canary{SampleRate: 1})
+
+		recorder := get(router, "head")
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Body.String()).To(MatchJSON(`{"id":1,"status":"open","total":3,"items":[{"name":"a"},{"name":"b"}]}`))
+
+		Expect(reports).To(HaveLen(1))
+		Expect(reports[0].Err).NotTo(HaveOccurred())
+		Expect(reports[0].Method).To(Equal("GET"))
+		Expect(reports[0].Path).To(Equal("/orders"))
+		Expect(reports[0].Version).To(Equal(v2))
+		Expect(reports[0].Differences).To(Equal([]CanaryDifference{
+			{Path: "items[].name", Kind: CanaryFieldAdded},
+			{Path: "items[].title", Kind: CanaryFieldRemoved},
+			{Path: "state", Kind: CanaryFieldRemoved},
+			{Path: "status", Kind: CanaryFieldAdded},
+			{Path: "total", Kind: CanaryFieldAdded},
+		}))
+	})
+
+	It("should compare against a configured version", func() {
+		router := newRouter(Canary{SampleRate: 1, Version: v3})
+		get(router, "head")
+
+		Expect(reports).To(HaveLen(1))
+		Expect(reports[0].Err).NotTo(HaveOccurred())
+		Expect(reports[0].Version).To(Equal(v3))
+		Expect(reports[0].Differences).To(BeEmpty())
+	})
+
+	It("should only sample HEAD requests", func() {
+		router := newRouter(Canary{SampleRate: 1})
+		Expect(get(router, "2025-01-01").Body.String()).To(MatchJSON(`{"id":1,"state":"open","items":[{"title":"a"},{"title":"b"}]}`))
+		Expect(reports).To(BeEmpty())
+	})
+
+	It("should skip error responses", func() {
+		status = http.StatusInternalServerError
+		router := newRouter(Canary{SampleRate: 1})
+		get(router, "head")
+		Expect(reports).To(BeEmpty())
+	})
+
+	It("should reject invalid configuration", func() {
+		_, err := NewEpoch().WithVersions(v1).WithHeadVersion().WithCanary(Canary{SampleRate: 1}).Build()
+		Expect(err).To(HaveOccurred())
+
+		_, err = NewEpoch().WithVersions(v1).WithHeadVersion().
+			WithCanary(Canary{SampleRate: 2, Report: func(*gin.Context, CanaryReport) {}}).Build()
+		Expect(err).To(HaveOccurred())
+
+		_, err = NewEpoch().WithVersions(v1).WithHeadVersion().
+			WithCanary(Canary{SampleRate: 1, Version: NewHeadVersion(), Report: func(*gin.Context, CanaryReport) {}}).Build()
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,104 @@
+package epoch
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// PrunedChange is a change Build() dropped because none of the types it targets are registered
+type PrunedChange struct {
+	Change   string         // Description of the change
+	From, To *Version       // Versions the change connects
+	Types    []reflect.Type // Types the change targets with ForType
+}
+
+// WithChangePruning drops changes that only target types outside the WithTypes types and their
+// nested types, for binaries that serve a subset of a large API: requests then don't consider
+// them, and their instructions aren't kept in memory. An empty change takes each dropped
+// change's place, so the versions it connected stay connected. Changes with global, envelope,
+// query or ForTypesMatching instructions are always kept. Epoch.PrunedChanges reports what
+// was dropped. Build() fails without WithTypes types, and endpoints whose request or response
+// types (nested types included) lost changes fail to wire: ToHandlerFunc panics and
+// RegisterEndpoint returns an error, instead of serving unmigrated bodies to older clients.
+func (cb *EpochBuilder) WithChangePruning() *EpochBuilder {
+	cb.pruneChanges = true
+	return cb
+}
+
+// PrunedChanges returns the changes WithChangePruning dropped at Build(), oldest first
+func (c *Epoch) PrunedChanges() []PrunedChange {
+	return append([]PrunedChange(nil), c.prunedChanges...)
+}
+
+// prunedChainChanges returns the changes to build the chain from, with placeholders in place of
+// the changes that only target unregistered types, and the changes it replaced
+func (cb *EpochBuilder) prunedChainChanges(types []reflect.Type) ([]*VersionChange, []PrunedChange, error) {
+	if !cb.pruneChanges {
+		return cb.changes, nil, nil
+	}
+	if len(types) == 0 {
+		return nil, nil, errors.New("change pruning requires types registered with WithTypes")
+	}
+	known := make(map[reflect.Type]bool, len(types))
+	for _, t := range types {
+		known[t] = true
+	}
+
+	changes := make([]*VersionChange, 0, len(cb.changes))
+	var pruned []PrunedChange
+	for _, change := range cb.changes {
+		if !change.targetsNoneOf(known) {
+			changes = append(changes, change)
+			continue
+		}
+		placeholder := NewVersionChange(change.Description(), change.FromVersion(), change.ToVersion())
+		placeholder.isHiddenFromChangelog = change.IsHiddenFromChangelog()
+		changes = append(changes, placeholder)
+		pruned = append(pruned, PrunedChange{
+			Change: change.Description(),
+			From:   change.FromVersion(),
+			To:     change.ToVersion(),
+			Types:  change.DeclaredTypes(),
+		})
+	}
+	sort.SliceStable(pruned, func(i, j int) bool { return pruned[i].From.IsOlderThan(pruned[j].From) })
+	return changes, pruned, nil
+}
+
+// checkPrunedTypes reports an endpoint whose request or response types, nested types included,
+// are targeted by a change WithChangePruning dropped
+func (c *Epoch) checkPrunedTypes(def *EndpointDefinition) error {
+	if len(c.prunedChanges) == 0 {
+		return nil
+	}
+	used := make(map[reflect.Type]bool)
+	for _, t := range def.types() {
+		used[t] = true
+	}
+	for _, pruned := range c.prunedChanges {
+		for _, t := range pruned.Types {
+			if used[t] {
+				return fmt.Errorf("%s %s uses %s, but change pruning dropped its change '%s'; register the type with WithTypes",
+					def.Method, def.PathPattern, t, pruned.Change)
+			}
+		}
+	}
+	return nil
+}
+
+// targetsNoneOf reports whether the change only has ForType instructions, for none of the known types
+func (vc *VersionChange) targetsNoneOf(known map[reflect.Type]bool) bool {
+	if len(vc.globalRequestInstructions) > 0 || len(vc.globalResponseInstructions) > 0 ||
+		len(vc.responseEnvelopeOps) > 0 || len(vc.requestQueryOps) > 0 || len(vc.requestPathParamOps) > 0 ||
+		len(vc.typeMatchers) > 0 || len(vc.declaredTypes) == 0 {
+		return false
+	}
+	for _, t := range vc.declaredTypes {
+		if known[t] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,122 @@
+package epoch
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type prunedAccount struct {
+	ID       int    `json:"id"`
+	FullName string `json:"full_name"`
+}
+
+type prunedInvoice struct {
+	ID    int `json:"id"`
+	Total int `json:"total"`
+}
+
+var _ = Describe("Change pruning", func() {
+	var (
+		v1, v2, v3             *Version
+		accountChange, invoice *VersionChange
+	)
+
+	BeforeEach(func() {
+		v1, _ = NewDateVersion("2024-01-01")
+		v2, _ = NewDateVersion("2024-06-01")
+		v3, _ = NewDateVersion("2025-01-01")
+		accountChange = NewVersionChangeBuilder(v1, v2).
+			Description("Rename account name").
+			ForType(prunedAccount{}).
+			ResponseToPreviousVersion().
+			RenameField("full_name", "name").
+			Build()
+		invoice = NewVersionChangeBuilder(v2, v3).
+			Description("Rename invoice total").
+			ForType(prunedInvoice{}).
+			ResponseToPreviousVersion().
+			RenameField("total", "amount").
+			Build()
+	})
+
+	It("should drop changes that only target unregistered types", func() {
+		epochInstance, err := NewEpoch().WithVersions(v1, v2, v3).WithHeadVersion().
+			WithChanges(accountChange, invoice).WithTypes(prunedAccount{}).WithChangePruning().Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(epochInstance.PrunedChanges()).To(Equal([]PrunedChange{{
+			Change: "Rename invoice total",
+			From:   v2,
+			To:     v3,
+			Types:  []reflect.Type{reflect.TypeOf(prunedInvoice{})},
+		}}))
+		Expect(epochInstance.GetMigrationChain().GetChanges()).To(HaveLen(2))
+		Expect(epochInstance.GetMigrationChain().GetChanges()).NotTo(ContainElement(invoice))
+
+		// Responses still migrate across the versions the dropped change connected
+		migrated, err := epochInstance.Transform(context.Background(), reflect.TypeOf(prunedAccount{}), DirectionResponse,
+			epochInstance.GetHeadVersion(), v1, []byte(`{"id":1,"full_name":"Ada"}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(migrated)).To(MatchJSON(`{"id":1,"name":"Ada"}`))
+
+		migrated, err = epochInstance.Transform(context.Background(), reflect.TypeOf(prunedInvoice{}), DirectionResponse,
+			epochInstance.GetHeadVersion(), v1, []byte(`{"id":1,"total":3}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(migrated)).To(MatchJSON(`{"id":1,"total":3}`))
+	})
+
+	It("should keep every change without pruning", func() {
+		epochInstance, err := NewEpoch().WithVersions(v1, v2, v3).WithHeadVersion().
+			WithChanges(accountChange, invoice).WithTypes(prunedAccount{}).Build()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(epochInstance.PrunedChanges()).To(BeEmpty())
+		Expect(epochInstance.GetMigrationChain().GetChanges()).To(ContainElement(invoice))
+	})
+
+	It("should keep changes targeting nested registered types", func() {
+		type order struct {
+			Invoice prunedInvoice `json:"invoice"`
+		}
+		epochInstance, err := NewEpoch().WithVersions(v1, v2, v3).WithHeadVersion().
+			WithChanges(accountChange, invoice).WithTypes(order{}).WithChangePruning().Build()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(epochInstance.PrunedChanges()).To(HaveLen(1))
+		Expect(epochInstance.PrunedChanges()[0].Change).To(Equal("Rename account name"))
+	})
+
+	It("should refuse endpoints using types whose changes were dropped", func() {
+		epochInstance, err := NewEpoch().WithVersions(v1, v2, v3).WithHeadVersion().
+			WithChanges(accountChange, invoice).WithTypes(prunedAccount{}).WithChangePruning().
+			WithRuntimeRegistration().Build()
+		Expect(err).NotTo(HaveOccurred())
+		handler := func(c *gin.Context) {}
+
+		Expect(func() {
+			epochInstance.WrapHandler(handler).Returns(prunedAccount{}).ToHandlerFunc("GET", "/accounts/:id")
+		}).NotTo(Panic())
+		Expect(func() {
+			epochInstance.WrapHandler(handler).Returns([]prunedInvoice{}).ToHandlerFunc("GET", "/invoices")
+		}).To(PanicWith(ContainSubstring("change pruning dropped its change 'Rename invoice total'")))
+
+		type statement struct {
+			Invoices []prunedInvoice `json:"invoices"`
+		}
+		err = epochInstance.RegisterEndpoint("GET", "/statements", &EndpointDefinition{ResponseType: reflect.TypeOf(statement{})})
+		Expect(err).To(MatchError(ContainSubstring("GET /statements uses epoch.prunedInvoice")))
+
+		// Endpoints with bound changes don't use the pruned chain
+		Expect(func() {
+			epochInstance.WrapHandler(handler).Returns(prunedInvoice{}).WithChanges(invoice).ToHandlerFunc("GET", "/invoices/:id")
+		}).NotTo(Panic())
+	})
+
+	It("should fail without registered types", func() {
+		_, err := NewEpoch().WithVersions(v1, v2, v3).WithHeadVersion().
+			WithChanges(accountChange, invoice).WithChangePruning().Build()
+		Expect(err).To(MatchError(ContainSubstring("change pruning requires types registered with WithTypes")))
+	})
+})
@@ -0,0 +1,155 @@
+package epoch
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ChangeSet groups VersionChanges published together under a namespace, e.g. a platform
+// package exporting "platform/pagination" for services to import with WithChangeSets
+type ChangeSet struct {
+	namespace string
+	changes   []*VersionChange
+}
+
+// NewChangeSet creates a change set and tags its changes with namespace.
+// A change can only belong to one change set.
+func NewChangeSet(namespace string, changes ...*VersionChange) *ChangeSet {
+	for _, change := range changes {
+		if change.namespace != "" && change.namespace != namespace {
+			panic(fmt.Sprintf("epoch: change '%s' already belongs to change set '%s'", change.Description(), change.namespace))
+		}
+		change.namespace = namespace
+	}
+	return &ChangeSet{namespace: namespace, changes: changes}
+}
+
+// Namespace returns the change set's namespace
+func (s *ChangeSet) Namespace() string {
+	return s.namespace
+}
+
+// Changes returns the change set's changes
+func (s *ChangeSet) Changes() []*VersionChange {
+	return append([]*VersionChange(nil), s.changes...)
+}
+
+// Namespace returns the namespace of the change set the change belongs to, or "" for
+// changes registered directly with WithChanges
+func (vc *VersionChange) Namespace() string {
+	return vc.namespace
+}
+
+// WithChangeSets adds the changes of each set. A set imported more than once (e.g. through
+// two packages) is only added once. Build() fails when changes from different sets, or a
+// set and the service's own changes, touch the same field of the same type in one version.
+func (cb *EpochBuilder) WithChangeSets(sets ...*ChangeSet) *EpochBuilder {
+	for _, set := range sets {
+		for _, change := range set.changes {
+			if !containsChange(cb.changes, change) {
+				cb.changes = append(cb.changes, change)
+			}
+		}
+	}
+	return cb
+}
+
+func containsChange(changes []*VersionChange, change *VersionChange) bool {
+	for _, existing := range changes {
+		if existing == change {
+			return true
+		}
+	}
+	return false
+}
+
+// ChangeSetConflict is a field that changes from different namespaces both touch in one version
+type ChangeSetConflict struct {
+	Version    string       // The version the changes migrate to
+	Type       reflect.Type // The type both changes alter
+	Field      string
+	Namespaces []string // "" stands for the service's own changes
+}
+
+// touchedField identifies a field of a type altered by changes into a version
+type touchedField struct {
+	version string
+	t       reflect.Type
+	field   string
+}
+
+// changeSetConflicts returns the fields touched by changes from more than one namespace
+func changeSetConflicts(changes []*VersionChange) []ChangeSetConflict {
+	namespaces := make(map[touchedField]map[string]bool)
+	var order []touchedField
+	for _, change := range changes {
+		for _, entry := range change.ChangelogEntries() {
+			for _, field := range entry.Doc.touchedFields() {
+				key := touchedField{version: change.ToVersion().String(), t: entry.Type, field: field}
+				if namespaces[key] == nil {
+					namespaces[key] = make(map[string]bool)
+					order = append(order, key)
+				}
+				namespaces[key][change.namespace] = true
+			}
+		}
+	}
+
+	var conflicts []ChangeSetConflict
+	for _, key := range order {
+		if len(namespaces[key]) < 2 {
+			continue
+		}
+		conflict := ChangeSetConflict{Version: key.version, Type: key.t, Field: key.field}
+		for namespace := range namespaces[key] {
+			conflict.Namespaces = append(conflict.Namespaces, namespace)
+		}
+		sort.Strings(conflict.Namespaces)
+		conflicts = append(conflicts, conflict)
+	}
+	return conflicts
+}
+
+// touchedFields lists every field the operation adds, removes or renames (both names)
+func (d OperationDoc) touchedFields() []string {
+	fields := append([]string(nil), d.RemovedFields...)
+	for name := range d.AddedFields {
+		fields = append(fields, name)
+	}
+	for from, to := range d.RenamedFields {
+		fields = append(fields, from, to)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// ChangeSetConflictError lists the fields changed by more than one namespace
+type ChangeSetConflictError struct {
+	Conflicts []ChangeSetConflict
+}
+
+func (e *ChangeSetConflictError) Error() string {
+	problems := make([]string, len(e.Conflicts))
+	for i, conflict := range e.Conflicts {
+		names := make([]string, len(conflict.Namespaces))
+		for j, namespace := range conflict.Namespaces {
+			names[j] = namespace
+			if namespace == "" {
+				names[j] = "(service changes)"
+			}
+		}
+		problems[i] = fmt.Sprintf("%s.%s in version %s is changed by %s",
+			conflict.Type, conflict.Field, conflict.Version, strings.Join(names, ", "))
+	}
+	return "change set conflicts:\n  " + strings.Join(problems, "\n  ")
+}
+
+// checkChangeSetConflicts fails Build() when namespaces collide on a field
+func checkChangeSetConflicts(changes []*VersionChange) error {
+	if conflicts := changeSetConflicts(changes); len(conflicts) > 0 {
+		return &ChangeSetConflictError{Conflicts: conflicts}
+	}
+	return nil
+}
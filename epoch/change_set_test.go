@@ -0,0 +1,119 @@
+package epoch
+
+import (
+	"errors"
+	"net/http/httptest"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type changeSetPage struct {
+	Items      []string `json:"items"`
+	NextCursor string   `json:"next_cursor"`
+	Total      int      `json:"total"`
+}
+
+var _ = Describe("Change sets", func() {
+	var v1, v2 *Version
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		v1, _ = NewDateVersion("2024-01-01")
+		v2, _ = NewDateVersion("2024-06-01")
+	})
+
+	paginationSet := func() *ChangeSet {
+		return NewChangeSet("platform/pagination",
+			NewVersionChangeBuilder(v1, v2).
+				Description("Cursor pagination").
+				ForType(changeSetPage{}).
+				ResponseToPreviousVersion().
+				RenameField("next_cursor", "next_page").
+				Build())
+	}
+
+	It("should tag changes with their namespace and add them once", func() {
+		set := paginationSet()
+		Expect(set.Namespace()).To(Equal("platform/pagination"))
+		Expect(set.Changes()[0].Namespace()).To(Equal("platform/pagination"))
+
+		own := NewVersionChangeBuilder(v1, v2).
+			ForType(changeSetPage{}).
+			ResponseToPreviousVersion().
+			RemoveField("total").
+			Build()
+		Expect(own.Namespace()).To(BeEmpty())
+
+		epochInstance, err := NewEpoch().
+			WithVersions(v1, v2).
+			WithHeadVersion().
+			WithChangeSets(set, set).
+			WithChanges(own).
+			Build()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(epochInstance.migrationChain.GetChanges()).To(HaveLen(2))
+
+		router := gin.New()
+		router.Use(epochInstance.Middleware())
+		router.GET("/items", epochInstance.WrapHandler(func(c *gin.Context) {
+			c.JSON(200, changeSetPage{Items: []string{"a"}, NextCursor: "c2", Total: 1})
+		}).Returns(changeSetPage{}).ToHandlerFunc("GET", "/items"))
+
+		req := httptest.NewRequest("GET", "/items", nil)
+		req.Header.Set("X-API-Version", "2024-01-01")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		Expect(recorder.Body.String()).To(MatchJSON(`{"items":["a"],"next_page":"c2"}`))
+	})
+
+	It("should fail Build when namespaces touch the same field in one version", func() {
+		own := NewVersionChangeBuilder(v1, v2).
+			ForType(changeSetPage{}).
+			ResponseToPreviousVersion().
+			RenameField("next_cursor", "cursor").
+			Build()
+		audit := NewChangeSet("platform/audit",
+			NewVersionChangeBuilder(v1, v2).
+				ForType(changeSetPage{}).
+				ResponseToPreviousVersion().
+				RemoveField("next_cursor").
+				Build())
+
+		_, err := NewEpoch().
+			WithVersions(v1, v2).
+			WithHeadVersion().
+			WithChangeSets(paginationSet(), audit).
+			WithChanges(own).
+			Build()
+
+		var conflictErr *ChangeSetConflictError
+		Expect(errors.As(err, &conflictErr)).To(BeTrue())
+		Expect(conflictErr.Conflicts).To(Equal([]ChangeSetConflict{{
+			Version:    "2024-06-01",
+			Type:       reflect.TypeOf(changeSetPage{}),
+			Field:      "next_cursor",
+			Namespaces: []string{"", "platform/audit", "platform/pagination"},
+		}}))
+		Expect(err.Error()).To(ContainSubstring(
+			"epoch.changeSetPage.next_cursor in version 2024-06-01 is changed by (service changes), platform/audit, platform/pagination"))
+	})
+
+	It("should allow namespaces to touch different fields", func() {
+		totals := NewChangeSet("platform/totals",
+			NewVersionChangeBuilder(v1, v2).
+				ForType(changeSetPage{}).
+				ResponseToPreviousVersion().
+				RemoveField("total").
+				Build())
+		_, err := NewEpoch().WithVersions(v1, v2).WithHeadVersion().WithChangeSets(paginationSet(), totals).Build()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should refuse to put a change in two change sets", func() {
+		set := paginationSet()
+		Expect(func() { NewChangeSet("other", set.Changes()...) }).To(PanicWith(ContainSubstring("already belongs to change set 'platform/pagination'")))
+	})
+})
@@ -0,0 +1,70 @@
+package epoch
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EnabledWhen gates the change behind a runtime toggle evaluated once per request.
+// When the predicate returns false the change is skipped in both directions, so a risky
+// migration can be rolled out gradually or switched off without redeploying.
+//
+// Inside Gin, ctx is the request's *gin.Context, so values set by earlier middleware
+// (e.g. the authenticated account) are available through ctx.Value or a type assertion.
+// Toggled changes are never compiled into response templates.
+func (vc *VersionChange) EnabledWhen(predicate func(ctx context.Context) bool) *VersionChange {
+	vc.enabledWhen = predicate
+	return vc
+}
+
+// IsToggled reports whether the change is gated by EnabledWhen
+func (vc *VersionChange) IsToggled() bool {
+	return vc.enabledWhen != nil
+}
+
+// isEnabled evaluates the toggle, caching the decision on the Gin context so the request,
+// its response and every array item of the same request see the same answer
+func (vc *VersionChange) isEnabled(ctx context.Context, c *gin.Context) bool {
+	if vc.enabledWhen == nil {
+		return true
+	}
+	if c == nil {
+		return vc.enabledWhen(ctx)
+	}
+
+	key := fmt.Sprintf("epoch_change_enabled_%p", vc)
+	if cached, exists := c.Get(key); exists {
+		if enabled, ok := cached.(bool); ok {
+			return enabled
+		}
+	}
+	enabled := vc.enabledWhen(c)
+	c.Set(key, enabled)
+	return enabled
+}
+
+// RolloutPercentage returns an EnabledWhen predicate that enables a change for roughly
+// percent (0-100) of traffic. When key returns a non-empty value (e.g. an account ID) the
+// decision is sticky for that key; otherwise each request is sampled at random.
+func RolloutPercentage(percent float64, key func(ctx context.Context) string) func(ctx context.Context) bool {
+	return func(ctx context.Context) bool {
+		if percent <= 0 {
+			return false
+		}
+		if percent >= 100 {
+			return true
+		}
+		if key != nil {
+			if k := key(ctx); k != "" {
+				h := fnv.New32a()
+				_, _ = h.Write([]byte(k))
+				return float64(h.Sum32()%10000) < percent*100
+			}
+		}
+		return rand.Float64()*100 < percent
+	}
+}
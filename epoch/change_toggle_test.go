@@ -0,0 +1,138 @@
+package epoch
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type toggleAccountKey struct{}
+
+type toggleUser struct {
+	FullName string `json:"full_name"`
+	Nickname string `json:"nickname"`
+}
+
+var _ = Describe("Change toggles", func() {
+	var (
+		v1, v2  *Version
+		enabled atomic.Bool
+		calls   atomic.Int32
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		v1, v2, _ = newTestVersions()
+		enabled.Store(true)
+		calls.Store(0)
+	})
+
+	serve := func(templates bool, body string) string {
+		change := NewVersionChangeBuilder(v1, v2).
+			ForType(toggleUser{}).
+			RequestToNextVersion().
+			RenameField("name", "full_name").
+			ResponseToPreviousVersion().
+			RenameField("full_name", "name").
+			RemoveField("nickname").
+			Build().
+			EnabledWhen(func(ctx context.Context) bool {
+				calls.Add(1)
+				return enabled.Load()
+			})
+
+		var options []func(*EpochBuilder) *EpochBuilder
+		if templates {
+			options = append(options, (*EpochBuilder).WithResponseTemplates)
+		}
+		epochInstance, err := setupHeadEpoch([]*Version{v1, v2}, []*VersionChange{change}, options...)
+		Expect(err).NotTo(HaveOccurred())
+
+		router := setupRouterWithMiddleware(epochInstance)
+		router.POST("/users", epochInstance.WrapHandler(func(c *gin.Context) {
+			var user toggleUser
+			Expect(c.ShouldBindJSON(&user)).To(Succeed())
+			user.Nickname = "jj"
+			c.JSON(200, user)
+		}).Accepts(toggleUser{}).Returns(toggleUser{}).ToHandlerFunc("POST", "/users"))
+
+		req := httptest.NewRequest("POST", "/users", strings.NewReader(body))
+		recorder := serveVersioned(router, req, "2024-01-01")
+		Expect(recorder.Code).To(Equal(200))
+		return recorder.Body.String()
+	}
+
+	It("should apply the change when enabled", func() {
+		Expect(serve(false, `{"name":"Jane"}`)).To(Equal(`{"name":"Jane"}`))
+	})
+
+	It("should skip the change in both directions when disabled", func() {
+		enabled.Store(false)
+		Expect(serve(false, `{"full_name":"Jane"}`)).To(Equal(`{"full_name":"Jane","nickname":"jj"}`))
+	})
+
+	It("should evaluate the toggle once per request", func() {
+		serve(false, `{"name":"Jane"}`)
+		Expect(calls.Load()).To(Equal(int32(1)))
+	})
+
+	It("should keep toggled changes out of response templates", func() {
+		enabled.Store(false)
+		Expect(serve(true, `{"full_name":"Jane"}`)).To(Equal(`{"full_name":"Jane","nickname":"jj"}`))
+	})
+
+	It("should pass the Gin context to the predicate", func() {
+		var sawAccount interface{}
+		change := NewVersionChange("Toggled", v1, v2).EnabledWhen(func(ctx context.Context) bool {
+			sawAccount = ctx.Value("account_id")
+			return true
+		})
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Set("account_id", "acct_1")
+
+		Expect(change.isEnabled(context.Background(), c)).To(BeTrue())
+		Expect(sawAccount).To(Equal("acct_1"))
+		Expect(change.IsToggled()).To(BeTrue())
+		Expect(NewVersionChange("Plain", v1, v2).IsToggled()).To(BeFalse())
+	})
+
+	Describe("RolloutPercentage", func() {
+		accountKey := func(ctx context.Context) string {
+			account, _ := ctx.Value(toggleAccountKey{}).(string)
+			return account
+		}
+		withAccount := func(account string) context.Context {
+			return context.WithValue(context.Background(), toggleAccountKey{}, account)
+		}
+
+		It("should honor the bounds", func() {
+			Expect(RolloutPercentage(0, nil)(context.Background())).To(BeFalse())
+			Expect(RolloutPercentage(100, nil)(context.Background())).To(BeTrue())
+		})
+
+		It("should be sticky per key", func() {
+			rollout := RolloutPercentage(50, accountKey)
+			for i := 0; i < 20; i++ {
+				ctx := withAccount(fmt.Sprintf("acct_%d", i))
+				Expect(rollout(ctx)).To(Equal(rollout(ctx)))
+			}
+		})
+
+		It("should enable roughly the requested share of keys", func() {
+			rollout := RolloutPercentage(25, accountKey)
+			enabledCount := 0
+			for i := 0; i < 4000; i++ {
+				if rollout(withAccount(fmt.Sprintf("acct_%d", i))) {
+					enabledCount++
+				}
+			}
+			Expect(enabledCount).To(BeNumerically("~", 1000, 150))
+		})
+	})
+})
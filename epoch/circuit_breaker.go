@@ -0,0 +1,108 @@
+package epoch
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// MigrationWarningHeader carries a warning on responses served without migration because the
+// endpoint's circuit breaker is open
+const MigrationWarningHeader = "Warning"
+
+// circuitOpenWarning is the MigrationWarningHeader value while an endpoint's breaker is open
+const circuitOpenWarning = `199 epoch "Migrations disabled after repeated failures; payloads are in HEAD format"`
+
+// CircuitBreaker disables migrations for an endpoint whose migrations keep failing, so a broken
+// change degrades to HEAD payloads instead of failing every call from older clients
+type CircuitBreaker struct {
+	Threshold int           // Failed migrations within Window that open the breaker
+	Window    time.Duration // How far back failures count
+	Cooldown  time.Duration // How long migrations stay disabled once the breaker opens
+	Clock     Clock         // Defaults to the Epoch's clock
+}
+
+// errCircuitBreakerConfig is returned for breakers that would open at once or never count a failure
+var errCircuitBreakerConfig = errors.New("circuit breaker needs a positive threshold, window and cooldown")
+
+// validate checks the configuration both WithCircuitBreaker methods accept
+func (config CircuitBreaker) validate() error {
+	if config.Threshold <= 0 || config.Window <= 0 || config.Cooldown <= 0 {
+		return errCircuitBreakerConfig
+	}
+	return nil
+}
+
+// circuitBreaker tracks one endpoint's migration failures
+type circuitBreaker struct {
+	config    CircuitBreaker
+	mu        sync.Mutex
+	failures  []time.Time
+	openUntil time.Time
+}
+
+// newCircuitBreaker returns a closed breaker, reading the system clock unless config has one
+func newCircuitBreaker(config CircuitBreaker) *circuitBreaker {
+	if config.Clock == nil {
+		config.Clock = SystemClock
+	}
+	return &circuitBreaker{config: config}
+}
+
+// isOpen reports whether migrations are disabled; nil breakers never open
+func (b *circuitBreaker) isOpen() bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.config.Clock.Now().Before(b.openUntil)
+}
+
+// recordFailure counts a failed migration, opening the breaker at the threshold. Requests
+// whose client went away don't count.
+func (b *circuitBreaker) recordFailure(err error) {
+	if b == nil || errors.Is(err, context.Canceled) {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.config.Clock.Now()
+	recent := b.failures[:0]
+	for _, at := range b.failures {
+		if now.Sub(at) < b.config.Window {
+			recent = append(recent, at)
+		}
+	}
+	b.failures = append(recent, now)
+	if len(b.failures) >= b.config.Threshold {
+		b.openUntil = now.Add(b.config.Cooldown)
+		b.failures = b.failures[:0]
+	}
+}
+
+// WithCircuitBreaker disables the handler's migrations for Cooldown once Threshold migrations
+// failed within Window. While open, requests reach the handler and responses reach the client
+// unmigrated, with MigrationWarningHeader set. It panics unless Threshold, Window and Cooldown
+// are positive.
+func (vah *VersionAwareHandler) WithCircuitBreaker(config CircuitBreaker) *VersionAwareHandler {
+	if err := config.validate(); err != nil {
+		panic("epoch: " + err.Error())
+	}
+	vah.breaker = newCircuitBreaker(config)
+	return vah
+}
+
+// WithCircuitBreaker gives every wrapped handler its own circuit breaker (see
+// VersionAwareHandler.WithCircuitBreaker). While an endpoint's breaker is open, its handler
+// receives older clients' requests unmigrated.
+func (cb *EpochBuilder) WithCircuitBreaker(config CircuitBreaker) *EpochBuilder {
+	if err := config.validate(); err != nil {
+		cb.errors = append(cb.errors, err)
+		return cb
+	}
+	cb.circuitBreaker = &config
+	return cb
+}
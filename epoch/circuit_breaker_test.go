@@ -0,0 +1,143 @@
+package epoch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Circuit breaker", func() {
+	var now time.Time
+	var clock Clock
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		now = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+		clock = ClockFunc(func() time.Time { return now })
+	})
+
+	It("should open at the threshold within the window and close after the cooldown", func() {
+		breaker := newCircuitBreaker(CircuitBreaker{Threshold: 2, Window: time.Minute, Cooldown: 5 * time.Minute, Clock: clock})
+		boom := errors.New("boom")
+
+		breaker.recordFailure(boom)
+		now = now.Add(2 * time.Minute)
+		breaker.recordFailure(boom)
+		Expect(breaker.isOpen()).To(BeFalse())
+
+		breaker.recordFailure(context.Canceled)
+		Expect(breaker.isOpen()).To(BeFalse())
+
+		now = now.Add(30 * time.Second)
+		breaker.recordFailure(boom)
+		Expect(breaker.isOpen()).To(BeTrue())
+
+		now = now.Add(5 * time.Minute)
+		Expect(breaker.isOpen()).To(BeFalse())
+	})
+
+	It("should serve HEAD payloads with a warning while open", func() {
+		v1, _ := NewDateVersion("2024-01-01")
+		v2, _ := NewDateVersion("2024-06-01")
+		epochInstance, err := NewEpoch().WithVersions(v1, v2).WithHeadVersion().WithClock(clock).
+			WithChanges(NewVersionChangeBuilder(v1, v2).
+				ForType(errorMigrationUser{}).
+				ResponseToPreviousVersion().
+				CustomOperation(failingOperation{}).
+				Build()).
+			WithCircuitBreaker(CircuitBreaker{Threshold: 2, Window: time.Minute, Cooldown: 5 * time.Minute}).
+			Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		router := gin.New()
+		router.Use(epochInstance.Middleware())
+		router.GET("/users/:id", epochInstance.WrapHandler(func(c *gin.Context) {
+			c.JSON(200, errorMigrationUser{ID: 1, FullName: "Ada"})
+		}).Returns(errorMigrationUser{}).ToHandlerFunc("GET", "/users/:id"))
+
+		get := func() *httptest.ResponseRecorder {
+			req := httptest.NewRequest("GET", "/users/1", nil)
+			req.Header.Set("X-API-Version", "2024-01-01")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+			return recorder
+		}
+
+		Expect(get().Code).To(Equal(500))
+		Expect(get().Code).To(Equal(500))
+
+		recorder := get()
+		Expect(recorder.Code).To(Equal(200))
+		Expect(recorder.Body.String()).To(MatchJSON(`{"id":1,"full_name":"Ada"}`))
+		Expect(recorder.Header().Get(MigrationWarningHeader)).To(ContainSubstring("Migrations disabled"))
+
+		now = now.Add(5 * time.Minute)
+		recorder = get()
+		Expect(recorder.Code).To(Equal(500))
+		Expect(recorder.Header().Get(MigrationWarningHeader)).To(BeEmpty())
+	})
+
+	It("should reject incomplete configuration", func() {
+		_, err := NewEpoch().WithDateVersions("2024-01-01").WithHeadVersion().
+			WithCircuitBreaker(CircuitBreaker{Threshold: 3}).Build()
+		Expect(err).To(MatchError(ContainSubstring("circuit breaker needs a positive threshold, window and cooldown")))
+
+		handler := &VersionAwareHandler{}
+		Expect(func() { handler.WithCircuitBreaker(CircuitBreaker{Window: time.Minute, Cooldown: time.Minute}) }).
+			To(PanicWith("epoch: circuit breaker needs a positive threshold, window and cooldown"))
+		Expect(func() { handler.WithCircuitBreaker(CircuitBreaker{Threshold: 3, Cooldown: time.Minute}) }).To(Panic())
+		Expect(func() { handler.WithCircuitBreaker(CircuitBreaker{Threshold: 3, Window: time.Minute}) }).To(Panic())
+		Expect(func() {
+			handler.WithCircuitBreaker(CircuitBreaker{Threshold: 3, Window: time.Minute, Cooldown: time.Minute})
+		}).NotTo(Panic())
+	})
+})
+
+var _ = Describe("Infrastructure passthrough", func() {
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+	})
+
+	It("should never migrate 429 and 5xx bodies", func() {
+		v1, _ := NewDateVersion("2024-01-01")
+		v2, _ := NewDateVersion("2024-06-01")
+		epochInstance, err := NewEpoch().WithVersions(v1, v2).WithHeadVersion().
+			WithChanges(NewVersionChangeBuilder(v1, v2).
+				ForType(errorMigrationUser{}).
+				ResponseToPreviousVersion().
+				RenameField("full_name", "name").
+				Build()).
+			WithErrorMigration(MigrateErrors(Status4xx, Status5xx)).
+			WithInfrastructurePassthrough().
+			Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		router := gin.New()
+		router.Use(epochInstance.Middleware())
+		router.GET("/users/:status", epochInstance.WrapHandler(func(c *gin.Context) {
+			var status int
+			_, _ = fmt.Sscan(c.Param("status"), &status)
+			c.JSON(status, errorMigrationUser{ID: 1, FullName: "Ada"})
+		}).Returns(errorMigrationUser{}).ToHandlerFunc("GET", "/users/:status"))
+
+		get := func(status int) string {
+			req := httptest.NewRequest("GET", fmt.Sprintf("/users/%d", status), nil)
+			req.Header.Set("X-API-Version", "2024-01-01")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+			Expect(recorder.Code).To(Equal(status))
+			return recorder.Body.String()
+		}
+
+		Expect(get(422)).To(MatchJSON(`{"id":1,"name":"Ada"}`))
+		for _, status := range []int{429, 500, 502, 503, 504} {
+			Expect(get(status)).To(MatchJSON(`{"id":1,"full_name":"Ada"}`))
+		}
+	})
+})
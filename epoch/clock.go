@@ -0,0 +1,47 @@
+package epoch
+
+import "time"
+
+// Clock tells Epoch the current time
+// Inject a fixed clock to freeze time in tests, or convert to the service's time zone so
+// date versions derived from "now" match the calendar date the service operates in.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function to a Clock
+type ClockFunc func() time.Time
+
+// Now calls f()
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// SystemClock reads the system time. It is the default clock.
+var SystemClock Clock = ClockFunc(time.Now)
+
+// FixedClock returns a clock that always reads t
+func FixedClock(t time.Time) Clock {
+	return ClockFunc(func() time.Time { return t })
+}
+
+// ClockIn returns a clock reading the system time in loc
+func ClockIn(loc *time.Location) Clock {
+	return ClockFunc(func() time.Time { return time.Now().In(loc) })
+}
+
+// Now returns the current time from the Epoch's clock (see WithClock)
+func (c *Epoch) Now() time.Time {
+	return c.clock.Now()
+}
+
+// Today returns the date version of the clock's current calendar date
+func (c *Epoch) Today() *Version {
+	return NewDateVersionFromTime(c.Now())
+}
+
+// WithClock sets the clock Epoch reads "now" from; defaults to SystemClock
+func (cb *EpochBuilder) WithClock(clock Clock) *EpochBuilder {
+	cb.clock = clock
+	return cb
+}
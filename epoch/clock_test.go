@@ -0,0 +1,41 @@
+package epoch
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Clock", func() {
+	It("should read the system time by default", func() {
+		epochInstance, err := NewEpoch().WithDateVersions("2024-01-01").WithHeadVersion().Build()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(epochInstance.Now()).To(BeTemporally("~", time.Now(), time.Second))
+	})
+
+	It("should read the injected clock", func() {
+		frozen := time.Date(2024, time.June, 1, 23, 30, 0, 0, time.UTC)
+		epochInstance, err := NewEpoch().WithDateVersions("2024-01-01").WithHeadVersion().
+			WithClock(FixedClock(frozen)).
+			Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(epochInstance.Now()).To(Equal(frozen))
+		Expect(epochInstance.Today().Raw).To(Equal("2024-06-01"))
+	})
+
+	It("should date versions in the clock's time zone", func() {
+		tokyo, err := time.LoadLocation("Asia/Tokyo")
+		Expect(err).NotTo(HaveOccurred())
+		// 23:30 UTC on June 1st is already June 2nd in Tokyo
+		frozen := time.Date(2024, time.June, 1, 23, 30, 0, 0, time.UTC)
+		clock := ClockFunc(func() time.Time { return frozen.In(tokyo) })
+
+		epochInstance, err := NewEpoch().WithDateVersions("2024-01-01").WithHeadVersion().WithClock(clock).Build()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(epochInstance.Today().Raw).To(Equal("2024-06-02"))
+
+		Expect(ClockIn(tokyo).Now().Location()).To(Equal(tokyo))
+	})
+})
@@ -0,0 +1,97 @@
+package epoch
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/astronomer/epoch/epoch/jsonast"
+)
+
+// RequestInfo and ResponseInfo are not safe for concurrent use: sonic nodes load lazily,
+// so even reads mutate the tree. Transformers that enrich a body from several goroutines
+// give each goroutine its own Clone() and Merge() the fields it produced once all are done.
+
+// Clone returns a copy of the RequestInfo with a deep copy of the body and headers
+func (r *RequestInfo) Clone() (*RequestInfo, error) {
+	body, err := CloneNode(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone request body: %w", err)
+	}
+	clone := r.withBody(body)
+	clone.Headers = r.Headers.Clone()
+	return clone, nil
+}
+
+// Merge copies the fields at the given dotted paths from another RequestInfo's body,
+// typically a Clone() a goroutine enriched. Fields missing from it are deleted.
+// Merge must not run concurrently with other uses of the RequestInfo.
+func (r *RequestInfo) Merge(from *RequestInfo, paths ...string) error {
+	if from == nil {
+		return nil
+	}
+	return mergeNodeFields(r.Body, from.Body, paths)
+}
+
+// Clone returns a copy of the ResponseInfo with a deep copy of the body and headers
+func (r *ResponseInfo) Clone() (*ResponseInfo, error) {
+	body, err := CloneNode(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone response body: %w", err)
+	}
+	clone := r.withBody(body)
+	clone.Headers = r.Headers.Clone()
+	return clone, nil
+}
+
+// Merge copies the fields at the given dotted paths from another ResponseInfo's body,
+// typically a Clone() a goroutine enriched. Fields missing from it are deleted.
+// Merge must not run concurrently with other uses of the ResponseInfo.
+func (r *ResponseInfo) Merge(from *ResponseInfo, paths ...string) error {
+	if from == nil {
+		return nil
+	}
+	return mergeNodeFields(r.Body, from.Body, paths)
+}
+
+// MergeHeaders copies the given headers from another ResponseInfo, deleting those it lacks
+func (r *ResponseInfo) MergeHeaders(from *ResponseInfo, names ...string) {
+	if from == nil {
+		return
+	}
+	if r.Headers == nil {
+		r.Headers = make(http.Header)
+	}
+	for _, name := range names {
+		if values := from.Headers.Values(name); len(values) > 0 {
+			r.Headers[http.CanonicalHeaderKey(name)] = append([]string(nil), values...)
+		} else {
+			r.Headers.Del(name)
+		}
+	}
+}
+
+// mergeNodeFields sets each path of dst to its value in src, deleting paths src lacks
+func mergeNodeFields(dst, src *jsonast.Node, paths []string) error {
+	if dst == nil {
+		return errors.New("body is nil")
+	}
+	for _, path := range paths {
+		node := GetNodeAtPath(src, path)
+		if node == nil || !node.Exists() {
+			if err := DeleteNodeAtPath(dst, path); err != nil {
+				return fmt.Errorf("failed to merge field %s: %w", path, err)
+			}
+			continue
+		}
+		// A cloned node keeps the field order and doesn't tie dst to src
+		value, err := CloneNode(node)
+		if err != nil {
+			return fmt.Errorf("failed to read field %s: %w", path, err)
+		}
+		if err := SetNodeAtPath(dst, path, value); err != nil {
+			return fmt.Errorf("failed to merge field %s: %w", path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package epoch
+
+import (
+	"net/http"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cloning and merging bodies", func() {
+	newResponse := func(raw string) *ResponseInfo {
+		body, err := getNode([]byte(raw))
+		Expect(err).NotTo(HaveOccurred())
+		return &ResponseInfo{Body: &body, StatusCode: 200, Headers: http.Header{"X-Trace": {"1"}}}
+	}
+
+	It("should clone a node that shares nothing with the original", func() {
+		node, err := getNode([]byte(`{"id":1,"profile":{"name":"Ada"}}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		clone, err := CloneNode(&node)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(SetNodeAtPath(clone, "profile.name", "Grace")).To(Succeed())
+
+		name, _ := GetNodeStringAtPath(&node, "profile.name")
+		Expect(name).To(Equal("Ada"))
+		raw, _ := clone.Raw()
+		Expect(raw).To(MatchJSON(`{"id":1,"profile":{"name":"Grace"}}`))
+	})
+
+	It("should merge fields enriched by goroutines on their own clones", func() {
+		resp := newResponse(`{"id":1,"owner_id":7,"team_id":3}`)
+
+		enrichments := map[string]func(*ResponseInfo) error{
+			"owner": func(clone *ResponseInfo) error {
+				return clone.SetAtPath("owner", map[string]interface{}{"id": 7})
+			},
+			"team": func(clone *ResponseInfo) error {
+				if err := clone.SetAtPath("team.name", "core"); err != nil {
+					return err
+				}
+				return clone.DeleteField("team_id")
+			},
+		}
+
+		clones := make(map[string]*ResponseInfo)
+		for name := range enrichments {
+			clone, err := resp.Clone()
+			Expect(err).NotTo(HaveOccurred())
+			clones[name] = clone
+		}
+
+		var wg sync.WaitGroup
+		for name, enrich := range enrichments {
+			wg.Add(1)
+			go func(clone *ResponseInfo, enrich func(*ResponseInfo) error) {
+				defer GinkgoRecover()
+				defer wg.Done()
+				Expect(enrich(clone)).To(Succeed())
+			}(clones[name], enrich)
+		}
+		wg.Wait()
+
+		Expect(resp.Merge(clones["owner"], "owner")).To(Succeed())
+		Expect(resp.Merge(clones["team"], "team", "team_id")).To(Succeed())
+
+		raw, err := resp.Body.Raw()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(raw).To(MatchJSON(`{"id":1,"owner_id":7,"owner":{"id":7},"team":{"name":"core"}}`))
+	})
+
+	It("should keep the field order of merged objects", func() {
+		resp := newResponse(`{"id":1}`)
+		clone, err := resp.Clone()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(clone.SetAtPath("profile.z", 1)).To(Succeed())
+		Expect(clone.SetAtPath("profile.a", 2)).To(Succeed())
+
+		Expect(resp.Merge(clone, "profile")).To(Succeed())
+		raw, _ := resp.Body.Raw()
+		Expect(raw).To(Equal(`{"id":1,"profile":{"z":1,"a":2}}`))
+	})
+
+	It("should clone and merge headers independently", func() {
+		resp := newResponse(`{}`)
+		clone, err := resp.Clone()
+		Expect(err).NotTo(HaveOccurred())
+		clone.Headers.Set("X-Owner", "7")
+		clone.Headers.Del("X-Trace")
+		Expect(resp.Headers.Get("X-Trace")).To(Equal("1"))
+
+		resp.MergeHeaders(clone, "X-Owner", "X-Trace")
+		Expect(resp.Headers.Get("X-Owner")).To(Equal("7"))
+		Expect(resp.Headers.Values("X-Trace")).To(BeEmpty())
+	})
+
+	It("should clone request infos", func() {
+		body, err := getNode([]byte(`{"name":"Ada"}`))
+		Expect(err).NotTo(HaveOccurred())
+		req := &RequestInfo{Body: &body}
+
+		clone, err := req.Clone()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(clone.SetField("email", "ada@example.com")).To(Succeed())
+		Expect(req.HasField("email")).To(BeFalse())
+
+		Expect(req.Merge(clone, "email")).To(Succeed())
+		email, _ := req.GetFieldString("email")
+		Expect(email).To(Equal("ada@example.com"))
+	})
+})
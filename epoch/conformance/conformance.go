@@ -0,0 +1,312 @@
+// Package conformance checks that a framework adapter integrates Epoch the way the Gin
+// integration does: versions resolve from the request, request and response bodies migrate,
+// error messages are transformed, and responses that need no migration stream through.
+// Third-party adapters (net/http, Echo, ...) run it from their own tests:
+//
+//	if err := conformance.Run(myAdapter{}).Err(); err != nil {
+//		t.Fatal(err)
+//	}
+package conformance
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/astronomer/epoch/epoch"
+)
+
+// VersionHeader is the header the suite's Epoch resolves versions from
+const VersionHeader = "X-API-Version"
+
+// Adapter mounts Epoch-versioned endpoints on a web framework
+type Adapter interface {
+	// Name identifies the adapter in reports
+	Name() string
+
+	// Handler serves the endpoints with epochInstance's version resolution and migrations,
+	// registering each endpoint's Accepts and Returns types like WrapHandler does
+	Handler(epochInstance *epoch.Epoch, endpoints []Endpoint) http.Handler
+}
+
+// Endpoint is a route the suite asks the adapter to serve. Handlers are framework-neutral
+// and always see, and answer with, HEAD payloads.
+type Endpoint struct {
+	Method  string
+	Path    string      // Literal path, without parameters
+	Accepts interface{} // Request type, or nil
+	Returns interface{} // Response type, or nil
+	Handler http.HandlerFunc
+}
+
+// User is the type the suite's endpoints accept and return. HEAD calls the name full_name;
+// Version1 clients call it name.
+type User struct {
+	ID       int    `json:"id"`
+	FullName string `json:"full_name"`
+}
+
+// Version1 and Version2 are the suite's versions; the rename happened in Version2
+const (
+	Version1 = "2024-01-01"
+	Version2 = "2025-01-01"
+)
+
+// Failure is a check the adapter didn't pass
+type Failure struct {
+	Check string
+	Err   error
+}
+
+// Result lists the checks an adapter ran and the ones it failed
+type Result struct {
+	Adapter  string
+	Checks   []string
+	Failures []Failure
+}
+
+// Passed reports whether the adapter passed every check
+func (r *Result) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// Err returns an error listing every failed check, or nil if the adapter conforms
+func (r *Result) Err() error {
+	if r.Passed() {
+		return nil
+	}
+	failures := make([]string, len(r.Failures))
+	for i, failure := range r.Failures {
+		failures[i] = fmt.Sprintf("%s: %v", failure.Check, failure.Err)
+	}
+	return fmt.Errorf("adapter %s failed %d of %d checks:\n  %s",
+		r.Adapter, len(r.Failures), len(r.Checks), strings.Join(failures, "\n  "))
+}
+
+// check is one conformance check against the suite's endpoints
+type check struct {
+	name string
+	run  func(s *suite) error
+}
+
+// checks are run in order, each against a fresh handler
+var checks = []check{
+	{"version resolution: header", checkHeaderVersion},
+	{"version resolution: unknown version", checkUnknownVersion},
+	{"version resolution: default version", checkDefaultVersion},
+	{"request migration", checkRequestMigration},
+	{"response migration", checkResponseMigration},
+	{"error transformation", checkErrorTransformation},
+	{"streaming pass-through", checkStreaming},
+}
+
+// Run runs every check against the adapter
+func Run(adapter Adapter) *Result {
+	result := &Result{Adapter: adapter.Name()}
+	for _, c := range checks {
+		result.Checks = append(result.Checks, c.name)
+		s, err := newSuite(adapter)
+		if err == nil {
+			err = c.run(s)
+		}
+		if err != nil {
+			result.Failures = append(result.Failures, Failure{Check: c.name, Err: err})
+		}
+	}
+	return result
+}
+
+// suite is the Epoch, endpoints and handler one check runs against
+type suite struct {
+	handler http.Handler
+
+	mu       sync.Mutex
+	received []byte // Body the last POST /users handler call read
+	flushed  bool   // Whether the streaming handler could flush
+}
+
+// newSuite builds the suite's Epoch and has the adapter serve its endpoints. Build attaches
+// changes to their versions, so every suite builds its own.
+func newSuite(adapter Adapter) (*suite, error) {
+	v1, err := epoch.NewDateVersion(Version1)
+	if err != nil {
+		return nil, err
+	}
+	v2, err := epoch.NewDateVersion(Version2)
+	if err != nil {
+		return nil, err
+	}
+	epochInstance, err := epoch.NewEpoch().
+		WithVersions(v1, v2).
+		WithHeadVersion().
+		WithVersionParameter(VersionHeader).
+		WithChanges(epoch.NewVersionChangeBuilder(v1, v2).
+			Description("Rename name to full_name").
+			ForType(User{}).
+			RequestToNextVersion().
+			RenameField("name", "full_name").
+			ResponseToPreviousVersion().
+			RenameField("full_name", "name").
+			Build()).
+		Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build the suite's Epoch: %w", err)
+	}
+
+	s := &suite{}
+	s.handler = adapter.Handler(epochInstance, []Endpoint{
+		{Method: http.MethodGet, Path: "/users", Returns: User{}, Handler: s.getUser},
+		{Method: http.MethodPost, Path: "/users", Accepts: User{}, Returns: User{}, Handler: s.createUser},
+		{Method: http.MethodGet, Path: "/events", Handler: s.streamEvents},
+	})
+	return s, nil
+}
+
+func (s *suite) getUser(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, User{ID: 1, FullName: "Ada Lovelace"})
+}
+
+func (s *suite) createUser(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	s.mu.Lock()
+	s.received = body
+	s.mu.Unlock()
+
+	var user User
+	if err := json.Unmarshal(body, &user); err != nil || user.FullName == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "full_name is required"})
+		return
+	}
+	user.ID = 2
+	writeJSON(w, http.StatusCreated, user)
+}
+
+// streamEvents writes its response in flushed chunks
+func (s *suite) streamEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	s.mu.Lock()
+	s.flushed = ok
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.WriteHeader(http.StatusOK)
+	for i := 1; i <= 3; i++ {
+		_, _ = fmt.Fprintf(w, "data: %d\n\n", i)
+		if ok {
+			flusher.Flush()
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// do sends a request, with the version header unless version is empty
+func (s *suite) do(method, path, version, body string) *httptest.ResponseRecorder {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if version != "" {
+		req.Header.Set(VersionHeader, version)
+	}
+	recorder := httptest.NewRecorder()
+	s.handler.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func checkHeaderVersion(s *suite) error {
+	recorder := s.do(http.MethodGet, "/users", Version1, "")
+	if got := recorder.Header().Get(VersionHeader); got != Version1 {
+		return fmt.Errorf("expected the %s response header to be %s, got %q", VersionHeader, Version1, got)
+	}
+	return expectJSON(recorder, http.StatusOK, `{"id":1,"name":"Ada Lovelace"}`)
+}
+
+func checkUnknownVersion(s *suite) error {
+	recorder := s.do(http.MethodGet, "/users", "1999-01-01", "")
+	if recorder.Code != http.StatusBadRequest {
+		return fmt.Errorf("expected status 400 for a version older than all versions, got %d", recorder.Code)
+	}
+	return nil
+}
+
+func checkDefaultVersion(s *suite) error {
+	return expectJSON(s.do(http.MethodGet, "/users", "", ""), http.StatusOK, `{"id":1,"full_name":"Ada Lovelace"}`)
+}
+
+func checkRequestMigration(s *suite) error {
+	recorder := s.do(http.MethodPost, "/users", Version1, `{"name":"Ada Lovelace"}`)
+	s.mu.Lock()
+	received := s.received
+	s.mu.Unlock()
+	if err := equalJSON(received, `{"full_name":"Ada Lovelace"}`); err != nil {
+		return fmt.Errorf("handler received the request unmigrated: %w", err)
+	}
+	return expectJSON(recorder, http.StatusCreated, `{"id":2,"name":"Ada Lovelace"}`)
+}
+
+func checkResponseMigration(s *suite) error {
+	if err := expectJSON(s.do(http.MethodGet, "/users", Version1, ""), http.StatusOK, `{"id":1,"name":"Ada Lovelace"}`); err != nil {
+		return err
+	}
+	return expectJSON(s.do(http.MethodGet, "/users", Version2, ""), http.StatusOK, `{"id":1,"full_name":"Ada Lovelace"}`)
+}
+
+func checkErrorTransformation(s *suite) error {
+	return expectJSON(s.do(http.MethodPost, "/users", Version1, `{}`), http.StatusBadRequest, `{"error":"name is required"}`)
+}
+
+func checkStreaming(s *suite) error {
+	recorder := s.do(http.MethodGet, "/events", Version1, "")
+	if recorder.Code != http.StatusOK {
+		return fmt.Errorf("expected status 200, got %d", recorder.Code)
+	}
+	if want := "data: 1\n\ndata: 2\n\ndata: 3\n\n"; recorder.Body.String() != want {
+		return fmt.Errorf("expected body %q, got %q", want, recorder.Body.String())
+	}
+	s.mu.Lock()
+	flushed := s.flushed
+	s.mu.Unlock()
+	if !flushed || !recorder.Flushed {
+		return errors.New("handler couldn't flush: the response writer must implement http.Flusher and pass flushes through")
+	}
+	return nil
+}
+
+// expectJSON checks a response's status and JSON body
+func expectJSON(recorder *httptest.ResponseRecorder, status int, want string) error {
+	if recorder.Code != status {
+		return fmt.Errorf("expected status %d, got %d: %s", status, recorder.Code, recorder.Body.String())
+	}
+	return equalJSON(recorder.Body.Bytes(), want)
+}
+
+// equalJSON compares JSON documents regardless of field order and formatting
+func equalJSON(got []byte, want string) error {
+	var gotValue, wantValue interface{}
+	if err := json.Unmarshal(bytes.TrimSpace(got), &gotValue); err != nil {
+		return fmt.Errorf("expected JSON %s, got %q", want, got)
+	}
+	if err := json.Unmarshal([]byte(want), &wantValue); err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(gotValue, wantValue) {
+		return fmt.Errorf("expected %s, got %s", want, bytes.TrimSpace(got))
+	}
+	return nil
+}
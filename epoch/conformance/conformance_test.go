@@ -0,0 +1,55 @@
+package conformance
+
+import (
+	"net/http"
+
+	"github.com/astronomer/epoch/epoch"
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// unversionedAdapter serves the endpoints with net/http and no Epoch at all
+type unversionedAdapter struct{}
+
+func (unversionedAdapter) Name() string {
+	return "unversioned"
+}
+
+func (unversionedAdapter) Handler(_ *epoch.Epoch, endpoints []Endpoint) http.Handler {
+	mux := http.NewServeMux()
+	for _, endpoint := range endpoints {
+		mux.HandleFunc(endpoint.Method+" "+endpoint.Path, endpoint.Handler)
+	}
+	return mux
+}
+
+var _ = Describe("Conformance", func() {
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+	})
+
+	It("should pass every check with the Gin integration", func() {
+		result := Run(GinAdapter{})
+		Expect(result.Err()).NotTo(HaveOccurred())
+		Expect(result.Checks).To(HaveLen(len(checks)))
+	})
+
+	It("should result the checks an adapter fails", func() {
+		result := Run(unversionedAdapter{})
+		Expect(result.Passed()).To(BeFalse())
+
+		var failed []string
+		for _, failure := range result.Failures {
+			failed = append(failed, failure.Check)
+		}
+		Expect(failed).To(ConsistOf(
+			"version resolution: header",
+			"version resolution: unknown version",
+			"request migration",
+			"response migration",
+			"error transformation",
+		))
+		Expect(result.Err()).To(MatchError(ContainSubstring("adapter unversioned failed 5 of 7 checks:\n  version resolution: header: expected the X-API-Version response header to be 2024-01-01")))
+	})
+})
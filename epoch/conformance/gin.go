@@ -0,0 +1,33 @@
+package conformance
+
+import (
+	"net/http"
+
+	"github.com/astronomer/epoch/epoch"
+	"github.com/gin-gonic/gin"
+)
+
+// GinAdapter is the reference adapter: Epoch's own Gin integration
+type GinAdapter struct{}
+
+// Name returns "gin"
+func (GinAdapter) Name() string {
+	return "gin"
+}
+
+// Handler serves the endpoints from a Gin engine using Middleware and WrapHandler
+func (GinAdapter) Handler(epochInstance *epoch.Epoch, endpoints []Endpoint) http.Handler {
+	router := gin.New()
+	router.Use(epochInstance.Middleware())
+	for _, endpoint := range endpoints {
+		wrapper := epochInstance.WrapHandler(gin.WrapF(endpoint.Handler))
+		if endpoint.Accepts != nil {
+			wrapper = wrapper.Accepts(endpoint.Accepts)
+		}
+		if endpoint.Returns != nil {
+			wrapper = wrapper.Returns(endpoint.Returns)
+		}
+		router.Handle(endpoint.Method, endpoint.Path, wrapper.ToHandlerFunc(endpoint.Method, endpoint.Path))
+	}
+	return router
+}
@@ -0,0 +1,68 @@
+package epoch
+
+import (
+	"fmt"
+
+	"github.com/astronomer/epoch/epoch/jsonast"
+)
+
+// ============================================================================
+// Request Operations - TO NEXT VERSION (Client→HEAD)
+// ============================================================================
+
+// RequestAddFieldFromContext adds a field missing from the request with a value the Gin context
+// holds, e.g. the organization an auth middleware read from the token with c.Set("org_id", ...)
+// Use case: HEAD requires a field older clients don't send but the server already knows
+type RequestAddFieldFromContext struct {
+	Name string // Field HEAD expects
+	Key  string // Gin context key holding its value
+}
+
+// ApplyToRequest does nothing: without the Gin context there's no value to add
+func (op *RequestAddFieldFromContext) ApplyToRequest(node *jsonast.Node) error {
+	return nil
+}
+
+// ApplyToRequestInfo adds the field from the request's Gin context. The field stays missing when
+// the client sent it, the context holds no value under Key, or there's no Gin context (Transform).
+func (op *RequestAddFieldFromContext) ApplyToRequestInfo(req *RequestInfo) error {
+	if req == nil || req.Body == nil || req.GinContext == nil {
+		return nil
+	}
+	if req.Body.Get(op.Name).Exists() {
+		return nil
+	}
+
+	value, ok := req.GinContext.Get(op.Key)
+	if !ok {
+		return nil
+	}
+	if err := SetNodeField(req.Body, op.Name, value); err != nil {
+		return fmt.Errorf("failed to set field %s from context key %s: %w", op.Name, op.Key, err)
+	}
+	markFieldSynthesized(req.GinContext, op.Name)
+	return nil
+}
+
+func (op *RequestAddFieldFromContext) GetFieldMapping() map[string]string {
+	return nil
+}
+
+// Inverse returns the opposite operation for schema generation
+// AddFieldFromContext (Client→HEAD) becomes RemoveField (HEAD→Client)
+func (op *RequestAddFieldFromContext) Inverse() RequestToNextVersionOperation {
+	return &RequestRemoveField{
+		Name: op.Name,
+	}
+}
+
+// AddFieldFromContext adds a field missing from the request with the value the Gin context holds
+// under key, so handlers don't need per-version fallbacks for data the server already knows
+func (b *requestToNextVersionBuilder) AddFieldFromContext(name, key string) *requestToNextVersionBuilder {
+	b.parent.requestToNextVersionOps = append(b.parent.requestToNextVersionOps,
+		&RequestAddFieldFromContext{
+			Name: name,
+			Key:  key,
+		})
+	return b
+}
@@ -0,0 +1,90 @@
+package epoch
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type contextProject struct {
+	Name           string `json:"name"`
+	OrganizationID string `json:"organization_id"`
+}
+
+var _ = Describe("Context field operations", func() {
+	var (
+		v1, v2         *Version
+		epochInstance  *Epoch
+		router         *gin.Engine
+		received       string
+		authenticateAs string
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		v1, _ = NewDateVersion("2024-01-01")
+		v2, _ = NewDateVersion("2024-06-01")
+		change := NewVersionChangeBuilder(v1, v2).
+			ForType(contextProject{}).
+			RequestToNextVersion().
+			AddFieldFromContext("organization_id", "org_id").
+			Build()
+
+		var err error
+		epochInstance, err = NewEpoch().WithVersions(v1, v2).WithHeadVersion().WithChanges(change).Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		authenticateAs = "org_7"
+		router = gin.New()
+		router.Use(func(c *gin.Context) {
+			if authenticateAs != "" {
+				c.Set("org_id", authenticateAs)
+			}
+		})
+		router.Use(epochInstance.Middleware())
+		router.POST("/projects", epochInstance.WrapHandler(func(c *gin.Context) {
+			body, _ := c.GetRawData()
+			received = string(body)
+			c.JSON(201, gin.H{})
+		}).Accepts(contextProject{}).ToHandlerFunc("POST", "/projects"))
+	})
+
+	send := func(body string) {
+		req := httptest.NewRequest("POST", "/projects", bytes.NewBufferString(body))
+		req.Header.Set("X-API-Version", "2024-01-01")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	It("should add missing fields from the Gin context", func() {
+		send(`{"name":"Apollo"}`)
+		Expect(received).To(MatchJSON(`{"name":"Apollo","organization_id":"org_7"}`))
+	})
+
+	It("should keep fields clients sent", func() {
+		send(`{"name":"Apollo","organization_id":"org_9"}`)
+		Expect(received).To(MatchJSON(`{"name":"Apollo","organization_id":"org_9"}`))
+	})
+
+	It("should leave the field missing when the context has no value", func() {
+		authenticateAs = ""
+		send(`{"name":"Apollo"}`)
+		Expect(received).To(MatchJSON(`{"name":"Apollo"}`))
+
+		migrated, err := epochInstance.Transform(context.Background(), reflect.TypeOf(contextProject{}), DirectionRequest,
+			v1, epochInstance.GetHeadVersion(), []byte(`{"name":"Apollo"}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(migrated)).To(MatchJSON(`{"name":"Apollo"}`))
+	})
+
+	It("should document the field as added", func() {
+		doc := DescribeOperation(&RequestAddFieldFromContext{Name: "organization_id", Key: "org_id"})
+		Expect(doc.Name).To(Equal("add_field_from_context"))
+		Expect(doc.AddedFields).To(HaveKey("organization_id"))
+		Expect((&RequestAddFieldFromContext{Name: "organization_id"}).Inverse()).To(Equal(&RequestRemoveField{Name: "organization_id"}))
+	})
+})
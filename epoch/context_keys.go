@@ -53,3 +53,46 @@ func HasCapturedField(c *gin.Context, fieldName string) bool {
 	_, exists := GetCapturedField(c, fieldName)
 	return exists
 }
+
+// MigrationContextKey is the context key for the per-request migration context.
+// Request transformers can stash arbitrary values here (e.g. a field exactly as the
+// client sent it) and response transformers can read them back for the same request.
+const MigrationContextKey = "epoch_migration_context"
+
+// GetMigrationContext retrieves the migration context map from a Gin context
+func GetMigrationContext(c *gin.Context) map[string]interface{} {
+	if c == nil {
+		return nil
+	}
+	if val, exists := c.Get(MigrationContextKey); exists {
+		if values, ok := val.(map[string]interface{}); ok {
+			return values
+		}
+	}
+	return nil
+}
+
+// SetMigrationValue stores a value in the per-request migration context.
+// Unlike captured fields, keys are chosen by the transformer author and are not
+// tied to any field name, so they can hold formatting hints or derived data.
+func SetMigrationValue(c *gin.Context, key string, value interface{}) {
+	if c == nil {
+		return
+	}
+	values := GetMigrationContext(c)
+	if values == nil {
+		values = make(map[string]interface{})
+		c.Set(MigrationContextKey, values)
+	}
+	values[key] = value
+}
+
+// GetMigrationValue retrieves a value from the per-request migration context
+func GetMigrationValue(c *gin.Context, key string) (interface{}, bool) {
+	values := GetMigrationContext(c)
+	if values == nil {
+		return nil, false
+	}
+	val, exists := values[key]
+	return val, exists
+}
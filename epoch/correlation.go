@@ -0,0 +1,54 @@
+package epoch
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// CorrelationIDKey is the Gin context key holding the request's correlation ID
+const CorrelationIDKey = "epoch.correlation_id"
+
+// CorrelationIDHeader carries the correlation ID next to MigrationsAppliedHeader
+const CorrelationIDHeader = "X-Epoch-Correlation-ID"
+
+// correlationIDField is the error payload field holding the correlation ID
+const correlationIDField = "correlation_id"
+
+// CorrelationIDExtractor reads a request's correlation ID; "" means the request has none
+type CorrelationIDExtractor func(c *gin.Context) string
+
+// CorrelationIDFromHeader reads the correlation ID from the first of the headers present
+func CorrelationIDFromHeader(names ...string) CorrelationIDExtractor {
+	return func(c *gin.Context) string {
+		for _, name := range names {
+			if id := c.GetHeader(name); id != "" {
+				return id
+			}
+		}
+		return ""
+	}
+}
+
+// CorrelationID returns the correlation ID the version middleware extracted, or ""
+func CorrelationID(c *gin.Context) string {
+	if c == nil {
+		return ""
+	}
+	return c.GetString(CorrelationIDKey)
+}
+
+// WithCorrelationIDExtractor makes Epoch trace its output back to the request: the ID is
+// stored under CorrelationIDKey, added as "correlation_id" to every error Epoch answers
+// and to structured error payloads, sent in CorrelationIDHeader with the migration debug
+// header, and set on SchemaMatch diagnostics and FieldErrors.
+func (cb *EpochBuilder) WithCorrelationIDExtractor(extractor CorrelationIDExtractor) *EpochBuilder {
+	cb.correlationID = extractor
+	return cb
+}
+
+// withCorrelationID adds the request's correlation ID to an error payload Epoch answers with
+func withCorrelationID(c *gin.Context, body gin.H) gin.H {
+	if id := CorrelationID(c); id != "" {
+		body[correlationIDField] = id
+	}
+	return body
+}
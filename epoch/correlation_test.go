@@ -0,0 +1,115 @@
+package epoch
+
+import (
+	"errors"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type correlatedUser struct {
+	ID       int    `json:"id"`
+	FullName string `json:"full_name"`
+}
+
+var _ = Describe("Correlation IDs", func() {
+	var v1, v2 *Version
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		v1, v2, _ = newTestVersions()
+	})
+
+	rename := func() *VersionChange {
+		return NewVersionChangeBuilder(v1, v2).
+			ForType(correlatedUser{}).
+			ResponseToPreviousVersion().
+			RenameField("full_name", "name").
+			Build()
+	}
+
+	serve := func(builder *EpochBuilder, handler gin.HandlerFunc, version string) *httptest.ResponseRecorder {
+		epochInstance, err := builder.Build()
+		Expect(err).NotTo(HaveOccurred())
+		router := setupRouterWithMiddleware(epochInstance)
+		router.POST("/users", epochInstance.WrapHandler(handler).
+			Accepts(correlatedUser{}).Returns(correlatedUser{}).ToHandlerFunc("POST", "/users"))
+
+		req := httptest.NewRequest("POST", "/users", nil)
+		req.Header.Set("X-Request-ID", "req-42")
+		return serveVersioned(router, req, version)
+	}
+
+	ok := func(c *gin.Context) {
+		Expect(CorrelationID(c)).To(Equal("req-42"))
+		c.JSON(200, correlatedUser{ID: 1, FullName: "Ada"})
+	}
+
+	newBuilder := func(changes ...*VersionChange) *EpochBuilder {
+		return NewEpoch().WithVersions(v1, v2).WithHeadVersion().WithChanges(changes...).
+			WithCorrelationIDExtractor(CorrelationIDFromHeader("X-Correlation-ID", "X-Request-ID"))
+	}
+
+	It("should add the correlation ID to version errors", func() {
+		recorder := serve(newBuilder(rename()), ok, "2023-01-01")
+		Expect(recorder.Code).To(Equal(400))
+		Expect(recorder.Body.String()).To(ContainSubstring(`"correlation_id":"req-42"`))
+	})
+
+	It("should add the correlation ID to migration errors", func() {
+		failing := NewVersionChangeBuilder(v1, v2).
+			CustomResponse(func(*ResponseInfo) error { return errors.New("boom") }).
+			Build()
+		recorder := serve(newBuilder(failing), ok, "2024-01-01")
+		Expect(recorder.Code).To(Equal(500))
+		Expect(recorder.Body.String()).To(ContainSubstring(`"correlation_id":"req-42"`))
+	})
+
+	It("should leave error responses alone without an extractor", func() {
+		recorder := serve(NewEpoch().WithVersions(v1, v2).WithHeadVersion().WithChanges(rename()), func(c *gin.Context) {
+			Expect(CorrelationID(c)).To(BeEmpty())
+		}, "2023-01-01")
+		Expect(recorder.Code).To(Equal(400))
+		Expect(recorder.Body.String()).NotTo(ContainSubstring("correlation_id"))
+	})
+
+	It("should send the correlation ID with the migration debug header", func() {
+		recorder := serve(newBuilder(rename()).WithMigrationDebugHeader(), ok, "2024-01-01")
+		Expect(recorder.Header().Get(MigrationsAppliedHeader)).NotTo(BeEmpty())
+		Expect(recorder.Header().Get(CorrelationIDHeader)).To(Equal("req-42"))
+	})
+
+	It("should attach the correlation ID to schema diagnostics", func() {
+		var matches []SchemaMatch
+		serve(newBuilder(rename()).WithSchemaMatchDiagnostics(func(c *gin.Context, match SchemaMatch) {
+			matches = append(matches, match)
+		}), ok, "2024-01-01")
+		Expect(matches).NotTo(BeEmpty())
+		for _, match := range matches {
+			Expect(match.CorrelationID).To(Equal("req-42"))
+		}
+	})
+
+	It("should include the correlation ID in structured error payloads", func() {
+		var rendered FieldError
+		builder := newBuilder(rename()).WithStructuredErrors(StructuredErrors{
+			Renderers: map[string]ErrorRenderer{"en": func(err FieldError) string {
+				rendered = err
+				return err.Message
+			}},
+			DefaultLocale: "en",
+		})
+		recorder := serve(builder, func(c *gin.Context) {
+			c.JSON(422, gin.H{"errors": []gin.H{{"field": "full_name", "code": "required", "message": "is required"}}})
+		}, "2024-01-01")
+
+		Expect(recorder.Code).To(Equal(422))
+		Expect(recorder.Body.String()).To(MatchJSON(`{
+			"errors": [{"field": "name", "code": "required", "message": "is required"}],
+			"correlation_id": "req-42"
+		}`))
+		Expect(rendered.CorrelationID).To(Equal("req-42"))
+	})
+})
@@ -0,0 +1,108 @@
+package epoch
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/astronomer/epoch/epoch/jsonast"
+)
+
+// ResponseDualWriteField writes a renamed field under both its names when response migrates
+// from HEAD to client, so renames roll out additively: clients inside the transition window
+// read either name while they move over, and a later change removes the older one.
+// Use case: HEAD renamed "name" to "full_name", send both to clients still migrating
+type ResponseDualWriteField struct {
+	OlderVersionName string   // Field name clients are moving away from
+	NewerVersionName string   // Field name in newer/HEAD version
+	Since            *Version // First version of the window; older versions only get OlderVersionName. nil opens it to all
+}
+
+// ApplyToResponse writes both names; without the request's version the window is assumed
+func (op *ResponseDualWriteField) ApplyToResponse(node *jsonast.Node) error {
+	if node == nil {
+		return nil
+	}
+	if err := dualWriteNodeField(node, op.NewerVersionName, op.OlderVersionName); err != nil {
+		return fmt.Errorf("failed to dual-write field %s as %s: %w", op.NewerVersionName, op.OlderVersionName, err)
+	}
+	return nil
+}
+
+// ApplyToResponseInfo writes both names for versions inside the window and renames the field
+// for versions before it
+func (op *ResponseDualWriteField) ApplyToResponseInfo(resp *ResponseInfo) error {
+	if resp == nil || resp.Body == nil {
+		return nil
+	}
+	if !op.inWindow(resp.Request) {
+		return (&ResponseRenameField{
+			NewerVersionName: op.NewerVersionName,
+			OlderVersionName: op.OlderVersionName,
+		}).ApplyToResponse(resp.Body)
+	}
+	return op.ApplyToResponse(resp.Body)
+}
+
+// inWindow reports whether the request's version is inside the transition window
+func (op *ResponseDualWriteField) inWindow(req *RequestInfo) bool {
+	if op.Since == nil || req == nil || req.Version == nil {
+		return true
+	}
+	return !req.Version.IsOlderThan(op.Since)
+}
+
+func (op *ResponseDualWriteField) GetFieldMapping() map[string]string {
+	// Every client this applies to knows the older name, so errors use it
+	return map[string]string{op.NewerVersionName: op.OlderVersionName}
+}
+
+// dualWriteNodeField copies the field at key to copyKey, placed right before it so both
+// names read together. A field already at copyKey is left alone.
+func dualWriteNodeField(node *jsonast.Node, key, copyKey string) error {
+	if node == nil {
+		return errors.New("node is nil")
+	}
+	if key == copyKey || !HasNodeField(node, key) || HasNodeField(node, copyKey) {
+		return nil
+	}
+
+	// A deep copy, so later operations on one name don't change the other
+	value, err := CloneNode(node.Get(key))
+	if err != nil {
+		return err
+	}
+
+	if err := node.LoadAll(); err != nil {
+		return fmt.Errorf("failed to load object: %w", err)
+	}
+	iter, err := node.Properties()
+	if err != nil {
+		return err
+	}
+	var pairs []jsonast.Pair
+	var pair jsonast.Pair
+	for iter.Next(&pair) {
+		if pair.Key == key {
+			pairs = append(pairs, jsonast.Pair{Key: copyKey, Value: *value})
+		}
+		pairs = append(pairs, pair)
+	}
+	*node = jsonast.NewObject(pairs)
+	return nil
+}
+
+// DualWriteField sends the field under both olderVersionName and newerVersionName to the
+// versions before this change, with since as the first version of the transition window:
+// versions before since get olderVersionName alone, as with RenameField. A nil since writes
+// both names to every version before this change. Declare it on the change that removes
+// the older name.
+// e.g. DualWriteField("name", "full_name", v2) while clients move to full_name
+func (b *responseToPreviousVersionBuilder) DualWriteField(olderVersionName, newerVersionName string, since *Version) *responseToPreviousVersionBuilder {
+	b.parent.responseToPreviousVersionOps = append(b.parent.responseToPreviousVersionOps,
+		&ResponseDualWriteField{
+			OlderVersionName: olderVersionName,
+			NewerVersionName: newerVersionName,
+			Since:            since,
+		})
+	return b
+}
@@ -0,0 +1,104 @@
+package epoch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type dualWriteUser struct {
+	ID       int    `json:"id"`
+	FullName string `json:"full_name"`
+}
+
+var _ = Describe("Dual-write fields", func() {
+	var v1, v2, v3 *Version
+
+	// newEpoch dual-writes from v2 on when windowed, or to every older version otherwise.
+	// Build() attaches changes to versions, so every Epoch gets its own.
+	newEpoch := func(windowed bool) *Epoch {
+		v1, _ = NewDateVersion("2024-01-01")
+		v2, _ = NewDateVersion("2024-06-01")
+		v3, _ = NewDateVersion("2025-01-01")
+		var since *Version
+		if windowed {
+			since = v2
+		}
+		plans := NewVersionChangeBuilder(v1, v2).
+			Description("Stop sending the plan").
+			ForType(dualWriteUser{}).
+			ResponseToPreviousVersion().
+			AddField("plan", "free").
+			Build()
+		rename := NewVersionChangeBuilder(v2, v3).
+			Description("Remove name in favor of full_name").
+			ForType(dualWriteUser{}).
+			ResponseToPreviousVersion().
+			DualWriteField("name", "full_name", since).
+			Build()
+		epochInstance, err := NewEpoch().WithVersions(v1, v2, v3).WithHeadVersion().WithChanges(plans, rename).Build()
+		Expect(err).NotTo(HaveOccurred())
+		return epochInstance
+	}
+
+	get := func(epochInstance *Epoch, version string) *httptest.ResponseRecorder {
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.Use(epochInstance.Middleware())
+		router.GET("/users/1", epochInstance.WrapHandler(func(c *gin.Context) {
+			c.JSON(http.StatusOK, dualWriteUser{ID: 1, FullName: "Ada Lovelace"})
+		}).Returns(dualWriteUser{}).ToHandlerFunc("GET", "/users/:id"))
+
+		req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+		req.Header.Set("X-API-Version", version)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		return recorder
+	}
+
+	It("should write both names inside the window", func() {
+		recorder := get(newEpoch(true), "2024-06-01")
+		Expect(recorder.Body.String()).To(MatchJSON(`{"id":1,"name":"Ada Lovelace","full_name":"Ada Lovelace"}`))
+	})
+
+	It("should only write the older name before the window", func() {
+		recorder := get(newEpoch(true), "2024-01-01")
+		Expect(recorder.Body.String()).To(MatchJSON(`{"id":1,"name":"Ada Lovelace","plan":"free"}`))
+	})
+
+	It("should only write the newer name after the change", func() {
+		Expect(get(newEpoch(true), "2025-01-01").Body.String()).To(MatchJSON(`{"id":1,"full_name":"Ada Lovelace"}`))
+		Expect(get(newEpoch(true), "head").Body.String()).To(MatchJSON(`{"id":1,"full_name":"Ada Lovelace"}`))
+	})
+
+	It("should write both names to every older version without a start", func() {
+		recorder := get(newEpoch(false), "2024-01-01")
+		Expect(recorder.Body.String()).To(MatchJSON(`{"id":1,"name":"Ada Lovelace","full_name":"Ada Lovelace","plan":"free"}`))
+	})
+
+	It("should place the older name before the newer one and keep copies independent", func() {
+		op := &ResponseDualWriteField{OlderVersionName: "name", NewerVersionName: "profile"}
+		node, err := getNode([]byte(`{"id":1,"profile":{"first":"Ada"}}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(op.ApplyToResponse(&node)).To(Succeed())
+		Expect(SetNodeAtPath(&node, "profile.first", "Grace")).To(Succeed())
+
+		raw, err := node.MarshalJSON()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(raw)).To(Equal(`{"id":1,"name":{"first":"Ada"},"profile":{"first":"Grace"}}`))
+	})
+
+	It("should write both names when migrating without a request", func() {
+		epochInstance := newEpoch(true)
+		migrated, err := epochInstance.Transform(context.Background(), reflect.TypeOf(dualWriteUser{}), DirectionResponse,
+			epochInstance.GetHeadVersion(), v1, []byte(`{"id":1,"full_name":"Ada"}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(migrated)).To(MatchJSON(`{"id":1,"name":"Ada","full_name":"Ada","plan":"free"}`))
+	})
+})
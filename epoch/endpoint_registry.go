@@ -10,7 +10,7 @@ import (
 
 // NestedTypeInfo describes a nested type within a struct
 type NestedTypeInfo struct {
-	Path    string       // JSON path e.g., "metadata" or "profile.skills"
+	Path    string       // JSON path e.g., "metadata" or "profile.skills", keys escaped as by EscapePathKey
 	Type    reflect.Type // The nested type
 	IsArray bool         // True if it's an array/slice field
 }
@@ -25,6 +25,7 @@ type EndpointDefinition struct {
 	RequestNestedObjects  map[string]reflect.Type // field path → type for request nested objects (auto-populated)
 	ResponseNestedArrays  map[string]reflect.Type // field path → item type for response nested arrays (auto-populated)
 	ResponseNestedObjects map[string]reflect.Type // field path → type for response nested objects (auto-populated)
+	Since                 *Version                // Earliest version the endpoint exists in, from its types' SinceVersion; nil for every version
 }
 
 // EndpointRegistry stores and manages endpoint→type mappings
@@ -33,6 +34,31 @@ type EndpointRegistry struct {
 	endpoints map[string]*EndpointDefinition // key: "METHOD:path_pattern"
 }
 
+// types returns the struct types the endpoint's bodies use: its request and response types
+// and their nested types, with pointers, slices and arrays unwrapped
+func (def *EndpointDefinition) types() []reflect.Type {
+	seen := make(map[reflect.Type]bool)
+	var types []reflect.Type
+	add := func(t reflect.Type) {
+		for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			t = t.Elem()
+		}
+		if t != nil && !seen[t] {
+			seen[t] = true
+			types = append(types, t)
+		}
+	}
+	add(def.RequestType)
+	add(def.ResponseType)
+	for _, nested := range []map[string]reflect.Type{def.RequestNestedArrays, def.RequestNestedObjects,
+		def.ResponseNestedArrays, def.ResponseNestedObjects} {
+		for _, t := range nested {
+			add(t)
+		}
+	}
+	return types
+}
+
 // NewEndpointRegistry creates a new endpoint registry
 func NewEndpointRegistry() *EndpointRegistry {
 	return &EndpointRegistry{
@@ -49,6 +75,20 @@ func (er *EndpointRegistry) Register(method, pathPattern string, def *EndpointDe
 	er.endpoints[key] = def
 }
 
+// RegisterNew stores an endpoint definition, failing if one is already registered
+// for the same method and path pattern
+func (er *EndpointRegistry) RegisterNew(method, pathPattern string, def *EndpointDefinition) error {
+	er.mu.Lock()
+	defer er.mu.Unlock()
+
+	key := er.makeKey(method, pathPattern)
+	if _, exists := er.endpoints[key]; exists {
+		return fmt.Errorf("endpoint %s %s is already registered", method, pathPattern)
+	}
+	er.endpoints[key] = def
+	return nil
+}
+
 // Lookup finds an endpoint definition by matching the request method and path
 // Handles path parameters like :id and *wildcard
 func (er *EndpointRegistry) Lookup(method, requestPath string) (*EndpointDefinition, error) {
@@ -96,6 +136,13 @@ func (er *EndpointRegistry) pathMatches(pattern, path string) bool {
 	return matched
 }
 
+// get returns the definition registered for exactly this method and path pattern, or nil
+func (er *EndpointRegistry) get(method, pathPattern string) *EndpointDefinition {
+	er.mu.RLock()
+	defer er.mu.RUnlock()
+	return er.endpoints[er.makeKey(method, pathPattern)]
+}
+
 // makeKey creates a unique key for an endpoint
 func (er *EndpointRegistry) makeKey(method, path string) string {
 	return method + ":" + path
@@ -166,9 +213,9 @@ func AnalyzeStructFields(t reflect.Type, prefix string, ancestors []reflect.Type
 		// Build the full path
 		var path string
 		if prefix == "" {
-			path = jsonName
+			path = EscapePathKey(jsonName)
 		} else {
-			path = prefix + "." + jsonName
+			path = prefix + "." + EscapePathKey(jsonName)
 		}
 
 		fieldType := field.Type
@@ -284,7 +331,7 @@ func BuildNestedTypeMaps(t reflect.Type) (nestedArrays, nestedObjects map[string
 		// Only store first-level nested types (paths without dots)
 		// Deeper nesting is handled recursively when we create new TransformableBody
 		// instances for nested objects/arrays via NewForNestedObject/NewForNestedArrayItem
-		if strings.Contains(info.Path, ".") {
+		if len(SplitPath(info.Path)) > 1 {
 			continue
 		}
 
@@ -297,3 +344,34 @@ func BuildNestedTypeMaps(t reflect.Type) (nestedArrays, nestedObjects map[string
 
 	return
 }
+
+// CollectMigratableTypes returns the given type plus every struct type reachable through its
+// fields, slices and pointers. These are the types migrations may be routed to when a body of
+// type t is transformed.
+func CollectMigratableTypes(t reflect.Type) []reflect.Type {
+	var types []reflect.Type
+	seen := make(map[reflect.Type]bool)
+
+	var visit func(reflect.Type)
+	visit = func(t reflect.Type) {
+		for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			t = t.Elem()
+		}
+		if t == nil || seen[t] {
+			return
+		}
+		seen[t] = true
+		types = append(types, t)
+
+		nestedArrays, nestedObjects := BuildNestedTypeMaps(t)
+		for _, itemType := range nestedArrays {
+			visit(itemType)
+		}
+		for _, objectType := range nestedObjects {
+			visit(objectType)
+		}
+	}
+
+	visit(t)
+	return types
+}
@@ -0,0 +1,169 @@
+package epoch
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/astronomer/epoch/epoch/jsonast"
+)
+
+// ResponseEnvelopeOperation changes the outer structure of an endpoint's response body
+// when migrating from HEAD to an older version. Envelope operations are declared per
+// endpoint with ForEndpoint() because they can't be expressed as field operations on a type.
+type ResponseEnvelopeOperation interface {
+	// ApplyToEnvelope returns the body older clients expect
+	ApplyToEnvelope(body *jsonast.Node) (*jsonast.Node, error)
+}
+
+// ResponseWrap nests the whole response body under Key for older clients
+// Use case: HEAD returns the bare object, older versions returned {"data": {...}}
+type ResponseWrap struct {
+	Key string
+}
+
+// ApplyToEnvelope wraps the body in a single-key object
+func (op *ResponseWrap) ApplyToEnvelope(body *jsonast.Node) (*jsonast.Node, error) {
+	wrapped := jsonast.NewObject([]jsonast.Pair{jsonast.NewPair(op.Key, *body)})
+	return &wrapped, nil
+}
+
+// ResponseUnwrap replaces the response body with its Key member for older clients
+// Use case: HEAD returns {"data": {...}}, older versions returned the bare object
+type ResponseUnwrap struct {
+	Key string
+}
+
+// ApplyToEnvelope returns the body's Key member, or the body unchanged when it has none
+func (op *ResponseUnwrap) ApplyToEnvelope(body *jsonast.Node) (*jsonast.Node, error) {
+	if body.TypeSafe() != jsonast.V_OBJECT {
+		return body, nil
+	}
+	inner := body.Get(op.Key)
+	if inner == nil || !inner.Exists() {
+		return body, nil
+	}
+	if err := inner.Check(); err != nil {
+		return nil, fmt.Errorf("failed to unwrap response field %s: %w", op.Key, err)
+	}
+	return inner, nil
+}
+
+// endpointKey identifies an endpoint the way the EndpointRegistry registers it
+func endpointKey(method, pathPattern string) string {
+	return strings.ToUpper(method) + " " + pathPattern
+}
+
+// GetResponseEnvelopeOperations returns the envelope operations this change declares for an endpoint
+func (vc *VersionChange) GetResponseEnvelopeOperations(method, pathPattern string) []ResponseEnvelopeOperation {
+	return vc.responseEnvelopeOps[endpointKey(method, pathPattern)]
+}
+
+// envelopePath orders the changes between from and to the way responses are migrated: newest first
+func (mc *MigrationChain) envelopePath(from, to *Version, method, pathPattern string) []*VersionChange {
+	var path []*VersionChange
+	for _, change := range mc.GetMigrationPath(from, to) {
+		if len(change.GetResponseEnvelopeOperations(method, pathPattern)) > 0 {
+			path = append(path, change)
+		}
+	}
+	sort.SliceStable(path, func(i, j int) bool {
+		return path[i].ToVersion().IsNewerThan(path[j].ToVersion())
+	})
+	return path
+}
+
+// HasEnvelopeOperations reports whether any change between two versions rewraps the endpoint's responses
+func (mc *MigrationChain) HasEnvelopeOperations(from, to *Version, method, pathPattern string) bool {
+	return len(mc.envelopePath(from, to, method, pathPattern)) > 0
+}
+
+// MigrateResponseEnvelope applies the endpoint's envelope operations from HEAD (from) down to
+// an older version (to). It runs after type migrations, which always see the HEAD structure.
+// Error responses keep their shape.
+func (mc *MigrationChain) MigrateResponseEnvelope(
+	ctx context.Context,
+	responseInfo *ResponseInfo,
+	method, pathPattern string,
+	from, to *Version,
+) error {
+	if responseInfo.Body == nil || responseInfo.StatusCode >= 400 {
+		return nil
+	}
+	for _, change := range mc.envelopePath(from, to, method, pathPattern) {
+		if !change.isEnabled(ctx, responseInfo.GinContext) {
+			continue
+		}
+		if err := checkMigrationContext(ctx); err != nil {
+			return err
+		}
+		responseInfo.ctx = ctx
+		err := change.recoverChange(DirectionResponse, func() error {
+			return applyEnvelopeOperations(change.GetResponseEnvelopeOperations(method, pathPattern), responseInfo)
+		})
+		if err != nil {
+			return fmt.Errorf("envelope migration failed at %s->%s: %w",
+				change.ToVersion().String(), change.FromVersion().String(), err)
+		}
+	}
+	return nil
+}
+
+// applyEnvelopeOperations applies one change's envelope operations to the response
+func applyEnvelopeOperations(ops []ResponseEnvelopeOperation, responseInfo *ResponseInfo) error {
+	for i, op := range ops {
+		err := recoverOperation(i, func() error {
+			// Operations that need the request (e.g. pagination) get the full ResponseInfo
+			if infoOp, ok := op.(ResponseInfoOperation); ok {
+				return infoOp.ApplyToResponseInfo(responseInfo)
+			}
+			body, err := op.ApplyToEnvelope(responseInfo.Body)
+			if err != nil {
+				return err
+			}
+			responseInfo.Body = body
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// endpointBuilder builds envelope operations for a single endpoint
+type endpointBuilder struct {
+	parent      *versionChangeBuilder
+	method      string
+	pathPattern string
+}
+
+// WrapResponse nests the endpoint's response under key for older versions,
+// e.g. HEAD returns {...} while older clients expect {"data": {...}}
+func (eb *endpointBuilder) WrapResponse(key string) *endpointBuilder {
+	eb.parent.addEnvelopeOperation(eb.method, eb.pathPattern, &ResponseWrap{Key: key})
+	return eb
+}
+
+// UnwrapResponse returns the endpoint's key member to older versions,
+// e.g. HEAD returns {"data": {...}} while older clients expect {...}
+func (eb *endpointBuilder) UnwrapResponse(key string) *endpointBuilder {
+	eb.parent.addEnvelopeOperation(eb.method, eb.pathPattern, &ResponseUnwrap{Key: key})
+	return eb
+}
+
+// ForEndpoint starts building operations for another endpoint
+func (eb *endpointBuilder) ForEndpoint(method, pathPattern string) *endpointBuilder {
+	return eb.parent.ForEndpoint(method, pathPattern)
+}
+
+// ForType starts building operations for specific types
+func (eb *endpointBuilder) ForType(types ...interface{}) *typeBuilder {
+	return eb.parent.ForType(types...)
+}
+
+// Build creates the VersionChange
+func (eb *endpointBuilder) Build() *VersionChange {
+	return eb.parent.Build()
+}
@@ -0,0 +1,103 @@
+package epoch
+
+import (
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type envelopeUser struct {
+	ID       int    `json:"id"`
+	FullName string `json:"full_name"`
+}
+
+var _ = Describe("Response envelopes", func() {
+	var v1, v2, v3 *Version
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		v1, v2, v3 = newTestVersions()
+	})
+
+	serve := func(status int, version string, changes ...*VersionChange) string {
+		epochInstance, err := setupHeadEpoch([]*Version{v1, v2, v3}, changes)
+		Expect(err).NotTo(HaveOccurred())
+
+		router := setupRouterWithMiddleware(epochInstance)
+		router.GET("/users/:id", epochInstance.WrapHandler(func(c *gin.Context) {
+			if status >= 400 {
+				c.JSON(status, gin.H{"error": "not found"})
+				return
+			}
+			c.JSON(status, envelopeUser{ID: 1, FullName: "Jane"})
+		}).Returns(envelopeUser{}).ToHandlerFunc("GET", "/users/:id"))
+
+		recorder := serveVersioned(router, httptest.NewRequest("GET", "/users/1", nil), version)
+		Expect(recorder.Code).To(Equal(status))
+		return recorder.Body.String()
+	}
+
+	unwrapped := func() *VersionChange {
+		return NewVersionChangeBuilder(v2, v3).
+			ForEndpoint("GET", "/users/:id").
+			WrapResponse("data").
+			Build()
+	}
+
+	It("should wrap responses for versions before the envelope was removed", func() {
+		Expect(serve(200, "2024-06-01", unwrapped())).To(Equal(`{"data":{"id":1,"full_name":"Jane"}}`))
+		Expect(serve(200, "2025-01-01", unwrapped())).To(Equal(`{"id":1,"full_name":"Jane"}`))
+	})
+
+	It("should run type operations on the HEAD structure first", func() {
+		rename := NewVersionChangeBuilder(v1, v2).
+			ForType(envelopeUser{}).
+			ResponseToPreviousVersion().
+			RenameField("full_name", "name").
+			Build()
+
+		Expect(serve(200, "2024-01-01", rename, unwrapped())).To(Equal(`{"data":{"id":1,"name":"Jane"}}`))
+	})
+
+	It("should compose wrap and unwrap across versions", func() {
+		wrapped := NewVersionChangeBuilder(v1, v2).
+			ForEndpoint("GET", "/users/:id").
+			UnwrapResponse("data").
+			Build()
+
+		// v1 was bare, v2 wrapped, v3 bare again
+		Expect(serve(200, "2024-01-01", wrapped, unwrapped())).To(Equal(`{"id":1,"full_name":"Jane"}`))
+	})
+
+	It("should leave error responses alone", func() {
+		Expect(serve(404, "2024-06-01", unwrapped())).To(Equal(`{"error":"not found"}`))
+	})
+
+	It("should only apply to the declared endpoint", func() {
+		change := NewVersionChangeBuilder(v2, v3).
+			ForEndpoint("GET", "/orgs/:id").
+			WrapResponse("data").
+			Build()
+
+		Expect(serve(200, "2024-06-01", change)).To(Equal(`{"id":1,"full_name":"Jane"}`))
+	})
+
+	It("should unwrap only objects holding the key", func() {
+		op := &ResponseUnwrap{Key: "data"}
+		for _, body := range []string{`[1,2]`, `{"items":[]}`} {
+			node, err := DefaultJSONEngine().Parse([]byte(body))
+			Expect(err).NotTo(HaveOccurred())
+			result, err := op.ApplyToEnvelope(node)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(BeIdenticalTo(node))
+		}
+	})
+
+	It("should expose envelope operations per endpoint", func() {
+		change := unwrapped()
+		Expect(change.GetResponseEnvelopeOperations("get", "/users/:id")).To(Equal([]ResponseEnvelopeOperation{&ResponseWrap{Key: "data"}}))
+		Expect(change.GetResponseEnvelopeOperations("POST", "/users/:id")).To(BeEmpty())
+	})
+})
@@ -3,6 +3,9 @@ package epoch
 import (
 	"fmt"
 	"reflect"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -13,6 +16,140 @@ type Epoch struct {
 	migrationChain   *MigrationChain
 	versionConfig    VersionConfig
 	endpointRegistry *EndpointRegistry
+	jsonEngine       JSONEngine
+
+	// responseTemplates enables precomputed byte-level response patches (see ResponseTemplate)
+	responseTemplates bool
+
+	// runtimeRegistration allows RegisterEndpoint/RegisterChange after Build()
+	runtimeRegistration bool
+
+	// migrationDebugHeader writes MigrationsAppliedHeader on migrated responses
+	migrationDebugHeader bool
+
+	// urlRewriter rewrites HEAD resource URLs for older versions (see WithURLRewriter)
+	urlRewriter   URLRewriter
+	urlBodyFields []string
+
+	// migrationTimeout bounds each migration phase (see WithMigrationTimeout)
+	migrationTimeout time.Duration
+
+	// panicFallback continues with the unmigrated body when a transformer panics
+	panicFallback bool
+
+	// strictSchemaMatching fails responses that don't match their registered type
+	strictSchemaMatching bool
+
+	// schemaDiagnostics records SchemaMatch diagnostics (see WithSchemaMatchDiagnostics)
+	schemaDiagnostics   bool
+	schemaMatchObserver func(*gin.Context, SchemaMatch)
+
+	// originalBodyLimit bounds the retained client request body (see WithOriginalRequestBody)
+	originalBodyLimit int
+
+	// interceptors wrap the response writer of every wrapped handler (see WithResponseInterceptors)
+	interceptors []ResponseInterceptor
+
+	// outputFormats formats migrated responses per version string (see WithOutputFormat)
+	outputFormats map[string]outputFormatEntry
+
+	// strippedVersions drop unknown response fields (see WithUnknownFieldStripping)
+	strippedVersions []*Version
+
+	// allowlists strip response fields a type didn't have in a version (see EnforceFieldAllowlist)
+	allowlists []fieldAllowlist
+
+	// structuredErrors transforms error responses through their errors array (see WithStructuredErrors)
+	structuredErrors *StructuredErrors
+
+	// clock tells the current time (see WithClock)
+	clock Clock
+
+	// retired versions are rejected with an upgrade hint (see RetireVersion)
+	retired *retiredVersions
+
+	// unreleased versions need a preview header to resolve (see WithUnreleasedVersion)
+	unreleased []unreleasedVersion
+
+	// internalHead hides head from clients (see WithInternalHeadVersion)
+	internalHead bool
+
+	// newerVersions serves versions newer than every registered one (see WithUnknownNewerVersionPolicy)
+	newerVersions NewerVersionPolicy
+
+	// sunsets are deprecated versions, warned about within sunsetWarning's window (see WithVersionSunset)
+	sunsets       []sunsetVersion
+	sunsetWarning *SunsetWarning
+
+	// modules mount their routes with MountModules (see WithModules)
+	modules []*EpochModule
+
+	// correlationID extracts the request's correlation ID (see WithCorrelationIDExtractor)
+	correlationID CorrelationIDExtractor
+
+	// errorMigration selects the error responses wrapped handlers migrate (see WithErrorMigration)
+	errorMigration *ErrorMigration
+
+	// errorShapers reshape error bodies written outside wrapped handlers (see WithErrorShaper)
+	errorShapers []errorShaper
+
+	// passthrough leaves 429 and 5xx responses unmigrated (see WithInfrastructurePassthrough)
+	passthrough bool
+
+	// newerFields handles request fields newer than the client's version (see WithNewerRequestFields)
+	newerFields NewerFieldPolicy
+
+	// circuitBreaker configures each wrapped handler's breaker (see WithCircuitBreaker)
+	circuitBreaker *CircuitBreaker
+
+	// snapshots keeps each change's input body for ResponseInfo.Snapshot (see WithResponseSnapshots)
+	snapshots bool
+
+	// canary compares sampled HEAD responses with the stable version (see WithCanary)
+	canary *Canary
+
+	// normalizeResponses reshapes handler output to its Returns() type (see WithResponseNormalization)
+	normalizeResponses bool
+
+	// stats records per-endpoint migration costs (see WithMigrationStats); nil records nothing
+	stats *migrationStats
+
+	// migrationLog writes an entry per versioned request (see WithMigrationLog); nil logs nothing
+	migrationLog *migrationLogger
+
+	// scrubber cleans bodies shown in diagnostics (see WithSensitiveFields); nil leaves them as-is
+	scrubber *bodyScrubber
+
+	// fixtureCapture writes sampled HEAD responses as fixtures (see WithFixtureCapture); nil writes none
+	fixtureCapture *fixtureRecorder
+
+	// responseCache stores migrated responses of endpoints using CacheResponses (see WithResponseCache)
+	responseCache ResponseCache
+
+	// prunedChanges were dropped from the chain at Build() (see WithChangePruning)
+	prunedChanges []PrunedChange
+
+	// squashedVersions were dropped from the bundle at Build() (see WithSquashedVersions)
+	squashedVersions []*Version
+
+	// ambiguousTypes are the types Build() found with identical JSON fields (see AmbiguousTypes)
+	ambiguousTypes []AmbiguousTypePair
+
+	// examples are canonical HEAD payloads verified at Build() (see WithExamples)
+	examples []interface{}
+
+	// types are the WithTypes types and every type nested in them, minus ExcludeTypes
+	types []reflect.Type
+
+	// typeFloors are the earliest versions types exist in (see SinceVersion)
+	typeFloors map[reflect.Type]*Version
+
+	// versionKey is the context key this instance's middleware stores the version under
+	versionKey string
+
+	// mu serializes runtime registration (RegisterChange) after Build(); the versions'
+	// changes it extends are guarded by the version bundle's lock
+	mu *sync.Mutex
 }
 
 // VersionConfig holds configuration for version detection and handling
@@ -20,6 +157,8 @@ type VersionConfig struct {
 	VersionParameterName string
 	VersionFormat        VersionFormat
 	DefaultVersion       *Version
+	VersionSources       []VersionSource // Where requests name their version; header then path when empty
+	VersionCookie        *VersionCookie  // Version affinity by cookie; nil when disabled
 }
 
 // NewEpoch creates a new Epoch instance for API versioning
@@ -43,7 +182,21 @@ func (c *Epoch) Middleware() gin.HandlerFunc {
 		ParameterName:  c.versionConfig.VersionParameterName,
 		Format:         c.versionConfig.VersionFormat,
 		DefaultVersion: c.versionConfig.DefaultVersion,
+		Sources:        c.versionConfig.VersionSources,
+		Cookie:         c.versionConfig.VersionCookie,
 	})
+	middleware.retired = c.retired
+	middleware.unreleased = c.unreleased
+	middleware.clock = c.clock
+	middleware.correlationID = c.correlationID
+	middleware.errorShapers = c.errorShapers
+	middleware.jsonEngine = c.jsonEngine
+	middleware.infrastructurePassthrough = c.passthrough
+	middleware.internalHead = c.internalHead
+	middleware.newerVersions = c.newerVersions
+	middleware.sunsets = c.sunsets
+	middleware.sunsetWarning = c.sunsetWarning
+	middleware.versionKey = c.versionKey
 	return middleware.Middleware()
 }
 
@@ -52,6 +205,11 @@ func (c *Epoch) VersionBundle() *VersionBundle {
 	return c.versionBundle
 }
 
+// JSONEngine returns the engine used to parse and serialize migrated bodies
+func (c *Epoch) JSONEngine() JSONEngine {
+	return c.jsonEngine
+}
+
 // EndpointRegistry returns the endpoint registry (for OpenAPI schema generation)
 func (c *Epoch) EndpointRegistry() *EndpointRegistry {
 	return c.endpointRegistry
@@ -67,6 +225,10 @@ type HandlerWrapper struct {
 	responseNestedObjects map[string]reflect.Type // Auto-populated from response type
 	requestNestedArrays   map[string]reflect.Type // Auto-populated from request type
 	requestNestedObjects  map[string]reflect.Type // Auto-populated from request type
+	changes               []*VersionChange        // Changes bound to the endpoint (see WithChanges)
+	errorMigration        *ErrorMigration         // Overrides the Epoch's error migration (see WithErrorMigration)
+	newerFields           NewerFieldPolicy        // Overrides the Epoch's newer field policy (see WithNewerRequestFields)
+	cacheKey              CacheKeyFunc            // Keys the endpoint's cached responses (see CacheResponses)
 }
 
 // WrapHandler wraps a Gin handler to provide automatic request/response migration
@@ -162,18 +324,114 @@ func (hw *HandlerWrapper) buildEndpointDefinition(method, pathPattern string) *E
 func (hw *HandlerWrapper) ToHandlerFunc(method, pathPattern string) gin.HandlerFunc {
 	// Build and register endpoint definition immediately
 	def := hw.buildEndpointDefinition(method, pathPattern)
+	def.Since = hw.epoch.endpointSince(def)
+	if len(hw.changes) == 0 {
+		if err := hw.epoch.checkPrunedTypes(def); err != nil {
+			panic("epoch: " + err.Error())
+		}
+	}
 	hw.epoch.endpointRegistry.Register(method, pathPattern, def)
 
-	// Return handler that uses version-aware processing
-	return func(c *gin.Context) {
-		versionAwareHandler := NewVersionAwareHandler(
-			hw.handler,
-			hw.epoch.versionBundle,
-			hw.epoch.migrationChain,
-			hw.epoch.endpointRegistry,
-		)
-		versionAwareHandler.HandlerFunc()(c)
+	// Build the version-aware handler once; it holds no per-request state
+	versionAwareHandler := NewVersionAwareHandler(
+		hw.handler,
+		hw.epoch.versionBundle,
+		hw.migrationChain(def),
+		hw.epoch.endpointRegistry,
+	).WithJSONEngine(hw.epoch.jsonEngine)
+
+	if hw.epoch.responseTemplates {
+		versionAwareHandler.WithResponseTemplates(def)
+	}
+	if hw.epoch.migrationDebugHeader {
+		versionAwareHandler.WithMigrationDebugHeader()
+	}
+	urlRewriter := hw.epoch.urlRewriter
+	if chain := versionAwareHandler.migrationChain; chain.hasPathParamOperations() {
+		urlRewriter = chain.pathParamURLRewriter(hw.epoch.versionBundle.GetHeadVersion(), urlRewriter)
+	}
+	if urlRewriter != nil {
+		versionAwareHandler.WithURLRewriter(urlRewriter, hw.epoch.urlBodyFields...)
+	}
+	if hw.epoch.migrationTimeout > 0 {
+		versionAwareHandler.WithMigrationTimeout(hw.epoch.migrationTimeout)
+	}
+	if hw.epoch.panicFallback {
+		versionAwareHandler.WithPanicFallback()
+	}
+	if hw.epoch.strictSchemaMatching {
+		versionAwareHandler.WithStrictSchemaMatching()
+	}
+	if hw.epoch.originalBodyLimit > 0 {
+		versionAwareHandler.WithOriginalRequestBody(hw.epoch.originalBodyLimit)
+	}
+	if len(hw.epoch.interceptors) > 0 {
+		versionAwareHandler.WithResponseInterceptors(hw.epoch.interceptors...)
+	}
+	for _, entry := range hw.epoch.outputFormats {
+		versionAwareHandler.WithOutputFormat(entry.version, entry.format)
+	}
+	if len(hw.epoch.strippedVersions) > 0 {
+		versionAwareHandler.WithUnknownFieldStripping(hw.epoch.strippedVersions...)
+	}
+	for _, allowlist := range hw.epoch.allowlists {
+		versionAwareHandler.WithFieldAllowlist(allowlist.typ, allowlist.version, allowlist.fields)
+	}
+	if hw.epoch.structuredErrors != nil {
+		versionAwareHandler.WithStructuredErrors(*hw.epoch.structuredErrors)
+	}
+	if hw.epoch.schemaDiagnostics {
+		versionAwareHandler.WithSchemaMatchDiagnostics(hw.epoch.schemaMatchObserver)
+	}
+	if hw.epoch.passthrough {
+		versionAwareHandler.WithInfrastructurePassthrough()
+	}
+	if hw.epoch.circuitBreaker != nil {
+		config := *hw.epoch.circuitBreaker
+		if config.Clock == nil {
+			config.Clock = hw.epoch.clock
+		}
+		versionAwareHandler.WithCircuitBreaker(config)
+	}
+	if hw.epoch.snapshots {
+		versionAwareHandler.WithResponseSnapshots()
+	}
+	if hw.epoch.normalizeResponses {
+		versionAwareHandler.WithResponseNormalization()
+	}
+	if hw.epoch.stats != nil {
+		versionAwareHandler.withMigrationStats(hw.epoch.stats)
 	}
+	if hw.epoch.migrationLog != nil {
+		versionAwareHandler.withMigrationLog(hw.epoch.migrationLog)
+	}
+	if hw.epoch.fixtureCapture != nil {
+		versionAwareHandler.withFixtureCapture(hw.epoch.fixtureCapture)
+	}
+	versionAwareHandler.withVersionKey(hw.epoch.versionKey)
+	if hw.epoch.canary != nil {
+		config := *hw.epoch.canary
+		config.unreleased = hw.epoch.IsUnreleased
+		versionAwareHandler.WithCanary(config)
+	}
+	if hw.errorMigration != nil {
+		versionAwareHandler.WithErrorMigration(hw.errorMigration)
+	} else if hw.epoch.errorMigration != nil {
+		versionAwareHandler.WithErrorMigration(hw.epoch.errorMigration)
+	}
+
+	if hw.cacheKey != nil && hw.epoch.responseCache != nil {
+		versionAwareHandler.WithResponseCache(hw.epoch.responseCache, hw.cacheKey)
+	}
+
+	if hw.newerFields != 0 {
+		versionAwareHandler.WithNewerRequestFields(hw.newerFields)
+	} else if hw.epoch.newerFields != 0 {
+		versionAwareHandler.WithNewerRequestFields(hw.epoch.newerFields)
+	}
+
+	// Return handler that uses version-aware processing
+	return versionAwareHandler.HandlerFunc()
 }
 
 // GetVersionBundle returns the version bundle
@@ -186,6 +444,11 @@ func (c *Epoch) GetMigrationChain() *MigrationChain {
 	return c.migrationChain
 }
 
+// Types returns the registered types: those passed to WithTypes and every type nested in them
+func (c *Epoch) Types() []reflect.Type {
+	return append([]reflect.Type(nil), c.types...)
+}
+
 // GetVersions returns all configured versions
 func (c *Epoch) GetVersions() []*Version {
 	return c.versionBundle.GetVersions()
@@ -203,11 +466,58 @@ func (c *Epoch) ParseVersion(versionStr string) (*Version, error) {
 
 // EpochBuilder provides a fluent API for building Epoch instances
 type EpochBuilder struct {
-	versions      []*Version
-	changes       []*VersionChange
-	types         []reflect.Type
-	versionConfig VersionConfig
-	errors        []error // Accumulated errors during building
+	versions            []*Version
+	changes             []*VersionChange
+	types               []reflect.Type
+	typeFloors          map[reflect.Type]*Version
+	excludedTypes       map[reflect.Type]bool
+	checkTypeReferences bool
+	versionConfig       VersionConfig
+	jsonEngine          JSONEngine
+	templates           bool
+	runtime             bool
+	debugHeader         bool
+	urlRewriter         URLRewriter
+	urlFields           []string
+	timeout             time.Duration
+	panicFallback       bool
+	strictSchemas       bool
+	schemaDiagnostics   bool
+	schemaMatchObserver func(*gin.Context, SchemaMatch)
+	originalBodyLimit   int
+	interceptors        []ResponseInterceptor
+	outputFormats       map[string]outputFormatEntry
+	strippedVersions    []*Version
+	allowlists          []fieldAllowlist
+	structuredErrors    *StructuredErrors
+	clock               Clock
+	unreleased          []unreleasedVersion
+	internalHead        bool
+	newerVersions       NewerVersionPolicy
+	sunsets             []sunsetVersion
+	sunsetWarning       *SunsetWarning
+	modules             []*EpochModule
+	correlationID       CorrelationIDExtractor
+	examples            []interface{}
+	errorMigration      *ErrorMigration
+	errorShapers        []errorShaper
+	passthrough         bool
+	newerFields         NewerFieldPolicy
+	circuitBreaker      *CircuitBreaker
+	snapshots           bool
+	canary              *Canary
+	normalizeResponses  bool
+	stats               bool
+	migrationLog        *MigrationLog
+	sensitiveFields     []string
+	scrubbers           []Scrubber
+	fixtureCapture      *FixtureCapture
+	responseCache       ResponseCache
+	pruneChanges        bool
+	squashUpTo          *Version
+	squashGoldens       []interface{}
+	roundTripCheck      bool
+	errors              []error // Accumulated errors during building
 }
 
 // WithVersions sets the versions for the application
@@ -283,9 +593,129 @@ func (cb *EpochBuilder) WithDefaultVersion(v *Version) *EpochBuilder {
 	return cb
 }
 
+// WithJSONEngine sets the engine used to parse and serialize bodies during migration
+// Defaults to DefaultJSONEngine() (Sonic, or encoding/json with the epoch_stdjson build tag)
+func (cb *EpochBuilder) WithJSONEngine(engine JSONEngine) *EpochBuilder {
+	cb.jsonEngine = engine
+	return cb
+}
+
+// WithResponseTemplates enables precomputed response templates for endpoints whose
+// migrations only add or remove constant fields. Templates are compiled once per
+// endpoint+version when the route is wired with ToHandlerFunc(), and responses are then
+// patched by splicing bytes instead of running the full AST migration.
+func (cb *EpochBuilder) WithResponseTemplates() *EpochBuilder {
+	cb.templates = true
+	return cb
+}
+
+// WithRuntimeRegistration allows endpoints and changes to be registered after Build()
+// via Epoch.RegisterEndpoint() and Epoch.RegisterChange()
+func (cb *EpochBuilder) WithRuntimeRegistration() *EpochBuilder {
+	cb.runtime = true
+	return cb
+}
+
+// WithMigrationDebugHeader writes an X-Epoch-Migrations-Applied header on migrated
+// responses listing the version changes and operations applied, for triaging field shape issues
+func (cb *EpochBuilder) WithMigrationDebugHeader() *EpochBuilder {
+	cb.debugHeader = true
+	return cb
+}
+
+// WithURLRewriter rewrites resource URLs for clients of older versions: Location,
+// Content-Location and Link headers, plus the given URL-valued body fields (dotted paths
+// in HEAD's structure). Use it when older versions used a different path layout. The same
+// URLs get path parameters mapped with MapPathParam; a nil rewriter only names the fields.
+func (cb *EpochBuilder) WithURLRewriter(rewriter URLRewriter, bodyFields ...string) *EpochBuilder {
+	cb.urlRewriter = rewriter
+	cb.urlFields = bodyFields
+	return cb
+}
+
+// WithMigrationTimeout bounds how long migrating a request, and separately its response, may
+// take. Migrations that run over answer 504; those whose client disconnected stop early.
+func (cb *EpochBuilder) WithMigrationTimeout(timeout time.Duration) *EpochBuilder {
+	cb.timeout = timeout
+	return cb
+}
+
+// WithPanicFallback serves the unmigrated body when a transformer panics, instead of
+// answering 500: handlers get the client's request as sent, clients get HEAD's response
+func (cb *EpochBuilder) WithPanicFallback() *EpochBuilder {
+	cb.panicFallback = true
+	return cb
+}
+
+// WithStrictSchemaMatching fails responses whose body doesn't match the type registered with
+// Returns() with a 500 and diagnostics, instead of shipping them unmigrated. Enable it in CI
+// and staging, not in production.
+func (cb *EpochBuilder) WithStrictSchemaMatching() *EpochBuilder {
+	cb.strictSchemas = true
+	return cb
+}
+
+// WithSchemaMatchDiagnostics records how each request and response body matches the type
+// registered for its endpoint (score, matched, missing and unknown fields), to explain why a
+// type's migrations left a payload unchanged. Diagnostics are readable with GetSchemaMatches
+// and passed to observer when it's not nil.
+func (cb *EpochBuilder) WithSchemaMatchDiagnostics(observer func(*gin.Context, SchemaMatch)) *EpochBuilder {
+	cb.schemaDiagnostics = true
+	cb.schemaMatchObserver = observer
+	return cb
+}
+
+// WithOriginalRequestBody retains up to maxBytes of each request body exactly as the client
+// sent it, before migration, so audit logging can read it with OriginalRequestBody
+func (cb *EpochBuilder) WithOriginalRequestBody(maxBytes int) *EpochBuilder {
+	cb.originalBodyLimit = maxBytes
+	return cb
+}
+
+// WithResponseInterceptors wraps the response writer of every wrapped handler with interceptors,
+// the first outermost (closest to the client). They see responses migrated to the client's version.
+func (cb *EpochBuilder) WithResponseInterceptors(interceptors ...ResponseInterceptor) *EpochBuilder {
+	cb.interceptors = append(cb.interceptors, interceptors...)
+	return cb
+}
+
+// WithOutputFormat sets how migrated responses for version are written, e.g. indented or in
+// the response type's field order, for clients that depend on an older framework's output
+func (cb *EpochBuilder) WithOutputFormat(version *Version, format OutputFormat) *EpochBuilder {
+	if cb.outputFormats == nil {
+		cb.outputFormats = make(map[string]outputFormatEntry)
+	}
+	cb.outputFormats[version.String()] = outputFormatEntry{version: version, format: format}
+	return cb
+}
+
+// WithUnknownFieldStripping removes fields the response type doesn't declare from responses
+// migrated to versions, for strict clients. Other versions pass unknown fields through untouched.
+func (cb *EpochBuilder) WithUnknownFieldStripping(versions ...*Version) *EpochBuilder {
+	cb.strippedVersions = append(cb.strippedVersions, versions...)
+	return cb
+}
+
+// WithStructuredErrors transforms error responses through a machine-readable errors array:
+// each error's field is renamed for the client's version, rather than substring-replacing
+// field names in messages, and messages are re-rendered per locale when renderers are given
+func (cb *EpochBuilder) WithStructuredErrors(config StructuredErrors) *EpochBuilder {
+	cb.structuredErrors = &config
+	return cb
+}
+
 // WithTypes registers multiple types for schema generation
+// Types nested in them (fields, slices, pointers) are registered at Build() too, so
+// listing top-level request and response types is enough. Wrap a type with SinceVersion
+// to declare the earliest version it exists in.
 func (cb *EpochBuilder) WithTypes(types ...interface{}) *EpochBuilder {
 	for _, t := range types {
+		if floor, ok := t.(TypeFloor); ok {
+			if reflectType := cb.registerTypeFloor(floor); reflectType != nil {
+				cb.types = append(cb.types, reflectType)
+			}
+			continue
+		}
 		reflectType := reflect.TypeOf(t)
 		if reflectType.Kind() == reflect.Ptr {
 			reflectType = reflectType.Elem()
@@ -295,6 +725,59 @@ func (cb *EpochBuilder) WithTypes(types ...interface{}) *EpochBuilder {
 	return cb
 }
 
+// ExcludeTypes keeps types out of the nested type registry built from WithTypes, along
+// with types only reachable through them, e.g. third-party structs Epoch shouldn't walk
+func (cb *EpochBuilder) ExcludeTypes(types ...interface{}) *EpochBuilder {
+	if cb.excludedTypes == nil {
+		cb.excludedTypes = make(map[reflect.Type]bool)
+	}
+	for _, t := range types {
+		cb.excludedTypes[typeOf(t)] = true
+	}
+	return cb
+}
+
+// registeredTypes returns the WithTypes types and their nested types, minus excluded ones
+func (cb *EpochBuilder) registeredTypes() []reflect.Type {
+	var types []reflect.Type
+	seen := make(map[reflect.Type]bool)
+
+	var visit func(reflect.Type)
+	visit = func(t reflect.Type) {
+		for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			t = t.Elem()
+		}
+		if t == nil || seen[t] || cb.excludedTypes[t] {
+			return
+		}
+		seen[t] = true
+		types = append(types, t)
+
+		nestedArrays, nestedObjects := BuildNestedTypeMaps(t)
+		for _, path := range sortedTypePaths(nestedArrays) {
+			visit(nestedArrays[path])
+		}
+		for _, path := range sortedTypePaths(nestedObjects) {
+			visit(nestedObjects[path])
+		}
+	}
+
+	for _, t := range cb.types {
+		visit(t)
+	}
+	return types
+}
+
+// sortedTypePaths returns the paths of a nested type map in a stable order
+func sortedTypePaths(nested map[string]reflect.Type) []string {
+	paths := make([]string, 0, len(nested))
+	for path := range nested {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
 // Build creates the Epoch instance
 func (cb *EpochBuilder) Build() (*Epoch, error) {
 	// Check for accumulated errors from builder methods
@@ -310,40 +793,161 @@ func (cb *EpochBuilder) Build() (*Epoch, error) {
 		return nil, fmt.Errorf("%s", errMsg)
 	}
 
+	if err := cb.mergeModules(); err != nil {
+		return nil, fmt.Errorf("failed to merge modules: %w", err)
+	}
+
 	if len(cb.versions) == 0 {
 		return nil, fmt.Errorf("at least one version must be specified")
 	}
 
+	if err := cb.checkUnreleased(); err != nil {
+		return nil, fmt.Errorf("unreleased version check failed: %w", err)
+	}
+	if err := cb.checkSunsets(); err != nil {
+		return nil, fmt.Errorf("sunset check failed: %w", err)
+	}
+	if err := cb.checkNewerVersionPolicy(); err != nil {
+		return nil, fmt.Errorf("newer version policy check failed: %w", err)
+	}
+
+	types := cb.registeredTypes()
+	if err := cb.checkChangeTargets(types); err != nil {
+		return nil, fmt.Errorf("type reference check failed: %w", err)
+	}
+
+	changes, prunedChanges, err := cb.prunedChainChanges(types)
+	if err != nil {
+		return nil, fmt.Errorf("change pruning failed: %w", err)
+	}
+	unsquashed := changes
+	versions, changes, squashedVersions, err := cb.squashedChainChanges(cb.versions, changes)
+	if err != nil {
+		return nil, fmt.Errorf("version squash failed: %w", err)
+	}
+
 	// Create version bundle (without changes associated yet to avoid validation errors)
-	versionBundle, err := NewVersionBundle(cb.versions)
+	versionBundle, err := NewVersionBundle(versions)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create version bundle: %w", err)
 	}
+	if err := cb.checkTypeFloors(versionBundle); err != nil {
+		return nil, fmt.Errorf("type version check failed: %w", err)
+	}
 
 	// Create migration chain with cycle detection
-	migrationChain, err := NewMigrationChain(cb.changes)
+	migrationChain, err := NewMigrationChain(changes)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create migration chain: %w", err)
 	}
 
 	// Associate changes with their from-versions AFTER validation and cycle detection
 	// This is needed for schema generation to find applicable changes
-	for _, change := range cb.changes {
-		// Find the version that this change migrates from
-		for _, version := range cb.versions {
-			if version.Equal(change.FromVersion()) {
-				version.Changes = append(version.Changes, change)
-				break
-			}
-		}
+	for _, change := range changes {
+		versionBundle.attachChange(change)
 	}
 
-	return &Epoch{
-		versionBundle:    versionBundle,
-		migrationChain:   migrationChain,
-		versionConfig:    cb.versionConfig,
-		endpointRegistry: NewEndpointRegistry(),
-	}, nil
+	// Predicate-based changes (ForTypesMatching) apply to registered types up front
+	for _, change := range changes {
+		change.BindTypes(types...)
+	}
+	if err := checkChangeSetConflicts(cb.changes); err != nil {
+		return nil, err
+	}
+
+	jsonEngine := cb.jsonEngine
+	if jsonEngine == nil {
+		jsonEngine = DefaultJSONEngine()
+	}
+	clock := cb.clock
+	if clock == nil {
+		clock = SystemClock
+	}
+
+	epochInstance := &Epoch{
+		versionBundle:        versionBundle,
+		migrationChain:       migrationChain,
+		versionConfig:        cb.versionConfig,
+		endpointRegistry:     NewEndpointRegistry(),
+		jsonEngine:           jsonEngine,
+		responseTemplates:    cb.templates,
+		runtimeRegistration:  cb.runtime,
+		migrationDebugHeader: cb.debugHeader,
+		urlRewriter:          cb.urlRewriter,
+		urlBodyFields:        cb.urlFields,
+		migrationTimeout:     cb.timeout,
+		panicFallback:        cb.panicFallback,
+		strictSchemaMatching: cb.strictSchemas,
+		schemaDiagnostics:    cb.schemaDiagnostics,
+		schemaMatchObserver:  cb.schemaMatchObserver,
+		originalBodyLimit:    cb.originalBodyLimit,
+		interceptors:         cb.interceptors,
+		outputFormats:        cb.outputFormats,
+		strippedVersions:     cb.strippedVersions,
+		allowlists:           cb.allowlists,
+		structuredErrors:     cb.structuredErrors,
+		clock:                clock,
+		retired:              newRetiredVersions(clock),
+		unreleased:           cb.unreleased,
+		internalHead:         cb.internalHead,
+		newerVersions:        cb.newerVersions,
+		sunsets:              cb.sunsets,
+		sunsetWarning:        cb.sunsetWarning,
+		modules:              cb.modules,
+		correlationID:        cb.correlationID,
+		examples:             cb.examples,
+		errorMigration:       cb.errorMigration,
+		errorShapers:         cb.errorShapers,
+		passthrough:          cb.passthrough,
+		newerFields:          cb.newerFields,
+		circuitBreaker:       cb.circuitBreaker,
+		snapshots:            cb.snapshots,
+		canary:               cb.canary,
+		normalizeResponses:   cb.normalizeResponses,
+		responseCache:        cb.responseCache,
+		prunedChanges:        prunedChanges,
+		squashedVersions:     squashedVersions,
+		ambiguousTypes:       cb.ambiguousTypes(types),
+		types:                types,
+		typeFloors:           cb.typeFloors,
+		versionKey:           newInstanceVersionKey(),
+		mu:                   &sync.Mutex{},
+	}
+	if cb.stats {
+		epochInstance.stats = newMigrationStats()
+	}
+	epochInstance.scrubber = newBodyScrubber(cb.sensitiveFields, cb.scrubbers)
+	if cb.migrationLog != nil {
+		config := *cb.migrationLog
+		if config.Clock == nil {
+			config.Clock = clock
+		}
+		config.Scrub = epochInstance.scrubber.then(config.Scrub)
+		epochInstance.migrationLog = newMigrationLogger(config)
+	}
+	if cb.fixtureCapture != nil {
+		config := *cb.fixtureCapture
+		config.Scrub = epochInstance.scrubber.then(config.Scrub)
+		epochInstance.fixtureCapture = newFixtureRecorder(config)
+	}
+	if cb.squashUpTo != nil {
+		original, err := NewMigrationChain(unsquashed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create migration chain: %w", err)
+		}
+		if err := epochInstance.verifySquash(original, cb.squashGoldens); err != nil {
+			return nil, fmt.Errorf("version squash failed: %w", err)
+		}
+	}
+	if err := epochInstance.VerifyExamples().Err(); err != nil {
+		return nil, fmt.Errorf("example verification failed: %w", err)
+	}
+	if cb.roundTripCheck {
+		if err := epochInstance.CheckRoundTrips().Err(); err != nil {
+			return nil, fmt.Errorf("round trip check failed: %w", err)
+		}
+	}
+	return epochInstance, nil
 }
 
 // Convenience functions for common setups
@@ -1,6 +1,8 @@
 package epoch
 
 import (
+	"reflect"
+
 	"github.com/gin-gonic/gin"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -15,6 +17,24 @@ type TestUserRequest struct {
 	Name string `json:"name"`
 }
 
+type registryProfile struct {
+	Skills   []registrySkill   `json:"skills"`
+	Settings *registrySettings `json:"settings"`
+}
+
+type registrySkill struct {
+	Name  string        `json:"name"`
+	Level registryLevel `json:"level"`
+}
+
+type registryLevel struct {
+	Rank int `json:"rank"`
+}
+
+type registrySettings struct {
+	Theme string `json:"theme"`
+}
+
 var _ = Describe("Cadwyn", func() {
 	var (
 		v1, v2 *Version
@@ -125,6 +145,43 @@ var _ = Describe("Cadwyn", func() {
 				result := builder.WithTypes(user)
 				Expect(result).To(Equal(builder))
 			})
+
+			It("should register every nested type at Build", func() {
+				epochInstance, err := builder.WithVersions(v1, v2).WithTypes(registryProfile{}).Build()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(epochInstance.Types()).To(Equal([]reflect.Type{
+					reflect.TypeOf(registryProfile{}),
+					reflect.TypeOf(registrySkill{}),
+					reflect.TypeOf(registryLevel{}),
+					reflect.TypeOf(registrySettings{}),
+				}))
+			})
+
+			It("should leave out excluded types and the types only they reach", func() {
+				epochInstance, err := builder.WithVersions(v1, v2).
+					WithTypes(registryProfile{}).
+					ExcludeTypes(&registrySkill{}).
+					Build()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(epochInstance.Types()).To(Equal([]reflect.Type{
+					reflect.TypeOf(registryProfile{}),
+					reflect.TypeOf(registrySettings{}),
+				}))
+			})
+
+			It("should bind predicate-based changes to the registered types", func() {
+				change := NewVersionChangeBuilder(v1, v2).
+					ForTypesMatching(func(t reflect.Type) bool { return t == reflect.TypeOf(registrySkill{}) }).
+					ResponseToPreviousVersion().
+					RemoveField("level").
+					Build()
+				_, err := builder.WithVersions(v1, v2).WithTypes(registryProfile{}).WithChanges(change).Build()
+				Expect(err).NotTo(HaveOccurred())
+
+				entries := change.ChangelogEntries()
+				Expect(entries).To(HaveLen(1))
+				Expect(entries[0].Type).To(Equal(reflect.TypeOf(registrySkill{})))
+			})
 		})
 
 		Describe("Build", func() {
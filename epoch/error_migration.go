@@ -0,0 +1,112 @@
+package epoch
+
+import "net/http"
+
+// StatusClass is a class of HTTP status codes: Status4xx covers 400-499
+type StatusClass int
+
+const (
+	Status4xx StatusClass = 4
+	Status5xx StatusClass = 5
+)
+
+// ErrorMigration selects the error responses (status >= 400) Epoch migrates. Responses it
+// leaves out reach the client exactly as the handler wrote them, e.g. so auth failures keep
+// the body an auth layer produced. Successful responses are always migrated.
+type ErrorMigration struct {
+	classes  map[StatusClass]bool
+	included map[int]bool
+	excluded map[int]bool
+}
+
+// MigrateErrors migrates error responses in the given status classes:
+// MigrateErrors(Status4xx).ExceptStatus(401, 403). With no classes, no error response is
+// migrated unless added with AndStatus.
+func MigrateErrors(classes ...StatusClass) *ErrorMigration {
+	m := &ErrorMigration{
+		classes:  make(map[StatusClass]bool),
+		included: make(map[int]bool),
+		excluded: make(map[int]bool),
+	}
+	for _, class := range classes {
+		m.classes[class] = true
+	}
+	return m
+}
+
+// ExceptStatus leaves error responses with these status codes unmigrated
+func (m *ErrorMigration) ExceptStatus(codes ...int) *ErrorMigration {
+	for _, code := range codes {
+		m.excluded[code] = true
+		delete(m.included, code)
+	}
+	return m
+}
+
+// AndStatus also migrates error responses with these status codes
+func (m *ErrorMigration) AndStatus(codes ...int) *ErrorMigration {
+	for _, code := range codes {
+		m.included[code] = true
+		delete(m.excluded, code)
+	}
+	return m
+}
+
+// Migrates reports whether a response with the status code is migrated. A nil
+// ErrorMigration migrates every response.
+func (m *ErrorMigration) Migrates(status int) bool {
+	if m == nil || status < 400 {
+		return true
+	}
+	if m.excluded[status] {
+		return false
+	}
+	return m.included[status] || m.classes[StatusClass(status/100)]
+}
+
+// WithErrorMigration limits which error responses the handler migrates
+func (vah *VersionAwareHandler) WithErrorMigration(m *ErrorMigration) *VersionAwareHandler {
+	vah.errorMigration = m
+	return vah
+}
+
+// WithErrorMigration limits which error responses every wrapped handler migrates; by default
+// all are. Endpoints can override it with HandlerWrapper.WithErrorMigration.
+func (cb *EpochBuilder) WithErrorMigration(m *ErrorMigration) *EpochBuilder {
+	cb.errorMigration = m
+	return cb
+}
+
+// WithErrorMigration limits which error responses this endpoint migrates, overriding the
+// Epoch's WithErrorMigration
+func (hw *HandlerWrapper) WithErrorMigration(m *ErrorMigration) *HandlerWrapper {
+	hw.errorMigration = m
+	return hw
+}
+
+// WithInfrastructurePassthrough guarantees 429 and 5xx responses, such as 502/503/504 from
+// upstream proxies, reach the client as written: never body-migrated, whatever
+// WithErrorMigration selects, nor reshaped by WithErrorShaper.
+func (cb *EpochBuilder) WithInfrastructurePassthrough() *EpochBuilder {
+	cb.passthrough = true
+	return cb
+}
+
+// WithInfrastructurePassthrough leaves 429 and 5xx responses unmigrated
+func (vah *VersionAwareHandler) WithInfrastructurePassthrough() *VersionAwareHandler {
+	vah.infrastructurePassthrough = true
+	return vah
+}
+
+// isInfrastructureStatus reports whether a status comes from rate limiting or a failing server
+func isInfrastructureStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+// migratesStatus reports whether the handler migrates a response with the status code
+func (vah *VersionAwareHandler) migratesStatus(status int) bool {
+	if vah.infrastructurePassthrough && isInfrastructureStatus(status) {
+		return false
+	}
+	return vah.errorMigration.Migrates(status)
+}
@@ -0,0 +1,94 @@
+package epoch
+
+import (
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type errorMigrationUser struct {
+	ID       int    `json:"id"`
+	FullName string `json:"full_name"`
+}
+
+var _ = Describe("Error migration", func() {
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+	})
+
+	Describe("ErrorMigration", func() {
+		It("should migrate every response without a policy", func() {
+			var m *ErrorMigration
+			Expect(m.Migrates(200)).To(BeTrue())
+			Expect(m.Migrates(401)).To(BeTrue())
+			Expect(m.Migrates(503)).To(BeTrue())
+		})
+
+		It("should select error responses by class and status", func() {
+			m := MigrateErrors(Status4xx).ExceptStatus(401, 403).AndStatus(503)
+			Expect(m.Migrates(200)).To(BeTrue())
+			Expect(m.Migrates(422)).To(BeTrue())
+			Expect(m.Migrates(401)).To(BeFalse())
+			Expect(m.Migrates(403)).To(BeFalse())
+			Expect(m.Migrates(500)).To(BeFalse())
+			Expect(m.Migrates(503)).To(BeTrue())
+		})
+
+		It("should migrate no error responses without classes", func() {
+			m := MigrateErrors()
+			Expect(m.Migrates(201)).To(BeTrue())
+			Expect(m.Migrates(400)).To(BeFalse())
+			Expect(m.AndStatus(400).Migrates(400)).To(BeTrue())
+		})
+	})
+
+	// serve answers status from a handler whose endpoint may override the Epoch's policy
+	serve := func(global, endpoint *ErrorMigration, status int) string {
+		v1, v2, _ := newTestVersions()
+		change := NewVersionChangeBuilder(v1, v2).
+			ForType(errorMigrationUser{}).
+			ResponseToPreviousVersion().
+			RenameField("full_name", "name").
+			Build()
+		var options []func(*EpochBuilder) *EpochBuilder
+		if global != nil {
+			options = append(options, func(builder *EpochBuilder) *EpochBuilder {
+				return builder.WithErrorMigration(global)
+			})
+		}
+		epochInstance, err := setupHeadEpoch([]*Version{v1, v2}, []*VersionChange{change}, options...)
+		Expect(err).NotTo(HaveOccurred())
+
+		router := setupRouterWithMiddleware(epochInstance)
+		wrapper := epochInstance.WrapHandler(func(c *gin.Context) {
+			c.JSON(status, errorMigrationUser{ID: 1, FullName: "Ada"})
+		}).Returns(errorMigrationUser{})
+		if endpoint != nil {
+			wrapper = wrapper.WithErrorMigration(endpoint)
+		}
+		router.GET("/users/:id", wrapper.ToHandlerFunc("GET", "/users/:id"))
+
+		recorder := serveVersioned(router, httptest.NewRequest("GET", "/users/1", nil), "2024-01-01")
+		Expect(recorder.Code).To(Equal(status))
+		return recorder.Body.String()
+	}
+
+	It("should migrate every error response by default", func() {
+		Expect(serve(nil, nil, 401)).To(MatchJSON(`{"id":1,"name":"Ada"}`))
+	})
+
+	It("should leave excluded error responses as the handler wrote them", func() {
+		policy := MigrateErrors(Status4xx).ExceptStatus(401, 403)
+		Expect(serve(policy, nil, 200)).To(MatchJSON(`{"id":1,"name":"Ada"}`))
+		Expect(serve(policy, nil, 422)).To(MatchJSON(`{"id":1,"name":"Ada"}`))
+		Expect(serve(policy, nil, 401)).To(MatchJSON(`{"id":1,"full_name":"Ada"}`))
+		Expect(serve(policy, nil, 500)).To(MatchJSON(`{"id":1,"full_name":"Ada"}`))
+	})
+
+	It("should let endpoints override the Epoch's policy", func() {
+		Expect(serve(MigrateErrors(), MigrateErrors(Status5xx), 500)).To(MatchJSON(`{"id":1,"name":"Ada"}`))
+		Expect(serve(nil, MigrateErrors(Status5xx), 422)).To(MatchJSON(`{"id":1,"full_name":"Ada"}`))
+	})
+})
@@ -0,0 +1,142 @@
+package epoch
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+// wrappedResponseKey marks responses written by a wrapped handler, which migrate their own errors
+const wrappedResponseKey = "epoch.wrapped_response"
+
+// errorShaper reshapes error bodies for clients on version or older
+type errorShaper struct {
+	version *Version
+	shape   func(*ResponseInfo) error
+}
+
+// WithErrorShaper reshapes error responses (status >= 400) that middleware outside wrapped
+// handlers writes, such as auth failures or rate limiting, for clients on version or older.
+// Those bodies never reach type-based migration; shape converts HEAD's error envelope into
+// the one version's clients expect. Several shapers run newest version first, like changes.
+func (cb *EpochBuilder) WithErrorShaper(version *Version, shape func(*ResponseInfo) error) *EpochBuilder {
+	if version == nil || version.IsHead {
+		cb.errors = append(cb.errors, fmt.Errorf("error shapers need a version older than head"))
+		return cb
+	}
+	cb.errorShapers = append(cb.errorShapers, errorShaper{version: version, shape: shape})
+	sort.SliceStable(cb.errorShapers, func(i, j int) bool {
+		return cb.errorShapers[i].version.IsNewerThan(cb.errorShapers[j].version)
+	})
+	return cb
+}
+
+// errorShapersFor returns the shapers applying to clients on version, newest first
+func (vm *VersionMiddleware) errorShapersFor(version *Version) []errorShaper {
+	if version.IsHead {
+		return nil
+	}
+	var shapers []errorShaper
+	for _, shaper := range vm.errorShapers {
+		if !shaper.version.IsOlderThan(version) {
+			shapers = append(shapers, shaper)
+		}
+	}
+	return shapers
+}
+
+// shapeErrors runs the rest of the chain, reshaping the error response it writes unless a
+// wrapped handler wrote it
+func (vm *VersionMiddleware) shapeErrors(c *gin.Context, shapers []errorShaper) {
+	writer := &errorBuffer{ResponseWriter: c.Writer}
+	c.Writer = writer
+	defer func() {
+		c.Writer = writer.ResponseWriter
+		if !writer.buffering {
+			return
+		}
+		body := writer.body
+		if !c.GetBool(wrappedResponseKey) && !(vm.infrastructurePassthrough && isInfrastructureStatus(writer.status)) {
+			shaped, err := vm.shapeErrorBody(c, writer.status, body, shapers)
+			if err != nil {
+				_ = c.Error(err)
+			} else {
+				body = shaped
+			}
+		}
+		c.Writer.WriteHeader(writer.status)
+		_, _ = c.Writer.Write(body)
+	}()
+	c.Next()
+}
+
+// shapeErrorBody runs the shapers over a JSON error body; other bodies are returned as is
+func (vm *VersionMiddleware) shapeErrorBody(c *gin.Context, status int, body []byte, shapers []errorShaper) ([]byte, error) {
+	if len(body) == 0 {
+		return body, nil
+	}
+	node, err := vm.jsonEngine.Parse(body)
+	if err != nil {
+		return body, nil
+	}
+	info := NewResponseInfo(c, node)
+	info.StatusCode = status
+	info.Headers = c.Writer.Header()
+	for _, shaper := range shapers {
+		if err := shaper.shape(info); err != nil {
+			return nil, fmt.Errorf("error shaper for %s failed: %w", shaper.version, err)
+		}
+	}
+	if info.Body == nil {
+		return nil, nil
+	}
+	return vm.jsonEngine.Serialize(info.Body)
+}
+
+// errorBuffer holds back error responses so they can be reshaped; others are written through
+type errorBuffer struct {
+	gin.ResponseWriter
+	status    int
+	buffering bool
+	body      []byte
+}
+
+func (w *errorBuffer) WriteHeader(code int) {
+	if code >= http.StatusBadRequest {
+		w.status = code
+		w.buffering = true
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *errorBuffer) WriteHeaderNow() {
+	if !w.buffering {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func (w *errorBuffer) Write(data []byte) (int, error) {
+	if w.buffering {
+		w.body = append(w.body, data...)
+		return len(data), nil
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *errorBuffer) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *errorBuffer) Status() int {
+	if w.buffering {
+		return w.status
+	}
+	return w.ResponseWriter.Status()
+}
+
+func (w *errorBuffer) Written() bool {
+	return w.buffering || w.ResponseWriter.Written()
+}
@@ -0,0 +1,118 @@
+package epoch
+
+import (
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Error shaping", func() {
+	var v1, v2, v3 *Version
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		v1, v2, v3 = newTestVersions()
+	})
+
+	// flatten turns HEAD's {"error": {"code", "message"}} into {"code", "message"}
+	flatten := func(resp *ResponseInfo) error {
+		code, err := GetNodeStringAtPath(resp.Body, "error.code")
+		if err != nil {
+			return err
+		}
+		message, err := GetNodeStringAtPath(resp.Body, "error.message")
+		if err != nil {
+			return err
+		}
+		if err := DeleteNodeField(resp.Body, "error"); err != nil {
+			return err
+		}
+		if err := SetNodeField(resp.Body, "code", code); err != nil {
+			return err
+		}
+		return SetNodeField(resp.Body, "message", message)
+	}
+
+	setup := func(builder *EpochBuilder) *gin.Engine {
+		epochInstance, err := builder.Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		router := setupRouterWithMiddleware(epochInstance)
+		router.Use(func(c *gin.Context) {
+			if c.GetHeader("Authorization") == "" && c.Request.URL.Path != "/public" {
+				c.AbortWithStatusJSON(401, gin.H{"error": gin.H{"code": "unauthorized", "message": "missing token"}})
+			}
+		})
+		router.GET("/public", func(c *gin.Context) {
+			c.JSON(200, gin.H{"error": gin.H{"code": "none", "message": "not an error"}})
+		})
+		router.POST("/users", epochInstance.WrapHandler(func(c *gin.Context) {
+			c.JSON(422, gin.H{"error": gin.H{"code": "invalid", "message": "bad user"}})
+		}).Returns(errorMigrationUser{}).ToHandlerFunc("POST", "/users"))
+		return router
+	}
+
+	serve := func(router *gin.Engine, method, path, version string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if method == "POST" {
+			req.Header.Set("Authorization", "Bearer token")
+		}
+		return serveVersioned(router, req, version)
+	}
+
+	newBuilder := func() *EpochBuilder {
+		return NewEpoch().WithVersions(v1, v2, v3).WithHeadVersion().WithChanges(
+			NewVersionChangeBuilder(v1, v2).
+				ForType(errorMigrationUser{}).
+				ResponseToPreviousVersion().
+				RenameField("full_name", "name").
+				Build())
+	}
+
+	It("should reshape error bodies from middleware for clients on the version or older", func() {
+		router := setup(newBuilder().WithErrorShaper(v2, flatten))
+
+		for _, version := range []string{"2024-01-01", "2024-06-01"} {
+			recorder := serve(router, "GET", "/orders", version)
+			Expect(recorder.Code).To(Equal(401))
+			Expect(recorder.Body.String()).To(MatchJSON(`{"code":"unauthorized","message":"missing token"}`))
+		}
+	})
+
+	It("should leave newer versions and HEAD alone", func() {
+		router := setup(newBuilder().WithErrorShaper(v2, flatten))
+
+		for _, version := range []string{"2025-01-01", "head"} {
+			recorder := serve(router, "GET", "/orders", version)
+			Expect(recorder.Code).To(Equal(401))
+			Expect(recorder.Body.String()).To(MatchJSON(`{"error":{"code":"unauthorized","message":"missing token"}}`))
+		}
+	})
+
+	It("should run shapers newest version first", func() {
+		rename := func(resp *ResponseInfo) error { return RenameNodeField(resp.Body, "message", "detail") }
+		router := setup(newBuilder().WithErrorShaper(v1, rename).WithErrorShaper(v3, flatten))
+
+		recorder := serve(router, "GET", "/orders", "2024-01-01")
+		Expect(recorder.Body.String()).To(MatchJSON(`{"code":"unauthorized","detail":"missing token"}`))
+	})
+
+	It("should leave wrapped handlers' errors and successful responses to migration", func() {
+		router := setup(newBuilder().WithErrorShaper(v2, flatten))
+
+		recorder := serve(router, "POST", "/users", "2024-01-01")
+		Expect(recorder.Code).To(Equal(422))
+		Expect(recorder.Body.String()).To(MatchJSON(`{"error":{"code":"invalid","message":"bad user"}}`))
+
+		recorder = serve(router, "GET", "/public", "2024-01-01")
+		Expect(recorder.Code).To(Equal(200))
+		Expect(recorder.Body.String()).To(MatchJSON(`{"error":{"code":"none","message":"not an error"}}`))
+	})
+
+	It("should reject shapers for HEAD", func() {
+		_, err := newBuilder().WithErrorShaper(HeadVersion(), flatten).Build()
+		Expect(err).To(MatchError(ContainSubstring("error shapers need a version older than head")))
+	})
+})
@@ -0,0 +1,39 @@
+package epoch
+
+import "errors"
+
+// ErrUnknownVersion is matched by errors for version strings no registered version matches
+var ErrUnknownVersion = errors.New("unknown version")
+
+// ErrMigrationFailed is matched by every error a version change fails a migration with,
+// including *MigrationError and *MigrationPanicError
+var ErrMigrationFailed = errors.New("migration failed")
+
+// ErrSchemaMismatch is matched by *SchemaMismatchError
+var ErrSchemaMismatch = errors.New("schema mismatch")
+
+// MigrationError reports the version change, and the operation within it, that failed a
+// migration. Its message is the underlying error's, so wrapping doesn't change what
+// clients and logs see; use errors.As to branch on where the migration failed.
+type MigrationError struct {
+	Change    string             // Description of the version change
+	From      *Version           // Version the change migrates from
+	To        *Version           // Version the change migrates to
+	Direction TransformDirection // DirectionRequest or DirectionResponse
+	Operation int                // Index of the operation in its list; -1 for custom and global transformers
+	Err       error              // What the transformer or operation returned
+}
+
+func (e *MigrationError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error the transformer or operation returned
+func (e *MigrationError) Unwrap() error {
+	return e.Err
+}
+
+// Is matches ErrMigrationFailed
+func (e *MigrationError) Is(target error) bool {
+	return target == ErrMigrationFailed
+}
@@ -0,0 +1,98 @@
+package epoch
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"github.com/astronomer/epoch/epoch/jsonast"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var errFailingOperation = errors.New("operation failed")
+
+// failingOperation returns errFailingOperation when applied
+type failingOperation struct{}
+
+func (failingOperation) Apply(*jsonast.Node, TransformDirection, *OperationContext) error {
+	return errFailingOperation
+}
+
+func (failingOperation) Describe() OperationDoc {
+	return OperationDoc{Name: "fail"}
+}
+
+var _ = Describe("Error types", func() {
+	var v1, v2 *Version
+
+	BeforeEach(func() {
+		v1, _ = NewDateVersion("2024-01-01")
+		v2, _ = NewDateVersion("2024-06-01")
+	})
+
+	migrate := func(change *VersionChange) error {
+		chain, err := NewMigrationChain([]*VersionChange{change})
+		Expect(err).NotTo(HaveOccurred())
+		body, err := getNode([]byte(`{"id":1,"full_name":"Ada"}`))
+		Expect(err).NotTo(HaveOccurred())
+		info := &ResponseInfo{Body: &body, StatusCode: 200}
+		return chain.MigrateResponseForType(context.Background(), info, reflect.TypeOf(panicItem{}), nil, v2, v1)
+	}
+
+	It("should match unknown versions with ErrUnknownVersion", func() {
+		bundle, err := NewVersionBundle([]*Version{v1, v2})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = bundle.ParseVersion("2030-01-01")
+		Expect(errors.Is(err, ErrUnknownVersion)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("unknown version '2030-01-01'")))
+	})
+
+	It("should attribute operation errors to the change and operation", func() {
+		err := migrate(NewVersionChangeBuilder(v1, v2).
+			Description("Rename full_name").
+			ForType(panicItem{}).
+			ResponseToPreviousVersion().
+			RenameField("full_name", "name").
+			CustomOperation(failingOperation{}).
+			Build())
+
+		Expect(errors.Is(err, ErrMigrationFailed)).To(BeTrue())
+		Expect(errors.Is(err, errFailingOperation)).To(BeTrue())
+		var migrationErr *MigrationError
+		Expect(errors.As(err, &migrationErr)).To(BeTrue())
+		Expect(migrationErr.Change).To(Equal("Rename full_name"))
+		Expect(migrationErr.From).To(Equal(v1))
+		Expect(migrationErr.To).To(Equal(v2))
+		Expect(migrationErr.Direction).To(Equal(DirectionResponse))
+		Expect(migrationErr.Operation).To(Equal(1))
+		Expect(err.Error()).To(Equal("reverse migration failed at 2024-06-01->2024-01-01: " +
+			"type-based response migration failed for change 'Rename full_name' (type: panicItem): operation failed"))
+	})
+
+	It("should attribute custom transformer errors to the change", func() {
+		err := migrate(NewVersionChangeBuilder(v1, v2).
+			CustomResponse(func(*ResponseInfo) error { return errors.New("boom") }).
+			Build())
+
+		var migrationErr *MigrationError
+		Expect(errors.As(err, &migrationErr)).To(BeTrue())
+		Expect(migrationErr.Operation).To(Equal(-1))
+		Expect(migrationErr.To).To(Equal(v2))
+	})
+
+	It("should match panics and schema mismatches with their sentinels", func() {
+		err := migrate(NewVersionChangeBuilder(v1, v2).
+			ForType(panicItem{}).
+			ResponseToPreviousVersion().
+			CustomOperation(panickingOperation{}).
+			Build())
+		Expect(errors.Is(err, ErrMigrationFailed)).To(BeTrue())
+		var migrationErr *MigrationError
+		Expect(errors.As(err, &migrationErr)).To(BeFalse())
+
+		var mismatch error = &SchemaMismatchError{ExpectedType: reflect.TypeOf(panicItem{}), Reason: "body is not valid JSON"}
+		Expect(errors.Is(mismatch, ErrSchemaMismatch)).To(BeTrue())
+		Expect(errors.Is(mismatch, ErrMigrationFailed)).To(BeFalse())
+	})
+})
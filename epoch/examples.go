@@ -0,0 +1,241 @@
+package epoch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/astronomer/epoch/epoch/jsonast"
+)
+
+// ExampleProblem is a migration step an example payload failed
+type ExampleProblem struct {
+	Type      reflect.Type       // Type of the example
+	Direction TransformDirection // DirectionRequest or DirectionResponse
+	From      *Version           // Version the step migrates from; nil when the example can't be encoded
+	To        *Version           // Version the step migrates to
+	Change    string             // Description of the failing change, when known
+	Problem   string             // What went wrong
+}
+
+func (p ExampleProblem) String() string {
+	if p.From == nil {
+		return fmt.Sprintf("%s: %s", p.Type, p.Problem)
+	}
+	direction := "request"
+	if p.Direction == DirectionResponse {
+		direction = "response"
+	}
+	where := fmt.Sprintf("%s %s %s->%s", p.Type, direction, p.From, p.To)
+	if p.Change != "" {
+		where += fmt.Sprintf(" (change '%s')", p.Change)
+	}
+	return where + ": " + p.Problem
+}
+
+// ExampleReport lists the migration steps example payloads failed
+type ExampleReport struct {
+	Problems []ExampleProblem
+}
+
+// Empty reports whether every example migrated through every version
+func (r *ExampleReport) Empty() bool {
+	return len(r.Problems) == 0
+}
+
+// Err returns an error listing every problem, or nil if there are none
+func (r *ExampleReport) Err() error {
+	if r.Empty() {
+		return nil
+	}
+	problems := make([]string, len(r.Problems))
+	for i, problem := range r.Problems {
+		problems[i] = problem.String()
+	}
+	return errors.New("example payloads don't migrate:\n  " + strings.Join(problems, "\n  "))
+}
+
+// WithExamples registers canonical HEAD payloads, one struct value per type, that Build()
+// runs through every version in both directions. Build fails with an ExampleReport error when
+// an operation expects a field the payload doesn't have or a migration step fails, catching
+// configuration mistakes before deploy. Set every field: omitted fields are reported missing.
+func (cb *EpochBuilder) WithExamples(examples ...interface{}) *EpochBuilder {
+	cb.examples = append(cb.examples, examples...)
+	return cb
+}
+
+// VerifyExamples runs the WithExamples payloads through every version. Responses migrate down
+// from HEAD one version at a time. Requests start from the payload each older version sends,
+// derived from HEAD's by undoing the request operations, and migrate up one version at a time.
+func (c *Epoch) VerifyExamples() *ExampleReport {
+	report := &ExampleReport{}
+	versions := c.exampleVersions()
+	for _, example := range c.examples {
+		typ := reflect.TypeOf(example)
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		data, err := json.Marshal(example)
+		if err != nil {
+			report.Problems = append(report.Problems, ExampleProblem{Type: typ, Problem: fmt.Sprintf("failed to encode example: %v", err)})
+			continue
+		}
+		c.verifyResponseExample(report, typ, versions, data)
+		c.verifyRequestExample(report, typ, versions, data)
+	}
+	return report
+}
+
+// exampleVersions returns the versions oldest first, ending with HEAD
+func (c *Epoch) exampleVersions() []*Version {
+	var versions []*Version
+	for _, v := range c.versionBundle.GetVersions() {
+		if !v.IsHead {
+			versions = append(versions, v)
+		}
+	}
+	sort.SliceStable(versions, func(i, j int) bool { return versions[i].IsOlderThan(versions[j]) })
+	return append(versions, c.versionBundle.GetHeadVersion())
+}
+
+// verifyResponseExample migrates a HEAD payload down one version at a time. Versions no
+// change migrates to share the payload of the version after them.
+func (c *Epoch) verifyResponseExample(report *ExampleReport, typ reflect.Type, versions []*Version, data []byte) {
+	for i := len(versions) - 1; i > 0; i-- {
+		newer, older := versions[i], versions[i-1]
+		node, err := c.jsonEngine.Parse(data)
+		if err != nil {
+			report.add(typ, DirectionResponse, newer, older, "", fmt.Sprintf("failed to parse payload: %v", err))
+			return
+		}
+		changes := c.stepChanges(older, newer)
+		if len(changes) == 0 {
+			continue
+		}
+		for _, change := range changes {
+			ops, _ := change.GetResponseOperationsByType(typ)
+			for _, op := range ops {
+				doc := DescribeOperation(op)
+				for _, field := range expectedFields(doc) {
+					if GetNodeAtPath(node, field) == nil {
+						report.add(typ, DirectionResponse, newer, older, change.Description(),
+							fmt.Sprintf("%s: field '%s' is missing", doc.Description, field))
+					}
+				}
+			}
+		}
+
+		migrated, err := c.Transform(context.Background(), typ, DirectionResponse, newer, older, data)
+		if err != nil {
+			report.addMigrationError(typ, DirectionResponse, newer, older, err)
+			return
+		}
+		data = migrated
+	}
+}
+
+// verifyRequestExample derives the payload every older version sends from a HEAD payload,
+// then migrates each one up a version
+func (c *Epoch) verifyRequestExample(report *ExampleReport, typ reflect.Type, versions []*Version, data []byte) {
+	payloads := make(map[*Version][]byte, len(versions))
+	for i := len(versions) - 1; i > 0; i-- {
+		newer, older := versions[i], versions[i-1]
+		node, err := c.jsonEngine.Parse(data)
+		if err != nil {
+			report.add(typ, DirectionRequest, older, newer, "", fmt.Sprintf("failed to parse payload: %v", err))
+			break
+		}
+		for _, change := range c.stepChanges(older, newer) {
+			ops, _ := change.GetRequestOperationsByType(typ)
+			for j := len(ops) - 1; j >= 0; j-- {
+				doc := DescribeOperation(ops[j])
+				if problem := undoRequestOperation(node, doc); problem != "" {
+					report.add(typ, DirectionRequest, older, newer, change.Description(), problem)
+				}
+			}
+		}
+		if data, err = c.jsonEngine.Serialize(node); err != nil {
+			report.add(typ, DirectionRequest, older, newer, "", fmt.Sprintf("failed to serialize payload: %v", err))
+			break
+		}
+		payloads[older] = data
+	}
+
+	for i := 0; i < len(versions)-1; i++ {
+		older, newer := versions[i], versions[i+1]
+		payload, ok := payloads[older]
+		if !ok || len(c.stepChanges(older, newer)) == 0 {
+			continue
+		}
+		if _, err := c.Transform(context.Background(), typ, DirectionRequest, older, newer, payload); err != nil {
+			report.addMigrationError(typ, DirectionRequest, older, newer, err)
+		}
+	}
+}
+
+// undoRequestOperation turns a payload into the one the operation migrated it from,
+// describing the problem when a field the operation produces is missing
+func undoRequestOperation(node *jsonast.Node, doc OperationDoc) string {
+	inverse := doc.Inverse()
+	for _, newer := range sortedKeys(inverse.RenamedFields) {
+		if GetNodeAtPath(node, newer) == nil {
+			return fmt.Sprintf("%s: field '%s' is missing", doc.Description, newer)
+		}
+		if err := MoveNodeAtPath(node, newer, inverse.RenamedFields[newer]); err != nil {
+			return fmt.Sprintf("%s: %v", doc.Description, err)
+		}
+	}
+	for _, field := range inverse.RemovedFields {
+		if GetNodeAtPath(node, field) == nil {
+			return fmt.Sprintf("%s: field '%s' is missing", doc.Description, field)
+		}
+		if err := DeleteNodeAtPath(node, field); err != nil {
+			return fmt.Sprintf("%s: %v", doc.Description, err)
+		}
+	}
+	for _, field := range sortedKeys(inverse.AddedFields) {
+		if err := SetNodeAtPath(node, field, inverse.AddedFields[field]); err != nil {
+			return fmt.Sprintf("%s: %v", doc.Description, err)
+		}
+	}
+	return ""
+}
+
+// expectedFields returns the fields an operation reads from the payload it migrates
+func expectedFields(doc OperationDoc) []string {
+	return append(sortedKeys(doc.RenamedFields), doc.RemovedFields...)
+}
+
+// stepChanges returns the changes migrating between two adjacent versions
+func (c *Epoch) stepChanges(older, newer *Version) []*VersionChange {
+	var changes []*VersionChange
+	for _, change := range c.migrationChain.GetChanges() {
+		if change.FromVersion().Equal(older) && change.ToVersion().Equal(newer) {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// add records a problem with a migration step
+func (r *ExampleReport) add(typ reflect.Type, direction TransformDirection, from, to *Version, change, problem string) {
+	r.Problems = append(r.Problems, ExampleProblem{Type: typ, Direction: direction, From: from, To: to, Change: change, Problem: problem})
+}
+
+// addMigrationError reports a failed step, attributed to the change that failed it when known
+func (r *ExampleReport) addMigrationError(typ reflect.Type, direction TransformDirection, from, to *Version, err error) {
+	var migrationErr *MigrationError
+	var panicErr *MigrationPanicError
+	switch {
+	case errors.As(err, &migrationErr):
+		r.add(typ, direction, from, to, migrationErr.Change, migrationErr.Err.Error())
+	case errors.As(err, &panicErr):
+		r.add(typ, direction, from, to, panicErr.Change, fmt.Sprintf("panic: %v", panicErr.Value))
+	default:
+		r.add(typ, direction, from, to, "", err.Error())
+	}
+}
@@ -0,0 +1,94 @@
+package epoch
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type exampleUser struct {
+	ID       int    `json:"id"`
+	FullName string `json:"full_name"`
+	Email    string `json:"email"`
+}
+
+var _ = Describe("Example verification", func() {
+	var v1, v2, v3 *Version
+
+	BeforeEach(func() {
+		v1, _ = NewDateVersion("2024-01-01")
+		v2, _ = NewDateVersion("2024-06-01")
+		v3, _ = NewDateVersion("2025-01-01")
+	})
+
+	example := exampleUser{ID: 1, FullName: "Ada Lovelace", Email: "ada@example.com"}
+
+	build := func(changes ...*VersionChange) (*Epoch, error) {
+		return NewEpoch().WithVersions(v1, v2, v3).WithHeadVersion().WithChanges(changes...).
+			WithExamples(example).Build()
+	}
+
+	It("should build when the examples migrate through every version", func() {
+		epochInstance, err := build(
+			NewVersionChangeBuilder(v1, v2).
+				ForType(exampleUser{}).
+				RequestToNextVersion().
+				RenameField("name", "full_name").
+				ResponseToPreviousVersion().
+				RenameField("full_name", "name").
+				Build(),
+			NewVersionChangeBuilder(v2, v3).
+				ForType(exampleUser{}).
+				RequestToNextVersion().
+				AddField("email", "unknown@example.com").
+				ResponseToPreviousVersion().
+				RemoveField("email").
+				Build(),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(epochInstance.VerifyExamples().Empty()).To(BeTrue())
+	})
+
+	It("should report response operations expecting missing fields", func() {
+		_, err := build(NewVersionChangeBuilder(v2, v3).
+			Description("Rename fullName").
+			ForType(exampleUser{}).
+			ResponseToPreviousVersion().
+			RenameField("fullName", "name").
+			Build())
+		Expect(err).To(MatchError(ContainSubstring("example verification failed: example payloads don't migrate:\n  " +
+			"epoch.exampleUser response 2025-01-01->2024-06-01 (change 'Rename fullName'): " +
+			"Rename field fullName to name: field 'fullName' is missing")))
+	})
+
+	It("should report request operations producing missing fields", func() {
+		_, err := build(NewVersionChangeBuilder(v1, v2).
+			ForType(exampleUser{}).
+			RequestToNextVersion().
+			RenameField("name", "display_name").
+			Build())
+		Expect(err).To(MatchError(ContainSubstring(
+			"epoch.exampleUser request 2024-01-01->2024-06-01 (change 'Migration from 2024-01-01 to 2024-06-01'): " +
+				"Rename field name to display_name: field 'display_name' is missing")))
+	})
+
+	It("should attribute failing migration steps to their change", func() {
+		_, err := build(NewVersionChangeBuilder(v1, v2).
+			Description("Split name").
+			ForType(exampleUser{}).
+			ResponseToPreviousVersion().
+			CustomOperation(failingOperation{}).
+			Build())
+		Expect(err).To(MatchError(ContainSubstring(
+			"epoch.exampleUser response 2024-06-01->2024-01-01 (change 'Split name'): operation failed")))
+	})
+
+	It("should report examples that can't be encoded", func() {
+		epochInstance, err := NewEpoch().WithVersions(v1).WithHeadVersion().Build()
+		Expect(err).NotTo(HaveOccurred())
+		epochInstance.examples = []interface{}{func() {}}
+
+		report := epochInstance.VerifyExamples()
+		Expect(report.Problems).To(HaveLen(1))
+		Expect(report.Err()).To(MatchError(ContainSubstring("func(): failed to encode example")))
+	})
+})
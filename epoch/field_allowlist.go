@@ -0,0 +1,62 @@
+package epoch
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// fieldAllowlist lists the only response fields a type may have in one version
+type fieldAllowlist struct {
+	typ     reflect.Type
+	version *Version
+	fields  []string // nil derives them from the chain
+}
+
+// EnforceFieldAllowlist strips every response field of typ that fields doesn't list from
+// responses migrated to version, wherever typ appears in them, so fields handlers add later
+// can't leak to clients that must only see the fields version had. A nil fields list is
+// derived from the chain: typ's HEAD fields, renamed and added for version.
+func (cb *EpochBuilder) EnforceFieldAllowlist(typ interface{}, version *Version, fields []string) *EpochBuilder {
+	t := reflect.TypeOf(typ)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		cb.errors = append(cb.errors, fmt.Errorf("field allowlist needs a struct type, got %T", typ))
+		return cb
+	}
+	if version == nil {
+		cb.errors = append(cb.errors, fmt.Errorf("field allowlist for %s needs a version", t.Name()))
+		return cb
+	}
+	cb.allowlists = append(cb.allowlists, fieldAllowlist{typ: t, version: version, fields: fields})
+	return cb
+}
+
+// WithFieldAllowlist strips t's response fields that fields doesn't list from responses
+// migrated to version. A nil fields list keeps the fields t has in version.
+func (vah *VersionAwareHandler) WithFieldAllowlist(t reflect.Type, version *Version, fields []string) *VersionAwareHandler {
+	if vah.fieldAllowlists == nil {
+		vah.fieldAllowlists = make(map[string]map[reflect.Type][]string)
+	}
+	byType := vah.fieldAllowlists[version.String()]
+	if byType == nil {
+		byType = make(map[reflect.Type][]string)
+		vah.fieldAllowlists[version.String()] = byType
+	}
+	byType[t] = fields
+	return vah
+}
+
+// allowlistedFields narrows the fields a type has in a version to the allowed ones, keeping
+// their types so nested objects are still stripped
+func allowlistedFields(fields map[string]reflect.Type, allowed []string) map[string]reflect.Type {
+	if allowed == nil {
+		return fields
+	}
+	narrowed := make(map[string]reflect.Type, len(allowed))
+	for _, name := range allowed {
+		narrowed[name] = fields[name]
+	}
+	return narrowed
+}
@@ -0,0 +1,71 @@
+package epoch
+
+import (
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Field allowlists", func() {
+	var v1, v2 *Version
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		v1, _, v2 = newTestVersions()
+	})
+
+	const handlerBody = `{"id":1,"full_name":"Ada","phone":"555","internal":{"a":1},` +
+		`"address":{"city":"Oslo","geo":"x"},"previous":[{"city":"Rome","geo":"y"}]}`
+
+	serve := func(builder *EpochBuilder, version string) string {
+		epochInstance, err := builder.
+			WithVersions(v1, v2).
+			WithHeadVersion().
+			WithChanges(NewVersionChangeBuilder(v1, v2).
+				ForType(roundTripUser{}).
+				ResponseToPreviousVersion().
+				RenameField("full_name", "name").
+				Build()).
+			Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		router := setupRouterWithMiddleware(epochInstance)
+		router.GET("/users/:id", epochInstance.WrapHandler(func(c *gin.Context) {
+			c.Data(200, "application/json", []byte(handlerBody))
+		}).Returns(roundTripUser{}).ToHandlerFunc("GET", "/users/:id"))
+
+		recorder := serveVersioned(router, httptest.NewRequest("GET", "/users/1", nil), version)
+		Expect(recorder.Code).To(Equal(200))
+		return recorder.Body.String()
+	}
+
+	It("should keep only the listed fields of the type for its version", func() {
+		builder := NewEpoch().EnforceFieldAllowlist(roundTripUser{}, v1, []string{"id", "name", "address"})
+		Expect(serve(builder, "2024-01-01")).To(Equal(`{"id":1,"name":"Ada","address":{"city":"Oslo","geo":"x"}}`))
+	})
+
+	It("should leave other versions and types alone", func() {
+		builder := NewEpoch().EnforceFieldAllowlist(roundTripAddress{}, v1, []string{"city"})
+		Expect(serve(builder, "2025-01-01")).To(Equal(handlerBody))
+
+		v1, _, v2 = newTestVersions()
+		Expect(serve(NewEpoch().EnforceFieldAllowlist(roundTripAddress{}, v1, []string{"city"}), "2024-01-01")).To(Equal(
+			`{"id":1,"name":"Ada","phone":"555","internal":{"a":1},"address":{"city":"Oslo"},"previous":[{"city":"Rome"}]}`))
+	})
+
+	It("should derive the allowlist from the chain when none is given", func() {
+		builder := NewEpoch().EnforceFieldAllowlist(&roundTripUser{}, v2, nil)
+		Expect(serve(builder, "2025-01-01")).To(Equal(
+			`{"id":1,"full_name":"Ada","address":{"city":"Oslo","geo":"x"},"previous":[{"city":"Rome","geo":"y"}]}`))
+	})
+
+	It("should reject allowlists without a struct type or version", func() {
+		_, err := NewEpoch().WithVersions(v1).EnforceFieldAllowlist("user", v1, nil).Build()
+		Expect(err).To(MatchError(ContainSubstring("field allowlist needs a struct type, got string")))
+
+		_, err = NewEpoch().WithVersions(v1).EnforceFieldAllowlist(roundTripUser{}, nil, nil).Build()
+		Expect(err).To(MatchError(ContainSubstring("field allowlist for roundTripUser needs a version")))
+	})
+})
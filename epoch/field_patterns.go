@@ -0,0 +1,140 @@
+package epoch
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/astronomer/epoch/epoch/jsonast"
+)
+
+// ============================================================================
+// Request Operations - TO NEXT VERSION (Client→HEAD)
+// ============================================================================
+
+// RequestRenameFieldMatching renames every field whose name matches Pattern when request migrates from client to HEAD
+// Use case: a naming convention changed across the API, e.g. older clients send "legacy_name", HEAD expects "name"
+type RequestRenameFieldMatching struct {
+	Pattern     *regexp.Regexp // Matched against older/client field names
+	Replacement string         // Replacement template for matches, e.g. "$1"
+	Except      []string       // Field names left unchanged
+}
+
+// Rename returns the HEAD name of an older/client field name
+func (op *RequestRenameFieldMatching) Rename(name string) string {
+	return renameMatching(op.Pattern, op.Replacement, op.Except, name)
+}
+
+func (op *RequestRenameFieldMatching) ApplyToRequest(node *jsonast.Node) error {
+	return renameNodeKeys(node, op.Rename)
+}
+
+func (op *RequestRenameFieldMatching) GetFieldMapping() map[string]string {
+	// Matched names aren't known up front, so error messages keep HEAD's names
+	return nil
+}
+
+// Inverse returns nil; a pattern rename can't be reversed, so request schemas keep HEAD's names
+func (op *RequestRenameFieldMatching) Inverse() RequestToNextVersionOperation {
+	return nil
+}
+
+// ============================================================================
+// Response Operations - TO PREVIOUS VERSION (HEAD→Client)
+// ============================================================================
+
+// ResponseRenameFieldMatching renames every field whose name matches Pattern when response migrates from HEAD to client
+// Use case: a naming convention changed across the API, e.g. HEAD returns "name", older clients expect "legacy_name"
+type ResponseRenameFieldMatching struct {
+	Pattern     *regexp.Regexp // Matched against newer/HEAD field names
+	Replacement string         // Replacement template for matches, e.g. "legacy_$0"
+	Except      []string       // Field names left unchanged
+}
+
+// Rename returns the older/client name of a HEAD field name
+func (op *ResponseRenameFieldMatching) Rename(name string) string {
+	return renameMatching(op.Pattern, op.Replacement, op.Except, name)
+}
+
+func (op *ResponseRenameFieldMatching) ApplyToResponse(node *jsonast.Node) error {
+	return renameNodeKeys(node, op.Rename)
+}
+
+func (op *ResponseRenameFieldMatching) GetFieldMapping() map[string]string {
+	// Matched names aren't known up front, so error messages keep HEAD's names
+	return nil
+}
+
+// renameMatching applies a pattern rename to name unless it's excepted
+func renameMatching(pattern *regexp.Regexp, replacement string, except []string, name string) string {
+	if pattern == nil {
+		return name
+	}
+	for _, excepted := range except {
+		if name == excepted {
+			return name
+		}
+	}
+	return pattern.ReplaceAllString(name, replacement)
+}
+
+// renameNodeKeys renames the keys of an object node in place, keeping their order
+// A key isn't renamed onto another key the object already has, so no value is lost.
+func renameNodeKeys(node *jsonast.Node, rename func(string) string) error {
+	if !IsNodeObject(node) {
+		return nil
+	}
+	if err := node.LoadAll(); err != nil {
+		return fmt.Errorf("failed to load object: %w", err)
+	}
+
+	iter, err := node.Properties()
+	if err != nil {
+		return err
+	}
+	var pairs []jsonast.Pair
+	existing := make(map[string]bool)
+	var pair jsonast.Pair
+	for iter.Next(&pair) {
+		pairs = append(pairs, pair)
+		existing[pair.Key] = true
+	}
+
+	renamed := false
+	for i, pair := range pairs {
+		name := rename(pair.Key)
+		if name == pair.Key || name == "" || existing[name] {
+			continue
+		}
+		pairs[i] = jsonast.NewPair(name, pair.Value)
+		existing[name] = true
+		renamed = true
+	}
+	if renamed {
+		*node = jsonast.NewObject(pairs)
+	}
+	return nil
+}
+
+// RenameFieldMatching renames every field whose name matches pattern to the expanded replacement
+// (as regexp.ReplaceAllString) when request migrates from client to HEAD. Fields named in except are kept.
+func (b *requestToNextVersionBuilder) RenameFieldMatching(pattern *regexp.Regexp, replacement string, except ...string) *requestToNextVersionBuilder {
+	b.parent.requestToNextVersionOps = append(b.parent.requestToNextVersionOps,
+		&RequestRenameFieldMatching{
+			Pattern:     pattern,
+			Replacement: replacement,
+			Except:      except,
+		})
+	return b
+}
+
+// RenameFieldMatching renames every field whose name matches pattern to the expanded replacement
+// (as regexp.ReplaceAllString) when response migrates from HEAD to client. Fields named in except are kept.
+func (b *responseToPreviousVersionBuilder) RenameFieldMatching(pattern *regexp.Regexp, replacement string, except ...string) *responseToPreviousVersionBuilder {
+	b.parent.responseToPreviousVersionOps = append(b.parent.responseToPreviousVersionOps,
+		&ResponseRenameFieldMatching{
+			Pattern:     pattern,
+			Replacement: replacement,
+			Except:      except,
+		})
+	return b
+}
@@ -0,0 +1,135 @@
+package epoch
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type conventionAccount struct {
+	AccountID   int                 `json:"accountId"`
+	DisplayName string              `json:"displayName"`
+	Owner       conventionOwner     `json:"owner"`
+	Tags        []string            `json:"tags"`
+	Contacts    []conventionContact `json:"contacts"`
+}
+
+type conventionOwner struct {
+	FullName string `json:"fullName"`
+}
+
+type conventionContact struct {
+	EmailAddress string `json:"emailAddress"`
+}
+
+// conventionLegacy keeps its names in every version
+type conventionLegacy struct {
+	LegacyID int `json:"legacyId"`
+}
+
+// camelBoundary renames the first lower-to-upper boundary of a camelCase name
+var camelBoundary = regexp.MustCompile(`^([a-z]+)([A-Z])([a-z]+)$`)
+
+var _ = Describe("Renaming fields matching a pattern", func() {
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+	})
+
+	It("should rename matching keys in place and keep their order", func() {
+		node, err := getNode([]byte(`{"accountId":1,"tags":[],"displayName":"Ada"}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		op := &ResponseRenameFieldMatching{Pattern: regexp.MustCompile(`^(\w+)Id$`), Replacement: "${1}_id"}
+		Expect(op.ApplyToResponse(&node)).To(Succeed())
+
+		raw, _ := node.Raw()
+		Expect(raw).To(Equal(`{"account_id":1,"tags":[],"displayName":"Ada"}`))
+	})
+
+	It("should skip excepted fields and renames onto existing keys", func() {
+		node, err := getNode([]byte(`{"userId":1,"user_id":2,"teamId":3}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		op := &RequestRenameFieldMatching{
+			Pattern:     regexp.MustCompile(`^(\w+)Id$`),
+			Replacement: "${1}_id",
+			Except:      []string{"teamId"},
+		}
+		Expect(op.ApplyToRequest(&node)).To(Succeed())
+
+		raw, _ := node.Raw()
+		Expect(raw).To(Equal(`{"userId":1,"user_id":2,"teamId":3}`))
+	})
+
+	It("should apply convention changes to every type except those excluded", func() {
+		v1, _ := NewDateVersion("2024-01-01")
+		v2, _ := NewDateVersion("2024-06-01")
+		change := NewVersionChangeBuilder(v1, v2).
+			ForAllTypes(conventionLegacy{}).
+			ResponseToPreviousVersion().
+			RenameFieldMatching(camelBoundary, "${1}_${2}${3}", "displayName").
+			Build()
+
+		chain, err := NewMigrationChain([]*VersionChange{change})
+		Expect(err).NotTo(HaveOccurred())
+
+		migrate := func(t interface{}, raw string) string {
+			body, err := getNode([]byte(raw))
+			Expect(err).NotTo(HaveOccurred())
+			info := &ResponseInfo{Body: &body, StatusCode: 200}
+			Expect(chain.MigrateResponseForTypeWithNestedObjects(context.Background(), info, reflect.TypeOf(t),
+				map[string]reflect.Type{"contacts": reflect.TypeOf(conventionContact{})},
+				map[string]reflect.Type{"owner": reflect.TypeOf(conventionOwner{})}, v2, v1)).To(Succeed())
+			out, _ := info.Body.Raw()
+			return out
+		}
+
+		Expect(migrate(conventionAccount{}, `{"accountId":1,"displayName":"Ada","owner":{"fullName":"Ada L"},"contacts":[{"emailAddress":"a@b.c"}]}`)).
+			To(Equal(`{"account_Id":1,"displayName":"Ada","owner":{"full_Name":"Ada L"},"contacts":[{"email_Address":"a@b.c"}]}`))
+		Expect(migrate(conventionLegacy{}, `{"legacyId":1}`)).To(Equal(`{"legacyId":1}`))
+	})
+
+	It("should migrate requests and responses through the middleware", func() {
+		v1, _ := NewDateVersion("2024-01-01")
+		v2, _ := NewDateVersion("2024-06-01")
+		change := NewVersionChangeBuilder(v1, v2).
+			ForAllTypes().
+			RequestToNextVersion().
+			RenameFieldMatching(regexp.MustCompile(`^(\w+)_id$`), "${1}Id").
+			ResponseToPreviousVersion().
+			RenameFieldMatching(regexp.MustCompile(`^(\w+)Id$`), "${1}_id").
+			Build()
+		epochInstance, err := NewEpoch().WithVersions(v1, v2).WithHeadVersion().WithChanges(change).Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		var received conventionAccount
+		router := gin.New()
+		router.Use(epochInstance.Middleware())
+		router.POST("/accounts", epochInstance.WrapHandler(func(c *gin.Context) {
+			Expect(c.ShouldBindJSON(&received)).To(Succeed())
+			c.JSON(200, conventionAccount{AccountID: received.AccountID, DisplayName: "Ada"})
+		}).Accepts(conventionAccount{}).Returns(conventionAccount{}).ToHandlerFunc("POST", "/accounts"))
+
+		req := httptest.NewRequest("POST", "/accounts", strings.NewReader(`{"account_id":7}`))
+		req.Header.Set("X-API-Version", "2024-01-01")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		Expect(recorder.Code).To(Equal(200))
+		Expect(received.AccountID).To(Equal(7))
+		Expect(recorder.Body.String()).To(ContainSubstring(`"account_id":7`))
+		Expect(recorder.Body.String()).NotTo(ContainSubstring(`"accountId"`))
+	})
+
+	It("should describe pattern renames", func() {
+		op := &ResponseRenameFieldMatching{Pattern: regexp.MustCompile(`Id$`), Replacement: "_id"}
+		Expect(DescribeOperation(op).Name).To(Equal("rename_field_matching"))
+		Expect(DescribeOperation(op).Description).To(Equal("Rename fields matching Id$ to _id"))
+	})
+})
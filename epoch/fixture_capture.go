@@ -0,0 +1,240 @@
+package epoch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FixtureCapture records a sample of real HEAD responses as fixture files, to seed golden-file
+// tests, MockServer and OpenAPI examples with realistic bodies. Meant for staging: bodies are
+// scrubbed with WithSensitiveFields, WithScrubber and Scrub before they're written.
+type FixtureCapture struct {
+	// Dir receives one fixture file per endpoint, e.g. GET_users_id.json for "GET /users/:id"
+	Dir string
+
+	// SampleRate is the fraction (0-1] of HEAD requests to allowlisted endpoints recorded
+	SampleRate float64
+
+	// Endpoints allowlists the endpoints captured, as "METHOD /route" with the route as
+	// registered with gin, e.g. "GET /users/:id"
+	Endpoints []string
+
+	// Scrub removes PII from bodies before they're written, after WithSensitiveFields
+	Scrub func(body []byte) []byte
+
+	// Overwrite replaces fixtures with newer samples. By default the first capture of an
+	// endpoint is kept, so fixtures only change when they're deleted.
+	Overwrite bool
+}
+
+// CapturedFixture is the content of a fixture file fixture capture writes
+type CapturedFixture struct {
+	Method string          `json:"method"`
+	Path   string          `json:"path"` // Route pattern of the endpoint
+	Status int             `json:"status"`
+	Body   json.RawMessage `json:"body"` // HEAD response body as sent, scrubbed
+}
+
+// MockFixture returns the capture as a MockServer fixture
+func (r CapturedFixture) MockFixture() MockFixture {
+	return MockFixture{Method: r.Method, Path: r.Path, Status: r.Status, Body: r.Body}
+}
+
+// WithFixtureCapture writes a sample of successful JSON HEAD responses of the allowlisted
+// endpoints to fixture files (see FixtureCapture). Requests for other versions aren't
+// captured; fixtures hold HEAD bodies, which MockServer and WithExample migrate themselves.
+func (cb *EpochBuilder) WithFixtureCapture(config FixtureCapture) *EpochBuilder {
+	if config.Dir == "" || config.SampleRate <= 0 || config.SampleRate > 1 || len(config.Endpoints) == 0 {
+		cb.errors = append(cb.errors, errors.New("fixture capture needs a directory, a sample rate in (0, 1] and endpoints"))
+		return cb
+	}
+	for _, endpoint := range config.Endpoints {
+		if _, _, err := parseCaptureEndpoint(endpoint); err != nil {
+			cb.errors = append(cb.errors, err)
+			return cb
+		}
+	}
+	cb.fixtureCapture = &config
+	return cb
+}
+
+// parseCaptureEndpoint splits an allowlisted "METHOD /route" endpoint
+func parseCaptureEndpoint(endpoint string) (string, string, error) {
+	method, path, found := strings.Cut(strings.TrimSpace(endpoint), " ")
+	path = strings.TrimSpace(path)
+	if !found || method == "" || !strings.HasPrefix(path, "/") {
+		return "", "", fmt.Errorf("invalid fixture capture endpoint %q: use \"METHOD /route\"", endpoint)
+	}
+	return strings.ToUpper(method), path, nil
+}
+
+// fixtureRecorder writes the fixtures of every wrapped handler; safe for concurrent use
+type fixtureRecorder struct {
+	config    FixtureCapture
+	endpoints map[string]bool
+	mu        sync.Mutex
+	captured  map[string]bool
+}
+
+// newFixtureRecorder returns a recorder for config, whose endpoints have been validated
+func newFixtureRecorder(config FixtureCapture) *fixtureRecorder {
+	endpoints := make(map[string]bool, len(config.Endpoints))
+	for _, endpoint := range config.Endpoints {
+		method, path, _ := parseCaptureEndpoint(endpoint)
+		endpoints[endpointKey(method, path)] = true
+	}
+	return &fixtureRecorder{config: config, endpoints: endpoints, captured: make(map[string]bool)}
+}
+
+// sample decides whether a request to an endpoint is captured
+func (r *fixtureRecorder) sample(method, pathPattern string) bool {
+	if !r.endpoints[endpointKey(method, pathPattern)] {
+		return false
+	}
+	return r.config.SampleRate >= 1 || rand.Float64() < r.config.SampleRate
+}
+
+// record writes a response body as the endpoint's fixture, unless it already has one.
+// Write errors are dropped: capturing must never fail the request.
+func (r *fixtureRecorder) record(method, pathPattern string, status int, body []byte) {
+	if !json.Valid(body) {
+		return
+	}
+	if r.config.Scrub != nil {
+		body = r.config.Scrub(body)
+		if !json.Valid(body) {
+			return
+		}
+	}
+
+	file := filepath.Join(r.config.Dir, captureFileName(method, pathPattern))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.config.Overwrite {
+		if r.captured[file] {
+			return
+		}
+		if _, err := os.Stat(file); err == nil {
+			r.captured[file] = true
+			return
+		}
+	}
+
+	data, err := marshalCapturedFixture(CapturedFixture{Method: method, Path: pathPattern, Status: status, Body: body})
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(r.config.Dir, 0755); err != nil {
+		return
+	}
+	// Write through a temporary file so readers never see half a fixture
+	temp := file + ".tmp"
+	if err := os.WriteFile(temp, data, 0644); err != nil {
+		return
+	}
+	if err := os.Rename(temp, file); err != nil {
+		os.Remove(temp)
+		return
+	}
+	r.captured[file] = true
+}
+
+// marshalCapturedFixture writes a fixture one field per line, with the body exactly as it was
+// sent: json.MarshalIndent would re-indent it
+func marshalCapturedFixture(fixture CapturedFixture) ([]byte, error) {
+	method, err := json.Marshal(fixture.Method)
+	if err != nil {
+		return nil, err
+	}
+	path, err := json.Marshal(fixture.Path)
+	if err != nil {
+		return nil, err
+	}
+	var data bytes.Buffer
+	fmt.Fprintf(&data, "{\n  \"method\": %s,\n  \"path\": %s,\n  \"status\": %d,\n  \"body\": ", method, path, fixture.Status)
+	data.Write(bytes.TrimSpace(fixture.Body))
+	data.WriteString("\n}\n")
+	return data.Bytes(), nil
+}
+
+// captureFileName names an endpoint's fixture file, e.g. GET_users_id.json for GET /users/:id
+func captureFileName(method, pathPattern string) string {
+	var name strings.Builder
+	name.WriteString(strings.ToUpper(method))
+	separated := true
+	for _, r := range strings.Trim(pathPattern, "/") {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' {
+			if separated {
+				name.WriteByte('_')
+				separated = false
+			}
+			name.WriteRune(r)
+			continue
+		}
+		separated = true
+	}
+	return name.String() + ".json"
+}
+
+// withFixtureCapture captures this handler's sampled HEAD responses with recorder
+func (vah *VersionAwareHandler) withFixtureCapture(recorder *fixtureRecorder) *VersionAwareHandler {
+	vah.fixtureCapture = recorder
+	return vah
+}
+
+// serveCaptured serves a HEAD request unchanged, then records its successful response if the
+// endpoint is allowlisted and the request sampled. It reports false, without serving, when the
+// request isn't captured.
+func (vah *VersionAwareHandler) serveCaptured(c *gin.Context) bool {
+	if vah.fixtureCapture == nil {
+		return false
+	}
+	endpointDef, err := vah.endpointRegistry.Lookup(c.Request.Method, vah.stripVersionPrefix(c.Request.URL.Path))
+	if err != nil || !vah.fixtureCapture.sample(endpointDef.Method, endpointDef.PathPattern) {
+		return false
+	}
+
+	writer := &canaryWriter{ResponseWriter: c.Writer}
+	c.Writer = writer
+	defer func() { c.Writer = writer.ResponseWriter }()
+	vah.handler(c)
+
+	if status := c.Writer.Status(); status < http.StatusMultipleChoices && writer.body.Len() > 0 {
+		vah.fixtureCapture.record(endpointDef.Method, endpointDef.PathPattern, status, writer.body.Bytes())
+	}
+	return true
+}
+
+// LoadCapturedFixtures reads the fixtures fixture capture wrote to dir, sorted by file name
+func LoadCapturedFixtures(dir string) ([]CapturedFixture, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	captured := make([]CapturedFixture, 0, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read captured response: %w", err)
+		}
+		var response CapturedFixture
+		if err := json.Unmarshal(data, &response); err != nil {
+			return nil, fmt.Errorf("failed to parse captured response %s: %w", filepath.Base(file), err)
+		}
+		captured = append(captured, response)
+	}
+	return captured, nil
+}
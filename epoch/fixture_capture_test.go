@@ -0,0 +1,166 @@
+package epoch
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type capturedUser struct {
+	ID       int    `json:"id"`
+	Email    string `json:"email"`
+	FullName string `json:"full_name"`
+}
+
+var _ = Describe("Fixture Capture", func() {
+	var (
+		v1, v2 *Version
+		dir    string
+		calls  int
+	)
+
+	BeforeEach(func() {
+		v1, _ = NewDateVersion("2024-01-01")
+		v2, _ = NewDateVersion("2025-01-01")
+		dir = filepath.Join(GinkgoT().TempDir(), "fixtures")
+		calls = 0
+	})
+
+	newEpoch := func(capture FixtureCapture) *Epoch {
+		// Build() attaches changes to versions, so every Epoch gets its own
+		v1, _ = NewDateVersion("2024-01-01")
+		v2, _ = NewDateVersion("2025-01-01")
+		change := NewVersionChangeBuilder(v1, v2).
+			ForType(capturedUser{}).
+			ResponseToPreviousVersion().
+			RenameField("full_name", "name").
+			Build()
+		epochInstance, err := NewEpoch().WithVersions(v1, v2).WithHeadVersion().WithChanges(change).
+			WithSensitiveFields("email").WithFixtureCapture(capture).Build()
+		Expect(err).NotTo(HaveOccurred())
+		return epochInstance
+	}
+
+	newRouter := func(epochInstance *Epoch) *gin.Engine {
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.Use(epochInstance.Middleware())
+		router.GET("/users/:id", epochInstance.WrapHandler(func(c *gin.Context) {
+			calls++
+			c.JSON(http.StatusOK, capturedUser{ID: calls, Email: "ada@example.com", FullName: "Ada"})
+		}).Returns(capturedUser{}).ToHandlerFunc("GET", "/users/:id"))
+		router.GET("/users", epochInstance.WrapHandler(func(c *gin.Context) {
+			c.JSON(http.StatusOK, []capturedUser{{ID: 1}})
+		}).Returns([]capturedUser{}).ToHandlerFunc("GET", "/users"))
+		return router
+	}
+
+	get := func(router *gin.Engine, path, version string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-API-Version", version)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	It("should write scrubbed HEAD responses of allowlisted endpoints", func() {
+		router := newRouter(newEpoch(FixtureCapture{Dir: dir, SampleRate: 1, Endpoints: []string{"get /users/:id"}}))
+
+		recorder := get(router, "/users/7", "head")
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Body.String()).To(MatchJSON(`{"id":1,"email":"ada@example.com","full_name":"Ada"}`))
+		get(router, "/users", "head")
+
+		captured, err := LoadCapturedFixtures(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(captured).To(HaveLen(1))
+		Expect(captured[0].Method).To(Equal("GET"))
+		Expect(captured[0].Path).To(Equal("/users/:id"))
+		Expect(captured[0].Status).To(Equal(http.StatusOK))
+		Expect(string(captured[0].Body)).To(MatchJSON(`{"id":1,"email":"[REDACTED]","full_name":"Ada"}`))
+		data, err := os.ReadFile(filepath.Join(dir, "GET_users_id.json"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring(`"body": {"id":1,"email":"[REDACTED]","full_name":"Ada"}`))
+	})
+
+	It("should keep the first capture unless Overwrite is set", func() {
+		router := newRouter(newEpoch(FixtureCapture{Dir: dir, SampleRate: 1, Endpoints: []string{"GET /users/:id"}}))
+		get(router, "/users/1", "head")
+		get(router, "/users/2", "head")
+
+		captured, err := LoadCapturedFixtures(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(captured[0].Body)).To(ContainSubstring(`"id":1`))
+
+		router = newRouter(newEpoch(FixtureCapture{Dir: dir, SampleRate: 1, Endpoints: []string{"GET /users/:id"}, Overwrite: true}))
+		get(router, "/users/3", "head")
+		captured, err = LoadCapturedFixtures(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(captured[0].Body)).To(ContainSubstring(`"id":3`))
+	})
+
+	It("should only capture HEAD requests", func() {
+		router := newRouter(newEpoch(FixtureCapture{Dir: dir, SampleRate: 1, Endpoints: []string{"GET /users/:id"}}))
+
+		recorder := get(router, "/users/1", "2024-01-01")
+		Expect(recorder.Body.String()).To(MatchJSON(`{"id":1,"email":"ada@example.com","name":"Ada"}`))
+		_, err := os.Stat(dir)
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	It("should apply the custom scrubber after sensitive fields", func() {
+		router := newRouter(newEpoch(FixtureCapture{
+			Dir:        dir,
+			SampleRate: 1,
+			Endpoints:  []string{"GET /users/:id"},
+			Scrub: func(body []byte) []byte {
+				var user map[string]interface{}
+				Expect(json.Unmarshal(body, &user)).To(Succeed())
+				Expect(user["email"]).To(Equal(RedactedValue))
+				user["full_name"] = "Jane Doe"
+				scrubbed, _ := json.Marshal(user)
+				return scrubbed
+			},
+		}))
+		get(router, "/users/1", "head")
+
+		captured, err := LoadCapturedFixtures(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(captured[0].Body)).To(ContainSubstring(`"full_name":"Jane Doe"`))
+	})
+
+	It("should serve captures from a mock server", func() {
+		epochInstance := newEpoch(FixtureCapture{Dir: dir, SampleRate: 1, Endpoints: []string{"GET /users/:id"}})
+		get(newRouter(epochInstance), "/users/1", "head")
+
+		captured, err := LoadCapturedFixtures(dir)
+		Expect(err).NotTo(HaveOccurred())
+		handler, err := epochInstance.MockServer(captured[0].MockFixture())
+		Expect(err).NotTo(HaveOccurred())
+
+		req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+		req.Header.Set("X-API-Version", "2024-01-01")
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		Expect(recorder.Body.String()).To(MatchJSON(`{"id":1,"email":"[REDACTED]","name":"Ada"}`))
+	})
+
+	It("should reject invalid configurations", func() {
+		_, err := NewEpoch().WithVersions(v1).WithFixtureCapture(FixtureCapture{Dir: dir, SampleRate: 2, Endpoints: []string{"GET /users"}}).Build()
+		Expect(err).To(MatchError(ContainSubstring("fixture capture needs")))
+
+		_, err = NewEpoch().WithVersions(v1).WithFixtureCapture(FixtureCapture{Dir: dir, SampleRate: 1, Endpoints: []string{"/users"}}).Build()
+		Expect(err).To(MatchError(ContainSubstring(`invalid fixture capture endpoint "/users"`)))
+	})
+
+	It("should name fixture files after the endpoint", func() {
+		Expect(captureFileName("get", "/users/:id/orders")).To(Equal("GET_users_id_orders.json"))
+		Expect(captureFileName("POST", "/")).To(Equal("POST.json"))
+	})
+})
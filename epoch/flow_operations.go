@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"reflect"
 
-	"github.com/bytedance/sonic/ast"
+	"github.com/astronomer/epoch/epoch/jsonast"
 )
 
 // Flow-based operation interfaces matching actual migration flow:
@@ -14,7 +14,7 @@ import (
 // RequestToNextVersionOperation applies when migrating requests from client version to HEAD
 // This is the ONLY direction requests flow
 type RequestToNextVersionOperation interface {
-	ApplyToRequest(node *ast.Node) error
+	ApplyToRequest(node *jsonast.Node) error
 	GetFieldMapping() map[string]string     // For error transformation
 	Inverse() RequestToNextVersionOperation // For OpenAPI schema generation (HEAD→Client)
 }
@@ -22,10 +22,22 @@ type RequestToNextVersionOperation interface {
 // ResponseToPreviousVersionOperation applies when migrating responses from HEAD to client version
 // This is the ONLY direction responses flow
 type ResponseToPreviousVersionOperation interface {
-	ApplyToResponse(node *ast.Node) error
+	ApplyToResponse(node *jsonast.Node) error
 	GetFieldMapping() map[string]string // For error transformation
 }
 
+// RequestInfoOperation is implemented by request operations that need access to the
+// surrounding RequestInfo (headers, Gin context, migration context) and not just the body node
+type RequestInfoOperation interface {
+	ApplyToRequestInfo(req *RequestInfo) error
+}
+
+// ResponseInfoOperation is implemented by response operations that need access to the
+// surrounding ResponseInfo (status code, Gin context, migration context) and not just the body node
+type ResponseInfoOperation interface {
+	ApplyToResponseInfo(resp *ResponseInfo) error
+}
+
 // ============================================================================
 // Request Operations - TO NEXT VERSION (Client→HEAD) - ONLY DIRECTION
 // ============================================================================
@@ -33,12 +45,13 @@ type ResponseToPreviousVersionOperation interface {
 // RequestAddField adds a field when request migrates from client to HEAD
 // Use case: HEAD version requires a field that older clients don't send
 type RequestAddField struct {
-	Name    string
-	Default interface{}
+	Name       string
+	Default    interface{}
+	Validation FieldValidation // Whether the field is added to satisfy HEAD's validation
 }
 
-func (op *RequestAddField) ApplyToRequest(node *ast.Node) error {
-	if node == nil {
+func (op *RequestAddField) ApplyToRequest(node *jsonast.Node) error {
+	if node == nil || op.Validation == FailValidation {
 		return nil
 	}
 
@@ -69,7 +82,7 @@ type RequestAddFieldWithDefault struct {
 	Default interface{}
 }
 
-func (op *RequestAddFieldWithDefault) ApplyToRequest(node *ast.Node) error {
+func (op *RequestAddFieldWithDefault) ApplyToRequest(node *jsonast.Node) error {
 	if node == nil {
 		return nil
 	}
@@ -100,7 +113,7 @@ type RequestRemoveField struct {
 	Name string
 }
 
-func (op *RequestRemoveField) ApplyToRequest(node *ast.Node) error {
+func (op *RequestRemoveField) ApplyToRequest(node *jsonast.Node) error {
 	if node == nil {
 		return nil
 	}
@@ -129,29 +142,16 @@ type RequestRenameField struct {
 	NewerVersionName string // Field name in newer/HEAD version
 }
 
-func (op *RequestRenameField) ApplyToRequest(node *ast.Node) error {
+func (op *RequestRenameField) ApplyToRequest(node *jsonast.Node) error {
 	if node == nil {
 		return nil
 	}
 
-	// Check if old field exists
-	if !node.Get(op.OlderVersionName).Exists() {
-		return nil
-	}
-
-	// Get the value of the old field
-	value := node.Get(op.OlderVersionName)
-	if value == nil {
-		return nil
-	}
-
-	// Set new field with the value
-	if err := SetNodeField(node, op.NewerVersionName, value); err != nil {
-		return fmt.Errorf("failed to set field %s: %w", op.NewerVersionName, err)
+	// Rename in place so the field keeps its position
+	if err := RenameNodeField(node, op.OlderVersionName, op.NewerVersionName); err != nil {
+		return fmt.Errorf("failed to rename field %s: %w", op.OlderVersionName, err)
 	}
-
-	// Delete old field
-	return DeleteNodeField(node, op.OlderVersionName)
+	return nil
 }
 
 func (op *RequestRenameField) GetFieldMapping() map[string]string {
@@ -169,16 +169,67 @@ func (op *RequestRenameField) Inverse() RequestToNextVersionOperation {
 	}
 }
 
+// RequestMoveField moves a field between nesting levels when request migrates from client to HEAD
+// Use case: HEAD moved "theme" into "settings.theme"
+type RequestMoveField struct {
+	OlderVersionPath string // Dotted path in older/client version
+	NewerVersionPath string // Dotted path in newer/HEAD version
+}
+
+func (op *RequestMoveField) ApplyToRequest(node *jsonast.Node) error {
+	if node == nil {
+		return nil
+	}
+
+	if err := MoveNodeAtPath(node, op.OlderVersionPath, op.NewerVersionPath); err != nil {
+		return fmt.Errorf("failed to move field %s to %s: %w", op.OlderVersionPath, op.NewerVersionPath, err)
+	}
+	return nil
+}
+
+func (op *RequestMoveField) GetFieldMapping() map[string]string {
+	// When transforming error messages, map new path back to old
+	return map[string]string{op.NewerVersionPath: op.OlderVersionPath}
+}
+
+// Inverse returns the opposite operation for schema generation
+// MoveField is completely reversible, like RenameField
+func (op *RequestMoveField) Inverse() RequestToNextVersionOperation {
+	return &RequestMoveField{
+		OlderVersionPath: op.NewerVersionPath, // Swap directions
+		NewerVersionPath: op.OlderVersionPath,
+	}
+}
+
 // RequestCustom applies a custom transformation function
+// Fn receives only the body node; InfoFn receives the full RequestInfo scoped to the node
 type RequestCustom struct {
-	Fn func(*ast.Node) error
+	Fn     func(*jsonast.Node) error
+	InfoFn func(*RequestInfo) error
 }
 
-func (op *RequestCustom) ApplyToRequest(node *ast.Node) error {
-	if node == nil || op.Fn == nil {
+func (op *RequestCustom) ApplyToRequest(node *jsonast.Node) error {
+	if node == nil {
 		return nil
 	}
-	return op.Fn(node)
+	if op.Fn != nil {
+		return op.Fn(node)
+	}
+	if op.InfoFn != nil {
+		return op.InfoFn(&RequestInfo{Body: node})
+	}
+	return nil
+}
+
+// ApplyToRequestInfo runs InfoFn with the full RequestInfo when available
+func (op *RequestCustom) ApplyToRequestInfo(req *RequestInfo) error {
+	if req == nil || req.Body == nil {
+		return nil
+	}
+	if op.InfoFn != nil {
+		return op.InfoFn(req)
+	}
+	return op.ApplyToRequest(req.Body)
 }
 
 func (op *RequestCustom) GetFieldMapping() map[string]string {
@@ -202,7 +253,7 @@ type ResponseAddField struct {
 	Default interface{}
 }
 
-func (op *ResponseAddField) ApplyToResponse(node *ast.Node) error {
+func (op *ResponseAddField) ApplyToResponse(node *jsonast.Node) error {
 	if node == nil {
 		return nil
 	}
@@ -225,7 +276,7 @@ type ResponseRemoveField struct {
 	Name string
 }
 
-func (op *ResponseRemoveField) ApplyToResponse(node *ast.Node) error {
+func (op *ResponseRemoveField) ApplyToResponse(node *jsonast.Node) error {
 	if node == nil {
 		return nil
 	}
@@ -244,7 +295,7 @@ type ResponseRemoveFieldIfDefault struct {
 	Default interface{}
 }
 
-func (op *ResponseRemoveFieldIfDefault) ApplyToResponse(node *ast.Node) error {
+func (op *ResponseRemoveFieldIfDefault) ApplyToResponse(node *jsonast.Node) error {
 	if node == nil {
 		return nil
 	}
@@ -280,46 +331,75 @@ type ResponseRenameField struct {
 	OlderVersionName string // Field name in older/client version
 }
 
-func (op *ResponseRenameField) ApplyToResponse(node *ast.Node) error {
+func (op *ResponseRenameField) ApplyToResponse(node *jsonast.Node) error {
 	if node == nil {
 		return nil
 	}
 
-	// Check if new field exists
-	if !node.Get(op.NewerVersionName).Exists() {
-		return nil
+	// Rename in place so the field keeps its position
+	if err := RenameNodeField(node, op.NewerVersionName, op.OlderVersionName); err != nil {
+		return fmt.Errorf("failed to rename field %s: %w", op.NewerVersionName, err)
 	}
+	return nil
+}
+
+func (op *ResponseRenameField) GetFieldMapping() map[string]string {
+	// When transforming error messages, map new field name to old
+	return map[string]string{op.NewerVersionName: op.OlderVersionName}
+}
 
-	// Get the value of the new field
-	value := node.Get(op.NewerVersionName)
-	if value == nil {
+// ResponseMoveField moves a field between nesting levels when response migrates from HEAD to client
+// Use case: HEAD moved "theme" into "settings.theme", move it back for old clients
+type ResponseMoveField struct {
+	NewerVersionPath string // Dotted path in newer/HEAD version
+	OlderVersionPath string // Dotted path in older/client version
+}
+
+func (op *ResponseMoveField) ApplyToResponse(node *jsonast.Node) error {
+	if node == nil {
 		return nil
 	}
 
-	// Set old field with the value
-	if err := SetNodeField(node, op.OlderVersionName, value); err != nil {
-		return fmt.Errorf("failed to set field %s: %w", op.OlderVersionName, err)
+	if err := MoveNodeAtPath(node, op.NewerVersionPath, op.OlderVersionPath); err != nil {
+		return fmt.Errorf("failed to move field %s to %s: %w", op.NewerVersionPath, op.OlderVersionPath, err)
 	}
-
-	// Delete new field
-	return DeleteNodeField(node, op.NewerVersionName)
+	return nil
 }
 
-func (op *ResponseRenameField) GetFieldMapping() map[string]string {
-	// When transforming error messages, map new field name to old
-	return map[string]string{op.NewerVersionName: op.OlderVersionName}
+func (op *ResponseMoveField) GetFieldMapping() map[string]string {
+	// When transforming error messages, map new path to old
+	return map[string]string{op.NewerVersionPath: op.OlderVersionPath}
 }
 
 // ResponseCustom applies a custom transformation function
+// Fn receives only the body node; InfoFn receives the full ResponseInfo scoped to the node
 type ResponseCustom struct {
-	Fn func(*ast.Node) error
+	Fn     func(*jsonast.Node) error
+	InfoFn func(*ResponseInfo) error
+}
+
+func (op *ResponseCustom) ApplyToResponse(node *jsonast.Node) error {
+	if node == nil {
+		return nil
+	}
+	if op.Fn != nil {
+		return op.Fn(node)
+	}
+	if op.InfoFn != nil {
+		return op.InfoFn(&ResponseInfo{Body: node})
+	}
+	return nil
 }
 
-func (op *ResponseCustom) ApplyToResponse(node *ast.Node) error {
-	if node == nil || op.Fn == nil {
+// ApplyToResponseInfo runs InfoFn with the full ResponseInfo when available
+func (op *ResponseCustom) ApplyToResponseInfo(resp *ResponseInfo) error {
+	if resp == nil || resp.Body == nil {
 		return nil
 	}
-	return op.Fn(node)
+	if op.InfoFn != nil {
+		return op.InfoFn(resp)
+	}
+	return op.ApplyToResponse(resp.Body)
 }
 
 func (op *ResponseCustom) GetFieldMapping() map[string]string {
@@ -334,7 +414,7 @@ func (op *ResponseCustom) GetFieldMapping() map[string]string {
 type RequestToNextVersionOperationList []RequestToNextVersionOperation
 
 // Apply applies all operations to a request node
-func (ops RequestToNextVersionOperationList) Apply(node *ast.Node) error {
+func (ops RequestToNextVersionOperationList) Apply(node *jsonast.Node) error {
 	for _, op := range ops {
 		if err := op.ApplyToRequest(node); err != nil {
 			return err
@@ -343,6 +423,23 @@ func (ops RequestToNextVersionOperationList) Apply(node *ast.Node) error {
 	return nil
 }
 
+// ApplyToInfo applies all operations to the request body, passing the full RequestInfo
+// to operations that implement RequestInfoOperation
+func (ops RequestToNextVersionOperationList) ApplyToInfo(req *RequestInfo) error {
+	for i, op := range ops {
+		err := recoverOperation(i, func() error {
+			if infoOp, ok := op.(RequestInfoOperation); ok {
+				return infoOp.ApplyToRequestInfo(req)
+			}
+			return op.ApplyToRequest(req.Body)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // GetFieldMappings returns combined field mappings from all operations
 func (ops RequestToNextVersionOperationList) GetFieldMappings() map[string]string {
 	result := make(map[string]string)
@@ -358,7 +455,7 @@ func (ops RequestToNextVersionOperationList) GetFieldMappings() map[string]strin
 type ResponseToPreviousVersionOperationList []ResponseToPreviousVersionOperation
 
 // Apply applies all operations to a response node
-func (ops ResponseToPreviousVersionOperationList) Apply(node *ast.Node) error {
+func (ops ResponseToPreviousVersionOperationList) Apply(node *jsonast.Node) error {
 	for _, op := range ops {
 		if err := op.ApplyToResponse(node); err != nil {
 			return err
@@ -367,6 +464,23 @@ func (ops ResponseToPreviousVersionOperationList) Apply(node *ast.Node) error {
 	return nil
 }
 
+// ApplyToInfo applies all operations to the response body, passing the full ResponseInfo
+// to operations that implement ResponseInfoOperation
+func (ops ResponseToPreviousVersionOperationList) ApplyToInfo(resp *ResponseInfo) error {
+	for i, op := range ops {
+		err := recoverOperation(i, func() error {
+			if infoOp, ok := op.(ResponseInfoOperation); ok {
+				return infoOp.ApplyToResponseInfo(resp)
+			}
+			return op.ApplyToResponse(resp.Body)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // GetFieldMappings returns combined field mappings from all operations
 func (ops ResponseToPreviousVersionOperationList) GetFieldMappings() map[string]string {
 	result := make(map[string]string)
@@ -0,0 +1,64 @@
+package epoch
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// instanceCount numbers Epoch instances, so each stores its version under its own context key
+var instanceCount atomic.Uint64
+
+// newInstanceVersionKey returns a context key no other Epoch instance uses
+func newInstanceVersionKey() string {
+	return fmt.Sprintf("%s.%d", versionContextKey, instanceCount.Add(1))
+}
+
+// VersionFromContext returns the version this Epoch's middleware resolved for the request, or
+// nil when it didn't run. Unlike GetVersionFromContext, which returns whatever version the last
+// Epoch middleware resolved, it isn't confused by other Epoch instances on the same engine.
+func (c *Epoch) VersionFromContext(ctx *gin.Context) *Version {
+	if v, exists := ctx.Get(c.versionKey); exists {
+		if version, ok := v.(*Version); ok {
+			return version
+		}
+	}
+	return nil
+}
+
+// Group returns a route group under relativePath that runs this Epoch's middleware, then
+// handlers. Several Epoch instances with their own version timelines can serve one engine when
+// each gets its own group: a middleware applied with router.Use runs for every route, and
+// rejects the versions of the other instances' clients.
+//
+//	public.Group(router, "/api").GET("/users/:id", public.WrapHandler(getUser).ToHandlerFunc("GET", "/api/users/:id"))
+//	partner.Group(router, "/partner").GET("/orders", partner.WrapHandler(listOrders).ToHandlerFunc("GET", "/partner/orders"))
+func (c *Epoch) Group(router gin.IRouter, relativePath string, handlers ...gin.HandlerFunc) *gin.RouterGroup {
+	return router.Group(relativePath, append([]gin.HandlerFunc{c.Middleware()}, handlers...)...)
+}
+
+// withVersionKey makes the handler migrate for the version stored under key, ignoring versions
+// other Epoch instances' middlewares resolved
+func (vah *VersionAwareHandler) withVersionKey(key string) *VersionAwareHandler {
+	vah.versionKey = key
+	return vah
+}
+
+// requestedVersion returns the version to migrate the request for, or nil when there is none.
+// The handler's own instance's version also becomes the one GetVersionFromContext returns.
+func (vah *VersionAwareHandler) requestedVersion(c *gin.Context) *Version {
+	if vah.versionKey == "" {
+		return GetVersionFromContext(c)
+	}
+	v, exists := c.Get(vah.versionKey)
+	if !exists {
+		return nil
+	}
+	version, ok := v.(*Version)
+	if !ok {
+		return nil
+	}
+	c.Set(versionContextKey, version)
+	return version
+}
@@ -0,0 +1,91 @@
+package epoch
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Multiple Epoch instances", func() {
+	var (
+		public, partner *Epoch
+		router          *gin.Engine
+	)
+
+	BeforeEach(func() {
+		d1, _ := NewDateVersion("2024-01-01")
+		d2, _ := NewDateVersion("2025-01-01")
+		publicChange := NewVersionChangeBuilder(d1, d2).
+			ForType(logUser{}).
+			ResponseToPreviousVersion().
+			RenameField("full_name", "name").
+			Build()
+		var err error
+		public, err = NewEpoch().WithVersions(d1, d2).WithHeadVersion().WithChanges(publicChange).Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		s1, _ := NewSemverVersion("1.0.0")
+		s2, _ := NewSemverVersion("2.0.0")
+		partnerChange := NewVersionChangeBuilder(s1, s2).
+			ForType(logUser{}).
+			ResponseToPreviousVersion().
+			RenameField("full_name", "display_name").
+			Build()
+		partner, err = NewEpoch().WithVersions(s1, s2).WithHeadVersion().WithChanges(partnerChange).Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		gin.SetMode(gin.TestMode)
+		router = gin.New()
+		user := func(c *gin.Context) {
+			c.JSON(http.StatusOK, logUser{ID: 1, FullName: "Ada"})
+		}
+		public.Group(router, "/api").GET("/users/1",
+			public.WrapHandler(user).Returns(logUser{}).ToHandlerFunc("GET", "/api/users/:id"))
+		partner.Group(router, "/partner").GET("/users/1",
+			partner.WrapHandler(user).Returns(logUser{}).ToHandlerFunc("GET", "/partner/users/:id"))
+	})
+
+	send := func(path, version string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("X-API-Version", version)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	It("should serve each instance's timeline on its own route group", func() {
+		recorder := send("/api/users/1", "2024-01-01")
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Body.String()).To(MatchJSON(`{"id":1,"name":"Ada","email":""}`))
+
+		recorder = send("/partner/users/1", "1.0.0")
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Body.String()).To(MatchJSON(`{"id":1,"display_name":"Ada","email":""}`))
+	})
+
+	It("should keep separate endpoint registries", func() {
+		_, err := public.EndpointRegistry().Lookup("GET", "/api/users/1")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = public.EndpointRegistry().Lookup("GET", "/partner/users/1")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should store each instance's version under its own context key", func() {
+		var publicVersion, partnerVersion *Version
+		engine := gin.New()
+		engine.Use(public.Middleware(), partner.Middleware())
+		engine.GET("/users", func(c *gin.Context) {
+			publicVersion, partnerVersion = public.VersionFromContext(c), partner.VersionFromContext(c)
+			c.Status(http.StatusOK)
+		})
+
+		req := httptest.NewRequest("GET", "/users", nil)
+		engine.ServeHTTP(httptest.NewRecorder(), req)
+		Expect(publicVersion.String()).To(Equal("head"))
+		Expect(partnerVersion.String()).To(Equal("head"))
+		Expect(publicVersion).NotTo(BeIdenticalTo(partnerVersion))
+	})
+})
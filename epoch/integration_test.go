@@ -4,10 +4,12 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"net/http/httptest"
 	"strings"
 	"sync"
 
+	"github.com/astronomer/epoch/epoch/jsonast"
 	"github.com/gin-gonic/gin"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -92,6 +94,52 @@ func setupRouterWithMiddleware(epochInstance *Epoch) *gin.Engine {
 	return router
 }
 
+// newTestVersions returns fresh 2024-01-01, 2024-06-01 and 2025-01-01 versions. Build attaches
+// changes to the versions it's given, so every Epoch a spec builds needs its own.
+func newTestVersions() (v1, v2, v3 *Version) {
+	v1, _ = NewDateVersion("2024-01-01")
+	v2, _ = NewDateVersion("2024-06-01")
+	v3, _ = NewDateVersion("2025-01-01")
+	return v1, v2, v3
+}
+
+// setupHeadEpoch is setupBasicEpoch with a head version, applying options to the builder
+func setupHeadEpoch(versions []*Version, changes []*VersionChange, options ...func(*EpochBuilder) *EpochBuilder) (*Epoch, error) {
+	builder := NewEpoch().WithVersions(versions...).WithHeadVersion().WithVersionFormat(VersionFormatDate)
+	if len(changes) > 0 {
+		builder = builder.WithChanges(changes...)
+	}
+	for _, option := range options {
+		builder = option(builder)
+	}
+	return builder.Build()
+}
+
+// serveVersioned serves req through router, asking for version unless it's empty
+func serveVersioned(router *gin.Engine, req *http.Request, version string) *httptest.ResponseRecorder {
+	if version != "" {
+		req.Header.Set("X-API-Version", version)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// getNode parses a JSON document, or the value at path in it, with the default engine
+func getNode(data []byte, path ...interface{}) (jsonast.Node, error) {
+	node, err := DefaultJSONEngine().Parse(data)
+	if err != nil {
+		return jsonast.Node{}, err
+	}
+	if len(path) > 0 {
+		node = node.GetByPath(path...)
+		if err := node.Check(); err != nil {
+			return jsonast.Node{}, err
+		}
+	}
+	return *node, nil
+}
+
 var _ = Describe("End-to-End Integration Tests", func() {
 	BeforeEach(func() {
 		gin.SetMode(gin.TestMode)
@@ -2320,4 +2368,264 @@ var _ = Describe("End-to-End Integration Tests", func() {
 			}
 		})
 	})
+	Describe("Migration Context Sharing", func() {
+		type EchoRequest struct {
+			FullName string `json:"full_name"`
+		}
+
+		type EchoResponse struct {
+			ID       int    `json:"id"`
+			FullName string `json:"full_name"`
+		}
+
+		It("should make values stashed during request migration available to response migration", func() {
+			v1, _ := NewDateVersion("2024-01-01")
+			v2, _ := NewDateVersion("2024-06-01")
+
+			change := NewVersionChangeBuilder(v1, v2).
+				Description("Rename name to full_name").
+				ForType(EchoRequest{}).
+				RequestToNextVersion().
+				Custom(func(req *RequestInfo) error {
+					original, err := req.GetFieldString("name")
+					if err != nil {
+						return nil
+					}
+					req.SetMigrationValue("original_name", original)
+					if err := req.SetField("full_name", strings.ToUpper(original)); err != nil {
+						return err
+					}
+					return req.DeleteField("name")
+				}).
+				ForType(EchoResponse{}).
+				ResponseToPreviousVersion().
+				Custom(func(resp *ResponseInfo) error {
+					if original, ok := resp.GetMigrationValue("original_name"); ok {
+						if err := resp.SetField("name", original); err != nil {
+							return err
+						}
+					}
+					return resp.DeleteField("full_name")
+				}).
+				Build()
+
+			epochInstance, err := setupBasicEpoch([]*Version{v1, v2}, []*VersionChange{change})
+			Expect(err).NotTo(HaveOccurred())
+
+			router := setupRouterWithMiddleware(epochInstance)
+			router.POST("/echo", epochInstance.WrapHandler(func(c *gin.Context) {
+				var req EchoRequest
+				if err := c.ShouldBindJSON(&req); err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+				Expect(req.FullName).To(Equal("JANE DOE"))
+				c.JSON(200, EchoResponse{ID: 1, FullName: req.FullName})
+			}).Accepts(EchoRequest{}).Returns(EchoResponse{}).ToHandlerFunc("POST", "/echo"))
+
+			req := httptest.NewRequest("POST", "/echo", strings.NewReader(`{"name":"Jane Doe"}`))
+			req.Header.Set("X-API-Version", "2024-01-01")
+			req.Header.Set("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			Expect(recorder.Code).To(Equal(200))
+			var response map[string]interface{}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+			Expect(response["name"]).To(Equal("Jane Doe"))
+			Expect(response).NotTo(HaveKey("full_name"))
+		})
+	})
+	Describe("Request Info in Response Migration", func() {
+		type NicknameRequest struct {
+			Name string `json:"name"`
+		}
+
+		type NicknameResponse struct {
+			ID       int    `json:"id"`
+			Name     string `json:"name"`
+			Nickname string `json:"nickname"`
+		}
+
+		It("should expose request metadata to response transformers", func() {
+			v1, _ := NewDateVersion("2024-01-01")
+			v2, _ := NewDateVersion("2024-06-01")
+
+			var seenVersion, seenID string
+			change := NewVersionChangeBuilder(v1, v2).
+				Description("Only echo nickname when the client sent it").
+				ForType(NicknameResponse{}).
+				ResponseToPreviousVersion().
+				Custom(func(resp *ResponseInfo) error {
+					Expect(resp.Request).NotTo(BeNil())
+					seenVersion = resp.Request.Version.String()
+					seenID = resp.Request.PathParams["id"]
+
+					original, err := getNode(resp.Request.OriginalBody, "nickname")
+					if err != nil || !original.Exists() {
+						return resp.DeleteField("nickname")
+					}
+					return nil
+				}).
+				Build()
+
+			epochInstance, err := setupBasicEpoch([]*Version{v1, v2}, []*VersionChange{change})
+			Expect(err).NotTo(HaveOccurred())
+
+			router := setupRouterWithMiddleware(epochInstance)
+			router.PUT("/users/:id", epochInstance.WrapHandler(func(c *gin.Context) {
+				c.JSON(200, NicknameResponse{ID: 7, Name: "Jane", Nickname: "JJ"})
+			}).Accepts(NicknameRequest{}).Returns(NicknameResponse{}).ToHandlerFunc("PUT", "/users/:id"))
+
+			send := func(body string) map[string]interface{} {
+				req := httptest.NewRequest("PUT", "/users/7", strings.NewReader(body))
+				req.Header.Set("X-API-Version", "2024-01-01")
+				req.Header.Set("Content-Type", "application/json")
+				recorder := httptest.NewRecorder()
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(200))
+
+				var response map[string]interface{}
+				Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+				return response
+			}
+
+			Expect(send(`{"name":"Jane"}`)).NotTo(HaveKey("nickname"))
+			Expect(send(`{"name":"Jane","nickname":"JJ"}`)).To(HaveKeyWithValue("nickname", "JJ"))
+			Expect(seenVersion).To(Equal("2024-01-01"))
+			Expect(seenID).To(Equal("7"))
+		})
+	})
+	Describe("Migration Bypass", func() {
+		type BypassUser struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+		}
+
+		type BypassOrder struct {
+			ID    int `json:"id"`
+			Total int `json:"total"`
+		}
+
+		It("should stream the handler response untouched when no change affects the endpoint types", func() {
+			v1, _ := NewDateVersion("2024-01-01")
+			v2, _ := NewDateVersion("2024-06-01")
+
+			// Only BypassUser is migrated; BypassOrder has no changes between v1 and HEAD
+			change := NewVersionChangeBuilder(v1, v2).
+				ForType(BypassUser{}).
+				ResponseToPreviousVersion().
+				RemoveField("name").
+				Build()
+
+			epochInstance, err := setupBasicEpoch([]*Version{v1, v2}, []*VersionChange{change})
+			Expect(err).NotTo(HaveOccurred())
+
+			// Non-canonical JSON (extra whitespace) would be reformatted if the body were parsed
+			rawBody := "{ \"id\": 1,  \"total\": 42 }"
+			router := setupRouterWithMiddleware(epochInstance)
+			router.GET("/orders/:id", epochInstance.WrapHandler(func(c *gin.Context) {
+				c.Data(200, "application/json", []byte(rawBody))
+			}).Returns(BypassOrder{}).ToHandlerFunc("GET", "/orders/:id"))
+			router.GET("/users/:id", epochInstance.WrapHandler(func(c *gin.Context) {
+				c.Data(200, "application/json", []byte("{ \"id\": 1,  \"name\": \"Jane\" }"))
+			}).Returns(BypassUser{}).ToHandlerFunc("GET", "/users/:id"))
+
+			req := httptest.NewRequest("GET", "/orders/1", nil)
+			req.Header.Set("X-API-Version", "2024-01-01")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			Expect(recorder.Code).To(Equal(200))
+			Expect(recorder.Body.String()).To(Equal(rawBody))
+
+			// The migrated endpoint still goes through the full pipeline
+			req = httptest.NewRequest("GET", "/users/1", nil)
+			req.Header.Set("X-API-Version", "2024-01-01")
+			recorder = httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			Expect(recorder.Code).To(Equal(200))
+			Expect(recorder.Body.String()).To(Equal(`{"id":1}`))
+		})
+
+		It("should not bypass when a nested type is migrated", func() {
+			type BypassCart struct {
+				ID    int          `json:"id"`
+				Owner BypassUser   `json:"owner"`
+				Items []BypassUser `json:"items"`
+			}
+
+			v1, _ := NewDateVersion("2024-01-01")
+			v2, _ := NewDateVersion("2024-06-01")
+
+			change := NewVersionChangeBuilder(v1, v2).
+				ForType(BypassUser{}).
+				ResponseToPreviousVersion().
+				RemoveField("name").
+				Build()
+
+			epochInstance, err := setupBasicEpoch([]*Version{v1, v2}, []*VersionChange{change})
+			Expect(err).NotTo(HaveOccurred())
+
+			router := setupRouterWithMiddleware(epochInstance)
+			router.GET("/carts/:id", epochInstance.WrapHandler(func(c *gin.Context) {
+				c.JSON(200, BypassCart{ID: 1, Owner: BypassUser{ID: 2, Name: "Jane"}, Items: []BypassUser{{ID: 3, Name: "Bob"}}})
+			}).Returns(BypassCart{}).ToHandlerFunc("GET", "/carts/:id"))
+
+			req := httptest.NewRequest("GET", "/carts/1", nil)
+			req.Header.Set("X-API-Version", "2024-01-01")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			Expect(recorder.Code).To(Equal(200))
+			Expect(recorder.Body.String()).To(Equal(`{"id":1,"owner":{"id":2},"items":[{"id":3}]}`))
+		})
+	})
+
+	Describe("Keys with dots, slashes and non-ASCII characters", func() {
+		type LocalizedSettings struct {
+			Enabled bool   `json:"enabled"`
+			Label   string `json:"libellé"`
+		}
+		type LocalizedAccount struct {
+			ID       int               `json:"id"`
+			Name     string            `json:"名前"`
+			Path     string            `json:"a/b"`
+			Settings LocalizedSettings `json:"config.v2"`
+		}
+
+		It("should migrate fields and nested types under such keys", func() {
+			v1, _ := NewDateVersion("2024-01-01")
+			v2, _ := NewDateVersion("2024-06-01")
+
+			changes := []*VersionChange{
+				NewVersionChangeBuilder(v1, v2).
+					ForType(LocalizedAccount{}).
+					ResponseToPreviousVersion().
+					RenameField("名前", "name").
+					RemoveField("a/b").
+					MoveField(EscapePathKey("config.v2")+".enabled", "enabled").
+					ForType(LocalizedSettings{}).
+					ResponseToPreviousVersion().
+					RenameField("libellé", "label").
+					Build(),
+			}
+			epochInstance, err := setupBasicEpoch([]*Version{v1, v2}, changes)
+			Expect(err).NotTo(HaveOccurred())
+
+			router := setupRouterWithMiddleware(epochInstance)
+			router.GET("/accounts/:id", epochInstance.WrapHandler(func(c *gin.Context) {
+				c.JSON(200, LocalizedAccount{ID: 1, Name: "山田", Path: "/x", Settings: LocalizedSettings{Enabled: true, Label: "Été"}})
+			}).Returns(LocalizedAccount{}).ToHandlerFunc("GET", "/accounts/:id"))
+
+			req := httptest.NewRequest("GET", "/accounts/1", nil)
+			req.Header.Set("X-API-Version", "2024-01-01")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			Expect(recorder.Code).To(Equal(200))
+			Expect(recorder.Body.String()).To(MatchJSON(`{"id":1,"name":"山田","config.v2":{"label":"Été"},"enabled":true}`))
+		})
+	})
 })
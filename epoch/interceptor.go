@@ -0,0 +1,63 @@
+package epoch
+
+import (
+	"io"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ResponseInterceptor is implemented by middleware that wraps the response writer to observe
+// or modify the body, e.g. compression or audit logging. Register interceptors with Epoch
+// (WithResponseInterceptors) or InterceptResponses rather than swapping c.Writer by hand, so
+// their order relative to each other and to Epoch's migration is fixed.
+type ResponseInterceptor interface {
+	// WrapResponseWriter returns a writer that writes through to w. If the returned writer
+	// implements io.Closer, it's closed once the handler finished, e.g. to flush compression.
+	WrapResponseWriter(c *gin.Context, w gin.ResponseWriter) gin.ResponseWriter
+}
+
+// ResponseInterceptorFunc adapts a function to a ResponseInterceptor
+type ResponseInterceptorFunc func(c *gin.Context, w gin.ResponseWriter) gin.ResponseWriter
+
+// WrapResponseWriter calls f(c, w)
+func (f ResponseInterceptorFunc) WrapResponseWriter(c *gin.Context, w gin.ResponseWriter) gin.ResponseWriter {
+	return f(c, w)
+}
+
+// InterceptResponses returns middleware installing interceptors around the rest of the chain,
+// in the same order as WithResponseInterceptors: the first is outermost, closest to the client.
+func InterceptResponses(interceptors ...ResponseInterceptor) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		finish := applyResponseInterceptors(c, interceptors)
+		defer finish()
+		c.Next()
+	}
+}
+
+// applyResponseInterceptors wraps c.Writer with the interceptors, the first outermost
+// The returned func closes the wrapping writers innermost first and restores c.Writer.
+func applyResponseInterceptors(c *gin.Context, interceptors []ResponseInterceptor) func() {
+	original := c.Writer
+	writers := make([]gin.ResponseWriter, 0, len(interceptors))
+	for _, interceptor := range interceptors {
+		c.Writer = interceptor.WrapResponseWriter(c, c.Writer)
+		writers = append(writers, c.Writer)
+	}
+	return func() {
+		for i := len(writers) - 1; i >= 0; i-- {
+			if closer, ok := writers[i].(io.Closer); ok {
+				if err := closer.Close(); err != nil {
+					_ = c.Error(err)
+				}
+			}
+		}
+		c.Writer = original
+	}
+}
+
+// WithResponseInterceptors wraps the response writer with interceptors, the first outermost
+// (closest to the client). Interceptors see the response after migration to the client's version.
+func (vah *VersionAwareHandler) WithResponseInterceptors(interceptors ...ResponseInterceptor) *VersionAwareHandler {
+	vah.interceptors = append(vah.interceptors, interceptors...)
+	return vah
+}
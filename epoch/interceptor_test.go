@@ -0,0 +1,135 @@
+package epoch
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type interceptedUser struct {
+	FullName string `json:"full_name"`
+}
+
+// gzipInterceptor compresses everything written through it
+type gzipInterceptor struct{}
+
+type gzipResponseWriter struct {
+	gin.ResponseWriter
+	zw *gzip.Writer
+}
+
+func (w *gzipResponseWriter) Write(data []byte) (int, error) { return w.zw.Write(data) }
+
+func (w *gzipResponseWriter) WriteString(s string) (int, error) { return w.zw.Write([]byte(s)) }
+
+func (w *gzipResponseWriter) Close() error { return w.zw.Close() }
+
+func (gzipInterceptor) WrapResponseWriter(c *gin.Context, w gin.ResponseWriter) gin.ResponseWriter {
+	w.Header().Set("Content-Encoding", "gzip")
+	return &gzipResponseWriter{ResponseWriter: w, zw: gzip.NewWriter(w)}
+}
+
+// recordingWriter copies the body written through it
+type recordingWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func recordBody(body *bytes.Buffer) ResponseInterceptor {
+	return ResponseInterceptorFunc(func(c *gin.Context, w gin.ResponseWriter) gin.ResponseWriter {
+		return &recordingWriter{ResponseWriter: w, body: body}
+	})
+}
+
+var _ = Describe("Response interceptors", func() {
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+	})
+
+	build := func(interceptors ...ResponseInterceptor) *Epoch {
+		v1, v2, _ := newTestVersions()
+		change := NewVersionChangeBuilder(v1, v2).
+			ForType(interceptedUser{}).
+			ResponseToPreviousVersion().
+			RenameField("full_name", "name").
+			Build()
+		epochInstance, err := setupHeadEpoch([]*Version{v1, v2}, []*VersionChange{change}, func(builder *EpochBuilder) *EpochBuilder {
+			return builder.WithResponseInterceptors(interceptors...)
+		})
+		Expect(err).NotTo(HaveOccurred())
+		return epochInstance
+	}
+
+	serve := func(router *gin.Engine) *httptest.ResponseRecorder {
+		return serveVersioned(router, httptest.NewRequest("GET", "/users/1", nil), "2024-01-01")
+	}
+
+	gunzip := func(body []byte) string {
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		Expect(err).NotTo(HaveOccurred())
+		plain, err := io.ReadAll(zr)
+		Expect(err).NotTo(HaveOccurred())
+		return string(plain)
+	}
+
+	handler := func(c *gin.Context) {
+		c.JSON(200, interceptedUser{FullName: "Ada"})
+	}
+
+	It("should run interceptors on the migrated response, the first outermost", func() {
+		var audited bytes.Buffer
+		epochInstance := build(gzipInterceptor{}, recordBody(&audited))
+
+		router := setupRouterWithMiddleware(epochInstance)
+		router.GET("/users/:id", epochInstance.WrapHandler(handler).
+			Returns(interceptedUser{}).ToHandlerFunc("GET", "/users/:id"))
+
+		recorder := serve(router)
+		Expect(recorder.Code).To(Equal(200))
+		Expect(recorder.Header().Get("Content-Encoding")).To(Equal("gzip"))
+		Expect(gunzip(recorder.Body.Bytes())).To(MatchJSON(`{"name":"Ada"}`))
+		// The audit interceptor sits inside gzip, so it sees the plain migrated body
+		Expect(audited.String()).To(MatchJSON(`{"name":"Ada"}`))
+	})
+
+	It("should order interceptors the same way as middleware", func() {
+		var audited bytes.Buffer
+		epochInstance := build()
+
+		router := setupRouterWithMiddleware(epochInstance)
+		router.Use(InterceptResponses(recordBody(&audited), gzipInterceptor{}))
+		router.GET("/users/:id", epochInstance.WrapHandler(handler).
+			Returns(interceptedUser{}).ToHandlerFunc("GET", "/users/:id"))
+
+		recorder := serve(router)
+		Expect(gunzip(recorder.Body.Bytes())).To(MatchJSON(`{"name":"Ada"}`))
+		// The audit interceptor sits outside gzip, so it sees the compressed body
+		Expect(gunzip(audited.Bytes())).To(MatchJSON(`{"name":"Ada"}`))
+	})
+
+	It("should restore the original writer afterwards", func() {
+		var after gin.ResponseWriter
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			before := c.Writer
+			c.Next()
+			after = c.Writer
+			Expect(after).To(BeIdenticalTo(before))
+		})
+		router.Use(InterceptResponses(gzipInterceptor{}))
+		router.GET("/users/:id", handler)
+
+		serve(router)
+		Expect(after).NotTo(BeNil())
+	})
+})
@@ -0,0 +1,23 @@
+package epoch
+
+// WithInternalHeadVersion adds the head version for handlers without exposing it: requests
+// asking for head are rejected as an unknown version, and requests without a version get
+// the default version, or else the newest one, instead of head. Clients can't couple to
+// shapes that haven't been released as a version yet.
+func (cb *EpochBuilder) WithInternalHeadVersion() *EpochBuilder {
+	cb.internalHead = true
+	return cb.WithHeadVersion()
+}
+
+// defaultHeadVersion is the version requests without one get when no default is configured:
+// head, or the newest other version while head is internal
+func (vm *VersionMiddleware) defaultHeadVersion() *Version {
+	if !vm.internalHead {
+		return vm.versionBundle.GetHeadVersion()
+	}
+	newest := vm.newestVersion()
+	if newest == nil {
+		return vm.versionBundle.GetHeadVersion()
+	}
+	return newest
+}
@@ -0,0 +1,73 @@
+package epoch
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Internal head version", func() {
+	var router *gin.Engine
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+	})
+
+	setup := func(builder *EpochBuilder) {
+		v1, _ := NewDateVersion("2024-01-01")
+		v2, _ := NewDateVersion("2025-01-01")
+		epochInstance, err := builder.WithVersions(v1, v2).WithVersionParameter("X-API-Version").Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		router = gin.New()
+		router.Use(epochInstance.Middleware())
+		router.GET("/version", func(c *gin.Context) {
+			version := GetVersionFromContext(c)
+			c.String(http.StatusOK, version.String())
+		})
+	}
+
+	get := func(version string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/version", nil)
+		if version != "" {
+			req.Header.Set("X-API-Version", version)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	It("should reject clients asking for head", func() {
+		setup(NewEpoch().WithInternalHeadVersion())
+
+		recorder := get("head")
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		var body map[string]interface{}
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &body)).To(Succeed())
+		Expect(body["error"]).To(Equal("Unknown version: head"))
+		Expect(body["available_versions"]).To(Equal([]interface{}{"2024-01-01", "2025-01-01"}))
+
+		Expect(get("2024-01-01").Body.String()).To(Equal("2024-01-01"))
+	})
+
+	It("should default requests without a version to the newest version", func() {
+		setup(NewEpoch().WithInternalHeadVersion())
+		Expect(get("").Body.String()).To(Equal("2025-01-01"))
+	})
+
+	It("should keep a configured default version", func() {
+		v1, _ := NewDateVersion("2024-01-01")
+		setup(NewEpoch().WithInternalHeadVersion().WithDefaultVersion(v1))
+		Expect(get("").Body.String()).To(Equal("2024-01-01"))
+	})
+
+	It("should let clients select head with WithHeadVersion", func() {
+		setup(NewEpoch().WithHeadVersion())
+		Expect(get("head").Body.String()).To(Equal("head"))
+		Expect(get("").Body.String()).To(Equal("head"))
+	})
+})
@@ -0,0 +1,205 @@
+package epoch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/astronomer/epoch/epoch/jsonast"
+)
+
+// JSONEngine parses request/response bodies into AST nodes and serializes them back
+//
+// Migrations always operate on *jsonast.Node trees. The engine only controls how bytes become
+// a tree and how a tree becomes bytes, which is where Sonic's native (JIT/SIMD) code runs.
+// Select an engine with EpochBuilder.WithJSONEngine() or the epoch_stdjson build tag.
+type JSONEngine interface {
+	// Name returns a short identifier for the engine (e.g. "sonic", "std")
+	Name() string
+	// Parse parses a JSON document into a fully loaded AST node
+	Parse(data []byte) (*jsonast.Node, error)
+	// Serialize converts an AST node back to JSON, preserving field order
+	Serialize(node *jsonast.Node) ([]byte, error)
+}
+
+// StdJSONEngine parses and serializes with encoding/json only.
+// It builds the same ordered AST so transformers behave identically, and is
+// intended for platforms where Sonic's native code is unavailable or unstable.
+var StdJSONEngine JSONEngine = stdJSONEngine{}
+
+// DefaultJSONEngine returns the engine used when none is configured.
+// This is Sonic unless the binary is built with the epoch_stdjson tag.
+func DefaultJSONEngine() JSONEngine {
+	return defaultJSONEngine
+}
+
+// ============================================================================
+// encoding/json engine
+// ============================================================================
+
+type stdJSONEngine struct{}
+
+func (stdJSONEngine) Name() string {
+	return "std"
+}
+
+func (stdJSONEngine) Parse(data []byte) (*jsonast.Node, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	node, err := decodeStdNode(decoder)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reject trailing data, matching Sonic's behavior for invalid documents
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, errors.New("invalid JSON: unexpected data after top-level value")
+	}
+	return &node, nil
+}
+
+// decodeStdNode reads the next JSON value from the decoder as an ordered AST node
+func decodeStdNode(decoder *json.Decoder) (jsonast.Node, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return jsonast.Node{}, err
+	}
+
+	switch value := token.(type) {
+	case json.Delim:
+		switch value {
+		case '{':
+			var pairs []jsonast.Pair
+			for decoder.More() {
+				keyToken, err := decoder.Token()
+				if err != nil {
+					return jsonast.Node{}, err
+				}
+				key, ok := keyToken.(string)
+				if !ok {
+					return jsonast.Node{}, fmt.Errorf("invalid JSON: expected object key, got %v", keyToken)
+				}
+				child, err := decodeStdNode(decoder)
+				if err != nil {
+					return jsonast.Node{}, err
+				}
+				pairs = append(pairs, jsonast.NewPair(key, child))
+			}
+			if _, err := decoder.Token(); err != nil {
+				return jsonast.Node{}, err
+			}
+			return jsonast.NewObject(pairs), nil
+		case '[':
+			var items []jsonast.Node
+			for decoder.More() {
+				child, err := decodeStdNode(decoder)
+				if err != nil {
+					return jsonast.Node{}, err
+				}
+				items = append(items, child)
+			}
+			if _, err := decoder.Token(); err != nil {
+				return jsonast.Node{}, err
+			}
+			return jsonast.NewArray(items), nil
+		}
+		return jsonast.Node{}, fmt.Errorf("invalid JSON: unexpected delimiter %v", value)
+	case string:
+		return jsonast.NewString(value), nil
+	case json.Number:
+		return jsonast.NewNumber(value.String()), nil
+	case bool:
+		return jsonast.NewBool(value), nil
+	case nil:
+		return jsonast.NewNull(), nil
+	}
+
+	return jsonast.Node{}, fmt.Errorf("invalid JSON: unexpected token %v", token)
+}
+
+func (stdJSONEngine) Serialize(node *jsonast.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encodeStdNode(&buf, node); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeStdNode writes an AST node as JSON using encoding/json for scalar values
+func encodeStdNode(buf *bytes.Buffer, node *jsonast.Node) error {
+	switch node.TypeSafe() {
+	case jsonast.V_OBJECT:
+		iter, err := node.Properties()
+		if err != nil {
+			return err
+		}
+		buf.WriteByte('{')
+		first := true
+		var pair jsonast.Pair
+		for iter.Next(&pair) {
+			if !first {
+				buf.WriteByte(',')
+			}
+			first = false
+			key, err := json.Marshal(pair.Key)
+			if err != nil {
+				return err
+			}
+			buf.Write(key)
+			buf.WriteByte(':')
+			value := pair.Value
+			if err := encodeStdNode(buf, &value); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+		return nil
+
+	case jsonast.V_ARRAY:
+		iter, err := node.Values()
+		if err != nil {
+			return err
+		}
+		buf.WriteByte('[')
+		first := true
+		var item jsonast.Node
+		for iter.Next(&item) {
+			if !first {
+				buf.WriteByte(',')
+			}
+			first = false
+			if err := encodeStdNode(buf, &item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+
+	case jsonast.V_NUMBER:
+		number, err := node.Number()
+		if err != nil {
+			return err
+		}
+		buf.WriteString(number.String())
+		return nil
+
+	case jsonast.V_NONE, jsonast.V_ERROR:
+		return fmt.Errorf("cannot serialize invalid node: %v", node.Check())
+
+	default:
+		// Strings, booleans, null and V_ANY values (set via SetAny)
+		value, err := node.InterfaceUseNumber()
+		if err != nil {
+			return err
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		buf.Write(encoded)
+		return nil
+	}
+}
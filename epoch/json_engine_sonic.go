@@ -0,0 +1,47 @@
+//go:build !epoch_stdjson
+
+package epoch
+
+import (
+	"github.com/astronomer/epoch/epoch/jsonast"
+	"github.com/bytedance/sonic"
+)
+
+// SonicJSONEngine parses with Sonic's native parser (fastest on supported platforms).
+// It isn't compiled into binaries built with the epoch_stdjson tag.
+var SonicJSONEngine JSONEngine = sonicJSONEngine{}
+
+// defaultJSONEngine is Sonic unless built with -tags epoch_stdjson
+var defaultJSONEngine = SonicJSONEngine
+
+// ============================================================================
+// Sonic engine
+// ============================================================================
+
+type sonicJSONEngine struct{}
+
+func (sonicJSONEngine) Name() string {
+	return "sonic"
+}
+
+func (sonicJSONEngine) Parse(data []byte) (*jsonast.Node, error) {
+	node, err := sonic.Get(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// IMPORTANT: sonic.Get() returns a search node that needs to be loaded
+	if err := node.Load(); err != nil {
+		return nil, err
+	}
+	return &node, nil
+}
+
+func (sonicJSONEngine) Serialize(node *jsonast.Node) ([]byte, error) {
+	// Use Sonic's Raw() to preserve field order
+	raw, err := node.Raw()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(raw), nil
+}
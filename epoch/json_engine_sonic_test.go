@@ -0,0 +1,6 @@
+//go:build !epoch_stdjson
+
+package epoch
+
+// jsonEngines are the engines compiled into the tests
+var jsonEngines = []JSONEngine{SonicJSONEngine, StdJSONEngine}
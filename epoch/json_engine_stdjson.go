@@ -0,0 +1,7 @@
+//go:build epoch_stdjson
+
+package epoch
+
+// defaultJSONEngine is encoding/json when built with -tags epoch_stdjson, which also leaves
+// Sonic out of the binary
+var defaultJSONEngine = StdJSONEngine
@@ -0,0 +1,17 @@
+//go:build epoch_stdjson
+
+package epoch
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// jsonEngines are the engines compiled into the tests
+var jsonEngines = []JSONEngine{StdJSONEngine}
+
+var _ = Describe("JSONEngine with epoch_stdjson", func() {
+	It("should default to encoding/json", func() {
+		Expect(DefaultJSONEngine()).To(Equal(StdJSONEngine))
+	})
+})
@@ -0,0 +1,67 @@
+package epoch
+
+import (
+	"github.com/astronomer/epoch/epoch/jsonast"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("JSONEngine", func() {
+	const document = `{"id":1,"name":"Jane","price":10.50,"big":12345678901234567890,"tags":["a","b"],"profile":{"bio":"hi","avatar":null},"active":true}`
+
+	for _, engine := range jsonEngines {
+		engine := engine
+
+		Describe(engine.Name(), func() {
+			It("should round-trip a document preserving field order", func() {
+				node, err := engine.Parse([]byte(document))
+				Expect(err).NotTo(HaveOccurred())
+
+				out, err := engine.Serialize(node)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(out)).To(Equal(document))
+			})
+
+			It("should serialize modifications made through the AST helpers", func() {
+				node, err := engine.Parse([]byte(`{"name":"Jane","email":"j@example.com"}`))
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(RenameNodeField(node, "name", "full_name")).To(Succeed())
+				Expect(DeleteNodeField(node, "email")).To(Succeed())
+				Expect(SetNodeField(node, "roles", []string{"admin"})).To(Succeed())
+
+				out, err := engine.Serialize(node)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(out)).To(Equal(`{"full_name":"Jane","roles":["admin"]}`))
+			})
+
+			It("should parse into loaded nodes that support random access", func() {
+				node, err := engine.Parse([]byte(`[{"id":1},{"id":2}]`))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(node.TypeSafe()).To(Equal(jsonast.V_ARRAY))
+
+				id, err := GetNodeIntAtPath(node, "1.id")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(id).To(Equal(int64(2)))
+			})
+
+			It("should reject invalid JSON", func() {
+				_, err := engine.Parse([]byte(`{"name":`))
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	}
+
+	It("should be configurable on the builder", func() {
+		epochInstance, err := NewEpoch().
+			WithHeadVersion().
+			WithJSONEngine(StdJSONEngine).
+			Build()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(epochInstance.JSONEngine()).To(Equal(StdJSONEngine))
+
+		defaultInstance, err := Simple()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(defaultInstance.JSONEngine()).To(Equal(DefaultJSONEngine()))
+	})
+})
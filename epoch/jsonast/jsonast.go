@@ -0,0 +1,7 @@
+// Package jsonast is the ordered JSON tree Epoch migrates bodies on.
+//
+// By default it is Sonic's ast package: Node, Pair and the constructors are aliases, so
+// transformers written against github.com/bytedance/sonic/ast keep compiling. Built with
+// -tags epoch_stdjson it is a pure Go implementation of the same API on encoding/json, and
+// the binary doesn't link Sonic at all, for platforms where Sonic's native code doesn't build.
+package jsonast
@@ -0,0 +1,13 @@
+package jsonast
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestJSONAST(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "JSONAST Suite")
+}
@@ -0,0 +1,136 @@
+package jsonast
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// These specs run against Sonic by default and the pure Go nodes with -tags epoch_stdjson,
+// so both builds behave the same for Epoch
+var _ = Describe("Node", func() {
+	load := func(raw string) Node {
+		node := NewRaw(raw)
+		Expect(node.Load()).To(Succeed())
+		return node
+	}
+
+	It("should keep raw documents as written until they are used", func() {
+		node := NewRaw(` {"b": 1, "a": [true, null]} `)
+		Expect(node.TypeSafe()).To(Equal(V_OBJECT))
+		Expect(node.Raw()).To(Equal(`{"b": 1, "a": [true, null]}`))
+
+		Expect(node.Get("b").Int64()).To(Equal(int64(1)))
+		Expect(node.Raw()).To(MatchJSON(`{"b":1,"a":[true,null]}`))
+	})
+
+	It("should report invalid documents as error nodes", func() {
+		node := NewRaw(`{"a":`)
+		Expect(node.Valid() && node.Load() == nil).To(BeFalse())
+		Expect(node.Exists()).To(BeFalse())
+	})
+
+	It("should return nil for missing keys and indexes and errors for the wrong type", func() {
+		node := load(`{"items":[1],"name":"Ada"}`)
+		Expect(node.Get("missing")).To(BeNil())
+		Expect(node.Get("missing").Exists()).To(BeFalse())
+		Expect(node.Get("items").Index(5)).To(BeNil())
+
+		wrongType := node.Get("name").Get("first")
+		Expect(wrongType.Exists()).To(BeFalse())
+		Expect(wrongType.Valid()).To(BeFalse())
+		Expect(wrongType.Check()).To(HaveOccurred())
+	})
+
+	It("should set, replace and unset members keeping their order", func() {
+		node := load(`{"id":1,"name":"Ada","email":"ada@example.com"}`)
+
+		Expect(node.Set("name", NewString("Grace"))).To(BeTrue())
+		Expect(node.Set("role", NewString("admin"))).To(BeFalse())
+		Expect(node.Unset("email")).To(BeTrue())
+		Expect(node.Unset("email")).To(BeFalse())
+
+		Expect(node.Len()).To(Equal(3))
+		Expect(node.Raw()).To(Equal(`{"id":1,"name":"Grace","role":"admin"}`))
+	})
+
+	It("should edit children in place through Get and Index", func() {
+		node := load(`{"profile":{"bio":"hi"},"tags":["a"]}`)
+
+		_, err := node.Get("profile").Set("avatar", NewNull())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(node.Get("tags").Add(NewString("b"))).To(Succeed())
+		*node.Get("tags").Index(0) = NewString("z")
+
+		Expect(node.Raw()).To(Equal(`{"profile":{"bio":"hi","avatar":null},"tags":["z","b"]}`))
+	})
+
+	It("should turn none and null nodes into containers", func() {
+		var object Node
+		Expect(object.Set("a", NewNumber("1"))).To(BeFalse())
+		Expect(object.Raw()).To(Equal(`{"a":1}`))
+
+		array := NewNull()
+		Expect(array.Add(NewBool(true))).To(Succeed())
+		Expect(array.Raw()).To(Equal(`[true]`))
+	})
+
+	It("should remove children by index", func() {
+		node := load(`[1,2,3]`)
+		Expect(node.UnsetByIndex(1)).To(BeTrue())
+		Expect(node.SetByIndex(1, NewString("three"))).To(BeTrue())
+		Expect(node.Raw()).To(Equal(`[1,"three"]`))
+		Expect(node.Len()).To(Equal(2))
+	})
+
+	It("should convert values like Sonic", func() {
+		node := load(`{"n":"42","f":1.5,"t":true,"z":null,"big":12345678901234567890}`)
+
+		Expect(node.Get("n").Int64()).To(Equal(int64(42)))
+		Expect(node.Get("f").String()).To(Equal("1.5"))
+		Expect(node.Get("t").String()).To(Equal("true"))
+		Expect(node.Get("z").String()).To(Equal(""))
+		Expect(node.Get("t").Int64()).To(Equal(int64(1)))
+		Expect(node.Get("big").Number()).To(Equal(json.Number("12345678901234567890")))
+
+		value, err := node.InterfaceUseNumber()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(value).To(HaveKeyWithValue("f", json.Number("1.5")))
+
+		value, err = node.Interface()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(value).To(HaveKeyWithValue("f", 1.5))
+	})
+
+	It("should iterate over children in order", func() {
+		node := load(`{"a":1,"b":[true,false]}`)
+
+		var keys []string
+		iter, err := node.Properties()
+		Expect(err).NotTo(HaveOccurred())
+		var pair Pair
+		for iter.Next(&pair) {
+			keys = append(keys, pair.Key)
+		}
+		Expect(keys).To(Equal([]string{"a", "b"}))
+
+		var items []int
+		Expect(node.Get("b").ForEach(func(path Sequence, item *Node) bool {
+			items = append(items, path.Index)
+			return true
+		})).To(Succeed())
+		Expect(items).To(Equal([]int{0, 1}))
+
+		_, err = node.Values()
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should encode Go values and strings without escaping HTML", func() {
+		node := NewObject([]Pair{
+			NewPair("html", NewString("<b>&</b>")),
+			NewPair("any", NewAny(map[string]int{"x": 1})),
+		})
+		Expect(node.Raw()).To(Equal(`{"html":"<b>&</b>","any":{"x":1}}`))
+	})
+})
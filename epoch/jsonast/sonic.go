@@ -0,0 +1,81 @@
+//go:build !epoch_stdjson
+
+package jsonast
+
+import (
+	"github.com/bytedance/sonic/ast"
+)
+
+// Node is a JSON value: an object, array, string, number, boolean or null
+type Node = ast.Node
+
+// Pair is a key and value of an object node
+type Pair = ast.Pair
+
+// Sequence is the position of a child visited by Node.ForEach
+type Sequence = ast.Sequence
+
+// Scanner visits children in Node.ForEach; returning false stops the scan
+type Scanner = ast.Scanner
+
+// Iterator is the position of a ListIterator or ObjectIterator
+type Iterator = ast.Iterator
+
+// ListIterator iterates over the children of an array node
+type ListIterator = ast.ListIterator
+
+// ObjectIterator iterates over the pairs of an object node
+type ObjectIterator = ast.ObjectIterator
+
+// Node types returned by Node.TypeSafe
+const (
+	V_NONE   = ast.V_NONE
+	V_ERROR  = ast.V_ERROR
+	V_NULL   = ast.V_NULL
+	V_TRUE   = ast.V_TRUE
+	V_FALSE  = ast.V_FALSE
+	V_ARRAY  = ast.V_ARRAY
+	V_OBJECT = ast.V_OBJECT
+	V_STRING = ast.V_STRING
+	V_NUMBER = ast.V_NUMBER
+	V_ANY    = ast.V_ANY
+)
+
+var (
+	// ErrNotExist is returned for nodes that don't exist
+	ErrNotExist = ast.ErrNotExist
+
+	// ErrUnsupportType is returned for operations the node's type doesn't support
+	ErrUnsupportType = ast.ErrUnsupportType
+)
+
+// NewRaw creates a node from a JSON document, parsed when first used.
+// Invalid JSON creates an error node.
+func NewRaw(json string) Node { return ast.NewRaw(json) }
+
+// NewAny creates a node holding a Go value, encoded as JSON when serialized
+func NewAny(any interface{}) Node { return ast.NewAny(any) }
+
+// NewBytes creates a string node of src encoded with base64
+func NewBytes(src []byte) Node { return ast.NewBytes(src) }
+
+// NewNull creates a null node
+func NewNull() Node { return ast.NewNull() }
+
+// NewBool creates a boolean node
+func NewBool(v bool) Node { return ast.NewBool(v) }
+
+// NewNumber creates a number node from its JSON literal
+func NewNumber(v string) Node { return ast.NewNumber(v) }
+
+// NewString creates a string node
+func NewString(v string) Node { return ast.NewString(v) }
+
+// NewArray creates an array node of the given children
+func NewArray(v []Node) Node { return ast.NewArray(v) }
+
+// NewObject creates an object node of the given pairs, in order
+func NewObject(v []Pair) Node { return ast.NewObject(v) }
+
+// NewPair creates an object member
+func NewPair(key string, val Node) Pair { return ast.NewPair(key, val) }
@@ -0,0 +1,1355 @@
+//go:build epoch_stdjson
+
+package jsonast
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// Node is a JSON value: an object, array, string, number, boolean or null.
+//
+// It mirrors Sonic's ast.Node: children returned by Get and Index point into the tree, so
+// setting them edits it, and a missing key or index returns nil.
+type Node struct {
+	t     int
+	raw   bool        // s holds the unparsed JSON of a NewRaw node
+	s     string      // string value, number literal, raw JSON or error message
+	pairs []*Pair     // object members, in order
+	items []*Node     // array children
+	any   interface{} // value of a V_ANY node
+}
+
+// Pair is a key and value of an object node
+type Pair struct {
+	Key   string
+	Value Node
+}
+
+// Sequence is the position of a child visited by Node.ForEach. Key is nil for array children.
+type Sequence struct {
+	Index int
+	Key   *string
+}
+
+// String describes the position
+func (s Sequence) String() string {
+	k := ""
+	if s.Key != nil {
+		k = *s.Key
+	}
+	return fmt.Sprintf("Sequence(%d, %q)", s.Index, k)
+}
+
+// Scanner visits children in Node.ForEach; returning false stops the scan
+type Scanner func(path Sequence, node *Node) bool
+
+// Node types returned by Node.TypeSafe, with the same values as Sonic's
+const (
+	V_NONE   = 0
+	V_ERROR  = 1
+	V_NULL   = 2
+	V_TRUE   = 3
+	V_FALSE  = 4
+	V_ARRAY  = 5
+	V_OBJECT = 6
+	V_STRING = 7
+	V_NUMBER = 33
+	V_ANY    = 34
+)
+
+var (
+	// ErrNotExist is returned for nodes that don't exist
+	ErrNotExist error = newError("value not exists")
+
+	// ErrUnsupportType is returned for operations the node's type doesn't support
+	ErrUnsupportType error = newError("unsupported type")
+)
+
+func newError(msg string) *Node {
+	return &Node{t: V_ERROR, s: msg}
+}
+
+// unwrapError returns err as an error node
+func unwrapError(err error) *Node {
+	if node, ok := err.(*Node); ok {
+		return node
+	}
+	return newError(err.Error())
+}
+
+// ============================================================================
+// Constructors
+// ============================================================================
+
+// NewRaw creates a node from a JSON document, parsed when first used.
+// Invalid JSON creates an error node.
+func NewRaw(data string) Node {
+	var raw json.RawMessage
+	if err := json.NewDecoder(bytes.NewReader([]byte(data))).Decode(&raw); err != nil {
+		if err == io.EOF {
+			return Node{}
+		}
+		return *newError(err.Error())
+	}
+	raw = bytes.TrimSpace(raw)
+	return Node{t: rawType(raw[0]), raw: true, s: string(raw)}
+}
+
+// rawType returns the type of the JSON value starting with c
+func rawType(c byte) int {
+	switch c {
+	case '{':
+		return V_OBJECT
+	case '[':
+		return V_ARRAY
+	case '"':
+		return V_STRING
+	case 't':
+		return V_TRUE
+	case 'f':
+		return V_FALSE
+	case 'n':
+		return V_NULL
+	}
+	return V_NUMBER
+}
+
+// NewAny creates a node holding a Go value, encoded as JSON when serialized
+func NewAny(any interface{}) Node {
+	switch n := any.(type) {
+	case Node:
+		return n
+	case *Node:
+		return *n
+	}
+	return Node{t: V_ANY, any: any}
+}
+
+// NewBytes creates a string node of src encoded with base64
+func NewBytes(src []byte) Node {
+	if len(src) == 0 {
+		panic("empty src bytes")
+	}
+	return NewString(base64.StdEncoding.EncodeToString(src))
+}
+
+// NewNull creates a null node
+func NewNull() Node {
+	return Node{t: V_NULL}
+}
+
+// NewBool creates a boolean node
+func NewBool(v bool) Node {
+	if v {
+		return Node{t: V_TRUE}
+	}
+	return Node{t: V_FALSE}
+}
+
+// NewNumber creates a number node from its JSON literal
+func NewNumber(v string) Node {
+	return Node{t: V_NUMBER, s: v}
+}
+
+// NewString creates a string node
+func NewString(v string) Node {
+	return Node{t: V_STRING, s: v}
+}
+
+// NewArray creates an array node of the given children
+func NewArray(v []Node) Node {
+	items := make([]*Node, len(v))
+	for i := range v {
+		item := v[i]
+		items[i] = &item
+	}
+	return Node{t: V_ARRAY, items: items}
+}
+
+// NewObject creates an object node of the given pairs, in order
+func NewObject(v []Pair) Node {
+	pairs := make([]*Pair, len(v))
+	for i := range v {
+		pair := v[i]
+		pairs[i] = &pair
+	}
+	return Node{t: V_OBJECT, pairs: pairs}
+}
+
+// NewPair creates an object member
+func NewPair(key string, val Node) Pair {
+	return Pair{Key: key, Value: val}
+}
+
+// ============================================================================
+// Parsing
+// ============================================================================
+
+// parse decodes a complete JSON document into a node
+func parse(data string) (Node, error) {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(data)))
+	decoder.UseNumber()
+	node, err := decode(decoder)
+	if err != nil {
+		return Node{}, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return Node{}, errors.New("invalid JSON: unexpected data after top-level value")
+	}
+	return node, nil
+}
+
+// decode reads the next JSON value from the decoder
+func decode(decoder *json.Decoder) (Node, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return Node{}, err
+	}
+
+	switch value := token.(type) {
+	case json.Delim:
+		switch value {
+		case '{':
+			node := Node{t: V_OBJECT}
+			for decoder.More() {
+				keyToken, err := decoder.Token()
+				if err != nil {
+					return Node{}, err
+				}
+				key, ok := keyToken.(string)
+				if !ok {
+					return Node{}, fmt.Errorf("invalid JSON: expected object key, got %v", keyToken)
+				}
+				child, err := decode(decoder)
+				if err != nil {
+					return Node{}, err
+				}
+				node.pairs = append(node.pairs, &Pair{Key: key, Value: child})
+			}
+			if _, err := decoder.Token(); err != nil {
+				return Node{}, err
+			}
+			return node, nil
+		case '[':
+			node := Node{t: V_ARRAY}
+			for decoder.More() {
+				child, err := decode(decoder)
+				if err != nil {
+					return Node{}, err
+				}
+				node.items = append(node.items, &child)
+			}
+			if _, err := decoder.Token(); err != nil {
+				return Node{}, err
+			}
+			return node, nil
+		}
+		return Node{}, fmt.Errorf("invalid JSON: unexpected delimiter %v", value)
+	case string:
+		return NewString(value), nil
+	case json.Number:
+		return NewNumber(value.String()), nil
+	case bool:
+		return NewBool(value), nil
+	case nil:
+		return NewNull(), nil
+	}
+	return Node{}, fmt.Errorf("invalid JSON: unexpected token %v", token)
+}
+
+// UnmarshalJSON makes the node a raw node of data
+func (self *Node) UnmarshalJSON(data []byte) error {
+	*self = NewRaw(string(data))
+	return self.Check()
+}
+
+// checkRaw parses raw nodes and reports whether the node is valid
+func (self *Node) checkRaw() error {
+	if err := self.Check(); err != nil {
+		return err
+	}
+	if self.raw {
+		node, err := parse(self.s)
+		if err != nil {
+			*self = *newError(err.Error())
+		} else {
+			*self = node
+		}
+	}
+	return self.Check()
+}
+
+// should reports an error unless the node is valid and of type t
+func (self *Node) should(t int) error {
+	if err := self.checkRaw(); err != nil {
+		return err
+	}
+	if self.t != t {
+		return ErrUnsupportType
+	}
+	return nil
+}
+
+// ============================================================================
+// Type accessors
+// ============================================================================
+
+// Type returns the node's type
+func (self Node) Type() int {
+	return self.t
+}
+
+// TypeSafe returns the node's type
+func (self *Node) TypeSafe() int {
+	if self == nil {
+		return V_NONE
+	}
+	return self.t
+}
+
+// Exists returns false if the node is nil, empty (V_NONE) or an error
+func (self *Node) Exists() bool {
+	return self != nil && self.t != V_NONE && self.t != V_ERROR
+}
+
+// Valid returns false if the node is nil or an error
+func (self *Node) Valid() bool {
+	return self != nil && self.t != V_ERROR
+}
+
+// Check returns ErrNotExist for nil nodes and the error of error nodes
+func (self *Node) Check() error {
+	if self == nil {
+		return ErrNotExist
+	}
+	if self.t == V_ERROR {
+		return self
+	}
+	return nil
+}
+
+// Error returns the message of an error node
+func (self Node) Error() string {
+	if self.t != V_ERROR {
+		return ""
+	}
+	return self.s
+}
+
+// IsRaw reports whether the node holds unparsed JSON
+func (self Node) IsRaw() bool {
+	return self.raw
+}
+
+// ============================================================================
+// Scalar values
+// ============================================================================
+
+// Raw returns the node's JSON
+func (self *Node) Raw() (string, error) {
+	if self == nil {
+		return "", ErrNotExist
+	}
+	if self.raw {
+		return self.s, nil
+	}
+	data, err := self.MarshalJSON()
+	return string(data), err
+}
+
+// Bool converts the node to a bool, including numbers, strings and null
+func (self *Node) Bool() (bool, error) {
+	if err := self.checkRaw(); err != nil {
+		return false, err
+	}
+	switch self.t {
+	case V_TRUE:
+		return true, nil
+	case V_FALSE, V_NULL:
+		return false, nil
+	case V_NUMBER:
+		if i, err := json.Number(self.s).Int64(); err == nil {
+			return i != 0, nil
+		}
+		f, err := json.Number(self.s).Float64()
+		return f != 0, err
+	case V_STRING:
+		return strconv.ParseBool(self.s)
+	case V_ANY:
+		switch v := self.any.(type) {
+		case bool:
+			return v, nil
+		case string:
+			return strconv.ParseBool(v)
+		case json.Number:
+			if i, err := v.Int64(); err == nil {
+				return i != 0, nil
+			}
+			f, err := v.Float64()
+			return f != 0, err
+		}
+		if f, ok := anyFloat(self.any); ok {
+			return f != 0, nil
+		}
+	}
+	return false, ErrUnsupportType
+}
+
+// Int64 converts the node to an int64, including strings, booleans and null
+func (self *Node) Int64() (int64, error) {
+	if err := self.checkRaw(); err != nil {
+		return 0, err
+	}
+	switch self.t {
+	case V_NUMBER, V_STRING:
+		return numberInt64(json.Number(self.s))
+	case V_TRUE:
+		return 1, nil
+	case V_FALSE, V_NULL:
+		return 0, nil
+	case V_ANY:
+		switch v := self.any.(type) {
+		case bool:
+			if v {
+				return 1, nil
+			}
+			return 0, nil
+		case string:
+			return numberInt64(json.Number(v))
+		case json.Number:
+			return numberInt64(v)
+		}
+		if i, ok := anyInt(self.any); ok {
+			return i, nil
+		}
+		if f, ok := anyFloat(self.any); ok {
+			return int64(f), nil
+		}
+	}
+	return 0, ErrUnsupportType
+}
+
+// StrictInt64 returns the int64 of number nodes
+func (self *Node) StrictInt64() (int64, error) {
+	if err := self.checkRaw(); err != nil {
+		return 0, err
+	}
+	switch self.t {
+	case V_NUMBER:
+		return json.Number(self.s).Int64()
+	case V_ANY:
+		if v, ok := self.any.(json.Number); ok {
+			return v.Int64()
+		}
+		if i, ok := anyInt(self.any); ok {
+			return i, nil
+		}
+	}
+	return 0, ErrUnsupportType
+}
+
+// Number converts the node to a json.Number, including strings, booleans and null
+func (self *Node) Number() (json.Number, error) {
+	if err := self.checkRaw(); err != nil {
+		return "", err
+	}
+	switch self.t {
+	case V_NUMBER:
+		return json.Number(self.s), nil
+	case V_STRING:
+		if _, err := json.Number(self.s).Float64(); err != nil {
+			return "", err
+		}
+		return json.Number(self.s), nil
+	case V_TRUE:
+		return "1", nil
+	case V_FALSE, V_NULL:
+		return "0", nil
+	case V_ANY:
+		switch v := self.any.(type) {
+		case bool:
+			return castNumber(v), nil
+		case string:
+			if _, err := strconv.ParseFloat(v, 64); err != nil {
+				return "", err
+			}
+			return json.Number(v), nil
+		case json.Number:
+			return v, nil
+		}
+		if f, ok := anyFloat(self.any); ok {
+			return castNumber(f != 0), nil
+		}
+	}
+	return "", ErrUnsupportType
+}
+
+// StrictNumber returns the json.Number of number nodes
+func (self *Node) StrictNumber() (json.Number, error) {
+	if err := self.checkRaw(); err != nil {
+		return "", err
+	}
+	switch self.t {
+	case V_NUMBER:
+		return json.Number(self.s), nil
+	case V_ANY:
+		if v, ok := self.any.(json.Number); ok {
+			return v, nil
+		}
+	}
+	return "", ErrUnsupportType
+}
+
+// String converts the node to a string: numbers as their literal, booleans as "true" or
+// "false" and null as ""
+func (self *Node) String() (string, error) {
+	if err := self.checkRaw(); err != nil {
+		return "", err
+	}
+	switch self.t {
+	case V_NULL:
+		return "", nil
+	case V_TRUE:
+		return "true", nil
+	case V_FALSE:
+		return "false", nil
+	case V_STRING, V_NUMBER:
+		return self.s, nil
+	case V_ANY:
+		switch v := self.any.(type) {
+		case bool:
+			return strconv.FormatBool(v), nil
+		case string:
+			return v, nil
+		case json.Number:
+			return v.String(), nil
+		case float32:
+			return strconv.FormatFloat(float64(v), 'g', -1, 64), nil
+		case float64:
+			return strconv.FormatFloat(v, 'g', -1, 64), nil
+		}
+		if i, ok := anyInt(self.any); ok {
+			return strconv.FormatInt(i, 10), nil
+		}
+	}
+	return "", ErrUnsupportType
+}
+
+// StrictString returns the value of string nodes
+func (self *Node) StrictString() (string, error) {
+	if err := self.checkRaw(); err != nil {
+		return "", err
+	}
+	switch self.t {
+	case V_STRING:
+		return self.s, nil
+	case V_ANY:
+		if v, ok := self.any.(string); ok {
+			return v, nil
+		}
+	}
+	return "", ErrUnsupportType
+}
+
+// Float64 converts the node to a float64, including strings, booleans and null
+func (self *Node) Float64() (float64, error) {
+	if err := self.checkRaw(); err != nil {
+		return 0, err
+	}
+	switch self.t {
+	case V_NUMBER, V_STRING:
+		return json.Number(self.s).Float64()
+	case V_TRUE:
+		return 1, nil
+	case V_FALSE, V_NULL:
+		return 0, nil
+	case V_ANY:
+		switch v := self.any.(type) {
+		case bool:
+			if v {
+				return 1, nil
+			}
+			return 0, nil
+		case string:
+			return strconv.ParseFloat(v, 64)
+		case json.Number:
+			return v.Float64()
+		}
+		if f, ok := anyFloat(self.any); ok {
+			return f, nil
+		}
+	}
+	return 0, ErrUnsupportType
+}
+
+// StrictBool returns the value of boolean nodes
+func (self *Node) StrictBool() (bool, error) {
+	if err := self.checkRaw(); err != nil {
+		return false, err
+	}
+	switch self.t {
+	case V_TRUE:
+		return true, nil
+	case V_FALSE:
+		return false, nil
+	case V_ANY:
+		if v, ok := self.any.(bool); ok {
+			return v, nil
+		}
+	}
+	return false, ErrUnsupportType
+}
+
+// StrictFloat64 returns the float64 of number nodes
+func (self *Node) StrictFloat64() (float64, error) {
+	if err := self.checkRaw(); err != nil {
+		return 0, err
+	}
+	switch self.t {
+	case V_NUMBER:
+		return json.Number(self.s).Float64()
+	case V_ANY:
+		switch v := self.any.(type) {
+		case float32:
+			return float64(v), nil
+		case float64:
+			return v, nil
+		}
+	}
+	return 0, ErrUnsupportType
+}
+
+func castNumber(v bool) json.Number {
+	if v {
+		return "1"
+	}
+	return "0"
+}
+
+// numberInt64 parses a number literal as an int64, truncating fractions
+func numberInt64(n json.Number) (int64, error) {
+	if i, err := n.Int64(); err == nil {
+		return i, nil
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return 0, err
+	}
+	return int64(f), nil
+}
+
+// anyInt returns Go integers as an int64
+func anyInt(v interface{}) (int64, bool) {
+	switch v := v.(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint:
+		return int64(v), true
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		return int64(v), true
+	}
+	return 0, false
+}
+
+// anyFloat returns Go numbers as a float64
+func anyFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	if i, ok := anyInt(v); ok {
+		return float64(i), true
+	}
+	return 0, false
+}
+
+// ============================================================================
+// Children
+// ============================================================================
+
+// Len returns the number of children of an array or object, or the length of a string
+func (self *Node) Len() (int, error) {
+	if err := self.checkRaw(); err != nil {
+		return 0, err
+	}
+	switch self.t {
+	case V_ARRAY:
+		return len(self.items), nil
+	case V_OBJECT:
+		return len(self.pairs), nil
+	case V_STRING:
+		return len(self.s), nil
+	case V_NONE, V_NULL:
+		return 0, nil
+	}
+	return 0, ErrUnsupportType
+}
+
+// Cap returns the capacity of an array or object for children
+func (self *Node) Cap() (int, error) {
+	if err := self.checkRaw(); err != nil {
+		return 0, err
+	}
+	switch self.t {
+	case V_ARRAY:
+		return cap(self.items), nil
+	case V_OBJECT:
+		return cap(self.pairs), nil
+	case V_NONE, V_NULL:
+		return 0, nil
+	}
+	return 0, ErrUnsupportType
+}
+
+// Get returns the value of key in an object, or nil if the object has no such key
+func (self *Node) Get(key string) *Node {
+	if err := self.should(V_OBJECT); err != nil {
+		return unwrapError(err)
+	}
+	node, _ := self.find(key)
+	return node
+}
+
+// find returns the value of key and its index
+func (self *Node) find(key string) (*Node, int) {
+	for i, pair := range self.pairs {
+		if pair.Key == key {
+			return &pair.Value, i
+		}
+	}
+	return nil, -1
+}
+
+// Index returns the child of an array or object at idx
+func (self *Node) Index(idx int) *Node {
+	if err := self.checkRaw(); err != nil {
+		return unwrapError(err)
+	}
+	switch self.t {
+	case V_ARRAY:
+		if idx < 0 || idx >= len(self.items) {
+			return nil
+		}
+		return self.items[idx]
+	case V_OBJECT:
+		if idx < 0 || idx >= len(self.pairs) {
+			return newError("value not exists")
+		}
+		return &self.pairs[idx].Value
+	}
+	return newError(fmt.Sprintf("unsupported type: %v", self.t))
+}
+
+// IndexPair returns the member of an object at idx
+func (self *Node) IndexPair(idx int) *Pair {
+	if err := self.should(V_OBJECT); err != nil || idx < 0 || idx >= len(self.pairs) {
+		return nil
+	}
+	return self.pairs[idx]
+}
+
+// IndexOrGet returns the value at idx if its key is key, and otherwise looks key up
+func (self *Node) IndexOrGet(idx int, key string) *Node {
+	node, _ := self.IndexOrGetWithIdx(idx, key)
+	return node
+}
+
+// IndexOrGetWithIdx is IndexOrGet, also returning the value's index
+func (self *Node) IndexOrGetWithIdx(idx int, key string) (*Node, int) {
+	if err := self.should(V_OBJECT); err != nil {
+		return unwrapError(err), idx
+	}
+	if pair := self.IndexPair(idx); pair != nil && pair.Key == key {
+		return &pair.Value, idx
+	}
+	return self.find(key)
+}
+
+// GetByPath follows string keys and int indexes down from the node
+func (self *Node) GetByPath(path ...interface{}) *Node {
+	if !self.Valid() {
+		return self
+	}
+	node := self
+	for _, p := range path {
+		switch p := p.(type) {
+		case int:
+			node = node.Index(p)
+		case string:
+			node = node.Get(p)
+		default:
+			panic("path must be either int or string")
+		}
+		if !node.Valid() {
+			return node
+		}
+	}
+	return node
+}
+
+// Set sets the value of key in an object and reports whether the key existed.
+// A none or null node becomes an object.
+func (self *Node) Set(key string, node Node) (bool, error) {
+	if err := self.checkRaw(); err != nil {
+		return false, err
+	}
+	if err := node.Check(); err != nil {
+		return false, err
+	}
+	if self.t == V_NONE || self.t == V_NULL {
+		*self = NewObject([]Pair{NewPair(key, node)})
+		return false, nil
+	}
+	if self.t != V_OBJECT {
+		return false, ErrUnsupportType
+	}
+	if existing, _ := self.find(key); existing != nil {
+		*existing = node
+		return true, nil
+	}
+	self.pairs = append(self.pairs, &Pair{Key: key, Value: node})
+	return false, nil
+}
+
+// SetAny sets the value of key to a Go value
+func (self *Node) SetAny(key string, val interface{}) (bool, error) {
+	return self.Set(key, NewAny(val))
+}
+
+// Unset removes key from an object and reports whether it existed
+func (self *Node) Unset(key string) (bool, error) {
+	if err := self.should(V_OBJECT); err != nil {
+		return false, err
+	}
+	_, i := self.find(key)
+	if i < 0 {
+		return false, nil
+	}
+	self.pairs = append(self.pairs[:i:i], self.pairs[i+1:]...)
+	return true, nil
+}
+
+// SetByIndex replaces the child at index and reports whether it existed.
+// A none or null node becomes an array when index is 0.
+func (self *Node) SetByIndex(index int, node Node) (bool, error) {
+	if err := self.checkRaw(); err != nil {
+		return false, err
+	}
+	if err := node.Check(); err != nil {
+		return false, err
+	}
+	if index == 0 && (self.t == V_NONE || self.t == V_NULL) {
+		*self = NewArray([]Node{node})
+		return false, nil
+	}
+	existing := self.Index(index)
+	if !existing.Exists() {
+		return false, ErrNotExist
+	}
+	*existing = node
+	return true, nil
+}
+
+// SetAnyByIndex replaces the child at index with a Go value
+func (self *Node) SetAnyByIndex(index int, val interface{}) (bool, error) {
+	return self.SetByIndex(index, NewAny(val))
+}
+
+// UnsetByIndex removes the child of an array or object at index
+func (self *Node) UnsetByIndex(index int) (bool, error) {
+	if err := self.checkRaw(); err != nil {
+		return false, err
+	}
+	switch self.t {
+	case V_ARRAY:
+		if index < 0 || index >= len(self.items) {
+			return false, ErrNotExist
+		}
+		self.items = append(self.items[:index:index], self.items[index+1:]...)
+	case V_OBJECT:
+		if index < 0 || index >= len(self.pairs) {
+			return false, ErrNotExist
+		}
+		self.pairs = append(self.pairs[:index:index], self.pairs[index+1:]...)
+	default:
+		return false, ErrUnsupportType
+	}
+	return true, nil
+}
+
+// Add appends a child to an array. A none or null node becomes an array.
+func (self *Node) Add(node Node) error {
+	if err := self.checkRaw(); err != nil {
+		return err
+	}
+	if self.t == V_NONE || self.t == V_NULL {
+		*self = NewArray([]Node{node})
+		return nil
+	}
+	if self.t != V_ARRAY {
+		return ErrUnsupportType
+	}
+	self.items = append(self.items, &node)
+	return nil
+}
+
+// AddAny appends a Go value to an array
+func (self *Node) AddAny(val interface{}) error {
+	return self.Add(NewAny(val))
+}
+
+// Pop removes the last child of an array or object
+func (self *Node) Pop() error {
+	if err := self.checkRaw(); err != nil {
+		return err
+	}
+	switch self.t {
+	case V_ARRAY:
+		if len(self.items) > 0 {
+			self.items = self.items[:len(self.items)-1]
+		}
+	case V_OBJECT:
+		if len(self.pairs) > 0 {
+			self.pairs = self.pairs[:len(self.pairs)-1]
+		}
+	default:
+		return ErrUnsupportType
+	}
+	return nil
+}
+
+// Move moves the child of an array at src to dst, sliding the children in between
+func (self *Node) Move(dst, src int) error {
+	if err := self.should(V_ARRAY); err != nil {
+		return err
+	}
+	if src < 0 || src >= len(self.items) || dst < 0 || dst >= len(self.items) {
+		return ErrNotExist
+	}
+	moved := self.items[src]
+	items := append(append([]*Node(nil), self.items[:src]...), self.items[src+1:]...)
+	self.items = append(items[:dst], append([]*Node{moved}, items[dst:]...)...)
+	return nil
+}
+
+// SortKeys sorts the members of objects by key, recursing into children if recurse is set
+func (self *Node) SortKeys(recurse bool) error {
+	if err := self.checkRaw(); err != nil {
+		return err
+	}
+	switch self.t {
+	case V_OBJECT:
+		sort.SliceStable(self.pairs, func(i, j int) bool { return self.pairs[i].Key < self.pairs[j].Key })
+		if recurse {
+			for _, pair := range self.pairs {
+				if err := pair.Value.SortKeys(true); err != nil {
+					return err
+				}
+			}
+		}
+	case V_ARRAY:
+		for _, item := range self.items {
+			if err := item.SortKeys(recurse); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ============================================================================
+// Loading and generic values
+// ============================================================================
+
+// Load parses the node if it's raw
+func (self *Node) Load() error {
+	switch self.TypeSafe() {
+	case V_ERROR:
+		return self
+	case V_NONE:
+		return nil
+	}
+	return self.checkRaw()
+}
+
+// LoadAll parses the node if it's raw
+func (self *Node) LoadAll() error {
+	return self.Load()
+}
+
+// Map returns an object as a map, with numbers as float64
+func (self *Node) Map() (map[string]interface{}, error) {
+	return self.toMap(false)
+}
+
+// MapUseNumber returns an object as a map, with numbers as json.Number
+func (self *Node) MapUseNumber() (map[string]interface{}, error) {
+	return self.toMap(true)
+}
+
+func (self *Node) toMap(useNumber bool) (map[string]interface{}, error) {
+	if self.Valid() && self.t == V_ANY {
+		if v, ok := self.any.(map[string]interface{}); ok {
+			return v, nil
+		}
+		return nil, ErrUnsupportType
+	}
+	if err := self.should(V_OBJECT); err != nil {
+		return nil, err
+	}
+	m := make(map[string]interface{}, len(self.pairs))
+	for _, pair := range self.pairs {
+		value, err := pair.Value.toInterface(useNumber)
+		if err != nil {
+			return nil, err
+		}
+		m[pair.Key] = value
+	}
+	return m, nil
+}
+
+// MapUseNode returns the members of an object by key
+func (self *Node) MapUseNode() (map[string]Node, error) {
+	if self.Valid() && self.t == V_ANY {
+		if v, ok := self.any.(map[string]Node); ok {
+			return v, nil
+		}
+		return nil, ErrUnsupportType
+	}
+	if err := self.should(V_OBJECT); err != nil {
+		return nil, err
+	}
+	m := make(map[string]Node, len(self.pairs))
+	for _, pair := range self.pairs {
+		m[pair.Key] = pair.Value
+	}
+	return m, nil
+}
+
+// Array returns an array as a slice, with numbers as float64
+func (self *Node) Array() ([]interface{}, error) {
+	return self.toSlice(false)
+}
+
+// ArrayUseNumber returns an array as a slice, with numbers as json.Number
+func (self *Node) ArrayUseNumber() ([]interface{}, error) {
+	return self.toSlice(true)
+}
+
+func (self *Node) toSlice(useNumber bool) ([]interface{}, error) {
+	if self.Valid() && self.t == V_ANY {
+		if v, ok := self.any.([]interface{}); ok {
+			return v, nil
+		}
+		return nil, ErrUnsupportType
+	}
+	if err := self.should(V_ARRAY); err != nil {
+		return nil, err
+	}
+	s := make([]interface{}, len(self.items))
+	for i, item := range self.items {
+		value, err := item.toInterface(useNumber)
+		if err != nil {
+			return nil, err
+		}
+		s[i] = value
+	}
+	return s, nil
+}
+
+// ArrayUseNode returns the children of an array
+func (self *Node) ArrayUseNode() ([]Node, error) {
+	if self.Valid() && self.t == V_ANY {
+		if v, ok := self.any.([]Node); ok {
+			return v, nil
+		}
+		return nil, ErrUnsupportType
+	}
+	if err := self.should(V_ARRAY); err != nil {
+		return nil, err
+	}
+	s := make([]Node, len(self.items))
+	for i, item := range self.items {
+		s[i] = *item
+	}
+	return s, nil
+}
+
+// Interface returns the node as a Go value, with numbers as float64
+func (self *Node) Interface() (interface{}, error) {
+	return self.toInterface(false)
+}
+
+// InterfaceUseNumber returns the node as a Go value, with numbers as json.Number
+func (self *Node) InterfaceUseNumber() (interface{}, error) {
+	return self.toInterface(true)
+}
+
+func (self *Node) toInterface(useNumber bool) (interface{}, error) {
+	if err := self.checkRaw(); err != nil {
+		return nil, err
+	}
+	switch self.t {
+	case V_NULL:
+		return nil, nil
+	case V_TRUE:
+		return true, nil
+	case V_FALSE:
+		return false, nil
+	case V_ARRAY:
+		return self.toSlice(useNumber)
+	case V_OBJECT:
+		return self.toMap(useNumber)
+	case V_STRING:
+		return self.s, nil
+	case V_NUMBER:
+		if useNumber {
+			return json.Number(self.s), nil
+		}
+		return json.Number(self.s).Float64()
+	case V_ANY:
+		if useNumber {
+			return self.any, nil
+		}
+		switch v := self.any.(type) {
+		case Node:
+			return v.Interface()
+		case *Node:
+			return v.Interface()
+		}
+		return self.any, nil
+	}
+	return nil, ErrUnsupportType
+}
+
+// InterfaceUseNode returns the children of an array or object, or the node itself
+func (self *Node) InterfaceUseNode() (interface{}, error) {
+	if err := self.checkRaw(); err != nil {
+		return nil, err
+	}
+	switch self.t {
+	case V_ARRAY:
+		return self.ArrayUseNode()
+	case V_OBJECT:
+		return self.MapUseNode()
+	}
+	return *self, self.Check()
+}
+
+// ============================================================================
+// Iteration
+// ============================================================================
+
+// Iterator is the position of a ListIterator or ObjectIterator
+type Iterator struct {
+	i int
+	p *Node
+}
+
+// Pos returns the index of the next child
+func (self *Iterator) Pos() int {
+	return self.i
+}
+
+// Len returns the number of children
+func (self *Iterator) Len() int {
+	switch self.p.t {
+	case V_ARRAY:
+		return len(self.p.items)
+	case V_OBJECT:
+		return len(self.p.pairs)
+	}
+	return 0
+}
+
+// HasNext reports whether children are left
+func (self *Iterator) HasNext() bool {
+	return self.p.Valid() && self.i < self.Len()
+}
+
+// ListIterator iterates over the children of an array node
+type ListIterator struct {
+	Iterator
+}
+
+// ObjectIterator iterates over the pairs of an object node
+type ObjectIterator struct {
+	Iterator
+}
+
+// Values returns an iterator over the children of an array
+func (self *Node) Values() (ListIterator, error) {
+	if err := self.should(V_ARRAY); err != nil {
+		return ListIterator{}, err
+	}
+	return ListIterator{Iterator{p: self}}, nil
+}
+
+// Properties returns an iterator over the members of an object
+func (self *Node) Properties() (ObjectIterator, error) {
+	if err := self.should(V_OBJECT); err != nil {
+		return ObjectIterator{}, err
+	}
+	return ObjectIterator{Iterator{p: self}}, nil
+}
+
+// Next copies the next child to v and reports whether there was one
+func (self *ListIterator) Next(v *Node) bool {
+	if !self.HasNext() {
+		return false
+	}
+	*v = *self.p.items[self.i]
+	self.i++
+	return true
+}
+
+// Next copies the next member to p and reports whether there was one
+func (self *ObjectIterator) Next(p *Pair) bool {
+	if !self.HasNext() {
+		return false
+	}
+	*p = *self.p.pairs[self.i]
+	self.i++
+	return true
+}
+
+// ForEach calls sc for each child of an array or object, in order, until it returns false.
+// Other nodes are passed to sc themselves, with an Index of -1.
+func (self *Node) ForEach(sc Scanner) error {
+	if err := self.checkRaw(); err != nil {
+		return err
+	}
+	switch self.t {
+	case V_ARRAY:
+		for i := 0; i < len(self.items); i++ {
+			if !sc(Sequence{Index: i}, self.items[i]) {
+				return nil
+			}
+		}
+	case V_OBJECT:
+		for i := 0; i < len(self.pairs); i++ {
+			pair := self.pairs[i]
+			if !sc(Sequence{Index: i, Key: &pair.Key}, &pair.Value) {
+				return nil
+			}
+		}
+	default:
+		sc(Sequence{Index: -1}, self)
+	}
+	return nil
+}
+
+// ============================================================================
+// Encoding
+// ============================================================================
+
+// MarshalJSON encodes the node, leaving raw nodes as they were written
+func (self *Node) MarshalJSON() ([]byte, error) {
+	if self == nil {
+		return []byte("null"), nil
+	}
+	var buf bytes.Buffer
+	if err := self.encode(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (self *Node) encode(buf *bytes.Buffer) error {
+	if self.raw {
+		buf.WriteString(self.s)
+		return nil
+	}
+	switch self.t {
+	case V_NONE:
+		return ErrNotExist
+	case V_ERROR:
+		return self.Check()
+	case V_NULL:
+		buf.WriteString("null")
+	case V_TRUE:
+		buf.WriteString("true")
+	case V_FALSE:
+		buf.WriteString("false")
+	case V_NUMBER:
+		buf.WriteString(self.s)
+	case V_STRING:
+		return encodeValue(buf, self.s)
+	case V_ANY:
+		return encodeValue(buf, self.any)
+	case V_ARRAY:
+		buf.WriteByte('[')
+		started := false
+		for _, item := range self.items {
+			if !item.Exists() {
+				continue
+			}
+			if started {
+				buf.WriteByte(',')
+			}
+			started = true
+			if err := item.encode(buf); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case V_OBJECT:
+		buf.WriteByte('{')
+		started := false
+		for _, pair := range self.pairs {
+			if !pair.Value.Exists() {
+				continue
+			}
+			if started {
+				buf.WriteByte(',')
+			}
+			started = true
+			if err := encodeValue(buf, pair.Key); err != nil {
+				return err
+			}
+			buf.WriteByte(':')
+			if err := pair.Value.encode(buf); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return ErrUnsupportType
+	}
+	return nil
+}
+
+// encodeValue writes a Go value as JSON without escaping HTML, as Sonic does
+func encodeValue(buf *bytes.Buffer, v interface{}) error {
+	var encoded bytes.Buffer
+	encoder := json.NewEncoder(&encoded)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	buf.Write(bytes.TrimSuffix(encoded.Bytes(), []byte("\n")))
+	return nil
+}
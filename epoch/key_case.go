@@ -0,0 +1,243 @@
+package epoch
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/astronomer/epoch/epoch/jsonast"
+)
+
+// KeyCase represents a conversion between naming conventions for JSON keys
+type KeyCase int
+
+const (
+	// SnakeToCamel converts snake_case keys to camelCase, e.g. user_id to userId
+	SnakeToCamel KeyCase = iota
+	// CamelToSnake converts camelCase keys to snake_case, e.g. userId to user_id
+	CamelToSnake
+)
+
+// String returns the string representation of the conversion
+func (kc KeyCase) String() string {
+	switch kc {
+	case SnakeToCamel:
+		return "snake_case to camelCase"
+	case CamelToSnake:
+		return "camelCase to snake_case"
+	default:
+		return "unknown"
+	}
+}
+
+// Inverse returns the opposite conversion
+func (kc KeyCase) Inverse() KeyCase {
+	if kc == SnakeToCamel {
+		return CamelToSnake
+	}
+	return SnakeToCamel
+}
+
+// Convert returns name in the target convention
+func (kc KeyCase) Convert(name string) string {
+	switch kc {
+	case SnakeToCamel:
+		return snakeToCamel(name)
+	case CamelToSnake:
+		return camelToSnake(name)
+	default:
+		return name
+	}
+}
+
+// snakeToCamel drops underscores between words and capitalizes the following letter; leading underscores are kept
+func snakeToCamel(name string) string {
+	if !strings.Contains(name, "_") {
+		return name
+	}
+	var b strings.Builder
+	b.Grow(len(name))
+	upper, inWord := false, false
+	for i, r := range name {
+		if r == '_' && inWord && i < len(name)-1 {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		inWord = inWord || r != '_'
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// camelToSnake inserts underscores at word boundaries and lowercases, treating acronyms as one word (userID to user_id)
+func camelToSnake(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	b.Grow(len(name) + 4)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && runes[i-1] != '_' {
+				prev := runes[i-1]
+				nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+				if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+					b.WriteByte('_')
+				}
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// convertNodeKeys converts every key of objects in node, recursively through objects and arrays
+// Excepted keys keep their name and their value is left untouched, e.g. for free-form metadata.
+// Objects are only rebuilt when one of their keys changes, and it reports whether any did.
+func convertNodeKeys(node *jsonast.Node, keyCase KeyCase, except map[string]bool) (bool, error) {
+	if node == nil || !node.Exists() {
+		return false, nil
+	}
+
+	switch node.TypeSafe() {
+	case jsonast.V_ARRAY:
+		length, err := node.Len()
+		if err != nil {
+			return false, fmt.Errorf("failed to get array length: %w", err)
+		}
+		changed := false
+		for i := 0; i < length; i++ {
+			itemChanged, err := convertNodeKeys(node.Index(i), keyCase, except)
+			if err != nil {
+				return false, err
+			}
+			changed = changed || itemChanged
+		}
+		return changed, nil
+
+	case jsonast.V_OBJECT:
+		if err := node.LoadAll(); err != nil {
+			return false, fmt.Errorf("failed to load object: %w", err)
+		}
+		iter, err := node.Properties()
+		if err != nil {
+			return false, err
+		}
+		var pairs []jsonast.Pair
+		existing := make(map[string]bool)
+		var pair jsonast.Pair
+		for iter.Next(&pair) {
+			pairs = append(pairs, pair)
+			existing[pair.Key] = true
+		}
+
+		changed := false
+		for i := range pairs {
+			if except[pairs[i].Key] {
+				continue
+			}
+			valueChanged, err := convertNodeKeys(&pairs[i].Value, keyCase, except)
+			if err != nil {
+				return false, err
+			}
+			name := keyCase.Convert(pairs[i].Key)
+			if name != pairs[i].Key && !existing[name] {
+				existing[name] = true
+				pairs[i].Key = name
+				valueChanged = true
+			}
+			changed = changed || valueChanged
+		}
+		if changed {
+			*node = jsonast.NewObject(pairs)
+		}
+		return changed, nil
+	}
+	return false, nil
+}
+
+// convertKeys converts the keys of a body, discarding whether any changed
+func convertKeys(node *jsonast.Node, keyCase KeyCase, except []string) error {
+	_, err := convertNodeKeys(node, keyCase, exceptSet(except))
+	return err
+}
+
+// exceptSet returns the excepted names as a set
+func exceptSet(except []string) map[string]bool {
+	set := make(map[string]bool, len(except))
+	for _, name := range except {
+		set[name] = true
+	}
+	return set
+}
+
+// ============================================================================
+// Request Operations - TO NEXT VERSION (Client→HEAD)
+// ============================================================================
+
+// RequestConvertKeysCase converts every key of the request body, at any depth, when request migrates from client to HEAD
+// Use case: older clients send snake_case keys, HEAD expects camelCase
+type RequestConvertKeysCase struct {
+	Case   KeyCase  // Conversion from older/client to HEAD keys
+	Except []string // Field names left unchanged, along with their values
+}
+
+func (op *RequestConvertKeysCase) ApplyToRequest(node *jsonast.Node) error {
+	return convertKeys(node, op.Case, op.Except)
+}
+
+func (op *RequestConvertKeysCase) GetFieldMapping() map[string]string {
+	// Converted names aren't known up front, so error messages keep HEAD's names
+	return nil
+}
+
+// Inverse returns the opposite operation for schema generation
+// If request converts snake_case to camelCase, the client schema uses snake_case
+func (op *RequestConvertKeysCase) Inverse() RequestToNextVersionOperation {
+	return &RequestConvertKeysCase{Case: op.Case.Inverse(), Except: op.Except}
+}
+
+// ============================================================================
+// Response Operations - TO PREVIOUS VERSION (HEAD→Client)
+// ============================================================================
+
+// ResponseConvertKeysCase converts every key of the response body, at any depth, when response migrates from HEAD to client
+// Use case: HEAD returns camelCase keys, older clients expect snake_case
+type ResponseConvertKeysCase struct {
+	Case   KeyCase  // Conversion from HEAD to older/client keys
+	Except []string // Field names left unchanged, along with their values
+}
+
+func (op *ResponseConvertKeysCase) ApplyToResponse(node *jsonast.Node) error {
+	return convertKeys(node, op.Case, op.Except)
+}
+
+func (op *ResponseConvertKeysCase) GetFieldMapping() map[string]string {
+	// Converted names aren't known up front, so error messages keep HEAD's names
+	return nil
+}
+
+// ConvertKeysCase converts every key of the request body, at any depth, when request migrates from client to HEAD
+// Fields named in except keep their name and value, e.g. free-form metadata maps.
+func (b *requestToNextVersionBuilder) ConvertKeysCase(keyCase KeyCase, except ...string) *requestToNextVersionBuilder {
+	b.parent.requestToNextVersionOps = append(b.parent.requestToNextVersionOps,
+		&RequestConvertKeysCase{
+			Case:   keyCase,
+			Except: except,
+		})
+	return b
+}
+
+// ConvertKeysCase converts every key of the response body, at any depth, when response migrates from HEAD to client
+// Fields named in except keep their name and value, e.g. free-form metadata maps.
+func (b *responseToPreviousVersionBuilder) ConvertKeysCase(keyCase KeyCase, except ...string) *responseToPreviousVersionBuilder {
+	b.parent.responseToPreviousVersionOps = append(b.parent.responseToPreviousVersionOps,
+		&ResponseConvertKeysCase{
+			Case:   keyCase,
+			Except: except,
+		})
+	return b
+}
@@ -0,0 +1,107 @@
+package epoch
+
+import (
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type caseOrder struct {
+	OrderID  int               `json:"orderId"`
+	Lines    []caseOrderLine   `json:"lines"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+type caseOrderLine struct {
+	ProductID int `json:"productId"`
+}
+
+var _ = Describe("Converting key case", func() {
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+	})
+
+	DescribeTable("should convert names between conventions",
+		func(keyCase KeyCase, name, expected string) {
+			Expect(keyCase.Convert(name)).To(Equal(expected))
+		},
+		Entry("snake to camel", SnakeToCamel, "user_id", "userId"),
+		Entry("several words", SnakeToCamel, "created_at_utc", "createdAtUtc"),
+		Entry("leading underscore", SnakeToCamel, "_internal_id", "_internalId"),
+		Entry("no underscore", SnakeToCamel, "name", "name"),
+		Entry("camel to snake", CamelToSnake, "userId", "user_id"),
+		Entry("acronym at the end", CamelToSnake, "userID", "user_id"),
+		Entry("acronym before a word", CamelToSnake, "HTTPServer", "http_server"),
+		Entry("digits", CamelToSnake, "address2Line", "address2_line"),
+		Entry("already snake", CamelToSnake, "user_id", "user_id"),
+	)
+
+	It("should convert keys recursively and keep their order", func() {
+		node, err := getNode([]byte(`{"orderId":1,"lines":[{"productId":2,"unitPrice":3}],"billing":{"postCode":"N1"}}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		op := &ResponseConvertKeysCase{Case: CamelToSnake}
+		Expect(op.ApplyToResponse(&node)).To(Succeed())
+
+		raw, _ := node.Raw()
+		Expect(raw).To(Equal(`{"order_id":1,"lines":[{"product_id":2,"unit_price":3}],"billing":{"post_code":"N1"}}`))
+	})
+
+	It("should leave excepted fields and their values untouched", func() {
+		node, err := getNode([]byte(`{"order_id":1,"metadata":{"source_app":"web"},"user_id":2,"userId":3}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		op := &RequestConvertKeysCase{Case: SnakeToCamel, Except: []string{"metadata"}}
+		Expect(op.ApplyToRequest(&node)).To(Succeed())
+
+		raw, _ := node.Raw()
+		Expect(raw).To(Equal(`{"orderId":1,"metadata":{"source_app":"web"},"user_id":2,"userId":3}`))
+	})
+
+	It("should invert request conversions for schema generation", func() {
+		op := &RequestConvertKeysCase{Case: SnakeToCamel, Except: []string{"metadata"}}
+		inverse, ok := op.Inverse().(*RequestConvertKeysCase)
+		Expect(ok).To(BeTrue())
+		Expect(inverse.Case).To(Equal(CamelToSnake))
+		Expect(inverse.Except).To(Equal([]string{"metadata"}))
+		Expect(DescribeOperation(op).Description).To(Equal("Convert keys from snake_case to camelCase"))
+	})
+
+	It("should convert request and response bodies through the middleware", func() {
+		v1, _ := NewDateVersion("2024-01-01")
+		v2, _ := NewDateVersion("2024-06-01")
+		change := NewVersionChangeBuilder(v1, v2).
+			ForType(caseOrder{}).
+			RequestToNextVersion().
+			ConvertKeysCase(SnakeToCamel, "metadata").
+			ResponseToPreviousVersion().
+			ConvertKeysCase(CamelToSnake, "metadata").
+			Build()
+		epochInstance, err := NewEpoch().WithVersions(v1, v2).WithHeadVersion().WithChanges(change).Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		var received caseOrder
+		router := gin.New()
+		router.Use(epochInstance.Middleware())
+		router.POST("/orders", epochInstance.WrapHandler(func(c *gin.Context) {
+			Expect(c.ShouldBindJSON(&received)).To(Succeed())
+			c.JSON(200, received)
+		}).Accepts(caseOrder{}).Returns(caseOrder{}).ToHandlerFunc("POST", "/orders"))
+
+		req := httptest.NewRequest("POST", "/orders", strings.NewReader(
+			`{"order_id":1,"lines":[{"product_id":2}],"metadata":{"source_app":"web"}}`))
+		req.Header.Set("X-API-Version", "2024-01-01")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		Expect(recorder.Code).To(Equal(200))
+		Expect(received.OrderID).To(Equal(1))
+		Expect(received.Lines[0].ProductID).To(Equal(2))
+		Expect(received.Metadata).To(HaveKeyWithValue("source_app", "web"))
+		Expect(recorder.Body.String()).To(MatchJSON(
+			`{"order_id":1,"lines":[{"product_id":2}],"metadata":{"source_app":"web"}}`))
+	})
+})
@@ -0,0 +1,100 @@
+package epoch
+
+import (
+	"fmt"
+
+	"github.com/astronomer/epoch/epoch/jsonast"
+)
+
+// ============================================================================
+// HAL Link Operations - TO PREVIOUS VERSION (HEAD→Client)
+// ============================================================================
+// Link operations work on a HAL-style links object, keyed by relation name:
+//
+//	{"_links": {"self": {"href": "/users/1"}, "items": [{"href": "/users/1/items/1"}]}}
+//
+// A relation holds a single link object or an array of them.
+
+// LinksField is the field holding a resource's links
+const LinksField = "_links"
+
+// AllLinkRels makes RewriteLinkHref apply to every relation
+const AllLinkRels = "*"
+
+// ResponseRenameLinkRel renames a link relation when response migrates from HEAD to client
+// Use case: HEAD renamed the "owner" relation to "author", rename it back for old clients
+type ResponseRenameLinkRel struct {
+	NewerVersionRel string // Relation name in newer/HEAD version
+	OlderVersionRel string // Relation name in older/client version
+}
+
+func (op *ResponseRenameLinkRel) ApplyToResponse(node *jsonast.Node) error {
+	links := GetNodeField(node, LinksField)
+	if !IsNodeObject(links) {
+		return nil
+	}
+	if err := RenameNodeField(links, op.NewerVersionRel, op.OlderVersionRel); err != nil {
+		return fmt.Errorf("failed to rename link relation %s to %s: %w", op.NewerVersionRel, op.OlderVersionRel, err)
+	}
+	return nil
+}
+
+func (op *ResponseRenameLinkRel) GetFieldMapping() map[string]string {
+	return nil
+}
+
+// ResponseRewriteLinkHref rewrites the href of a relation's links when response migrates from HEAD to client
+// Use case: HEAD's "self" links point at /v2/accounts/{id}, old clients expect /users/{id}
+type ResponseRewriteLinkHref struct {
+	Rel     string // Relation name, or AllLinkRels
+	Rewrite func(href string) string
+}
+
+func (op *ResponseRewriteLinkHref) ApplyToResponse(node *jsonast.Node) error {
+	links := GetNodeField(node, LinksField)
+	if !IsNodeObject(links) || op.Rewrite == nil {
+		return nil
+	}
+	if op.Rel != AllLinkRels {
+		return op.rewriteRel(links, op.Rel)
+	}
+	return MapNodeObject(links, "", func(rel string, _ *jsonast.Node) error {
+		return op.rewriteRel(links, rel)
+	})
+}
+
+// rewriteRel rewrites the href of a relation's link object, or of each link in an array
+func (op *ResponseRewriteLinkHref) rewriteRel(links *jsonast.Node, rel string) error {
+	link := GetNodeField(links, rel)
+	if IsNodeArray(link) {
+		return ForEachNodeArrayItem(link, "", func(_ int, item *jsonast.Node) error {
+			return op.rewriteLink(item, rel)
+		})
+	}
+	return op.rewriteLink(link, rel)
+}
+
+// rewriteLink rewrites the href of a single link object
+func (op *ResponseRewriteLinkHref) rewriteLink(link *jsonast.Node, rel string) error {
+	if !IsNodeObject(link) {
+		return nil
+	}
+	href := GetNodeField(link, "href")
+	if href == nil || href.TypeSafe() != jsonast.V_STRING {
+		return nil
+	}
+	value, err := href.String()
+	if err != nil {
+		return fmt.Errorf("failed to read href of link relation %s: %w", rel, err)
+	}
+	if rewritten := op.Rewrite(value); rewritten != value {
+		if err := SetNodeField(link, "href", rewritten); err != nil {
+			return fmt.Errorf("failed to rewrite href of link relation %s: %w", rel, err)
+		}
+	}
+	return nil
+}
+
+func (op *ResponseRewriteLinkHref) GetFieldMapping() map[string]string {
+	return nil
+}
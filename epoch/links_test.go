@@ -0,0 +1,93 @@
+package epoch
+
+import (
+	"net/http/httptest"
+	"strings"
+
+	"github.com/astronomer/epoch/epoch/jsonast"
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type linkHref struct {
+	Href string `json:"href"`
+}
+
+type linkedUser struct {
+	ID    int                 `json:"id"`
+	Links map[string]linkHref `json:"_links"`
+}
+
+var _ = Describe("HAL link operations", func() {
+	apply := func(fn func(node *jsonast.Node) error, body string) string {
+		node, err := DefaultJSONEngine().Parse([]byte(body))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fn(node)).To(Succeed())
+		out, err := node.MarshalJSON()
+		Expect(err).NotTo(HaveOccurred())
+		return string(out)
+	}
+
+	toUsers := func(href string) string {
+		return strings.Replace(href, "/accounts/", "/users/", 1)
+	}
+
+	Describe("RenameLinkRel", func() {
+		op := &ResponseRenameLinkRel{NewerVersionRel: "author", OlderVersionRel: "owner"}
+
+		It("should rename the relation", func() {
+			Expect(apply(op.ApplyToResponse, `{"id":1,"_links":{"self":{"href":"/a"},"author":{"href":"/b"}}}`)).
+				To(Equal(`{"id":1,"_links":{"self":{"href":"/a"},"owner":{"href":"/b"}}}`))
+		})
+
+		It("should leave bodies without the relation or links alone", func() {
+			for _, body := range []string{`{"id":1}`, `{"_links":null}`, `{"_links":{"self":{"href":"/a"}}}`} {
+				Expect(apply(op.ApplyToResponse, body)).To(Equal(body))
+			}
+		})
+	})
+
+	Describe("RewriteLinkHref", func() {
+		It("should rewrite a single link and arrays of links", func() {
+			self := &ResponseRewriteLinkHref{Rel: "self", Rewrite: toUsers}
+			items := &ResponseRewriteLinkHref{Rel: "items", Rewrite: toUsers}
+			body := `{"_links":{"self":{"href":"/accounts/1"},"items":[{"href":"/accounts/2"},{"name":"x"}],"next":{"href":"/accounts/3"}}}`
+
+			Expect(apply(items.ApplyToResponse, apply(self.ApplyToResponse, body))).
+				To(Equal(`{"_links":{"self":{"href":"/users/1"},"items":[{"href":"/users/2"},{"name":"x"}],"next":{"href":"/accounts/3"}}}`))
+		})
+
+		It("should rewrite every relation with AllLinkRels", func() {
+			op := &ResponseRewriteLinkHref{Rel: AllLinkRels, Rewrite: toUsers}
+			Expect(apply(op.ApplyToResponse, `{"_links":{"self":{"href":"/accounts/1"},"next":{"href":"/accounts/2","templated":true}}}`)).
+				To(Equal(`{"_links":{"self":{"href":"/users/1"},"next":{"href":"/users/2","templated":true}}}`))
+		})
+	})
+
+	It("should migrate links end to end", func() {
+		gin.SetMode(gin.TestMode)
+		v1, _ := NewDateVersion("2024-01-01")
+		v2, _ := NewDateVersion("2024-06-01")
+		change := NewVersionChangeBuilder(v1, v2).
+			ForType(linkedUser{}).
+			ResponseToPreviousVersion().
+			RenameLinkRel("author", "owner").
+			RewriteLinkHref(AllLinkRels, toUsers).
+			Build()
+		epochInstance, err := NewEpoch().WithVersions(v1, v2).WithHeadVersion().WithChanges(change).Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		router := gin.New()
+		router.Use(epochInstance.Middleware())
+		router.GET("/users/:id", epochInstance.WrapHandler(func(c *gin.Context) {
+			c.JSON(200, gin.H{"id": 1, "_links": gin.H{"self": gin.H{"href": "/accounts/1"}}})
+		}).Returns(linkedUser{}).ToHandlerFunc("GET", "/users/:id"))
+
+		req := httptest.NewRequest("GET", "/users/1", nil)
+		req.Header.Set("X-API-Version", "2024-01-01")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		Expect(recorder.Body.String()).To(Equal(`{"_links":{"self":{"href":"/users/1"}},"id":1}`))
+	})
+})
@@ -2,15 +2,18 @@ package epoch
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"reflect"
 	"regexp"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
-	"github.com/bytedance/sonic"
-	"github.com/bytedance/sonic/ast"
+	"github.com/astronomer/epoch/epoch/jsonast"
 	"github.com/gin-gonic/gin"
 )
 
@@ -24,10 +27,9 @@ const (
 )
 
 // VersionManager checks all locations for version information
-// Priority: Header > Path
+// Priority: Header > Path, unless sources say otherwise
 type VersionManager struct {
-	headerName       string
-	versionRegex     *regexp.Regexp
+	sources          []VersionSource
 	possibleVersions map[string]bool
 }
 
@@ -35,40 +37,27 @@ type VersionManager struct {
 // Priority: Header > Path
 // Supports partial version matching (e.g., "v1" matches latest v1.x.x)
 func NewVersionManager(headerName string, possibleVersions []string) *VersionManager {
+	return NewVersionManagerWithSources([]VersionSource{Header(headerName), Path()}, possibleVersions)
+}
+
+// NewVersionManagerWithSources creates a version manager that checks sources in order
+func NewVersionManagerWithSources(sources []VersionSource, possibleVersions []string) *VersionManager {
 	versionMap := make(map[string]bool)
 	for _, v := range possibleVersions {
 		versionMap[v] = true
 	}
 
-	// Create regex to match version-like patterns in the path
-	// Matches: /v1/, /v1.0/, /v1.0.0/, /1/, /1.0/, /1.0.0/, /2024-01-01/, /v1.0-beta/, etc.
-	// The pattern captures version strings that start with optional 'v', followed by numbers/dots/hyphens/alphanumeric
-	pattern := `/([vV]?\d+(?:[\.\-]\w+)*)/`
-	regex := regexp.MustCompile(pattern)
-
 	return &VersionManager{
-		headerName:       headerName,
-		versionRegex:     regex,
+		sources:          sources,
 		possibleVersions: versionMap,
 	}
 }
 
-// GetVersion checks all locations for version information
-// Priority: Header > Path
+// GetVersion checks all locations for version information, returning the first version found
 func (vm *VersionManager) GetVersion(c *gin.Context) (string, error) {
-	// First, check header (highest priority)
-	headerVersion := c.GetHeader(vm.headerName)
-	if headerVersion != "" {
-		return headerVersion, nil
-	}
-
-	// Second, check URL path
-	matches := vm.versionRegex.FindStringSubmatch(c.Request.URL.Path)
-	if len(matches) > 1 {
-		potentialVersion := matches[1]
-		// Only return as version if it matches a known version (exact or partial match)
-		if vm.isKnownVersion(potentialVersion) {
-			return potentialVersion, nil
+	for _, source := range vm.sources {
+		if version := source.Version(c, vm.isKnownVersion); version != "" {
+			return version, nil
 		}
 	}
 
@@ -112,6 +101,37 @@ type VersionMiddleware struct {
 	defaultVersion *Version
 	parameterName  string
 	format         VersionFormat
+
+	// retired versions are rejected with an upgrade hint (see Epoch.RetireVersion)
+	retired *retiredVersions
+
+	// unreleased versions resolve only for requests with their preview header
+	unreleased []unreleasedVersion
+	clock      Clock
+
+	// correlationID extracts the ID stored under CorrelationIDKey
+	correlationID CorrelationIDExtractor
+
+	// errorShapers reshape error bodies written outside wrapped handlers, newest version first
+	errorShapers              []errorShaper
+	jsonEngine                JSONEngine
+	infrastructurePassthrough bool
+
+	// internalHead rejects requests for head and defaults the rest to the newest version
+	internalHead bool
+
+	// newerVersions serves versions newer than every registered one (see WithUnknownNewerVersionPolicy)
+	newerVersions NewerVersionPolicy
+
+	// cookie remembers the version a client named (see WithVersionCookie); nil sets no cookie
+	cookie *VersionCookie
+
+	// sunsets are warned about within sunsetWarning's window (see WithSunsetWarning)
+	sunsets       []sunsetVersion
+	sunsetWarning *SunsetWarning
+
+	// versionKey also stores the version for the Epoch instance's own handlers (see Epoch.Group)
+	versionKey string
 }
 
 // MiddlewareConfig holds configuration for version middleware
@@ -121,6 +141,8 @@ type MiddlewareConfig struct {
 	ParameterName  string
 	Format         VersionFormat
 	DefaultVersion *Version
+	Sources        []VersionSource // Defaults to Header(ParameterName), Path()
+	Cookie         *VersionCookie  // Checked after Sources and set once a request names a version
 }
 
 // NewVersionMiddleware creates a new version detection middleware
@@ -133,7 +155,14 @@ func NewVersionMiddleware(config MiddlewareConfig) *VersionMiddleware {
 	}
 
 	// Create version manager that checks all locations
-	versionManager := NewVersionManager(config.ParameterName, versions)
+	sources := config.Sources
+	if len(sources) == 0 {
+		sources = []VersionSource{Header(config.ParameterName), Path()}
+	}
+	if config.Cookie != nil {
+		sources = append(sources[:len(sources):len(sources)], Cookie(config.Cookie.Name))
+	}
+	versionManager := NewVersionManagerWithSources(sources, versions)
 
 	return &VersionMiddleware{
 		versionBundle:  config.VersionBundle,
@@ -142,6 +171,7 @@ func NewVersionMiddleware(config MiddlewareConfig) *VersionMiddleware {
 		defaultVersion: config.DefaultVersion,
 		parameterName:  config.ParameterName,
 		format:         config.Format,
+		cookie:         config.Cookie,
 	}
 }
 
@@ -152,28 +182,42 @@ const defaultVersionContextKey = "epoch_default_version_used"
 // Middleware returns the Gin middleware function
 func (vm *VersionMiddleware) Middleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if vm.correlationID != nil {
+			if id := vm.correlationID(c); id != "" {
+				c.Set(CorrelationIDKey, id)
+			}
+		}
+
 		// Extract version from request
 		versionStr, err := vm.versionManager.GetVersion(c)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid version format: %v", err)})
+			c.JSON(http.StatusBadRequest, withCorrelationID(c, gin.H{"error": fmt.Sprintf("Invalid version format: %v", err)}))
 			c.Abort()
 			return
 		}
 
 		var requestedVersion *Version
-		var defaultUsed bool
+		var defaultUsed, preview bool
 
 		if versionStr == "" {
 			// No version specified, use default
 			requestedVersion = vm.defaultVersion
 			if requestedVersion == nil {
-				requestedVersion = vm.versionBundle.GetHeadVersion()
+				requestedVersion = vm.defaultHeadVersion()
 			}
 			defaultUsed = true
 		} else {
 			// Parse the requested version
 			requestedVersion, err = vm.versionBundle.ParseVersion(versionStr)
 			if err != nil {
+				// Versions newer than every registered one follow WithUnknownNewerVersionPolicy
+				var handled bool
+				requestedVersion, preview, handled = vm.resolveNewerVersion(c, versionStr)
+				if handled && requestedVersion == nil {
+					return
+				}
+			}
+			if err != nil && requestedVersion == nil {
 				// First, try to match as a partial version (e.g., "v1" matches latest v1.x.x)
 				requestedVersion = vm.findLatestMatchingVersion(versionStr)
 
@@ -183,20 +227,28 @@ func (vm *VersionMiddleware) Middleware() gin.HandlerFunc {
 				}
 
 				if requestedVersion == nil {
-					hint := fmt.Sprintf("Specify version using '%s' header or include it in the URL path (e.g., /v1/resource)", vm.parameterName)
-					c.JSON(http.StatusBadRequest, gin.H{
-						"error":              fmt.Sprintf("Unknown version: %s", versionStr),
-						"available_versions": vm.versionBundle.GetVersionValues(),
-						"hint":               hint,
-					})
-					c.Abort()
+					vm.rejectUnknownVersion(c, versionStr)
 					return
 				}
 			}
+			if requestedVersion.IsHead && vm.internalHead {
+				vm.rejectUnknownVersion(c, versionStr)
+				return
+			}
+		}
+
+		if !preview {
+			requestedVersion = vm.releasedVersion(c, requestedVersion)
+		}
+		if vm.rejectRetired(c, requestedVersion) {
+			return
 		}
 
 		// Set version in Gin context
 		c.Set(versionContextKey, requestedVersion)
+		if vm.versionKey != "" {
+			c.Set(vm.versionKey, requestedVersion)
+		}
 		if defaultUsed {
 			c.Set(defaultVersionContextKey, true)
 		}
@@ -209,12 +261,29 @@ func (vm *VersionMiddleware) Middleware() gin.HandlerFunc {
 
 		// Add version to response header
 		c.Header(vm.parameterName, requestedVersion.String())
+		vm.setVersionCookie(c, requestedVersion, defaultUsed)
+		vm.warnSunset(c, requestedVersion)
 
 		// Continue with the request
+		if shapers := vm.errorShapersFor(requestedVersion); len(shapers) > 0 {
+			vm.shapeErrors(c, shapers)
+			return
+		}
 		c.Next()
 	}
 }
 
+// rejectUnknownVersion answers 400 for a version the request can't select
+func (vm *VersionMiddleware) rejectUnknownVersion(c *gin.Context, versionStr string) {
+	hint := "Specify version using " + describeVersionSources(vm.versionManager.sources)
+	c.JSON(http.StatusBadRequest, withCorrelationID(c, gin.H{
+		"error":              fmt.Sprintf("Unknown version: %s", versionStr),
+		"available_versions": vm.visibleVersionValues(c),
+		"hint":               hint,
+	}))
+	c.Abort()
+}
+
 // findLatestMatchingVersion finds the latest version matching a partial version string
 // For example, "v1" or "1" matches the latest v1.x.x version
 func (vm *VersionMiddleware) findLatestMatchingVersion(partialVersionStr string) *Version {
@@ -273,6 +342,10 @@ func (vm *VersionMiddleware) findClosestOlderVersion(requestedVersionStr string)
 	var closestVersion *Version
 
 	for _, v := range vm.versionBundle.GetVersions() {
+		// Versions of other types don't bracket the request: "bogus" isn't newer than any date
+		if v.Type != requestedVersion.Type {
+			continue
+		}
 		// Use proper version comparison instead of string comparison
 		if v.IsOlderThan(requestedVersion) {
 			if closestVersion == nil || v.IsNewerThan(closestVersion) {
@@ -331,6 +404,101 @@ type VersionAwareHandler struct {
 	versionBundle    *VersionBundle
 	migrationChain   *MigrationChain
 	endpointRegistry *EndpointRegistry
+	jsonEngine       JSONEngine
+
+	// bypassCache remembers, per endpoint+version, whether migration would be a no-op
+	bypassCache sync.Map
+
+	// debugHeader writes MigrationsAppliedHeader on migrated responses
+	debugHeader      bool
+	debugHeaderCache sync.Map
+
+	// responseTemplates holds precomputed response patches for templateEndpoint,
+	// recompiled when changes are added to the chain at runtime
+	templateEndpoint  *EndpointDefinition
+	responseTemplates atomic.Pointer[responseTemplateSet]
+
+	// urlRewriter rewrites HEAD resource URLs in headers and urlBodyFields for older versions
+	urlRewriter   URLRewriter
+	urlBodyFields []string
+
+	// migrationTimeout bounds each migration phase (request, response); 0 means unbounded
+	migrationTimeout time.Duration
+
+	// panicFallback continues with the unmigrated body when a transformer panics
+	panicFallback bool
+
+	// strictSchemaMatching fails responses that don't match their registered type
+	strictSchemaMatching bool
+
+	// schemaDiagnostics records SchemaMatch diagnostics, handing them to schemaMatchObserver
+	schemaDiagnostics   bool
+	schemaMatchObserver func(*gin.Context, SchemaMatch)
+
+	// originalBodyLimit is how many bytes of the client's request body to retain; 0 retains none
+	originalBodyLimit int
+
+	// interceptors wrap the response writer outside Epoch's capture, the first outermost
+	interceptors []ResponseInterceptor
+
+	// outputFormats formats migrated responses, keyed by version string
+	outputFormats map[string]OutputFormat
+
+	// strippedVersions are the versions whose responses lose fields their type doesn't declare
+	strippedVersions map[string]bool
+
+	// fieldAllowlists are the fields each type keeps, keyed by version string; nil derives them
+	fieldAllowlists map[string]map[reflect.Type][]string
+
+	// structuredErrors transforms error responses through their errors array instead of messages
+	structuredErrors *StructuredErrors
+
+	// errorMigration selects the error responses to migrate; nil migrates all of them
+	errorMigration *ErrorMigration
+
+	// infrastructurePassthrough leaves 429 and 5xx responses unmigrated
+	infrastructurePassthrough bool
+
+	// breaker disables migrations after repeated failures; nil never does
+	breaker *circuitBreaker
+
+	// newerFields handles request fields newer than the client's version; zero passes them through
+	newerFields NewerFieldPolicy
+
+	// snapshots keeps each change's input body for ResponseInfo.Snapshot
+	snapshots bool
+
+	// canary compares a sample of HEAD responses with their stable rendering; nil never does
+	canary *Canary
+
+	// normalizeResponses reshapes handler output to the registered response type before migrating it
+	normalizeResponses bool
+
+	// stats records the cost of each migrated request; nil records nothing
+	stats *migrationStats
+
+	// responseCache stores migrated responses by the key cacheKey derives; nil caches none
+	responseCache ResponseCache
+	cacheKey      CacheKeyFunc
+
+	// changeCountCache caches, per endpoint+version, how many changes a migrated request applies
+	changeCountCache sync.Map
+
+	// migrationLog writes an entry per request; nil logs nothing
+	migrationLog        *migrationLogger
+	operationCountCache sync.Map
+
+	// fixtureCapture records sampled HEAD responses of allowlisted endpoints; nil records none
+	fixtureCapture *fixtureRecorder
+
+	// versionKey is the context key of the Epoch instance's version; empty reads GetVersionFromContext
+	versionKey string
+}
+
+// responseTemplateSet is the set of templates compiled for one chain generation
+type responseTemplateSet struct {
+	generation uint64
+	byVersion  map[string]*ResponseTemplate
 }
 
 // NewVersionAwareHandler creates a new version-aware handler
@@ -340,13 +508,81 @@ func NewVersionAwareHandler(handler gin.HandlerFunc, versionBundle *VersionBundl
 		versionBundle:    versionBundle,
 		migrationChain:   migrationChain,
 		endpointRegistry: endpointRegistry,
+		jsonEngine:       DefaultJSONEngine(),
+	}
+}
+
+// WithJSONEngine sets the engine used to parse and serialize bodies during migration
+func (vah *VersionAwareHandler) WithJSONEngine(engine JSONEngine) *VersionAwareHandler {
+	if engine != nil {
+		vah.jsonEngine = engine
 	}
+	return vah
+}
+
+// WithMigrationDebugHeader writes MigrationsAppliedHeader listing the changes and operations
+// applied to each migrated request/response
+func (vah *VersionAwareHandler) WithMigrationDebugHeader() *VersionAwareHandler {
+	vah.debugHeader = true
+	return vah
+}
+
+// WithResponseTemplates precomputes response templates for the endpoint for every version
+// whose changes only add or remove constant fields. Other versions use the full migration.
+func (vah *VersionAwareHandler) WithResponseTemplates(endpointDef *EndpointDefinition) *VersionAwareHandler {
+	vah.templateEndpoint = endpointDef
+	vah.compileResponseTemplates()
+	return vah
+}
+
+// compileResponseTemplates compiles templates for every version against the current chain
+func (vah *VersionAwareHandler) compileResponseTemplates() *responseTemplateSet {
+	set := &responseTemplateSet{
+		generation: vah.migrationChain.Generation(),
+		byVersion:  make(map[string]*ResponseTemplate),
+	}
+	headVersion := vah.versionBundle.GetHeadVersion()
+	for _, version := range vah.versionBundle.GetVersions() {
+		if version.IsHead {
+			continue
+		}
+		template, err := CompileResponseTemplate(vah.templateEndpoint, vah.migrationChain, headVersion, version, vah.jsonEngine)
+		if err != nil || template == nil {
+			continue
+		}
+		set.byVersion[version.String()] = template
+	}
+	vah.responseTemplates.Store(set)
+	return set
+}
+
+// responseTemplate returns the template for a version, recompiling if the chain changed
+func (vah *VersionAwareHandler) responseTemplate(version *Version) *ResponseTemplate {
+	if vah.templateEndpoint == nil {
+		return nil
+	}
+	if _, formatted := vah.outputFormats[version.String()]; formatted || vah.stripsFields(version) {
+		// Templates splice bytes, so formatted and stripped versions take the full migration path
+		return nil
+	}
+	set := vah.responseTemplates.Load()
+	if set == nil || set.generation != vah.migrationChain.Generation() {
+		set = vah.compileResponseTemplates()
+	}
+	return set.byVersion[version.String()]
 }
 
 // HandlerFunc returns a Gin handler function with automatic migration
 func (vah *VersionAwareHandler) HandlerFunc() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		requestedVersion := GetVersionFromContext(c)
+		c.Set(wrappedResponseKey, true)
+		vah.retainOriginalBody(c)
+		if len(vah.interceptors) > 0 {
+			finish := applyResponseInterceptors(c, vah.interceptors)
+			defer finish()
+		}
+
+		requestedVersion := vah.requestedVersion(c)
 		if requestedVersion == nil {
 			// No version in context, call handler directly
 			vah.handler(c)
@@ -358,21 +594,37 @@ func (vah *VersionAwareHandler) HandlerFunc() gin.HandlerFunc {
 	}
 }
 
+// versionPrefixRegex matches version-like prefixes at the start of the path
+// Matches: /v1/, /v2.0/, /v1.1/, /1/, /2.0/, /2024-01-01/, etc.
+// Compiled once since it runs on every migrated request.
+var versionPrefixRegex = regexp.MustCompile(`^/([vV]?\d+(?:[\.\-]\w+)*)/`)
+
 // stripVersionPrefix removes version prefix from path for endpoint lookup
 func (vah *VersionAwareHandler) stripVersionPrefix(path string) string {
-	// Regex pattern matches version-like prefixes at the start of the path
-	// Matches: /v1/, /v2.0/, /v1.1/, /1/, /2.0/, /2024-01-01/, etc.
-	pattern := `^/([vV]?\d+(?:[\.\-]\w+)*)/`
-	regex := regexp.MustCompile(pattern)
-
 	// Replace the version prefix with just /
-	return regex.ReplaceAllString(path, "/")
+	return versionPrefixRegex.ReplaceAllString(path, "/")
 }
 
 // handleWithMigration handles request/response migration for version-aware handlers
 func (vah *VersionAwareHandler) handleWithMigration(c *gin.Context, requestedVersion *Version) {
+	logged := vah.startMigrationLog(c, requestedVersion)
+	defer logged.write(c)
+
 	// Skip migration if requesting head version
 	if requestedVersion.IsHead {
+		if vah.sampleCanary() {
+			vah.serveCanary(c)
+			return
+		}
+		if vah.serveCaptured(c) {
+			return
+		}
+		vah.handler(c)
+		return
+	}
+
+	if vah.breaker.isOpen() {
+		c.Header(MigrationWarningHeader, circuitOpenWarning)
 		vah.handler(c)
 		return
 	}
@@ -382,64 +634,283 @@ func (vah *VersionAwareHandler) handleWithMigration(c *gin.Context, requestedVer
 	// Lookup endpoint definition
 	endpointDef, err := vah.endpointRegistry.Lookup(c.Request.Method, lookupPath)
 	if err != nil {
-		c.JSON(500, gin.H{"error": "Endpoint not registered", "details": "This endpoint must be registered with type information via WrapHandler().Returns()/.Accepts()"})
+		c.JSON(500, withCorrelationID(c, gin.H{"error": "Endpoint not registered", "details": "This endpoint must be registered with type information via WrapHandler().Returns()/.Accepts()"}))
 		return
 	}
+	logged.setEndpoint(endpointDef)
+
+	// Endpoints whose types don't exist yet in the requested version (see SinceVersion)
+	if endpointDef.Since != nil && requestedVersion.IsOlderThan(endpointDef.Since) {
+		rejectUnavailable(c, endpointDef, requestedVersion)
+		return
+	}
+
+	// Fast path: nothing in the chain touches this endpoint's types for this version,
+	// so stream the handler's response directly without capturing or parsing bodies.
+	// URL rewriting needs the captured response, so it always takes the full path.
+	if vah.urlRewriter == nil && !vah.needsMigration(endpointDef, requestedVersion) {
+		vah.recordSkippedSchemaMatches(c, endpointDef)
+		vah.handler(c)
+		return
+	}
+
+	if vah.debugHeader {
+		if value := vah.migrationsAppliedHeader(endpointDef, requestedVersion); value != "" {
+			c.Writer.Header().Set(MigrationsAppliedHeader, value)
+			if id := CorrelationID(c); id != "" {
+				c.Writer.Header().Set(CorrelationIDHeader, id)
+			}
+		}
+	}
+
+	sample := vah.startStatsSample(endpointDef, requestedVersion)
+	defer sample.record()
+	logged.captureResponse(c)
+
+	// RequestInfo is created up front so response transformers can see request metadata
+	// (headers, path params, resolved version, original body) even for body-less requests
+	requestInfo := NewRequestInfo(c, nil)
 
 	// 1. Migrate request using KNOWN type
 	if endpointDef.RequestType != nil {
-		if err := vah.migrateRequest(c, requestedVersion, endpointDef.RequestType,
-			endpointDef.RequestNestedArrays, endpointDef.RequestNestedObjects); err != nil {
-			c.JSON(500, gin.H{"error": "Request migration failed", "details": err.Error()})
+		started := time.Now()
+		err := vah.migrateRequest(c, requestInfo, requestedVersion, endpointDef.RequestType,
+			endpointDef.RequestNestedArrays, endpointDef.RequestNestedObjects)
+		sample.addTime(time.Since(started))
+		sample.addRequest(requestInfo.OriginalBody)
+		if logged != nil {
+			var migrated []byte
+			if err == nil {
+				migrated = peekRequestBody(c)
+			}
+			types, operations := vah.migrationOperations(endpointDef.RequestType, requestedVersion, DirectionRequest)
+			logged.addRequest(types, operations, requestInfo.OriginalBody, migrated)
+		}
+		if err != nil {
+			var newer *NewerFieldsError
+			if errors.As(err, &newer) {
+				c.JSON(http.StatusBadRequest, withCorrelationID(c, gin.H{"error": newer.Error(), "fields": newer.Fields}))
+				c.Abort()
+				return
+			}
+			vah.breaker.recordFailure(err)
+			if !vah.fallBackOnPanic(err) {
+				abortMigration(c, "Request migration failed", err)
+				return
+			}
+			// Hand the handler the body exactly as the client sent it
+			c.Request.Body = io.NopCloser(bytes.NewReader(requestInfo.OriginalBody))
+			c.Request.ContentLength = int64(len(requestInfo.OriginalBody))
+		}
+	}
+	vah.recordRequestSchemaMatch(c, requestInfo, endpointDef.RequestType)
+
+	// 1b. Translate query parameters declared with ForEndpoint(). requestInfo keeps the
+	// parameters as the client sent them, so response migrations can still read them.
+	if err := vah.migrateRequestQuery(c, endpointDef, requestedVersion); err != nil {
+		vah.breaker.recordFailure(err)
+		if !vah.fallBackOnPanic(err) {
+			abortMigration(c, "Request migration failed", err)
 			return
 		}
 	}
 
-	// 2. Create a response writer that captures the response
-	responseCapture := &ResponseCapture{
-		ResponseWriter: c.Writer,
-		body:           make([]byte, 0),
-		statusCode:     200,
+	// 1c. Map path parameters declared with ForEndpoint() to HEAD's format
+	if err := vah.migrateRequestPathParams(c, endpointDef, requestedVersion); err != nil {
+		var invalid *PathParamError
+		if errors.As(err, &invalid) {
+			c.JSON(http.StatusBadRequest, withCorrelationID(c, gin.H{"error": invalid.Error(), "param": invalid.Param}))
+			c.Abort()
+			return
+		}
+		vah.breaker.recordFailure(err)
+		if !vah.fallBackOnPanic(err) {
+			abortMigration(c, "Request migration failed", err)
+			return
+		}
 	}
+
+	// 2. Create a response writer that captures the response (pooled to reduce GC pressure)
+	responseCapture := acquireResponseCapture(c.Writer)
 	originalWriter := c.Writer
 	c.Writer = responseCapture
 
 	// Ensure the original writer is always restored, even if the handler panics.
 	// Without this, Gin's recovery middleware would operate on the capture writer,
-	// causing broken error responses.
-	defer func() { c.Writer = originalWriter }()
+	// causing broken error responses. The capture is only returned to the pool
+	// afterwards, once nothing references its buffer.
+	defer func() {
+		c.Writer = originalWriter
+		releaseResponseCapture(responseCapture)
+	}()
 
 	// 3. Call the handler (which expects head version data)
 	vah.handler(c)
+	logged.addResponseBefore(responseCapture.body)
+
+	// Rewrite HEAD resource URLs before any migration, like type operations see HEAD's structure
+	vah.rewriteURLHeaders(responseCapture.Header(), requestedVersion)
+
+	// 3b. Older versions of endpoints HEAD made asynchronous wait for the job's result
+	awaited, resultType, err := vah.awaitAsyncResponse(c, endpointDef, requestedVersion, responseCapture)
+	if err != nil {
+		c.Writer = responseCapture.ResponseWriter
+		if errors.Is(err, ErrAsyncTimeout) {
+			c.JSON(504, withCorrelationID(c, gin.H{"error": "Asynchronous job timed out", "details": err.Error()}))
+			return
+		}
+		c.JSON(500, withCorrelationID(c, gin.H{"error": "Response migration failed", "details": err.Error()}))
+		return
+	}
+
+	// 3c. Normalize map-built bodies to the registered type, then diagnose how the body matches
+	// it. In strict mode, a body that doesn't match fails loudly rather than shipping unmigrated.
+	registeredType := endpointDef.ResponseType
+	if awaited {
+		registeredType = resultType
+	}
+	if err := vah.normalizeResponse(responseCapture, registeredType); err != nil {
+		c.Writer = responseCapture.ResponseWriter
+		abortMigration(c, "Response migration failed", err)
+		return
+	}
+	vah.recordResponseSchemaMatch(c, responseCapture, registeredType)
+	if mismatch := vah.checkResponseSchema(responseCapture, registeredType); mismatch != nil {
+		c.Writer = responseCapture.ResponseWriter
+		abortSchemaMismatch(c, mismatch)
+		return
+	}
+
+	// 3d. Serve the migrated response cached for this endpoint, version and key
+	cacheKey, cacheable := vah.cachedResponseKey(c, endpointDef, requestedVersion, responseCapture, awaited)
+	if cacheable {
+		c.Writer = responseCapture.ResponseWriter
+		if vah.serveCachedResponse(c, cacheKey) {
+			sample.addResponse(responseCapture.body)
+			logged.addResponse(vah.migrationOperations(registeredType, requestedVersion, DirectionResponse))
+			return
+		}
+		c.Writer = responseCapture
+	}
+
+	// 4a. Splice precomputed templates for successful responses. Captured request fields
+	// can override AddField defaults, so those requests take the full migration path.
+	if template := vah.responseTemplate(requestedVersion); template != nil && !awaited && len(vah.urlBodyFields) == 0 &&
+		responseCapture.statusCode < 400 && len(responseCapture.body) > 0 && len(GetCapturedFields(c)) == 0 {
+		started := time.Now()
+		if patched, err := template.Apply(responseCapture.body); err == nil {
+			sample.addTime(time.Since(started))
+			sample.addResponse(responseCapture.body)
+			logged.addResponse(vah.migrationOperations(registeredType, requestedVersion, DirectionResponse))
+			c.Writer = responseCapture.ResponseWriter
+			c.Data(responseCapture.statusCode, "application/json", patched)
+			return
+		}
+		// Bodies the splicer can't read fall through to the full migration
+	}
 
 	// 4. Migrate response using KNOWN type(s)
 	// Always attempt migration for error responses (status >= 400) to transform field names
 	// even if no response type is registered. For error responses, use request type if available
 	// since validation errors reference request field names.
 	responseTypeForMigration := endpointDef.ResponseType
+	nestedArrays, nestedObjects := endpointDef.ResponseNestedArrays, endpointDef.ResponseNestedObjects
+	if awaited {
+		// The job result replaced the accepted response, so migrate it as the result type
+		responseTypeForMigration, nestedArrays, nestedObjects = resultType, nil, nil
+		if resultType != nil {
+			nestedArrays, nestedObjects = BuildNestedTypeMaps(resultType)
+		}
+	}
 	if responseTypeForMigration == nil && responseCapture.statusCode >= 400 && endpointDef.RequestType != nil {
 		// Use request type for error transformation
 		responseTypeForMigration = endpointDef.RequestType
 	}
 
-	if responseTypeForMigration != nil || responseCapture.statusCode >= 400 {
-		if err := vah.migrateResponse(c, requestedVersion, responseCapture,
-			responseTypeForMigration, endpointDef.ResponseNestedArrays, endpointDef.ResponseNestedObjects); err != nil {
+	hasEnvelope := vah.migrationChain.HasEnvelopeOperations(
+		vah.versionBundle.GetHeadVersion(), requestedVersion, endpointDef.Method, endpointDef.PathPattern)
+
+	migrate := responseTypeForMigration != nil || responseCapture.statusCode >= 400 || hasEnvelope
+	if migrate && vah.migratesStatus(responseCapture.statusCode) {
+		sample.addResponse(responseCapture.body)
+		logged.addResponse(vah.migrationOperations(responseTypeForMigration, requestedVersion, DirectionResponse))
+		var recorder *cacheRecorder
+		if cacheable {
+			recorder = recordResponse(responseCapture)
+		}
+		started := time.Now()
+		err := vah.migrateResponse(c, requestInfo, requestedVersion, responseCapture, endpointDef,
+			responseTypeForMigration, nestedArrays, nestedObjects)
+		sample.addTime(time.Since(started))
+		if err != nil {
+			vah.breaker.recordFailure(err)
 			c.Writer = responseCapture.ResponseWriter
-			c.JSON(500, gin.H{"error": "Response migration failed", "details": err.Error()})
-			return
+			if !vah.fallBackOnPanic(err) {
+				abortMigration(c, "Response migration failed", err)
+				return
+			}
+			// Give the client HEAD's response rather than a half-migrated one
+			if len(responseCapture.body) > 0 {
+				c.Data(responseCapture.statusCode, "application/json", responseCapture.body)
+			} else {
+				c.Writer.WriteHeader(responseCapture.statusCode)
+			}
+		} else if recorder != nil {
+			recorder.store(vah, cacheKey)
 		}
 	} else {
-		// No response type registered and not an error, write response as-is
+		// No response type registered and not an error, or an error left unmigrated: write response as-is
 		c.Writer = responseCapture.ResponseWriter
 		if len(responseCapture.body) > 0 {
-			c.Data(responseCapture.statusCode, "application/json", responseCapture.body)
+			body, err := vah.rewriteURLBody(responseCapture.body, requestedVersion)
+			if err != nil {
+				c.JSON(500, withCorrelationID(c, gin.H{"error": "Response migration failed", "details": err.Error()}))
+				return
+			}
+			c.Data(responseCapture.statusCode, "application/json", body)
 		} else {
 			c.Writer.WriteHeader(responseCapture.statusCode)
 		}
 	}
 }
 
+// bypassCacheKey identifies an endpoint+version pair in the bypass cache.
+// The chain generation is part of the key so runtime-added changes are picked up.
+type bypassCacheKey struct {
+	endpoint   *EndpointDefinition
+	version    string
+	generation uint64
+}
+
+// needsMigration reports whether any change between the requested version and HEAD
+// alters the types declared for the endpoint. The answer is cached per endpoint+version.
+func (vah *VersionAwareHandler) needsMigration(endpointDef *EndpointDefinition, requestedVersion *Version) bool {
+	cacheKey := bypassCacheKey{
+		endpoint:   endpointDef,
+		version:    requestedVersion.String(),
+		generation: vah.migrationChain.Generation(),
+	}
+	if cached, ok := vah.bypassCache.Load(cacheKey); ok {
+		return cached.(bool)
+	}
+
+	headVersion := vah.versionBundle.GetHeadVersion()
+	requestTypes := CollectMigratableTypes(endpointDef.RequestType)
+
+	// Error responses fall back to the request type, so it counts for the response side too
+	responseTypes := append(CollectMigratableTypes(endpointDef.ResponseType), requestTypes...)
+
+	needed := vah.migrationChain.HasMigrationsForTypes(requestedVersion, headVersion, DirectionRequest, requestTypes) ||
+		vah.migrationChain.HasMigrationsForTypes(headVersion, requestedVersion, DirectionResponse, responseTypes) ||
+		vah.migrationChain.HasEnvelopeOperations(headVersion, requestedVersion, endpointDef.Method, endpointDef.PathPattern) ||
+		vah.migrationChain.HasQueryOperations(requestedVersion, headVersion, endpointDef.Method, endpointDef.PathPattern) ||
+		vah.migrationChain.HasPathParamOperations(requestedVersion, headVersion, endpointDef.Method, endpointDef.PathPattern) ||
+		(vah.stripsFields(requestedVersion) && endpointDef.ResponseType != nil)
+
+	vah.bypassCache.Store(cacheKey, needed)
+	return needed
+}
+
 // ResponseCapture captures response data for migration
 type ResponseCapture struct {
 	gin.ResponseWriter
@@ -456,9 +927,40 @@ func (rc *ResponseCapture) WriteHeader(statusCode int) {
 	rc.statusCode = statusCode
 }
 
+// maxPooledCaptureSize caps the buffer capacity kept in the pool so that a single
+// very large response doesn't pin that much memory for the lifetime of the process
+const maxPooledCaptureSize = 1 << 20
+
+// responseCapturePool reuses ResponseCapture values and their body buffers across requests
+var responseCapturePool = sync.Pool{
+	New: func() interface{} {
+		return &ResponseCapture{body: make([]byte, 0, 4096)}
+	},
+}
+
+// acquireResponseCapture returns a reset ResponseCapture wrapping the given writer
+func acquireResponseCapture(w gin.ResponseWriter) *ResponseCapture {
+	rc := responseCapturePool.Get().(*ResponseCapture)
+	rc.ResponseWriter = w
+	rc.body = rc.body[:0]
+	rc.statusCode = 200
+	return rc
+}
+
+// releaseResponseCapture returns a ResponseCapture to the pool
+// Callers must not use the capture or its body after releasing it
+func releaseResponseCapture(rc *ResponseCapture) {
+	if cap(rc.body) > maxPooledCaptureSize {
+		return
+	}
+	rc.ResponseWriter = nil
+	responseCapturePool.Put(rc)
+}
+
 // migrateRequest migrates request data using a known type (no schema matching)
 func (vah *VersionAwareHandler) migrateRequest(
 	c *gin.Context,
+	requestInfo *RequestInfo,
 	fromVersion *Version,
 	requestType reflect.Type,
 	nestedArrays map[string]reflect.Type,
@@ -476,66 +978,94 @@ func (vah *VersionAwareHandler) migrateRequest(
 	}
 	c.Request.Body.Close()
 
+	requestInfo.OriginalBody = bodyBytes
+
 	// If body is empty, nothing to migrate
 	if len(bodyBytes) == 0 {
 		c.Request.Body = io.NopCloser(bytes.NewReader(bodyBytes))
 		return nil
 	}
 
-	// Parse JSON body with Sonic to preserve field order
-	bodyNode, err := sonic.Get(bodyBytes)
+	// Parse JSON body into an ordered AST to preserve field order
+	bodyNode, err := vah.jsonEngine.Parse(bodyBytes)
 	if err != nil {
 		// If JSON parsing fails, restore original body and let handler deal with it
 		c.Request.Body = io.NopCloser(bytes.NewReader(bodyBytes))
 		return nil
 	}
 
-	// Load the node to parse the entire structure
-	if err := bodyNode.Load(); err != nil {
-		c.Request.Body = io.NopCloser(bytes.NewReader(bodyBytes))
-		return nil
-	}
+	// Attach the parsed body for migration
+	requestInfo.Body = bodyNode
 
-	// Create RequestInfo for migration
-	requestInfo := NewRequestInfo(c, &bodyNode)
+	warning, err := vah.handleNewerRequestFields(bodyNode, requestType, fromVersion)
+	if err != nil {
+		return err
+	}
+	if warning != "" {
+		c.Writer.Header().Add(MigrationWarningHeader, warning)
+	}
 
 	// Apply migrations for this SPECIFIC type (NO schema matching)
 	// Use the extended version that supports nested objects
+	ctx, cancel := vah.migrationContext(c)
+	defer cancel()
+
 	headVersion := vah.versionBundle.GetHeadVersion()
 	if err := vah.migrationChain.MigrateRequestForTypeWithNestedObjects(
-		c.Request.Context(), requestInfo, requestType, nestedArrays, nestedObjects, fromVersion, headVersion); err != nil {
-		return fmt.Errorf("failed to migrate request: %w", err)
+		ctx, requestInfo, requestType, nestedArrays, nestedObjects, fromVersion, headVersion); err != nil {
+		return fmt.Errorf("failed to migrate request: %w", withMigrationCause(ctx, err))
 	}
 
 	// Update the request context with migrated data
 	c.Set("migratedRequestBody", requestInfo.Body)
 
-	// Marshal the migrated body using Sonic's Raw() to preserve field order
-	migratedJSON, err := requestInfo.Body.Raw()
+	// Serialize the migrated body, preserving field order
+	migratedBytes, err := vah.jsonEngine.Serialize(requestInfo.Body)
 	if err != nil {
 		return fmt.Errorf("failed to get raw JSON from migrated request: %w", err)
 	}
 
-	migratedBytes := []byte(migratedJSON)
 	c.Request.Body = io.NopCloser(bytes.NewReader(migratedBytes))
 	c.Request.ContentLength = int64(len(migratedBytes))
 
 	return nil
 }
 
+// migrateRequestQuery rewrites the request URL's query parameters for HEAD
+func (vah *VersionAwareHandler) migrateRequestQuery(c *gin.Context, endpointDef *EndpointDefinition, fromVersion *Version) error {
+	headVersion := vah.versionBundle.GetHeadVersion()
+	if c.Request.URL == nil ||
+		!vah.migrationChain.HasQueryOperations(fromVersion, headVersion, endpointDef.Method, endpointDef.PathPattern) {
+		return nil
+	}
+
+	ctx, cancel := vah.migrationContext(c)
+	defer cancel()
+
+	query := c.Request.URL.Query()
+	if err := vah.migrationChain.MigrateRequestQuery(
+		ctx, c, query, endpointDef.Method, endpointDef.PathPattern, fromVersion, headVersion); err != nil {
+		return fmt.Errorf("failed to migrate query parameters: %w", withMigrationCause(ctx, err))
+	}
+	c.Request.URL.RawQuery = query.Encode()
+	return nil
+}
+
 // migrateResponse migrates response data using known type(s) (no schema matching)
 func (vah *VersionAwareHandler) migrateResponse(
 	c *gin.Context,
+	requestInfo *RequestInfo,
 	toVersion *Version,
 	responseCapture *ResponseCapture,
+	endpointDef *EndpointDefinition,
 	responseType reflect.Type,
 	nestedArrays map[string]reflect.Type,
 	nestedObjects map[string]reflect.Type,
 ) error {
-	// Parse captured response body with Sonic to preserve field order
-	var responseNode *ast.Node
+	// Parse captured response body into an ordered AST to preserve field order
+	var responseNode *jsonast.Node
 	if len(responseCapture.body) > 0 {
-		node, err := sonic.Get(responseCapture.body)
+		node, err := vah.jsonEngine.Parse(responseCapture.body)
 		if err != nil {
 			// If JSON parsing fails, write original response
 			c.Writer = responseCapture.ResponseWriter
@@ -544,26 +1074,31 @@ func (vah *VersionAwareHandler) migrateResponse(
 			return nil
 		}
 
-		// IMPORTANT: sonic.Get() returns a search node that needs to be loaded
-		if err := node.Load(); err != nil {
-			c.Writer = responseCapture.ResponseWriter
-			c.Writer.WriteHeader(responseCapture.statusCode)
-			_, _ = c.Writer.Write(responseCapture.body)
-			return nil
-		}
+		responseNode = node
+	}
 
-		responseNode = &node
+	if err := vah.rewriteURLFields(responseNode, toVersion); err != nil {
+		return err
 	}
 
 	// Create ResponseInfo for migration
 	responseInfo := NewResponseInfo(c, responseNode)
 	responseInfo.StatusCode = responseCapture.statusCode
+	responseInfo.Request = requestInfo
+	responseInfo.snapshots = vah.snapshots
 
 	// Apply migrations for this SPECIFIC type (NO schema matching)
 	// Use the extended version that supports nested objects
+	ctx, cancel := vah.migrationContext(c)
+	defer cancel()
+
+	if vah.structuredErrors != nil && responseInfo.StatusCode >= 400 {
+		c.Set(structuredErrorsKey, true)
+	}
+
 	headVersion := vah.versionBundle.GetHeadVersion()
 	if err := vah.migrationChain.MigrateResponseForTypeWithNestedObjects(
-		c.Request.Context(),
+		ctx,
 		responseInfo,
 		responseType,
 		nestedArrays,
@@ -571,20 +1106,34 @@ func (vah *VersionAwareHandler) migrateResponse(
 		headVersion,
 		toVersion,
 	); err != nil {
-		return fmt.Errorf("failed to migrate response: %w", err)
+		return fmt.Errorf("failed to migrate response: %w", withMigrationCause(ctx, err))
+	}
+
+	if err := vah.transformStructuredErrors(responseInfo, responseType, toVersion); err != nil {
+		return fmt.Errorf("failed to transform errors: %w", err)
+	}
+	if err := vah.stripUnknownResponseFields(responseInfo, responseType, toVersion); err != nil {
+		return err
+	}
+
+	// Envelope changes run last so type migrations above always see the HEAD structure
+	if err := vah.migrationChain.MigrateResponseEnvelope(
+		ctx, responseInfo, endpointDef.Method, endpointDef.PathPattern, headVersion, toVersion); err != nil {
+		return fmt.Errorf("failed to migrate response envelope: %w", withMigrationCause(ctx, err))
 	}
 
 	// Write the migrated response with preserved field order
 	c.Writer = responseCapture.ResponseWriter
+	syncResponseHeaders(c.Writer.Header(), responseInfo.Headers)
 
 	if responseInfo.Body != nil {
-		// Use Sonic's Raw() to preserve field order
-		migratedJSON, err := responseInfo.Body.Raw()
+		// Serialize with the configured engine to preserve field order
+		migratedJSON, err := vah.serializeResponse(responseInfo.Body, toVersion, responseType)
 		if err != nil {
 			return fmt.Errorf("failed to get raw JSON from migrated response: %w", err)
 		}
 
-		c.Data(responseInfo.StatusCode, "application/json", []byte(migratedJSON))
+		c.Data(responseInfo.StatusCode, "application/json", migratedJSON)
 	} else {
 		if len(responseCapture.body) > 0 {
 			c.Data(responseCapture.statusCode, "application/json", responseCapture.body)
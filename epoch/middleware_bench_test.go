@@ -0,0 +1,124 @@
+package epoch
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type benchItem struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type benchListResponse struct {
+	Items []benchItem `json:"items"`
+	Total int         `json:"total"`
+}
+
+// benchStatus has no changes between versions, so its endpoint takes the bypass path
+type benchStatus struct {
+	Items []string `json:"items"`
+	Total int      `json:"total"`
+}
+
+// setupBenchRouter builds a router with migrated list and POST endpoints plus an unmigrated one
+func setupBenchRouter(b *testing.B, templates bool) *gin.Engine {
+	b.Helper()
+	gin.SetMode(gin.TestMode)
+
+	v1, _ := NewDateVersion("2024-01-01")
+	v2, _ := NewDateVersion("2024-06-01")
+
+	change := NewVersionChangeBuilder(v1, v2).
+		Description("Add email to items").
+		ForType(benchItem{}).
+		RequestToNextVersion().
+		AddField("email", "unknown@example.com").
+		ResponseToPreviousVersion().
+		RemoveField("email").
+		Build()
+
+	builder := NewEpoch().WithVersions(v1, v2).WithHeadVersion().WithChanges(change)
+	if templates {
+		builder = builder.WithResponseTemplates()
+	}
+	epochInstance, err := builder.Build()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	items := make([]benchItem, 50)
+	for i := range items {
+		items[i] = benchItem{ID: i, Name: "Item", Email: "item@example.com"}
+	}
+
+	router := gin.New()
+	router.Use(epochInstance.Middleware())
+	router.GET("/items", epochInstance.WrapHandler(func(c *gin.Context) {
+		c.JSON(200, benchListResponse{Items: items, Total: len(items)})
+	}).Returns(benchListResponse{}).ToHandlerFunc("GET", "/items"))
+	router.POST("/items", epochInstance.WrapHandler(func(c *gin.Context) {
+		var item benchItem
+		_ = c.ShouldBindJSON(&item)
+		c.JSON(201, item)
+	}).Accepts(benchItem{}).Returns(benchItem{}).ToHandlerFunc("POST", "/items"))
+	router.GET("/status", epochInstance.WrapHandler(func(c *gin.Context) {
+		c.JSON(200, benchStatus{Items: make([]string, 50), Total: 50})
+	}).Returns(benchStatus{}).ToHandlerFunc("GET", "/status"))
+
+	return router
+}
+
+func BenchmarkResponseMigration(b *testing.B) {
+	router := setupBenchRouter(b, false)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		req := httptest.NewRequest("GET", "/items", nil)
+		req.Header.Set("X-API-Version", "2024-01-01")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+}
+
+func BenchmarkResponseTemplate(b *testing.B) {
+	router := setupBenchRouter(b, true)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		req := httptest.NewRequest("GET", "/items", nil)
+		req.Header.Set("X-API-Version", "2024-01-01")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+}
+
+func BenchmarkRequestAndResponseMigration(b *testing.B) {
+	router := setupBenchRouter(b, false)
+	body := `{"id":1,"name":"Item"}`
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		req := httptest.NewRequest("POST", "/items", strings.NewReader(body))
+		req.Header.Set("X-API-Version", "2024-01-01")
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+}
+
+func BenchmarkUnmigratedEndpoint(b *testing.B) {
+	router := setupBenchRouter(b, false)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		req := httptest.NewRequest("GET", "/status", nil)
+		req.Header.Set("X-API-Version", "2024-01-01")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+}
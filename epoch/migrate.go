@@ -0,0 +1,59 @@
+package epoch
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// MigrateOptions describes one run of MigrationChain.Migrate
+type MigrateOptions struct {
+	// From and To are the versions to migrate between: older to newer for requests, newer to
+	// older for responses. Either may be HEAD.
+	From, To *Version
+
+	// Type is the body's type, e.g. reflect.TypeOf(User{}) or reflect.TypeOf([]User{}). Pointers
+	// are dereferenced. A nil Type runs only the changes' global instructions.
+	Type reflect.Type
+
+	// NestedArrays and NestedObjects map dotted field paths to the types found there. Leave both
+	// nil to discover them from Type, as Accepts and Returns do. Items of a top-level array
+	// always have their nested types discovered.
+	NestedArrays  map[string]reflect.Type
+	NestedObjects map[string]reflect.Type
+}
+
+// Migrate runs the chain on a *RequestInfo, from an older version up to a newer one, or on a
+// *ResponseInfo, from a newer version down to an older one, the way the middleware does for
+// registered endpoints. It is the supported entry point for custom chains built with
+// NewMigrationChain; the MigrateRequestForType and MigrateResponseForType variants are the
+// lower-level steps it's built on.
+func (mc *MigrationChain) Migrate(ctx context.Context, body TransformableBody, opts MigrateOptions) error {
+	if opts.From == nil || opts.To == nil {
+		return fmt.Errorf("migration needs both a from and a to version")
+	}
+
+	typ := opts.Type
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	nestedArrays, nestedObjects := opts.NestedArrays, opts.NestedObjects
+	if typ != nil && nestedArrays == nil && nestedObjects == nil {
+		nestedArrays, nestedObjects = BuildNestedTypeMaps(typ)
+	}
+
+	switch info := body.(type) {
+	case *RequestInfo:
+		if opts.From.IsNewerThan(opts.To) {
+			return fmt.Errorf("request migrations go from older to newer versions, got %s to %s", opts.From, opts.To)
+		}
+		return mc.MigrateRequestForTypeWithNestedObjects(ctx, info, typ, nestedArrays, nestedObjects, opts.From, opts.To)
+	case *ResponseInfo:
+		if opts.From.IsOlderThan(opts.To) {
+			return fmt.Errorf("response migrations go from newer to older versions, got %s to %s", opts.From, opts.To)
+		}
+		return mc.MigrateResponseForTypeWithNestedObjects(ctx, info, typ, nestedArrays, nestedObjects, opts.From, opts.To)
+	default:
+		return fmt.Errorf("migration needs a *RequestInfo or *ResponseInfo, got %T", body)
+	}
+}
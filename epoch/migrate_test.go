@@ -0,0 +1,91 @@
+package epoch
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MigrationChain.Migrate", func() {
+	var (
+		chain         *MigrationChain
+		v1, v2        *Version
+		payloadType   = reflect.TypeOf(webhookPayload{})
+		parse         func(string) *RequestInfo
+		parseResponse func(string) *ResponseInfo
+		ctx           = context.Background()
+	)
+
+	BeforeEach(func() {
+		v1, _ = NewDateVersion("2024-01-01")
+		v2, _ = NewDateVersion("2024-06-01")
+		var err error
+		chain, err = NewMigrationChain([]*VersionChange{
+			NewVersionChangeBuilder(v1, v2).
+				ForType(webhookPayload{}).
+				RequestToNextVersion().
+				RenameField("mail", "email").
+				ResponseToPreviousVersion().
+				RenameField("email", "mail").
+				Build(),
+			NewVersionChangeBuilder(v1, v2).
+				ForType(webhookItem{}).
+				RequestToNextVersion().
+				RenameField("qty", "quantity").
+				ResponseToPreviousVersion().
+				RenameField("quantity", "qty").
+				Build(),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		parse = func(raw string) *RequestInfo {
+			node, err := DefaultJSONEngine().Parse([]byte(raw))
+			Expect(err).NotTo(HaveOccurred())
+			return &RequestInfo{Body: node, Headers: http.Header{}}
+		}
+		parseResponse = func(raw string) *ResponseInfo {
+			node, err := DefaultJSONEngine().Parse([]byte(raw))
+			Expect(err).NotTo(HaveOccurred())
+			return &ResponseInfo{Body: node, StatusCode: http.StatusOK, Headers: http.Header{}}
+		}
+	})
+
+	It("should migrate requests up, discovering nested types", func() {
+		req := parse(`{"mail":"a@b.c","items":[{"sku":"A","qty":2}]}`)
+		Expect(chain.Migrate(ctx, req, MigrateOptions{From: v1, To: v2, Type: reflect.PointerTo(payloadType)})).To(Succeed())
+		raw, _ := req.Body.Raw()
+		Expect(raw).To(MatchJSON(`{"email":"a@b.c","items":[{"sku":"A","quantity":2}]}`))
+	})
+
+	It("should migrate responses down, using the nested types given", func() {
+		resp := parseResponse(`{"email":"a@b.c","items":[{"sku":"A","quantity":2}]}`)
+		Expect(chain.Migrate(ctx, resp, MigrateOptions{
+			From:          v2,
+			To:            v1,
+			Type:          payloadType,
+			NestedArrays:  map[string]reflect.Type{},
+			NestedObjects: map[string]reflect.Type{},
+		})).To(Succeed())
+		raw, _ := resp.Body.Raw()
+		Expect(raw).To(MatchJSON(`{"mail":"a@b.c","items":[{"sku":"A","quantity":2}]}`))
+
+		list := parseResponse(`[{"email":"a@b.c","items":[{"sku":"A","quantity":2}]}]`)
+		Expect(chain.Migrate(ctx, list, MigrateOptions{From: v2, To: v1, Type: reflect.TypeOf([]webhookPayload{})})).To(Succeed())
+		raw, _ = list.Body.Raw()
+		Expect(raw).To(MatchJSON(`[{"mail":"a@b.c","items":[{"sku":"A","qty":2}]}]`))
+	})
+
+	It("should reject missing versions, the wrong direction and other bodies", func() {
+		Expect(chain.Migrate(ctx, parse(`{}`), MigrateOptions{From: v1, Type: payloadType})).To(
+			MatchError("migration needs both a from and a to version"))
+		Expect(chain.Migrate(ctx, parse(`{}`), MigrateOptions{From: v2, To: v1, Type: payloadType})).To(
+			MatchError("request migrations go from older to newer versions, got 2024-06-01 to 2024-01-01"))
+		Expect(chain.Migrate(ctx, parseResponse(`{}`), MigrateOptions{From: v1, To: v2, Type: payloadType})).To(
+			MatchError("response migrations go from newer to older versions, got 2024-01-01 to 2024-06-01"))
+		Expect(chain.Migrate(ctx, nil, MigrateOptions{From: v1, To: v2})).To(
+			MatchError("migration needs a *RequestInfo or *ResponseInfo, got <nil>"))
+	})
+})
@@ -0,0 +1,120 @@
+package epoch
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// MigrationsAppliedHeader lists the version changes and operations applied to a request
+// and its response. Written only when migration debugging is enabled.
+const MigrationsAppliedHeader = "X-Epoch-Migrations-Applied"
+
+// DescribeMigrations summarizes the changes between two versions that touch the given types
+// in the given direction, one entry per change in the order they run, e.g.
+// "2025-01-01->2024-06-01[UserResponse: rename full_name->name, remove phone]"
+func (mc *MigrationChain) DescribeMigrations(from, to *Version, direction TransformDirection, types []reflect.Type) []string {
+	path := mc.GetMigrationPath(from, to)
+	if direction == DirectionResponse {
+		// Responses run newer → older
+		sort.SliceStable(path, func(i, j int) bool { return path[i].FromVersion().IsNewerThan(path[j].FromVersion()) })
+	}
+
+	wanted := make(map[reflect.Type]bool, len(types))
+	for _, t := range types {
+		wanted[t] = true
+	}
+
+	var descriptions []string
+	for _, change := range path {
+		change.BindTypes(types...)
+		var groups []string
+		if direction == DirectionRequest && len(change.globalRequestInstructions) > 0 ||
+			direction == DirectionResponse && len(change.globalResponseInstructions) > 0 {
+			groups = append(groups, "*: custom")
+		}
+
+		var currentType reflect.Type
+		var ops []string
+		flush := func() {
+			if len(ops) > 0 {
+				groups = append(groups, currentType.Name()+": "+strings.Join(ops, ", "))
+			}
+			ops = nil
+		}
+		for _, entry := range change.ChangelogEntries() {
+			if entry.Direction != direction || !wanted[entry.Type] {
+				continue
+			}
+			if entry.Type != currentType {
+				flush()
+				currentType = entry.Type
+			}
+			ops = append(ops, summarizeOperationDoc(entry.Doc))
+		}
+		flush()
+
+		if len(groups) == 0 {
+			continue
+		}
+		versions := change.FromVersion().String() + "->" + change.ToVersion().String()
+		if direction == DirectionResponse {
+			versions = change.ToVersion().String() + "->" + change.FromVersion().String()
+		}
+		descriptions = append(descriptions, versions+"["+strings.Join(groups, "; ")+"]")
+	}
+	return descriptions
+}
+
+// summarizeOperationDoc renders an operation as a short phrase such as "rename a->b"
+func summarizeOperationDoc(doc OperationDoc) string {
+	var parts []string
+	for _, from := range sortedKeys(doc.RenamedFields) {
+		parts = append(parts, "rename "+from+"->"+doc.RenamedFields[from])
+	}
+	for _, name := range doc.RemovedFields {
+		parts = append(parts, "remove "+name)
+	}
+	for _, name := range sortedKeys(doc.AddedFields) {
+		parts = append(parts, "add "+name)
+	}
+	if len(parts) == 0 {
+		return strings.ReplaceAll(doc.Name, "_", " ")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// sortedKeys returns the keys of a string-keyed map in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// migrationsAppliedHeader builds the debug header value for an endpoint+version
+// The value is cached alongside the bypass decision since it depends on the same inputs.
+func (vah *VersionAwareHandler) migrationsAppliedHeader(endpointDef *EndpointDefinition, requestedVersion *Version) string {
+	cacheKey := bypassCacheKey{
+		endpoint:   endpointDef,
+		version:    requestedVersion.String(),
+		generation: vah.migrationChain.Generation(),
+	}
+	if cached, ok := vah.debugHeaderCache.Load(cacheKey); ok {
+		return cached.(string)
+	}
+
+	headVersion := vah.versionBundle.GetHeadVersion()
+	requestTypes := CollectMigratableTypes(endpointDef.RequestType)
+	responseTypes := append(CollectMigratableTypes(endpointDef.ResponseType), requestTypes...)
+
+	descriptions := vah.migrationChain.DescribeMigrations(requestedVersion, headVersion, DirectionRequest, requestTypes)
+	descriptions = append(descriptions,
+		vah.migrationChain.DescribeMigrations(headVersion, requestedVersion, DirectionResponse, responseTypes)...)
+
+	value := strings.Join(descriptions, ", ")
+	vah.debugHeaderCache.Store(cacheKey, value)
+	return value
+}
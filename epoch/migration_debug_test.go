@@ -0,0 +1,121 @@
+package epoch
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type debugUserRequest struct {
+	FullName string `json:"full_name"`
+}
+
+type debugUserResponse struct {
+	ID       int    `json:"id"`
+	FullName string `json:"full_name"`
+	Phone    string `json:"phone"`
+}
+
+var _ = Describe("Migration debug header", func() {
+	var v1, v2, v3 *Version
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		v1, v2, v3 = newTestVersions()
+	})
+
+	changes := func() []*VersionChange {
+		return []*VersionChange{
+			NewVersionChangeBuilder(v1, v2).
+				ForType(debugUserResponse{}).
+				ResponseToPreviousVersion().
+				RemoveField("id").
+				Build(),
+			NewVersionChangeBuilder(v2, v3).
+				ForType(debugUserRequest{}).
+				RequestToNextVersion().
+				RenameField("name", "full_name").
+				ForType(debugUserResponse{}).
+				ResponseToPreviousVersion().
+				RenameField("full_name", "name").
+				RemoveField("phone").
+				Build(),
+		}
+	}
+
+	Describe("DescribeMigrations", func() {
+		It("should list changes in the order they run", func() {
+			chain, err := NewMigrationChain(changes())
+			Expect(err).NotTo(HaveOccurred())
+			head := NewHeadVersion()
+			responseTypes := []reflect.Type{reflect.TypeOf(debugUserResponse{})}
+
+			Expect(chain.DescribeMigrations(head, v1, DirectionResponse, responseTypes)).To(Equal([]string{
+				"2025-01-01->2024-06-01[debugUserResponse: rename full_name->name, remove phone]",
+				"2024-06-01->2024-01-01[debugUserResponse: remove id]",
+			}))
+
+			requestTypes := []reflect.Type{reflect.TypeOf(debugUserRequest{})}
+			Expect(chain.DescribeMigrations(v1, head, DirectionRequest, requestTypes)).To(Equal([]string{
+				"2024-06-01->2025-01-01[debugUserRequest: rename name->full_name]",
+			}))
+		})
+
+		It("should skip changes that do not touch the given types", func() {
+			chain, err := NewMigrationChain(changes())
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(chain.DescribeMigrations(NewHeadVersion(), v1, DirectionResponse,
+				[]reflect.Type{reflect.TypeOf(pluginWidget{})})).To(BeEmpty())
+		})
+	})
+
+	Describe("WithMigrationDebugHeader", func() {
+		serve := func(debug bool, version string) *httptest.ResponseRecorder {
+			var options []func(*EpochBuilder) *EpochBuilder
+			if debug {
+				options = append(options, (*EpochBuilder).WithMigrationDebugHeader)
+			}
+			epochInstance, err := setupHeadEpoch([]*Version{v1, v2, v3}, changes(), options...)
+			Expect(err).NotTo(HaveOccurred())
+
+			router := setupRouterWithMiddleware(epochInstance)
+			router.POST("/users", epochInstance.WrapHandler(func(c *gin.Context) {
+				c.JSON(200, debugUserResponse{ID: 1, FullName: "Jane", Phone: "555"})
+			}).Accepts(debugUserRequest{}).Returns(debugUserResponse{}).ToHandlerFunc("POST", "/users"))
+
+			req := httptest.NewRequest("POST", "/users", strings.NewReader(`{"name":"Jane"}`))
+			recorder := serveVersioned(router, req, version)
+			Expect(recorder.Code).To(Equal(200))
+			return recorder
+		}
+
+		It("should list request and response migrations", func() {
+			recorder := serve(true, "2024-01-01")
+
+			Expect(recorder.Header().Get(MigrationsAppliedHeader)).To(Equal(
+				"2024-06-01->2025-01-01[debugUserRequest: rename name->full_name], " +
+					"2025-01-01->2024-06-01[debugUserResponse: rename full_name->name, remove phone], " +
+					"2024-06-01->2024-01-01[debugUserResponse: remove id]"))
+			Expect(recorder.Body.String()).To(Equal(`{"name":"Jane"}`))
+		})
+
+		It("should only list changes newer than the requested version", func() {
+			recorder := serve(true, "2024-06-01")
+
+			Expect(recorder.Header().Get(MigrationsAppliedHeader)).NotTo(ContainSubstring("remove id"))
+		})
+
+		It("should be off by default", func() {
+			Expect(serve(false, "2024-01-01").Header().Values(MigrationsAppliedHeader)).To(BeEmpty())
+		})
+
+		It("should be omitted when nothing is migrated", func() {
+			Expect(serve(true, "head").Header().Values(MigrationsAppliedHeader)).To(BeEmpty())
+		})
+	})
+})
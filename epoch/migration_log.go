@@ -0,0 +1,258 @@
+package epoch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultMigrationLogSampleBytes is how much of each body a migration log entry keeps
+// unless MigrationLog.SampleBytes says otherwise
+const DefaultMigrationLogSampleBytes = 512
+
+// MigrationLog configures the migration log (see WithMigrationLog)
+type MigrationLog struct {
+	Writer      io.Writer                // Receives one JSON entry per line
+	SampleBytes int                      // Bytes kept of each body; DefaultMigrationLogSampleBytes when 0, none when negative
+	Scrub       func(body []byte) []byte // Removes PII from whole bodies before they're sampled, after WithSensitiveFields
+	Clock       Clock                    // Defaults to the Epoch's clock
+}
+
+// MigrationLogEntry is what the migration log records about one request
+type MigrationLogEntry struct {
+	Time               time.Time `json:"time"`
+	Method             string    `json:"method"`
+	Path               string    `json:"path"`               // Path as the client requested it
+	Endpoint           string    `json:"endpoint,omitempty"` // Route pattern of the registered endpoint
+	Version            string    `json:"version"`
+	CorrelationID      string    `json:"correlation_id,omitempty"`
+	Status             int       `json:"status"`
+	RequestTypes       []string  `json:"request_types,omitempty"`  // Types the request body migrated as
+	ResponseTypes      []string  `json:"response_types,omitempty"` // Types the response body migrated as
+	RequestOperations  int       `json:"request_operations"`       // Operations run on the request, client→HEAD
+	ResponseOperations int       `json:"response_operations"`      // Operations run on the response, HEAD→client
+	RequestBefore      string    `json:"request_before,omitempty"` // Request body as the client sent it
+	RequestAfter       string    `json:"request_after,omitempty"`  // Request body as the handler received it
+	ResponseBefore     string    `json:"response_before,omitempty"`
+	ResponseAfter      string    `json:"response_after,omitempty"`
+	Truncated          bool      `json:"truncated,omitempty"` // Some sample was cut at SampleBytes
+}
+
+// WithMigrationLog writes a structured JSON entry for every request wrapped handlers serve
+// under a version: the resolved version and endpoint, the types its bodies migrated as, how
+// many operations ran in each direction, and body samples before and after migration. HEAD
+// requests and versions no change touches the endpoint for are logged without bodies.
+func (cb *EpochBuilder) WithMigrationLog(config MigrationLog) *EpochBuilder {
+	if config.Writer == nil {
+		cb.errors = append(cb.errors, errors.New("migration log needs a writer"))
+		return cb
+	}
+	cb.migrationLog = &config
+	return cb
+}
+
+// migrationLogger writes the entries of every wrapped handler; safe for concurrent use
+type migrationLogger struct {
+	config MigrationLog
+	mu     sync.Mutex
+}
+
+// newMigrationLogger returns a logger for config, reading the system clock unless config has one
+func newMigrationLogger(config MigrationLog) *migrationLogger {
+	if config.Clock == nil {
+		config.Clock = SystemClock
+	}
+	if config.SampleBytes == 0 {
+		config.SampleBytes = DefaultMigrationLogSampleBytes
+	}
+	return &migrationLogger{config: config}
+}
+
+// withMigrationLog logs this handler's requests with logger
+func (vah *VersionAwareHandler) withMigrationLog(logger *migrationLogger) *VersionAwareHandler {
+	vah.migrationLog = logger
+	return vah
+}
+
+// migrationLogRecord accumulates one request's entry until it's written. A nil record logs nothing.
+type migrationLogRecord struct {
+	logger   *migrationLogger
+	entry    MigrationLogEntry
+	response *sampledResponseWriter
+	bodies   [4][]byte // request before/after, response before/after
+}
+
+// startMigrationLog starts recording a request, or returns nil without a migration log
+func (vah *VersionAwareHandler) startMigrationLog(c *gin.Context, version *Version) *migrationLogRecord {
+	if vah.migrationLog == nil {
+		return nil
+	}
+	return &migrationLogRecord{
+		logger: vah.migrationLog,
+		entry: MigrationLogEntry{
+			Time:          vah.migrationLog.config.Clock.Now(),
+			Method:        c.Request.Method,
+			Path:          c.Request.URL.Path,
+			Version:       version.String(),
+			CorrelationID: CorrelationID(c),
+		},
+	}
+}
+
+// setEndpoint notes the endpoint the request was matched to
+func (r *migrationLogRecord) setEndpoint(endpointDef *EndpointDefinition) {
+	if r != nil {
+		r.entry.Endpoint = endpointDef.PathPattern
+	}
+}
+
+// captureResponse keeps what the client is sent from here on, so it can be sampled
+func (r *migrationLogRecord) captureResponse(c *gin.Context) {
+	if r != nil && r.logger.config.SampleBytes > 0 {
+		r.response = &sampledResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = r.response
+	}
+}
+
+// addRequest notes the request's migration; after is nil when the migration failed
+func (r *migrationLogRecord) addRequest(types []string, operations int, before, after []byte) {
+	if r != nil {
+		r.entry.RequestTypes, r.entry.RequestOperations = types, operations
+		r.bodies[0], r.bodies[1] = before, after
+	}
+}
+
+// addResponseBefore notes the response as the handler wrote it
+func (r *migrationLogRecord) addResponseBefore(body []byte) {
+	if r != nil {
+		r.bodies[2] = append([]byte(nil), body...)
+	}
+}
+
+// addResponse notes the response's migration
+func (r *migrationLogRecord) addResponse(types []string, operations int) {
+	if r != nil {
+		r.entry.ResponseTypes, r.entry.ResponseOperations = types, operations
+	}
+}
+
+// write samples the bodies and writes the entry as a JSON line
+func (r *migrationLogRecord) write(c *gin.Context) {
+	if r == nil {
+		return
+	}
+	r.entry.Status = c.Writer.Status()
+	if r.response != nil {
+		c.Writer = r.response.ResponseWriter
+		r.bodies[3] = r.response.body.Bytes()
+	}
+
+	config := r.logger.config
+	samples := [4]*string{&r.entry.RequestBefore, &r.entry.RequestAfter, &r.entry.ResponseBefore, &r.entry.ResponseAfter}
+	for i, body := range r.bodies {
+		if len(body) == 0 || config.SampleBytes < 0 {
+			continue
+		}
+		if config.Scrub != nil {
+			body = config.Scrub(body)
+		}
+		if len(body) > config.SampleBytes {
+			body = body[:config.SampleBytes]
+			r.entry.Truncated = true
+		}
+		*samples[i] = string(body)
+	}
+
+	line, err := json.Marshal(r.entry)
+	if err != nil {
+		return
+	}
+	r.logger.mu.Lock()
+	defer r.logger.mu.Unlock()
+	r.logger.config.Writer.Write(append(line, '\n'))
+}
+
+// sampledResponseWriter keeps a copy of the response body written through it
+type sampledResponseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *sampledResponseWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *sampledResponseWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// peekRequestBody returns the request body the handler will read, leaving it readable
+func peekRequestBody(c *gin.Context) []byte {
+	if c.Request.Body == nil {
+		return nil
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	return body
+}
+
+// operationCountKey identifies a type+version+direction in the operation count cache
+type operationCountKey struct {
+	typ        reflect.Type
+	version    string
+	direction  TransformDirection
+	generation uint64
+}
+
+// operationCount is the cached result of migrationOperations
+type operationCount struct {
+	types      []string
+	operations int
+}
+
+// migrationOperations names typ and the types nested in it, and counts the operations the changes
+// between version and HEAD run on them in one direction. The result is cached per type and version.
+func (vah *VersionAwareHandler) migrationOperations(typ reflect.Type, version *Version, direction TransformDirection) ([]string, int) {
+	if vah.migrationLog == nil || typ == nil {
+		return nil, 0
+	}
+	cacheKey := operationCountKey{typ: typ, version: version.String(), direction: direction, generation: vah.migrationChain.Generation()}
+	if cached, ok := vah.operationCountCache.Load(cacheKey); ok {
+		count := cached.(operationCount)
+		return count.types, count.operations
+	}
+
+	types := CollectMigratableTypes(typ)
+	wanted := make(map[reflect.Type]bool, len(types))
+	count := operationCount{}
+	for _, t := range types {
+		wanted[t] = true
+		count.types = append(count.types, t.String())
+	}
+	for _, change := range vah.migrationChain.GetMigrationPath(version, vah.versionBundle.GetHeadVersion()) {
+		change.BindTypes(types...)
+		if direction == DirectionRequest {
+			count.operations += len(change.globalRequestInstructions)
+		} else {
+			count.operations += len(change.globalResponseInstructions)
+		}
+		for _, entry := range change.ChangelogEntries() {
+			if entry.Direction == direction && wanted[entry.Type] {
+				count.operations++
+			}
+		}
+	}
+	vah.operationCountCache.Store(cacheKey, count)
+	return count.types, count.operations
+}
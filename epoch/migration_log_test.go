@@ -0,0 +1,133 @@
+package epoch
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type logUser struct {
+	ID       int    `json:"id"`
+	FullName string `json:"full_name"`
+	Email    string `json:"email"`
+}
+
+var _ = Describe("Migration log", func() {
+	var (
+		v1, v2 *Version
+		output *bytes.Buffer
+		router *gin.Engine
+	)
+
+	const userJSON = `{"id":1,"full_name":"Ada","email":"ada@example.com"}`
+	loggedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	build := func(config MigrationLog) {
+		// Build() attaches changes to versions, so every Epoch gets its own
+		v1, _ = NewDateVersion("2024-01-01")
+		v2, _ = NewDateVersion("2025-01-01")
+		change := NewVersionChangeBuilder(v1, v2).
+			ForType(logUser{}).
+			RequestToNextVersion().
+			RenameField("name", "full_name").
+			ResponseToPreviousVersion().
+			RenameField("full_name", "name").
+			Build()
+		config.Writer = output
+		epochInstance, err := NewEpoch().WithVersions(v1, v2).WithHeadVersion().WithChanges(change).
+			WithClock(FixedClock(loggedAt)).WithMigrationLog(config).Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		gin.SetMode(gin.TestMode)
+		router = gin.New()
+		router.Use(epochInstance.Middleware())
+		router.POST("/users", epochInstance.WrapHandler(func(c *gin.Context) {
+			c.Data(http.StatusCreated, "application/json", []byte(userJSON))
+		}).Accepts(logUser{}).Returns(logUser{}).ToHandlerFunc("POST", "/users"))
+	}
+
+	send := func(version, body string) []MigrationLogEntry {
+		output.Reset()
+		req := httptest.NewRequest("POST", "/users", strings.NewReader(body))
+		req.Header.Set("X-API-Version", version)
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		var entries []MigrationLogEntry
+		for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+			var entry MigrationLogEntry
+			Expect(json.Unmarshal([]byte(line), &entry)).To(Succeed())
+			entries = append(entries, entry)
+		}
+		return entries
+	}
+
+	BeforeEach(func() {
+		v1, _ = NewDateVersion("2024-01-01")
+		v2, _ = NewDateVersion("2025-01-01")
+		output = &bytes.Buffer{}
+	})
+
+	It("should log the migration of each request as a JSON line", func() {
+		build(MigrationLog{})
+		entries := send("2024-01-01", `{"id":1,"name":"Ada","email":"ada@example.com"}`)
+		Expect(entries).To(HaveLen(1))
+
+		entry := entries[0]
+		Expect(entry.Time).To(BeTemporally("==", loggedAt))
+		Expect(entry.Method).To(Equal("POST"))
+		Expect(entry.Endpoint).To(Equal("/users"))
+		Expect(entry.Version).To(Equal("2024-01-01"))
+		Expect(entry.Status).To(Equal(http.StatusCreated))
+		Expect(entry.RequestTypes).To(Equal([]string{"epoch.logUser"}))
+		Expect(entry.ResponseTypes).To(Equal([]string{"epoch.logUser"}))
+		Expect(entry.RequestOperations).To(Equal(1))
+		Expect(entry.ResponseOperations).To(Equal(1))
+		Expect(entry.RequestBefore).To(MatchJSON(`{"id":1,"name":"Ada","email":"ada@example.com"}`))
+		Expect(entry.RequestAfter).To(MatchJSON(userJSON))
+		Expect(entry.ResponseBefore).To(MatchJSON(userJSON))
+		Expect(entry.ResponseAfter).To(MatchJSON(`{"id":1,"name":"Ada","email":"ada@example.com"}`))
+		Expect(entry.Truncated).To(BeFalse())
+	})
+
+	It("should log HEAD requests without migrating them", func() {
+		build(MigrationLog{})
+		entries := send("head", userJSON)
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Status).To(Equal(http.StatusCreated))
+		Expect(entries[0].RequestOperations).To(BeZero())
+		Expect(entries[0].RequestBefore).To(BeEmpty())
+		Expect(entries[0].ResponseAfter).To(BeEmpty())
+	})
+
+	It("should scrub and truncate body samples", func() {
+		build(MigrationLog{
+			SampleBytes: 20,
+			Scrub: func(body []byte) []byte {
+				return bytes.ReplaceAll(body, []byte("ada@example.com"), []byte("***"))
+			},
+		})
+		entry := send("2024-01-01", `{"email":"ada@example.com","id":1,"name":"Ada"}`)[0]
+		Expect(entry.RequestBefore).To(Equal(`{"email":"***","id":`))
+		Expect(entry.Truncated).To(BeTrue())
+		Expect(output.String()).NotTo(ContainSubstring("ada@example.com"))
+
+		build(MigrationLog{SampleBytes: -1})
+		entry = send("2024-01-01", `{"id":1,"name":"Ada"}`)[0]
+		Expect(entry.RequestOperations).To(Equal(1))
+		Expect(entry.RequestBefore).To(BeEmpty())
+		Expect(entry.ResponseAfter).To(BeEmpty())
+	})
+
+	It("should require a writer", func() {
+		_, err := NewEpoch().WithVersions(v1).WithMigrationLog(MigrationLog{}).Build()
+		Expect(err).To(MatchError(ContainSubstring("migration log needs a writer")))
+	})
+})
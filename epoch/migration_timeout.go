@@ -0,0 +1,76 @@
+package epoch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrMigrationTimeout is returned when migrating a request or response takes longer than
+// the timeout set with WithMigrationTimeout
+var ErrMigrationTimeout = errors.New("migration timed out")
+
+// WithMigrationTimeout bounds how long migrating the request, and separately the response,
+// may take. The handler itself isn't bounded. A zero timeout only honors the request context.
+func (vah *VersionAwareHandler) WithMigrationTimeout(timeout time.Duration) *VersionAwareHandler {
+	vah.migrationTimeout = timeout
+	return vah
+}
+
+// migrationContext derives the context one migration phase runs under from the request context
+func (vah *VersionAwareHandler) migrationContext(c *gin.Context) (context.Context, context.CancelFunc) {
+	ctx := migrationContext(nil, c)
+	if vah.migrationTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeoutCause(ctx, vah.migrationTimeout, ErrMigrationTimeout)
+}
+
+// abortMigration answers a failed migration: 504 when it timed out, nothing when the client
+// went away (there's no one to answer), and 500 with message otherwise
+func abortMigration(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, ErrMigrationTimeout):
+		c.JSON(http.StatusGatewayTimeout, withCorrelationID(c, gin.H{"error": "Migration timed out", "details": err.Error()}))
+	case errors.Is(err, context.Canceled):
+		c.Abort()
+	default:
+		c.JSON(http.StatusInternalServerError, withCorrelationID(c, gin.H{"error": message, "details": err.Error()}))
+	}
+}
+
+// migrationContext returns ctx, falling back to the request context of c, then context.Background()
+func migrationContext(ctx context.Context, c *gin.Context) context.Context {
+	if ctx != nil {
+		return ctx
+	}
+	if c != nil && c.Request != nil {
+		return c.Request.Context()
+	}
+	return context.Background()
+}
+
+// checkMigrationContext returns an error once ctx is done, so the chain stops transforming
+// a body nobody will receive
+func checkMigrationContext(ctx context.Context) error {
+	if ctx == nil || ctx.Err() == nil {
+		return nil
+	}
+	return fmt.Errorf("migration stopped: %w", context.Cause(ctx))
+}
+
+// withMigrationCause attaches why ctx is done to err, so a transformer that gave up with
+// ctx.Err() still surfaces as ErrMigrationTimeout
+func withMigrationCause(ctx context.Context, err error) error {
+	if ctx.Err() == nil {
+		return err
+	}
+	if cause := context.Cause(ctx); !errors.Is(err, cause) {
+		return fmt.Errorf("%w: %w", cause, err)
+	}
+	return err
+}
@@ -0,0 +1,116 @@
+package epoch
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type timeoutItem struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+var _ = Describe("Migration timeouts and cancellation", func() {
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+	})
+
+	serve := func(timeout time.Duration, ctx context.Context, transform func(*ResponseInfo) error, handlerCalled *bool) *httptest.ResponseRecorder {
+		v1, v2, _ := newTestVersions()
+		change := NewVersionChangeBuilder(v1, v2).
+			ForType(timeoutItem{}).
+			RequestToNextVersion().
+			AddField("name", "unnamed").
+			ResponseToPreviousVersion().
+			Custom(transform).
+			Build()
+		epochInstance, err := setupHeadEpoch([]*Version{v1, v2}, []*VersionChange{change}, func(builder *EpochBuilder) *EpochBuilder {
+			return builder.WithMigrationTimeout(timeout)
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		router := setupRouterWithMiddleware(epochInstance)
+		router.POST("/items", epochInstance.WrapHandler(func(c *gin.Context) {
+			if handlerCalled != nil {
+				*handlerCalled = true
+			}
+			c.JSON(200, timeoutItem{ID: 1, Name: "first"})
+		}).Accepts(timeoutItem{}).Returns(timeoutItem{}).ToHandlerFunc("POST", "/items"))
+
+		req := httptest.NewRequest("POST", "/items", strings.NewReader(`{"id":1}`)).WithContext(ctx)
+		return serveVersioned(router, req, "2024-01-01")
+	}
+
+	It("should answer 504 when the response migration runs over the timeout", func() {
+		recorder := serve(20*time.Millisecond, context.Background(), func(resp *ResponseInfo) error {
+			<-resp.Context().Done()
+			return resp.Context().Err()
+		}, nil)
+
+		Expect(recorder.Code).To(Equal(504))
+		Expect(recorder.Body.String()).To(ContainSubstring("Migration timed out"))
+	})
+
+	It("should expose the migration deadline to custom transformers", func() {
+		var deadlineSet bool
+		recorder := serve(time.Minute, context.Background(), func(resp *ResponseInfo) error {
+			_, deadlineSet = resp.Context().Deadline()
+			return nil
+		}, nil)
+
+		Expect(recorder.Code).To(Equal(200))
+		Expect(deadlineSet).To(BeTrue())
+	})
+
+	It("should not bound migrations without a timeout", func() {
+		var deadlineSet bool
+		recorder := serve(0, context.Background(), func(resp *ResponseInfo) error {
+			_, deadlineSet = resp.Context().Deadline()
+			return nil
+		}, nil)
+
+		Expect(recorder.Code).To(Equal(200))
+		Expect(deadlineSet).To(BeFalse())
+	})
+
+	It("should not call the handler or write a response once the client disconnected", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		var handlerCalled bool
+		recorder := serve(0, ctx, func(*ResponseInfo) error { return nil }, &handlerCalled)
+
+		Expect(handlerCalled).To(BeFalse())
+		Expect(recorder.Body.Len()).To(BeZero())
+	})
+
+	It("should stop the chain between changes when the context is done", func() {
+		v1, v2, v3 := newTestVersions()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		var ran []string
+		chain, err := NewMigrationChain([]*VersionChange{
+			NewVersionChangeBuilder(v1, v2).ForType(timeoutItem{}).ResponseToPreviousVersion().
+				Custom(func(*ResponseInfo) error { ran = append(ran, "v2->v1"); return nil }).Build(),
+			NewVersionChangeBuilder(v2, v3).ForType(timeoutItem{}).ResponseToPreviousVersion().
+				Custom(func(*ResponseInfo) error { ran = append(ran, "v3->v2"); cancel(); return nil }).Build(),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		body, err := getNode([]byte(`{"id":1,"name":"first"}`))
+		Expect(err).NotTo(HaveOccurred())
+		info := &ResponseInfo{Body: &body, StatusCode: 200}
+
+		err = chain.MigrateResponseForType(ctx, info, reflect.TypeOf(timeoutItem{}), nil, v3, v1)
+		Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+		Expect(ran).To(Equal([]string{"v3->v2"}))
+	})
+})
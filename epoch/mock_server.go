@@ -0,0 +1,91 @@
+package epoch
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MockFixture is a canned HEAD response MockServer serves for an endpoint
+type MockFixture struct {
+	Method  string
+	Path    string      // Path pattern as registered with gin, e.g. "/users/:id"
+	Status  int         // Defaults to 200 OK
+	Body    interface{} // HEAD response: a value of the response type, or raw JSON as []byte or json.RawMessage
+	Returns interface{} // Response type to migrate Body as; defaults to Body's type, or for raw JSON the endpoint's registered type
+}
+
+// MockServer returns a server that answers each fixture's endpoint with its body migrated to
+// the version the request asks for, so client teams can develop against older versions without
+// the real backend. Serve it with httptest.NewServer in tests, or http.ListenAndServe on its own.
+// Requests go through the Epoch's middleware but reach no handler, so every request to an
+// endpoint gets the same body whatever its path parameters, query or body.
+// Endpoints the Epoch already registered with WrapHandler keep their request type.
+func (c *Epoch) MockServer(fixtures ...MockFixture) (http.Handler, error) {
+	router := gin.New()
+	router.Use(c.Middleware())
+
+	seen := make(map[string]bool, len(fixtures))
+	for _, fixture := range fixtures {
+		key := endpointKey(fixture.Method, fixture.Path)
+		if seen[key] {
+			return nil, fmt.Errorf("mock fixture %s is declared twice", key)
+		}
+		seen[key] = true
+
+		handler, err := c.mockHandler(fixture)
+		if err != nil {
+			return nil, fmt.Errorf("mock fixture %s: %w", key, err)
+		}
+		router.Handle(fixture.Method, fixture.Path, handler)
+	}
+	return router, nil
+}
+
+// mockHandler wraps a handler that writes the fixture's body, typed for migration
+func (c *Epoch) mockHandler(fixture MockFixture) (gin.HandlerFunc, error) {
+	registered := c.endpointRegistry.get(fixture.Method, fixture.Path)
+
+	var body []byte
+	var responseType reflect.Type
+	switch raw := fixture.Body.(type) {
+	case []byte:
+		body = raw
+	case json.RawMessage:
+		body = raw
+	default:
+		encoded, err := json.Marshal(fixture.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode body: %w", err)
+		}
+		body = encoded
+		responseType = reflect.TypeOf(fixture.Body)
+	}
+	if !json.Valid(body) {
+		return nil, fmt.Errorf("body is not valid JSON")
+	}
+	switch {
+	case fixture.Returns != nil:
+		responseType = reflect.TypeOf(fixture.Returns)
+	case responseType == nil && registered != nil:
+		responseType = registered.ResponseType
+	}
+	if responseType == nil {
+		return nil, fmt.Errorf("raw JSON body needs Returns or an endpoint registered with WrapHandler")
+	}
+
+	status := fixture.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	wrapper := c.WrapHandler(func(ctx *gin.Context) {
+		ctx.Data(status, "application/json; charset=utf-8", body)
+	}).Returns(reflect.New(responseType).Elem().Interface())
+	if registered != nil && registered.RequestType != nil {
+		wrapper.Accepts(reflect.New(registered.RequestType).Elem().Interface())
+	}
+	return wrapper.ToHandlerFunc(fixture.Method, fixture.Path), nil
+}
@@ -0,0 +1,118 @@
+package epoch
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type mockUser struct {
+	ID       int    `json:"id"`
+	FullName string `json:"full_name"`
+}
+
+var _ = Describe("Mock server", func() {
+	var (
+		v1, v2        *Version
+		epochInstance *Epoch
+	)
+
+	BeforeEach(func() {
+		v1, _ = NewDateVersion("2024-01-01")
+		v2, _ = NewDateVersion("2025-01-01")
+		change := NewVersionChangeBuilder(v1, v2).
+			ForType(mockUser{}).
+			ResponseToPreviousVersion().
+			RenameField("full_name", "name").
+			Build()
+		var err error
+		epochInstance, err = NewEpoch().WithVersions(v1, v2).WithHeadVersion().WithChanges(change).Build()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	get := func(server *httptest.Server, path, version string) (int, string) {
+		req, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		Expect(err).NotTo(HaveOccurred())
+		req.Header.Set("X-API-Version", version)
+		resp, err := server.Client().Do(req)
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		return resp.StatusCode, string(body)
+	}
+
+	It("should serve fixtures migrated to the requested version", func() {
+		handler, err := epochInstance.MockServer(
+			MockFixture{Method: "GET", Path: "/users/:id", Body: mockUser{ID: 1, FullName: "Ada"}},
+			MockFixture{Method: "GET", Path: "/users", Body: []mockUser{{ID: 1, FullName: "Ada"}}},
+		)
+		Expect(err).NotTo(HaveOccurred())
+		server := httptest.NewServer(handler)
+		defer server.Close()
+
+		status, body := get(server, "/users/1", "2024-01-01")
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(body).To(MatchJSON(`{"id":1,"name":"Ada"}`))
+
+		_, body = get(server, "/users/2", "head")
+		Expect(body).To(MatchJSON(`{"id":1,"full_name":"Ada"}`))
+
+		_, body = get(server, "/users", "2024-01-01")
+		Expect(body).To(MatchJSON(`[{"id":1,"name":"Ada"}]`))
+
+		status, _ = get(server, "/orders", "2024-01-01")
+		Expect(status).To(Equal(http.StatusNotFound))
+	})
+
+	It("should migrate raw JSON as the Returns type with the fixture's status", func() {
+		handler, err := epochInstance.MockServer(MockFixture{
+			Method:  "POST",
+			Path:    "/users",
+			Status:  http.StatusCreated,
+			Body:    json.RawMessage(`{"id":2,"full_name":"Grace"}`),
+			Returns: mockUser{},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		server := httptest.NewServer(handler)
+		defer server.Close()
+
+		req, _ := http.NewRequest(http.MethodPost, server.URL+"/users", nil)
+		req.Header.Set("X-API-Version", "2024-01-01")
+		resp, err := server.Client().Do(req)
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		Expect(resp.StatusCode).To(Equal(http.StatusCreated))
+		Expect(string(body)).To(MatchJSON(`{"id":2,"name":"Grace"}`))
+	})
+
+	It("should type raw JSON from an endpoint already registered", func() {
+		epochInstance.WrapHandler(nil).Returns(mockUser{}).ToHandlerFunc("GET", "/me")
+		handler, err := epochInstance.MockServer(MockFixture{Method: "GET", Path: "/me", Body: []byte(`{"id":3,"full_name":"Ken"}`)})
+		Expect(err).NotTo(HaveOccurred())
+		server := httptest.NewServer(handler)
+		defer server.Close()
+
+		_, body := get(server, "/me", "2024-01-01")
+		Expect(body).To(MatchJSON(`{"id":3,"name":"Ken"}`))
+	})
+
+	It("should reject fixtures it can't serve", func() {
+		_, err := epochInstance.MockServer(MockFixture{Method: "GET", Path: "/raw", Body: []byte(`{"id":1}`)})
+		Expect(err).To(MatchError(ContainSubstring("raw JSON body needs Returns")))
+
+		_, err = epochInstance.MockServer(MockFixture{Method: "GET", Path: "/bad", Body: []byte(`{`), Returns: mockUser{}})
+		Expect(err).To(MatchError(ContainSubstring("body is not valid JSON")))
+
+		_, err = epochInstance.MockServer(
+			MockFixture{Method: "GET", Path: "/users", Body: mockUser{}},
+			MockFixture{Method: "GET", Path: "/users", Body: mockUser{}},
+		)
+		Expect(err).To(MatchError(ContainSubstring("declared twice")))
+	})
+})
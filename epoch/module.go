@@ -0,0 +1,122 @@
+package epoch
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EpochModule is one package's share of an Epoch configuration: the versions it cares
+// about, its types and changes, and the routes it mounts. Large codebases register each
+// package's module with WithModules instead of configuring everything in one place.
+type EpochModule struct {
+	Name       string
+	Versions   []*Version
+	Types      []interface{}
+	Changes    []*VersionChange
+	ChangeSets []*ChangeSet
+
+	// Routes mounts the module's endpoints once the Epoch is built (see MountModules)
+	Routes func(epochInstance *Epoch, router gin.IRouter)
+}
+
+// WithModules merges the modules into the configuration at Build(). Versions declared by
+// several modules are merged and sorted, and every version a module's change names must be
+// declared by some module. Module changes that skip versions other modules declared start
+// from the version right before their ToVersion.
+func (cb *EpochBuilder) WithModules(modules ...*EpochModule) *EpochBuilder {
+	cb.modules = append(cb.modules, modules...)
+	return cb
+}
+
+// mergeModules folds the modules' versions, types and changes into the builder
+func (cb *EpochBuilder) mergeModules() error {
+	if len(cb.modules) == 0 {
+		return nil
+	}
+
+	names := make(map[string]bool, len(cb.modules))
+	for _, module := range cb.modules {
+		if module.Name == "" {
+			return fmt.Errorf("module must have a name")
+		}
+		if names[module.Name] {
+			return fmt.Errorf("module %q is registered twice", module.Name)
+		}
+		names[module.Name] = true
+
+		for _, v := range module.Versions {
+			if cb.findVersion(v) == nil {
+				cb.versions = append(cb.versions, v)
+			}
+		}
+		cb.WithTypes(module.Types...)
+		cb.WithChangeSets(module.ChangeSets...)
+		for _, change := range module.Changes {
+			if !containsChange(cb.changes, change) {
+				cb.changes = append(cb.changes, change)
+			}
+		}
+	}
+	sort.SliceStable(cb.versions, func(i, j int) bool { return cb.versions[i].IsOlderThan(cb.versions[j]) })
+
+	var versionType *VersionType
+	for _, v := range cb.versions {
+		if v.IsHead {
+			continue
+		}
+		if versionType == nil {
+			versionType = &v.Type
+		} else if *versionType != v.Type {
+			return fmt.Errorf("modules mix %s and %s versions", *versionType, v.Type)
+		}
+	}
+
+	for _, module := range cb.modules {
+		changes := append([]*VersionChange(nil), module.Changes...)
+		for _, set := range module.ChangeSets {
+			changes = append(changes, set.changes...)
+		}
+		for _, change := range changes {
+			for _, v := range []*Version{change.FromVersion(), change.ToVersion()} {
+				if !v.IsHead && cb.findVersion(v) == nil {
+					return fmt.Errorf("module %q: change '%s' uses version %s, which no module declares",
+						module.Name, change.Description(), v)
+				}
+			}
+			cb.rebaseChange(change)
+		}
+	}
+	return nil
+}
+
+// rebaseChange moves a change's FromVersion up to the merged version right before its
+// ToVersion. A module only knows its own versions, so its v1→v3 change skips the v2 another
+// module added; the chain steps between adjacent versions, and the type didn't change at v2.
+func (cb *EpochBuilder) rebaseChange(change *VersionChange) {
+	for _, v := range cb.versions {
+		if !v.IsHead && v.IsNewerThan(change.fromVersion) && v.IsOlderThan(change.toVersion) {
+			change.fromVersion = v
+		}
+	}
+}
+
+// findVersion returns the builder's version equal to v, or nil
+func (cb *EpochBuilder) findVersion(v *Version) *Version {
+	for _, existing := range cb.versions {
+		if existing.Equal(v) {
+			return existing
+		}
+	}
+	return nil
+}
+
+// MountModules mounts the routes of every WithModules module on router, in registration order
+func (c *Epoch) MountModules(router gin.IRouter) {
+	for _, module := range c.modules {
+		if module.Routes != nil {
+			module.Routes(c, router)
+		}
+	}
+}
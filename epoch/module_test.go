@@ -0,0 +1,120 @@
+package epoch
+
+import (
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type moduleOrder struct {
+	ID     int    `json:"id"`
+	Amount int    `json:"amount"`
+	Status string `json:"status"`
+}
+
+type moduleInvoice struct {
+	ID    int    `json:"id"`
+	Total int    `json:"total"`
+	Payer string `json:"payer"`
+}
+
+var _ = Describe("Modules", func() {
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+	})
+
+	// Each module builds its own Version values, as separate packages would
+	ordersModule := func() *EpochModule {
+		v1, _ := NewDateVersion("2024-01-01")
+		v2, _ := NewDateVersion("2024-06-01")
+		return &EpochModule{
+			Name:     "orders",
+			Versions: []*Version{v2, v1},
+			Types:    []interface{}{moduleOrder{}},
+			Changes: []*VersionChange{NewVersionChangeBuilder(v1, v2).
+				ForType(moduleOrder{}).
+				ResponseToPreviousVersion().
+				RenameField("amount", "price").
+				Build()},
+			Routes: func(e *Epoch, r gin.IRouter) {
+				r.GET("/orders/:id", e.WrapHandler(func(c *gin.Context) {
+					c.JSON(200, moduleOrder{ID: 1, Amount: 42, Status: "paid"})
+				}).Returns(moduleOrder{}).ToHandlerFunc("GET", "/orders/:id"))
+			},
+		}
+	}
+
+	billingModule := func() *EpochModule {
+		v1, _ := NewDateVersion("2024-01-01")
+		v3, _ := NewDateVersion("2025-01-01")
+		return &EpochModule{
+			Name:     "billing",
+			Versions: []*Version{v1, v3},
+			Changes: []*VersionChange{NewVersionChangeBuilder(v1, v3).
+				ForType(moduleInvoice{}).
+				ResponseToPreviousVersion().
+				RemoveField("payer").
+				Build()},
+			Routes: func(e *Epoch, r gin.IRouter) {
+				r.GET("/invoices/:id", e.WrapHandler(func(c *gin.Context) {
+					c.JSON(200, moduleInvoice{ID: 7, Total: 10, Payer: "Ada"})
+				}).Returns(moduleInvoice{}).ToHandlerFunc("GET", "/invoices/:id"))
+			},
+		}
+	}
+
+	get := func(router *gin.Engine, path, version string) string {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("X-API-Version", version)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder.Body.String()
+	}
+
+	It("should merge versions, types and changes and mount every module's routes", func() {
+		epochInstance, err := NewEpoch().
+			WithModules(ordersModule(), billingModule()).
+			WithHeadVersion().
+			Build()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(epochInstance.VersionBundle().GetVersionValues()).To(Equal([]string{"2024-01-01", "2024-06-01", "2025-01-01", "head"}))
+		Expect(epochInstance.types).To(ContainElement(typeOf(moduleOrder{})))
+
+		router := gin.New()
+		router.Use(epochInstance.Middleware())
+		epochInstance.MountModules(router)
+
+		Expect(get(router, "/orders/1", "2024-01-01")).To(MatchJSON(`{"id":1,"price":42,"status":"paid"}`))
+		Expect(get(router, "/orders/1", "2024-06-01")).To(MatchJSON(`{"id":1,"amount":42,"status":"paid"}`))
+		Expect(get(router, "/invoices/7", "2024-06-01")).To(MatchJSON(`{"id":7,"total":10}`))
+		Expect(get(router, "/invoices/7", "2025-01-01")).To(MatchJSON(`{"id":7,"total":10,"payer":"Ada"}`))
+	})
+
+	It("should reject changes using versions no module declares", func() {
+		v1, _ := NewDateVersion("2024-01-01")
+		v9, _ := NewDateVersion("2024-09-01")
+		_, err := NewEpoch().WithModules(ordersModule(), &EpochModule{
+			Name: "search",
+			Changes: []*VersionChange{NewVersionChangeBuilder(v1, v9).
+				ForType(moduleOrder{}).
+				ResponseToPreviousVersion().
+				RemoveField("status").
+				Build()},
+		}).WithHeadVersion().Build()
+		Expect(err).To(MatchError(ContainSubstring(`module "search": change 'Migration from 2024-01-01 to 2024-09-01' uses version 2024-09-01, which no module declares`)))
+	})
+
+	It("should reject unnamed, duplicate and mixed-format modules", func() {
+		_, err := NewEpoch().WithModules(&EpochModule{}).Build()
+		Expect(err).To(MatchError(ContainSubstring("module must have a name")))
+
+		_, err = NewEpoch().WithModules(ordersModule(), ordersModule()).Build()
+		Expect(err).To(MatchError(ContainSubstring(`module "orders" is registered twice`)))
+
+		semver, _ := NewSemverVersion("2.0.0")
+		_, err = NewEpoch().WithModules(ordersModule(), &EpochModule{Name: "legacy", Versions: []*Version{semver}}).Build()
+		Expect(err).To(MatchError(ContainSubstring("modules mix date and semver versions")))
+	})
+})
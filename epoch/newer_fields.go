@@ -0,0 +1,138 @@
+package epoch
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/astronomer/epoch/epoch/jsonast"
+)
+
+// NewerFieldPolicy decides what happens to request fields that only exist in versions newer
+// than the client's, e.g. "phone" sent by a client pinned to a version before it was added.
+// The zero value leaves the decision to the Epoch, which passes them through by default.
+type NewerFieldPolicy int
+
+const (
+	// NewerFieldsPassThrough hands newer fields to the handler as sent
+	NewerFieldsPassThrough NewerFieldPolicy = iota + 1
+	// NewerFieldsReject answers 400, listing the newer fields
+	NewerFieldsReject
+	// NewerFieldsStrip drops newer fields and names them in MigrationWarningHeader
+	NewerFieldsStrip
+)
+
+// NewerFieldsError reports request fields that don't exist yet in the client's version
+type NewerFieldsError struct {
+	Version *Version
+	Fields  []string
+}
+
+func (e *NewerFieldsError) Error() string {
+	return fmt.Sprintf("fields %s don't exist in version %s", strings.Join(e.Fields, ", "), e.Version)
+}
+
+// WithNewerRequestFields sets what every wrapped handler does with request fields that only
+// exist in versions newer than the client's. Endpoints can override it with
+// HandlerWrapper.WithNewerRequestFields.
+func (cb *EpochBuilder) WithNewerRequestFields(policy NewerFieldPolicy) *EpochBuilder {
+	cb.newerFields = policy
+	return cb
+}
+
+// WithNewerRequestFields sets what this endpoint does with request fields that only exist in
+// versions newer than the client's, overriding the Epoch's WithNewerRequestFields
+func (hw *HandlerWrapper) WithNewerRequestFields(policy NewerFieldPolicy) *HandlerWrapper {
+	hw.newerFields = policy
+	return hw
+}
+
+// WithNewerRequestFields sets what the handler does with request fields that only exist in
+// versions newer than the client's
+func (vah *VersionAwareHandler) WithNewerRequestFields(policy NewerFieldPolicy) *VersionAwareHandler {
+	vah.newerFields = policy
+	return vah
+}
+
+// handleNewerRequestFields applies the newer field policy to a parsed request body, returning
+// a *NewerFieldsError when it rejects the body and the warning to send when it strips it
+func (vah *VersionAwareHandler) handleNewerRequestFields(body *jsonast.Node, requestType reflect.Type, version *Version) (string, error) {
+	if vah.newerFields != NewerFieldsReject && vah.newerFields != NewerFieldsStrip {
+		return "", nil
+	}
+	for requestType != nil && requestType.Kind() == reflect.Ptr {
+		requestType = requestType.Elem()
+	}
+	if requestType == nil || requestType.Kind() != reflect.Struct || body.TypeSafe() != jsonast.V_OBJECT {
+		return "", nil
+	}
+
+	newer := vah.migrationChain.newerRequestFields(requestType, version, vah.versionBundle.GetHeadVersion())
+	var sent []string
+	for _, name := range newer {
+		if body.Get(name).Exists() {
+			sent = append(sent, name)
+		}
+	}
+	if len(sent) == 0 {
+		return "", nil
+	}
+	if vah.newerFields == NewerFieldsReject {
+		return "", &NewerFieldsError{Version: version, Fields: sent}
+	}
+	for _, name := range sent {
+		if _, err := body.Unset(name); err != nil {
+			return "", fmt.Errorf("failed to strip field %s: %w", name, err)
+		}
+	}
+	return fmt.Sprintf(`199 epoch "Ignored fields that don't exist in version %s: %s"`, version, strings.Join(sent, ", ")), nil
+}
+
+// newerRequestFields returns, sorted, the top-level request fields of t that exist in some
+// version newer than from but not in from itself. It walks t's HEAD fields down to from,
+// undoing each request operation.
+func (mc *MigrationChain) newerRequestFields(t reflect.Type, from, head *Version) []string {
+	fields := make(map[string]bool)
+	for _, field := range orderedJSONFields(t) {
+		fields[field.name] = true
+	}
+	// seen collects the fields of every version on the way down
+	seen := make(map[string]bool, len(fields))
+	for name := range fields {
+		seen[name] = true
+	}
+
+	path := mc.GetMigrationPath(from, head)
+	sort.SliceStable(path, func(i, j int) bool {
+		return path[i].FromVersion().IsNewerThan(path[j].FromVersion())
+	})
+	for _, change := range path {
+		ops, _ := change.GetRequestOperationsByType(t)
+		for i := len(ops) - 1; i >= 0; i-- {
+			doc := DescribeOperation(ops[i]).Inverse()
+			for _, name := range doc.RemovedFields {
+				delete(fields, name)
+			}
+			for newer, older := range doc.RenamedFields {
+				delete(fields, newer)
+				fields[older] = true
+			}
+			for name := range doc.AddedFields {
+				fields[name] = true
+			}
+		}
+		for name := range fields {
+			seen[name] = true
+		}
+	}
+
+	var newer []string
+	for name := range seen {
+		if !fields[name] {
+			newer = append(newer, name)
+		}
+	}
+	sort.Strings(newer)
+	return newer
+}
@@ -0,0 +1,131 @@
+package epoch
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type newerFieldsUser struct {
+	FullName string `json:"full_name"`
+	Phone    string `json:"phone"`
+	Email    string `json:"email"`
+}
+
+var _ = Describe("Newer request fields", func() {
+	var (
+		v1, v2, v3 *Version
+		received   string
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		v1, _ = NewDateVersion("2024-01-01")
+		v2, _ = NewDateVersion("2024-06-01")
+		v3, _ = NewDateVersion("2025-01-01")
+		received = ""
+	})
+
+	setup := func(builder *EpochBuilder, wrap func(*HandlerWrapper) *HandlerWrapper) *gin.Engine {
+		epochInstance, err := builder.
+			WithVersions(v1, v2, v3).
+			WithHeadVersion().
+			WithChanges(
+				NewVersionChangeBuilder(v1, v2).
+					ForType(newerFieldsUser{}).
+					RequestToNextVersion().
+					RenameField("name", "full_name").
+					AddField("phone", "").
+					Build(),
+				NewVersionChangeBuilder(v2, v3).
+					ForType(newerFieldsUser{}).
+					RequestToNextVersion().
+					AddField("email", "").
+					Build(),
+			).
+			Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		router := gin.New()
+		router.Use(epochInstance.Middleware())
+		wrapper := epochInstance.WrapHandler(func(c *gin.Context) {
+			body, _ := io.ReadAll(c.Request.Body)
+			received = string(body)
+			c.Status(http.StatusNoContent)
+		}).Accepts(newerFieldsUser{})
+		if wrap != nil {
+			wrapper = wrap(wrapper)
+		}
+		router.POST("/users", wrapper.ToHandlerFunc("POST", "/users"))
+		return router
+	}
+
+	post := func(router *gin.Engine, version, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Version", version)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	It("should find fields every newer version has but the client's doesn't", func() {
+		epochInstance, err := NewEpoch().WithVersions(v1, v2, v3).WithHeadVersion().WithChanges(
+			NewVersionChangeBuilder(v1, v2).
+				ForType(newerFieldsUser{}).
+				RequestToNextVersion().
+				RenameField("name", "display_name").
+				Build(),
+			NewVersionChangeBuilder(v2, v3).
+				ForType(newerFieldsUser{}).
+				RequestToNextVersion().
+				RenameField("display_name", "full_name").
+				Build(),
+		).Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		chain := epochInstance.migrationChain
+		head := epochInstance.VersionBundle().GetHeadVersion()
+		userType := reflect.TypeOf(newerFieldsUser{})
+		Expect(chain.newerRequestFields(userType, v1, head)).To(Equal([]string{"display_name", "full_name"}))
+		Expect(chain.newerRequestFields(userType, v2, head)).To(Equal([]string{"full_name"}))
+		Expect(chain.newerRequestFields(userType, v3, head)).To(BeEmpty())
+	})
+
+	It("should pass newer fields through by default", func() {
+		recorder := post(setup(NewEpoch(), nil), "2024-01-01", `{"name":"Ada","email":"a@b.c"}`)
+		Expect(recorder.Code).To(Equal(http.StatusNoContent))
+		Expect(received).To(MatchJSON(`{"full_name":"Ada","email":"a@b.c","phone":""}`))
+	})
+
+	It("should reject newer fields when configured", func() {
+		router := setup(NewEpoch().WithNewerRequestFields(NewerFieldsReject), nil)
+
+		recorder := post(router, "2024-01-01", `{"name":"Ada","phone":"555","email":"a@b.c"}`)
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		Expect(recorder.Body.String()).To(MatchJSON(
+			`{"error":"fields email, phone don't exist in version 2024-01-01","fields":["email","phone"]}`))
+		Expect(received).To(BeEmpty())
+
+		recorder = post(router, "2024-06-01", `{"full_name":"Ada","phone":"555"}`)
+		Expect(recorder.Code).To(Equal(http.StatusNoContent))
+	})
+
+	It("should strip newer fields with a warning, with endpoints overriding the Epoch", func() {
+		router := setup(NewEpoch().WithNewerRequestFields(NewerFieldsReject), func(hw *HandlerWrapper) *HandlerWrapper {
+			return hw.WithNewerRequestFields(NewerFieldsStrip)
+		})
+
+		recorder := post(router, "2024-06-01", `{"full_name":"Ada","phone":"555","email":"a@b.c"}`)
+		Expect(recorder.Code).To(Equal(http.StatusNoContent))
+		Expect(received).To(MatchJSON(`{"full_name":"Ada","phone":"555","email":""}`))
+		Expect(recorder.Header().Get(MigrationWarningHeader)).To(Equal(
+			`199 epoch "Ignored fields that don't exist in version 2024-06-01: email"`))
+	})
+})
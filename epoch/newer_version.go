@@ -0,0 +1,82 @@
+package epoch
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NewerVersionPolicy decides how requests for a version newer than every registered version
+// are served, e.g. a client sending tomorrow's date. The zero value falls back to the newest
+// registered version, as for any unregistered version newer than it.
+type NewerVersionPolicy int
+
+const (
+	// NewerVersionFallBack serves the closest older version, the newest registered one
+	NewerVersionFallBack NewerVersionPolicy = iota + 1
+	// NewerVersionReject answers 400, listing the supported versions
+	NewerVersionReject
+	// NewerVersionClampToHead serves head, or the newest version when head is internal
+	NewerVersionClampToHead
+	// NewerVersionPreview serves the newest registered version, including versions
+	// WithUnreleasedVersion hides from requests without their preview header
+	NewerVersionPreview
+)
+
+// WithUnknownNewerVersionPolicy sets how requests for a version newer than every registered
+// version are served. NewerVersionPreview needs a WithUnreleasedVersion version.
+func (cb *EpochBuilder) WithUnknownNewerVersionPolicy(policy NewerVersionPolicy) *EpochBuilder {
+	cb.newerVersions = policy
+	return cb
+}
+
+// checkNewerVersionPolicy validates the newer version policy at Build()
+func (cb *EpochBuilder) checkNewerVersionPolicy() error {
+	if cb.newerVersions == NewerVersionPreview && len(cb.unreleased) == 0 {
+		return errors.New("NewerVersionPreview needs a version registered with WithUnreleasedVersion")
+	}
+	return nil
+}
+
+// newestVersion returns the newest registered non-head version, or nil if there's none
+func (vm *VersionMiddleware) newestVersion() *Version {
+	var newest *Version
+	for _, v := range vm.versionBundle.GetVersions() {
+		if !v.IsHead && (newest == nil || v.IsNewerThan(newest)) {
+			newest = v
+		}
+	}
+	return newest
+}
+
+// resolveNewerVersion applies the newer version policy to an unregistered version string. It
+// reports whether the policy handled it: with the version to serve and whether that version
+// skips the preview cutoff, or with nil once the request was rejected.
+func (vm *VersionMiddleware) resolveNewerVersion(c *gin.Context, versionStr string) (version *Version, preview, handled bool) {
+	if vm.newerVersions == 0 || vm.newerVersions == NewerVersionFallBack {
+		return nil, false, false
+	}
+	requested, err := NewVersion(versionStr)
+	newest := vm.newestVersion()
+	if err != nil || newest == nil || requested.Type != newest.Type || !requested.IsNewerThan(newest) {
+		return nil, false, false
+	}
+
+	switch vm.newerVersions {
+	case NewerVersionReject:
+		c.JSON(http.StatusBadRequest, withCorrelationID(c, gin.H{
+			"error":              fmt.Sprintf("Version %s is newer than every supported version", versionStr),
+			"latest_version":     vm.releasedVersion(c, newest).String(),
+			"available_versions": vm.visibleVersionValues(c),
+		}))
+		c.Abort()
+		return nil, false, true
+	case NewerVersionClampToHead:
+		return vm.defaultHeadVersion(), false, true
+	case NewerVersionPreview:
+		return newest, true, true
+	}
+	return nil, false, false
+}
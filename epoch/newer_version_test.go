@@ -0,0 +1,96 @@
+package epoch
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type futureUser struct {
+	ID       int    `json:"id"`
+	FullName string `json:"full_name"`
+}
+
+var _ = Describe("Unknown newer versions", func() {
+	var v1, v2 *Version
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		v1, _ = NewDateVersion("2024-01-01")
+		v2, _ = NewDateVersion("2025-01-01")
+	})
+
+	build := func(builder *EpochBuilder) *gin.Engine {
+		change := NewVersionChangeBuilder(v1, v2).
+			ForType(futureUser{}).
+			ResponseToPreviousVersion().
+			RenameField("full_name", "name").
+			Build()
+		epochInstance, err := builder.WithVersions(v1, v2).WithHeadVersion().WithChanges(change).
+			WithClock(ClockFunc(func() time.Time { return time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC) })).
+			Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		router := gin.New()
+		router.Use(epochInstance.Middleware())
+		router.GET("/users/:id", epochInstance.WrapHandler(func(c *gin.Context) {
+			c.JSON(http.StatusOK, futureUser{ID: 1, FullName: "Ada"})
+		}).Returns(futureUser{}).ToHandlerFunc("GET", "/users/:id"))
+		return router
+	}
+
+	get := func(router *gin.Engine, version string, headers ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+		req.Header.Set("X-API-Version", version)
+		for i := 0; i+1 < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	It("should fall back to the newest version by default", func() {
+		recorder := get(build(NewEpoch()), "2030-01-01")
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Header().Get("X-API-Version")).To(Equal("2025-01-01"))
+	})
+
+	It("should reject newer versions, listing the supported ones", func() {
+		router := build(NewEpoch().WithUnknownNewerVersionPolicy(NewerVersionReject))
+		recorder := get(router, "2030-01-01")
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		Expect(recorder.Body.String()).To(MatchJSON(`{
+			"error": "Version 2030-01-01 is newer than every supported version",
+			"latest_version": "2025-01-01",
+			"available_versions": ["2024-01-01", "2025-01-01", "head"]
+		}`))
+
+		// Unregistered versions between registered ones still fall back
+		Expect(get(router, "2024-06-01").Header().Get("X-API-Version")).To(Equal("2024-01-01"))
+	})
+
+	It("should clamp newer versions to head", func() {
+		recorder := get(build(NewEpoch().WithUnknownNewerVersionPolicy(NewerVersionClampToHead)), "2030-01-01")
+		Expect(recorder.Header().Get("X-API-Version")).To(Equal("head"))
+		Expect(recorder.Body.String()).To(MatchJSON(`{"id":1,"full_name":"Ada"}`))
+	})
+
+	It("should route newer versions to the unreleased version", func() {
+		router := build(NewEpoch().
+			WithUnknownNewerVersionPolicy(NewerVersionPreview).
+			WithUnreleasedVersion(v2, "X-API-Preview"))
+		Expect(get(router, "2030-01-01").Header().Get("X-API-Version")).To(Equal("2025-01-01"))
+		Expect(get(router, "2025-01-01").Header().Get("X-API-Version")).To(Equal("2024-01-01"))
+	})
+
+	It("should require an unreleased version for the preview policy", func() {
+		_, err := NewEpoch().WithVersions(v1).WithHeadVersion().
+			WithUnknownNewerVersionPolicy(NewerVersionPreview).Build()
+		Expect(err).To(MatchError(ContainSubstring("needs a version registered with WithUnreleasedVersion")))
+	})
+})
@@ -0,0 +1,104 @@
+package epoch
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type preciseAccount struct {
+	ID      int64   `json:"id"`
+	Limit   uint64  `json:"limit"`
+	Rate    float64 `json:"rate"`
+	Balance string  `json:"balance"`
+}
+
+type preciseAccountRequest struct {
+	Name string `json:"name"`
+}
+
+var _ = Describe("Number precision", func() {
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+	})
+
+	const (
+		largeInt64  = `9007199254740993`
+		maxUint64   = `18446744073709551615`
+		preciseRate = `0.1000000000000000055511151231257827`
+		decimal     = `"12345678901234567890.123456789"`
+	)
+
+	It("should keep numbers exact when helpers move and copy them", func() {
+		for _, engine := range jsonEngines {
+			node, err := engine.Parse([]byte(`{"id":` + largeInt64 + `,"limit":` + maxUint64 + `,"rate":` + preciseRate + `}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(RenameNodeField(node, "id", "account_id")).To(Succeed())
+			copied, err := engine.Parse([]byte(`{}`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(CopyNodeField(node, copied, "limit")).To(Succeed())
+			Expect(CopyNodeField(node, copied, "rate")).To(Succeed())
+
+			raw, err := engine.Serialize(node)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(raw)).To(Equal(`{"account_id":`+largeInt64+`,"limit":`+maxUint64+`,"rate":`+preciseRate+`}`), engine.Name())
+
+			raw, err = engine.Serialize(copied)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(raw)).To(Equal(`{"limit":`+maxUint64+`,"rate":`+preciseRate+`}`), engine.Name())
+		}
+	})
+
+	serve := func(engine JSONEngine, body string) string {
+		v1, v2, _ := newTestVersions()
+		changes := []*VersionChange{
+			NewVersionChangeBuilder(v1, v2).
+				ForType(preciseAccountRequest{}).
+				RequestToNextVersion().
+				RemoveField("legacy_id").
+				ForType(preciseAccount{}).
+				ResponseToPreviousVersion().
+				RenameField("id", "account_id").
+				AddField("legacy_id", 0).
+				Build(),
+		}
+		epochInstance, err := setupHeadEpoch([]*Version{v1, v2}, changes, func(builder *EpochBuilder) *EpochBuilder {
+			return builder.WithJSONEngine(engine)
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		router := setupRouterWithMiddleware(epochInstance)
+		router.POST("/accounts", epochInstance.WrapHandler(func(c *gin.Context) {
+			c.Data(200, "application/json", []byte(`{"id":`+largeInt64+`,"limit":`+maxUint64+
+				`,"rate":`+preciseRate+`,"balance":`+decimal+`}`))
+		}).Accepts(preciseAccountRequest{}).Returns(preciseAccount{}).ToHandlerFunc("POST", "/accounts"))
+
+		req := httptest.NewRequest("POST", "/accounts", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		recorder := serveVersioned(router, req, "2024-01-01")
+		Expect(recorder.Code).To(Equal(200))
+		return recorder.Body.String()
+	}
+
+	It("should round-trip int64, uint64 and decimal values through migration", func() {
+		for _, engine := range jsonEngines {
+			body := serve(engine, `{"name":"Ada","legacy_id":`+maxUint64+`}`)
+
+			Expect(body).To(ContainSubstring(`"account_id":` + largeInt64))
+			Expect(body).To(ContainSubstring(`"limit":` + maxUint64))
+			Expect(body).To(ContainSubstring(`"rate":` + preciseRate))
+			Expect(body).To(ContainSubstring(`"balance":` + decimal))
+			// The captured request value is restored exactly
+			Expect(body).To(ContainSubstring(`"legacy_id":` + maxUint64))
+
+			var decoded map[string]json.Number
+			Expect(json.Unmarshal([]byte(strings.Replace(body, decimal, "0", 1)), &decoded)).To(Succeed())
+			Expect(decoded["account_id"].String()).To(Equal(largeInt64), engine.Name())
+		}
+	})
+})
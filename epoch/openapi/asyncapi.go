@@ -0,0 +1,282 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+
+	"github.com/astronomer/epoch/epoch"
+	"gopkg.in/yaml.v3"
+)
+
+// AsyncAPIVersion is the AsyncAPI specification version of generated documents
+const AsyncAPIVersion = "2.6.0"
+
+// AsyncAPIConfig configures an AsyncAPIGenerator
+type AsyncAPIConfig struct {
+	// VersionBundle contains all versions and the changes migrating event payloads
+	VersionBundle *epoch.VersionBundle
+
+	// Title is the info.title of every document (default "Events")
+	Title string
+
+	// OutputFormat specifies the output format ("yaml" or "json")
+	OutputFormat string
+}
+
+// EventDefinition describes an event type sent over a channel
+type EventDefinition struct {
+	// Channel is the channel the event is sent on, e.g. "users.created"
+	Channel string
+
+	// Payload is a value of the event's HEAD payload type, e.g. UserCreated{}
+	Payload interface{}
+
+	// Name names the message; defaults to the payload's schema name
+	Name string
+
+	// Summary describes the event
+	Summary string
+
+	// Incoming marks events clients send to the API, which are migrated like requests
+	// (AsyncAPI publish operations). Events the API sends are migrated like responses
+	// and documented as subscribe operations.
+	Incoming bool
+}
+
+// AsyncAPIGenerator generates versioned AsyncAPI documents for event types, the way
+// SchemaGenerator generates OpenAPI specs for endpoint types. Changes registered with
+// ForType() apply to event payloads like they do to request and response bodies.
+type AsyncAPIGenerator struct {
+	config AsyncAPIConfig
+	events []EventDefinition
+}
+
+// AsyncAPIDocument is an AsyncAPI 2.6 document
+type AsyncAPIDocument struct {
+	AsyncAPI           string                      `json:"asyncapi" yaml:"asyncapi"`
+	Info               AsyncAPIInfo                `json:"info" yaml:"info"`
+	DefaultContentType string                      `json:"defaultContentType" yaml:"defaultContentType"`
+	Channels           map[string]*AsyncAPIChannel `json:"channels" yaml:"channels"`
+	Components         AsyncAPIComponents          `json:"components" yaml:"components"`
+}
+
+// AsyncAPIInfo is the info object of an AsyncAPI document
+type AsyncAPIInfo struct {
+	Title   string `json:"title" yaml:"title"`
+	Version string `json:"version" yaml:"version"`
+}
+
+// AsyncAPIChannel is a channel item; subscribe documents events the API sends,
+// publish documents events clients send
+type AsyncAPIChannel struct {
+	Subscribe *AsyncAPIOperation `json:"subscribe,omitempty" yaml:"subscribe,omitempty"`
+	Publish   *AsyncAPIOperation `json:"publish,omitempty" yaml:"publish,omitempty"`
+}
+
+// AsyncAPIOperation is an operation of a channel
+type AsyncAPIOperation struct {
+	Message AsyncAPIMessageRef `json:"message" yaml:"message"`
+}
+
+// AsyncAPIMessageRef references one message, or several with OneOf when a channel carries
+// more than one event type
+type AsyncAPIMessageRef struct {
+	Ref   string               `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	OneOf []AsyncAPIMessageRef `json:"oneOf,omitempty" yaml:"oneOf,omitempty"`
+}
+
+// AsyncAPIComponents holds the messages and payload schemas of a document
+type AsyncAPIComponents struct {
+	Messages map[string]*AsyncAPIMessage       `json:"messages" yaml:"messages"`
+	Schemas  map[string]map[string]interface{} `json:"schemas" yaml:"schemas"`
+}
+
+// AsyncAPIMessage describes an event message
+type AsyncAPIMessage struct {
+	Name    string             `json:"name" yaml:"name"`
+	Summary string             `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Payload AsyncAPIMessageRef `json:"payload" yaml:"payload"`
+}
+
+// NewAsyncAPIGenerator creates a new AsyncAPI generator
+func NewAsyncAPIGenerator(config AsyncAPIConfig) *AsyncAPIGenerator {
+	if config.Title == "" {
+		config.Title = "Events"
+	}
+	if config.OutputFormat != "json" {
+		config.OutputFormat = "yaml"
+	}
+	return &AsyncAPIGenerator{config: config}
+}
+
+// WithEvent registers an event type to document
+func (g *AsyncAPIGenerator) WithEvent(event EventDefinition) *AsyncAPIGenerator {
+	g.events = append(g.events, event)
+	return g
+}
+
+// GenerateVersionedDocuments generates an AsyncAPI document for HEAD and every version,
+// keyed by version
+func (g *AsyncAPIGenerator) GenerateVersionedDocuments() (map[string]*AsyncAPIDocument, error) {
+	sg, payloadTypes, err := g.schemaGenerator()
+	if err != nil {
+		return nil, err
+	}
+
+	versions := append([]*epoch.Version{g.config.VersionBundle.GetHeadVersion()}, g.config.VersionBundle.GetVersions()...)
+	documents := make(map[string]*AsyncAPIDocument, len(versions))
+	for _, version := range versions {
+		document, err := g.generateDocument(sg, payloadTypes, version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate AsyncAPI document for version %s: %w", version.String(), err)
+		}
+		documents[version.String()] = document
+	}
+	return documents, nil
+}
+
+// schemaGenerator returns a SchemaGenerator whose registry holds the events' payload types,
+// registered as responses or, for incoming events, requests so they migrate the same way
+func (g *AsyncAPIGenerator) schemaGenerator() (*SchemaGenerator, []reflect.Type, error) {
+	registry := epoch.NewEndpointRegistry()
+	payloadTypes := make([]reflect.Type, len(g.events))
+	for i, event := range g.events {
+		if event.Channel == "" {
+			return nil, nil, fmt.Errorf("event %d has no channel", i)
+		}
+		typ := reflect.TypeOf(event.Payload)
+		if typ != nil && typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		if typ == nil || typ.Kind() != reflect.Struct || typ.Name() == "" {
+			return nil, nil, fmt.Errorf("payload of event on channel %s must be a named struct, got %v", event.Channel, typ)
+		}
+		payloadTypes[i] = typ
+
+		definition := &epoch.EndpointDefinition{Method: "EVENT", PathPattern: event.Channel}
+		if event.Incoming {
+			definition.RequestType = typ
+		} else {
+			definition.ResponseType = typ
+		}
+		// Keyed by position: a channel may carry several event types
+		registry.Register(definition.Method, fmt.Sprintf("%s#%d", event.Channel, i), definition)
+	}
+
+	return NewSchemaGenerator(SchemaGeneratorConfig{
+		VersionBundle: g.config.VersionBundle,
+		TypeRegistry:  registry,
+	}), payloadTypes, nil
+}
+
+// generateDocument builds the AsyncAPI document of one version
+func (g *AsyncAPIGenerator) generateDocument(sg *SchemaGenerator, payloadTypes []reflect.Type, version *epoch.Version) (*AsyncAPIDocument, error) {
+	spec, err := sg.generateStandaloneSpec(version)
+	if err != nil {
+		return nil, err
+	}
+
+	document := &AsyncAPIDocument{
+		AsyncAPI:           AsyncAPIVersion,
+		Info:               AsyncAPIInfo{Title: g.config.Title, Version: version.String()},
+		DefaultContentType: "application/json",
+		Channels:           make(map[string]*AsyncAPIChannel),
+		Components: AsyncAPIComponents{
+			Messages: make(map[string]*AsyncAPIMessage),
+			Schemas:  make(map[string]map[string]interface{}, len(spec.Components.Schemas)),
+		},
+	}
+	for name, schemaRef := range spec.Components.Schemas {
+		converted, err := toJSONSchema(schemaRef, componentRefPrefix)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert schema %s: %w", name, err)
+		}
+		document.Components.Schemas[name] = converted
+	}
+
+	for i, event := range g.events {
+		schemaName := sg.componentName(payloadTypes[i])
+		if _, ok := document.Components.Schemas[schemaName]; !ok {
+			return nil, fmt.Errorf("no schema generated for event payload %s", payloadTypes[i].Name())
+		}
+		messageName := event.Name
+		if messageName == "" {
+			messageName = schemaName
+		}
+		if existing, ok := document.Components.Messages[messageName]; ok && existing.Payload.Ref != componentRefPrefix+schemaName {
+			return nil, fmt.Errorf("message %s is used by events with different payloads; give them distinct names", messageName)
+		}
+		document.Components.Messages[messageName] = &AsyncAPIMessage{
+			Name:    messageName,
+			Summary: event.Summary,
+			Payload: AsyncAPIMessageRef{Ref: componentRefPrefix + schemaName},
+		}
+
+		channel := document.Channels[event.Channel]
+		if channel == nil {
+			channel = &AsyncAPIChannel{}
+			document.Channels[event.Channel] = channel
+		}
+		operation := &channel.Subscribe
+		if event.Incoming {
+			operation = &channel.Publish
+		}
+		addAsyncAPIMessage(operation, AsyncAPIMessageRef{Ref: "#/components/messages/" + messageName})
+	}
+	return document, nil
+}
+
+// addAsyncAPIMessage adds a message to an operation, switching to oneOf for the second one
+func addAsyncAPIMessage(operation **AsyncAPIOperation, message AsyncAPIMessageRef) {
+	if *operation == nil {
+		*operation = &AsyncAPIOperation{Message: message}
+		return
+	}
+	current := &(*operation).Message
+	if current.Ref == message.Ref {
+		return
+	}
+	if current.Ref != "" {
+		*current = AsyncAPIMessageRef{OneOf: []AsyncAPIMessageRef{*current}}
+	}
+	for _, existing := range current.OneOf {
+		if existing.Ref == message.Ref {
+			return
+		}
+	}
+	current.OneOf = append(current.OneOf, message)
+	sort.Slice(current.OneOf, func(i, j int) bool { return current.OneOf[i].Ref < current.OneOf[j].Ref })
+}
+
+// MarshalDocument encodes a document in the generator's output format
+func (g *AsyncAPIGenerator) MarshalDocument(document *AsyncAPIDocument) ([]byte, error) {
+	var data []byte
+	var err error
+	if g.config.OutputFormat == "json" {
+		data, err = json.MarshalIndent(document, "", "  ")
+	} else {
+		data, err = yaml.Marshal(document)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal AsyncAPI document: %w", err)
+	}
+	return data, nil
+}
+
+// WriteVersionedDocuments writes documents to files
+// filenamePattern should contain %s for version, e.g., "docs/events_%s.yaml"
+func (g *AsyncAPIGenerator) WriteVersionedDocuments(documents map[string]*AsyncAPIDocument, filenamePattern string) error {
+	for _, version := range sortedKeys(documents) {
+		data, err := g.MarshalDocument(documents[version])
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(fmt.Sprintf(filenamePattern, version), data, 0644); err != nil {
+			return fmt.Errorf("failed to write AsyncAPI document for version %s: %w", version, err)
+		}
+	}
+	return nil
+}
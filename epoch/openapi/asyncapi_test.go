@@ -0,0 +1,127 @@
+package openapi
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/astronomer/epoch/epoch"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
+)
+
+type UserCreatedEvent struct {
+	ID      int          `json:"id"`
+	Email   string       `json:"email"`
+	Address EventAddress `json:"address"`
+}
+
+type UserDeletedEvent struct {
+	ID int `json:"id"`
+}
+
+type EventAddress struct {
+	City string `json:"city"`
+}
+
+type InviteUserCommand struct {
+	Email    string `json:"email"`
+	FullName string `json:"full_name"`
+}
+
+var _ = Describe("AsyncAPI Generation", func() {
+	var generator *AsyncAPIGenerator
+
+	BeforeEach(func() {
+		v1, _ := epoch.NewDateVersion("2024-01-01")
+		v2, _ := epoch.NewDateVersion("2025-01-01")
+		bundle, err := epoch.NewVersionBundle([]*epoch.Version{v1, v2})
+		Expect(err).NotTo(HaveOccurred())
+		v1.Changes = []epoch.VersionChangeInterface{
+			epoch.NewVersionChangeBuilder(v1, v2).
+				ForType(UserCreatedEvent{}).
+				ResponseToPreviousVersion().
+				RemoveField("email").
+				ForType(InviteUserCommand{}).
+				RequestToNextVersion().
+				RenameField("name", "full_name").
+				Build(),
+		}
+
+		generator = NewAsyncAPIGenerator(AsyncAPIConfig{VersionBundle: bundle, Title: "User Events"}).
+			WithEvent(EventDefinition{Channel: "users", Payload: UserCreatedEvent{}, Summary: "A user signed up"}).
+			WithEvent(EventDefinition{Channel: "users", Payload: &UserDeletedEvent{}}).
+			WithEvent(EventDefinition{Channel: "invites", Payload: InviteUserCommand{}, Name: "InviteUser", Incoming: true})
+	})
+
+	It("should document channels and messages of every version", func() {
+		documents, err := generator.GenerateVersionedDocuments()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(documents).To(HaveLen(3))
+
+		head := documents["head"]
+		Expect(head.AsyncAPI).To(Equal(AsyncAPIVersion))
+		Expect(head.Info).To(Equal(AsyncAPIInfo{Title: "User Events", Version: "head"}))
+
+		users := head.Channels["users"]
+		Expect(users.Publish).To(BeNil())
+		Expect(users.Subscribe.Message.OneOf).To(Equal([]AsyncAPIMessageRef{
+			{Ref: "#/components/messages/UserCreatedEvent"},
+			{Ref: "#/components/messages/UserDeletedEvent"},
+		}))
+		Expect(head.Channels["invites"].Publish.Message.Ref).To(Equal("#/components/messages/InviteUser"))
+
+		Expect(head.Components.Messages["UserCreatedEvent"].Summary).To(Equal("A user signed up"))
+		Expect(head.Components.Messages["InviteUser"].Payload.Ref).To(Equal("#/components/schemas/InviteUserCommand"))
+	})
+
+	It("should migrate payload schemas to each version", func() {
+		documents, err := generator.GenerateVersionedDocuments()
+		Expect(err).NotTo(HaveOccurred())
+
+		properties := func(version, schema string) map[string]interface{} {
+			return documents[version].Components.Schemas[schema]["properties"].(map[string]interface{})
+		}
+		Expect(properties("head", "UserCreatedEvent")).To(HaveKey("email"))
+		Expect(properties("2025-01-01", "UserCreatedEvent")).To(HaveKey("email"))
+		Expect(properties("2024-01-01", "UserCreatedEvent")).NotTo(HaveKey("email"))
+
+		Expect(properties("head", "InviteUserCommand")).To(HaveKey("full_name"))
+		Expect(properties("2024-01-01", "InviteUserCommand")).To(HaveKey("name"))
+		Expect(properties("2024-01-01", "InviteUserCommand")).NotTo(HaveKey("full_name"))
+
+		// Nested types become components referenced from the payload
+		Expect(documents["head"].Components.Schemas).To(HaveKey("EventAddress"))
+		Expect(properties("head", "UserCreatedEvent")["address"]).To(Equal(map[string]interface{}{
+			"$ref": "#/components/schemas/EventAddress",
+		}))
+	})
+
+	It("should reject events without a channel or struct payload", func() {
+		bundle, _ := epoch.NewVersionBundle([]*epoch.Version{})
+		_, err := NewAsyncAPIGenerator(AsyncAPIConfig{VersionBundle: bundle}).
+			WithEvent(EventDefinition{Payload: UserDeletedEvent{}}).
+			GenerateVersionedDocuments()
+		Expect(err).To(MatchError(ContainSubstring("has no channel")))
+
+		_, err = NewAsyncAPIGenerator(AsyncAPIConfig{VersionBundle: bundle}).
+			WithEvent(EventDefinition{Channel: "ids", Payload: 42}).
+			GenerateVersionedDocuments()
+		Expect(err).To(MatchError(ContainSubstring("must be a named struct")))
+	})
+
+	It("should write documents to files", func() {
+		documents, err := generator.GenerateVersionedDocuments()
+		Expect(err).NotTo(HaveOccurred())
+
+		pattern := filepath.Join(GinkgoT().TempDir(), "events_%s.yaml")
+		Expect(generator.WriteVersionedDocuments(documents, pattern)).To(Succeed())
+
+		data, err := os.ReadFile(filepath.Join(filepath.Dir(pattern), "events_2024-01-01.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		var document map[string]interface{}
+		Expect(yaml.Unmarshal(data, &document)).To(Succeed())
+		Expect(document["asyncapi"]).To(Equal("2.6.0"))
+		Expect(document["channels"]).To(HaveKey("invites"))
+	})
+})
@@ -0,0 +1,167 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/astronomer/epoch/epoch"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// componentMatchContext is what's known about where an inline schema is nested, to choose
+// between components with the same properties, e.g. Skill and SkillRequest
+type componentMatchContext struct {
+	parent         reflect.Type               // Go type of the schema the inline schema is nested in
+	field          reflect.Type               // Go type of the field the inline schema describes
+	componentTypes map[string]reflect.Type    // Go type of each component generated from one
+	directions     map[reflect.Type]typeUsage // How endpoints use each type
+}
+
+// typeUsage records whether endpoints accept a type, return it, or both, directly or nested
+type typeUsage struct {
+	request, response bool
+}
+
+// forField returns the context of the inline schema of the parent's property, nil-safe
+func (m *componentMatchContext) forField(propName string) *componentMatchContext {
+	if m == nil {
+		return nil
+	}
+	field := *m
+	field.field = nil
+	if m.parent != nil {
+		field.field = jsonFieldType(m.parent, propName)
+	}
+	return &field
+}
+
+// findComponentFor finds the component whose properties match the inline schema's. When
+// several do, it prefers the component generated from the field's Go type, then components
+// used in the same direction as the parent, then the first by name.
+func (sg *SchemaGenerator) findComponentFor(
+	inlineSchema *openapi3.Schema,
+	spec *openapi3.T,
+	match *componentMatchContext,
+) string {
+	if inlineSchema == nil || inlineSchema.Properties == nil {
+		return ""
+	}
+
+	var candidates []string
+	for _, componentName := range sortedSchemaNames(spec.Components.Schemas) {
+		componentRef := spec.Components.Schemas[componentName]
+		if componentRef == nil || componentRef.Value == nil || componentRef.Value.Properties == nil {
+			continue
+		}
+		// Simple heuristic: if property names match, it's likely the same type
+		if sameProperties(inlineSchema.Properties, componentRef.Value.Properties) {
+			candidates = append(candidates, componentName)
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	if len(candidates) == 1 || match == nil {
+		return candidates[0]
+	}
+
+	if match.field != nil {
+		for _, candidate := range candidates {
+			if match.componentTypes[candidate] == match.field {
+				return candidate
+			}
+		}
+	}
+	if usage := match.directions[match.parent]; usage != (typeUsage{}) {
+		for _, candidate := range candidates {
+			if t := match.componentTypes[candidate]; t != nil && match.directions[t] == usage {
+				return candidate
+			}
+		}
+	}
+	return candidates[0]
+}
+
+// sameProperties reports whether two schemas have the same property names
+func sameProperties(a, b openapi3.Schemas) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for propName := range a {
+		if _, ok := b[propName]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// componentTypes maps the components of a version's spec to the Go types they're generated from
+func (sg *SchemaGenerator) componentTypes(baseSpec *openapi3.T, types []reflect.Type, versionKey string) map[string]reflect.Type {
+	componentTypes := make(map[string]reflect.Type)
+	for typ, componentName := range sg.nestedTypeRegistry[versionKey] {
+		componentTypes[componentName] = typ
+	}
+	for _, typ := range types {
+		if typ.Kind() == reflect.Struct {
+			componentTypes[sg.schemaNameForType(baseSpec, typ)] = typ
+		}
+	}
+	return componentTypes
+}
+
+// typeDirections records how registered endpoints use each of their types
+func (sg *SchemaGenerator) typeDirections() map[reflect.Type]typeUsage {
+	directions := make(map[reflect.Type]typeUsage)
+	for _, endpoint := range sg.sortedEndpoints() {
+		for _, t := range epoch.CollectMigratableTypes(endpoint.RequestType) {
+			usage := directions[t]
+			usage.request = true
+			directions[t] = usage
+		}
+		for _, t := range epoch.CollectMigratableTypes(endpoint.ResponseType) {
+			usage := directions[t]
+			usage.response = true
+			directions[t] = usage
+		}
+	}
+	return directions
+}
+
+// jsonFieldType returns the struct type a struct's JSON field holds, through pointers, slices
+// and maps, or nil if it has no such field
+func jsonFieldType(t reflect.Type, name string) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		fieldName := strings.Split(tag, ",")[0]
+		if field.Anonymous && fieldName == "" {
+			if found := jsonFieldType(field.Type, name); found != nil {
+				return found
+			}
+			continue
+		}
+		if fieldName == "" {
+			fieldName = field.Name
+		}
+		if !field.IsExported() || fieldName != name {
+			continue
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr || fieldType.Kind() == reflect.Slice ||
+			fieldType.Kind() == reflect.Array || fieldType.Kind() == reflect.Map {
+			fieldType = fieldType.Elem()
+		}
+		return fieldType
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package openapi
+
+import (
+	"reflect"
+
+	"github.com/astronomer/epoch/epoch"
+	"github.com/getkin/kin-openapi/openapi3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type MatchSkill struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type MatchSkillRequest struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type MatchProfile struct {
+	Skills []MatchSkill `json:"skills"`
+}
+
+type MatchProfileRequest struct {
+	Skill *MatchSkillRequest `json:"skill"`
+}
+
+type MatchSkillPatch struct {
+	Skill struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	} `json:"skill"`
+}
+
+var _ = Describe("Component matching", func() {
+	var (
+		generator *SchemaGenerator
+		spec      *openapi3.T
+		match     *componentMatchContext
+	)
+
+	skillSchema := func() *openapi3.Schema {
+		return openapi3.NewObjectSchema().
+			WithProperty("id", openapi3.NewIntegerSchema()).
+			WithProperty("name", openapi3.NewStringSchema())
+	}
+
+	BeforeEach(func() {
+		v1, _ := epoch.NewDateVersion("2024-01-01")
+		versionBundle, _ := epoch.NewVersionBundle([]*epoch.Version{v1})
+		registry := epoch.NewEndpointRegistry()
+		registry.Register("GET", "/profile", &epoch.EndpointDefinition{
+			Method: "GET", PathPattern: "/profile", ResponseType: reflect.TypeOf(MatchProfile{}),
+		})
+		registry.Register("POST", "/profile", &epoch.EndpointDefinition{
+			Method: "POST", PathPattern: "/profile", RequestType: reflect.TypeOf(MatchProfileRequest{}),
+		})
+		registry.Register("PATCH", "/profile", &epoch.EndpointDefinition{
+			Method: "PATCH", PathPattern: "/profile", RequestType: reflect.TypeOf(MatchSkillPatch{}),
+		})
+		generator = NewSchemaGenerator(SchemaGeneratorConfig{VersionBundle: versionBundle, TypeRegistry: registry})
+
+		spec = &openapi3.T{Components: &openapi3.Components{Schemas: openapi3.Schemas{
+			"Skill":        openapi3.NewSchemaRef("", skillSchema()),
+			"SkillRequest": openapi3.NewSchemaRef("", skillSchema()),
+		}}}
+		match = &componentMatchContext{
+			componentTypes: map[string]reflect.Type{
+				"Skill":        reflect.TypeOf(MatchSkill{}),
+				"SkillRequest": reflect.TypeOf(MatchSkillRequest{}),
+			},
+			directions: generator.typeDirections(),
+		}
+	})
+
+	It("should pick the first matching component by name without context", func() {
+		Expect(generator.findMatchingComponent(skillSchema(), spec)).To(Equal("Skill"))
+	})
+
+	It("should pick the component of the field's Go type", func() {
+		match.parent = reflect.TypeOf(MatchProfileRequest{})
+		Expect(generator.findComponentFor(skillSchema(), spec, match.forField("skill"))).To(Equal("SkillRequest"))
+
+		match.parent = reflect.TypeOf(MatchProfile{})
+		Expect(generator.findComponentFor(skillSchema(), spec, match.forField("skills"))).To(Equal("Skill"))
+	})
+
+	It("should prefer components used in the parent's direction", func() {
+		match.parent = reflect.TypeOf(MatchSkillPatch{})
+		Expect(generator.findComponentFor(skillSchema(), spec, match.forField("skill"))).To(Equal("SkillRequest"))
+	})
+
+	It("should find the Go type of JSON fields", func() {
+		Expect(jsonFieldType(reflect.TypeOf(&MatchProfile{}), "skills")).To(Equal(reflect.TypeOf(MatchSkill{})))
+		Expect(jsonFieldType(reflect.TypeOf(MatchProfileRequest{}), "skill")).To(Equal(reflect.TypeOf(MatchSkillRequest{})))
+		Expect(jsonFieldType(reflect.TypeOf(MatchProfile{}), "missing")).To(BeNil())
+	})
+})
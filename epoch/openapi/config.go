@@ -1,6 +1,8 @@
 package openapi
 
 import (
+	"reflect"
+
 	"github.com/astronomer/epoch/epoch"
 )
 
@@ -26,6 +28,30 @@ type SchemaGeneratorConfig struct {
 	// - If schema with mapped name exists in base spec → transforms it in place
 	// - If schema doesn't exist → generates from scratch using Go type name
 	SchemaNameMapper func(typeName string) string
+
+	// SchemaNames maps Go types to schema names, for types SchemaNameMapper can't tell apart
+	// by name, like billing.Account and auth.Account. A mapped name is used both to find the
+	// schema in the base spec and to name the generated component.
+	//
+	// Example: map[reflect.Type]string{reflect.TypeOf(billing.Account{}): "BillingAccount"}
+	// Takes precedence over SchemaTypeNameMapper and SchemaNameMapper.
+	SchemaNames map[reflect.Type]string
+
+	// SchemaTypeNameMapper maps Go types to schema names like SchemaNames, from the whole type
+	// including its package path. Returning "" falls back to SchemaNameMapper.
+	//
+	// Example: func(t reflect.Type) string { return path.Base(t.PkgPath()) + "." + t.Name() }
+	SchemaTypeNameMapper func(t reflect.Type) string
+
+	// VersionExtensions adds x-epoch-* vendor extensions derived from VersionChanges:
+	// x-epoch-added-in, x-epoch-renamed-from and x-epoch-removed-in on properties,
+	// and x-epoch-changed-in on operations of registered endpoints.
+	// Doc tooling can use them to render "since version" badges.
+	VersionExtensions bool
+
+	// PropertyOrder sets the order properties of generated component schemas are written in.
+	// Default: PropertyOrderAlphabetical
+	PropertyOrder PropertyOrder
 }
 
 // SchemaDirection indicates whether we're generating request or response schemas
@@ -49,3 +75,27 @@ func (sd SchemaDirection) String() string {
 		return "unknown"
 	}
 }
+
+// PropertyOrder controls the order properties of component schemas are written in
+type PropertyOrder int
+
+const (
+	// PropertyOrderAlphabetical writes properties sorted by name
+	PropertyOrderAlphabetical PropertyOrder = iota
+	// PropertyOrderStruct writes properties in the order of the Go struct fields they come from.
+	// Properties without a field in the HEAD struct, like a field's name in an older version,
+	// follow in alphabetical order.
+	PropertyOrderStruct
+)
+
+// String returns the string representation of PropertyOrder
+func (po PropertyOrder) String() string {
+	switch po {
+	case PropertyOrderAlphabetical:
+		return "alphabetical"
+	case PropertyOrderStruct:
+		return "struct"
+	default:
+		return "unknown"
+	}
+}
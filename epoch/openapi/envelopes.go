@@ -0,0 +1,178 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/astronomer/epoch/epoch"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// applyEnvelopesForVersion rewrites the 2xx response schemas of endpoints whose envelope
+// changed between HEAD and version (ForEndpoint().WrapResponse/UnwrapResponse)
+// Paths are shared with the base spec, so modified path items and operations are copied first.
+func (sg *SchemaGenerator) applyEnvelopesForVersion(spec *openapi3.T, version *epoch.Version) {
+	if spec.Paths == nil || spec.Paths.Len() == 0 {
+		return
+	}
+
+	// Responses migrate newest change first
+	var changes []*epoch.VersionChange
+	all := sg.transformer.allVersionChanges()
+	for i := len(all) - 1; i >= 0; i-- {
+		if !all[i].ToVersion().IsNewerThan(version) {
+			continue
+		}
+		changes = append(changes, all[i])
+	}
+	if len(changes) == 0 {
+		return
+	}
+
+	pathsCopied := false
+	for _, endpoint := range sg.sortedEndpoints() {
+		var ops []epoch.ResponseEnvelopeOperation
+		for _, change := range changes {
+			ops = append(ops, change.GetResponseEnvelopeOperations(endpoint.Method, endpoint.PathPattern)...)
+		}
+		if len(ops) == 0 {
+			continue
+		}
+
+		operation := copyOperation(spec, &pathsCopied, ginPathToOpenAPI(endpoint.PathPattern), endpoint.Method)
+		if operation == nil || operation.Responses == nil {
+			continue
+		}
+		for _, op := range ops {
+			if async, ok := op.(*epoch.ResponseAwaitAsync); ok {
+				operation.Responses = sg.syncResponses(spec, operation.Responses, async.Config)
+			}
+		}
+		operation.Responses = envelopeResponses(spec, operation.Responses, ops)
+	}
+}
+
+// syncResponses replaces the accepted (202) response of an endpoint HEAD made asynchronous
+// with the 200 response older clients receive, referencing the result type's component if known
+func (sg *SchemaGenerator) syncResponses(spec *openapi3.T, responses *openapi3.Responses, config epoch.AsyncConfig) *openapi3.Responses {
+	accepted := http.StatusAccepted
+	if config.AcceptedStatus != 0 {
+		accepted = config.AcceptedStatus
+	}
+	if responses.Status(accepted) == nil {
+		return responses
+	}
+
+	result := openapi3.NewResponsesWithCapacity(responses.Len())
+	result.Extensions = responses.Extensions
+	for code, responseRef := range responses.Map() {
+		if code != strconv.Itoa(accepted) {
+			result.Set(code, responseRef)
+		}
+	}
+
+	response := openapi3.NewResponse().WithDescription("Result of the completed job")
+	if resultType := reflect.TypeOf(config.ResultType); resultType != nil {
+		if resultType.Kind() == reflect.Ptr {
+			resultType = resultType.Elem()
+		}
+		for _, name := range []string{sg.mappedSchemaName(resultType), sg.componentName(resultType)} {
+			if spec.Components != nil && spec.Components.Schemas[name] != nil {
+				response.WithJSONSchemaRef(openapi3.NewSchemaRef(componentRefPrefix+name, nil))
+				break
+			}
+		}
+	}
+	result.Set(strconv.Itoa(http.StatusOK), &openapi3.ResponseRef{Value: response})
+	return result
+}
+
+// copyOperation replaces an operation in spec with a copy that is safe to modify and returns it
+// Paths are shared with the base spec, so the path map is copied on first use (tracked by
+// pathsCopied) and the path item every time. Returns nil if the operation doesn't exist.
+func copyOperation(spec *openapi3.T, pathsCopied *bool, path, method string) *openapi3.Operation {
+	item := spec.Paths.Value(path)
+	if item == nil {
+		return nil
+	}
+	operation := item.GetOperation(strings.ToUpper(method))
+	if operation == nil {
+		return nil
+	}
+
+	if !*pathsCopied {
+		paths := openapi3.NewPaths()
+		paths.Extensions = spec.Paths.Extensions
+		for p, pathItem := range spec.Paths.Map() {
+			paths.Set(p, pathItem)
+		}
+		spec.Paths = paths
+		*pathsCopied = true
+	}
+	itemCopy := *item
+	spec.Paths.Set(path, &itemCopy)
+
+	operationCopy := *operation
+	itemCopy.SetOperation(strings.ToUpper(method), &operationCopy)
+	return &operationCopy
+}
+
+// envelopeResponses copies responses, applying envelope operations to every 2xx media type schema
+func envelopeResponses(spec *openapi3.T, responses *openapi3.Responses, ops []epoch.ResponseEnvelopeOperation) *openapi3.Responses {
+	result := openapi3.NewResponsesWithCapacity(responses.Len())
+	result.Extensions = responses.Extensions
+	for code, responseRef := range responses.Map() {
+		if !strings.HasPrefix(code, "2") || responseRef == nil || responseRef.Value == nil || responseRef.Ref != "" {
+			result.Set(code, responseRef)
+			continue
+		}
+
+		response := *responseRef.Value
+		response.Content = make(openapi3.Content, len(responseRef.Value.Content))
+		for mediaType, media := range responseRef.Value.Content {
+			if media == nil || media.Schema == nil {
+				response.Content[mediaType] = media
+				continue
+			}
+			mediaCopy := *media
+			for _, op := range ops {
+				mediaCopy.Schema = envelopeSchema(spec, mediaCopy.Schema, op)
+			}
+			// Examples describe the HEAD envelope
+			mediaCopy.Example = nil
+			mediaCopy.Examples = nil
+			response.Content[mediaType] = &mediaCopy
+		}
+		result.Set(code, &openapi3.ResponseRef{Value: &response, Extensions: responseRef.Extensions})
+	}
+	return result
+}
+
+// envelopeSchema applies one envelope operation to a response schema
+func envelopeSchema(spec *openapi3.T, schemaRef *openapi3.SchemaRef, op epoch.ResponseEnvelopeOperation) *openapi3.SchemaRef {
+	switch envelope := op.(type) {
+	case *epoch.ResponseWrap:
+		return openapi3.NewSchemaRef("", &openapi3.Schema{
+			Type:       &openapi3.Types{"object"},
+			Properties: openapi3.Schemas{envelope.Key: schemaRef},
+			Required:   []string{envelope.Key},
+		})
+	case *epoch.ResponsePagination:
+		return paginationMetadataSchema(schemaRef, envelope.Config)
+	case *epoch.ResponseUnwrap:
+		schema := schemaRef.Value
+		if strings.HasPrefix(schemaRef.Ref, componentRefPrefix) {
+			if component := spec.Components.Schemas[strings.TrimPrefix(schemaRef.Ref, componentRefPrefix)]; component != nil {
+				schema = component.Value
+			}
+		}
+		if schema != nil {
+			if inner, ok := schema.Properties[envelope.Key]; ok && inner != nil {
+				return inner
+			}
+		}
+	}
+	return schemaRef
+}
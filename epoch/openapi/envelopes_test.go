@@ -0,0 +1,172 @@
+package openapi
+
+import (
+	"reflect"
+
+	"github.com/astronomer/epoch/epoch"
+	"github.com/getkin/kin-openapi/openapi3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Response envelopes", func() {
+	var (
+		v1, v2    *epoch.Version
+		generator *SchemaGenerator
+		baseSpec  *openapi3.T
+	)
+
+	BeforeEach(func() {
+		v1, _ = epoch.NewDateVersion("2024-01-01")
+		v2, _ = epoch.NewDateVersion("2024-06-01")
+		versionBundle, err := epoch.NewVersionBundle([]*epoch.Version{v1, v2})
+		Expect(err).NotTo(HaveOccurred())
+
+		v1.Changes = []epoch.VersionChangeInterface{
+			epoch.NewVersionChangeBuilder(v1, v2).
+				ForEndpoint("GET", "/users/:id").
+				WrapResponse("data").
+				ForEndpoint("GET", "/envelopes/:id").
+				UnwrapResponse("data").
+				Build(),
+		}
+
+		registry := epoch.NewEndpointRegistry()
+		registry.Register("GET", "/users/:id", &epoch.EndpointDefinition{
+			Method:       "GET",
+			PathPattern:  "/users/:id",
+			ResponseType: reflect.TypeOf(TestUserResponse{}),
+		})
+		registry.Register("GET", "/envelopes/:id", &epoch.EndpointDefinition{
+			Method:       "GET",
+			PathPattern:  "/envelopes/:id",
+			ResponseType: reflect.TypeOf(OrgSettings{}),
+		})
+
+		generator = NewSchemaGenerator(SchemaGeneratorConfig{
+			VersionBundle: versionBundle,
+			TypeRegistry:  registry,
+		})
+
+		operation := func(schemaName string) *openapi3.Operation {
+			return &openapi3.Operation{
+				Responses: openapi3.NewResponses(
+					openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().
+						WithDescription("OK").
+						WithJSONSchemaRef(openapi3.NewSchemaRef("#/components/schemas/"+schemaName, nil))}),
+					openapi3.WithStatus(404, &openapi3.ResponseRef{Value: openapi3.NewResponse().
+						WithDescription("Not found").
+						WithJSONSchemaRef(openapi3.NewSchemaRef("#/components/schemas/"+schemaName, nil))}),
+				),
+			}
+		}
+		baseSpec = &openapi3.T{
+			OpenAPI:    "3.0.3",
+			Info:       &openapi3.Info{Title: "Test", Version: "1.0"},
+			Paths:      openapi3.NewPaths(),
+			Components: &openapi3.Components{Schemas: openapi3.Schemas{}},
+		}
+		baseSpec.Paths.Set("/users/{id}", &openapi3.PathItem{Get: operation("TestUserResponse")})
+		baseSpec.Paths.Set("/envelopes/{id}", &openapi3.PathItem{Get: operation("OrgSettings")})
+	})
+
+	responseSchema := func(spec *openapi3.T, path string, status int) *openapi3.SchemaRef {
+		return spec.Paths.Value(path).Get.Responses.Status(status).Value.Content.Get("application/json").Schema
+	}
+
+	It("should wrap success responses for older versions", func() {
+		spec, err := generator.GenerateSpecForVersion(baseSpec, v1)
+		Expect(err).NotTo(HaveOccurred())
+
+		schema := responseSchema(spec, "/users/{id}", 200)
+		Expect(schema.Ref).To(BeEmpty())
+		Expect(schema.Value.Required).To(Equal([]string{"data"}))
+		Expect(schema.Value.Properties["data"].Ref).To(Equal("#/components/schemas/TestUserResponse"))
+
+		Expect(responseSchema(spec, "/users/{id}", 404).Ref).To(Equal("#/components/schemas/TestUserResponse"))
+	})
+
+	It("should unwrap to the envelope's member schema", func() {
+		spec, err := generator.GenerateSpecForVersion(baseSpec, v1)
+		Expect(err).NotTo(HaveOccurred())
+
+		// OrgSettings has no "data" property, so the schema is left as is
+		Expect(responseSchema(spec, "/envelopes/{id}", 200).Ref).To(Equal("#/components/schemas/OrgSettings"))
+
+		schema := envelopeSchema(spec, openapi3.NewSchemaRef("", &openapi3.Schema{
+			Properties: openapi3.Schemas{"data": openapi3.NewSchemaRef("#/components/schemas/OrgMember", nil)},
+		}), &epoch.ResponseUnwrap{Key: "data"})
+		Expect(schema.Ref).To(Equal("#/components/schemas/OrgMember"))
+	})
+
+	It("should leave HEAD and the base spec untouched", func() {
+		spec, err := generator.GenerateSpecForVersion(baseSpec, epoch.NewHeadVersion())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(responseSchema(spec, "/users/{id}", 200).Ref).To(Equal("#/components/schemas/TestUserResponse"))
+
+		_, err = generator.GenerateSpecForVersion(baseSpec, v1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(responseSchema(baseSpec, "/users/{id}", 200).Ref).To(Equal("#/components/schemas/TestUserResponse"))
+	})
+})
+
+var _ = Describe("Asynchronous endpoints", func() {
+	It("should document the synchronous result for versions before the endpoint became asynchronous", func() {
+		v1, _ := epoch.NewDateVersion("2024-01-01")
+		v2, _ := epoch.NewDateVersion("2024-06-01")
+		versionBundle, err := epoch.NewVersionBundle([]*epoch.Version{v1, v2})
+		Expect(err).NotTo(HaveOccurred())
+		v1.Changes = []epoch.VersionChangeInterface{
+			epoch.NewVersionChangeBuilder(v1, v2).
+				ForEndpoint("POST", "/reports").
+				AwaitAsync(epoch.AsyncConfig{ResultType: TestUserResponse{}}).
+				Build(),
+		}
+
+		registry := epoch.NewEndpointRegistry()
+		registry.Register("POST", "/reports", &epoch.EndpointDefinition{
+			Method:       "POST",
+			PathPattern:  "/reports",
+			ResponseType: reflect.TypeOf(OrgSettings{}),
+		})
+		registry.Register("GET", "/users/:id", &epoch.EndpointDefinition{
+			Method:       "GET",
+			PathPattern:  "/users/:id",
+			ResponseType: reflect.TypeOf(TestUserResponse{}),
+		})
+		generator := NewSchemaGenerator(SchemaGeneratorConfig{VersionBundle: versionBundle, TypeRegistry: registry})
+
+		baseSpec := &openapi3.T{
+			OpenAPI:    "3.0.3",
+			Info:       &openapi3.Info{Title: "Test", Version: "1.0"},
+			Paths:      openapi3.NewPaths(),
+			Components: &openapi3.Components{Schemas: openapi3.Schemas{}},
+		}
+		baseSpec.Paths.Set("/reports", &openapi3.PathItem{Post: &openapi3.Operation{
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(202, &openapi3.ResponseRef{Value: openapi3.NewResponse().
+					WithDescription("Accepted").
+					WithJSONSchemaRef(openapi3.NewSchemaRef("#/components/schemas/OrgSettings", nil))}),
+				openapi3.WithStatus(400, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("Bad request")}),
+			),
+		}})
+		baseSpec.Paths.Set("/users/{id}", &openapi3.PathItem{Get: &openapi3.Operation{
+			Responses: openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().
+				WithDescription("OK").
+				WithJSONSchemaRef(openapi3.NewSchemaRef("#/components/schemas/TestUserResponse", nil))})),
+		}})
+
+		spec, err := generator.GenerateSpecForVersion(baseSpec, v1)
+		Expect(err).NotTo(HaveOccurred())
+		responses := spec.Paths.Value("/reports").Post.Responses
+		Expect(responses.Status(202)).To(BeNil())
+		Expect(responses.Status(400)).NotTo(BeNil())
+		Expect(responses.Status(200).Value.Content.Get("application/json").Schema.Ref).
+			To(Equal("#/components/schemas/TestUserResponse"))
+
+		head, err := generator.GenerateSpecForVersion(baseSpec, epoch.NewHeadVersion())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(head.Paths.Value("/reports").Post.Responses.Status(202)).NotTo(BeNil())
+		Expect(baseSpec.Paths.Value("/reports").Post.Responses.Status(202)).NotTo(BeNil())
+	})
+})
@@ -0,0 +1,133 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/astronomer/epoch/epoch"
+	"github.com/astronomer/epoch/epoch/jsonast"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// WithExample attaches an example payload to a registered type
+// The example describes the HEAD version; each versioned spec gets a copy migrated
+// through the version chain so it matches that version's schema.
+func (sg *SchemaGenerator) WithExample(typ interface{}, example interface{}) *SchemaGenerator {
+	t := reflect.TypeOf(typ)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	sg.examples[t] = example
+	return sg
+}
+
+// applyExamplesForVersion sets each registered example, migrated to version, on its component schema
+func (sg *SchemaGenerator) applyExamplesForVersion(baseSpec *openapi3.T, spec *openapi3.T, version *epoch.Version) error {
+	if len(sg.examples) == 0 {
+		return nil
+	}
+
+	chain, err := epoch.NewMigrationChain(sg.transformer.allVersionChanges())
+	if err != nil {
+		return fmt.Errorf("failed to build migration chain for examples: %w", err)
+	}
+
+	for typ, example := range sg.examples {
+		schemaRef, ok := spec.Components.Schemas[sg.schemaNameForType(baseSpec, typ)]
+		if !ok || schemaRef.Value == nil {
+			continue
+		}
+
+		migrated, err := sg.migrateExample(chain, typ, example, version)
+		if err != nil {
+			return fmt.Errorf("failed to migrate example for %s to %s: %w", typ.Name(), version.String(), err)
+		}
+		schemaRef.Value.Example = migrated
+	}
+	return nil
+}
+
+// migrateExample converts a HEAD example into the shape clients of version see
+// Responses run through the real migration chain. Requests only migrate Client→HEAD at
+// runtime, so their examples are derived by inverting the documented top-level operations.
+func (sg *SchemaGenerator) migrateExample(
+	chain *epoch.MigrationChain,
+	typ reflect.Type,
+	example interface{},
+	version *epoch.Version,
+) (interface{}, error) {
+	data, err := json.Marshal(example)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal example: %w", err)
+	}
+
+	if !version.IsHead {
+		node, err := epoch.DefaultJSONEngine().Parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse example: %w", err)
+		}
+
+		if sg.getDirectionForType(typ) == SchemaDirectionRequest {
+			if err := sg.transformer.invertRequestExample(node, typ, version); err != nil {
+				return nil, err
+			}
+		} else {
+			nestedArrays, nestedObjects := epoch.BuildNestedTypeMaps(typ)
+			responseInfo := &epoch.ResponseInfo{Body: node, StatusCode: 200}
+			if err := chain.MigrateResponseForTypeWithNestedObjects(
+				context.Background(),
+				responseInfo,
+				typ,
+				nestedArrays,
+				nestedObjects,
+				sg.config.VersionBundle.GetHeadVersion(),
+				version,
+			); err != nil {
+				return nil, err
+			}
+			node = responseInfo.Body
+		}
+
+		if data, err = epoch.DefaultJSONEngine().Serialize(node); err != nil {
+			return nil, fmt.Errorf("failed to serialize example: %w", err)
+		}
+	}
+
+	// Decode to plain values so both YAML and JSON writers render the example
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("failed to decode example: %w", err)
+	}
+	return value, nil
+}
+
+// invertRequestExample walks request changes newer than targetVersion backward,
+// undoing their documented effects on the example body
+// Fields a newer version removed are left out since their old values are unknown.
+func (vt *VersionTransformer) invertRequestExample(node *jsonast.Node, targetType reflect.Type, targetVersion *epoch.Version) error {
+	changes := vt.allVersionChanges()
+	for i := len(changes) - 1; i >= 0; i-- {
+		vc := changes[i]
+		if !vc.ToVersion().IsNewerThan(targetVersion) {
+			continue
+		}
+
+		ops, _ := vc.GetRequestOperationsByType(targetType)
+		for j := len(ops) - 1; j >= 0; j-- {
+			doc := epoch.DescribeOperation(ops[j]).Inverse()
+			for _, name := range doc.RemovedFields {
+				if _, err := node.Unset(name); err != nil {
+					return fmt.Errorf("failed to remove field %s from example: %w", name, err)
+				}
+			}
+			for from, to := range doc.RenamedFields {
+				if err := epoch.RenameNodeField(node, from, to); err != nil {
+					return fmt.Errorf("failed to rename field %s in example: %w", from, err)
+				}
+			}
+		}
+	}
+	return nil
+}
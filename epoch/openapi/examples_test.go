@@ -0,0 +1,101 @@
+package openapi
+
+import (
+	"reflect"
+
+	"github.com/astronomer/epoch/epoch"
+	"github.com/getkin/kin-openapi/openapi3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Versioned Examples", func() {
+	var (
+		v1, v2    *epoch.Version
+		generator *SchemaGenerator
+		baseSpec  *openapi3.T
+	)
+
+	BeforeEach(func() {
+		v1, _ = epoch.NewDateVersion("2024-01-01")
+		v2, _ = epoch.NewDateVersion("2024-06-01")
+
+		versionBundle, err := epoch.NewVersionBundle([]*epoch.Version{v1, v2})
+		Expect(err).NotTo(HaveOccurred())
+
+		// v2 adds email to responses and renames the request's full_name to name
+		v1.Changes = []epoch.VersionChangeInterface{
+			epoch.NewVersionChangeBuilder(v1, v2).
+				ForType(TestUserResponse{}).
+				ResponseToPreviousVersion().
+				RemoveField("email").
+				ForType(TestUserRequest{}).
+				RequestToNextVersion().
+				RenameField("full_name", "name").
+				Build(),
+		}
+
+		registry := epoch.NewEndpointRegistry()
+		registry.Register("POST", "/users", &epoch.EndpointDefinition{
+			Method:       "POST",
+			PathPattern:  "/users",
+			RequestType:  reflect.TypeOf(TestUserRequest{}),
+			ResponseType: reflect.TypeOf(TestUserResponse{}),
+		})
+
+		generator = NewSchemaGenerator(SchemaGeneratorConfig{
+			VersionBundle: versionBundle,
+			TypeRegistry:  registry,
+		}).
+			WithExample(TestUserResponse{}, TestUserResponse{ID: 1, Name: "Jane", Email: "jane@example.com"}).
+			WithExample(&TestUserRequest{}, TestUserRequest{Name: "Jane", Email: "jane@example.com"})
+
+		baseSpec = &openapi3.T{
+			OpenAPI:    "3.0.3",
+			Info:       &openapi3.Info{Title: "Test", Version: "1.0"},
+			Paths:      openapi3.NewPaths(),
+			Components: &openapi3.Components{Schemas: openapi3.Schemas{}},
+		}
+	})
+
+	exampleFor := func(spec *openapi3.T, schemaName string) interface{} {
+		schema := spec.Components.Schemas[schemaName]
+		Expect(schema).NotTo(BeNil())
+		return schema.Value.Example
+	}
+
+	It("should keep the HEAD example as given", func() {
+		spec, err := generator.GenerateSpecForVersion(baseSpec, epoch.NewHeadVersion())
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(exampleFor(spec, "TestUserResponse")).To(Equal(map[string]interface{}{
+			"id": float64(1), "name": "Jane", "email": "jane@example.com",
+		}))
+	})
+
+	It("should migrate response examples through the chain", func() {
+		spec, err := generator.GenerateSpecForVersion(baseSpec, v1)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(exampleFor(spec, "TestUserResponse")).To(Equal(map[string]interface{}{
+			"id": float64(1), "name": "Jane",
+		}))
+	})
+
+	It("should invert request operations for request examples", func() {
+		spec, err := generator.GenerateSpecForVersion(baseSpec, v1)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(exampleFor(spec, "TestUserRequest")).To(Equal(map[string]interface{}{
+			"full_name": "Jane", "email": "jane@example.com",
+		}))
+	})
+
+	It("should leave schemas without examples alone", func() {
+		generator.examples = make(map[reflect.Type]interface{})
+		spec, err := generator.GenerateSpecForVersion(baseSpec, v1)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(exampleFor(spec, "TestUserResponse")).To(BeNil())
+	})
+})
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/astronomer/epoch/epoch"
 	"github.com/getkin/kin-openapi/openapi3"
@@ -22,6 +23,15 @@ type SchemaGenerator struct {
 
 	// Track which types need component schemas generated
 	typesToGenerate map[string][]reflect.Type
+
+	// HEAD example payloads registered with WithExample()
+	examples map[reflect.Type]interface{}
+
+	// Security declared with WithVersionSecurity()
+	versionSecurity []versionSecurity
+
+	// Servers declared with WithVersionServers()
+	versionServers []versionServers
 }
 
 // NewSchemaGenerator creates a new schema generator
@@ -35,14 +45,27 @@ func NewSchemaGenerator(config SchemaGeneratorConfig) *SchemaGenerator {
 		config.SchemaNameMapper = func(name string) string { return name }
 	}
 
-	return &SchemaGenerator{
+	sg := &SchemaGenerator{
 		config:             &config,
 		typeParser:         NewTypeParser(),
 		transformer:        NewVersionTransformer(config.VersionBundle),
 		writer:             NewWriter(config.OutputFormat),
 		nestedTypeRegistry: make(map[string]map[reflect.Type]string),
 		typesToGenerate:    make(map[string][]reflect.Type),
+		examples:           make(map[reflect.Type]interface{}),
 	}
+	sg.typeParser.SetComponentNamer(sg.componentName)
+	sg.transformer.typeParser.SetComponentNamer(sg.componentName)
+	return sg
+}
+
+// WithTypeSchemaOverride describes a type whose JSON wire format reflection can't infer,
+// e.g. a type with a custom MarshalJSON. schemaFn is called for every use of the type.
+func (sg *SchemaGenerator) WithTypeSchemaOverride(typ interface{}, schemaFn func() *openapi3.Schema) *SchemaGenerator {
+	t := reflect.TypeOf(typ)
+	sg.typeParser.SetSchemaOverride(t, schemaFn)
+	sg.transformer.typeParser.SetSchemaOverride(t, schemaFn)
+	return sg
 }
 
 // GenerateVersionedSpecs generates OpenAPI specs for all versions in the version bundle
@@ -50,6 +73,10 @@ func NewSchemaGenerator(config SchemaGeneratorConfig) *SchemaGenerator {
 func (sg *SchemaGenerator) GenerateVersionedSpecs(baseSpec *openapi3.T) (map[string]*openapi3.T, error) {
 	result := make(map[string]*openapi3.T)
 
+	if err := sg.validateVersionSecurity(baseSpec); err != nil {
+		return nil, err
+	}
+
 	// Generate spec for HEAD version
 	headVersion := sg.config.VersionBundle.GetHeadVersion()
 	headSpec, err := sg.GenerateSpecForVersion(baseSpec, headVersion)
@@ -93,6 +120,11 @@ func (sg *SchemaGenerator) GenerateSpecForVersion(baseSpec *openapi3.T, version
 		sg.collectNestedTypesForGeneration(typ, version)
 	}
 
+	// Two types sharing a component name would silently overwrite each other's schema
+	if err := sg.checkComponentNames(baseSpec, types, version.String()); err != nil {
+		return nil, err
+	}
+
 	// PASS 2: Generate component schemas for all nested types in three sub-passes
 	// Sub-pass 2a: Parse and store base schemas (no refs, no transformations)
 	versionKey := version.String()
@@ -124,7 +156,12 @@ func (sg *SchemaGenerator) GenerateSpecForVersion(baseSpec *openapi3.T, version
 	}
 
 	// Sub-pass 2b: Add all base schemas to components (so refs can resolve in PASS 4)
-	for nestedType, schema := range baseSchemas {
+	// Types are visited in collection order rather than map order to keep output stable
+	for _, nestedType := range sg.typesToGenerate[versionKey] {
+		schema, ok := baseSchemas[nestedType]
+		if !ok {
+			continue
+		}
 		componentName := sg.getComponentNameForType(versionKey, nestedType)
 		if componentName == "" {
 			continue
@@ -135,7 +172,11 @@ func (sg *SchemaGenerator) GenerateSpecForVersion(baseSpec *openapi3.T, version
 	}
 
 	// Sub-pass 2c: Apply transformations to all schemas (refs will be replaced in PASS 4)
-	for nestedType, schema := range baseSchemas {
+	for _, nestedType := range sg.typesToGenerate[versionKey] {
+		schema, ok := baseSchemas[nestedType]
+		if !ok {
+			continue
+		}
 		componentName := sg.getComponentNameForType(versionKey, nestedType)
 		if componentName == "" {
 			continue
@@ -169,18 +210,61 @@ func (sg *SchemaGenerator) GenerateSpecForVersion(baseSpec *openapi3.T, version
 		}
 	}
 
-	// PASS 4: Now that all components exist, replace nested schemas with refs in ALL schemas
-	for componentName, schemaRef := range spec.Components.Schemas {
+	// PASS 4: Now that all components exist, replace nested schemas with refs in ALL schemas.
+	// Knowing each component's Go type tells apart components with the same properties.
+	componentTypes := sg.componentTypes(baseSpec, types, versionKey)
+	directions := sg.typeDirections()
+	for _, componentName := range sortedKeys(spec.Components.Schemas) {
+		schemaRef := spec.Components.Schemas[componentName]
 		if schemaRef == nil || schemaRef.Value == nil {
 			continue
 		}
 
 		// Replace any remaining inline schemas with refs
-		if err := sg.replaceNestedSchemasWithRefsGeneric(schemaRef.Value, spec); err != nil {
+		match := &componentMatchContext{
+			parent:         componentTypes[componentName],
+			componentTypes: componentTypes,
+			directions:     directions,
+		}
+		if err := sg.replaceNestedSchemasWithRefs(schemaRef.Value, spec, match); err != nil {
 			return nil, fmt.Errorf("failed to replace refs in %s: %w", componentName, err)
 		}
 	}
 
+	if sg.config.PropertyOrder == PropertyOrderStruct {
+		sg.writer.recordPropertyOrders(componentTypes)
+	}
+
+	// Migrate registered examples to this version
+	if err := sg.applyExamplesForVersion(baseSpec, spec, version); err != nil {
+		return nil, err
+	}
+
+	// Leave out endpoints whose types don't exist yet in this version
+	sg.removeUnavailableEndpoints(spec, version)
+
+	// Rewrap responses of endpoints whose envelope changed
+	sg.applyEnvelopesForVersion(spec, version)
+
+	// Restore the query parameters older clients send
+	sg.applyQueryParamsForVersion(spec, version)
+
+	// Document the response headers older clients receive
+	sg.applyResponseHeadersForVersion(spec, version)
+
+	// Document the auth of versions whose security differs from the base spec
+	if err := sg.applySecurityForVersion(spec, version); err != nil {
+		return nil, err
+	}
+
+	// Point versions served from their own base URL at it
+	sg.applyServersForVersion(spec, version)
+
+	// PASS 5: Annotate properties and operations with version metadata
+	if sg.config.VersionExtensions {
+		sg.annotateSpecForVersion(baseSpec, spec, types, version)
+	}
+
 	return spec, nil
 }
 
@@ -200,7 +284,7 @@ func (sg *SchemaGenerator) processTypeForVersion(
 	goTypeName := typ.Name()
 
 	// Map to schema name in spec (e.g., "versionedapi.UpdateExampleRequest")
-	mappedSchemaName := sg.config.SchemaNameMapper(goTypeName)
+	mappedSchemaName := sg.mappedSchemaName(typ)
 
 	// Try to find existing schema in base spec
 	existingSchema := sg.findSchemaInSpec(baseSpec, mappedSchemaName)
@@ -228,7 +312,7 @@ func (sg *SchemaGenerator) processTypeForVersion(
 	} else {
 		// FALLBACK PATH: Schema doesn't exist in base spec
 
-		schemaKey := goTypeName
+		schemaKey := sg.componentName(typ)
 
 		if _, exists := spec.Components.Schemas[schemaKey]; exists {
 			// Already generated as a nested type in PASS 2, skip to avoid duplication
@@ -250,6 +334,15 @@ func (sg *SchemaGenerator) processTypeForVersion(
 	return nil
 }
 
+// schemaNameForType returns the component name processTypeForVersion uses for a top-level type
+func (sg *SchemaGenerator) schemaNameForType(baseSpec *openapi3.T, typ reflect.Type) string {
+	mappedSchemaName := sg.mappedSchemaName(typ)
+	if baseSpec != nil && baseSpec.Components != nil && baseSpec.Components.Schemas[mappedSchemaName] != nil {
+		return mappedSchemaName
+	}
+	return sg.componentName(typ)
+}
+
 // findSchemaInSpec looks for a schema by name in the base spec
 // Returns cloned schema if found, nil if not found
 func (sg *SchemaGenerator) findSchemaInSpec(spec *openapi3.T, schemaName string) *openapi3.Schema {
@@ -272,10 +365,7 @@ func (sg *SchemaGenerator) getRegisteredTypes() []reflect.Type {
 	typeMap := make(map[reflect.Type]bool)
 	var types []reflect.Type
 
-	// Get all endpoints from registry
-	endpoints := sg.config.TypeRegistry.GetAll()
-
-	for _, endpoint := range endpoints {
+	for _, endpoint := range sg.sortedEndpoints() {
 		if endpoint.RequestType != nil {
 			reqType := endpoint.RequestType
 			// For slice/array types, register the element type instead
@@ -324,28 +414,32 @@ func (sg *SchemaGenerator) getRegisteredTypes() []reflect.Type {
 			}
 		}
 
-		for _, itemType := range endpoint.ResponseNestedArrays {
+		for _, fieldPath := range sortedKeys(endpoint.ResponseNestedArrays) {
+			itemType := endpoint.ResponseNestedArrays[fieldPath]
 			if !typeMap[itemType] {
 				typeMap[itemType] = true
 				types = append(types, itemType)
 			}
 		}
 
-		for _, objType := range endpoint.ResponseNestedObjects {
+		for _, fieldPath := range sortedKeys(endpoint.ResponseNestedObjects) {
+			objType := endpoint.ResponseNestedObjects[fieldPath]
 			if !typeMap[objType] {
 				typeMap[objType] = true
 				types = append(types, objType)
 			}
 		}
 
-		for _, itemType := range endpoint.RequestNestedArrays {
+		for _, fieldPath := range sortedKeys(endpoint.RequestNestedArrays) {
+			itemType := endpoint.RequestNestedArrays[fieldPath]
 			if !typeMap[itemType] {
 				typeMap[itemType] = true
 				types = append(types, itemType)
 			}
 		}
 
-		for _, objType := range endpoint.RequestNestedObjects {
+		for _, fieldPath := range sortedKeys(endpoint.RequestNestedObjects) {
+			objType := endpoint.RequestNestedObjects[fieldPath]
 			if !typeMap[objType] {
 				typeMap[objType] = true
 				types = append(types, objType)
@@ -381,6 +475,63 @@ func (sg *SchemaGenerator) collectNestedTypes(rootType reflect.Type, typeMap map
 			*types = append(*types, info.Type)
 		}
 	}
+
+	// AnalyzeStructFields doesn't descend into maps, so collect map value types at every level
+	structTypes := []reflect.Type{rootType}
+	for _, info := range nestedInfos {
+		structTypes = append(structTypes, info.Type)
+	}
+	for _, structType := range structTypes {
+		for _, valueType := range mapValueTypes(structType) {
+			if !typeMap[valueType] {
+				typeMap[valueType] = true
+				*types = append(*types, valueType)
+				sg.collectNestedTypes(valueType, typeMap, types)
+			}
+		}
+	}
+}
+
+// mapValueTypes returns the struct types used as map values in t's fields
+// e.g. Members map[string]Member, Groups map[string][]*Member
+func mapValueTypes(t reflect.Type) []reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var result []reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || strings.Split(field.Tag.Get("json"), ",")[0] == "-" {
+			continue
+		}
+
+		fieldType := unwrapContainerType(field.Type)
+		if fieldType.Kind() != reflect.Map {
+			continue
+		}
+
+		// Maps of maps nest further; unwrap until the value is no longer a map
+		valueType := unwrapContainerType(fieldType.Elem())
+		for valueType.Kind() == reflect.Map {
+			valueType = unwrapContainerType(valueType.Elem())
+		}
+		if valueType.Kind() == reflect.Struct && valueType != reflect.TypeOf(time.Time{}) {
+			result = append(result, valueType)
+		}
+	}
+	return result
+}
+
+// unwrapContainerType strips pointers, slices and arrays
+func unwrapContainerType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	return t
 }
 
 // cloneSpec creates a shallow clone of an OpenAPI spec
@@ -397,8 +548,12 @@ func (sg *SchemaGenerator) cloneSpec(original *openapi3.T) *openapi3.T {
 	}
 
 	// Clone components, preserving schemas from base spec
-	clone.Components = &openapi3.Components{
-		Schemas:         sg.copySchemas(original.Components),
+	clone.Components = &openapi3.Components{Schemas: sg.copySchemas(original.Components)}
+	if original.Components == nil {
+		return clone
+	}
+	*clone.Components = openapi3.Components{
+		Schemas:         clone.Components.Schemas,
 		Parameters:      original.Components.Parameters,
 		Headers:         original.Components.Headers,
 		RequestBodies:   original.Components.RequestBodies,
@@ -429,11 +584,22 @@ func (sg *SchemaGenerator) WriteVersionedSpecs(specs map[string]*openapi3.T, fil
 	return sg.writer.WriteVersionedSpecs(specs, filenamePattern)
 }
 
+// sortedEndpoints returns the registered endpoints ordered by method and path, so specs
+// come out the same on every run instead of following map iteration order
+func (sg *SchemaGenerator) sortedEndpoints() []*epoch.EndpointDefinition {
+	endpoints := sg.config.TypeRegistry.GetAll()
+	sorted := make([]*epoch.EndpointDefinition, 0, len(endpoints))
+	for _, key := range sortedKeys(endpoints) {
+		sorted = append(sorted, endpoints[key])
+	}
+	return sorted
+}
+
 // getDirectionForType determines if a type is used as request or response
 // by checking the endpoint registry
 func (sg *SchemaGenerator) getDirectionForType(typ reflect.Type) SchemaDirection {
 	// Check endpoint registry to determine type's role
-	for _, endpoint := range sg.config.TypeRegistry.GetAll() {
+	for _, endpoint := range sg.sortedEndpoints() {
 		if endpoint.RequestType == typ {
 			return SchemaDirectionRequest
 		}
@@ -504,7 +670,7 @@ func (sg *SchemaGenerator) generateComponentNameForType(typ reflect.Type) string
 		}
 	}
 
-	return typeName
+	return sg.componentName(typ)
 }
 
 // collectNestedTypesForGeneration collects all nested types that need component generation
@@ -573,6 +739,13 @@ func (sg *SchemaGenerator) collectNestedTypesRecursive(typ reflect.Type, version
 		// Recursively collect nested types (with cycle detection)
 		sg.collectNestedTypesRecursive(objType, version, visited)
 	}
+
+	// Register map value types so additionalProperties $refs resolve
+	for _, valueType := range mapValueTypes(typ) {
+		componentName := sg.generateComponentNameForType(valueType)
+		sg.registerNestedType(versionKey, valueType, componentName)
+		sg.collectNestedTypesRecursive(valueType, version, visited)
+	}
 }
 
 // generateSchemaWithoutRefs generates a schema WITHOUT replacing nested schemas with refs
@@ -625,6 +798,17 @@ func (sg *SchemaGenerator) generateSchemaWithoutRefs(
 func (sg *SchemaGenerator) replaceNestedSchemasWithRefsGeneric(
 	schema *openapi3.Schema,
 	spec *openapi3.T,
+) error {
+	return sg.replaceNestedSchemasWithRefs(schema, spec, nil)
+}
+
+// replaceNestedSchemasWithRefs replaces inline nested schemas with $ref pointers, choosing
+// between components with the same properties by where the schema is registered (see
+// componentMatchContext); a nil match picks the first such component by name
+func (sg *SchemaGenerator) replaceNestedSchemasWithRefs(
+	schema *openapi3.Schema,
+	spec *openapi3.T,
+	match *componentMatchContext,
 ) error {
 	if schema == nil {
 		return nil
@@ -657,19 +841,28 @@ func (sg *SchemaGenerator) replaceNestedSchemasWithRefsGeneric(
 			if typeStr == "object" && propSchema.Properties != nil {
 				// This is an inline object - try to find a matching component
 				// Look for components that match this schema structure
-				matchingComponentName := sg.findMatchingComponent(propSchema, spec)
+				matchingComponentName := sg.findComponentFor(propSchema, spec, match.forField(propName))
 				if matchingComponentName != "" {
 					// Replace with $ref
 					schema.Properties[propName] = &openapi3.SchemaRef{
 						Ref: fmt.Sprintf("#/components/schemas/%s", matchingComponentName),
 					}
 				}
+			} else if typeStr == "object" && propSchema.AdditionalProperties.Schema != nil &&
+				propSchema.AdditionalProperties.Schema.Ref == "" && propSchema.AdditionalProperties.Schema.Value != nil {
+				// Map of inline objects - try to find a matching component for the values
+				matchingComponentName := sg.findComponentFor(propSchema.AdditionalProperties.Schema.Value, spec, match.forField(propName))
+				if matchingComponentName != "" {
+					propSchema.AdditionalProperties.Schema = &openapi3.SchemaRef{
+						Ref: fmt.Sprintf("#/components/schemas/%s", matchingComponentName),
+					}
+				}
 			} else if typeStr == "array" && propSchema.Items != nil && propSchema.Items.Value != nil {
 				// Check if array items are inline objects
 				itemSchema := propSchema.Items.Value
 				if itemSchema.Type != nil && len(*itemSchema.Type) > 0 && (*itemSchema.Type)[0] == "object" {
 					// Inline object in array - try to find matching component
-					matchingComponentName := sg.findMatchingComponent(itemSchema, spec)
+					matchingComponentName := sg.findComponentFor(itemSchema, spec, match.forField(propName))
 					if matchingComponentName != "" {
 						// Replace items with $ref
 						propSchema.Items = &openapi3.SchemaRef{
@@ -694,48 +887,11 @@ func (sg *SchemaGenerator) replaceNestedSchemasWithRefsGeneric(
 // - Inline schemas only occur for anonymous structs or when parsing fails
 // - Property name collisions across different business domains are uncommon
 //
-// If false positives occur, the workaround is to ensure all nested types are named Go structs
-// rather than anonymous inline definitions.
+// When several components match, the first by name is returned; findComponentFor uses where
+// the schema is registered to choose instead (see epoch.AmbiguousTypePair).
 func (sg *SchemaGenerator) findMatchingComponent(
 	inlineSchema *openapi3.Schema,
 	spec *openapi3.T,
 ) string {
-	if inlineSchema == nil || inlineSchema.Properties == nil {
-		return ""
-	}
-
-	// Get the property names from the inline schema
-	inlineProps := make(map[string]bool)
-	for propName := range inlineSchema.Properties {
-		inlineProps[propName] = true
-	}
-
-	// Search components for a matching schema
-	for componentName, componentRef := range spec.Components.Schemas {
-		if componentRef == nil || componentRef.Value == nil || componentRef.Value.Properties == nil {
-			continue
-		}
-
-		// Check if properties match
-		componentProps := make(map[string]bool)
-		for propName := range componentRef.Value.Properties {
-			componentProps[propName] = true
-		}
-
-		// Simple heuristic: if property names match, it's likely the same type
-		if len(inlineProps) == len(componentProps) {
-			allMatch := true
-			for propName := range inlineProps {
-				if !componentProps[propName] {
-					allMatch = false
-					break
-				}
-			}
-			if allMatch {
-				return componentName
-			}
-		}
-	}
-
-	return ""
+	return sg.findComponentFor(inlineSchema, spec, nil)
 }
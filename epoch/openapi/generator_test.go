@@ -58,6 +58,24 @@ type NestedContainer struct {
 	Metadata NestedMetadata `json:"metadata"`
 }
 
+// Map types for additionalProperties tests
+type OrgMember struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type OrgSettings struct {
+	Owners map[string]*OrgMember `json:"owners"`
+}
+
+type OrganizationResponse struct {
+	ID       string                  `json:"id"`
+	Metadata map[string]string       `json:"metadata"`
+	Members  map[string]OrgMember    `json:"members"`
+	Teams    map[string][]*OrgMember `json:"teams"`
+	Settings OrgSettings             `json:"settings"`
+}
+
 // Self-referential type for circular dependency testing
 type SelfReferential struct {
 	ID    int              `json:"id"`
@@ -806,6 +824,139 @@ var _ = Describe("SchemaGenerator", func() {
 		})
 	})
 
+	Describe("Map Types", func() {
+		var (
+			generator *SchemaGenerator
+			v1, v2    *epoch.Version
+			baseSpec  *openapi3.T
+		)
+
+		BeforeEach(func() {
+			v1, _ = epoch.NewDateVersion("2024-01-01")
+			v2, _ = epoch.NewDateVersion("2024-06-01")
+			versionBundle, _ := epoch.NewVersionBundle([]*epoch.Version{v1, v2})
+			v1.Changes = []epoch.VersionChangeInterface{
+				epoch.NewVersionChangeBuilder(v1, v2).
+					ForType(OrgMember{}).
+					ResponseToPreviousVersion().
+					RemoveField("email").
+					Build(),
+			}
+
+			registry := epoch.NewEndpointRegistry()
+			registry.Register("GET", "/orgs/:id", &epoch.EndpointDefinition{
+				Method:       "GET",
+				PathPattern:  "/orgs/:id",
+				ResponseType: reflect.TypeOf(OrganizationResponse{}),
+			})
+
+			generator = NewSchemaGenerator(SchemaGeneratorConfig{
+				VersionBundle: versionBundle,
+				TypeRegistry:  registry,
+			})
+			baseSpec = &openapi3.T{
+				OpenAPI:    "3.0.3",
+				Info:       &openapi3.Info{Title: "Test", Version: "1.0"},
+				Paths:      openapi3.NewPaths(),
+				Components: &openapi3.Components{Schemas: openapi3.Schemas{}},
+			}
+		})
+
+		It("should emit additionalProperties for maps of primitives", func() {
+			spec, err := generator.GenerateSpecForVersion(baseSpec, v2)
+			Expect(err).NotTo(HaveOccurred())
+
+			metadata := spec.Components.Schemas["OrganizationResponse"].Value.Properties["metadata"].Value
+			Expect(metadata.Type.Is("object")).To(BeTrue())
+			Expect(metadata.AdditionalProperties.Schema.Value.Type.Is("string")).To(BeTrue())
+		})
+
+		It("should generate components for map values so $refs resolve", func() {
+			spec, err := generator.GenerateSpecForVersion(baseSpec, v2)
+			Expect(err).NotTo(HaveOccurred())
+
+			org := spec.Components.Schemas["OrganizationResponse"].Value
+			Expect(org.Properties["members"].Value.AdditionalProperties.Schema.Ref).To(Equal("#/components/schemas/OrgMember"))
+			Expect(org.Properties["teams"].Value.AdditionalProperties.Schema.Value.Items.Ref).To(Equal("#/components/schemas/OrgMember"))
+			Expect(spec.Components.Schemas).To(HaveKey("OrgMember"))
+
+			// Maps inside nested structs are discovered too
+			settings := spec.Components.Schemas["OrgSettings"].Value
+			Expect(settings.Properties["owners"].Value.AdditionalProperties.Schema.Ref).To(Equal("#/components/schemas/OrgMember"))
+		})
+
+		It("should version map value components", func() {
+			headSpec, err := generator.GenerateSpecForVersion(baseSpec, epoch.NewHeadVersion())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(headSpec.Components.Schemas["OrgMember"].Value.Properties).To(HaveKey("email"))
+
+			v1Spec, err := generator.GenerateSpecForVersion(baseSpec, v1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v1Spec.Components.Schemas["OrgMember"].Value.Properties).NotTo(HaveKey("email"))
+		})
+
+		It("should replace inline map value objects from base specs with $refs", func() {
+			spec := &openapi3.T{Components: &openapi3.Components{Schemas: openapi3.Schemas{
+				"OrgMember": openapi3.NewSchemaRef("", &openapi3.Schema{
+					Type: &openapi3.Types{"object"},
+					Properties: openapi3.Schemas{
+						"name":  openapi3.NewSchemaRef("", &openapi3.Schema{Type: &openapi3.Types{"string"}}),
+						"email": openapi3.NewSchemaRef("", &openapi3.Schema{Type: &openapi3.Types{"string"}}),
+					},
+				}),
+			}}}
+			schema := &openapi3.Schema{
+				Type: &openapi3.Types{"object"},
+				Properties: openapi3.Schemas{
+					"members": openapi3.NewSchemaRef("", &openapi3.Schema{
+						Type: &openapi3.Types{"object"},
+						AdditionalProperties: openapi3.AdditionalProperties{
+							Schema: openapi3.NewSchemaRef("", CloneSchema(spec.Components.Schemas["OrgMember"].Value)),
+						},
+					}),
+				},
+			}
+
+			Expect(generator.replaceNestedSchemasWithRefsGeneric(schema, spec)).To(Succeed())
+			Expect(schema.Properties["members"].Value.AdditionalProperties.Schema.Ref).To(Equal("#/components/schemas/OrgMember"))
+		})
+	})
+
+	Describe("Type Schema Overrides", func() {
+		It("should use overrides in every versioned spec", func() {
+			v1, _ := epoch.NewDateVersion("2024-01-01")
+			versionBundle, _ := epoch.NewVersionBundle([]*epoch.Version{v1})
+			registry := epoch.NewEndpointRegistry()
+			registry.Register("GET", "/invoices/:id", &epoch.EndpointDefinition{
+				Method:       "GET",
+				PathPattern:  "/invoices/:id",
+				ResponseType: reflect.TypeOf(Invoice{}),
+			})
+
+			generator := NewSchemaGenerator(SchemaGeneratorConfig{
+				VersionBundle: versionBundle,
+				TypeRegistry:  registry,
+			}).WithTypeSchemaOverride(Cents(0), func() *openapi3.Schema {
+				return &openapi3.Schema{Type: &openapi3.Types{"string"}, Format: "decimal"}
+			})
+
+			baseSpec := &openapi3.T{
+				OpenAPI:    "3.0.3",
+				Info:       &openapi3.Info{Title: "Test", Version: "1.0"},
+				Paths:      openapi3.NewPaths(),
+				Components: &openapi3.Components{Schemas: openapi3.Schemas{}},
+			}
+			for _, version := range []*epoch.Version{v1, epoch.NewHeadVersion()} {
+				spec, err := generator.GenerateSpecForVersion(baseSpec, version)
+				Expect(err).NotTo(HaveOccurred())
+
+				total := spec.Components.Schemas["Invoice"].Value.Properties["total"].Value
+				Expect(total.Type.Is("string")).To(BeTrue())
+				Expect(total.Format).To(Equal("decimal"))
+			}
+		})
+	})
+
 	Describe("Component Name Generation", func() {
 		var generator *SchemaGenerator
 
@@ -0,0 +1,224 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/astronomer/epoch/epoch"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// JSONSchemaDialect is the $schema of documents produced by GenerateJSONSchemas
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+const componentRefPrefix = "#/components/schemas/"
+
+// jsonSchemaDefsPrefix is where standalone JSON Schema documents keep the components they use
+const jsonSchemaDefsPrefix = "#/$defs/"
+
+// GenerateJSONSchemas emits a standalone JSON Schema document for every registered
+// request/response type as seen by clients of version, keyed by schema name
+// Nested types are inlined under $defs so each document validates on its own.
+func (sg *SchemaGenerator) GenerateJSONSchemas(version *epoch.Version) (map[string][]byte, error) {
+	spec, err := sg.generateStandaloneSpec(version)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert every component once; documents pick the ones they reference
+	components := make(map[string]map[string]interface{}, len(spec.Components.Schemas))
+	for name, schemaRef := range spec.Components.Schemas {
+		converted, err := toJSONSchema(schemaRef, jsonSchemaDefsPrefix)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert schema %s: %w", name, err)
+		}
+		components[name] = converted
+	}
+
+	documents := make(map[string][]byte)
+	for _, name := range sg.endpointSchemaNames() {
+		root, ok := components[name]
+		if !ok {
+			continue
+		}
+
+		document := make(map[string]interface{}, len(root)+3)
+		for key, value := range root {
+			document[key] = value
+		}
+		document["$schema"] = JSONSchemaDialect
+		document["title"] = name
+
+		if defs := collectJSONSchemaDefs(root, components); len(defs) > 0 {
+			document["$defs"] = defs
+		}
+
+		data, err := json.MarshalIndent(document, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal JSON Schema for %s: %w", name, err)
+		}
+		documents[name] = data
+	}
+	return documents, nil
+}
+
+// generateStandaloneSpec generates a spec for version from Go types alone (no base spec)
+// It backs exports that only need component schemas.
+func (sg *SchemaGenerator) generateStandaloneSpec(version *epoch.Version) (*openapi3.T, error) {
+	baseSpec := &openapi3.T{
+		OpenAPI:    "3.0.3",
+		Info:       &openapi3.Info{Title: "Epoch export", Version: version.String()},
+		Paths:      openapi3.NewPaths(),
+		Components: &openapi3.Components{Schemas: openapi3.Schemas{}},
+	}
+	return sg.GenerateSpecForVersion(baseSpec, version)
+}
+
+// WriteJSONSchemas writes documents from GenerateJSONSchemas to dir as <name>.schema.json
+func (sg *SchemaGenerator) WriteJSONSchemas(documents map[string][]byte, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+	for name, data := range documents {
+		path := filepath.Join(dir, name+".schema.json")
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return fmt.Errorf("failed to write JSON Schema for %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// endpointSchemaNames returns the schema names of registered request and response types
+// Slice types contribute their element type, matching how specs are generated.
+func (sg *SchemaGenerator) endpointSchemaNames() []string {
+	seen := make(map[string]bool)
+	var names []string
+	add := func(t reflect.Type) {
+		if t == nil {
+			return
+		}
+		t = unwrapContainerType(t)
+		if t.Kind() != reflect.Struct || t.Name() == "" {
+			return
+		}
+		name := sg.componentName(t)
+		if seen[name] {
+			return
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	for _, endpoint := range sg.sortedEndpoints() {
+		add(endpoint.RequestType)
+		add(endpoint.ResponseType)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// toJSONSchema converts an OpenAPI 3.0 schema into JSON Schema 2020-12 keywords
+// Component $refs are rewritten to point below refPrefix.
+func toJSONSchema(schemaRef *openapi3.SchemaRef, refPrefix string) (map[string]interface{}, error) {
+	data, err := json.Marshal(schemaRef)
+	if err != nil {
+		return nil, err
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, err
+	}
+	convertJSONSchemaKeywords(schema, refPrefix)
+	return schema, nil
+}
+
+// convertJSONSchemaKeywords rewrites OpenAPI-only keywords in place, recursing into subschemas
+// Values of non-schema keywords (example, default, enum) are left untouched.
+func convertJSONSchemaKeywords(schema map[string]interface{}, refPrefix string) {
+	if ref, ok := schema["$ref"].(string); ok && strings.HasPrefix(ref, componentRefPrefix) {
+		schema["$ref"] = refPrefix + strings.TrimPrefix(ref, componentRefPrefix)
+	}
+
+	// nullable: true → type: [T, "null"]
+	if nullable, _ := schema["nullable"].(bool); nullable {
+		if typ, ok := schema["type"].(string); ok {
+			schema["type"] = []interface{}{typ, "null"}
+		}
+	}
+	delete(schema, "nullable")
+
+	// example → examples
+	if example, ok := schema["example"]; ok {
+		schema["examples"] = []interface{}{example}
+		delete(schema, "example")
+	}
+
+	// Boolean exclusiveMinimum/exclusiveMaximum → numeric bounds
+	for exclusive, bound := range map[string]string{"exclusiveMinimum": "minimum", "exclusiveMaximum": "maximum"} {
+		if flag, ok := schema[exclusive].(bool); ok {
+			if value, hasBound := schema[bound]; flag && hasBound {
+				schema[exclusive] = value
+				delete(schema, bound)
+			} else {
+				delete(schema, exclusive)
+			}
+		}
+	}
+
+	for _, keyword := range []string{"properties", "patternProperties", "$defs"} {
+		if children, ok := schema[keyword].(map[string]interface{}); ok {
+			for _, child := range children {
+				if childSchema, ok := child.(map[string]interface{}); ok {
+					convertJSONSchemaKeywords(childSchema, refPrefix)
+				}
+			}
+		}
+	}
+	for _, keyword := range []string{"items", "additionalProperties", "not"} {
+		if childSchema, ok := schema[keyword].(map[string]interface{}); ok {
+			convertJSONSchemaKeywords(childSchema, refPrefix)
+		}
+	}
+	for _, keyword := range []string{"allOf", "anyOf", "oneOf"} {
+		if children, ok := schema[keyword].([]interface{}); ok {
+			for _, child := range children {
+				if childSchema, ok := child.(map[string]interface{}); ok {
+					convertJSONSchemaKeywords(childSchema, refPrefix)
+				}
+			}
+		}
+	}
+}
+
+// collectJSONSchemaDefs returns every component reachable from root through $refs
+func collectJSONSchemaDefs(root map[string]interface{}, components map[string]map[string]interface{}) map[string]interface{} {
+	defs := make(map[string]interface{})
+	pending := []interface{}{root}
+	for len(pending) > 0 {
+		node := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		switch value := node.(type) {
+		case map[string]interface{}:
+			if ref, ok := value["$ref"].(string); ok && strings.HasPrefix(ref, jsonSchemaDefsPrefix) {
+				name := strings.TrimPrefix(ref, jsonSchemaDefsPrefix)
+				if _, done := defs[name]; !done {
+					if component, ok := components[name]; ok {
+						defs[name] = component
+						pending = append(pending, component)
+					}
+				}
+			}
+			for _, child := range value {
+				pending = append(pending, child)
+			}
+		case []interface{}:
+			pending = append(pending, value...)
+		}
+	}
+	return defs
+}
@@ -0,0 +1,136 @@
+package openapi
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/astronomer/epoch/epoch"
+	"github.com/getkin/kin-openapi/openapi3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("JSON Schema export", func() {
+	var (
+		v1, v2    *epoch.Version
+		generator *SchemaGenerator
+	)
+
+	BeforeEach(func() {
+		v1, _ = epoch.NewDateVersion("2024-01-01")
+		v2, _ = epoch.NewDateVersion("2024-06-01")
+		versionBundle, err := epoch.NewVersionBundle([]*epoch.Version{v1, v2})
+		Expect(err).NotTo(HaveOccurred())
+
+		v1.Changes = []epoch.VersionChangeInterface{
+			epoch.NewVersionChangeBuilder(v1, v2).
+				ForType(OrgMember{}).
+				ResponseToPreviousVersion().
+				RemoveField("email").
+				Build(),
+		}
+
+		registry := epoch.NewEndpointRegistry()
+		registry.Register("GET", "/orgs/:id", &epoch.EndpointDefinition{
+			Method:       "GET",
+			PathPattern:  "/orgs/:id",
+			ResponseType: reflect.TypeOf(OrganizationResponse{}),
+		})
+		registry.Register("POST", "/users", &epoch.EndpointDefinition{
+			Method:      "POST",
+			PathPattern: "/users",
+			RequestType: reflect.TypeOf([]TestUserRequest{}),
+		})
+
+		generator = NewSchemaGenerator(SchemaGeneratorConfig{
+			VersionBundle: versionBundle,
+			TypeRegistry:  registry,
+		})
+	})
+
+	decode := func(documents map[string][]byte, name string) map[string]interface{} {
+		Expect(documents).To(HaveKey(name))
+		var document map[string]interface{}
+		Expect(json.Unmarshal(documents[name], &document)).To(Succeed())
+		return document
+	}
+
+	It("should emit one self-contained document per registered type", func() {
+		documents, err := generator.GenerateJSONSchemas(v2)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(documents).To(HaveLen(2))
+
+		org := decode(documents, "OrganizationResponse")
+		Expect(org["$schema"]).To(Equal(JSONSchemaDialect))
+		Expect(org["title"]).To(Equal("OrganizationResponse"))
+
+		members := org["properties"].(map[string]interface{})["members"].(map[string]interface{})
+		Expect(members["additionalProperties"]).To(HaveKeyWithValue("$ref", "#/$defs/OrgMember"))
+
+		defs := org["$defs"].(map[string]interface{})
+		Expect(defs).To(HaveKey("OrgMember"))
+		Expect(defs).To(HaveKey("OrgSettings"))
+
+		// Slice request types export their element type
+		user := decode(documents, "TestUserRequest")
+		Expect(user["required"]).To(ConsistOf("name", "email"))
+	})
+
+	It("should reflect each version's migrations", func() {
+		headDocs, err := generator.GenerateJSONSchemas(epoch.NewHeadVersion())
+		Expect(err).NotTo(HaveOccurred())
+		v1Docs, err := generator.GenerateJSONSchemas(v1)
+		Expect(err).NotTo(HaveOccurred())
+
+		memberProperties := func(documents map[string][]byte) map[string]interface{} {
+			defs := decode(documents, "OrganizationResponse")["$defs"].(map[string]interface{})
+			return defs["OrgMember"].(map[string]interface{})["properties"].(map[string]interface{})
+		}
+		Expect(memberProperties(headDocs)).To(HaveKey("email"))
+		Expect(memberProperties(v1Docs)).NotTo(HaveKey("email"))
+	})
+
+	It("should translate OpenAPI-only keywords", func() {
+		min := 0.0
+		schema, err := toJSONSchema(openapi3.NewSchemaRef("", &openapi3.Schema{
+			Type: &openapi3.Types{"object"},
+			Properties: openapi3.Schemas{
+				"nickname": openapi3.NewSchemaRef("", &openapi3.Schema{
+					Type:     &openapi3.Types{"string"},
+					Nullable: true,
+					Example:  map[string]interface{}{"nullable": true},
+				}),
+				"score": openapi3.NewSchemaRef("", &openapi3.Schema{
+					Type:         &openapi3.Types{"number"},
+					Min:          &min,
+					ExclusiveMin: true,
+				}),
+			},
+		}), jsonSchemaDefsPrefix)
+		Expect(err).NotTo(HaveOccurred())
+
+		properties := schema["properties"].(map[string]interface{})
+		nickname := properties["nickname"].(map[string]interface{})
+		Expect(nickname["type"]).To(Equal([]interface{}{"string", "null"}))
+		Expect(nickname).NotTo(HaveKey("nullable"))
+		Expect(nickname["examples"]).To(Equal([]interface{}{map[string]interface{}{"nullable": true}}))
+
+		score := properties["score"].(map[string]interface{})
+		Expect(score["exclusiveMinimum"]).To(Equal(0.0))
+		Expect(score).NotTo(HaveKey("minimum"))
+	})
+
+	It("should write documents to a directory", func() {
+		documents, err := generator.GenerateJSONSchemas(v1)
+		Expect(err).NotTo(HaveOccurred())
+
+		dir := filepath.Join(GinkgoT().TempDir(), "schemas", v1.String())
+		Expect(generator.WriteJSONSchemas(documents, dir)).To(Succeed())
+
+		data, err := os.ReadFile(filepath.Join(dir, "OrganizationResponse.schema.json"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(Equal(documents["OrganizationResponse"]))
+	})
+})
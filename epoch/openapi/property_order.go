@@ -0,0 +1,198 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// recordPropertyOrders remembers the struct field order of each component's Go type,
+// so the component's properties are written in that order
+func (w *Writer) recordPropertyOrders(componentTypes map[string]reflect.Type) {
+	if w.propertyOrder == nil {
+		w.propertyOrder = make(map[string][]string)
+	}
+	for componentName, typ := range componentTypes {
+		if typ.Kind() == reflect.Struct {
+			w.propertyOrder[componentName] = structPropertyOrder(typ)
+		}
+	}
+}
+
+// structPropertyOrder returns the JSON names of a struct's fields in declaration order,
+// with the fields of untagged embedded structs promoted in place like encoding/json does
+func structPropertyOrder(t reflect.Type) []string {
+	tagParser := NewTagParser()
+	seen := make(map[string]bool)
+	var names []string
+
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() && !field.Anonymous {
+				continue
+			}
+			jsonTag := field.Tag.Get("json")
+			if jsonTag == "-" {
+				continue
+			}
+
+			fieldName, _ := tagParser.ParseJSONTag(jsonTag)
+			if field.Anonymous && fieldName == "" {
+				embedded := field.Type
+				if embedded.Kind() == reflect.Ptr {
+					embedded = embedded.Elem()
+				}
+				if embedded.Kind() == reflect.Struct {
+					walk(embedded)
+					continue
+				}
+			}
+			if !field.IsExported() {
+				continue
+			}
+			if fieldName == "" {
+				fieldName = field.Name
+			}
+			if !seen[fieldName] {
+				seen[fieldName] = true
+				names = append(names, fieldName)
+			}
+		}
+	}
+	walk(t)
+	return names
+}
+
+// orderProperties rewrites a marshalled spec so the properties of components with a recorded
+// order come in that order. Encoders sort map keys, so the spec is reordered as a yaml.Node,
+// which also parses JSON, and encoded again from the node.
+func (w *Writer) orderProperties(data []byte) ([]byte, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to order properties: %w", err)
+	}
+	if len(root.Content) == 0 {
+		return data, nil
+	}
+	document := root.Content[0]
+
+	schemas := mappingValue(mappingValue(document, "components"), "schemas")
+	if schemas != nil {
+		for i := 0; i+1 < len(schemas.Content); i += 2 {
+			if order, ok := w.propertyOrder[schemas.Content[i].Value]; ok {
+				reorderMapping(mappingValue(schemas.Content[i+1], "properties"), order)
+			}
+		}
+	}
+
+	if w.format == "json" {
+		var buf bytes.Buffer
+		if err := writeJSONNode(&buf, document); err != nil {
+			return nil, fmt.Errorf("failed to order properties: %w", err)
+		}
+		return buf.Bytes(), nil
+	}
+	ordered, err := yaml.Marshal(&root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to order properties: %w", err)
+	}
+	return ordered, nil
+}
+
+// mappingValue returns the value of a key in a mapping node, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// reorderMapping puts the keys of a mapping node in the given order; keys not in it keep
+// their relative order after the ordered ones
+func reorderMapping(node *yaml.Node, order []string) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return
+	}
+	rank := make(map[string]int, len(order))
+	for i, name := range order {
+		rank[name] = i
+	}
+
+	pairs := make([][2]*yaml.Node, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		pairs = append(pairs, [2]*yaml.Node{node.Content[i], node.Content[i+1]})
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		ri, iRanked := rank[pairs[i][0].Value]
+		rj, jRanked := rank[pairs[j][0].Value]
+		if iRanked && jRanked {
+			return ri < rj
+		}
+		return iRanked && !jRanked
+	})
+
+	content := make([]*yaml.Node, 0, len(node.Content))
+	for _, pair := range pairs {
+		content = append(content, pair[0], pair[1])
+	}
+	node.Content = content
+}
+
+// writeJSONNode encodes a node parsed from JSON back to compact JSON, keeping key order
+func writeJSONNode(buf *bytes.Buffer, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.MappingNode:
+		buf.WriteByte('{')
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, err := json.Marshal(node.Content[i].Value)
+			if err != nil {
+				return err
+			}
+			buf.Write(key)
+			buf.WriteByte(':')
+			if err := writeJSONNode(buf, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, item := range node.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeJSONNode(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case yaml.ScalarNode:
+		if node.Tag != "!!str" {
+			// Numbers, booleans and null are written as they were read
+			buf.WriteString(node.Value)
+			return nil
+		}
+		value, err := json.Marshal(node.Value)
+		if err != nil {
+			return err
+		}
+		buf.Write(value)
+	default:
+		return fmt.Errorf("unexpected node kind %d", node.Kind)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package openapi
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/astronomer/epoch/epoch"
+	"github.com/getkin/kin-openapi/openapi3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
+)
+
+type OrderedAudit struct {
+	CreatedBy string `json:"created_by"`
+	CreatedAt string `json:"created_at"`
+}
+
+type orderedProject struct {
+	Name string `json:"name"`
+	ID   int    `json:"id"`
+	OrderedAudit
+	Zone   string `json:"zone"`
+	Secret string `json:"-"`
+	Active bool
+}
+
+type orderedTask struct {
+	Title    string `json:"title"`
+	Assignee string `json:"assignee"`
+}
+
+var _ = Describe("Property Order", func() {
+	var (
+		v1, v2   *epoch.Version
+		registry *epoch.EndpointRegistry
+		bundle   *epoch.VersionBundle
+	)
+
+	BeforeEach(func() {
+		v1, _ = epoch.NewDateVersion("2024-01-01")
+		v2, _ = epoch.NewDateVersion("2025-01-01")
+		change := epoch.NewVersionChangeBuilder(v1, v2).
+			ForType(orderedProject{}).
+			ResponseToPreviousVersion().
+			RenameField("zone", "area").
+			Build()
+		var err error
+		bundle, err = epoch.NewVersionBundle([]*epoch.Version{v1, v2})
+		Expect(err).NotTo(HaveOccurred())
+		v1.Changes = []epoch.VersionChangeInterface{change}
+
+		registry = epoch.NewEndpointRegistry()
+		registry.Register("GET", "/projects/:id", &epoch.EndpointDefinition{
+			Method:       "GET",
+			PathPattern:  "/projects/:id",
+			ResponseType: reflect.TypeOf(orderedProject{}),
+		})
+		registry.Register("POST", "/tasks", &epoch.EndpointDefinition{
+			Method:       "POST",
+			PathPattern:  "/tasks",
+			RequestType:  reflect.TypeOf(orderedTask{}),
+			ResponseType: reflect.TypeOf(orderedTask{}),
+		})
+		registry.Register("GET", "/users/:id", &epoch.EndpointDefinition{
+			Method:       "GET",
+			PathPattern:  "/users/:id",
+			ResponseType: reflect.TypeOf(TestUserResponse{}),
+		})
+	})
+
+	baseSpec := func() *openapi3.T {
+		return &openapi3.T{
+			OpenAPI:    "3.0.3",
+			Info:       &openapi3.Info{Title: "API", Version: "head"},
+			Paths:      openapi3.NewPaths(),
+			Components: &openapi3.Components{Schemas: openapi3.Schemas{}},
+		}
+	}
+
+	write := func(config SchemaGeneratorConfig) string {
+		config.VersionBundle = bundle
+		config.TypeRegistry = registry
+		generator := NewSchemaGenerator(config)
+		specs, err := generator.GenerateVersionedSpecs(baseSpec())
+		Expect(err).NotTo(HaveOccurred())
+
+		dir := GinkgoT().TempDir()
+		pattern := filepath.Join(dir, "%s."+generator.config.OutputFormat)
+		Expect(generator.WriteVersionedSpecs(specs, pattern)).To(Succeed())
+		return pattern
+	}
+
+	// propertyNames reads the property names of a component in the order they were written
+	propertyNames := func(path, component string) []string {
+		data, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		var root yaml.Node
+		Expect(yaml.Unmarshal(data, &root)).To(Succeed())
+
+		schemas := mappingValue(mappingValue(root.Content[0], "components"), "schemas")
+		properties := mappingValue(mappingValue(schemas, component), "properties")
+		Expect(properties).NotTo(BeNil())
+		var names []string
+		for i := 0; i < len(properties.Content); i += 2 {
+			names = append(names, properties.Content[i].Value)
+		}
+		return names
+	}
+
+	It("should write identical specs on every run", func() {
+		for _, format := range []string{"yaml", "json"} {
+			first := write(SchemaGeneratorConfig{OutputFormat: format})
+			for i := 0; i < 5; i++ {
+				next := write(SchemaGeneratorConfig{OutputFormat: format})
+				for _, version := range []string{"head", "2024-01-01", "2025-01-01"} {
+					want, err := os.ReadFile(fmt.Sprintf(first, version))
+					Expect(err).NotTo(HaveOccurred())
+					got, err := os.ReadFile(fmt.Sprintf(next, version))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(got)).To(Equal(string(want)), "%s spec of %s changed between runs", format, version)
+				}
+			}
+		}
+	})
+
+	It("should sort properties alphabetically by default", func() {
+		pattern := write(SchemaGeneratorConfig{})
+		Expect(propertyNames(fmt.Sprintf(pattern, "head"), "orderedProject")).To(Equal([]string{
+			"Active", "created_at", "created_by", "id", "name", "zone",
+		}))
+	})
+
+	It("should write properties in struct order", func() {
+		for _, format := range []string{"yaml", "json"} {
+			pattern := write(SchemaGeneratorConfig{OutputFormat: format, PropertyOrder: PropertyOrderStruct})
+			Expect(propertyNames(fmt.Sprintf(pattern, "head"), "orderedProject")).To(Equal([]string{
+				"name", "id", "created_by", "created_at", "zone", "Active",
+			}))
+			Expect(propertyNames(fmt.Sprintf(pattern, "head"), "orderedTask")).To(Equal([]string{"title", "assignee"}))
+		}
+	})
+
+	It("should put properties missing from the struct last", func() {
+		pattern := write(SchemaGeneratorConfig{PropertyOrder: PropertyOrderStruct})
+		Expect(propertyNames(fmt.Sprintf(pattern, "2024-01-01"), "orderedProject")).To(Equal([]string{
+			"name", "id", "created_by", "created_at", "Active", "area",
+		}))
+	})
+
+	It("should keep JSON specs valid when reordering", func() {
+		pattern := write(SchemaGeneratorConfig{OutputFormat: "json", PropertyOrder: PropertyOrderStruct})
+		spec, err := LoadSpecFile(fmt.Sprintf(pattern, "head"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(spec.Components.Schemas).To(HaveKey("orderedProject"))
+		Expect(spec.Components.Schemas["orderedProject"].Value.Properties).To(HaveLen(6))
+	})
+
+	Describe("structPropertyOrder", func() {
+		It("should follow encoding/json field naming", func() {
+			Expect(structPropertyOrder(reflect.TypeOf(orderedProject{}))).To(Equal([]string{
+				"name", "id", "created_by", "created_at", "zone", "Active",
+			}))
+		})
+	})
+})
@@ -0,0 +1,108 @@
+package openapi
+
+import (
+	"github.com/astronomer/epoch/epoch"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// applyQueryParamsForVersion rewrites the query parameters of endpoints whose parameters
+// changed between version and HEAD (ForEndpoint().RenameQueryParam/TranslatePagination)
+func (sg *SchemaGenerator) applyQueryParamsForVersion(spec *openapi3.T, version *epoch.Version) {
+	if spec.Paths == nil || spec.Paths.Len() == 0 {
+		return
+	}
+
+	// Undo request changes newest first to get from HEAD's parameters back to version's
+	var changes []*epoch.VersionChange
+	all := sg.transformer.allVersionChanges()
+	for i := len(all) - 1; i >= 0; i-- {
+		if all[i].ToVersion().IsNewerThan(version) {
+			changes = append(changes, all[i])
+		}
+	}
+
+	pathsCopied := false
+	for _, endpoint := range sg.sortedEndpoints() {
+		var ops []epoch.RequestQueryOperation
+		for _, change := range changes {
+			changeOps := change.GetRequestQueryOperations(endpoint.Method, endpoint.PathPattern)
+			for i := len(changeOps) - 1; i >= 0; i-- {
+				ops = append(ops, changeOps[i])
+			}
+		}
+		if len(ops) == 0 {
+			continue
+		}
+
+		operation := copyOperation(spec, &pathsCopied, ginPathToOpenAPI(endpoint.PathPattern), endpoint.Method)
+		if operation == nil {
+			continue
+		}
+		parameters := append(openapi3.Parameters{}, operation.Parameters...)
+		for _, op := range ops {
+			parameters = invertQueryOperation(parameters, op)
+		}
+		operation.Parameters = parameters
+	}
+}
+
+// invertQueryOperation returns the parameters an older client sent before op translated them
+func invertQueryOperation(parameters openapi3.Parameters, op epoch.RequestQueryOperation) openapi3.Parameters {
+	switch operation := op.(type) {
+	case *epoch.RequestRenameQueryParam:
+		for i, param := range parameters {
+			if isQueryParameter(param, operation.NewerVersionName) {
+				renamed := *param.Value
+				renamed.Name = operation.OlderVersionName
+				parameters[i] = &openapi3.ParameterRef{Value: &renamed}
+			}
+		}
+
+	case *epoch.RequestPagination:
+		config := operation.Config.WithDefaults()
+		kept := parameters[:0]
+		for _, param := range parameters {
+			if !isQueryParameter(param, config.CursorParam) && !isQueryParameter(param, config.LimitParam) {
+				kept = append(kept, param)
+			}
+		}
+
+		minimum := 1.0
+		page := openapi3.NewIntegerSchema()
+		page.Min = &minimum
+		perPage := openapi3.NewIntegerSchema()
+		perPage.Min = &minimum
+		if config.DefaultPerPage > 0 {
+			perPage.Default = config.DefaultPerPage
+		}
+		parameters = append(kept,
+			&openapi3.ParameterRef{Value: openapi3.NewQueryParameter(config.PageParam).
+				WithDescription("Page number, starting at 1").WithSchema(page)},
+			&openapi3.ParameterRef{Value: openapi3.NewQueryParameter(config.PerPageParam).
+				WithDescription("Number of items per page").WithSchema(perPage)},
+		)
+	}
+	return parameters
+}
+
+// paginationMetadataSchema adds the older page-based metadata properties to a list response schema
+func paginationMetadataSchema(schemaRef *openapi3.SchemaRef, config epoch.PaginationConfig) *openapi3.SchemaRef {
+	config = config.WithDefaults()
+	metadata := &openapi3.Schema{
+		Type: &openapi3.Types{"object"},
+		Properties: openapi3.Schemas{
+			config.PageField:    openapi3.NewSchemaRef("", openapi3.NewIntegerSchema()),
+			config.PerPageField: openapi3.NewSchemaRef("", openapi3.NewIntegerSchema()),
+		},
+		Required: []string{config.PageField},
+	}
+	if config.TotalField != "" {
+		metadata.Properties[config.TotalPagesField] = openapi3.NewSchemaRef("", openapi3.NewIntegerSchema())
+	}
+	return openapi3.NewSchemaRef("", &openapi3.Schema{AllOf: openapi3.SchemaRefs{schemaRef, openapi3.NewSchemaRef("", metadata)}})
+}
+
+// isQueryParameter reports whether param is the inline query parameter called name
+func isQueryParameter(param *openapi3.ParameterRef, name string) bool {
+	return param != nil && param.Value != nil && param.Value.In == openapi3.ParameterInQuery && param.Value.Name == name
+}
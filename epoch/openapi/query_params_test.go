@@ -0,0 +1,101 @@
+package openapi
+
+import (
+	"reflect"
+
+	"github.com/astronomer/epoch/epoch"
+	"github.com/getkin/kin-openapi/openapi3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Query parameter changes", func() {
+	var (
+		v1, v2    *epoch.Version
+		generator *SchemaGenerator
+		baseSpec  *openapi3.T
+	)
+
+	BeforeEach(func() {
+		v1, _ = epoch.NewDateVersion("2024-01-01")
+		v2, _ = epoch.NewDateVersion("2024-06-01")
+		versionBundle, err := epoch.NewVersionBundle([]*epoch.Version{v1, v2})
+		Expect(err).NotTo(HaveOccurred())
+
+		v1.Changes = []epoch.VersionChangeInterface{
+			epoch.NewVersionChangeBuilder(v1, v2).
+				ForEndpoint("GET", "/users").
+				RenameQueryParam("q", "search").
+				TranslatePagination(epoch.PaginationConfig{DefaultPerPage: 20, TotalField: "total"}).
+				Build(),
+		}
+
+		registry := epoch.NewEndpointRegistry()
+		registry.Register("GET", "/users", &epoch.EndpointDefinition{
+			Method:       "GET",
+			PathPattern:  "/users",
+			ResponseType: reflect.TypeOf(TestUserResponse{}),
+		})
+
+		generator = NewSchemaGenerator(SchemaGeneratorConfig{
+			VersionBundle: versionBundle,
+			TypeRegistry:  registry,
+		})
+
+		baseSpec = &openapi3.T{
+			OpenAPI:    "3.0.3",
+			Info:       &openapi3.Info{Title: "Test", Version: "1.0"},
+			Paths:      openapi3.NewPaths(),
+			Components: &openapi3.Components{Schemas: openapi3.Schemas{}},
+		}
+		baseSpec.Paths.Set("/users", &openapi3.PathItem{Get: &openapi3.Operation{
+			Parameters: openapi3.Parameters{
+				{Value: openapi3.NewQueryParameter("search").WithSchema(openapi3.NewStringSchema())},
+				{Value: openapi3.NewQueryParameter("cursor").WithSchema(openapi3.NewStringSchema())},
+				{Value: openapi3.NewQueryParameter("limit").WithSchema(openapi3.NewIntegerSchema())},
+			},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, &openapi3.ResponseRef{Value: openapi3.NewResponse().
+					WithDescription("OK").
+					WithJSONSchemaRef(openapi3.NewSchemaRef("#/components/schemas/TestUserResponse", nil))}),
+			),
+		}})
+	})
+
+	parameterNames := func(spec *openapi3.T) []string {
+		var names []string
+		for _, param := range spec.Paths.Value("/users").Get.Parameters {
+			names = append(names, param.Value.Name)
+		}
+		return names
+	}
+
+	It("should document the parameters older clients send", func() {
+		spec, err := generator.GenerateSpecForVersion(baseSpec, v1)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(parameterNames(spec)).To(Equal([]string{"q", "page", "per_page"}))
+		perPage := spec.Paths.Value("/users").Get.Parameters.GetByInAndName("query", "per_page")
+		Expect(perPage.Schema.Value.Default).To(Equal(20))
+	})
+
+	It("should add the page metadata to success responses", func() {
+		spec, err := generator.GenerateSpecForVersion(baseSpec, v1)
+		Expect(err).NotTo(HaveOccurred())
+
+		schema := spec.Paths.Value("/users").Get.Responses.Status(200).Value.Content.Get("application/json").Schema
+		Expect(schema.Value.AllOf).To(HaveLen(2))
+		Expect(schema.Value.AllOf[0].Ref).To(Equal("#/components/schemas/TestUserResponse"))
+		Expect(schema.Value.AllOf[1].Value.Properties).To(HaveKey("total_pages"))
+	})
+
+	It("should leave HEAD and the base spec untouched", func() {
+		spec, err := generator.GenerateSpecForVersion(baseSpec, epoch.NewHeadVersion())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(parameterNames(spec)).To(Equal([]string{"search", "cursor", "limit"}))
+
+		_, err = generator.GenerateSpecForVersion(baseSpec, v1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(parameterNames(baseSpec)).To(Equal([]string{"search", "cursor", "limit"}))
+	})
+})
@@ -0,0 +1,125 @@
+package openapi
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/astronomer/epoch/epoch"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// applyResponseHeadersForVersion documents the response headers older clients receive for
+// endpoints whose response types declare header operations (AddHeader, RemoveHeader, ...)
+func (sg *SchemaGenerator) applyResponseHeadersForVersion(spec *openapi3.T, version *epoch.Version) {
+	if spec.Paths == nil || spec.Paths.Len() == 0 {
+		return
+	}
+
+	// Responses migrate newest change first
+	var changes []*epoch.VersionChange
+	all := sg.transformer.allVersionChanges()
+	for i := len(all) - 1; i >= 0; i-- {
+		if all[i].ToVersion().IsNewerThan(version) {
+			changes = append(changes, all[i])
+		}
+	}
+
+	pathsCopied := false
+	for _, endpoint := range sg.sortedEndpoints() {
+		var ops []epoch.ResponseToPreviousVersionOperation
+		for _, change := range changes {
+			for _, t := range epoch.CollectMigratableTypes(endpoint.ResponseType) {
+				typeOps, _ := change.GetResponseOperationsByType(t)
+				for _, op := range typeOps {
+					if isHeaderOperation(op) {
+						ops = append(ops, op)
+					}
+				}
+			}
+		}
+		if len(ops) == 0 {
+			continue
+		}
+
+		operation := copyOperation(spec, &pathsCopied, ginPathToOpenAPI(endpoint.PathPattern), endpoint.Method)
+		if operation == nil || operation.Responses == nil {
+			continue
+		}
+		operation.Responses = headerResponses(operation.Responses, ops)
+	}
+}
+
+// isHeaderOperation reports whether op changes response headers
+func isHeaderOperation(op epoch.ResponseToPreviousVersionOperation) bool {
+	switch op.(type) {
+	case *epoch.ResponseAddHeader, *epoch.ResponseAddHeaderFromField, *epoch.ResponseRemoveHeader, *epoch.ResponseRenameHeader:
+		return true
+	}
+	return false
+}
+
+// headerResponses copies responses, applying header operations to every 2xx response
+func headerResponses(responses *openapi3.Responses, ops []epoch.ResponseToPreviousVersionOperation) *openapi3.Responses {
+	result := openapi3.NewResponsesWithCapacity(responses.Len())
+	result.Extensions = responses.Extensions
+	for code, responseRef := range responses.Map() {
+		if !strings.HasPrefix(code, "2") || responseRef == nil || responseRef.Value == nil || responseRef.Ref != "" {
+			result.Set(code, responseRef)
+			continue
+		}
+
+		response := *responseRef.Value
+		response.Headers = make(openapi3.Headers, len(responseRef.Value.Headers))
+		for name, header := range responseRef.Value.Headers {
+			response.Headers[name] = header
+		}
+		for _, op := range ops {
+			applyHeaderOperation(response.Headers, op)
+		}
+		if len(response.Headers) == 0 {
+			response.Headers = nil
+		}
+		result.Set(code, &openapi3.ResponseRef{Value: &response, Extensions: responseRef.Extensions})
+	}
+	return result
+}
+
+// applyHeaderOperation applies one header operation to a response's documented headers
+func applyHeaderOperation(headers openapi3.Headers, op epoch.ResponseToPreviousVersionOperation) {
+	stringHeader := func() *openapi3.HeaderRef {
+		return &openapi3.HeaderRef{Value: &openapi3.Header{Parameter: openapi3.Parameter{
+			Schema: openapi3.NewSchemaRef("", openapi3.NewStringSchema()),
+		}}}
+	}
+
+	switch operation := op.(type) {
+	case *epoch.ResponseAddHeader:
+		if headerKey(headers, operation.Name) == "" {
+			headers[http.CanonicalHeaderKey(operation.Name)] = stringHeader()
+		}
+	case *epoch.ResponseAddHeaderFromField:
+		if headerKey(headers, operation.Name) == "" {
+			header := stringHeader()
+			header.Value.Description = "Value of the " + operation.Field + " field"
+			headers[http.CanonicalHeaderKey(operation.Name)] = header
+		}
+	case *epoch.ResponseRemoveHeader:
+		delete(headers, headerKey(headers, operation.Name))
+	case *epoch.ResponseRenameHeader:
+		if key := headerKey(headers, operation.NewerVersionName); key != "" {
+			header := headers[key]
+			delete(headers, key)
+			headers[http.CanonicalHeaderKey(operation.OlderVersionName)] = header
+		}
+	}
+}
+
+// headerKey returns the documented name of a header, matched case-insensitively like HTTP does
+func headerKey(headers openapi3.Headers, name string) string {
+	for key := range headers {
+		if strings.EqualFold(key, name) {
+			return key
+		}
+	}
+	return ""
+}
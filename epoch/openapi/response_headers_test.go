@@ -0,0 +1,95 @@
+package openapi
+
+import (
+	"reflect"
+
+	"github.com/astronomer/epoch/epoch"
+	"github.com/getkin/kin-openapi/openapi3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Response header changes", func() {
+	var (
+		v1, v2    *epoch.Version
+		generator *SchemaGenerator
+		baseSpec  *openapi3.T
+	)
+
+	BeforeEach(func() {
+		v1, _ = epoch.NewDateVersion("2024-01-01")
+		v2, _ = epoch.NewDateVersion("2024-06-01")
+		versionBundle, err := epoch.NewVersionBundle([]*epoch.Version{v1, v2})
+		Expect(err).NotTo(HaveOccurred())
+
+		v1.Changes = []epoch.VersionChangeInterface{
+			epoch.NewVersionChangeBuilder(v1, v2).
+				ForType(TestUserResponse{}).
+				ResponseToPreviousVersion().
+				AddHeaderFromField("X-Total-Count", "total").
+				RenameHeader("Request-Id", "X-Request-Id").
+				RemoveHeader("ratelimit-limit").
+				Build(),
+		}
+
+		registry := epoch.NewEndpointRegistry()
+		registry.Register("GET", "/users", &epoch.EndpointDefinition{
+			Method:       "GET",
+			PathPattern:  "/users",
+			ResponseType: reflect.TypeOf(TestUserResponse{}),
+		})
+
+		generator = NewSchemaGenerator(SchemaGeneratorConfig{
+			VersionBundle: versionBundle,
+			TypeRegistry:  registry,
+		})
+
+		stringHeader := &openapi3.HeaderRef{Value: &openapi3.Header{Parameter: openapi3.Parameter{
+			Schema: openapi3.NewSchemaRef("", openapi3.NewStringSchema()),
+		}}}
+		baseSpec = &openapi3.T{
+			OpenAPI:    "3.0.3",
+			Info:       &openapi3.Info{Title: "Test", Version: "1.0"},
+			Paths:      openapi3.NewPaths(),
+			Components: &openapi3.Components{Schemas: openapi3.Schemas{}},
+		}
+		ok := openapi3.NewResponse().
+			WithDescription("OK").
+			WithJSONSchemaRef(openapi3.NewSchemaRef("#/components/schemas/TestUserResponse", nil))
+		ok.Headers = openapi3.Headers{"Request-Id": stringHeader, "RateLimit-Limit": stringHeader}
+		baseSpec.Paths.Set("/users", &openapi3.PathItem{Get: &openapi3.Operation{
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, &openapi3.ResponseRef{Value: ok}),
+				openapi3.WithStatus(404, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("Not found")}),
+			),
+		}})
+	})
+
+	headerNames := func(spec *openapi3.T, status int) []string {
+		var names []string
+		for name := range spec.Paths.Value("/users").Get.Responses.Status(status).Value.Headers {
+			names = append(names, name)
+		}
+		return names
+	}
+
+	It("should document the headers older clients receive", func() {
+		spec, err := generator.GenerateSpecForVersion(baseSpec, v1)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(headerNames(spec, 200)).To(ConsistOf("X-Total-Count", "X-Request-Id"))
+		Expect(spec.Paths.Value("/users").Get.Responses.Status(200).Value.Headers["X-Total-Count"].Value.Description).
+			To(Equal("Value of the total field"))
+		Expect(headerNames(spec, 404)).To(BeEmpty())
+	})
+
+	It("should leave HEAD and the base spec untouched", func() {
+		spec, err := generator.GenerateSpecForVersion(baseSpec, epoch.NewHeadVersion())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(headerNames(spec, 200)).To(ConsistOf("Request-Id", "RateLimit-Limit"))
+
+		_, err = generator.GenerateSpecForVersion(baseSpec, v1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(headerNames(baseSpec, 200)).To(ConsistOf("Request-Id", "RateLimit-Limit"))
+	})
+})
@@ -0,0 +1,84 @@
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// typeSchemaName returns the name SchemaNames or SchemaTypeNameMapper gives a type, if any
+func (sg *SchemaGenerator) typeSchemaName(typ reflect.Type) (string, bool) {
+	if name := sg.config.SchemaNames[typ]; name != "" {
+		return name, true
+	}
+	if sg.config.SchemaTypeNameMapper != nil {
+		if name := sg.config.SchemaTypeNameMapper(typ); name != "" {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// componentName returns the name of the component generated for a named type
+func (sg *SchemaGenerator) componentName(typ reflect.Type) string {
+	if name, ok := sg.typeSchemaName(typ); ok {
+		return name
+	}
+	return typ.Name()
+}
+
+// mappedSchemaName returns the name a type's schema is looked up by in the base spec
+func (sg *SchemaGenerator) mappedSchemaName(typ reflect.Type) string {
+	if name, ok := sg.typeSchemaName(typ); ok {
+		return name
+	}
+	return sg.config.SchemaNameMapper(typ.Name())
+}
+
+// checkComponentNames fails when two different Go types of a version's spec would be
+// written to the same component, which would leave one described by the other's schema
+func (sg *SchemaGenerator) checkComponentNames(baseSpec *openapi3.T, types []reflect.Type, versionKey string) error {
+	owners := make(map[string]reflect.Type)
+	reported := make(map[string]bool)
+	var collisions []string
+	claim := func(name string, typ reflect.Type) {
+		// Anonymous structs get synthetic names and are matched by their properties instead
+		if name == "" || typ.Name() == "" {
+			return
+		}
+		owner, ok := owners[name]
+		if !ok {
+			owners[name] = typ
+			return
+		}
+		if owner != typ && !reported[name] {
+			reported[name] = true
+			collisions = append(collisions, fmt.Sprintf("%q is used by both %s and %s", name, typeDisplayName(owner), typeDisplayName(typ)))
+		}
+	}
+
+	for _, typ := range sg.typesToGenerate[versionKey] {
+		claim(sg.getComponentNameForType(versionKey, typ), typ)
+	}
+	for _, typ := range types {
+		if typ.Kind() == reflect.Struct {
+			claim(sg.schemaNameForType(baseSpec, typ), typ)
+		}
+	}
+
+	if len(collisions) > 0 {
+		return fmt.Errorf("schema name collision: %s; give the types distinct names with SchemaNames or SchemaTypeNameMapper",
+			strings.Join(collisions, "; "))
+	}
+	return nil
+}
+
+// typeDisplayName names a type with its full package path, e.g. github.com/acme/api/billing.Account
+func typeDisplayName(typ reflect.Type) string {
+	if typ.PkgPath() == "" {
+		return typ.String()
+	}
+	return typ.PkgPath() + "." + typ.Name()
+}
@@ -0,0 +1,150 @@
+package openapi
+
+import (
+	"reflect"
+
+	"github.com/astronomer/epoch/epoch"
+	"github.com/getkin/kin-openapi/openapi3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// Two types named Account, as if from billing and auth packages
+var billingAccount, authAccount = func() (reflect.Type, reflect.Type) {
+	type Account struct {
+		Balance int `json:"balance"`
+	}
+	billing := reflect.TypeOf(Account{})
+	return billing, func() reflect.Type {
+		type Account struct {
+			Username string `json:"username"`
+		}
+		return reflect.TypeOf(Account{})
+	}()
+}()
+
+var _ = Describe("Schema Names", func() {
+	var (
+		registry *epoch.EndpointRegistry
+		bundle   *epoch.VersionBundle
+	)
+
+	BeforeEach(func() {
+		v1, _ := epoch.NewDateVersion("2024-01-01")
+		bundle, _ = epoch.NewVersionBundle([]*epoch.Version{v1})
+		registry = epoch.NewEndpointRegistry()
+		registry.Register("GET", "/billing/account", &epoch.EndpointDefinition{
+			Method:       "GET",
+			PathPattern:  "/billing/account",
+			ResponseType: billingAccount,
+		})
+		registry.Register("GET", "/auth/account", &epoch.EndpointDefinition{
+			Method:       "GET",
+			PathPattern:  "/auth/account",
+			ResponseType: authAccount,
+		})
+	})
+
+	generate := func(config SchemaGeneratorConfig) (map[string]*openapi3.T, error) {
+		config.VersionBundle = bundle
+		config.TypeRegistry = registry
+		return NewSchemaGenerator(config).GenerateVersionedSpecs(&openapi3.T{
+			OpenAPI:    "3.0.3",
+			Info:       &openapi3.Info{Title: "API", Version: "head"},
+			Paths:      openapi3.NewPaths(),
+			Components: &openapi3.Components{Schemas: openapi3.Schemas{}},
+		})
+	}
+
+	It("should fail when two types map to the same component name", func() {
+		_, err := generate(SchemaGeneratorConfig{})
+		Expect(err).To(MatchError(ContainSubstring(`schema name collision: "Account" is used by both`)))
+		Expect(err).To(MatchError(ContainSubstring("SchemaNames or SchemaTypeNameMapper")))
+	})
+
+	It("should detect collisions introduced by a mapper", func() {
+		registry = epoch.NewEndpointRegistry()
+		registry.Register("GET", "/users/:id", &epoch.EndpointDefinition{
+			Method:       "GET",
+			PathPattern:  "/users/:id",
+			ResponseType: reflect.TypeOf(TestUserResponse{}),
+		})
+		registry.Register("POST", "/users", &epoch.EndpointDefinition{
+			Method:       "POST",
+			PathPattern:  "/users",
+			RequestType:  reflect.TypeOf(TestUserRequest{}),
+			ResponseType: reflect.TypeOf(TestUserResponse{}),
+		})
+		_, err := generate(SchemaGeneratorConfig{
+			SchemaTypeNameMapper: func(t reflect.Type) string { return "User" },
+		})
+		Expect(err).To(MatchError(ContainSubstring(`"User" is used by both`)))
+	})
+
+	It("should name components from SchemaNames", func() {
+		specs, err := generate(SchemaGeneratorConfig{
+			SchemaNames: map[reflect.Type]string{
+				billingAccount: "BillingAccount",
+				authAccount:    "AuthAccount",
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		schemas := specs["2024-01-01"].Components.Schemas
+		Expect(schemas).To(HaveKey("BillingAccount"))
+		Expect(schemas).To(HaveKey("AuthAccount"))
+		Expect(schemas).To(HaveLen(2))
+		Expect(schemas["BillingAccount"].Value.Properties).To(HaveKey("balance"))
+		Expect(schemas["AuthAccount"].Value.Properties).To(HaveKey("username"))
+	})
+
+	It("should name components from SchemaTypeNameMapper and fall back to the Go name", func() {
+		specs, err := generate(SchemaGeneratorConfig{
+			SchemaTypeNameMapper: func(t reflect.Type) string {
+				if t == authAccount {
+					return "AuthAccount"
+				}
+				return ""
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, version := range []string{"head", "2024-01-01"} {
+			schemas := specs[version].Components.Schemas
+			Expect(schemas).To(HaveLen(2))
+			Expect(schemas["Account"].Value.Properties).To(HaveKey("balance"))
+			Expect(schemas["AuthAccount"].Value.Properties).To(HaveKey("username"))
+		}
+	})
+
+	It("should use per-type names to find schemas in the base spec", func() {
+		config := SchemaGeneratorConfig{
+			VersionBundle: bundle,
+			TypeRegistry:  registry,
+			SchemaNames: map[reflect.Type]string{
+				billingAccount: "billing.Account",
+				authAccount:    "auth.Account",
+			},
+		}
+		baseSpec := &openapi3.T{
+			OpenAPI: "3.0.3",
+			Info:    &openapi3.Info{Title: "API", Version: "head"},
+			Paths:   openapi3.NewPaths(),
+			Components: &openapi3.Components{Schemas: openapi3.Schemas{
+				"billing.Account": openapi3.NewSchemaRef("", &openapi3.Schema{
+					Type:        &openapi3.Types{"object"},
+					Description: "From swag",
+					Properties: openapi3.Schemas{
+						"balance": openapi3.NewSchemaRef("", openapi3.NewIntegerSchema()),
+					},
+				}),
+			}},
+		}
+		specs, err := NewSchemaGenerator(config).GenerateVersionedSpecs(baseSpec)
+		Expect(err).NotTo(HaveOccurred())
+
+		schemas := specs["head"].Components.Schemas
+		Expect(schemas["billing.Account"].Value.Description).To(Equal("From swag"))
+		Expect(schemas["auth.Account"].Value.Properties).To(HaveKey("username"))
+	})
+})
@@ -0,0 +1,198 @@
+package openapi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/astronomer/epoch/epoch"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// VersionSecurity describes the security of versions whose auth differs from the base spec,
+// e.g. API keys in versions older than HEAD's OAuth scopes
+type VersionSecurity struct {
+	// SecuritySchemes replaces components.securitySchemes when not nil. Operation requirements
+	// naming a scheme it doesn't have are dropped, so those operations fall back to Security.
+	SecuritySchemes openapi3.SecuritySchemes
+
+	// Security replaces the top-level security requirements when not nil;
+	// an empty list documents no security
+	Security openapi3.SecurityRequirements
+
+	// Operations replaces the security of individual operations, keyed by "METHOD /path"
+	// with the path in gin or OpenAPI syntax, e.g. "GET /users/:id"
+	Operations map[string]openapi3.SecurityRequirements
+}
+
+// versionSecurity is security declared with WithVersionSecurity
+type versionSecurity struct {
+	version  *epoch.Version
+	security VersionSecurity
+}
+
+// WithVersionSecurity declares the security documented in version's spec. It also applies to
+// older versions, up to the next older version with security of its own, since auth usually
+// changes once and stays that way.
+func (sg *SchemaGenerator) WithVersionSecurity(version *epoch.Version, security VersionSecurity) *SchemaGenerator {
+	sg.versionSecurity = append(sg.versionSecurity, versionSecurity{version: version, security: security})
+	return sg
+}
+
+// securityForVersion returns the security declared for the oldest version not older than version
+func (sg *SchemaGenerator) securityForVersion(version *epoch.Version) (versionSecurity, bool) {
+	declared := make([]*epoch.Version, len(sg.versionSecurity))
+	for i, security := range sg.versionSecurity {
+		declared[i] = security.version
+	}
+	i := closestDeclaredVersion(version, declared)
+	if i < 0 {
+		return versionSecurity{}, false
+	}
+	return sg.versionSecurity[i], true
+}
+
+// closestDeclaredVersion returns the index of the oldest declared version not older than
+// version, or -1 if every declared version is older
+func closestDeclaredVersion(version *epoch.Version, declared []*epoch.Version) int {
+	closest := -1
+	for i, candidate := range declared {
+		if candidate.IsOlderThan(version) {
+			continue
+		}
+		if closest < 0 || candidate.IsOlderThan(declared[closest]) {
+			closest = i
+		}
+	}
+	return closest
+}
+
+// validateVersionSecurity checks the security declared with WithVersionSecurity once, before any
+// spec is generated, so errors name the version that declared the problem rather than every
+// older version it applies to
+func (sg *SchemaGenerator) validateVersionSecurity(baseSpec *openapi3.T) error {
+	var baseSchemes openapi3.SecuritySchemes
+	if baseSpec != nil && baseSpec.Components != nil {
+		baseSchemes = baseSpec.Components.SecuritySchemes
+	}
+	for _, declared := range sg.versionSecurity {
+		security, version := declared.security, declared.version.String()
+		schemes := baseSchemes
+		if security.SecuritySchemes != nil {
+			schemes = security.SecuritySchemes
+		}
+		if err := checkSecuritySchemeNames(security.Security, schemes, version); err != nil {
+			return err
+		}
+		for _, key := range sortedKeys(security.Operations) {
+			if _, _, ok := parseSecurityOperation(key); !ok {
+				return fmt.Errorf("invalid operation %q in security of version %s: use \"METHOD /path\"", key, version)
+			}
+			if err := checkSecuritySchemeNames(security.Operations[key], schemes, version); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkSecuritySchemeNames returns an error for the first requirement naming a scheme that
+// isn't in schemes
+func checkSecuritySchemeNames(requirements openapi3.SecurityRequirements, schemes openapi3.SecuritySchemes, version string) error {
+	for _, requirement := range requirements {
+		for _, name := range sortedKeys(requirement) {
+			if _, ok := schemes[name]; !ok {
+				return fmt.Errorf("unknown security scheme %q in security of version %s", name, version)
+			}
+		}
+	}
+	return nil
+}
+
+// parseSecurityOperation splits a "METHOD /path" operation key, converting gin paths to OpenAPI
+func parseSecurityOperation(key string) (string, string, bool) {
+	method, path, found := strings.Cut(strings.TrimSpace(key), " ")
+	path = strings.TrimSpace(path)
+	if !found || method == "" || !strings.HasPrefix(path, "/") {
+		return "", "", false
+	}
+	return strings.ToUpper(method), ginPathToOpenAPI(path), true
+}
+
+// applySecurityForVersion replaces the security schemes and requirements of version's spec
+// with those declared for it
+func (sg *SchemaGenerator) applySecurityForVersion(spec *openapi3.T, version *epoch.Version) error {
+	declared, ok := sg.securityForVersion(version)
+	if !ok {
+		return nil
+	}
+	security := declared.security
+
+	operations := make(map[string]openapi3.SecurityRequirements, len(security.Operations))
+	for key, requirements := range security.Operations {
+		method, path, ok := parseSecurityOperation(key)
+		if !ok {
+			return fmt.Errorf("invalid operation %q in security of version %s: use \"METHOD /path\"", key, declared.version.String())
+		}
+		operations[method+" "+path] = requirements
+	}
+
+	if security.SecuritySchemes != nil {
+		spec.Components.SecuritySchemes = security.SecuritySchemes
+	}
+	if security.Security != nil {
+		spec.Security = security.Security
+	}
+
+	if spec.Paths == nil {
+		return nil
+	}
+	pathsCopied := false
+	paths := spec.Paths.Map()
+	for _, path := range sortedKeys(paths) {
+		item := paths[path]
+		if item == nil {
+			continue
+		}
+		itemOperations := item.Operations()
+		for _, method := range sortedKeys(itemOperations) {
+			operation := itemOperations[method]
+			if requirements, ok := operations[method+" "+path]; ok {
+				operationCopy := copyOperation(spec, &pathsCopied, path, method)
+				operationCopy.Security = &requirements
+				continue
+			}
+			if security.SecuritySchemes == nil || operation.Security == nil {
+				continue
+			}
+			kept := knownSecurityRequirements(*operation.Security, security.SecuritySchemes)
+			if len(kept) == len(*operation.Security) {
+				continue
+			}
+			operationCopy := copyOperation(spec, &pathsCopied, path, method)
+			if len(kept) == 0 {
+				operationCopy.Security = nil // Fall back to the top-level security
+			} else {
+				operationCopy.Security = &kept
+			}
+		}
+	}
+	return nil
+}
+
+// knownSecurityRequirements returns the requirements whose schemes all exist
+func knownSecurityRequirements(requirements openapi3.SecurityRequirements, schemes openapi3.SecuritySchemes) openapi3.SecurityRequirements {
+	kept := openapi3.SecurityRequirements{}
+	for _, requirement := range requirements {
+		known := true
+		for name := range requirement {
+			if _, ok := schemes[name]; !ok {
+				known = false
+				break
+			}
+		}
+		if known {
+			kept = append(kept, requirement)
+		}
+	}
+	return kept
+}
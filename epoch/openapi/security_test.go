@@ -0,0 +1,133 @@
+package openapi
+
+import (
+	"github.com/astronomer/epoch/epoch"
+	"github.com/getkin/kin-openapi/openapi3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Version Security", func() {
+	var (
+		v0, v1, v2 *epoch.Version
+		generator  *SchemaGenerator
+		baseSpec   *openapi3.T
+	)
+
+	oauth := openapi3.SecuritySchemes{
+		"oauth": &openapi3.SecuritySchemeRef{Value: openapi3.NewOIDCSecurityScheme("https://auth.example.com/.well-known/openid-configuration")},
+	}
+	apiKey := openapi3.SecuritySchemes{
+		"apiKey": &openapi3.SecuritySchemeRef{Value: &openapi3.SecurityScheme{Type: "apiKey", In: "header", Name: "X-API-Key"}},
+	}
+
+	operation := func(spec *openapi3.T, method, path string) *openapi3.Operation {
+		return spec.Paths.Value(path).GetOperation(method)
+	}
+
+	BeforeEach(func() {
+		v0, _ = epoch.NewDateVersion("2023-01-01")
+		v1, _ = epoch.NewDateVersion("2024-01-01")
+		v2, _ = epoch.NewDateVersion("2025-01-01")
+		bundle, _ := epoch.NewVersionBundle([]*epoch.Version{v0, v1, v2})
+		generator = NewSchemaGenerator(SchemaGeneratorConfig{
+			VersionBundle: bundle,
+			TypeRegistry:  epoch.NewEndpointRegistry(),
+		})
+
+		readUsers := openapi3.SecurityRequirements{{"oauth": {"users:read"}}}
+		writeUsers := openapi3.SecurityRequirements{{"oauth": {"users:write"}}}
+		baseSpec = &openapi3.T{
+			OpenAPI:    "3.0.3",
+			Info:       &openapi3.Info{Title: "API", Version: "head"},
+			Paths:      openapi3.NewPaths(),
+			Components: &openapi3.Components{SecuritySchemes: oauth},
+			Security:   openapi3.SecurityRequirements{{"oauth": {}}},
+		}
+		baseSpec.Paths.Set("/users/{id}", &openapi3.PathItem{
+			Get: &openapi3.Operation{Security: &readUsers, Responses: openapi3.NewResponses()},
+		})
+		baseSpec.Paths.Set("/users", &openapi3.PathItem{
+			Post: &openapi3.Operation{Security: &writeUsers, Responses: openapi3.NewResponses()},
+		})
+	})
+
+	It("should replace schemes and requirements in the declared version", func() {
+		generator.WithVersionSecurity(v1, VersionSecurity{
+			SecuritySchemes: apiKey,
+			Security:        openapi3.SecurityRequirements{{"apiKey": {}}},
+			Operations: map[string]openapi3.SecurityRequirements{
+				"POST /users": {{"apiKey": {}}, {}},
+			},
+		})
+		specs, err := generator.GenerateVersionedSpecs(baseSpec)
+		Expect(err).NotTo(HaveOccurred())
+
+		spec := specs["2024-01-01"]
+		Expect(spec.Components.SecuritySchemes).To(Equal(apiKey))
+		Expect(spec.Security).To(Equal(openapi3.SecurityRequirements{{"apiKey": {}}}))
+		Expect(operation(spec, "GET", "/users/{id}").Security).To(BeNil())
+		Expect(*operation(spec, "POST", "/users").Security).To(Equal(openapi3.SecurityRequirements{{"apiKey": {}}, {}}))
+	})
+
+	It("should apply to older versions but not newer ones", func() {
+		generator.WithVersionSecurity(v1, VersionSecurity{SecuritySchemes: apiKey})
+		specs, err := generator.GenerateVersionedSpecs(baseSpec)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(specs["2023-01-01"].Components.SecuritySchemes).To(HaveKey("apiKey"))
+		Expect(specs["2025-01-01"].Components.SecuritySchemes).To(HaveKey("oauth"))
+		Expect(specs["head"].Components.SecuritySchemes).To(HaveKey("oauth"))
+		Expect(*operation(specs["2025-01-01"], "GET", "/users/{id}").Security).To(Equal(openapi3.SecurityRequirements{{"oauth": {"users:read"}}}))
+	})
+
+	It("should use the closest declared version", func() {
+		generator.
+			WithVersionSecurity(v1, VersionSecurity{SecuritySchemes: apiKey}).
+			WithVersionSecurity(v0, VersionSecurity{Security: openapi3.SecurityRequirements{}})
+		specs, err := generator.GenerateVersionedSpecs(baseSpec)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(specs["2023-01-01"].Components.SecuritySchemes).To(HaveKey("oauth"))
+		Expect(specs["2023-01-01"].Security).To(BeEmpty())
+		Expect(specs["2024-01-01"].Components.SecuritySchemes).To(HaveKey("apiKey"))
+	})
+
+	It("should accept gin paths and leave the base spec unchanged", func() {
+		generator.WithVersionSecurity(v2, VersionSecurity{
+			Operations: map[string]openapi3.SecurityRequirements{
+				"get /users/:id": {{"oauth": {"users"}}},
+			},
+		})
+		specs, err := generator.GenerateVersionedSpecs(baseSpec)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(*operation(specs["2025-01-01"], "GET", "/users/{id}").Security).To(Equal(openapi3.SecurityRequirements{{"oauth": {"users"}}}))
+		Expect(*operation(baseSpec, "GET", "/users/{id}").Security).To(Equal(openapi3.SecurityRequirements{{"oauth": {"users:read"}}}))
+	})
+
+	It("should reject malformed operation keys", func() {
+		generator.WithVersionSecurity(v1, VersionSecurity{
+			Operations: map[string]openapi3.SecurityRequirements{"/users": {}},
+		})
+		_, err := generator.GenerateVersionedSpecs(baseSpec)
+		Expect(err).To(MatchError(ContainSubstring(`invalid operation "/users" in security of version 2024-01-01`)))
+
+		// Also when generating a single older version the declaration applies to
+		_, err = generator.GenerateSpecForVersion(baseSpec, v0)
+		Expect(err).To(MatchError(ContainSubstring("in security of version 2024-01-01")))
+	})
+
+	It("should reject requirements naming unknown schemes", func() {
+		generator.WithVersionSecurity(v1, VersionSecurity{
+			Operations: map[string]openapi3.SecurityRequirements{"GET /users/:id": {{"apiKey": {}}}},
+		})
+		_, err := generator.GenerateVersionedSpecs(baseSpec)
+		Expect(err).To(MatchError(`unknown security scheme "apiKey" in security of version 2024-01-01`))
+
+		generator = NewSchemaGenerator(SchemaGeneratorConfig{VersionBundle: generator.config.VersionBundle, TypeRegistry: epoch.NewEndpointRegistry()}).
+			WithVersionSecurity(v2, VersionSecurity{SecuritySchemes: apiKey, Security: openapi3.SecurityRequirements{{"oauth": {}}}})
+		_, err = generator.GenerateVersionedSpecs(baseSpec)
+		Expect(err).To(MatchError(`unknown security scheme "oauth" in security of version 2025-01-01`))
+	})
+})
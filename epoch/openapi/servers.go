@@ -0,0 +1,32 @@
+package openapi
+
+import (
+	"github.com/astronomer/epoch/epoch"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// versionServers are servers declared with WithVersionServers
+type versionServers struct {
+	version *epoch.Version
+	servers openapi3.Servers
+}
+
+// WithVersionServers sets the servers of version's spec, e.g. https://api.example.com/v1 for a
+// version served under a path prefix, so code generators get the right base URL. Like
+// WithVersionSecurity, they also apply to older versions up to the next older version with
+// servers of its own; newer versions keep the base spec's servers.
+func (sg *SchemaGenerator) WithVersionServers(version *epoch.Version, servers ...*openapi3.Server) *SchemaGenerator {
+	sg.versionServers = append(sg.versionServers, versionServers{version: version, servers: servers})
+	return sg
+}
+
+// applyServersForVersion replaces the servers of version's spec with those declared for it
+func (sg *SchemaGenerator) applyServersForVersion(spec *openapi3.T, version *epoch.Version) {
+	declared := make([]*epoch.Version, len(sg.versionServers))
+	for i, servers := range sg.versionServers {
+		declared[i] = servers.version
+	}
+	if i := closestDeclaredVersion(version, declared); i >= 0 {
+		spec.Servers = sg.versionServers[i].servers
+	}
+}
@@ -0,0 +1,66 @@
+package openapi
+
+import (
+	"github.com/astronomer/epoch/epoch"
+	"github.com/getkin/kin-openapi/openapi3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Version Servers", func() {
+	var (
+		v0, v1, v2 *epoch.Version
+		generator  *SchemaGenerator
+		baseSpec   *openapi3.T
+	)
+
+	BeforeEach(func() {
+		v0, _ = epoch.NewDateVersion("2023-01-01")
+		v1, _ = epoch.NewDateVersion("2024-01-01")
+		v2, _ = epoch.NewDateVersion("2025-01-01")
+		bundle, _ := epoch.NewVersionBundle([]*epoch.Version{v0, v1, v2})
+		generator = NewSchemaGenerator(SchemaGeneratorConfig{
+			VersionBundle: bundle,
+			TypeRegistry:  epoch.NewEndpointRegistry(),
+		})
+		baseSpec = &openapi3.T{
+			OpenAPI:    "3.0.3",
+			Info:       &openapi3.Info{Title: "API", Version: "head"},
+			Paths:      openapi3.NewPaths(),
+			Components: &openapi3.Components{},
+			Servers:    openapi3.Servers{{URL: "https://api.example.com"}},
+		}
+	})
+
+	It("should set the servers of declared and older versions", func() {
+		generator.WithVersionServers(v1, &openapi3.Server{URL: "https://api.example.com/v1", Description: "Path versioned"})
+		specs, err := generator.GenerateVersionedSpecs(baseSpec)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(specs["2023-01-01"].Servers).To(HaveLen(1))
+		Expect(specs["2023-01-01"].Servers[0].URL).To(Equal("https://api.example.com/v1"))
+		Expect(specs["2024-01-01"].Servers[0].URL).To(Equal("https://api.example.com/v1"))
+		Expect(specs["2025-01-01"].Servers[0].URL).To(Equal("https://api.example.com"))
+		Expect(specs["head"].Servers[0].URL).To(Equal("https://api.example.com"))
+		Expect(baseSpec.Servers[0].URL).To(Equal("https://api.example.com"))
+	})
+
+	It("should use the closest declared version", func() {
+		generator.
+			WithVersionServers(v1, &openapi3.Server{URL: "https://api.example.com/v2"}).
+			WithVersionServers(v0, &openapi3.Server{URL: "https://api.example.com/v1"}, &openapi3.Server{URL: "https://legacy.example.com"})
+		specs, err := generator.GenerateVersionedSpecs(baseSpec)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(specs["2023-01-01"].Servers).To(HaveLen(2))
+		Expect(specs["2023-01-01"].Servers[1].URL).To(Equal("https://legacy.example.com"))
+		Expect(specs["2024-01-01"].Servers[0].URL).To(Equal("https://api.example.com/v2"))
+	})
+
+	It("should write valid specs with the declared servers", func() {
+		generator.WithVersionServers(v2, &openapi3.Server{URL: "https://api.example.com/v3"})
+		specs, err := generator.GenerateVersionedSpecs(baseSpec)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(generator.writer.ValidateVersionedSpecs(specs)).To(Succeed())
+	})
+})
@@ -0,0 +1,164 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/astronomer/epoch/epoch"
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v3"
+)
+
+// SpecDiff is the difference between the component schemas of two OpenAPI specs
+type SpecDiff struct {
+	Schemas        []*epoch.TypeDiff // Schemas both specs have whose properties differ, by name
+	AddedSchemas   []string          // Schemas only the newer spec has; new types need no migration
+	RemovedSchemas []string          // Schemas only the older spec has
+}
+
+// DiffSpecs compares the component schemas of two specs, e.g. the last published spec and the
+// current swag output, to bootstrap the changes between them. Properties are compared as
+// epoch.DiffFields does, in name order since schemas don't keep the declaration order.
+func DiffSpecs(older, newer *openapi3.T) *SpecDiff {
+	olderSchemas, newerSchemas := componentSchemas(older), componentSchemas(newer)
+	diff := &SpecDiff{}
+	for _, name := range sortedSchemaNames(newerSchemas) {
+		olderSchema, ok := olderSchemas[name]
+		if !ok {
+			diff.AddedSchemas = append(diff.AddedSchemas, name)
+			continue
+		}
+		schemaDiff := epoch.DiffFields(name, propertyShapes(olderSchema), propertyShapes(newerSchemas[name]))
+		if !schemaDiff.IsEmpty() {
+			diff.Schemas = append(diff.Schemas, schemaDiff)
+		}
+	}
+	for _, name := range sortedSchemaNames(olderSchemas) {
+		if _, ok := newerSchemas[name]; !ok {
+			diff.RemovedSchemas = append(diff.RemovedSchemas, name)
+		}
+	}
+	return diff
+}
+
+// IsEmpty reports whether no schema both specs have differs
+func (d *SpecDiff) IsEmpty() bool {
+	return len(d.Schemas) == 0
+}
+
+// Code renders a VersionChangeBuilder skeleton for the differing schemas, with from and to as
+// the Go expressions of its versions (see epoch.ChangeCode). Schema names are used as Go type
+// names, so rename them to the types they document while reviewing.
+func (d *SpecDiff) Code(from, to string) string {
+	if d.IsEmpty() {
+		return ""
+	}
+	return epoch.ChangeCode(from, to, d.Schemas...)
+}
+
+// componentSchemas returns a spec's component schemas, or nil when it has none
+func componentSchemas(spec *openapi3.T) openapi3.Schemas {
+	if spec == nil || spec.Components == nil {
+		return nil
+	}
+	return spec.Components.Schemas
+}
+
+// sortedSchemaNames returns the names of schemas in order
+func sortedSchemaNames(schemas openapi3.Schemas) []string {
+	names := make([]string, 0, len(schemas))
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// propertyShapes describes a schema's properties for epoch.DiffFields, in name order
+func propertyShapes(schemaRef *openapi3.SchemaRef) []epoch.FieldShape {
+	if schemaRef == nil || schemaRef.Value == nil {
+		return nil
+	}
+	properties := schemaRef.Value.Properties
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	shapes := make([]epoch.FieldShape, 0, len(names))
+	for _, name := range names {
+		shape := epoch.FieldShape{Name: name}
+		if property := properties[name]; property != nil && property.Value != nil {
+			shape.Kind = schemaKind(property.Value)
+			shape.Nullable = property.Value.Nullable || property.Value.Type.Includes("null")
+		}
+		shapes = append(shapes, shape)
+	}
+	return shapes
+}
+
+// schemaKind names the kind of JSON value a schema describes, or "" when it allows several
+func schemaKind(schema *openapi3.Schema) string {
+	var kinds []string
+	for _, typ := range schema.Type.Slice() {
+		switch typ {
+		case "null":
+			continue
+		case "integer":
+			typ = "number"
+		}
+		kinds = append(kinds, typ)
+	}
+	switch {
+	case len(kinds) == 1:
+		return kinds[0]
+	case len(kinds) == 0 && len(schema.Properties) > 0:
+		return "object"
+	}
+	return ""
+}
+
+// LoadSpecFile loads a spec to diff from a JSON or YAML file: an OpenAPI 3 spec, or a Swagger 2.0
+// spec such as swag's output, which is converted to OpenAPI 3
+func LoadSpecFile(path string) (*openapi3.T, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spec %s: %w", path, err)
+	}
+	// YAML is a superset of JSON, so this reads both
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse spec %s: %w", path, err)
+	}
+
+	loader := openapi3.NewLoader()
+	if _, ok := doc["swagger"]; !ok {
+		spec, err := loader.LoadFromFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load spec %s: %w", path, err)
+		}
+		return spec, nil
+	}
+
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert spec %s: %w", path, err)
+	}
+	var swagger openapi2.T
+	if err := json.Unmarshal(raw, &swagger); err != nil {
+		return nil, fmt.Errorf("failed to load Swagger spec %s: %w", path, err)
+	}
+	spec, err := openapi2conv.ToV3(&swagger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert Swagger spec %s: %w", path, err)
+	}
+	if err := loader.ResolveRefsIn(spec, nil); err != nil {
+		return nil, fmt.Errorf("failed to resolve references in %s: %w", path, err)
+	}
+	return spec, nil
+}
@@ -0,0 +1,125 @@
+package openapi
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/astronomer/epoch/epoch"
+	"github.com/getkin/kin-openapi/openapi3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const publishedSpec = `openapi: 3.0.0
+info:
+  title: API
+  version: "1"
+paths: {}
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        id:
+          type: integer
+        name:
+          type: string
+        nickname:
+          type: string
+    Legacy:
+      type: object
+      properties:
+        id:
+          type: integer
+    Org:
+      type: object
+      properties:
+        id:
+          type: string
+`
+
+// swagOutput is a Swagger 2.0 spec, as swag generates
+const swagOutput = `{
+  "swagger": "2.0",
+  "info": {"title": "API", "version": "2"},
+  "paths": {},
+  "definitions": {
+    "User": {
+      "type": "object",
+      "properties": {
+        "id": {"type": "integer"},
+        "full_name": {"type": "string"},
+        "verified": {"type": "boolean"}
+      }
+    },
+    "Org": {"type": "object", "properties": {"id": {"type": "string"}}},
+    "Team": {"type": "object", "properties": {"id": {"type": "string"}}}
+  }
+}`
+
+var _ = Describe("Spec diffs", func() {
+	load := func(name, content string) *openapi3.T {
+		path := filepath.Join(GinkgoT().TempDir(), name)
+		Expect(os.WriteFile(path, []byte(content), 0o644)).To(Succeed())
+		spec, err := LoadSpecFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		return spec
+	}
+
+	It("should suggest changes for the schemas both specs have", func() {
+		diff := DiffSpecs(load("published.yaml", publishedSpec), load("swagger.json", swagOutput))
+
+		Expect(diff.AddedSchemas).To(Equal([]string{"Team"}))
+		Expect(diff.RemovedSchemas).To(Equal([]string{"Legacy"}))
+		Expect(diff.Schemas).To(HaveLen(1))
+
+		user := diff.Schemas[0]
+		Expect(user.TypeName).To(Equal("User"))
+		Expect(user.Renamed).To(Equal([]epoch.FieldRename{
+			{Older: "name", Newer: "full_name", Guess: epoch.RenameGuessSimilarity},
+		}))
+		Expect(user.Added).To(Equal([]epoch.DiffField{{Name: "verified", Default: false}}))
+		Expect(user.Removed).To(Equal([]epoch.DiffField{{Name: "nickname", Default: ""}}))
+	})
+
+	It("should render builder code for the differing schemas", func() {
+		diff := DiffSpecs(load("published.yaml", publishedSpec), load("swagger.json", swagOutput))
+		Expect(diff.Code("v1", "v2")).To(Equal(`epoch.NewVersionChangeBuilder(v1, v2).
+	Description("Update User").
+	ForType(User{}).
+		RequestToNextVersion().
+			RenameField("name", "full_name"). // guessed from similar names
+			AddField("verified", false).
+			RemoveField("nickname").
+		ResponseToPreviousVersion().
+			RenameField("full_name", "name"). // guessed from similar names
+			RemoveField("verified").
+			AddField("nickname", "").
+	Build()
+`))
+	})
+
+	It("should default nullable properties to nil and flag retyped ones", func() {
+		older := &openapi3.T{Components: &openapi3.Components{Schemas: openapi3.Schemas{
+			"Order": openapi3.NewSchemaRef("", openapi3.NewObjectSchema().
+				WithProperty("total", openapi3.NewStringSchema())),
+		}}}
+		newer := &openapi3.T{Components: &openapi3.Components{Schemas: openapi3.Schemas{
+			"Order": openapi3.NewSchemaRef("", openapi3.NewObjectSchema().
+				WithProperty("total", openapi3.NewFloat64Schema()).
+				WithProperty("note", openapi3.NewStringSchema().WithNullable())),
+		}}}
+
+		diff := DiffSpecs(older, newer)
+		Expect(diff.Schemas).To(HaveLen(1))
+		Expect(diff.Schemas[0].Retyped).To(Equal([]string{"total"}))
+		Expect(diff.Schemas[0].Added).To(Equal([]epoch.DiffField{{Name: "note", Default: nil}}))
+	})
+
+	It("should report identical specs as empty", func() {
+		spec := load("published.yaml", publishedSpec)
+		diff := DiffSpecs(spec, spec)
+		Expect(diff.IsEmpty()).To(BeTrue())
+		Expect(diff.Code("v1", "v2")).To(BeEmpty())
+	})
+})
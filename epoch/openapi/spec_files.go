@@ -0,0 +1,85 @@
+package openapi
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/astronomer/epoch/epoch"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// SpecFilesConfig configures GenerateSpecFiles
+type SpecFilesConfig struct {
+	// BaseSpec is the path of the HEAD spec to version, e.g. swag output (OpenAPI 3 or Swagger 2).
+	// Without one, every schema is generated from the registered Go types.
+	BaseSpec string
+
+	// OutputDir receives one spec per version; it is created if missing
+	OutputDir string
+
+	// Format is "yaml" (default) or "json"
+	Format string
+
+	// Filename names each version's file, with %s for the version; defaults to "%s.yaml" or "%s.json"
+	Filename string
+}
+
+// GenerateSpecFiles generates the spec of every version of epochInstance, head included, from
+// the endpoints its wrapped handlers registered, and writes them to config.OutputDir. It
+// returns the paths written, sorted. Specs are validated before writing, so any error means
+// the output directory may hold some versions' specs but not others.
+func GenerateSpecFiles(epochInstance *epoch.Epoch, config SpecFilesConfig) ([]string, error) {
+	if config.OutputDir == "" {
+		return nil, fmt.Errorf("spec files need an output directory")
+	}
+	if config.Format == "" {
+		config.Format = "yaml"
+	}
+	if config.Format != "yaml" && config.Format != "json" {
+		return nil, fmt.Errorf("unknown spec format %q: use yaml or json", config.Format)
+	}
+	if config.Filename == "" {
+		config.Filename = "%s." + config.Format
+	}
+
+	baseSpec := &openapi3.T{
+		OpenAPI:    "3.0.3",
+		Info:       &openapi3.Info{Title: "API", Version: "head"},
+		Paths:      openapi3.NewPaths(),
+		Components: &openapi3.Components{Schemas: openapi3.Schemas{}},
+	}
+	if config.BaseSpec != "" {
+		loaded, err := LoadSpecFile(config.BaseSpec)
+		if err != nil {
+			return nil, err
+		}
+		baseSpec = loaded
+	}
+
+	generator := NewSchemaGenerator(SchemaGeneratorConfig{
+		VersionBundle: epochInstance.VersionBundle(),
+		TypeRegistry:  epochInstance.EndpointRegistry(),
+		OutputFormat:  config.Format,
+	})
+	specs, err := generator.GenerateVersionedSpecs(baseSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(config.OutputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	pattern := filepath.Join(config.OutputDir, config.Filename)
+	if err := generator.WriteVersionedSpecs(specs, pattern); err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(specs))
+	for version := range specs {
+		paths = append(paths, fmt.Sprintf(pattern, version))
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
@@ -0,0 +1,92 @@
+package openapi
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/astronomer/epoch/epoch"
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
+)
+
+var _ = Describe("GenerateSpecFiles", func() {
+	var epochInstance *epoch.Epoch
+
+	BeforeEach(func() {
+		v1, _ := epoch.NewDateVersion("2024-01-01")
+		v2, _ := epoch.NewDateVersion("2025-01-01")
+		change := epoch.NewVersionChangeBuilder(v1, v2).
+			ForType(TestUserResponse{}).
+			ResponseToPreviousVersion().
+			RemoveField("email").
+			Build()
+		var err error
+		epochInstance, err = epoch.NewEpoch().WithVersions(v1, v2).WithHeadVersion().WithChanges(change).Build()
+		Expect(err).NotTo(HaveOccurred())
+		epochInstance.WrapHandler(func(c *gin.Context) {}).
+			Returns(TestUserResponse{}).
+			ToHandlerFunc("GET", "/users/:id")
+	})
+
+	properties := func(path string) map[string]interface{} {
+		data, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		var spec struct {
+			Components struct {
+				Schemas map[string]struct {
+					Properties map[string]interface{} `yaml:"properties"`
+				} `yaml:"schemas"`
+			} `yaml:"components"`
+		}
+		Expect(yaml.Unmarshal(data, &spec)).To(Succeed())
+		return spec.Components.Schemas["TestUserResponse"].Properties
+	}
+
+	It("should write every version's spec to the output directory", func() {
+		dir := filepath.Join(GinkgoT().TempDir(), "specs")
+		paths, err := GenerateSpecFiles(epochInstance, SpecFilesConfig{OutputDir: dir})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(paths).To(Equal([]string{
+			filepath.Join(dir, "2024-01-01.yaml"),
+			filepath.Join(dir, "2025-01-01.yaml"),
+			filepath.Join(dir, "head.yaml"),
+		}))
+
+		Expect(properties(paths[0])).NotTo(HaveKey("email"))
+		Expect(properties(paths[1])).To(HaveKey("email"))
+	})
+
+	It("should generate specs without a base spec, or from one without components", func() {
+		dir := GinkgoT().TempDir()
+		paths, err := GenerateSpecFiles(epochInstance, SpecFilesConfig{OutputDir: dir})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(properties(paths[2])).To(HaveKeyWithValue("email", Not(BeNil())))
+
+		base := filepath.Join(GinkgoT().TempDir(), "base.yaml")
+		Expect(os.WriteFile(base, []byte("openapi: 3.0.3\ninfo:\n  title: Users\n  version: head\npaths: {}\n"), 0644)).To(Succeed())
+		paths, err = GenerateSpecFiles(epochInstance, SpecFilesConfig{OutputDir: dir, BaseSpec: base})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(properties(paths[0])).To(HaveKey("id"))
+		Expect(properties(paths[0])).NotTo(HaveKey("email"))
+	})
+
+	It("should use the filename pattern and format", func() {
+		dir := GinkgoT().TempDir()
+		paths, err := GenerateSpecFiles(epochInstance, SpecFilesConfig{OutputDir: dir, Format: "json", Filename: "api_%s.json"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(paths).To(ContainElement(filepath.Join(dir, "api_head.json")))
+	})
+
+	It("should reject bad configuration and base specs", func() {
+		_, err := GenerateSpecFiles(epochInstance, SpecFilesConfig{})
+		Expect(err).To(MatchError(ContainSubstring("output directory")))
+
+		_, err = GenerateSpecFiles(epochInstance, SpecFilesConfig{OutputDir: GinkgoT().TempDir(), Format: "xml"})
+		Expect(err).To(MatchError(ContainSubstring("unknown spec format")))
+
+		_, err = GenerateSpecFiles(epochInstance, SpecFilesConfig{OutputDir: GinkgoT().TempDir(), BaseSpec: "missing.yaml"})
+		Expect(err).To(MatchError(ContainSubstring("failed to read spec")))
+	})
+})
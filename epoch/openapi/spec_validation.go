@@ -0,0 +1,301 @@
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// SpecValidationError reports the problems of every versioned spec that failed validation
+type SpecValidationError struct {
+	// Problems lists each failing version's problems, keyed by version
+	Problems map[string][]string
+}
+
+func (e *SpecValidationError) Error() string {
+	versions := make([]string, 0, len(e.Problems))
+	for version := range e.Problems {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "spec validation failed for %d version(s):", len(versions))
+	for _, version := range versions {
+		fmt.Fprintf(&b, "\n  %s:", version)
+		for _, problem := range e.Problems[version] {
+			fmt.Fprintf(&b, "\n    - %s", problem)
+		}
+	}
+	return b.String()
+}
+
+// ValidateVersionedSpecs checks every spec for dangling local $refs and empty component
+// schemas, then validates it with kin-openapi the way WriteSpec does. It returns a
+// *SpecValidationError listing the problems of each failing version, or nil.
+func (w *Writer) ValidateVersionedSpecs(specs map[string]*openapi3.T) error {
+	_, err := w.checkVersionedSpecs(specs)
+	return err
+}
+
+// checkVersionedSpecs validates the specs and returns each one marshalled, keyed by version
+func (w *Writer) checkVersionedSpecs(specs map[string]*openapi3.T) (map[string][]byte, error) {
+	report := &SpecValidationError{Problems: make(map[string][]string)}
+	marshalled := make(map[string][]byte, len(specs))
+	for version, spec := range specs {
+		data, problems := w.checkSpec(spec)
+		if len(problems) > 0 {
+			report.Problems[version] = problems
+			continue
+		}
+		marshalled[version] = data
+	}
+	if len(report.Problems) > 0 {
+		return nil, report
+	}
+	return marshalled, nil
+}
+
+// checkSpec returns the spec marshalled, or the problems keeping it from being written
+func (w *Writer) checkSpec(spec *openapi3.T) ([]byte, []string) {
+	if spec == nil {
+		return nil, []string{"spec is nil"}
+	}
+	checker := &specChecker{spec: spec, seen: make(map[*openapi3.Schema]bool)}
+	checker.check()
+	if len(checker.problems) > 0 {
+		// Loading would only fail on the first dangling $ref, with less context
+		return nil, checker.problems
+	}
+
+	data, err := w.marshalSpec(spec)
+	if err != nil {
+		return nil, []string{err.Error()}
+	}
+	if err := validateSpecData(data); err != nil {
+		return nil, []string{err.Error()}
+	}
+	return data, nil
+}
+
+// specChecker finds dangling local $refs and empty component schemas in a spec
+type specChecker struct {
+	spec     *openapi3.T
+	problems []string
+	seen     map[*openapi3.Schema]bool
+}
+
+func (sc *specChecker) problem(format string, args ...interface{}) {
+	sc.problems = append(sc.problems, fmt.Sprintf(format, args...))
+}
+
+func (sc *specChecker) check() {
+	if components := sc.spec.Components; components != nil {
+		for _, name := range sortedKeys(components.Schemas) {
+			schema := components.Schemas[name]
+			path := "components.schemas." + name
+			if schema != nil && schema.Ref == "" && isEmptySchema(schema.Value) {
+				sc.problem("%s: schema is empty", path)
+				continue
+			}
+			sc.schema(path, schema)
+		}
+		for _, name := range sortedKeys(components.Parameters) {
+			sc.parameter("components.parameters."+name, components.Parameters[name])
+		}
+		for _, name := range sortedKeys(components.RequestBodies) {
+			sc.requestBody("components.requestBodies."+name, components.RequestBodies[name])
+		}
+		for _, name := range sortedKeys(components.Responses) {
+			sc.response("components.responses."+name, components.Responses[name])
+		}
+		for _, name := range sortedKeys(components.Headers) {
+			sc.header("components.headers."+name, components.Headers[name])
+		}
+	}
+
+	if sc.spec.Paths == nil {
+		return
+	}
+	paths := sc.spec.Paths.Map()
+	for _, route := range sortedKeys(paths) {
+		item := paths[route]
+		if item == nil {
+			continue
+		}
+		for i, param := range item.Parameters {
+			sc.parameter(fmt.Sprintf("paths.%s.parameters[%d]", route, i), param)
+		}
+		operations := item.Operations()
+		for _, method := range sortedKeys(operations) {
+			op := operations[method]
+			path := fmt.Sprintf("paths.%s.%s", route, strings.ToLower(method))
+			for i, param := range op.Parameters {
+				sc.parameter(fmt.Sprintf("%s.parameters[%d]", path, i), param)
+			}
+			sc.requestBody(path+".requestBody", op.RequestBody)
+			if op.Responses != nil {
+				responses := op.Responses.Map()
+				for _, status := range sortedKeys(responses) {
+					sc.response(path+".responses."+status, responses[status])
+				}
+			}
+		}
+	}
+}
+
+// ref reports a local $ref naming a component the spec doesn't have
+func (sc *specChecker) ref(path, ref string) {
+	const prefix = "#/components/"
+	if !strings.HasPrefix(ref, prefix) {
+		return // External refs are resolved when the spec is loaded
+	}
+	kind, name, _ := strings.Cut(strings.TrimPrefix(ref, prefix), "/")
+	components := sc.spec.Components
+	if components == nil {
+		components = &openapi3.Components{}
+	}
+	var exists bool
+	switch kind {
+	case "schemas":
+		_, exists = components.Schemas[name]
+	case "parameters":
+		_, exists = components.Parameters[name]
+	case "requestBodies":
+		_, exists = components.RequestBodies[name]
+	case "responses":
+		_, exists = components.Responses[name]
+	case "headers":
+		_, exists = components.Headers[name]
+	default:
+		return
+	}
+	if !exists {
+		sc.problem("%s: $ref %s does not exist", path, ref)
+	}
+}
+
+func (sc *specChecker) schema(path string, ref *openapi3.SchemaRef) {
+	if ref == nil {
+		return
+	}
+	if ref.Ref != "" {
+		sc.ref(path, ref.Ref)
+		return
+	}
+	schema := ref.Value
+	if schema == nil {
+		sc.problem("%s: schema has neither a $ref nor a value", path)
+		return
+	}
+	if sc.seen[schema] {
+		return
+	}
+	sc.seen[schema] = true
+
+	for _, name := range sortedKeys(schema.Properties) {
+		sc.schema(path+".properties."+name, schema.Properties[name])
+	}
+	sc.schema(path+".items", schema.Items)
+	sc.schema(path+".additionalProperties", schema.AdditionalProperties.Schema)
+	sc.schema(path+".not", schema.Not)
+	for i, sub := range schema.AllOf {
+		sc.schema(fmt.Sprintf("%s.allOf[%d]", path, i), sub)
+	}
+	for i, sub := range schema.OneOf {
+		sc.schema(fmt.Sprintf("%s.oneOf[%d]", path, i), sub)
+	}
+	for i, sub := range schema.AnyOf {
+		sc.schema(fmt.Sprintf("%s.anyOf[%d]", path, i), sub)
+	}
+}
+
+func (sc *specChecker) content(path string, content openapi3.Content) {
+	for _, mediaType := range sortedKeys(content) {
+		if media := content[mediaType]; media != nil {
+			sc.schema(path+".content."+mediaType+".schema", media.Schema)
+		}
+	}
+}
+
+func (sc *specChecker) parameter(path string, ref *openapi3.ParameterRef) {
+	if ref == nil {
+		return
+	}
+	if ref.Ref != "" {
+		sc.ref(path, ref.Ref)
+		return
+	}
+	if ref.Value != nil {
+		sc.schema(path+".schema", ref.Value.Schema)
+		sc.content(path, ref.Value.Content)
+	}
+}
+
+func (sc *specChecker) requestBody(path string, ref *openapi3.RequestBodyRef) {
+	if ref == nil {
+		return
+	}
+	if ref.Ref != "" {
+		sc.ref(path, ref.Ref)
+		return
+	}
+	if ref.Value != nil {
+		sc.content(path, ref.Value.Content)
+	}
+}
+
+func (sc *specChecker) response(path string, ref *openapi3.ResponseRef) {
+	if ref == nil {
+		return
+	}
+	if ref.Ref != "" {
+		sc.ref(path, ref.Ref)
+		return
+	}
+	if ref.Value == nil {
+		return
+	}
+	sc.content(path, ref.Value.Content)
+	for _, name := range sortedKeys(ref.Value.Headers) {
+		sc.header(path+".headers."+name, ref.Value.Headers[name])
+	}
+}
+
+func (sc *specChecker) header(path string, ref *openapi3.HeaderRef) {
+	if ref == nil {
+		return
+	}
+	if ref.Ref != "" {
+		sc.ref(path, ref.Ref)
+		return
+	}
+	if ref.Value != nil {
+		sc.schema(path+".schema", ref.Value.Schema)
+		sc.content(path, ref.Value.Content)
+	}
+}
+
+// isEmptySchema reports whether a schema says nothing, like {}, which generation only produces
+// for types it couldn't describe
+func isEmptySchema(schema *openapi3.Schema) bool {
+	if schema == nil {
+		return false
+	}
+	empty := *schema
+	empty.Extensions, empty.Origin = nil, nil
+	return reflect.DeepEqual(empty, openapi3.Schema{})
+}
+
+// sortedKeys returns a map's keys in order, for iterating maps deterministically
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
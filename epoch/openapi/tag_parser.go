@@ -23,6 +23,7 @@ func NewTagParser() *TagParser {
 //
 //	json:"field_name" → "field_name", false
 //	json:"field_name,omitempty" → "field_name", true
+//	json:"field_name,omitzero" → "field_name", true
 //	json:"-" → "-", false (skip field)
 func (tp *TagParser) ParseJSONTag(tag string) (fieldName string, omitempty bool) {
 	if tag == "" {
@@ -34,7 +35,7 @@ func (tp *TagParser) ParseJSONTag(tag string) (fieldName string, omitempty bool)
 
 	// Check for omitempty
 	for i := 1; i < len(parts); i++ {
-		if parts[i] == "omitempty" {
+		if parts[i] == "omitempty" || parts[i] == "omitzero" {
 			omitempty = true
 			break
 		}
@@ -43,6 +44,17 @@ func (tp *TagParser) ParseJSONTag(tag string) (fieldName string, omitempty bool)
 	return fieldName, omitempty
 }
 
+// HasJSONTagOption reports whether a json struct tag carries the given option (e.g. "string")
+func (tp *TagParser) HasJSONTagOption(tag, option string) bool {
+	parts := strings.Split(tag, ",")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] == option {
+			return true
+		}
+	}
+	return false
+}
+
 // ApplyValidationTags applies validation constraints from binding and validate tags to a schema
 // Supports tags from both gin's binding validator and go-playground/validator
 func (tp *TagParser) ApplyValidationTags(schema *openapi3.Schema, bindingTag, validateTag string) {
@@ -0,0 +1,46 @@
+package openapi
+
+import (
+	"strings"
+
+	"github.com/astronomer/epoch/epoch"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// removeUnavailableEndpoints drops the operations of endpoints that don't exist yet in
+// version, because a type they accept or return is declared with epoch.SinceVersion.
+// Paths are shared with the base spec, so the path map and modified path items are copied.
+func (sg *SchemaGenerator) removeUnavailableEndpoints(spec *openapi3.T, version *epoch.Version) {
+	if spec.Paths == nil || spec.Paths.Len() == 0 || version.IsHead {
+		return
+	}
+
+	pathsCopied := false
+	for _, endpoint := range sg.sortedEndpoints() {
+		if endpoint.Since == nil || !version.IsOlderThan(endpoint.Since) {
+			continue
+		}
+		path := ginPathToOpenAPI(endpoint.PathPattern)
+		item := spec.Paths.Value(path)
+		if item == nil || item.GetOperation(strings.ToUpper(endpoint.Method)) == nil {
+			continue
+		}
+
+		if !pathsCopied {
+			paths := openapi3.NewPaths()
+			paths.Extensions = spec.Paths.Extensions
+			for p, pathItem := range spec.Paths.Map() {
+				paths.Set(p, pathItem)
+			}
+			spec.Paths = paths
+			pathsCopied = true
+		}
+		itemCopy := *item
+		itemCopy.SetOperation(strings.ToUpper(endpoint.Method), nil)
+		if len(itemCopy.Operations()) == 0 {
+			spec.Paths.Delete(path)
+		} else {
+			spec.Paths.Set(path, &itemCopy)
+		}
+	}
+}
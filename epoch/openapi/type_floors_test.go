@@ -0,0 +1,65 @@
+package openapi
+
+import (
+	"reflect"
+
+	"github.com/astronomer/epoch/epoch"
+	"github.com/getkin/kin-openapi/openapi3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Type version floors", func() {
+	var (
+		v1, v2    *epoch.Version
+		generator *SchemaGenerator
+		baseSpec  *openapi3.T
+	)
+
+	BeforeEach(func() {
+		v1, _ = epoch.NewDateVersion("2024-01-01")
+		v2, _ = epoch.NewDateVersion("2024-06-01")
+		versionBundle, err := epoch.NewVersionBundle([]*epoch.Version{v1, v2})
+		Expect(err).NotTo(HaveOccurred())
+
+		registry := epoch.NewEndpointRegistry()
+		registry.Register("GET", "/users/:id", &epoch.EndpointDefinition{
+			Method: "GET", PathPattern: "/users/:id", ResponseType: reflect.TypeOf(TestUserResponse{}),
+		})
+		registry.Register("GET", "/invoices/:id", &epoch.EndpointDefinition{
+			Method: "GET", PathPattern: "/invoices/:id", ResponseType: reflect.TypeOf(OrgSettings{}), Since: v2,
+		})
+		registry.Register("DELETE", "/users/:id", &epoch.EndpointDefinition{
+			Method: "DELETE", PathPattern: "/users/:id", ResponseType: reflect.TypeOf(OrgSettings{}), Since: v2,
+		})
+		generator = NewSchemaGenerator(SchemaGeneratorConfig{VersionBundle: versionBundle, TypeRegistry: registry})
+
+		baseSpec = &openapi3.T{
+			OpenAPI:    "3.0.3",
+			Info:       &openapi3.Info{Title: "Test", Version: "1.0"},
+			Paths:      openapi3.NewPaths(),
+			Components: &openapi3.Components{Schemas: openapi3.Schemas{}},
+		}
+		baseSpec.Paths.Set("/users/{id}", &openapi3.PathItem{Get: &openapi3.Operation{}, Delete: &openapi3.Operation{}})
+		baseSpec.Paths.Set("/invoices/{id}", &openapi3.PathItem{Get: &openapi3.Operation{}})
+	})
+
+	It("should leave endpoints out of versions older than their types", func() {
+		spec, err := generator.GenerateSpecForVersion(baseSpec, v1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(spec.Paths.Value("/invoices/{id}")).To(BeNil())
+		Expect(spec.Paths.Value("/users/{id}").Get).NotTo(BeNil())
+		Expect(spec.Paths.Value("/users/{id}").Delete).To(BeNil())
+
+		// The base spec is shared, so it keeps every endpoint
+		Expect(baseSpec.Paths.Value("/invoices/{id}")).NotTo(BeNil())
+		Expect(baseSpec.Paths.Value("/users/{id}").Delete).NotTo(BeNil())
+	})
+
+	It("should keep endpoints from their types' version on", func() {
+		spec, err := generator.GenerateSpecForVersion(baseSpec, v2)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(spec.Paths.Value("/invoices/{id}")).NotTo(BeNil())
+		Expect(spec.Paths.Value("/users/{id}").Delete).NotTo(BeNil())
+	})
+})
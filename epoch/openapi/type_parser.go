@@ -1,9 +1,11 @@
 package openapi
 
 import (
+	"encoding"
+	"encoding/json"
 	"fmt"
+	"math/big"
 	"reflect"
-	"strings"
 	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
@@ -21,6 +23,12 @@ type TypeParser struct {
 
 	// Track types currently being parsed to detect cycles
 	parsing map[reflect.Type]bool
+
+	// Schemas for types whose wire format reflection can't see (kept across Reset)
+	overrides map[reflect.Type]func() *openapi3.Schema
+
+	// Names components of named structs; nil uses the Go type name
+	componentNamer func(reflect.Type) string
 }
 
 // NewTypeParser creates a new type parser
@@ -30,6 +38,7 @@ func NewTypeParser() *TypeParser {
 		cache:      make(map[reflect.Type]*openapi3.SchemaRef),
 		components: make(map[string]*openapi3.SchemaRef),
 		parsing:    make(map[reflect.Type]bool),
+		overrides:  make(map[reflect.Type]func() *openapi3.Schema),
 	}
 }
 
@@ -41,6 +50,11 @@ func (tp *TypeParser) ParseType(t reflect.Type) (*openapi3.SchemaRef, error) {
 		t = t.Elem()
 	}
 
+	// Types that marshal to something other than their Go shape
+	if schema := tp.wireFormatSchema(t); schema != nil {
+		return openapi3.NewSchemaRef("", schema), nil
+	}
+
 	// Check cache first
 	if cached, ok := tp.cache[t]; ok {
 		return cached, nil
@@ -109,6 +123,73 @@ func (tp *TypeParser) ParseType(t reflect.Type) (*openapi3.SchemaRef, error) {
 	return schemaRef, nil
 }
 
+// SetSchemaOverride makes t (and *t) always produce the schema returned by schemaFn
+// schemaFn is called for every use so each field gets its own schema.
+func (tp *TypeParser) SetSchemaOverride(t reflect.Type, schemaFn func() *openapi3.Schema) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	tp.overrides[t] = schemaFn
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// wellKnownSchemas describes standard library types with custom JSON encodings
+var wellKnownSchemas = map[reflect.Type]func() *openapi3.Schema{
+	reflect.TypeOf(big.Int{}): func() *openapi3.Schema {
+		return &openapi3.Schema{Type: &openapi3.Types{"integer"}}
+	},
+	reflect.TypeOf(json.RawMessage{}): func() *openapi3.Schema {
+		return &openapi3.Schema{} // Any JSON value
+	},
+}
+
+// wellKnownSchemasByName describes common third-party types with custom JSON encodings, keyed by
+// reflect type string since their packages aren't dependencies
+var wellKnownSchemasByName = map[string]func() *openapi3.Schema{
+	// shopspring/decimal and similar marshal as quoted strings to keep precision
+	"decimal.Decimal": func() *openapi3.Schema {
+		return &openapi3.Schema{Type: &openapi3.Types{"string"}, Format: "decimal"}
+	},
+	"decimal.NullDecimal": func() *openapi3.Schema {
+		return &openapi3.Schema{Type: &openapi3.Types{"string"}, Format: "decimal", Nullable: true}
+	},
+	"uuid.UUID": func() *openapi3.Schema {
+		return &openapi3.Schema{Type: &openapi3.Types{"string"}, Format: "uuid"}
+	},
+}
+
+// wireFormatSchema returns the schema for types whose JSON encoding differs from their Go shape,
+// or nil when reflection describes the type correctly
+// Order: registered overrides, well-known types, then encoding.TextMarshaler (always a JSON string).
+// Other json.Marshaler types fall back to reflection; register an override to describe them.
+func (tp *TypeParser) wireFormatSchema(t reflect.Type) *openapi3.Schema {
+	if schemaFn, ok := tp.overrides[t]; ok {
+		return schemaFn()
+	}
+	if schemaFn, ok := wellKnownSchemas[t]; ok {
+		return schemaFn()
+	}
+	if schemaFn, ok := wellKnownSchemasByName[t.String()]; ok {
+		return schemaFn()
+	}
+	if t.Kind() == reflect.Interface {
+		return nil
+	}
+
+	ptr := reflect.PointerTo(t)
+	if t.Implements(jsonMarshalerType) || ptr.Implements(jsonMarshalerType) {
+		return nil
+	}
+	if t.Implements(textMarshalerType) || ptr.Implements(textMarshalerType) {
+		return &openapi3.Schema{Type: &openapi3.Types{"string"}}
+	}
+	return nil
+}
+
 // parseBool creates a schema for bool types
 func (tp *TypeParser) parseBool() *openapi3.SchemaRef {
 	return openapi3.NewSchemaRef("", &openapi3.Schema{
@@ -188,7 +269,7 @@ func (tp *TypeParser) parseStruct(t reflect.Type) (*openapi3.SchemaRef, error) {
 	// For named structs, create a component reference
 	if t.Name() != "" {
 		// Check if we've already created this component
-		componentName := t.Name()
+		componentName := tp.componentName(t)
 		if _, exists := tp.components[componentName]; exists {
 			return tp.createRef(t), nil
 		}
@@ -220,13 +301,14 @@ func (tp *TypeParser) parseStruct(t reflect.Type) (*openapi3.SchemaRef, error) {
 			}
 
 			fieldName, omitempty := tp.tagParser.ParseJSONTag(jsonTag)
-			if fieldName == "" {
-				// If no json tag, use field name in lowercase
-				fieldName = strings.ToLower(field.Name)
+			tagged := fieldName != ""
+			if !tagged {
+				// Like encoding/json, untagged fields use the Go field name
+				fieldName = field.Name
 			}
 
-			// Handle embedded/anonymous fields
-			if field.Anonymous {
+			// Handle embedded/anonymous fields (a json name makes them regular fields)
+			if field.Anonymous && !tagged {
 				// For embedded structs, promote their fields to this level
 				embeddedSchema, err := tp.ParseType(field.Type)
 				if err != nil {
@@ -237,9 +319,8 @@ func (tp *TypeParser) parseStruct(t reflect.Type) (*openapi3.SchemaRef, error) {
 				var actualSchema *openapi3.Schema
 				if embeddedSchema.Ref != "" {
 					// It's a reference, resolve it from components
-					embeddedTypeName := field.Type.Name()
-					if embeddedTypeName != "" {
-						if component, ok := tp.components[embeddedTypeName]; ok {
+					if field.Type.Name() != "" {
+						if component, ok := tp.components[tp.componentName(field.Type)]; ok {
 							actualSchema = component.Value
 						}
 					}
@@ -262,22 +343,12 @@ func (tp *TypeParser) parseStruct(t reflect.Type) (*openapi3.SchemaRef, error) {
 			}
 
 			// Parse field type
-			fieldSchema, err := tp.ParseType(field.Type)
+			fieldSchema, isRequired, err := tp.parseFieldSchema(field, jsonTag, omitempty)
 			if err != nil {
 				return nil, fmt.Errorf("failed to parse field %s.%s: %w", t.Name(), field.Name, err)
 			}
-
-			// Apply validation tags if this is a new inline schema
-			if fieldSchema.Value != nil {
-				bindingTag := field.Tag.Get("binding")
-				validateTag := field.Tag.Get("validate")
-				tp.tagParser.ApplyValidationTags(fieldSchema.Value, bindingTag, validateTag)
-				tp.tagParser.ApplyCommonTags(fieldSchema.Value, field)
-
-				// Check if required
-				if tp.tagParser.IsRequired(bindingTag, validateTag, omitempty) {
-					required = append(required, fieldName)
-				}
+			if isRequired {
+				required = append(required, fieldName)
 			}
 
 			schema.Properties[fieldName] = fieldSchema
@@ -312,23 +383,15 @@ func (tp *TypeParser) parseStruct(t reflect.Type) (*openapi3.SchemaRef, error) {
 
 		fieldName, omitempty := tp.tagParser.ParseJSONTag(jsonTag)
 		if fieldName == "" {
-			fieldName = strings.ToLower(field.Name)
+			fieldName = field.Name
 		}
 
-		fieldSchema, err := tp.ParseType(field.Type)
+		fieldSchema, isRequired, err := tp.parseFieldSchema(field, jsonTag, omitempty)
 		if err != nil {
 			return nil, err
 		}
-
-		if fieldSchema.Value != nil {
-			bindingTag := field.Tag.Get("binding")
-			validateTag := field.Tag.Get("validate")
-			tp.tagParser.ApplyValidationTags(fieldSchema.Value, bindingTag, validateTag)
-			tp.tagParser.ApplyCommonTags(fieldSchema.Value, field)
-
-			if tp.tagParser.IsRequired(bindingTag, validateTag, omitempty) {
-				required = append(required, fieldName)
-			}
+		if isRequired {
+			required = append(required, fieldName)
 		}
 
 		schema.Properties[fieldName] = fieldSchema
@@ -341,6 +404,47 @@ func (tp *TypeParser) parseStruct(t reflect.Type) (*openapi3.SchemaRef, error) {
 	return openapi3.NewSchemaRef("", schema), nil
 }
 
+// parseFieldSchema parses a struct field's type and applies its tags
+// Inline schemas are cloned per field so tags don't leak into other fields of the same type.
+func (tp *TypeParser) parseFieldSchema(field reflect.StructField, jsonTag string, omitempty bool) (*openapi3.SchemaRef, bool, error) {
+	fieldSchema, err := tp.ParseType(field.Type)
+	if err != nil {
+		return nil, false, err
+	}
+	if fieldSchema.Ref != "" || fieldSchema.Value == nil {
+		return fieldSchema, false, nil
+	}
+
+	fieldSchema = openapi3.NewSchemaRef("", CloneSchema(fieldSchema.Value))
+	if tp.tagParser.HasJSONTagOption(jsonTag, "string") {
+		applyStringTagOption(fieldSchema.Value, field.Type)
+	}
+
+	bindingTag := field.Tag.Get("binding")
+	validateTag := field.Tag.Get("validate")
+	tp.tagParser.ApplyValidationTags(fieldSchema.Value, bindingTag, validateTag)
+	tp.tagParser.ApplyCommonTags(fieldSchema.Value, field)
+
+	return fieldSchema, tp.tagParser.IsRequired(bindingTag, validateTag, omitempty), nil
+}
+
+// applyStringTagOption describes the quoted output of the ",string" json option
+// encoding/json only honors it for numbers and booleans (and pointers to them).
+func applyStringTagOption(schema *openapi3.Schema, t reflect.Type) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		// Keep the numeric format (e.g. int64) as a hint for clients parsing the string
+		schema.Type = &openapi3.Types{"string"}
+		schema.Min = nil
+	}
+}
+
 // parseSlice creates a schema for slice/array types
 func (tp *TypeParser) parseSlice(t reflect.Type) (*openapi3.SchemaRef, error) {
 	// Get element type
@@ -367,9 +471,9 @@ func (tp *TypeParser) parseSlice(t reflect.Type) (*openapi3.SchemaRef, error) {
 
 // parseMap creates a schema for map types
 func (tp *TypeParser) parseMap(t reflect.Type) (*openapi3.SchemaRef, error) {
-	// Only support map[string]T
-	if t.Key().Kind() != reflect.String {
-		return nil, fmt.Errorf("only map[string]T is supported, got map[%s]T", t.Key().Kind())
+	// encoding/json writes string, integer and TextMarshaler keys as JSON object keys
+	if !isJSONMapKey(t.Key()) {
+		return nil, fmt.Errorf("map keys must be strings, integers or implement encoding.TextMarshaler, got map[%s]T", t.Key().Kind())
 	}
 
 	// Get value type
@@ -387,6 +491,17 @@ func (tp *TypeParser) parseMap(t reflect.Type) (*openapi3.SchemaRef, error) {
 	return openapi3.NewSchemaRef("", schema), nil
 }
 
+// isJSONMapKey reports whether encoding/json can use values of t as object keys
+func isJSONMapKey(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return t.Implements(reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem())
+}
+
 // parseInterface creates a schema for interface{} types
 func (tp *TypeParser) parseInterface() *openapi3.SchemaRef {
 	// interface{} is represented as a free-form object
@@ -397,13 +512,26 @@ func (tp *TypeParser) parseInterface() *openapi3.SchemaRef {
 
 // createRef creates a $ref reference to a component schema
 func (tp *TypeParser) createRef(t reflect.Type) *openapi3.SchemaRef {
-	componentName := t.Name()
+	componentName := tp.componentName(t)
 	ref := fmt.Sprintf("#/components/schemas/%s", componentName)
 	return &openapi3.SchemaRef{
 		Ref: ref,
 	}
 }
 
+// SetComponentNamer sets how components of named structs are named (Go type name by default)
+func (tp *TypeParser) SetComponentNamer(namer func(reflect.Type) string) {
+	tp.componentNamer = namer
+}
+
+// componentName returns the component name of a named struct
+func (tp *TypeParser) componentName(t reflect.Type) string {
+	if tp.componentNamer != nil {
+		return tp.componentNamer(t)
+	}
+	return t.Name()
+}
+
 // GetComponents returns all component schemas collected during parsing
 func (tp *TypeParser) GetComponents() map[string]*openapi3.SchemaRef {
 	return tp.components
@@ -1,9 +1,13 @@
 package openapi
 
 import (
+	"encoding/json"
+	"fmt"
+	"net"
 	"reflect"
 	"time"
 
+	"github.com/getkin/kin-openapi/openapi3"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -100,7 +104,14 @@ var _ = Describe("TypeParser", func() {
 			},
 			Entry("map[string]string", map[string]string{}),
 			Entry("map[string]interface{}", map[string]interface{}{}),
+			Entry("map[int]string", map[int]string{}),
+			Entry("map[uint64]bool", map[uint64]bool{}),
 		)
+
+		It("should reject map keys encoding/json can't write", func() {
+			_, err := tp.ParseType(reflect.TypeOf(map[float64]string{}))
+			Expect(err).To(HaveOccurred())
+		})
 	})
 
 	Describe("Special Cases", func() {
@@ -210,4 +221,91 @@ var _ = Describe("TypeParser", func() {
 			})
 		})
 	})
+
+	Describe("Wire Format", func() {
+		parseComponent := func(value interface{}) *openapi3.Schema {
+			_, err := tp.ParseType(reflect.TypeOf(value))
+			Expect(err).NotTo(HaveOccurred())
+			component := tp.GetComponents()[reflect.TypeOf(value).Name()]
+			Expect(component).NotTo(BeNil())
+			return component.Value
+		}
+
+		It("should describe ,string numbers and booleans as strings", func() {
+			schema := parseComponent(WireFormatStruct{})
+
+			Expect(schema.Properties["id"].Value.Type.Is("string")).To(BeTrue())
+			Expect(schema.Properties["id"].Value.Format).To(Equal("int64"))
+			Expect(schema.Properties["enabled"].Value.Type.Is("string")).To(BeTrue())
+			Expect(schema.Properties["count"].Value.Type.Is("integer")).To(BeTrue())
+		})
+
+		It("should keep tags from leaking between fields of the same type", func() {
+			schema := parseComponent(WireFormatStruct{})
+
+			Expect(schema.Properties["short"].Value.MaxLength).NotTo(BeNil())
+			Expect(schema.Properties["long"].Value.MaxLength).To(BeNil())
+		})
+
+		It("should name untagged fields like encoding/json", func() {
+			schema := parseComponent(WireFormatStruct{})
+
+			Expect(schema.Properties).To(HaveKey("Untagged"))
+			Expect(schema.Properties).To(HaveKey("Named"))
+		})
+
+		It("should treat omitzero fields as optional", func() {
+			schema := parseComponent(WireFormatStruct{})
+
+			Expect(schema.Required).To(ContainElement("short"))
+			Expect(schema.Required).NotTo(ContainElement("optional"))
+		})
+
+		It("should describe encoding.TextMarshaler types as strings", func() {
+			schemaRef, err := tp.ParseType(reflect.TypeOf(net.IP{}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(schemaRef.Value.Type.Is("string")).To(BeTrue())
+		})
+
+		It("should leave json.RawMessage free-form", func() {
+			schemaRef, err := tp.ParseType(reflect.TypeOf(json.RawMessage{}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(schemaRef.Value.Type).To(BeNil())
+		})
+
+		It("should prefer registered overrides and keep them across Reset", func() {
+			tp.SetSchemaOverride(reflect.TypeOf(new(Cents)), func() *openapi3.Schema {
+				return &openapi3.Schema{Type: &openapi3.Types{"string"}, Pattern: `^\d+\.\d{2}$`}
+			})
+			tp.Reset()
+
+			schema := parseComponent(Invoice{})
+			Expect(schema.Properties["total"].Value.Type.Is("string")).To(BeTrue())
+			Expect(schema.Properties["total"].Value.Pattern).To(Equal(`^\d+\.\d{2}$`))
+			Expect(schema.Properties["total"].Value.Description).To(Equal("Invoice total"))
+		})
+	})
 })
+
+// WireFormatStruct exercises json tag options that change the wire format
+type WireFormatStruct struct {
+	ID       int64  `json:"id,string"`
+	Enabled  bool   `json:"enabled,string"`
+	Count    int    `json:"count"`
+	Short    string `json:"short" binding:"required,max=5"`
+	Long     string `json:"long"`
+	Optional string `json:"optional,omitzero" binding:"required"`
+	Untagged string
+	Named    string `json:",omitempty"`
+}
+
+// Cents marshals as a decimal string, which reflection can't see
+type Cents int64
+
+func (c Cents) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fmt.Sprintf("%d.%02d", c/100, c%100))
+}
+
+type Invoice struct {
+	Total Cents `json:"total" description:"Invoice total"`
+}
@@ -0,0 +1,225 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/astronomer/epoch/epoch"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+var (
+	tsIdentifierPattern    = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+	tsVersionSuffixPattern = regexp.MustCompile(`[^A-Za-z0-9]+`)
+)
+
+// TypeScriptInterfaceName returns the interface name emitted for a schema at version
+// e.g. UserResponse at 2024-01-01 → UserResponse_2024_01_01, at HEAD → UserResponse_Head
+func TypeScriptInterfaceName(schemaName string, version *epoch.Version) string {
+	if version.IsHead {
+		return schemaName + "_Head"
+	}
+	return schemaName + "_" + strings.Trim(tsVersionSuffixPattern.ReplaceAllString(version.String(), "_"), "_")
+}
+
+// GenerateTypeScript emits a .d.ts file with one interface per component schema as seen by
+// clients of version. Interface names carry the version suffix so files for several versions
+// can be imported side by side.
+func (sg *SchemaGenerator) GenerateTypeScript(version *epoch.Version) ([]byte, error) {
+	spec, err := sg.generateStandaloneSpec(version)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(spec.Components.Schemas))
+	for name := range spec.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("// Code generated by epoch. DO NOT EDIT.\n")
+	fmt.Fprintf(&b, "// API version: %s\n", version.String())
+
+	for _, name := range names {
+		schemaRef := spec.Components.Schemas[name]
+		if schemaRef == nil || schemaRef.Value == nil {
+			continue
+		}
+		b.WriteString("\n")
+		writeTSDoc(&b, "", schemaRef.Value.Description, nil)
+
+		interfaceName := TypeScriptInterfaceName(name, version)
+		if len(schemaRef.Value.Properties) == 0 || schemaRef.Value.AdditionalProperties.Schema != nil {
+			// Maps, free-form objects and non-object components become type aliases
+			fmt.Fprintf(&b, "export type %s = %s;\n", interfaceName, tsType(schemaRef, version, ""))
+			continue
+		}
+		fmt.Fprintf(&b, "export interface %s %s\n", interfaceName, tsObjectBody(schemaRef.Value, version, ""))
+	}
+	return []byte(b.String()), nil
+}
+
+// WriteTypeScript generates .d.ts files for HEAD and every version in the bundle
+// filenamePattern should contain %s for version, e.g., "web/types/api_%s.d.ts"
+func (sg *SchemaGenerator) WriteTypeScript(filenamePattern string) error {
+	versions := append([]*epoch.Version{sg.config.VersionBundle.GetHeadVersion()}, sg.config.VersionBundle.GetVersions()...)
+	for _, version := range versions {
+		data, err := sg.GenerateTypeScript(version)
+		if err != nil {
+			return fmt.Errorf("failed to generate TypeScript for version %s: %w", version.String(), err)
+		}
+		path := fmt.Sprintf(filenamePattern, version.String())
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return fmt.Errorf("failed to write TypeScript for version %s: %w", version.String(), err)
+		}
+	}
+	return nil
+}
+
+// tsType renders a schema as a TypeScript type expression
+func tsType(schemaRef *openapi3.SchemaRef, version *epoch.Version, indent string) string {
+	if schemaRef == nil {
+		return "unknown"
+	}
+	if strings.HasPrefix(schemaRef.Ref, componentRefPrefix) {
+		return TypeScriptInterfaceName(strings.TrimPrefix(schemaRef.Ref, componentRefPrefix), version)
+	}
+	schema := schemaRef.Value
+	if schema == nil {
+		return "unknown"
+	}
+
+	var result string
+	switch {
+	case len(schema.AllOf) > 0:
+		parts := make([]string, 0, len(schema.AllOf))
+		for _, part := range schema.AllOf {
+			parts = append(parts, tsType(part, version, indent))
+		}
+		result = strings.Join(parts, " & ")
+	case len(schema.OneOf) > 0 || len(schema.AnyOf) > 0:
+		parts := make([]string, 0, len(schema.OneOf)+len(schema.AnyOf))
+		for _, part := range append(append(openapi3.SchemaRefs{}, schema.OneOf...), schema.AnyOf...) {
+			parts = append(parts, tsType(part, version, indent))
+		}
+		result = strings.Join(parts, " | ")
+	case len(schema.Enum) > 0:
+		parts := make([]string, 0, len(schema.Enum))
+		for _, value := range schema.Enum {
+			literal, err := json.Marshal(value)
+			if err != nil {
+				return "unknown"
+			}
+			parts = append(parts, string(literal))
+		}
+		result = strings.Join(parts, " | ")
+	case schema.Type.Is(openapi3.TypeString):
+		result = "string"
+	case schema.Type.Is(openapi3.TypeInteger), schema.Type.Is(openapi3.TypeNumber):
+		result = "number"
+	case schema.Type.Is(openapi3.TypeBoolean):
+		result = "boolean"
+	case schema.Type.Is(openapi3.TypeArray):
+		result = tsType(schema.Items, version, indent)
+		if strings.ContainsAny(result, "|&") {
+			result = "(" + result + ")"
+		}
+		result += "[]"
+	case schema.Type.Is(openapi3.TypeObject):
+		result = tsObjectType(schema, version, indent)
+	default:
+		result = "unknown"
+	}
+
+	if schema.Nullable {
+		result += " | null"
+	}
+	return result
+}
+
+// tsObjectType renders an object schema as an inline type, a Record, or both
+func tsObjectType(schema *openapi3.Schema, version *epoch.Version, indent string) string {
+	var record string
+	if schema.AdditionalProperties.Schema != nil {
+		record = "Record<string, " + tsType(schema.AdditionalProperties.Schema, version, indent) + ">"
+	}
+	if len(schema.Properties) == 0 {
+		if record != "" {
+			return record
+		}
+		return "Record<string, unknown>"
+	}
+	body := tsObjectBody(schema, version, indent)
+	if record != "" {
+		return body + " & " + record
+	}
+	return body
+}
+
+// tsObjectBody renders the { ... } member list of an object schema
+// Properties missing from required are optional.
+func tsObjectBody(schema *openapi3.Schema, version *epoch.Version, indent string) string {
+	required := make(map[string]bool, len(schema.Required))
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	memberIndent := indent + "  "
+	var b strings.Builder
+	b.WriteString("{\n")
+	for _, name := range names {
+		propRef := schema.Properties[name]
+		if propRef != nil && propRef.Value != nil && propRef.Ref == "" {
+			writeTSDoc(&b, memberIndent, propRef.Value.Description, propRef.Value.Extensions)
+		}
+
+		key := name
+		if !tsIdentifierPattern.MatchString(name) {
+			key = fmt.Sprintf("%q", name)
+		}
+		if !required[name] {
+			key += "?"
+		}
+		fmt.Fprintf(&b, "%s%s: %s;\n", memberIndent, key, tsType(propRef, version, memberIndent))
+	}
+	b.WriteString(indent + "}")
+	return b.String()
+}
+
+// writeTSDoc writes a JSDoc comment from a description and x-epoch-* extensions, if any
+func writeTSDoc(b *strings.Builder, indent, description string, extensions map[string]any) {
+	var lines []string
+	if description != "" {
+		lines = append(lines, strings.Split(description, "\n")...)
+	}
+	if addedIn, ok := extensions[ExtensionAddedIn].(string); ok {
+		lines = append(lines, "@since "+addedIn)
+	}
+	if removedIn, ok := extensions[ExtensionRemovedIn].(string); ok {
+		lines = append(lines, "@deprecated Removed in "+removedIn)
+	}
+	if len(lines) == 0 {
+		return
+	}
+
+	if len(lines) == 1 {
+		fmt.Fprintf(b, "%s/** %s */\n", indent, strings.ReplaceAll(lines[0], "*/", "*\\/"))
+		return
+	}
+	fmt.Fprintf(b, "%s/**\n", indent)
+	for _, line := range lines {
+		fmt.Fprintf(b, "%s * %s\n", indent, strings.ReplaceAll(line, "*/", "*\\/"))
+	}
+	fmt.Fprintf(b, "%s */\n", indent)
+}
@@ -0,0 +1,116 @@
+package openapi
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/astronomer/epoch/epoch"
+	"github.com/getkin/kin-openapi/openapi3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TypeScript generation", func() {
+	var (
+		v1, v2    *epoch.Version
+		generator *SchemaGenerator
+	)
+
+	BeforeEach(func() {
+		v1, _ = epoch.NewDateVersion("2024-01-01")
+		v2, _ = epoch.NewDateVersion("2024-06-01")
+		versionBundle, err := epoch.NewVersionBundle([]*epoch.Version{v1, v2})
+		Expect(err).NotTo(HaveOccurred())
+
+		v1.Changes = []epoch.VersionChangeInterface{
+			epoch.NewVersionChangeBuilder(v1, v2).
+				ForType(OrgMember{}).
+				ResponseToPreviousVersion().
+				RemoveField("email").
+				Build(),
+		}
+
+		registry := epoch.NewEndpointRegistry()
+		registry.Register("GET", "/orgs/:id", &epoch.EndpointDefinition{
+			Method:       "GET",
+			PathPattern:  "/orgs/:id",
+			ResponseType: reflect.TypeOf(OrganizationResponse{}),
+		})
+		registry.Register("POST", "/users", &epoch.EndpointDefinition{
+			Method:       "POST",
+			PathPattern:  "/users",
+			RequestType:  reflect.TypeOf(TestUserRequest{}),
+			ResponseType: reflect.TypeOf(TestUserResponse{}),
+		})
+
+		generator = NewSchemaGenerator(SchemaGeneratorConfig{
+			VersionBundle: versionBundle,
+			TypeRegistry:  registry,
+		})
+	})
+
+	It("should suffix interface names with the version", func() {
+		Expect(TypeScriptInterfaceName("UserResponse", v1)).To(Equal("UserResponse_2024_01_01"))
+		Expect(TypeScriptInterfaceName("UserResponse", epoch.NewHeadVersion())).To(Equal("UserResponse_Head"))
+
+		semver, _ := epoch.NewSemverVersion("1.2.0")
+		Expect(TypeScriptInterfaceName("UserResponse", semver)).To(Equal("UserResponse_1_2_0"))
+	})
+
+	It("should emit interfaces with versioned references", func() {
+		data, err := generator.GenerateTypeScript(v2)
+		Expect(err).NotTo(HaveOccurred())
+		output := string(data)
+
+		Expect(output).To(HavePrefix("// Code generated by epoch. DO NOT EDIT.\n// API version: 2024-06-01\n"))
+		Expect(output).To(ContainSubstring("export interface OrganizationResponse_2024_06_01 {"))
+		Expect(output).To(ContainSubstring("members?: Record<string, OrgMember_2024_06_01>;"))
+		Expect(output).To(ContainSubstring("teams?: Record<string, OrgMember_2024_06_01[]>;"))
+		Expect(output).To(ContainSubstring("metadata?: Record<string, string>;"))
+		Expect(output).To(ContainSubstring("settings?: OrgSettings_2024_06_01;"))
+		Expect(output).To(ContainSubstring("export interface OrgMember_2024_06_01 {\n  email?: string;\n  name?: string;\n}"))
+	})
+
+	It("should reflect each version's migrations", func() {
+		data, err := generator.GenerateTypeScript(v1)
+		Expect(err).NotTo(HaveOccurred())
+		output := string(data)
+
+		Expect(output).To(ContainSubstring("export interface OrgMember_2024_01_01 {\n  name?: string;\n}"))
+		Expect(output).NotTo(ContainSubstring("_2024_06_01"))
+	})
+
+	It("should mark required fields as non-optional", func() {
+		data, err := generator.GenerateTypeScript(v2)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(string(data)).To(ContainSubstring("export interface TestUserRequest_2024_06_01 {\n  email: string;\n  name: string;\n}"))
+	})
+
+	It("should render nullable, enum and non-identifier properties", func() {
+		output := tsObjectBody(&openapi3.Schema{
+			Type: &openapi3.Types{"object"},
+			Properties: openapi3.Schemas{
+				"nickname": openapi3.NewSchemaRef("", &openapi3.Schema{Type: &openapi3.Types{"string"}, Nullable: true}),
+				"status":   openapi3.NewSchemaRef("", &openapi3.Schema{Type: &openapi3.Types{"string"}, Enum: []interface{}{"active", "banned"}}),
+				"x-trace":  openapi3.NewSchemaRef("", &openapi3.Schema{Type: &openapi3.Types{"integer"}}),
+			},
+			Required: []string{"status"},
+		}, v1, "")
+
+		Expect(output).To(ContainSubstring("nickname?: string | null;"))
+		Expect(output).To(ContainSubstring(`status: "active" | "banned";`))
+		Expect(output).To(ContainSubstring(`"x-trace"?: number;`))
+	})
+
+	It("should write one file per version", func() {
+		dir := GinkgoT().TempDir()
+		Expect(generator.WriteTypeScript(filepath.Join(dir, "api_%s.d.ts"))).To(Succeed())
+
+		for _, name := range []string{"api_head.d.ts", "api_2024-01-01.d.ts", "api_2024-06-01.d.ts"} {
+			_, err := os.Stat(filepath.Join(dir, name))
+			Expect(err).NotTo(HaveOccurred())
+		}
+	})
+})
@@ -0,0 +1,283 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/astronomer/epoch/epoch"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Vendor extensions emitted when SchemaGeneratorConfig.VersionExtensions is enabled
+const (
+	// ExtensionAddedIn marks the version a property first appeared in
+	ExtensionAddedIn = "x-epoch-added-in"
+	// ExtensionRenamedFrom holds the property's name before its most recent rename
+	ExtensionRenamedFrom = "x-epoch-renamed-from"
+	// ExtensionRemovedIn marks the first newer version that no longer has the property
+	ExtensionRemovedIn = "x-epoch-removed-in"
+	// ExtensionChangedIn lists the versions that changed an operation's request or response types
+	ExtensionChangedIn = "x-epoch-changed-in"
+)
+
+// fieldHistory tracks how a property reached the version being annotated
+type fieldHistory struct {
+	addedIn     string
+	renamedFrom string
+}
+
+// forwardDocs returns the schema effects of a change on a type in the older → newer direction
+func forwardDocs(vc *epoch.VersionChange, targetType reflect.Type, direction SchemaDirection) []epoch.OperationDoc {
+	var docs []epoch.OperationDoc
+	if direction == SchemaDirectionRequest {
+		// Request operations already run older → newer
+		ops, _ := vc.GetRequestOperationsByType(targetType)
+		for _, op := range ops {
+			docs = append(docs, epoch.DescribeOperation(op))
+		}
+		return docs
+	}
+
+	// Response operations run newer → older, so invert them in reverse order
+	ops, _ := vc.GetResponseOperationsByType(targetType)
+	for i := len(ops) - 1; i >= 0; i-- {
+		docs = append(docs, epoch.DescribeOperation(ops[i]).Inverse())
+	}
+	return docs
+}
+
+// allVersionChanges returns every change in the bundle, ordered by from-version
+func (vt *VersionTransformer) allVersionChanges() []*epoch.VersionChange {
+	var changes []*epoch.VersionChange
+	for _, v := range vt.versionBundle.GetVersions() {
+		for _, vc := range vt.versionBundle.VersionChanges(v) {
+			if epochVC, ok := vc.(*epoch.VersionChange); ok {
+				changes = append(changes, epochVC)
+			}
+		}
+	}
+	return changes
+}
+
+// AnnotateSchemaForVersion adds x-epoch-added-in, x-epoch-renamed-from and x-epoch-removed-in
+// extensions to the properties of a schema generated for targetVersion
+func (vt *VersionTransformer) AnnotateSchemaForVersion(
+	schema *openapi3.Schema,
+	targetType reflect.Type,
+	targetVersion *epoch.Version,
+	direction SchemaDirection,
+) {
+	if schema == nil || len(schema.Properties) == 0 || targetType == nil {
+		return
+	}
+	if targetType.Kind() == reflect.Ptr {
+		targetType = targetType.Elem()
+	}
+
+	history := make(map[string]*fieldHistory)
+	removedIn := make(map[string]string)
+	current := make(map[string]string) // current name → name at targetVersion
+	for name := range schema.Properties {
+		current[name] = name
+	}
+
+	for _, vc := range vt.allVersionChanges() {
+		toVersion := vc.ToVersion()
+		older := !toVersion.IsNewerThan(targetVersion)
+
+		for _, doc := range forwardDocs(vc, targetType, direction) {
+			if older {
+				// Replay how fields reached targetVersion
+				for _, name := range doc.RemovedFields {
+					delete(history, name)
+				}
+				for from, to := range doc.RenamedFields {
+					h := history[from]
+					if h == nil {
+						h = &fieldHistory{}
+					}
+					h.renamedFrom = from
+					delete(history, from)
+					history[to] = h
+				}
+				for name := range doc.AddedFields {
+					history[name] = &fieldHistory{addedIn: toVersion.String()}
+				}
+				continue
+			}
+
+			// Follow targetVersion's fields forward until they disappear
+			for _, name := range doc.RemovedFields {
+				if original, ok := current[name]; ok {
+					removedIn[original] = toVersion.String()
+					delete(current, name)
+				}
+			}
+			renamed := make(map[string]string)
+			for from, to := range doc.RenamedFields {
+				if original, ok := current[from]; ok {
+					delete(current, from)
+					renamed[to] = original
+				}
+			}
+			for to, original := range renamed {
+				current[to] = original
+			}
+		}
+	}
+
+	for name, propRef := range schema.Properties {
+		extensions := make(map[string]any)
+		if h := history[name]; h != nil {
+			if h.addedIn != "" {
+				extensions[ExtensionAddedIn] = h.addedIn
+			}
+			if h.renamedFrom != "" {
+				extensions[ExtensionRenamedFrom] = h.renamedFrom
+			}
+		}
+		if version, ok := removedIn[name]; ok {
+			extensions[ExtensionRemovedIn] = version
+		}
+		if len(extensions) > 0 {
+			schema.Properties[name] = withExtensions(propRef, extensions)
+		}
+	}
+}
+
+// withExtensions sets extensions on a property schema
+// $ref siblings are ignored by OpenAPI 3.0, so referenced schemas are wrapped in allOf
+func withExtensions(propRef *openapi3.SchemaRef, extensions map[string]any) *openapi3.SchemaRef {
+	if propRef == nil {
+		return nil
+	}
+	if propRef.Ref != "" {
+		propRef = openapi3.NewSchemaRef("", &openapi3.Schema{
+			AllOf: openapi3.SchemaRefs{&openapi3.SchemaRef{Ref: propRef.Ref}},
+		})
+	}
+	if propRef.Value == nil {
+		return propRef
+	}
+	if propRef.Value.Extensions == nil {
+		propRef.Value.Extensions = make(map[string]any, len(extensions))
+	}
+	for key, value := range extensions {
+		propRef.Value.Extensions[key] = value
+	}
+	return propRef
+}
+
+// annotateSpecForVersion adds x-epoch-* extensions to generated schemas and registered operations
+// Each component is annotated with the same direction(s) it was transformed with.
+func (sg *SchemaGenerator) annotateSpecForVersion(
+	baseSpec *openapi3.T,
+	spec *openapi3.T,
+	types []reflect.Type,
+	version *epoch.Version,
+) {
+	versionKey := version.String()
+
+	// Nested components carry both request and response transformations
+	for _, nestedType := range sg.typesToGenerate[versionKey] {
+		componentName := sg.getComponentNameForType(versionKey, nestedType)
+		if schemaRef, ok := spec.Components.Schemas[componentName]; ok && schemaRef.Value != nil {
+			sg.transformer.AnnotateSchemaForVersion(schemaRef.Value, nestedType, version, SchemaDirectionRequest)
+			sg.transformer.AnnotateSchemaForVersion(schemaRef.Value, nestedType, version, SchemaDirectionResponse)
+		}
+	}
+
+	// Top-level types transformed in place from the base spec use their endpoint direction
+	for _, typ := range types {
+		if typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+			continue
+		}
+		schemaName := sg.schemaNameForType(baseSpec, typ)
+		if schemaName == sg.componentName(typ) && sg.getComponentNameForType(versionKey, typ) == schemaName {
+			continue // Already annotated as a nested component
+		}
+		if schemaRef, ok := spec.Components.Schemas[schemaName]; ok && schemaRef.Value != nil {
+			sg.transformer.AnnotateSchemaForVersion(schemaRef.Value, typ, version, sg.getDirectionForType(typ))
+		}
+	}
+
+	sg.annotateOperationsForVersion(spec, version)
+}
+
+// annotateOperationsForVersion sets x-epoch-changed-in on operations of registered endpoints
+// Paths are shared with the base spec, so annotated path items and operations are copied first.
+func (sg *SchemaGenerator) annotateOperationsForVersion(spec *openapi3.T, version *epoch.Version) {
+	if spec.Paths == nil || spec.Paths.Len() == 0 {
+		return
+	}
+
+	paths := openapi3.NewPaths()
+	paths.Extensions = spec.Paths.Extensions
+	for path, item := range spec.Paths.Map() {
+		itemCopy := *item
+		paths.Set(path, &itemCopy)
+	}
+	spec.Paths = paths
+
+	changes := sg.transformer.allVersionChanges()
+	for _, endpoint := range sg.sortedEndpoints() {
+		item := paths.Value(ginPathToOpenAPI(endpoint.PathPattern))
+		if item == nil {
+			continue
+		}
+		operation := item.GetOperation(endpoint.Method)
+		if operation == nil {
+			continue
+		}
+
+		endpointTypes := make(map[reflect.Type]bool)
+		for _, t := range epoch.CollectMigratableTypes(endpoint.RequestType) {
+			endpointTypes[t] = true
+		}
+		for _, t := range epoch.CollectMigratableTypes(endpoint.ResponseType) {
+			endpointTypes[t] = true
+		}
+
+		var changedIn []string
+		for _, vc := range changes {
+			for t := range endpointTypes {
+				vc.BindTypes(t)
+			}
+			toVersion := vc.ToVersion()
+			if toVersion.IsNewerThan(version) {
+				continue
+			}
+			if len(changedIn) > 0 && changedIn[len(changedIn)-1] == toVersion.String() {
+				continue
+			}
+			for _, entry := range vc.ChangelogEntries() {
+				if endpointTypes[entry.Type] {
+					changedIn = append(changedIn, toVersion.String())
+					break
+				}
+			}
+		}
+		if len(changedIn) == 0 {
+			continue
+		}
+
+		operationCopy := *operation
+		operationCopy.Extensions = make(map[string]any, len(operation.Extensions)+1)
+		for key, value := range operation.Extensions {
+			operationCopy.Extensions[key] = value
+		}
+		operationCopy.Extensions[ExtensionChangedIn] = changedIn
+		item.SetOperation(endpoint.Method, &operationCopy)
+	}
+}
+
+// ginPathToOpenAPI converts a Gin route pattern (/users/:id) to an OpenAPI path (/users/{id})
+func ginPathToOpenAPI(pathPattern string) string {
+	segments := strings.Split(pathPattern, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,130 @@
+package openapi
+
+import (
+	"reflect"
+
+	"github.com/astronomer/epoch/epoch"
+	"github.com/getkin/kin-openapi/openapi3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Version Extensions", func() {
+	var (
+		v1, v2, v3 *epoch.Version
+		generator  *SchemaGenerator
+		baseSpec   *openapi3.T
+	)
+
+	BeforeEach(func() {
+		v1, _ = epoch.NewDateVersion("2024-01-01")
+		v2, _ = epoch.NewDateVersion("2024-06-01")
+		v3, _ = epoch.NewDateVersion("2025-01-01")
+
+		versionBundle, err := epoch.NewVersionBundle([]*epoch.Version{v1, v2, v3})
+		Expect(err).NotTo(HaveOccurred())
+
+		// v2 adds email; v3 renames full_name to name and drops nickname
+		v1.Changes = []epoch.VersionChangeInterface{
+			epoch.NewVersionChangeBuilder(v1, v2).
+				ForType(TestUserResponse{}).
+				ResponseToPreviousVersion().
+				RemoveField("email").
+				Build(),
+		}
+		v2.Changes = []epoch.VersionChangeInterface{
+			epoch.NewVersionChangeBuilder(v2, v3).
+				ForType(TestUserResponse{}).
+				ResponseToPreviousVersion().
+				RenameField("name", "full_name").
+				AddField("nickname", "").
+				Build(),
+		}
+
+		registry := epoch.NewEndpointRegistry()
+		registry.Register("GET", "/users/:id", &epoch.EndpointDefinition{
+			Method:       "GET",
+			PathPattern:  "/users/:id",
+			ResponseType: reflect.TypeOf(TestUserResponse{}),
+		})
+
+		generator = NewSchemaGenerator(SchemaGeneratorConfig{
+			VersionBundle:     versionBundle,
+			TypeRegistry:      registry,
+			VersionExtensions: true,
+		})
+
+		paths := openapi3.NewPaths()
+		paths.Set("/users/{id}", &openapi3.PathItem{
+			Get: &openapi3.Operation{OperationID: "getUser", Responses: openapi3.NewResponses()},
+		})
+		baseSpec = &openapi3.T{
+			OpenAPI:    "3.0.3",
+			Info:       &openapi3.Info{Title: "Test", Version: "1.0"},
+			Paths:      paths,
+			Components: &openapi3.Components{Schemas: openapi3.Schemas{}},
+		}
+	})
+
+	propertyExtensions := func(spec *openapi3.T, property string) map[string]any {
+		schema := spec.Components.Schemas["TestUserResponse"]
+		Expect(schema).NotTo(BeNil())
+		Expect(schema.Value.Properties).To(HaveKey(property))
+		return schema.Value.Properties[property].Value.Extensions
+	}
+
+	It("should mark when properties were added and renamed", func() {
+		spec, err := generator.GenerateSpecForVersion(baseSpec, v3)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(propertyExtensions(spec, "email")).To(HaveKeyWithValue(ExtensionAddedIn, "2024-06-01"))
+		Expect(propertyExtensions(spec, "name")).To(HaveKeyWithValue(ExtensionRenamedFrom, "full_name"))
+		Expect(propertyExtensions(spec, "id")).To(BeEmpty())
+	})
+
+	It("should mark properties that newer versions remove", func() {
+		spec, err := generator.GenerateSpecForVersion(baseSpec, v2)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(propertyExtensions(spec, "email")).To(HaveKeyWithValue(ExtensionAddedIn, "2024-06-01"))
+		Expect(propertyExtensions(spec, "nickname")).To(HaveKeyWithValue(ExtensionRemovedIn, "2025-01-01"))
+		Expect(propertyExtensions(spec, "full_name")).To(BeEmpty())
+	})
+
+	It("should list the versions that changed an operation", func() {
+		headSpec, err := generator.GenerateSpecForVersion(baseSpec, epoch.NewHeadVersion())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(headSpec.Paths.Value("/users/{id}").Get.Extensions).To(
+			HaveKeyWithValue(ExtensionChangedIn, []string{"2024-06-01", "2025-01-01"}))
+
+		v1Spec, err := generator.GenerateSpecForVersion(baseSpec, v1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v1Spec.Paths.Value("/users/{id}").Get.Extensions).NotTo(HaveKey(ExtensionChangedIn))
+
+		// The base spec's operations are left untouched
+		Expect(baseSpec.Paths.Value("/users/{id}").Get.Extensions).To(BeEmpty())
+	})
+
+	It("should not annotate unless enabled", func() {
+		generator.config.VersionExtensions = false
+		spec, err := generator.GenerateSpecForVersion(baseSpec, v3)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(propertyExtensions(spec, "email")).To(BeEmpty())
+		Expect(spec.Paths.Value("/users/{id}").Get.Extensions).To(BeEmpty())
+	})
+
+	It("should wrap $ref properties in allOf so extensions survive", func() {
+		wrapped := withExtensions(&openapi3.SchemaRef{Ref: "#/components/schemas/Address"},
+			map[string]any{ExtensionAddedIn: "2024-06-01"})
+
+		Expect(wrapped.Ref).To(BeEmpty())
+		Expect(wrapped.Value.AllOf).To(HaveLen(1))
+		Expect(wrapped.Value.AllOf[0].Ref).To(Equal("#/components/schemas/Address"))
+		Expect(wrapped.Value.Extensions).To(HaveKeyWithValue(ExtensionAddedIn, "2024-06-01"))
+	})
+
+	It("should convert Gin route patterns to OpenAPI paths", func() {
+		Expect(ginPathToOpenAPI("/orgs/:org/files/*path")).To(Equal("/orgs/{org}/files/{path}"))
+	})
+})
@@ -3,6 +3,7 @@ package openapi
 import (
 	"fmt"
 	"reflect"
+	"sort"
 
 	"github.com/astronomer/epoch/epoch"
 	"github.com/getkin/kin-openapi/openapi3"
@@ -106,7 +107,7 @@ func (vt *VersionTransformer) getVersionChanges(
 
 			// Process changes on currentVer (which describe transitions FROM currentVer)
 			// These are applied in reverse for request schema transformation
-			for _, vc := range currentVer.Changes {
+			for _, vc := range vt.versionBundle.VersionChanges(currentVer) {
 				if epochVC, ok := vc.(*epoch.VersionChange); ok {
 					// Check if this change has request operations for our type
 					if ops, exists := epochVC.GetRequestOperationsByType(targetType); exists && len(ops) > 0 {
@@ -156,7 +157,7 @@ func (vt *VersionTransformer) getVersionChanges(
 
 			// Process changes on currentVer (which describe transitions FROM currentVer)
 			// These are applied in reverse for response transformation
-			for _, vc := range currentVer.Changes {
+			for _, vc := range vt.versionBundle.VersionChanges(currentVer) {
 				if epochVC, ok := vc.(*epoch.VersionChange); ok {
 					// Check if this change applies to our type
 					if vt.changeAppliesToType(epochVC, targetType, SchemaDirectionResponse) {
@@ -227,6 +228,29 @@ func (vt *VersionTransformer) applyChange(
 			actualOp := op
 
 			if change.inverted {
+				// Plugin operations document their schema effect instead of implementing Inverse()
+				if described, ok := op.(*epoch.RequestOperation); ok {
+					vt.applyOperationDoc(schema, described.Describe().Inverse())
+					continue
+				}
+
+				// Older clients send the embedded object instead of HEAD's id field
+				if collapse, ok := op.(*epoch.RequestCollapseReference); ok {
+					vt.ExpandReferenceInSchema(schema, collapse.HeadFieldName(), collapse.Name)
+					continue
+				}
+
+				// HEAD requires the field but older clients may omit it, so keep it as optional
+				if withDefault, ok := op.(*epoch.RequestAddFieldWithDefault); ok {
+					vt.MarkFieldOptional(schema, withDefault.Name)
+					continue
+				}
+
+				// Older clients must send a field HEAD's validation rejects when missing
+				if add, ok := op.(*epoch.RequestAddField); ok && add.Validation == epoch.FailValidation {
+					continue
+				}
+
 				// Invert the operation for schema generation
 				actualOp = op.Inverse()
 				if actualOp == nil {
@@ -265,9 +289,9 @@ func (vt *VersionTransformer) applyOperationToSchema(
 ) error {
 	switch operation := op.(type) {
 	case *epoch.ResponseAddField:
-		// Add a field to the response schema
+		// Add a field to the response schema; older clients always receive it
 		fieldSchema := vt.createSchemaForValue(operation.Default)
-		vt.AddFieldToSchema(schema, operation.Name, fieldSchema, false)
+		vt.AddFieldToSchema(schema, operation.Name, fieldSchema, true)
 
 	case *epoch.ResponseRemoveField:
 		// Remove a field from the response schema
@@ -277,6 +301,28 @@ func (vt *VersionTransformer) applyOperationToSchema(
 		// Rename a field in the response schema
 		vt.RenameFieldInSchema(schema, operation.NewerVersionName, operation.OlderVersionName)
 
+	case *epoch.ResponseDualWriteField:
+		// Older clients get the field under both names; before Since, only under the older one
+		if fieldSchema, ok := schema.Properties[operation.NewerVersionName]; ok {
+			vt.AddFieldToSchema(schema, operation.OlderVersionName, fieldSchema,
+				containsString(schema.Required, operation.NewerVersionName))
+			if operation.Since != nil {
+				vt.MarkFieldOptional(schema, operation.NewerVersionName)
+			}
+		}
+
+	case *epoch.ResponseRenameFieldMatching:
+		// Rename every matching field in the response schema
+		vt.RenameFieldsMatchingInSchema(schema, operation.Rename)
+
+	case *epoch.ResponseConvertKeysCase:
+		// Convert property names of the response schema and its inline schemas
+		vt.ConvertKeysInSchema(schema, operation.Case, operation.Except)
+
+	case *epoch.RequestConvertKeysCase:
+		// Convert property names of the request schema and its inline schemas
+		vt.ConvertKeysInSchema(schema, operation.Case, operation.Except)
+
 	case *epoch.RequestAddField:
 		// Add a field to the request schema
 		fieldSchema := vt.createSchemaForValue(operation.Default)
@@ -296,9 +342,44 @@ func (vt *VersionTransformer) applyOperationToSchema(
 		vt.RenameFieldInSchema(schema, operation.OlderVersionName, operation.NewerVersionName)
 
 	case *epoch.ResponseRemoveFieldIfDefault:
-		// For schema generation, treat this as a regular remove
-		// (The conditional logic only applies at runtime)
-		vt.RemoveFieldFromSchema(schema, operation.Name)
+		// The field is omitted only when it has its default value, so it becomes optional
+		vt.MarkFieldOptional(schema, operation.Name)
+
+	case *epoch.ResponseMoveField:
+		// Move a field between nesting levels in the response schema
+		vt.MoveFieldInSchema(schema, operation.NewerVersionPath, operation.OlderVersionPath)
+
+	case *epoch.RequestMoveField:
+		// Move a field between nesting levels in the request schema
+		vt.MoveFieldInSchema(schema, operation.OlderVersionPath, operation.NewerVersionPath)
+
+	case *epoch.ResponseRenameLinkRel:
+		// Rename the relation in an inline _links schema; free-form links objects are unchanged
+		vt.MoveFieldInSchema(schema, epoch.LinksField+"."+operation.NewerVersionRel, epoch.LinksField+"."+operation.OlderVersionRel)
+
+	case *epoch.ResponseExpandReference:
+		vt.ExpandReferenceInSchema(schema, operation.IDField, operation.Name)
+
+	case *epoch.RequestCollapseReference:
+		vt.RenameFieldInSchema(schema, operation.Name, operation.HeadFieldName())
+
+	case *epoch.ResponseArrayToObject:
+		vt.ArrayToObjectInSchema(schema, operation.Name, operation.KeyField)
+
+	case *epoch.ResponseObjectToArray:
+		vt.ObjectToArrayInSchema(schema, operation.Name, operation.KeyField)
+
+	case *epoch.RequestArrayToObject:
+		vt.ArrayToObjectInSchema(schema, operation.Name, operation.KeyField)
+
+	case *epoch.RequestObjectToArray:
+		vt.ObjectToArrayInSchema(schema, operation.Name, operation.KeyField)
+
+	case *epoch.RequestOperation:
+		vt.applyOperationDoc(schema, operation.Describe())
+
+	case *epoch.ResponseOperation:
+		vt.applyOperationDoc(schema, operation.Describe())
 
 	case *epoch.RequestCustom, *epoch.ResponseCustom:
 		// Custom operations are skipped during schema generation
@@ -313,6 +394,19 @@ func (vt *VersionTransformer) applyOperationToSchema(
 	return nil
 }
 
+// applyOperationDoc applies the schema effects documented by a plugin operation
+func (vt *VersionTransformer) applyOperationDoc(schema *openapi3.Schema, doc epoch.OperationDoc) {
+	for _, name := range doc.RemovedFields {
+		vt.RemoveFieldFromSchema(schema, name)
+	}
+	for from, to := range doc.RenamedFields {
+		vt.RenameFieldInSchema(schema, from, to)
+	}
+	for name, value := range doc.AddedFields {
+		vt.AddFieldToSchema(schema, name, vt.createSchemaForValue(value), false)
+	}
+}
+
 // createSchemaForValue generates an OpenAPI schema for a given default value
 // This is used when adding fields to schemas during version transformations
 func (vt *VersionTransformer) createSchemaForValue(value interface{}) *openapi3.SchemaRef {
@@ -399,11 +493,16 @@ func (vt *VersionTransformer) AddFieldToSchema(schema *openapi3.Schema, fieldNam
 
 	schema.Properties[fieldName] = fieldSchema
 
-	if required {
+	if required && !containsString(schema.Required, fieldName) {
 		schema.Required = append(schema.Required, fieldName)
 	}
 }
 
+// MarkFieldOptional keeps a field in the schema but removes it from the required array
+func (vt *VersionTransformer) MarkFieldOptional(schema *openapi3.Schema, fieldName string) {
+	schema.Required = removeFromSlice(schema.Required, fieldName)
+}
+
 // RemoveFieldFromSchema removes a field from a schema
 func (vt *VersionTransformer) RemoveFieldFromSchema(schema *openapi3.Schema, fieldName string) {
 	if schema.Properties != nil {
@@ -441,6 +540,218 @@ func (vt *VersionTransformer) RenameFieldInSchema(schema *openapi3.Schema, oldNa
 	}
 }
 
+// RenameFieldsMatchingInSchema renames every property of a schema using rename
+// A property isn't renamed onto one the schema already has, matching runtime behavior.
+func (vt *VersionTransformer) RenameFieldsMatchingInSchema(schema *openapi3.Schema, rename func(string) string) {
+	if schema.Properties == nil {
+		return
+	}
+
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		newName := rename(name)
+		if newName == name || newName == "" {
+			continue
+		}
+		if _, exists := schema.Properties[newName]; exists {
+			continue
+		}
+		vt.RenameFieldInSchema(schema, name, newName)
+	}
+}
+
+// ConvertKeysInSchema converts property names of a schema and of its inline object and array item schemas
+// Excepted properties keep their name and schema. Schemas behind a $ref are shared components, so they
+// are left unchanged and converted when their own type is transformed.
+func (vt *VersionTransformer) ConvertKeysInSchema(schema *openapi3.Schema, keyCase epoch.KeyCase, except []string) {
+	if schema == nil {
+		return
+	}
+	excepted := make(map[string]bool, len(except))
+	for _, name := range except {
+		excepted[name] = true
+	}
+	vt.convertKeysInSchema(schema, keyCase, excepted)
+}
+
+// convertKeysInSchema converts property names recursively through inline schemas
+func (vt *VersionTransformer) convertKeysInSchema(schema *openapi3.Schema, keyCase epoch.KeyCase, except map[string]bool) {
+	if schema.Items != nil && schema.Items.Ref == "" && schema.Items.Value != nil {
+		// CloneSchema shares items between versions, so convert a copy
+		schema.Items = openapi3.NewSchemaRef("", CloneSchema(schema.Items.Value))
+		vt.convertKeysInSchema(schema.Items.Value, keyCase, except)
+	}
+	if schema.Properties == nil {
+		return
+	}
+
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if except[name] {
+			continue
+		}
+		if property := schema.Properties[name]; property != nil && property.Ref == "" && property.Value != nil {
+			vt.convertKeysInSchema(property.Value, keyCase, except)
+		}
+		newName := keyCase.Convert(name)
+		if newName == name {
+			continue
+		}
+		if _, exists := schema.Properties[newName]; exists {
+			continue
+		}
+		vt.RenameFieldInSchema(schema, name, newName)
+	}
+}
+
+// MoveFieldInSchema moves a property between nesting levels using dotted paths
+// Missing intermediate objects are created and objects left without properties are removed.
+// Only inline schemas are rewritten: paths through a $ref are left unchanged since components are shared.
+func (vt *VersionTransformer) MoveFieldInSchema(schema *openapi3.Schema, fromPath, toPath string) {
+	fromParts := epoch.SplitPath(fromPath)
+	toParts := epoch.SplitPath(toPath)
+
+	var sourceParents []*openapi3.Schema
+	parent := schema
+	for _, part := range fromParts[:len(fromParts)-1] {
+		sourceParents = append(sourceParents, parent)
+		if parent = inlineProperty(parent, part); parent == nil {
+			return
+		}
+	}
+	fromName := fromParts[len(fromParts)-1]
+	fieldSchema, exists := parent.Properties[fromName]
+	if !exists {
+		return
+	}
+
+	// Check the destination is reachable before changing anything
+	target := schema
+	for _, part := range toParts[:len(toParts)-1] {
+		if _, exists := target.Properties[part]; !exists {
+			break
+		}
+		if target = inlineProperty(target, part); target == nil {
+			return
+		}
+	}
+
+	required := containsString(parent.Required, fromName)
+	vt.RemoveFieldFromSchema(parent, fromName)
+	for i := len(sourceParents) - 1; i >= 0; i-- {
+		if len(parent.Properties) > 0 || (len(toParts) > i+1 && hasPathPrefix(toParts, fromParts[:i+1])) {
+			break
+		}
+		vt.RemoveFieldFromSchema(sourceParents[i], fromParts[i])
+		parent = sourceParents[i]
+	}
+
+	target = schema
+	for _, part := range toParts[:len(toParts)-1] {
+		if _, exists := target.Properties[part]; !exists {
+			vt.AddFieldToSchema(target, part, openapi3.NewSchemaRef("", openapi3.NewObjectSchema()), required)
+		}
+		target = inlineProperty(target, part)
+	}
+	vt.AddFieldToSchema(target, toParts[len(toParts)-1], fieldSchema, required)
+}
+
+// inlineProperty returns a property's inline object schema, or nil when it is missing or a $ref
+func inlineProperty(schema *openapi3.Schema, name string) *openapi3.Schema {
+	propRef, exists := schema.Properties[name]
+	if !exists || propRef == nil || propRef.Ref != "" || propRef.Value == nil {
+		return nil
+	}
+	return propRef.Value
+}
+
+// ExpandReferenceInSchema replaces an id property with an object property for the referenced
+// resource; the object's shape comes from the fetcher, so it's documented as a free-form object
+func (vt *VersionTransformer) ExpandReferenceInSchema(schema *openapi3.Schema, idField, name string) {
+	if _, exists := schema.Properties[idField]; !exists {
+		return
+	}
+	required := false
+	for _, field := range schema.Required {
+		if field == idField {
+			required = true
+		}
+	}
+	vt.RemoveFieldFromSchema(schema, idField)
+	vt.AddFieldToSchema(schema, name, openapi3.NewSchemaRef("", &openapi3.Schema{
+		Type:        &openapi3.Types{"object"},
+		Description: "Referenced by " + idField,
+		Nullable:    true,
+	}), required)
+}
+
+// ArrayToObjectInSchema turns an array property into an object whose values are the array items
+// The key field is dropped from inline item schemas; referenced item schemas are kept as is.
+func (vt *VersionTransformer) ArrayToObjectInSchema(schema *openapi3.Schema, fieldName, keyField string) {
+	fieldSchema, exists := schema.Properties[fieldName]
+	if !exists || fieldSchema == nil || fieldSchema.Value == nil || fieldSchema.Value.Items == nil {
+		return
+	}
+
+	items := fieldSchema.Value.Items
+	if items.Ref == "" && items.Value != nil {
+		value := CloneSchema(items.Value)
+		vt.RemoveFieldFromSchema(value, keyField)
+		items = openapi3.NewSchemaRef("", value)
+	}
+
+	schema.Properties[fieldName] = openapi3.NewSchemaRef("", &openapi3.Schema{
+		Type:                 &openapi3.Types{"object"},
+		Description:          fieldSchema.Value.Description,
+		Nullable:             fieldSchema.Value.Nullable,
+		AdditionalProperties: openapi3.AdditionalProperties{Schema: items},
+	})
+}
+
+// ObjectToArrayInSchema turns a map-like object property into an array of its values,
+// each holding the object key in keyField
+func (vt *VersionTransformer) ObjectToArrayInSchema(schema *openapi3.Schema, fieldName, keyField string) {
+	fieldSchema, exists := schema.Properties[fieldName]
+	if !exists || fieldSchema == nil || fieldSchema.Value == nil || fieldSchema.Value.AdditionalProperties.Schema == nil {
+		return
+	}
+
+	keySchema := openapi3.NewStringSchema()
+	values := fieldSchema.Value.AdditionalProperties.Schema
+	var items *openapi3.Schema
+	if values.Ref == "" && values.Value != nil {
+		items = CloneSchema(values.Value)
+		vt.AddFieldToSchema(items, keyField, openapi3.NewSchemaRef("", keySchema), true)
+	} else {
+		// Referenced schemas are shared, so add the key field alongside them
+		items = &openapi3.Schema{AllOf: openapi3.SchemaRefs{
+			values,
+			openapi3.NewSchemaRef("", &openapi3.Schema{
+				Type:       &openapi3.Types{"object"},
+				Properties: openapi3.Schemas{keyField: openapi3.NewSchemaRef("", keySchema)},
+				Required:   []string{keyField},
+			}),
+		}}
+	}
+
+	schema.Properties[fieldName] = openapi3.NewSchemaRef("", &openapi3.Schema{
+		Type:        &openapi3.Types{"array"},
+		Description: fieldSchema.Value.Description,
+		Nullable:    fieldSchema.Value.Nullable,
+		Items:       openapi3.NewSchemaRef("", items),
+	})
+}
+
 // CloneSchema creates a deep copy of an OpenAPI schema
 func CloneSchema(original *openapi3.Schema) *openapi3.Schema {
 	if original == nil {
@@ -516,8 +827,23 @@ func CloneSchema(original *openapi3.Schema) *openapi3.Schema {
 	// Copy items
 	clone.Items = original.Items
 
-	// Copy additionalProperties
+	// Copy additionalProperties, cloning inline value schemas so versions don't share them
 	clone.AdditionalProperties = original.AdditionalProperties
+	if valueRef := original.AdditionalProperties.Schema; valueRef != nil && valueRef.Ref == "" && valueRef.Value != nil {
+		clone.AdditionalProperties.Schema = openapi3.NewSchemaRef("", CloneSchema(valueRef.Value))
+	}
+	if original.AdditionalProperties.Has != nil {
+		has := *original.AdditionalProperties.Has
+		clone.AdditionalProperties.Has = &has
+	}
+
+	// Copy vendor extensions
+	if original.Extensions != nil {
+		clone.Extensions = make(map[string]any, len(original.Extensions))
+		for k, v := range original.Extensions {
+			clone.Extensions[k] = v
+		}
+	}
 
 	return clone
 }
@@ -532,3 +858,26 @@ func removeFromSlice(slice []string, item string) []string {
 	}
 	return result
 }
+
+// containsString reports whether slice contains item
+func containsString(slice []string, item string) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
+
+// hasPathPrefix reports whether path starts with all segments of prefix
+func hasPathPrefix(path, prefix []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if path[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
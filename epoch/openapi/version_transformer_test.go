@@ -2,8 +2,10 @@ package openapi
 
 import (
 	"reflect"
+	"regexp"
 
 	"github.com/astronomer/epoch/epoch"
+	"github.com/astronomer/epoch/epoch/jsonast"
 	"github.com/getkin/kin-openapi/openapi3"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -529,6 +531,105 @@ var _ = Describe("VersionTransformer", func() {
 				Expect(foundNewName).To(BeTrue())
 			})
 		})
+		Context("Move field", func() {
+			It("should move a field into new intermediate objects and prune emptied ones", func() {
+				schema.Properties["profile"] = openapi3.NewSchemaRef("", &openapi3.Schema{
+					Type:       &openapi3.Types{"object"},
+					Properties: openapi3.Schemas{"theme": openapi3.NewSchemaRef("", openapi3.NewStringSchema())},
+					Required:   []string{"theme"},
+				})
+
+				transformer.MoveFieldInSchema(schema, "profile.theme", "settings.theme")
+
+				Expect(schema.Properties).NotTo(HaveKey("profile"))
+				settings := schema.Properties["settings"].Value
+				Expect(settings.Properties["theme"].Value.Type.Is("string")).To(BeTrue())
+				Expect(settings.Required).To(Equal([]string{"theme"}))
+				Expect(schema.Required).To(Equal([]string{"settings"}))
+
+				transformer.MoveFieldInSchema(schema, "settings.theme", "theme")
+				Expect(schema.Properties).To(HaveKey("theme"))
+				Expect(schema.Properties).NotTo(HaveKey("settings"))
+			})
+
+			It("should leave paths through referenced schemas alone", func() {
+				schema.Properties["theme"] = openapi3.NewSchemaRef("", openapi3.NewStringSchema())
+				schema.Properties["settings"] = openapi3.NewSchemaRef("#/components/schemas/Settings", nil)
+
+				transformer.MoveFieldInSchema(schema, "theme", "settings.theme")
+				transformer.MoveFieldInSchema(schema, "settings.theme", "color")
+
+				Expect(schema.Properties).To(HaveKey("theme"))
+				Expect(schema.Properties).NotTo(HaveKey("color"))
+			})
+		})
+
+		Context("Reshape field", func() {
+			phone := func() *openapi3.Schema {
+				return &openapi3.Schema{
+					Type: &openapi3.Types{"object"},
+					Properties: openapi3.Schemas{
+						"type":   openapi3.NewSchemaRef("", openapi3.NewStringSchema()),
+						"number": openapi3.NewSchemaRef("", openapi3.NewStringSchema()),
+					},
+					Required: []string{"type", "number"},
+				}
+			}
+
+			It("should turn an array into a keyed object without the key field", func() {
+				items := openapi3.NewSchemaRef("", phone())
+				schema.Properties["phones"] = openapi3.NewSchemaRef("", openapi3.NewArraySchema().WithItems(items.Value))
+
+				transformer.ArrayToObjectInSchema(schema, "phones", "type")
+
+				phones := schema.Properties["phones"].Value
+				Expect(phones.Type.Is("object")).To(BeTrue())
+				values := phones.AdditionalProperties.Schema.Value
+				Expect(values.Properties).To(HaveKey("number"))
+				Expect(values.Properties).NotTo(HaveKey("type"))
+				Expect(values.Required).To(Equal([]string{"number"}))
+				Expect(items.Value.Properties).To(HaveKey("type"))
+			})
+
+			It("should turn a keyed object into an array holding the key", func() {
+				values := phone()
+				delete(values.Properties, "type")
+				values.Required = []string{"number"}
+				schema.Properties["phones"] = openapi3.NewSchemaRef("", openapi3.NewObjectSchema().WithAdditionalProperties(values))
+
+				transformer.ObjectToArrayInSchema(schema, "phones", "type")
+
+				phones := schema.Properties["phones"].Value
+				Expect(phones.Type.Is("array")).To(BeTrue())
+				Expect(phones.Items.Value.Properties).To(HaveKey("type"))
+				Expect(phones.Items.Value.Required).To(ContainElement("type"))
+				Expect(values.Properties).NotTo(HaveKey("type"))
+			})
+
+			It("should add the key field alongside referenced item schemas", func() {
+				ref := openapi3.NewSchemaRef("#/components/schemas/Phone", nil)
+				schema.Properties["phones"] = openapi3.NewSchemaRef("", &openapi3.Schema{
+					Type:                 &openapi3.Types{"object"},
+					AdditionalProperties: openapi3.AdditionalProperties{Schema: ref},
+				})
+
+				transformer.ObjectToArrayInSchema(schema, "phones", "type")
+
+				allOf := schema.Properties["phones"].Value.Items.Value.AllOf
+				Expect(allOf).To(HaveLen(2))
+				Expect(allOf[0].Ref).To(Equal("#/components/schemas/Phone"))
+				Expect(allOf[1].Value.Required).To(Equal([]string{"type"}))
+			})
+
+			It("should leave fields of another shape alone", func() {
+				schema.Properties["phones"] = openapi3.NewSchemaRef("", openapi3.NewStringSchema())
+
+				transformer.ArrayToObjectInSchema(schema, "phones", "type")
+				transformer.ObjectToArrayInSchema(schema, "phones", "type")
+
+				Expect(schema.Properties["phones"].Value.Type.Is("string")).To(BeTrue())
+			})
+		})
 	})
 
 	Describe("Utilities", func() {
@@ -619,4 +720,319 @@ var _ = Describe("VersionTransformer", func() {
 			})
 		})
 	})
+	Describe("Plugin operations", func() {
+		It("should apply documented schema effects in both directions", func() {
+			v1, _ := epoch.NewDateVersion("2024-01-01")
+			v2, _ := epoch.NewDateVersion("2024-06-01")
+
+			change := epoch.NewVersionChangeBuilder(v1, v2).
+				ForType(TestUser{}).
+				RequestToNextVersion().
+				CustomOperation(pluginRename{}).
+				ResponseToPreviousVersion().
+				CustomOperation(pluginRename{}.inverse()).
+				Build()
+
+			vb, _ := epoch.NewVersionBundle([]*epoch.Version{v1, v2})
+			v2.Changes = []epoch.VersionChangeInterface{change}
+			transformer := NewVersionTransformer(vb)
+
+			baseSchema := &openapi3.Schema{
+				Type: &openapi3.Types{"object"},
+				Properties: map[string]*openapi3.SchemaRef{
+					"id":    openapi3.NewSchemaRef("", &openapi3.Schema{Type: &openapi3.Types{"integer"}}),
+					"phone": openapi3.NewSchemaRef("", &openapi3.Schema{Type: &openapi3.Types{"string"}}),
+				},
+			}
+
+			for _, direction := range []SchemaDirection{SchemaDirectionRequest, SchemaDirectionResponse} {
+				result, err := transformer.TransformSchemaForVersion(baseSchema, reflect.TypeOf(TestUser{}), v1, direction)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Properties).To(HaveKey("phone_number"))
+				Expect(result.Properties).NotTo(HaveKey("phone"))
+				Expect(result.Properties).To(HaveKey("legacy"))
+			}
+
+			// Only the response doc carries a value to infer the added field's type from
+			result, err := transformer.TransformSchemaForVersion(baseSchema, reflect.TypeOf(TestUser{}), v1, SchemaDirectionResponse)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Properties["legacy"].Value.Type.Is("boolean")).To(BeTrue())
+		})
+	})
+
+	Describe("Required fields", func() {
+		var (
+			v1, v2     *epoch.Version
+			vb         *epoch.VersionBundle
+			baseSchema *openapi3.Schema
+		)
+
+		BeforeEach(func() {
+			v1, _ = epoch.NewDateVersion("2024-01-01")
+			v2, _ = epoch.NewDateVersion("2024-06-01")
+			vb, _ = epoch.NewVersionBundle([]*epoch.Version{v1, v2})
+
+			baseSchema = &openapi3.Schema{
+				Type: &openapi3.Types{"object"},
+				Properties: map[string]*openapi3.SchemaRef{
+					"id":     openapi3.NewSchemaRef("", &openapi3.Schema{Type: &openapi3.Types{"integer"}}),
+					"status": openapi3.NewSchemaRef("", &openapi3.Schema{Type: &openapi3.Types{"string"}}),
+				},
+				Required: []string{"id", "status"},
+			}
+		})
+
+		transform := func(change *epoch.VersionChange, direction SchemaDirection) *openapi3.Schema {
+			v1.Changes = []epoch.VersionChangeInterface{change}
+			result, err := NewVersionTransformer(vb).TransformSchemaForVersion(baseSchema, reflect.TypeOf(TestUser{}), v1, direction)
+			Expect(err).NotTo(HaveOccurred())
+			return result
+		}
+
+		It("should make fields HEAD requires optional for older request schemas", func() {
+			change := epoch.NewVersionChangeBuilder(v1, v2).
+				ForType(TestUser{}).
+				RequestToNextVersion().
+				AddFieldWithDefault("status", "active").
+				Build()
+
+			result := transform(change, SchemaDirectionRequest)
+			Expect(result.Properties).To(HaveKey("status"))
+			Expect(result.Required).To(Equal([]string{"id"}))
+		})
+
+		It("should keep fields added with FailValidation required in older request schemas", func() {
+			change := epoch.NewVersionChangeBuilder(v1, v2).
+				ForType(TestUser{}).
+				RequestToNextVersion().
+				AddField("status", nil, epoch.FailValidation).
+				Build()
+
+			result := transform(change, SchemaDirectionRequest)
+			Expect(result.Properties).To(HaveKey("status"))
+			Expect(result.Required).To(Equal([]string{"id", "status"}))
+		})
+
+		It("should require fields that response migrations always add", func() {
+			change := epoch.NewVersionChangeBuilder(v1, v2).
+				ForType(TestUser{}).
+				ResponseToPreviousVersion().
+				AddField("legacy_id", "").
+				AddField("status", "active").
+				Build()
+
+			result := transform(change, SchemaDirectionResponse)
+			Expect(result.Required).To(Equal([]string{"id", "status", "legacy_id"}))
+		})
+
+		It("should make fields removed when default optional for older responses", func() {
+			change := epoch.NewVersionChangeBuilder(v1, v2).
+				ForType(TestUser{}).
+				ResponseToPreviousVersion().
+				RemoveFieldIfDefault("status", "active").
+				Build()
+
+			result := transform(change, SchemaDirectionResponse)
+			Expect(result.Properties).To(HaveKey("status"))
+			Expect(result.Required).To(Equal([]string{"id"}))
+		})
+
+		It("should drop removed fields from the required list", func() {
+			change := epoch.NewVersionChangeBuilder(v1, v2).
+				ForType(TestUser{}).
+				ResponseToPreviousVersion().
+				RemoveField("status").
+				Build()
+
+			result := transform(change, SchemaDirectionResponse)
+			Expect(result.Required).To(Equal([]string{"id"}))
+			Expect(baseSchema.Required).To(Equal([]string{"id", "status"}))
+		})
+	})
+})
+
+// pluginRename is a plugin operation that renames phone_number (v1) to phone (HEAD)
+// and drops the v1-only "legacy" flag on the way to HEAD
+type pluginRename struct {
+	reversed bool
+}
+
+func (op pluginRename) inverse() pluginRename {
+	return pluginRename{reversed: !op.reversed}
+}
+
+func (op pluginRename) Apply(node *jsonast.Node, _ epoch.TransformDirection, _ *epoch.OperationContext) error {
+	return nil
+}
+
+func (op pluginRename) Describe() epoch.OperationDoc {
+	doc := epoch.OperationDoc{
+		Name:          "rename_phone",
+		RenamedFields: map[string]string{"phone_number": "phone"},
+		RemovedFields: []string{"legacy"},
+	}
+	if op.reversed {
+		doc = epoch.OperationDoc{
+			Name:          "rename_phone",
+			RenamedFields: map[string]string{"phone": "phone_number"},
+			AddedFields:   map[string]interface{}{"legacy": true},
+		}
+	}
+	return doc
+}
+
+var _ = Describe("HAL link schemas", func() {
+	It("should rename relations of an inline _links schema", func() {
+		v1, _ := epoch.NewDateVersion("2024-01-01")
+		v2, _ := epoch.NewDateVersion("2024-06-01")
+		vb, _ := epoch.NewVersionBundle([]*epoch.Version{v1, v2})
+		v1.Changes = []epoch.VersionChangeInterface{
+			epoch.NewVersionChangeBuilder(v1, v2).
+				ForType(TestUser{}).
+				ResponseToPreviousVersion().
+				RenameLinkRel("author", "owner").
+				RewriteLinkHref("self", func(href string) string { return href }).
+				Build(),
+		}
+
+		link := openapi3.NewObjectSchema().WithProperty("href", openapi3.NewStringSchema())
+		baseSchema := openapi3.NewObjectSchema().WithProperty("_links", openapi3.NewObjectSchema().
+			WithProperty("self", link).
+			WithProperty("author", link))
+
+		result, err := NewVersionTransformer(vb).TransformSchemaForVersion(baseSchema, reflect.TypeOf(TestUser{}), v1, SchemaDirectionResponse)
+		Expect(err).NotTo(HaveOccurred())
+		links := result.Properties["_links"].Value
+		Expect(links.Properties).To(HaveKey("self"))
+		Expect(links.Properties).To(HaveKey("owner"))
+		Expect(links.Properties).NotTo(HaveKey("author"))
+		Expect(baseSchema.Properties["_links"].Value.Properties).To(HaveKey("author"))
+	})
+})
+
+var _ = Describe("Reference schemas", func() {
+	It("should document embedded objects instead of ids for older versions", func() {
+		v1, _ := epoch.NewDateVersion("2024-01-01")
+		v2, _ := epoch.NewDateVersion("2024-06-01")
+		vb, _ := epoch.NewVersionBundle([]*epoch.Version{v1, v2})
+		v1.Changes = []epoch.VersionChangeInterface{
+			epoch.NewVersionChangeBuilder(v1, v2).
+				ForType(TestUser{}).
+				RequestToNextVersion().
+				CollapseReference("organization", "id").
+				ResponseToPreviousVersion().
+				ExpandReference("organization_id", nil).
+				Build(),
+		}
+
+		baseSchema := openapi3.NewObjectSchema().
+			WithProperty("id", openapi3.NewIntegerSchema()).
+			WithProperty("organization_id", openapi3.NewIntegerSchema()).
+			WithRequired([]string{"id", "organization_id"})
+
+		for _, direction := range []SchemaDirection{SchemaDirectionRequest, SchemaDirectionResponse} {
+			result, err := NewVersionTransformer(vb).TransformSchemaForVersion(baseSchema, reflect.TypeOf(TestUser{}), v1, direction)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Properties).NotTo(HaveKey("organization_id"))
+			Expect(result.Properties["organization"].Value.Type.Is("object")).To(BeTrue())
+			Expect(result.Required).To(ConsistOf("id", "organization"))
+		}
+	})
+})
+
+var _ = Describe("Schemas of types matched by predicate", func() {
+	It("should apply ForTypesMatching operations to matching types", func() {
+		v1, _ := epoch.NewDateVersion("2024-01-01")
+		v2, _ := epoch.NewDateVersion("2024-06-01")
+		vb, _ := epoch.NewVersionBundle([]*epoch.Version{v1, v2})
+		v1.Changes = []epoch.VersionChangeInterface{
+			epoch.NewVersionChangeBuilder(v1, v2).
+				ForTypesMatching(func(t reflect.Type) bool { return t == reflect.TypeOf(TestUser{}) }).
+				ResponseToPreviousVersion().
+				RenameField("email", "email_address").
+				Build(),
+		}
+
+		baseSchema := openapi3.NewObjectSchema().WithProperty("email", openapi3.NewStringSchema())
+		transformer := NewVersionTransformer(vb)
+
+		result, err := transformer.TransformSchemaForVersion(baseSchema, reflect.TypeOf(TestUser{}), v1, SchemaDirectionResponse)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Properties).To(HaveKey("email_address"))
+		Expect(result.Properties).NotTo(HaveKey("email"))
+
+		other, err := transformer.TransformSchemaForVersion(baseSchema, reflect.TypeOf(struct{ Email string }{}), v1, SchemaDirectionResponse)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(other.Properties).To(HaveKey("email"))
+	})
+})
+
+var _ = Describe("Schemas of pattern renames", func() {
+	It("should rename every matching property of response schemas", func() {
+		v1, _ := epoch.NewDateVersion("2024-01-01")
+		v2, _ := epoch.NewDateVersion("2024-06-01")
+		vb, _ := epoch.NewVersionBundle([]*epoch.Version{v1, v2})
+		v1.Changes = []epoch.VersionChangeInterface{
+			epoch.NewVersionChangeBuilder(v1, v2).
+				ForAllTypes().
+				ResponseToPreviousVersion().
+				RenameFieldMatching(regexp.MustCompile(`^(\w+)Id$`), "${1}_id", "teamId").
+				Build(),
+		}
+
+		baseSchema := openapi3.NewObjectSchema().
+			WithProperty("userId", openapi3.NewIntegerSchema()).
+			WithProperty("teamId", openapi3.NewIntegerSchema()).
+			WithProperty("name", openapi3.NewStringSchema())
+		baseSchema.Required = []string{"userId"}
+		transformer := NewVersionTransformer(vb)
+
+		result, err := transformer.TransformSchemaForVersion(baseSchema, reflect.TypeOf(TestUser{}), v1, SchemaDirectionResponse)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Properties).To(HaveKey("user_id"))
+		Expect(result.Properties).To(HaveKey("teamId"))
+		Expect(result.Properties).To(HaveKey("name"))
+		Expect(result.Properties).NotTo(HaveKey("userId"))
+		Expect(result.Required).To(Equal([]string{"user_id"}))
+	})
+})
+
+var _ = Describe("Schemas of key case conversions", func() {
+	It("should convert property names of response and request schemas", func() {
+		v1, _ := epoch.NewDateVersion("2024-01-01")
+		v2, _ := epoch.NewDateVersion("2024-06-01")
+		vb, _ := epoch.NewVersionBundle([]*epoch.Version{v1, v2})
+		v1.Changes = []epoch.VersionChangeInterface{
+			epoch.NewVersionChangeBuilder(v1, v2).
+				ForType(TestUser{}).
+				RequestToNextVersion().
+				ConvertKeysCase(epoch.SnakeToCamel, "metadata").
+				ResponseToPreviousVersion().
+				ConvertKeysCase(epoch.CamelToSnake, "metadata").
+				Build(),
+		}
+
+		lineSchema := openapi3.NewObjectSchema().WithProperty("productId", openapi3.NewIntegerSchema())
+		baseSchema := openapi3.NewObjectSchema().
+			WithProperty("userId", openapi3.NewIntegerSchema()).
+			WithProperty("homeAddress", openapi3.NewObjectSchema().WithProperty("postCode", openapi3.NewStringSchema())).
+			WithProperty("lines", openapi3.NewArraySchema().WithItems(lineSchema)).
+			WithProperty("metadata", openapi3.NewObjectSchema().WithProperty("sourceApp", openapi3.NewStringSchema()))
+		baseSchema.Required = []string{"userId"}
+		transformer := NewVersionTransformer(vb)
+
+		for _, direction := range []SchemaDirection{SchemaDirectionResponse, SchemaDirectionRequest} {
+			result, err := transformer.TransformSchemaForVersion(baseSchema, reflect.TypeOf(TestUser{}), v1, direction)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Properties).To(HaveKey("user_id"))
+			Expect(result.Required).To(Equal([]string{"user_id"}))
+			Expect(result.Properties["home_address"].Value.Properties).To(HaveKey("post_code"))
+			Expect(result.Properties["lines"].Value.Items.Value.Properties).To(HaveKey("product_id"))
+			Expect(result.Properties["metadata"].Value.Properties).To(HaveKey("sourceApp"))
+		}
+
+		// The HEAD schema is unchanged
+		Expect(baseSchema.Properties).To(HaveKey("userId"))
+		Expect(lineSchema.Properties).To(HaveKey("productId"))
+	})
 })
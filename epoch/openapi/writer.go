@@ -12,6 +12,9 @@ import (
 // Writer handles writing OpenAPI specs to files
 type Writer struct {
 	format string // "yaml" or "json"
+
+	// propertyOrder lists the properties of components written in struct order, by component name
+	propertyOrder map[string][]string
 }
 
 // NewWriter creates a new spec writer
@@ -26,6 +29,24 @@ func NewWriter(format string) *Writer {
 
 // WriteSpec writes an OpenAPI spec to a file
 func (w *Writer) WriteSpec(spec *openapi3.T, filepath string) error {
+	data, err := w.marshalSpec(spec)
+	if err != nil {
+		return err
+	}
+	if err := validateSpecData(data); err != nil {
+		return err
+	}
+
+	// Write to file
+	if err := os.WriteFile(filepath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
+
+// marshalSpec encodes a spec in the writer's format
+func (w *Writer) marshalSpec(spec *openapi3.T) ([]byte, error) {
 	var data []byte
 	var err error
 
@@ -37,11 +58,17 @@ func (w *Writer) WriteSpec(spec *openapi3.T, filepath string) error {
 	}
 
 	if err != nil {
-		return fmt.Errorf("failed to marshal spec: %w", err)
+		return nil, fmt.Errorf("failed to marshal spec: %w", err)
+	}
+	if len(w.propertyOrder) > 0 {
+		return w.orderProperties(data)
 	}
+	return data, nil
+}
 
-	// Validate by loading the marshalled data
-	// This ensures we validate the actual output, not in-memory state
+// validateSpecData validates a marshalled spec by loading it, so the actual output is
+// validated rather than in-memory state
+func validateSpecData(data []byte) error {
 	loader := openapi3.NewLoader()
 	loader.IsExternalRefsAllowed = true
 
@@ -54,12 +81,6 @@ func (w *Writer) WriteSpec(spec *openapi3.T, filepath string) error {
 	if err := validationSpec.Validate(context.Background(), openapi3.DisableExamplesValidation()); err != nil {
 		return fmt.Errorf("spec validation failed: %w", err)
 	}
-
-	// Write to file
-	if err := os.WriteFile(filepath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
-	}
-
 	return nil
 }
 
@@ -74,10 +95,16 @@ func (w *Writer) ValidateSpec(spec *openapi3.T) error {
 // WriteVersionedSpecs writes multiple versioned specs to files
 // filenamePattern should contain a %s placeholder for the version string
 // Example: "docs/api_v1alpha1_%s.yaml"
+// Every spec is validated first (see ValidateVersionedSpecs); if any fails, nothing is written
+// and the returned *SpecValidationError reports the problems of each failing version.
 func (w *Writer) WriteVersionedSpecs(specs map[string]*openapi3.T, filenamePattern string) error {
-	for version, spec := range specs {
+	marshalled, err := w.checkVersionedSpecs(specs)
+	if err != nil {
+		return err
+	}
+	for version, data := range marshalled {
 		filepath := fmt.Sprintf(filenamePattern, version)
-		if err := w.WriteSpec(spec, filepath); err != nil {
+		if err := os.WriteFile(filepath, data, 0644); err != nil {
 			return fmt.Errorf("failed to write spec for version %s: %w", version, err)
 		}
 	}
@@ -2,6 +2,7 @@ package openapi
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
 
@@ -251,4 +252,75 @@ var _ = Describe("Writer", func() {
 			Expect(info["title"]).To(Equal("Test API v1"))
 		})
 	})
+	Describe("Validate Versioned Specs", func() {
+		validSpec := func() *openapi3.T {
+			return &openapi3.T{
+				OpenAPI: "3.0.0",
+				Info:    &openapi3.Info{Title: "Test API", Version: "1.0.0"},
+				Paths:   openapi3.NewPaths(),
+				Components: &openapi3.Components{
+					Schemas: openapi3.Schemas{
+						"User": openapi3.NewSchemaRef("", &openapi3.Schema{
+							Type: &openapi3.Types{"object"},
+							Properties: openapi3.Schemas{
+								"id": openapi3.NewSchemaRef("", &openapi3.Schema{Type: &openapi3.Types{"integer"}}),
+							},
+						}),
+					},
+				},
+			}
+		}
+
+		It("should report dangling refs and empty schemas per version without writing", func() {
+			dangling := validSpec()
+			dangling.Components.Schemas["User"].Value.Properties["org"] = openapi3.NewSchemaRef("#/components/schemas/Org", nil)
+			dangling.Paths.Set("/users", &openapi3.PathItem{
+				Get: &openapi3.Operation{
+					Responses: openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{
+						Value: openapi3.NewResponse().WithDescription("OK").
+							WithJSONSchemaRef(openapi3.NewSchemaRef("#/components/schemas/Users", nil)),
+					})),
+				},
+			})
+			empty := validSpec()
+			empty.Components.Schemas["Settings"] = openapi3.NewSchemaRef("", &openapi3.Schema{})
+
+			tmpDir := GinkgoT().TempDir()
+			writer := NewWriter("yaml")
+			err := writer.WriteVersionedSpecs(map[string]*openapi3.T{
+				"v1":   dangling,
+				"v2":   empty,
+				"head": validSpec(),
+			}, filepath.Join(tmpDir, "api_%s.yaml"))
+
+			var report *SpecValidationError
+			Expect(errors.As(err, &report)).To(BeTrue())
+			Expect(report.Problems).To(HaveLen(2))
+			Expect(report.Problems["v1"]).To(Equal([]string{
+				"components.schemas.User.properties.org: $ref #/components/schemas/Org does not exist",
+				"paths./users.get.responses.200.content.application/json.schema: $ref #/components/schemas/Users does not exist",
+			}))
+			Expect(report.Problems["v2"]).To(Equal([]string{"components.schemas.Settings: schema is empty"}))
+			Expect(err.Error()).To(HavePrefix("spec validation failed for 2 version(s):\n  v1:\n    - components.schemas.User"))
+
+			entries, readErr := os.ReadDir(tmpDir)
+			Expect(readErr).NotTo(HaveOccurred())
+			Expect(entries).To(BeEmpty())
+		})
+
+		It("should report kin-openapi validation errors", func() {
+			invalid := validSpec()
+			invalid.Info = nil
+
+			err := NewWriter("json").ValidateVersionedSpecs(map[string]*openapi3.T{"v1": invalid, "head": validSpec()})
+			var report *SpecValidationError
+			Expect(errors.As(err, &report)).To(BeTrue())
+			Expect(report.Problems).To(HaveKey("v1"))
+			Expect(report.Problems).NotTo(HaveKey("head"))
+		})
+
+		It("should accept valid specs", func() {
+			Expect(NewWriter("yaml").ValidateVersionedSpecs(map[string]*openapi3.T{"head": validSpec()})).To(Succeed())
+		})
+	})
 })
@@ -0,0 +1,265 @@
+package epoch
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/astronomer/epoch/epoch/jsonast"
+	"github.com/gin-gonic/gin"
+)
+
+// ============================================================================
+// Plugin Operations - reusable, domain-specific operations
+// ============================================================================
+
+// Operation is implemented by custom, reusable operation types (e.g. "normalize phone number")
+// Register one with CustomOperation() on the request or response builder. Operations take part
+// in changelog generation and OpenAPI generation through Describe(), like built-in operations.
+type Operation interface {
+	// Apply transforms the body node. direction is DirectionRequest for Client→HEAD
+	// migrations and DirectionResponse for HEAD→Client migrations.
+	Apply(node *jsonast.Node, direction TransformDirection, ctx *OperationContext) error
+	// Describe documents the operation and its effect on the schema
+	Describe() OperationDoc
+}
+
+// OperationContext gives operations access to the request being migrated
+// Fields are nil when the operation runs outside the middleware (e.g. in unit tests)
+type OperationContext struct {
+	GinContext *gin.Context
+	Request    *RequestInfo  // Set when migrating a request
+	Response   *ResponseInfo // Set when migrating a response
+}
+
+// OperationDoc describes an operation for changelogs and OpenAPI schema generation
+// Schema effects are expressed in the direction the operation runs.
+type OperationDoc struct {
+	Name          string                 // Short identifier, e.g. "normalize_phone"
+	Description   string                 // Human-readable summary for changelogs
+	AddedFields   map[string]interface{} // Field name → default/example value (used to infer the schema type)
+	RemovedFields []string               // Fields removed by the operation
+	RenamedFields map[string]string      // Field name before → field name after the operation
+}
+
+// Inverse returns the doc describing the opposite schema effect
+// Used to document request operations, which run Client→HEAD, on HEAD→Client schemas
+func (d OperationDoc) Inverse() OperationDoc {
+	inverse := OperationDoc{Name: d.Name, Description: d.Description}
+	for _, name := range d.RemovedFields {
+		if inverse.AddedFields == nil {
+			inverse.AddedFields = make(map[string]interface{})
+		}
+		inverse.AddedFields[name] = nil
+	}
+	for name := range d.AddedFields {
+		inverse.RemovedFields = append(inverse.RemovedFields, name)
+	}
+	sort.Strings(inverse.RemovedFields)
+	for from, to := range d.RenamedFields {
+		if inverse.RenamedFields == nil {
+			inverse.RenamedFields = make(map[string]string)
+		}
+		inverse.RenamedFields[to] = from
+	}
+	return inverse
+}
+
+// fieldMapping returns error field mappings (newer name → older name) for the given direction
+func (d OperationDoc) fieldMapping(direction TransformDirection) map[string]string {
+	if len(d.RenamedFields) == 0 {
+		return nil
+	}
+	mapping := make(map[string]string, len(d.RenamedFields))
+	for from, to := range d.RenamedFields {
+		if direction == DirectionRequest {
+			// Requests rename older → newer
+			mapping[to] = from
+		} else {
+			// Responses rename newer → older
+			mapping[from] = to
+		}
+	}
+	return mapping
+}
+
+// RequestOperation adapts an Operation to the request flow (Client→HEAD)
+type RequestOperation struct {
+	Op Operation
+}
+
+func (op *RequestOperation) ApplyToRequest(node *jsonast.Node) error {
+	if node == nil {
+		return nil
+	}
+	return op.Op.Apply(node, DirectionRequest, &OperationContext{})
+}
+
+// ApplyToRequestInfo runs the operation with the live request context
+func (op *RequestOperation) ApplyToRequestInfo(req *RequestInfo) error {
+	if req == nil || req.Body == nil {
+		return nil
+	}
+	return op.Op.Apply(req.Body, DirectionRequest, &OperationContext{GinContext: req.GinContext, Request: req})
+}
+
+func (op *RequestOperation) GetFieldMapping() map[string]string {
+	return op.Op.Describe().fieldMapping(DirectionRequest)
+}
+
+// Inverse returns nil; schema generation uses Describe().Inverse() instead
+func (op *RequestOperation) Inverse() RequestToNextVersionOperation {
+	return nil
+}
+
+// Describe returns the wrapped operation's documentation
+func (op *RequestOperation) Describe() OperationDoc {
+	return op.Op.Describe()
+}
+
+// ResponseOperation adapts an Operation to the response flow (HEAD→Client)
+type ResponseOperation struct {
+	Op Operation
+}
+
+func (op *ResponseOperation) ApplyToResponse(node *jsonast.Node) error {
+	if node == nil {
+		return nil
+	}
+	return op.Op.Apply(node, DirectionResponse, &OperationContext{})
+}
+
+// ApplyToResponseInfo runs the operation with the live response context
+func (op *ResponseOperation) ApplyToResponseInfo(resp *ResponseInfo) error {
+	if resp == nil || resp.Body == nil {
+		return nil
+	}
+	return op.Op.Apply(resp.Body, DirectionResponse, &OperationContext{GinContext: resp.GinContext, Response: resp})
+}
+
+func (op *ResponseOperation) GetFieldMapping() map[string]string {
+	return op.Op.Describe().fieldMapping(DirectionResponse)
+}
+
+// Describe returns the wrapped operation's documentation
+func (op *ResponseOperation) Describe() OperationDoc {
+	return op.Op.Describe()
+}
+
+// DescribeOperation documents any request or response operation, built-in or plugin
+func DescribeOperation(op interface{}) OperationDoc {
+	switch operation := op.(type) {
+	case interface{ Describe() OperationDoc }:
+		return operation.Describe()
+	case *RequestAddField:
+		if operation.Validation == FailValidation {
+			return OperationDoc{Name: "require_field", Description: "Require field " + operation.Name}
+		}
+		return OperationDoc{Name: "add_field", Description: "Add field " + operation.Name,
+			AddedFields: map[string]interface{}{operation.Name: operation.Default}}
+	case *RequestAddFieldWithDefault:
+		return OperationDoc{Name: "add_field_with_default", Description: "Default missing field " + operation.Name,
+			AddedFields: map[string]interface{}{operation.Name: operation.Default}}
+	case *RequestAddFieldFromContext:
+		return OperationDoc{Name: "add_field_from_context", Description: "Add field " + operation.Name + " from context key " + operation.Key,
+			AddedFields: map[string]interface{}{operation.Name: nil}}
+	case *RequestRemoveField:
+		return OperationDoc{Name: "remove_field", Description: "Remove field " + operation.Name,
+			RemovedFields: []string{operation.Name}}
+	case *RequestRenameField:
+		return OperationDoc{Name: "rename_field", Description: "Rename field " + operation.OlderVersionName + " to " + operation.NewerVersionName,
+			RenamedFields: map[string]string{operation.OlderVersionName: operation.NewerVersionName}}
+	case *ResponseAddField:
+		return OperationDoc{Name: "add_field", Description: "Add field " + operation.Name,
+			AddedFields: map[string]interface{}{operation.Name: operation.Default}}
+	case *ResponseRemoveField:
+		return OperationDoc{Name: "remove_field", Description: "Remove field " + operation.Name,
+			RemovedFields: []string{operation.Name}}
+	case *ResponseRemoveFieldIfDefault:
+		return OperationDoc{Name: "remove_field_if_default", Description: "Remove field " + operation.Name + " when it has its default value",
+			RemovedFields: []string{operation.Name}}
+	case *ResponseRenameField:
+		return OperationDoc{Name: "rename_field", Description: "Rename field " + operation.NewerVersionName + " to " + operation.OlderVersionName,
+			RenamedFields: map[string]string{operation.NewerVersionName: operation.OlderVersionName}}
+	case *ResponseDualWriteField:
+		return OperationDoc{Name: "dual_write_field", Description: "Write field " + operation.NewerVersionName + " as both " + operation.OlderVersionName + " and " + operation.NewerVersionName,
+			AddedFields: map[string]interface{}{operation.OlderVersionName: nil}}
+	case *RequestRenameFieldMatching:
+		return OperationDoc{Name: "rename_field_matching", Description: "Rename fields matching " + operation.Pattern.String() + " to " + operation.Replacement}
+	case *ResponseRenameFieldMatching:
+		return OperationDoc{Name: "rename_field_matching", Description: "Rename fields matching " + operation.Pattern.String() + " to " + operation.Replacement}
+	case *RequestConvertKeysCase:
+		return OperationDoc{Name: "convert_keys_case", Description: "Convert keys from " + operation.Case.String()}
+	case *ResponseConvertKeysCase:
+		return OperationDoc{Name: "convert_keys_case", Description: "Convert keys from " + operation.Case.String()}
+	case *RequestMoveField:
+		return OperationDoc{Name: "move_field", Description: "Move field " + operation.OlderVersionPath + " to " + operation.NewerVersionPath}
+	case *ResponseMoveField:
+		return OperationDoc{Name: "move_field", Description: "Move field " + operation.NewerVersionPath + " to " + operation.OlderVersionPath}
+	case *ResponseAddHeader:
+		return OperationDoc{Name: "add_header", Description: "Add header " + operation.Name}
+	case *ResponseAddHeaderFromField:
+		return OperationDoc{Name: "add_header", Description: "Add header " + operation.Name + " from field " + operation.Field}
+	case *ResponseRemoveHeader:
+		return OperationDoc{Name: "remove_header", Description: "Remove header " + operation.Name}
+	case *ResponseRenameHeader:
+		return OperationDoc{Name: "rename_header", Description: "Rename header " + operation.NewerVersionName + " to " + operation.OlderVersionName}
+	case *ResponseRenameLinkRel:
+		return OperationDoc{Name: "rename_link_rel", Description: "Rename link relation " + operation.NewerVersionRel + " to " + operation.OlderVersionRel}
+	case *ResponseRewriteLinkHref:
+		return OperationDoc{Name: "rewrite_link_href", Description: "Rewrite href of link relation " + operation.Rel}
+	case *RequestObjectToArray:
+		return OperationDoc{Name: "object_to_array", Description: "Convert field " + operation.Name + " from an object to an array keyed by " + operation.KeyField}
+	case *RequestArrayToObject:
+		return OperationDoc{Name: "array_to_object", Description: "Convert field " + operation.Name + " from an array to an object keyed by " + operation.KeyField}
+	case *ResponseObjectToArray:
+		return OperationDoc{Name: "object_to_array", Description: "Convert field " + operation.Name + " from an object to an array keyed by " + operation.KeyField}
+	case *ResponseArrayToObject:
+		return OperationDoc{Name: "array_to_object", Description: "Convert field " + operation.Name + " from an array to an object keyed by " + operation.KeyField}
+	case *RequestCollapseReference:
+		return OperationDoc{Name: "collapse_reference", Description: "Replace field " + operation.Name + " with " + operation.HeadFieldName()}
+	case *ResponseExpandReference:
+		return OperationDoc{Name: "expand_reference", Description: "Replace field " + operation.IDField + " with the referenced " + operation.Name}
+	case *RequestCustom, *ResponseCustom:
+		return OperationDoc{Name: "custom", Description: "Custom transformation"}
+	}
+	return OperationDoc{Name: "unknown"}
+}
+
+// ChangelogEntry documents one operation of a version change
+type ChangelogEntry struct {
+	Type      reflect.Type
+	Direction TransformDirection
+	Doc       OperationDoc
+}
+
+// ChangelogEntries documents every operation in the change, ordered by type name,
+// then direction (requests first), then declaration order. ForTypesMatching operations
+// are listed for the types bound so far (see BindTypes).
+func (vc *VersionChange) ChangelogEntries() []ChangelogEntry {
+	vc.typesMu.RLock()
+	defer vc.typesMu.RUnlock()
+
+	typeSet := make(map[reflect.Type]bool)
+	for t := range vc.requestOperationsByType {
+		typeSet[t] = true
+	}
+	for t := range vc.responseOperationsByType {
+		typeSet[t] = true
+	}
+	types := make([]reflect.Type, 0, len(typeSet))
+	for t := range typeSet {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].String() < types[j].String() })
+
+	var entries []ChangelogEntry
+	for _, t := range types {
+		for _, op := range vc.requestOperationsByType[t] {
+			entries = append(entries, ChangelogEntry{Type: t, Direction: DirectionRequest, Doc: DescribeOperation(op)})
+		}
+		for _, op := range vc.responseOperationsByType[t] {
+			entries = append(entries, ChangelogEntry{Type: t, Direction: DirectionResponse, Doc: DescribeOperation(op)})
+		}
+	}
+	return entries
+}
@@ -0,0 +1,149 @@
+package epoch
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"strings"
+
+	"github.com/astronomer/epoch/epoch/jsonast"
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// normalizePhone is a sample domain-specific plugin operation
+type normalizePhone struct {
+	Field string
+}
+
+func (op normalizePhone) Apply(node *jsonast.Node, direction TransformDirection, ctx *OperationContext) error {
+	phone, err := GetNodeFieldString(node, op.Field)
+	if err != nil {
+		return nil
+	}
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, phone)
+	if direction == DirectionResponse {
+		// Old clients expect the dashed format
+		if len(digits) == 10 {
+			digits = digits[:3] + "-" + digits[3:6] + "-" + digits[6:]
+		}
+	} else if ctx.Request != nil && ctx.Request.Headers.Get("X-Phone-Region") == "US" {
+		digits = "+1" + digits
+	}
+	return SetNodeField(node, op.Field, digits)
+}
+
+func (op normalizePhone) Describe() OperationDoc {
+	return OperationDoc{Name: "normalize_phone", Description: "Normalize " + op.Field + " formatting"}
+}
+
+// renameViaPlugin is a plugin operation with a documented schema effect
+type renameViaPlugin struct{}
+
+func (renameViaPlugin) Apply(node *jsonast.Node, _ TransformDirection, _ *OperationContext) error {
+	return RenameNodeField(node, "phone_number", "phone")
+}
+
+func (renameViaPlugin) Describe() OperationDoc {
+	return OperationDoc{Name: "rename_phone", RenamedFields: map[string]string{"phone_number": "phone"}}
+}
+
+type PluginContact struct {
+	ID    int    `json:"id"`
+	Phone string `json:"phone"`
+}
+
+var _ = Describe("Plugin operations", func() {
+	var v1, v2 *Version
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		v1, _ = NewDateVersion("2024-01-01")
+		v2, _ = NewDateVersion("2024-06-01")
+	})
+
+	It("should run in both directions with access to the request", func() {
+		change := NewVersionChangeBuilder(v1, v2).
+			ForType(PluginContact{}).
+			RequestToNextVersion().
+			CustomOperation(normalizePhone{Field: "phone"}).
+			ResponseToPreviousVersion().
+			CustomOperation(normalizePhone{Field: "phone"}).
+			Build()
+
+		epochInstance, err := setupBasicEpoch([]*Version{v1, v2}, []*VersionChange{change})
+		Expect(err).NotTo(HaveOccurred())
+
+		router := setupRouterWithMiddleware(epochInstance)
+		router.POST("/contacts", epochInstance.WrapHandler(func(c *gin.Context) {
+			var contact PluginContact
+			Expect(c.ShouldBindJSON(&contact)).To(Succeed())
+			Expect(contact.Phone).To(Equal("+15551234567"))
+			c.JSON(200, PluginContact{ID: 1, Phone: "5551234567"})
+		}).Accepts(PluginContact{}).Returns(PluginContact{}).ToHandlerFunc("POST", "/contacts"))
+
+		req := httptest.NewRequest("POST", "/contacts", strings.NewReader(`{"phone":"(555) 123-4567"}`))
+		req.Header.Set("X-API-Version", "2024-01-01")
+		req.Header.Set("X-Phone-Region", "US")
+		req.Header.Set("Content-Type", "application/json")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		Expect(recorder.Code).To(Equal(200))
+		Expect(recorder.Body.String()).To(Equal(`{"id":1,"phone":"555-123-4567"}`))
+	})
+
+	It("should derive error field mappings from documented renames", func() {
+		requestOp := &RequestOperation{Op: renameViaPlugin{}}
+		responseOp := &ResponseOperation{Op: renameViaPlugin{}}
+
+		Expect(requestOp.GetFieldMapping()).To(Equal(map[string]string{"phone": "phone_number"}))
+		Expect(responseOp.GetFieldMapping()).To(Equal(map[string]string{"phone_number": "phone"}))
+	})
+
+	Describe("OperationDoc", func() {
+		It("should invert schema effects", func() {
+			doc := OperationDoc{
+				Name:          "reshape",
+				AddedFields:   map[string]interface{}{"email": "x"},
+				RemovedFields: []string{"fax"},
+				RenamedFields: map[string]string{"name": "full_name"},
+			}
+
+			inverse := doc.Inverse()
+			Expect(inverse.Name).To(Equal("reshape"))
+			Expect(inverse.AddedFields).To(HaveKeyWithValue("fax", BeNil()))
+			Expect(inverse.RemovedFields).To(Equal([]string{"email"}))
+			Expect(inverse.RenamedFields).To(Equal(map[string]string{"full_name": "name"}))
+		})
+	})
+
+	Describe("ChangelogEntries", func() {
+		It("should document built-in and plugin operations", func() {
+			change := NewVersionChangeBuilder(v1, v2).
+				ForType(PluginContact{}).
+				RequestToNextVersion().
+				CustomOperation(normalizePhone{Field: "phone"}).
+				ResponseToPreviousVersion().
+				RemoveField("id").
+				Build()
+
+			entries := change.ChangelogEntries()
+			Expect(entries).To(HaveLen(2))
+
+			Expect(entries[0].Type).To(Equal(reflect.TypeOf(PluginContact{})))
+			Expect(entries[0].Direction).To(Equal(DirectionRequest))
+			Expect(entries[0].Doc.Name).To(Equal("normalize_phone"))
+			Expect(entries[0].Doc.Description).To(Equal("Normalize phone formatting"))
+
+			Expect(entries[1].Direction).To(Equal(DirectionResponse))
+			Expect(entries[1].Doc.Name).To(Equal("remove_field"))
+			Expect(entries[1].Doc.RemovedFields).To(Equal([]string{"id"}))
+		})
+	})
+})
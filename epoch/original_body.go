@@ -0,0 +1,69 @@
+package epoch
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OriginalRequestBodyKey is the context key for the request body exactly as the client sent it
+const OriginalRequestBodyKey = "epoch_original_request_body"
+
+// originalRequestBody is the retained body and whether it was cut at the size limit
+type originalRequestBody struct {
+	body      []byte
+	truncated bool
+}
+
+// OriginalRequestBody returns the request body exactly as the client sent it, before any
+// migration, when the Epoch retains it (see WithOriginalRequestBody). complete is false when
+// the body was cut at the size limit or wasn't retained.
+func OriginalRequestBody(c *gin.Context) (body []byte, complete bool) {
+	if c == nil {
+		return nil, false
+	}
+	if val, exists := c.Get(OriginalRequestBodyKey); exists {
+		if original, ok := val.(*originalRequestBody); ok {
+			return original.body, !original.truncated
+		}
+	}
+	return nil, false
+}
+
+// WithOriginalRequestBody retains up to maxBytes of each request body as the client sent it,
+// for OriginalRequestBody. Larger bodies keep their first maxBytes and still reach the
+// handler whole.
+func (vah *VersionAwareHandler) WithOriginalRequestBody(maxBytes int) *VersionAwareHandler {
+	vah.originalBodyLimit = maxBytes
+	return vah
+}
+
+// retainOriginalBody stores the start of the request body in the context, leaving the
+// request body readable from the beginning
+func (vah *VersionAwareHandler) retainOriginalBody(c *gin.Context) {
+	if vah.originalBodyLimit <= 0 || c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return
+	}
+	prefix, err := io.ReadAll(io.LimitReader(c.Request.Body, int64(vah.originalBodyLimit)+1))
+	original := &originalRequestBody{body: prefix}
+	if len(prefix) > vah.originalBodyLimit {
+		original.body, original.truncated = prefix[:vah.originalBodyLimit], true
+	}
+	if err != nil {
+		original.truncated = true
+	}
+	c.Set(OriginalRequestBodyKey, original)
+
+	c.Request.Body = readCloser{
+		Reader: io.MultiReader(bytes.NewReader(prefix), c.Request.Body),
+		Closer: c.Request.Body,
+	}
+}
+
+// readCloser reads from one source and closes another
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,99 @@
+package epoch
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type auditUser struct {
+	FullName string `json:"full_name"`
+}
+
+var _ = Describe("Original request body", func() {
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+	})
+
+	type audited struct {
+		original string
+		complete bool
+		handled  string
+	}
+
+	serve := func(limit int, body string) audited {
+		v1, v2, _ := newTestVersions()
+		change := NewVersionChangeBuilder(v1, v2).
+			ForType(auditUser{}).
+			RequestToNextVersion().
+			RenameField("name", "full_name").
+			Build()
+		var options []func(*EpochBuilder) *EpochBuilder
+		if limit > 0 {
+			options = append(options, func(builder *EpochBuilder) *EpochBuilder {
+				return builder.WithOriginalRequestBody(limit)
+			})
+		}
+		epochInstance, err := setupHeadEpoch([]*Version{v1, v2}, []*VersionChange{change}, options...)
+		Expect(err).NotTo(HaveOccurred())
+
+		var result audited
+		router := setupRouterWithMiddleware(epochInstance)
+		// An audit middleware reads the original body after the handler ran
+		router.Use(func(c *gin.Context) {
+			c.Next()
+			original, complete := OriginalRequestBody(c)
+			result.original, result.complete = string(original), complete
+		})
+		router.POST("/users", epochInstance.WrapHandler(func(c *gin.Context) {
+			handled, err := io.ReadAll(c.Request.Body)
+			Expect(err).NotTo(HaveOccurred())
+			result.handled = string(handled)
+			c.Status(204)
+		}).Accepts(auditUser{}).ToHandlerFunc("POST", "/users"))
+
+		req := httptest.NewRequest("POST", "/users", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		serveVersioned(router, req, "2024-01-01")
+		return result
+	}
+
+	It("should keep the client's body while the handler gets the migrated one", func() {
+		result := serve(1024, `{"name":"Ada"}`)
+		Expect(result.original).To(Equal(`{"name":"Ada"}`))
+		Expect(result.complete).To(BeTrue())
+		Expect(result.handled).To(MatchJSON(`{"full_name":"Ada"}`))
+	})
+
+	It("should keep only the first bytes of bodies over the limit", func() {
+		result := serve(5, `{"name":"Ada"}`)
+		Expect(result.original).To(Equal(`{"nam`))
+		Expect(result.complete).To(BeFalse())
+		Expect(result.handled).To(MatchJSON(`{"full_name":"Ada"}`))
+	})
+
+	It("should retain nothing unless enabled", func() {
+		result := serve(0, `{"name":"Ada"}`)
+		Expect(result.original).To(BeEmpty())
+		Expect(result.complete).To(BeFalse())
+		Expect(result.handled).To(MatchJSON(`{"full_name":"Ada"}`))
+	})
+
+	It("should leave a body beyond the limit readable in full", func() {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("POST", "/", strings.NewReader("abcdefgh"))
+		vah := (&VersionAwareHandler{}).WithOriginalRequestBody(3)
+		vah.retainOriginalBody(c)
+
+		rest, err := io.ReadAll(c.Request.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(rest)).To(Equal("abcdefgh"))
+		original, complete := OriginalRequestBody(c)
+		Expect(string(original)).To(Equal("abc"))
+		Expect(complete).To(BeFalse())
+	})
+})
@@ -0,0 +1,207 @@
+package epoch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/astronomer/epoch/epoch/jsonast"
+)
+
+// OutputFormat controls how Epoch writes the migrated responses of a version
+// Use it for clients that depend on how an older framework formatted JSON.
+type OutputFormat struct {
+	Indent           string // Indentation per nesting level, e.g. "  "; empty writes compact JSON
+	StructFieldOrder bool   // Order fields as the registered response type declares them
+}
+
+// outputFormatEntry is an output format and the version it applies to
+type outputFormatEntry struct {
+	version *Version
+	format  OutputFormat
+}
+
+// WithOutputFormat formats the migrated responses of version. Responses that need no migration
+// for the version are written as the handler produced them.
+func (vah *VersionAwareHandler) WithOutputFormat(version *Version, format OutputFormat) *VersionAwareHandler {
+	if vah.outputFormats == nil {
+		vah.outputFormats = make(map[string]OutputFormat)
+	}
+	vah.outputFormats[version.String()] = format
+	return vah
+}
+
+// serializeResponse serializes a migrated response body in the version's output format
+func (vah *VersionAwareHandler) serializeResponse(node *jsonast.Node, version *Version, responseType reflect.Type) ([]byte, error) {
+	format, ok := vah.outputFormats[version.String()]
+	if !ok {
+		return vah.jsonEngine.Serialize(node)
+	}
+
+	if format.StructFieldOrder && responseType != nil {
+		renames := func(t reflect.Type) map[string]string {
+			return vah.migrationChain.renamedResponseFields(t, vah.versionBundle.GetHeadVersion(), version)
+		}
+		if err := orderFieldsByType(node, responseType, renames); err != nil {
+			return nil, fmt.Errorf("failed to order fields: %w", err)
+		}
+	}
+	raw, err := vah.jsonEngine.Serialize(node)
+	if err != nil || format.Indent == "" {
+		return raw, err
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, raw, "", format.Indent); err != nil {
+		return nil, fmt.Errorf("failed to indent response: %w", err)
+	}
+	return indented.Bytes(), nil
+}
+
+// jsonField is a field of a struct as it appears in JSON
+type jsonField struct {
+	name      string
+	goName    string
+	fieldType reflect.Type
+}
+
+// orderedJSONFields returns the JSON fields of a struct type in declaration order,
+// with the fields of embedded structs in place of the embedding field
+func orderedJSONFields(t reflect.Type) []jsonField {
+	var fields []jsonField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		name := strings.Split(tag, ",")[0]
+		if tag == "-" {
+			continue
+		}
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			fields = append(fields, orderedJSONFields(fieldType)...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, jsonField{name: name, goName: field.Name, fieldType: field.Type})
+	}
+	return fields
+}
+
+// orderFieldsByType reorders the fields of objects in node as t declares them, recursively
+// renames maps a type's declared names to the names they were renamed to, if any. Fields t
+// doesn't declare (e.g. added by migrations) keep their position, and declared fields are
+// reordered among the remaining positions.
+func orderFieldsByType(node *jsonast.Node, t reflect.Type, renames func(reflect.Type) map[string]string) error {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch node.TypeSafe() {
+	case jsonast.V_ARRAY:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return nil
+		}
+		if err := node.LoadAll(); err != nil {
+			return fmt.Errorf("failed to load array: %w", err)
+		}
+		length, err := node.Len()
+		if err != nil {
+			return fmt.Errorf("failed to get array length: %w", err)
+		}
+		for i := 0; i < length; i++ {
+			if err := orderFieldsByType(node.Index(i), t.Elem(), renames); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case jsonast.V_OBJECT:
+		if t.Kind() != reflect.Struct {
+			return nil
+		}
+		if err := node.LoadAll(); err != nil {
+			return fmt.Errorf("failed to load object: %w", err)
+		}
+		fields := orderedJSONFields(t)
+		var renamed map[string]string
+		if renames != nil {
+			renamed = renames(t)
+		}
+		position := make(map[string]int, len(fields))
+		for i, field := range fields {
+			name := field.name
+			if older, ok := renamed[name]; ok {
+				name = older
+			}
+			if _, exists := position[name]; !exists {
+				position[name] = i
+			}
+		}
+
+		iter, err := node.Properties()
+		if err != nil {
+			return err
+		}
+		var pairs, declared []jsonast.Pair
+		var slots []int
+		var pair jsonast.Pair
+		for iter.Next(&pair) {
+			if i, ok := position[pair.Key]; ok {
+				if err := orderFieldsByType(&pair.Value, fields[i].fieldType, renames); err != nil {
+					return err
+				}
+				slots = append(slots, len(pairs))
+				declared = append(declared, pair)
+			}
+			pairs = append(pairs, pair)
+		}
+
+		sort.SliceStable(declared, func(i, j int) bool {
+			return position[declared[i].Key] < position[declared[j].Key]
+		})
+		for i, slot := range slots {
+			pairs[slot] = declared[i]
+		}
+		*node = jsonast.NewObject(pairs)
+	}
+	return nil
+}
+
+// renamedResponseFields maps t's field names at from to their names at to, for fields that
+// response operations between the versions rename
+func (mc *MigrationChain) renamedResponseFields(t reflect.Type, from, to *Version) map[string]string {
+	path := mc.GetMigrationPath(from, to)
+	sort.SliceStable(path, func(i, j int) bool {
+		return path[i].FromVersion().IsNewerThan(path[j].FromVersion())
+	})
+
+	renamed := make(map[string]string)
+	for _, change := range path {
+		ops, _ := change.GetResponseOperationsByType(t)
+		for _, op := range ops {
+			for newer, older := range DescribeOperation(op).RenamedFields {
+				found := false
+				for name, current := range renamed {
+					if current == newer {
+						renamed[name], found = older, true
+					}
+				}
+				if !found {
+					renamed[newer] = older
+				}
+			}
+		}
+	}
+	return renamed
+}
@@ -0,0 +1,85 @@
+package epoch
+
+import (
+	"net/http/httptest"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type formattedAddress struct {
+	Street string `json:"street"`
+	City   string `json:"city"`
+}
+
+type formattedUser struct {
+	ID       int                `json:"id"`
+	FullName string             `json:"full_name"`
+	Email    string             `json:"email"`
+	Address  formattedAddress   `json:"address"`
+	Previous []formattedAddress `json:"previous"`
+}
+
+var _ = Describe("Output format", func() {
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+	})
+
+	It("should order declared and renamed fields and keep undeclared ones in place", func() {
+		node, err := getNode([]byte(`{"email":"a@b.c","nickname":"A","name":"Ada","id":1,` +
+			`"previous":[{"city":"Paris","street":"Rue"}],"address":{"city":"Oslo","street":"Gate"}}`))
+		Expect(err).NotTo(HaveOccurred())
+		renames := func(t reflect.Type) map[string]string {
+			if t == reflect.TypeOf(formattedUser{}) {
+				return map[string]string{"full_name": "name"}
+			}
+			return nil
+		}
+		Expect(orderFieldsByType(&node, reflect.TypeOf(formattedUser{}), renames)).To(Succeed())
+
+		raw, err := node.Raw()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(raw).To(Equal(`{"id":1,"nickname":"A","name":"Ada","email":"a@b.c",` +
+			`"address":{"street":"Gate","city":"Oslo"},"previous":[{"street":"Rue","city":"Paris"}]}`))
+	})
+
+	serve := func(version string) string {
+		v1, v2, v3 := newTestVersions()
+		changes := []*VersionChange{
+			NewVersionChangeBuilder(v1, v2).
+				ForType(formattedUser{}).
+				ResponseToPreviousVersion().
+				RenameField("full_name", "name").
+				Build(),
+			NewVersionChangeBuilder(v2, v3).
+				ForType(formattedUser{}).
+				ResponseToPreviousVersion().
+				RemoveField("previous").
+				Build(),
+		}
+		epochInstance, err := setupHeadEpoch([]*Version{v1, v2, v3}, changes, func(builder *EpochBuilder) *EpochBuilder {
+			return builder.WithOutputFormat(v1, OutputFormat{Indent: "  ", StructFieldOrder: true})
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		router := setupRouterWithMiddleware(epochInstance)
+		router.GET("/users/:id", epochInstance.WrapHandler(func(c *gin.Context) {
+			// Maps serialize alphabetically, not in the type's order
+			c.JSON(200, gin.H{"id": 1, "full_name": "Ada", "email": "a@b.c", "previous": []string{}})
+		}).Returns(formattedUser{}).ToHandlerFunc("GET", "/users/:id"))
+
+		recorder := serveVersioned(router, httptest.NewRequest("GET", "/users/1", nil), version)
+		Expect(recorder.Code).To(Equal(200))
+		return recorder.Body.String()
+	}
+
+	It("should format migrated responses of the configured version", func() {
+		Expect(serve("2024-01-01")).To(Equal("{\n  \"id\": 1,\n  \"name\": \"Ada\",\n  \"email\": \"a@b.c\"\n}"))
+	})
+
+	It("should leave other versions compact and in the handler's order", func() {
+		Expect(serve("2024-06-01")).To(Equal(`{"email":"a@b.c","full_name":"Ada","id":1}`))
+	})
+})
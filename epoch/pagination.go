@@ -0,0 +1,198 @@
+package epoch
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/astronomer/epoch/epoch/jsonast"
+)
+
+// PaginationConfig describes how an older page-based pagination scheme maps onto
+// HEAD's cursor-based one. Zero values fall back to the defaults in parentheses.
+type PaginationConfig struct {
+	PageParam    string // Older 1-based page number parameter ("page")
+	PerPageParam string // Older page size parameter ("per_page")
+	CursorParam  string // HEAD cursor parameter ("cursor")
+	LimitParam   string // HEAD page size parameter ("limit")
+
+	// DefaultPerPage is the page size older versions used when the client sent none.
+	// When set, it's forwarded to HEAD as the limit so older clients keep their page size.
+	DefaultPerPage int
+
+	// PageToCursor returns the HEAD cursor pointing at the start of a page, or "" for the
+	// first page. The default encodes the item offset ((page-1)*perPage) as a decimal string.
+	PageToCursor func(page, perPage int) (string, error)
+
+	// TotalField is the dotted path of the total item count in HEAD responses (e.g. "meta.total")
+	// Without it, or when a response doesn't include it, total pages aren't synthesized.
+	TotalField string
+
+	PageField       string // Older response field for the page number ("page")
+	PerPageField    string // Older response field for the page size ("per_page")
+	TotalPagesField string // Older response field for the page count ("total_pages")
+}
+
+// WithDefaults returns the config with default parameter names, field names and PageToCursor filled in
+func (cfg PaginationConfig) WithDefaults() PaginationConfig {
+	defaults := []struct {
+		value    *string
+		fallback string
+	}{
+		{&cfg.PageParam, "page"},
+		{&cfg.PerPageParam, "per_page"},
+		{&cfg.CursorParam, "cursor"},
+		{&cfg.LimitParam, "limit"},
+		{&cfg.PageField, "page"},
+		{&cfg.PerPageField, "per_page"},
+		{&cfg.TotalPagesField, "total_pages"},
+	}
+	for _, d := range defaults {
+		if *d.value == "" {
+			*d.value = d.fallback
+		}
+	}
+	if cfg.PageToCursor == nil {
+		cfg.PageToCursor = offsetCursor
+	}
+	return cfg
+}
+
+// offsetCursor is the default PageToCursor: the item offset as a decimal string
+func offsetCursor(page, perPage int) (string, error) {
+	if page <= 1 {
+		return "", nil
+	}
+	return strconv.Itoa((page - 1) * perPage), nil
+}
+
+// pageParams reads the page number and page size from older query parameters
+// perPage is 0 when neither the client nor DefaultPerPage provides it.
+func (cfg PaginationConfig) pageParams(get func(string) (string, bool)) (page, perPage int, err error) {
+	page, perPage = 1, cfg.DefaultPerPage
+	if value, ok := get(cfg.PageParam); ok {
+		if page, err = strconv.Atoi(value); err != nil || page < 1 {
+			return 0, 0, fmt.Errorf("invalid %s parameter %q", cfg.PageParam, value)
+		}
+	}
+	if value, ok := get(cfg.PerPageParam); ok {
+		if perPage, err = strconv.Atoi(value); err != nil || perPage < 1 {
+			return 0, 0, fmt.Errorf("invalid %s parameter %q", cfg.PerPageParam, value)
+		}
+	}
+	return page, perPage, nil
+}
+
+// RequestPagination translates page/per_page query parameters into cursor/limit
+// when request migrates from client to HEAD
+type RequestPagination struct {
+	Config PaginationConfig
+}
+
+// ApplyToQuery replaces the page parameters with HEAD's cursor parameters
+func (op *RequestPagination) ApplyToQuery(query url.Values) error {
+	cfg := op.Config.WithDefaults()
+	page, perPage, err := cfg.pageParams(func(name string) (string, bool) {
+		_, ok := query[name]
+		return query.Get(name), ok
+	})
+	if err != nil {
+		return err
+	}
+	query.Del(cfg.PageParam)
+	query.Del(cfg.PerPageParam)
+
+	if perPage > 0 {
+		query.Set(cfg.LimitParam, strconv.Itoa(perPage))
+	}
+	if page > 1 {
+		if perPage == 0 {
+			return fmt.Errorf("%s is required to translate %s %d to a cursor", cfg.PerPageParam, cfg.PageParam, page)
+		}
+		cursor, err := cfg.PageToCursor(page, perPage)
+		if err != nil {
+			return fmt.Errorf("failed to build cursor for page %d: %w", page, err)
+		}
+		if cursor != "" {
+			query.Set(cfg.CursorParam, cursor)
+		}
+	}
+	return nil
+}
+
+// ResponsePagination adds older page-based metadata to HEAD's cursor-based list responses
+// when response migrates from HEAD to client. The page and page size come from the
+// client's original query parameters.
+type ResponsePagination struct {
+	Config PaginationConfig
+}
+
+// ApplyToEnvelope adds the metadata for the first page; used when no request is available
+func (op *ResponsePagination) ApplyToEnvelope(body *jsonast.Node) (*jsonast.Node, error) {
+	cfg := op.Config.WithDefaults()
+	return body, cfg.addMetadata(body, 1, cfg.DefaultPerPage)
+}
+
+// ApplyToResponseInfo adds the metadata for the page the client requested
+func (op *ResponsePagination) ApplyToResponseInfo(resp *ResponseInfo) error {
+	if resp == nil || resp.Body == nil {
+		return nil
+	}
+	cfg := op.Config.WithDefaults()
+
+	var query map[string]string
+	if resp.Request != nil {
+		query = resp.Request.QueryParams
+	}
+	page, perPage, err := cfg.pageParams(func(name string) (string, bool) {
+		value, ok := query[name]
+		return value, ok
+	})
+	if err != nil {
+		// The request migration already rejected these, so there's nothing to describe
+		return nil
+	}
+	return cfg.addMetadata(resp.Body, page, perPage)
+}
+
+// addMetadata sets the page, page size and, when the total is known, the page count
+func (cfg PaginationConfig) addMetadata(body *jsonast.Node, page, perPage int) error {
+	if !IsNodeObject(body) {
+		return nil
+	}
+	if err := SetNodeField(body, cfg.PageField, page); err != nil {
+		return fmt.Errorf("failed to set field %s: %w", cfg.PageField, err)
+	}
+	if perPage == 0 {
+		return nil
+	}
+	if err := SetNodeField(body, cfg.PerPageField, perPage); err != nil {
+		return fmt.Errorf("failed to set field %s: %w", cfg.PerPageField, err)
+	}
+
+	if cfg.TotalField == "" {
+		return nil
+	}
+	totalNode := GetNodeAtPath(body, cfg.TotalField)
+	if totalNode == nil || totalNode.TypeSafe() != jsonast.V_NUMBER {
+		return nil
+	}
+	total, err := totalNode.Int64()
+	if err != nil {
+		return nil
+	}
+	totalPages := (total + int64(perPage) - 1) / int64(perPage)
+	if err := SetNodeField(body, cfg.TotalPagesField, totalPages); err != nil {
+		return fmt.Errorf("failed to set field %s: %w", cfg.TotalPagesField, err)
+	}
+	return nil
+}
+
+// TranslatePagination maps the endpoint's older page/per_page pagination onto HEAD's
+// cursor/limit pagination: query parameters are translated on requests, and page, per_page
+// and (when the total is known) total_pages are added to successful responses
+func (eb *endpointBuilder) TranslatePagination(config PaginationConfig) *endpointBuilder {
+	eb.parent.addQueryOperation(eb.method, eb.pathPattern, &RequestPagination{Config: config})
+	eb.parent.addEnvelopeOperation(eb.method, eb.pathPattern, &ResponsePagination{Config: config})
+	return eb
+}
@@ -0,0 +1,87 @@
+package epoch
+
+import (
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Pagination translation", func() {
+	var v1, v2 *Version
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		v1, v2, _ = newTestVersions()
+	})
+
+	// serve answers with HEAD's cursor envelope, echoing the cursor/limit it received
+	serve := func(target string, config PaginationConfig) (int, string) {
+		change := NewVersionChangeBuilder(v1, v2).
+			ForEndpoint("GET", "/users").
+			TranslatePagination(config).
+			Build()
+		epochInstance, err := setupHeadEpoch([]*Version{v1, v2}, []*VersionChange{change})
+		Expect(err).NotTo(HaveOccurred())
+
+		router := setupRouterWithMiddleware(epochInstance)
+		router.GET("/users", epochInstance.WrapHandler(func(c *gin.Context) {
+			limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+			c.JSON(200, gin.H{"data": []int{}, "cursor": c.Query("cursor"), "limit": limit, "meta": gin.H{"total": 45}})
+		}).ToHandlerFunc("GET", "/users"))
+
+		recorder := serveVersioned(router, httptest.NewRequest("GET", target, nil), "2024-01-01")
+		return recorder.Code, recorder.Body.String()
+	}
+
+	It("should translate pages to cursors and synthesize page metadata", func() {
+		code, body := serve("/users?page=3&per_page=10", PaginationConfig{TotalField: "meta.total"})
+		Expect(code).To(Equal(200))
+		Expect(body).To(Equal(`{"cursor":"20","data":[],"limit":10,"meta":{"total":45},"page":3,"per_page":10,"total_pages":5}`))
+	})
+
+	It("should forward the older default page size", func() {
+		_, body := serve("/users", PaginationConfig{DefaultPerPage: 25, TotalField: "meta.total"})
+		Expect(body).To(Equal(`{"cursor":"","data":[],"limit":25,"meta":{"total":45},"page":1,"per_page":25,"total_pages":2}`))
+	})
+
+	It("should only report the page when the page size is unknown", func() {
+		_, body := serve("/users", PaginationConfig{TotalField: "meta.total"})
+		Expect(body).To(Equal(`{"cursor":"","data":[],"limit":50,"meta":{"total":45},"page":1}`))
+	})
+
+	It("should skip total pages without a total", func() {
+		_, body := serve("/users?per_page=10", PaginationConfig{})
+		Expect(body).To(Equal(`{"cursor":"","data":[],"limit":10,"meta":{"total":45},"page":1,"per_page":10}`))
+	})
+
+	It("should use custom parameter names and cursors", func() {
+		config := PaginationConfig{
+			PageParam:   "p",
+			CursorParam: "after",
+			PageToCursor: func(page, perPage int) (string, error) {
+				return "opaque-" + strconv.Itoa(page), nil
+			},
+		}
+		query := url.Values{"p": {"2"}, "per_page": {"5"}}
+		Expect((&RequestPagination{Config: config}).ApplyToQuery(query)).To(Succeed())
+		Expect(query).To(Equal(url.Values{"after": {"opaque-2"}, "limit": {"5"}}))
+	})
+
+	It("should reject page parameters it can't translate", func() {
+		code, body := serve("/users?page=zero", PaginationConfig{})
+		Expect(code).To(Equal(500))
+		Expect(body).To(ContainSubstring(`invalid page parameter \"zero\"`))
+
+		Expect((&RequestPagination{}).ApplyToQuery(url.Values{"page": {"2"}})).
+			To(MatchError(ContainSubstring("per_page is required")))
+
+		failing := PaginationConfig{PageToCursor: func(int, int) (string, error) { return "", errors.New("boom") }}
+		Expect((&RequestPagination{Config: failing}).ApplyToQuery(url.Values{"page": {"2"}, "per_page": {"5"}})).
+			To(MatchError(ContainSubstring("boom")))
+	})
+})
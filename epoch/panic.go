@@ -0,0 +1,110 @@
+package epoch
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
+// MigrationPanicError reports a transformer or operation that panicked during migration.
+// Epoch recovers the panic so it surfaces like any other migration error instead of
+// reaching Gin's recovery middleware.
+type MigrationPanicError struct {
+	Change    string             // Description of the version change
+	From      *Version           // Version the change migrates from
+	To        *Version           // Version the change migrates to
+	Direction TransformDirection // DirectionRequest or DirectionResponse
+	Operation int                // Index of the operation in its list; -1 for custom and global transformers
+	Value     interface{}        // Value passed to panic
+	Stack     []byte             // Stack trace of the panicking goroutine
+}
+
+func (e *MigrationPanicError) Error() string {
+	step := fmt.Sprintf("%s->%s", e.From, e.To)
+	if e.Direction == DirectionResponse {
+		step = fmt.Sprintf("%s->%s", e.To, e.From)
+	}
+	where := "transformer"
+	if e.Operation >= 0 {
+		where = fmt.Sprintf("operation %d", e.Operation)
+	}
+	return fmt.Sprintf("panic in %s of change '%s' (%s): %v", where, e.Change, step, e.Value)
+}
+
+// Unwrap returns the panic value when it's an error
+func (e *MigrationPanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// Is matches ErrMigrationFailed
+func (e *MigrationPanicError) Is(target error) bool {
+	return target == ErrMigrationFailed
+}
+
+// WithPanicFallback answers with the unmigrated body when a transformer panics: the
+// handler gets the request as the client sent it, the client gets HEAD's response.
+// Without it, panics answer 500 with the MigrationPanicError details.
+func (vah *VersionAwareHandler) WithPanicFallback() *VersionAwareHandler {
+	vah.panicFallback = true
+	return vah
+}
+
+// fallBackOnPanic reports whether a failed migration should continue with the unmigrated body
+func (vah *VersionAwareHandler) fallBackOnPanic(err error) bool {
+	var panicErr *MigrationPanicError
+	return vah.panicFallback && errors.As(err, &panicErr)
+}
+
+// recoverOperation runs apply, converting a panic into a MigrationPanicError and an error
+// into a MigrationError for the operation at index
+func recoverOperation(index int, apply func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &MigrationPanicError{Operation: index, Value: r, Stack: debug.Stack()}
+		}
+	}()
+	if err := apply(); err != nil {
+		return &MigrationError{Operation: index, Err: err}
+	}
+	return nil
+}
+
+// recoverChange runs apply, converting a panic into a MigrationPanicError and attributing
+// panics and errors recovered from its operations to the change. Errors wrapping an
+// unattributed panic were formatted without the change, so the attributed
+// MigrationPanicError replaces them; other errors are wrapped in a MigrationError unless
+// one of its operations already reported one.
+func (vc *VersionChange) recoverChange(direction TransformDirection, apply func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &MigrationPanicError{Operation: -1, Value: r, Stack: debug.Stack()}
+		}
+		if err == nil {
+			return
+		}
+		var panicErr *MigrationPanicError
+		if errors.As(err, &panicErr) {
+			if panicErr.From == nil {
+				panicErr.Change = vc.description
+				panicErr.From = vc.fromVersion
+				panicErr.To = vc.toVersion
+				panicErr.Direction = direction
+				err = panicErr
+			}
+			return
+		}
+		var migrationErr *MigrationError
+		if !errors.As(err, &migrationErr) || migrationErr.From != nil {
+			migrationErr = &MigrationError{Operation: -1, Err: err}
+			err = migrationErr
+		}
+		migrationErr.Change = vc.description
+		migrationErr.From = vc.fromVersion
+		migrationErr.To = vc.toVersion
+		migrationErr.Direction = direction
+	}()
+	return apply()
+}
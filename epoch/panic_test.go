@@ -0,0 +1,132 @@
+package epoch
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+
+	"github.com/astronomer/epoch/epoch/jsonast"
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type panicItem struct {
+	ID       int    `json:"id"`
+	FullName string `json:"full_name"`
+}
+
+// panickingOperation panics when applied
+type panickingOperation struct{}
+
+func (panickingOperation) Apply(*jsonast.Node, TransformDirection, *OperationContext) error {
+	panic("boom")
+}
+
+func (panickingOperation) Describe() OperationDoc {
+	return OperationDoc{Name: "panic"}
+}
+
+var _ = Describe("Panic recovery", func() {
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+	})
+
+	panicChange := func(v1, v2 *Version) *VersionChange {
+		return NewVersionChangeBuilder(v1, v2).
+			Description("Rename name to full_name").
+			ForType(panicItem{}).
+			RequestToNextVersion().
+			RenameField("name", "full_name").
+			CustomOperation(panickingOperation{}).
+			ResponseToPreviousVersion().
+			RenameField("full_name", "name").
+			CustomOperation(panickingOperation{}).
+			Build()
+	}
+
+	serve := func(fallback bool, received *string) *httptest.ResponseRecorder {
+		v1, v2, _ := newTestVersions()
+		var options []func(*EpochBuilder) *EpochBuilder
+		if fallback {
+			options = append(options, (*EpochBuilder).WithPanicFallback)
+		}
+		epochInstance, err := setupHeadEpoch([]*Version{v1, v2}, []*VersionChange{panicChange(v1, v2)}, options...)
+		Expect(err).NotTo(HaveOccurred())
+
+		router := setupRouterWithMiddleware(epochInstance)
+		router.POST("/items", epochInstance.WrapHandler(func(c *gin.Context) {
+			body, _ := io.ReadAll(c.Request.Body)
+			*received = string(body)
+			c.JSON(200, panicItem{ID: 1, FullName: "Ada"})
+		}).Accepts(panicItem{}).Returns(panicItem{}).ToHandlerFunc("POST", "/items"))
+
+		req := httptest.NewRequest("POST", "/items", strings.NewReader(`{"id":1,"name":"Ada"}`))
+		return serveVersioned(router, req, "2024-01-01")
+	}
+
+	It("should turn a panic into a structured migration error", func() {
+		var received string
+		recorder := serve(false, &received)
+
+		Expect(recorder.Code).To(Equal(500))
+		Expect(recorder.Body.String()).To(ContainSubstring("Request migration failed"))
+		Expect(recorder.Body.String()).To(ContainSubstring("panic in operation 1 of change 'Rename name to full_name'"))
+		Expect(received).To(BeEmpty())
+	})
+
+	It("should fall back to the unmigrated bodies when configured", func() {
+		var received string
+		recorder := serve(true, &received)
+
+		Expect(recorder.Code).To(Equal(200))
+		Expect(received).To(Equal(`{"id":1,"name":"Ada"}`))
+		Expect(recorder.Body.String()).To(Equal(`{"id":1,"full_name":"Ada"}`))
+	})
+
+	It("should attribute panics to the change and operation", func() {
+		v1, v2, _ := newTestVersions()
+		chain, err := NewMigrationChain([]*VersionChange{panicChange(v1, v2)})
+		Expect(err).NotTo(HaveOccurred())
+
+		body, err := getNode([]byte(`{"id":1,"full_name":"Ada"}`))
+		Expect(err).NotTo(HaveOccurred())
+		info := &ResponseInfo{Body: &body, StatusCode: 200}
+
+		err = chain.MigrateResponseForType(context.Background(), info, reflect.TypeOf(panicItem{}), nil, v2, v1)
+		var panicErr *MigrationPanicError
+		Expect(errors.As(err, &panicErr)).To(BeTrue())
+		Expect(panicErr.Change).To(Equal("Rename name to full_name"))
+		Expect(panicErr.Direction).To(Equal(DirectionResponse))
+		Expect(panicErr.Operation).To(Equal(1))
+		Expect(panicErr.Value).To(Equal("boom"))
+		Expect(panicErr.Stack).NotTo(BeEmpty())
+		Expect(panicErr.Error()).To(ContainSubstring("(2024-06-01->2024-01-01)"))
+	})
+
+	It("should recover panics in custom transformers", func() {
+		v1, v2, _ := newTestVersions()
+		change := NewVersionChangeBuilder(v1, v2).
+			ForType(panicItem{}).
+			ResponseToPreviousVersion().
+			Custom(func(*ResponseInfo) error {
+				var m map[string]int
+				m["x"] = 1
+				return nil
+			}).
+			Build()
+
+		body, err := getNode([]byte(`{"id":1}`))
+		Expect(err).NotTo(HaveOccurred())
+		info := &ResponseInfo{Body: &body, StatusCode: 200, schemaMatched: true, matchedSchemaType: reflect.TypeOf(panicItem{})}
+
+		err = change.MigrateResponse(context.Background(), info)
+		var panicErr *MigrationPanicError
+		Expect(errors.As(err, &panicErr)).To(BeTrue())
+		var runtimeErr interface{ RuntimeError() }
+		Expect(errors.As(err, &runtimeErr)).To(BeTrue())
+	})
+})
@@ -0,0 +1,263 @@
+package epoch
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestMapPathParam maps a path parameter between the format older clients use and HEAD's,
+// e.g. the numeric IDs of older versions and HEAD's ULIDs. Mappings are declared per endpoint
+// with ForEndpoint().
+type RequestMapPathParam struct {
+	Param   string                       // Parameter name, e.g. ":id" or "id"
+	ToNewer func(string) (string, error) // Maps an older client's value to HEAD's
+	ToOlder func(string) (string, error) // Maps a HEAD value back, for URLs in responses; nil leaves them
+}
+
+// name returns the parameter name as gin.Params knows it
+func (op *RequestMapPathParam) name() string {
+	return strings.TrimPrefix(op.Param, ":")
+}
+
+// PathParamError reports a path parameter value an older client sent that has no HEAD equivalent
+type PathParamError struct {
+	Param string
+	Value string
+	Err   error
+}
+
+func (e *PathParamError) Error() string {
+	return fmt.Sprintf("invalid path parameter %s %q: %v", e.Param, e.Value, e.Err)
+}
+
+func (e *PathParamError) Unwrap() error {
+	return e.Err
+}
+
+// GetRequestPathParamOperations returns the path parameter mappings this change declares for an endpoint
+func (vc *VersionChange) GetRequestPathParamOperations(method, pathPattern string) []*RequestMapPathParam {
+	return vc.requestPathParamOps[endpointKey(method, pathPattern)]
+}
+
+// pathParamPath orders the changes between from and to that map the endpoint's path
+// parameters the way requests are migrated: oldest first
+func (mc *MigrationChain) pathParamPath(from, to *Version, method, pathPattern string) []*VersionChange {
+	var path []*VersionChange
+	for _, change := range mc.GetMigrationPath(from, to) {
+		if len(change.GetRequestPathParamOperations(method, pathPattern)) > 0 {
+			path = append(path, change)
+		}
+	}
+	sort.SliceStable(path, func(i, j int) bool {
+		return path[i].FromVersion().IsOlderThan(path[j].FromVersion())
+	})
+	return path
+}
+
+// HasPathParamOperations reports whether any change between two versions maps the endpoint's path parameters
+func (mc *MigrationChain) HasPathParamOperations(from, to *Version, method, pathPattern string) bool {
+	return len(mc.pathParamPath(from, to, method, pathPattern)) > 0
+}
+
+// MigrateRequestPathParams maps the endpoint's path parameters from an older version (from) up
+// to HEAD (to), rewriting params in place. A value a mapping rejects fails with a *PathParamError.
+func (mc *MigrationChain) MigrateRequestPathParams(
+	ctx context.Context,
+	c *gin.Context,
+	params gin.Params,
+	method, pathPattern string,
+	from, to *Version,
+) error {
+	for _, change := range mc.pathParamPath(from, to, method, pathPattern) {
+		if !change.isEnabled(ctx, c) {
+			continue
+		}
+		if err := checkMigrationContext(ctx); err != nil {
+			return err
+		}
+		ops := change.GetRequestPathParamOperations(method, pathPattern)
+		err := change.recoverChange(DirectionRequest, func() error {
+			for i, op := range ops {
+				if err := recoverOperation(i, func() error { return op.apply(params) }); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("path parameter migration failed at %s->%s: %w",
+				change.FromVersion().String(), change.ToVersion().String(), err)
+		}
+	}
+	return nil
+}
+
+// apply maps the parameter's value in params to HEAD's format
+func (op *RequestMapPathParam) apply(params gin.Params) error {
+	for i := range params {
+		if params[i].Key != op.name() {
+			continue
+		}
+		value, err := op.ToNewer(params[i].Value)
+		if err != nil {
+			return &PathParamError{Param: op.name(), Value: params[i].Value, Err: err}
+		}
+		params[i].Value = value
+	}
+	return nil
+}
+
+// migrateRequestPathParams maps the request's path parameters to HEAD's format before the handler runs
+func (vah *VersionAwareHandler) migrateRequestPathParams(c *gin.Context, endpointDef *EndpointDefinition, fromVersion *Version) error {
+	ctx, cancel := vah.migrationContext(c)
+	defer cancel()
+
+	if err := vah.migrationChain.MigrateRequestPathParams(ctx, c, c.Params, endpointDef.Method, endpointDef.PathPattern,
+		fromVersion, vah.versionBundle.GetHeadVersion()); err != nil {
+		return withMigrationCause(ctx, err)
+	}
+	return nil
+}
+
+// hasPathParamOperations reports whether any change in the chain maps path parameters
+func (mc *MigrationChain) hasPathParamOperations() bool {
+	for _, change := range mc.snapshot() {
+		if len(change.requestPathParamOps) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// pathParamURLRewriter returns a URLRewriter that maps the path parameters of HEAD URLs back to
+// their older format, for every endpoint whose parameters the chain maps, then applies next
+func (mc *MigrationChain) pathParamURLRewriter(headVersion *Version, next URLRewriter) URLRewriter {
+	return func(version *Version, rawURL string) string {
+		rawURL = mc.mapURLPathParams(headVersion, version, rawURL)
+		if next != nil {
+			rawURL = next(version, rawURL)
+		}
+		return rawURL
+	}
+}
+
+// mapURLPathParams maps the path parameters of a HEAD URL to version's format, newest change first
+func (mc *MigrationChain) mapURLPathParams(headVersion, version *Version, rawURL string) string {
+	if version.IsHead {
+		return rawURL
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	segments := strings.Split(parsed.Path, "/")
+
+	path := mc.GetMigrationPath(headVersion, version)
+	sort.SliceStable(path, func(i, j int) bool {
+		return path[i].FromVersion().IsNewerThan(path[j].FromVersion())
+	})
+	changed := false
+	for _, change := range path {
+		for _, pattern := range change.pathParamPatterns() {
+			positions := matchPathPattern(pattern.path, segments)
+			if positions == nil {
+				continue
+			}
+			for _, op := range pattern.ops {
+				i, ok := positions[op.name()]
+				if !ok || op.ToOlder == nil {
+					continue
+				}
+				if value, err := op.ToOlder(segments[i]); err == nil && value != segments[i] {
+					segments[i] = value
+					changed = true
+				}
+			}
+		}
+	}
+	if !changed {
+		return rawURL
+	}
+	parsed.Path = strings.Join(segments, "/")
+	parsed.RawPath = ""
+	return parsed.String()
+}
+
+// pathParamPattern is a path pattern and the mappings declared for its parameters
+type pathParamPattern struct {
+	path string
+	ops  []*RequestMapPathParam
+}
+
+// pathParamPatterns groups the change's path parameter mappings by path pattern, in a stable
+// order. Methods sharing a pattern share its URLs, so each parameter keeps the first mapping.
+func (vc *VersionChange) pathParamPatterns() []pathParamPattern {
+	keys := make([]string, 0, len(vc.requestPathParamOps))
+	for key := range vc.requestPathParamOps {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var patterns []pathParamPattern
+	index := make(map[string]int)
+	for _, key := range keys {
+		path := key[strings.IndexByte(key, ' ')+1:]
+		i, ok := index[path]
+		if !ok {
+			i = len(patterns)
+			index[path] = i
+			patterns = append(patterns, pathParamPattern{path: path})
+		}
+		for _, op := range vc.requestPathParamOps[key] {
+			mapped := false
+			for _, existing := range patterns[i].ops {
+				mapped = mapped || existing.name() == op.name()
+			}
+			if !mapped {
+				patterns[i].ops = append(patterns[i].ops, op)
+			}
+		}
+	}
+	return patterns
+}
+
+// matchPathPattern matches URL path segments against a gin path pattern, returning the segment
+// index of each parameter, or nil when the path doesn't match
+func matchPathPattern(pattern string, segments []string) map[string]int {
+	patternSegments := strings.Split(pattern, "/")
+	if len(patternSegments) != len(segments) {
+		return nil
+	}
+	positions := make(map[string]int)
+	for i, segment := range patternSegments {
+		switch {
+		case strings.HasPrefix(segment, ":"):
+			if segments[i] == "" {
+				return nil
+			}
+			positions[segment[1:]] = i
+		case segment != segments[i]:
+			return nil
+		}
+	}
+	return positions
+}
+
+// MapPathParam maps the endpoint's path parameter for older versions: toNewer turns the value
+// an older client sent into HEAD's before the handler runs, and toOlder, when not nil, turns
+// HEAD values back in the URLs of responses (see WithURLRewriter). A value toNewer rejects is
+// answered with 400 Bad Request.
+// e.g. MapPathParam(":id", legacyIDToULID, ulidToLegacyID) when HEAD replaced numeric IDs with ULIDs
+func (eb *endpointBuilder) MapPathParam(param string, toNewer, toOlder func(string) (string, error)) *endpointBuilder {
+	eb.parent.addPathParamOperation(eb.method, eb.pathPattern, &RequestMapPathParam{
+		Param:   param,
+		ToNewer: toNewer,
+		ToOlder: toOlder,
+	})
+	return eb
+}
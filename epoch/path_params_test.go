@@ -0,0 +1,108 @@
+package epoch
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type pathParamUser struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+}
+
+var _ = Describe("Path parameter mapping", func() {
+	var (
+		v1, v2 *Version
+		seen   string
+	)
+
+	// Older versions use numeric IDs, HEAD prefixes them
+	legacyToHead := func(id string) (string, error) {
+		if _, err := strconv.Atoi(id); err != nil {
+			return "", errors.New("expected a numeric ID")
+		}
+		return "usr_" + id, nil
+	}
+	headToLegacy := func(id string) (string, error) {
+		return strings.TrimPrefix(id, "usr_"), nil
+	}
+
+	newRouter := func(toOlder func(string) (string, error)) *gin.Engine {
+		change := NewVersionChangeBuilder(v1, v2).
+			ForEndpoint("GET", "/users/:id").
+			MapPathParam(":id", legacyToHead, toOlder).
+			Build()
+		epochInstance, err := NewEpoch().WithVersions(v1, v2).WithHeadVersion().
+			WithURLRewriter(nil, "url").WithChanges(change).Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.Use(epochInstance.Middleware())
+		router.GET("/users/:id", epochInstance.WrapHandler(func(c *gin.Context) {
+			seen = c.Param("id")
+			c.Header("Location", "https://api.example.com/users/"+seen+"?expand=org")
+			c.JSON(http.StatusOK, pathParamUser{ID: seen, URL: "/users/" + seen})
+		}).Returns(pathParamUser{}).ToHandlerFunc("GET", "/users/:id"))
+		return router
+	}
+
+	get := func(router *gin.Engine, path, version string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-API-Version", version)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	BeforeEach(func() {
+		v1, _ = NewDateVersion("2024-01-01")
+		v2, _ = NewDateVersion("2025-01-01")
+		seen = ""
+	})
+
+	It("should hand the handler HEAD's value and map URLs back", func() {
+		recorder := get(newRouter(headToLegacy), "/users/42", "2024-01-01")
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(seen).To(Equal("usr_42"))
+		Expect(recorder.Body.String()).To(MatchJSON(`{"id":"usr_42","url":"/users/42"}`))
+		Expect(recorder.Header().Get("Location")).To(Equal("https://api.example.com/users/42?expand=org"))
+	})
+
+	It("should leave HEAD requests alone", func() {
+		recorder := get(newRouter(headToLegacy), "/users/usr_42", "head")
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(seen).To(Equal("usr_42"))
+		Expect(recorder.Body.String()).To(MatchJSON(`{"id":"usr_42","url":"/users/usr_42"}`))
+	})
+
+	It("should leave versions after the change alone", func() {
+		get(newRouter(headToLegacy), "/users/usr_42", "2025-01-01")
+		Expect(seen).To(Equal("usr_42"))
+	})
+
+	It("should answer 400 for values with no HEAD equivalent", func() {
+		recorder := get(newRouter(headToLegacy), "/users/abc", "2024-01-01")
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		Expect(seen).To(BeEmpty())
+
+		var body map[string]interface{}
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &body)).To(Succeed())
+		Expect(body["param"]).To(Equal("id"))
+		Expect(body["error"]).To(ContainSubstring("expected a numeric ID"))
+	})
+
+	It("should keep HEAD values in URLs without a reverse mapping", func() {
+		recorder := get(newRouter(nil), "/users/42", "2024-01-01")
+		Expect(seen).To(Equal("usr_42"))
+		Expect(recorder.Body.String()).To(MatchJSON(`{"id":"usr_42","url":"/users/usr_42"}`))
+	})
+})
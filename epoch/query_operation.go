@@ -0,0 +1,105 @@
+package epoch
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestQueryOperation changes an endpoint's query parameters when migrating a request from
+// an older version to HEAD. Query operations are declared per endpoint with ForEndpoint().
+type RequestQueryOperation interface {
+	// ApplyToQuery rewrites the query parameters in place
+	ApplyToQuery(query url.Values) error
+}
+
+// RequestRenameQueryParam renames a query parameter when request migrates from client to HEAD
+// Use case: older clients send ?per_page=10, HEAD reads ?limit=10
+type RequestRenameQueryParam struct {
+	OlderVersionName string // Parameter name in older/client version
+	NewerVersionName string // Parameter name in newer/HEAD version
+}
+
+// ApplyToQuery moves all values of the older parameter to the newer one
+// A newer parameter the client already sent takes precedence.
+func (op *RequestRenameQueryParam) ApplyToQuery(query url.Values) error {
+	values, ok := query[op.OlderVersionName]
+	if !ok {
+		return nil
+	}
+	query.Del(op.OlderVersionName)
+	if _, exists := query[op.NewerVersionName]; !exists {
+		query[op.NewerVersionName] = values
+	}
+	return nil
+}
+
+// GetRequestQueryOperations returns the query operations this change declares for an endpoint
+func (vc *VersionChange) GetRequestQueryOperations(method, pathPattern string) []RequestQueryOperation {
+	return vc.requestQueryOps[endpointKey(method, pathPattern)]
+}
+
+// queryPath orders the changes between from and to the way requests are migrated: oldest first
+func (mc *MigrationChain) queryPath(from, to *Version, method, pathPattern string) []*VersionChange {
+	var path []*VersionChange
+	for _, change := range mc.GetMigrationPath(from, to) {
+		if len(change.GetRequestQueryOperations(method, pathPattern)) > 0 {
+			path = append(path, change)
+		}
+	}
+	sort.SliceStable(path, func(i, j int) bool {
+		return path[i].FromVersion().IsOlderThan(path[j].FromVersion())
+	})
+	return path
+}
+
+// HasQueryOperations reports whether any change between two versions rewrites the endpoint's query parameters
+func (mc *MigrationChain) HasQueryOperations(from, to *Version, method, pathPattern string) bool {
+	return len(mc.queryPath(from, to, method, pathPattern)) > 0
+}
+
+// MigrateRequestQuery applies the endpoint's query operations from an older version (from)
+// up to HEAD (to), rewriting query in place
+func (mc *MigrationChain) MigrateRequestQuery(
+	ctx context.Context,
+	c *gin.Context,
+	query url.Values,
+	method, pathPattern string,
+	from, to *Version,
+) error {
+	for _, change := range mc.queryPath(from, to, method, pathPattern) {
+		if !change.isEnabled(ctx, c) {
+			continue
+		}
+		if err := checkMigrationContext(ctx); err != nil {
+			return err
+		}
+		ops := change.GetRequestQueryOperations(method, pathPattern)
+		err := change.recoverChange(DirectionRequest, func() error {
+			for i, op := range ops {
+				if err := recoverOperation(i, func() error { return op.ApplyToQuery(query) }); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("query migration failed at %s->%s: %w",
+				change.FromVersion().String(), change.ToVersion().String(), err)
+		}
+	}
+	return nil
+}
+
+// RenameQueryParam renames the endpoint's query parameter for requests from older versions,
+// e.g. older clients send ?per_page=10 while HEAD reads ?limit=10
+func (eb *endpointBuilder) RenameQueryParam(olderVersionName, newerVersionName string) *endpointBuilder {
+	eb.parent.addQueryOperation(eb.method, eb.pathPattern, &RequestRenameQueryParam{
+		OlderVersionName: olderVersionName,
+		NewerVersionName: newerVersionName,
+	})
+	return eb
+}
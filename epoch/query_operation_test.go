@@ -0,0 +1,72 @@
+package epoch
+
+import (
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Query operations", func() {
+	var v1, v2, v3 *Version
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		v1, v2, v3 = newTestVersions()
+	})
+
+	serve := func(target, version string, changes ...*VersionChange) string {
+		epochInstance, err := setupHeadEpoch([]*Version{v1, v2, v3}, changes)
+		Expect(err).NotTo(HaveOccurred())
+
+		router := setupRouterWithMiddleware(epochInstance)
+		router.GET("/users", epochInstance.WrapHandler(func(c *gin.Context) {
+			c.String(200, c.Request.URL.RawQuery)
+		}).ToHandlerFunc("GET", "/users"))
+
+		recorder := serveVersioned(router, httptest.NewRequest("GET", target, nil), version)
+		Expect(recorder.Code).To(Equal(200))
+		return recorder.Body.String()
+	}
+
+	It("should rename query parameters for older versions only", func() {
+		change := NewVersionChangeBuilder(v2, v3).
+			ForEndpoint("GET", "/users").
+			RenameQueryParam("q", "search").
+			Build()
+
+		Expect(serve("/users?q=jane", "2024-06-01", change)).To(Equal("search=jane"))
+		Expect(serve("/users?q=jane", "2025-01-01", change)).To(Equal("q=jane"))
+	})
+
+	It("should apply changes oldest first", func() {
+		first := NewVersionChangeBuilder(v1, v2).
+			ForEndpoint("GET", "/users").
+			RenameQueryParam("q", "query").
+			Build()
+		second := NewVersionChangeBuilder(v2, v3).
+			ForEndpoint("GET", "/users").
+			RenameQueryParam("query", "search").
+			Build()
+
+		Expect(serve("/users?q=jane", "2024-01-01", second, first)).To(Equal("search=jane"))
+	})
+
+	It("should keep a newer parameter the client already sent", func() {
+		query := url.Values{"q": {"old"}, "search": {"new"}}
+		op := &RequestRenameQueryParam{OlderVersionName: "q", NewerVersionName: "search"}
+		Expect(op.ApplyToQuery(query)).To(Succeed())
+		Expect(query).To(Equal(url.Values{"search": {"new"}}))
+	})
+
+	It("should expose query operations per endpoint", func() {
+		change := NewVersionChangeBuilder(v2, v3).
+			ForEndpoint("GET", "/users").
+			RenameQueryParam("q", "search").
+			Build()
+		Expect(change.GetRequestQueryOperations("get", "/users")).To(HaveLen(1))
+		Expect(change.GetRequestQueryOperations("GET", "/orgs")).To(BeEmpty())
+	})
+})
@@ -0,0 +1,208 @@
+package epoch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/astronomer/epoch/epoch/jsonast"
+)
+
+// ReferenceFetcher loads the referenced objects for a batch of ids, keyed by id.
+// Ids are JSON scalars as text (`42`, `org_1`). Ids missing from the result expand to null.
+type ReferenceFetcher func(ctx context.Context, ids []string) (map[string]interface{}, error)
+
+// ReferenceName returns the field an id field expands to: "organization_id" -> "organization"
+func ReferenceName(idField string) string {
+	for _, suffix := range []string{"_id", "Id", "ID"} {
+		if name := strings.TrimSuffix(idField, suffix); name != idField && name != "" {
+			return name
+		}
+	}
+	return idField
+}
+
+// ============================================================================
+// Request Operations - TO NEXT VERSION (Client→HEAD)
+// ============================================================================
+
+// RequestCollapseReference replaces an embedded object with its id when request migrates from client to HEAD
+// Use case: older clients send "organization": {"id": 7, ...}, HEAD expects "organization_id": 7
+type RequestCollapseReference struct {
+	Name    string // Embedded object field in older/client version
+	IDField string // Field of the embedded object holding the id
+}
+
+// HeadFieldName returns the id field HEAD expects, e.g. "organization_id"
+func (op *RequestCollapseReference) HeadFieldName() string {
+	return op.Name + "_" + op.IDField
+}
+
+func (op *RequestCollapseReference) ApplyToRequest(node *jsonast.Node) error {
+	embedded := GetNodeField(node, op.Name)
+	if !IsNodeObject(embedded) {
+		return nil
+	}
+
+	id := GetNodeField(embedded, op.IDField)
+	if id != nil && id.Exists() {
+		value, err := id.InterfaceUseNumber()
+		if err != nil {
+			return fmt.Errorf("failed to read %s.%s: %w", op.Name, op.IDField, err)
+		}
+		if !HasNodeField(node, op.HeadFieldName()) {
+			if err := SetNodeField(node, op.HeadFieldName(), value); err != nil {
+				return fmt.Errorf("failed to set field %s: %w", op.HeadFieldName(), err)
+			}
+		}
+	}
+	return DeleteNodeField(node, op.Name)
+}
+
+func (op *RequestCollapseReference) GetFieldMapping() map[string]string {
+	// When transforming error messages, map the older embedded field to HEAD's id field
+	return map[string]string{op.Name: op.HeadFieldName()}
+}
+
+// Inverse returns nil; expanding needs a fetcher, so schema generation handles this operation directly
+func (op *RequestCollapseReference) Inverse() RequestToNextVersionOperation {
+	return nil
+}
+
+// ============================================================================
+// Response Operations - TO PREVIOUS VERSION (HEAD→Client)
+// ============================================================================
+
+// ResponseExpandReference replaces an id with the object it references when response migrates from HEAD to client
+// Use case: HEAD returns "organization_id": 7, older clients expect the full "organization" object
+//
+// Ids are collected while the response migrates and fetched in one Fetch call per operation
+// once all changes have run, so list responses don't fetch item by item. The expanded objects
+// are returned as Fetch provides them.
+type ResponseExpandReference struct {
+	IDField string // Id field in newer/HEAD version
+	Name    string // Expanded object field in older/client version
+	Fetch   ReferenceFetcher
+}
+
+func (op *ResponseExpandReference) ApplyToResponse(node *jsonast.Node) error {
+	return op.ApplyToResponseInfo(&ResponseInfo{Body: node})
+}
+
+// ApplyToResponseInfo replaces the id with a placeholder that's filled when the batch is fetched
+// Outside a response migration (no batch), the reference is fetched immediately.
+func (op *ResponseExpandReference) ApplyToResponseInfo(resp *ResponseInfo) error {
+	if resp == nil || !IsNodeObject(resp.Body) || op.Fetch == nil {
+		return nil
+	}
+	idNode := GetNodeField(resp.Body, op.IDField)
+	if idNode == nil || !idNode.Exists() {
+		return nil
+	}
+
+	id, ok, err := referenceID(idNode)
+	if err != nil {
+		return fmt.Errorf("failed to read field %s: %w", op.IDField, err)
+	}
+	if err := DeleteNodeField(resp.Body, op.IDField); err != nil {
+		return fmt.Errorf("failed to remove field %s: %w", op.IDField, err)
+	}
+	if err := SetNodeField(resp.Body, op.Name, nil); err != nil {
+		return fmt.Errorf("failed to set field %s: %w", op.Name, err)
+	}
+	if !ok {
+		return nil // A null id expands to null
+	}
+
+	pending := pendingReference{op: op, node: resp.Body, id: id}
+	if resp.references != nil {
+		resp.references.add(pending)
+		return nil
+	}
+
+	batch := &referenceBatch{}
+	batch.add(pending)
+	return batch.resolve(resp.Context())
+}
+
+func (op *ResponseExpandReference) GetFieldMapping() map[string]string {
+	// When transforming error messages, map HEAD's id field to the older embedded field
+	return map[string]string{op.IDField: op.Name}
+}
+
+// referenceID returns a scalar id as text; ok is false for null
+func referenceID(node *jsonast.Node) (id string, ok bool, err error) {
+	switch node.TypeSafe() {
+	case jsonast.V_NULL:
+		return "", false, nil
+	case jsonast.V_STRING:
+		id, err = node.String()
+	case jsonast.V_NUMBER:
+		id, err = node.Raw()
+	default:
+		return "", false, errors.New("reference id must be a string or number")
+	}
+	return id, err == nil, err
+}
+
+// pendingReference is an expanded field waiting for its referenced object
+type pendingReference struct {
+	op   *ResponseExpandReference
+	node *jsonast.Node // Object holding the expanded field
+	id   string
+}
+
+// referenceBatch collects the references expanded while one response migrates
+type referenceBatch struct {
+	mu      sync.Mutex
+	pending []pendingReference
+}
+
+func (b *referenceBatch) add(ref pendingReference) {
+	b.mu.Lock()
+	b.pending = append(b.pending, ref)
+	b.mu.Unlock()
+}
+
+// resolve fetches the collected ids, one Fetch call per operation, and fills the expanded fields
+func (b *referenceBatch) resolve(ctx context.Context) error {
+	b.mu.Lock()
+	pending := b.pending
+	b.pending = nil
+	b.mu.Unlock()
+
+	// Group by operation, keeping the order operations were first seen in
+	var ops []*ResponseExpandReference
+	byOp := make(map[*ResponseExpandReference][]pendingReference)
+	for _, ref := range pending {
+		if _, seen := byOp[ref.op]; !seen {
+			ops = append(ops, ref.op)
+		}
+		byOp[ref.op] = append(byOp[ref.op], ref)
+	}
+
+	for _, op := range ops {
+		refs := byOp[op]
+		var ids []string
+		seen := make(map[string]bool, len(refs))
+		for _, ref := range refs {
+			if !seen[ref.id] {
+				seen[ref.id] = true
+				ids = append(ids, ref.id)
+			}
+		}
+
+		objects, err := op.Fetch(ctx, ids)
+		if err != nil {
+			return fmt.Errorf("failed to fetch %s references: %w", op.Name, err)
+		}
+		for _, ref := range refs {
+			if err := SetNodeField(ref.node, op.Name, objects[ref.id]); err != nil {
+				return fmt.Errorf("failed to set field %s: %w", op.Name, err)
+			}
+		}
+	}
+	return nil
+}
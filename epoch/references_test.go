@@ -0,0 +1,173 @@
+package epoch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type refUser struct {
+	ID             int    `json:"id"`
+	OrganizationID string `json:"organization_id"`
+}
+
+type refOrg struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type refTeam struct {
+	Name    string    `json:"name"`
+	Members []refUser `json:"members"`
+}
+
+var _ = Describe("Reference operations", func() {
+	var (
+		calls   [][]string
+		fetcher ReferenceFetcher
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		calls = nil
+		fetcher = func(ctx context.Context, ids []string) (map[string]interface{}, error) {
+			calls = append(calls, ids)
+			orgs := map[string]interface{}{}
+			for _, id := range ids {
+				if id != "missing" {
+					orgs[id] = refOrg{ID: id, Name: "Org " + id}
+				}
+			}
+			return orgs, nil
+		}
+	})
+
+	serve := func(method, version, body string, returns interface{}, handler gin.HandlerFunc, changes ...func(v1, v2, v3 *Version) *VersionChange) *httptest.ResponseRecorder {
+		v1, v2, v3 := newTestVersions()
+		var built []*VersionChange
+		for _, change := range changes {
+			built = append(built, change(v1, v2, v3))
+		}
+		epochInstance, err := setupHeadEpoch([]*Version{v1, v2, v3}, built)
+		Expect(err).NotTo(HaveOccurred())
+
+		router := setupRouterWithMiddleware(epochInstance)
+		router.Handle(method, "/resource", epochInstance.WrapHandler(handler).
+			Accepts(refUser{}).Returns(returns).ToHandlerFunc(method, "/resource"))
+
+		req := httptest.NewRequest(method, "/resource", bytes.NewBufferString(body))
+		return serveVersioned(router, req, version)
+	}
+
+	expand := func(v1, v2, v3 *Version) *VersionChange {
+		return NewVersionChangeBuilder(v2, v3).
+			ForType(refUser{}).
+			ResponseToPreviousVersion().
+			ExpandReference("organization_id", fetcher).
+			Build()
+	}
+
+	It("should derive the expanded field name", func() {
+		Expect(ReferenceName("organization_id")).To(Equal("organization"))
+		Expect(ReferenceName("ownerId")).To(Equal("owner"))
+		Expect(ReferenceName("parent")).To(Equal("parent"))
+	})
+
+	It("should expand a reference for older versions", func() {
+		handler := func(c *gin.Context) { c.JSON(200, gin.H{"id": 1, "organization_id": "7"}) }
+
+		recorder := serve("GET", "2024-06-01", "", refUser{}, handler, expand)
+		Expect(recorder.Body.String()).To(Equal(`{"id":1,"organization":{"id":"7","name":"Org 7"}}`))
+
+		recorder = serve("GET", "2025-01-01", "", refUser{}, handler, expand)
+		Expect(recorder.Body.String()).To(Equal(`{"id":1,"organization_id":"7"}`))
+	})
+
+	It("should fetch all references of a list response in one batch", func() {
+		handler := func(c *gin.Context) {
+			c.JSON(200, []gin.H{
+				{"id": 1, "organization_id": "7"},
+				{"id": 2, "organization_id": "8"},
+				{"id": 3, "organization_id": "7"},
+				{"id": 4, "organization_id": nil},
+				{"id": 5, "organization_id": "missing"},
+			})
+		}
+
+		recorder := serve("GET", "2024-06-01", "", []refUser{}, handler, expand)
+		Expect(recorder.Code).To(Equal(200))
+		Expect(calls).To(Equal([][]string{{"7", "8", "missing"}}))
+		Expect(recorder.Body.String()).To(Equal(`[` +
+			`{"id":1,"organization":{"id":"7","name":"Org 7"}},` +
+			`{"id":2,"organization":{"id":"8","name":"Org 8"}},` +
+			`{"id":3,"organization":{"id":"7","name":"Org 7"}},` +
+			`{"id":4,"organization":null},` +
+			`{"id":5,"organization":null}]`))
+	})
+
+	It("should batch nested array items and survive later changes to the parent", func() {
+		handler := func(c *gin.Context) {
+			c.Data(200, "application/json", []byte(`{"name":"core","members":[{"id":1,"organization_id":7},{"id":2,"organization_id":8}]}`))
+		}
+		addField := func(v1, v2, v3 *Version) *VersionChange {
+			return NewVersionChangeBuilder(v1, v2).
+				ForType(refUser{}).
+				ResponseToPreviousVersion().
+				AddField("legacy", true).
+				Build()
+		}
+
+		recorder := serve("GET", "2024-01-01", "", refTeam{}, handler, addField, expand)
+		Expect(calls).To(Equal([][]string{{"7", "8"}}))
+		Expect(recorder.Body.String()).To(Equal(`{"name":"core","members":[` +
+			`{"id":1,"organization":{"id":"7","name":"Org 7"},"legacy":true},` +
+			`{"id":2,"organization":{"id":"8","name":"Org 8"},"legacy":true}]}`))
+	})
+
+	It("should fail the response when fetching fails", func() {
+		fetcher = func(ctx context.Context, ids []string) (map[string]interface{}, error) {
+			return nil, errors.New("database unavailable")
+		}
+		handler := func(c *gin.Context) { c.JSON(200, gin.H{"id": 1, "organization_id": "7"}) }
+
+		recorder := serve("GET", "2024-06-01", "", refUser{}, handler, expand)
+		Expect(recorder.Code).To(Equal(500))
+		Expect(recorder.Body.String()).To(ContainSubstring("database unavailable"))
+	})
+
+	It("should collapse an embedded object into its id for requests", func() {
+		var received string
+		handler := func(c *gin.Context) {
+			body, _ := c.GetRawData()
+			received = string(body)
+			c.JSON(200, gin.H{"id": 1})
+		}
+		collapse := func(v1, v2, v3 *Version) *VersionChange {
+			return NewVersionChangeBuilder(v2, v3).
+				ForType(refUser{}).
+				RequestToNextVersion().
+				CollapseReference("organization", "id").
+				Build()
+		}
+
+		serve("POST", "2024-06-01", `{"id":1,"organization":{"id":7,"name":"Org 7"}}`, refUser{}, handler, collapse)
+		Expect(received).To(Equal(`{"id":1,"organization_id":7}`))
+
+		serve("POST", "2024-06-01", `{"id":1,"organization_id":7}`, refUser{}, handler, collapse)
+		Expect(received).To(Equal(`{"id":1,"organization_id":7}`))
+	})
+
+	It("should fetch immediately when applied outside a response migration", func() {
+		node, err := DefaultJSONEngine().Parse([]byte(`{"organization_id":7}`))
+		Expect(err).NotTo(HaveOccurred())
+		op := &ResponseExpandReference{IDField: "organization_id", Name: "organization", Fetch: fetcher}
+		Expect(op.ApplyToResponse(node)).To(Succeed())
+		out, _ := node.MarshalJSON()
+		Expect(string(out)).To(Equal(`{"organization":{"id":"7","name":"Org 7"}}`))
+	})
+})
@@ -0,0 +1,144 @@
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Exchange is one recorded request and the response the legacy API returned for it
+type Exchange struct {
+	Method   string           `json:"method"`
+	URL      string           `json:"url"`
+	Header   http.Header      `json:"headers,omitempty"`
+	Body     json.RawMessage  `json:"body,omitempty"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedResponse is the legacy response of an Exchange
+type RecordedResponse struct {
+	Status int             `json:"status"`
+	Header http.Header     `json:"headers,omitempty"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// LoadJSONL reads one JSON-encoded Exchange per line; blank lines are skipped
+func LoadJSONL(r io.Reader) ([]Exchange, error) {
+	var exchanges []Exchange
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var exchange Exchange
+		if err := json.Unmarshal(scanner.Bytes(), &exchange); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		exchange.Header = canonicalHeader(exchange.Header)
+		exchange.Response.Header = canonicalHeader(exchange.Response.Header)
+		exchanges = append(exchanges, exchange)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read recording: %w", err)
+	}
+	return exchanges, nil
+}
+
+// harLog is the subset of the HAR 1.2 format needed for replay
+type harLog struct {
+	Log struct {
+		Entries []struct {
+			Request struct {
+				Method   string      `json:"method"`
+				URL      string      `json:"url"`
+				Headers  []harHeader `json:"headers"`
+				PostData *struct {
+					Text string `json:"text"`
+				} `json:"postData"`
+			} `json:"request"`
+			Response struct {
+				Status  int         `json:"status"`
+				Headers []harHeader `json:"headers"`
+				Content struct {
+					Text     string `json:"text"`
+					Encoding string `json:"encoding"`
+				} `json:"content"`
+			} `json:"response"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+type harHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// LoadHAR reads the entries of a HAR archive, e.g. exported from browser dev tools or a proxy
+func LoadHAR(r io.Reader) ([]Exchange, error) {
+	var har harLog
+	if err := json.NewDecoder(r).Decode(&har); err != nil {
+		return nil, fmt.Errorf("failed to decode HAR: %w", err)
+	}
+
+	exchanges := make([]Exchange, 0, len(har.Log.Entries))
+	for i, entry := range har.Log.Entries {
+		exchange := Exchange{
+			Method: entry.Request.Method,
+			URL:    entry.Request.URL,
+			Header: harHeaders(entry.Request.Headers),
+			Response: RecordedResponse{
+				Status: entry.Response.Status,
+				Header: harHeaders(entry.Response.Headers),
+			},
+		}
+		if entry.Request.PostData != nil && entry.Request.PostData.Text != "" {
+			exchange.Body = json.RawMessage(entry.Request.PostData.Text)
+		}
+
+		body := []byte(entry.Response.Content.Text)
+		if entry.Response.Content.Encoding == "base64" {
+			decoded, err := base64.StdEncoding.DecodeString(entry.Response.Content.Text)
+			if err != nil {
+				return nil, fmt.Errorf("entry %d: failed to decode response body: %w", i, err)
+			}
+			body = decoded
+		}
+		if len(body) > 0 {
+			exchange.Response.Body = body
+		}
+		exchanges = append(exchanges, exchange)
+	}
+	return exchanges, nil
+}
+
+// harHeaders converts HAR name/value pairs to an http.Header
+// HTTP/2 pseudo-headers (:authority, ...) are dropped.
+func harHeaders(headers []harHeader) http.Header {
+	result := make(http.Header, len(headers))
+	for _, header := range headers {
+		if len(header.Name) > 0 && header.Name[0] == ':' {
+			continue
+		}
+		result.Add(header.Name, header.Value)
+	}
+	return result
+}
+
+// canonicalHeader re-keys a decoded header so Get works regardless of the recorded casing
+func canonicalHeader(header http.Header) http.Header {
+	if header == nil {
+		return nil
+	}
+	result := make(http.Header, len(header))
+	for name, values := range header {
+		result[http.CanonicalHeaderKey(name)] = append(result[http.CanonicalHeaderKey(name)], values...)
+	}
+	return result
+}
@@ -0,0 +1,63 @@
+package replay
+
+import (
+	"encoding/base64"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Recordings", func() {
+	Describe("LoadJSONL", func() {
+		It("should read one exchange per line", func() {
+			exchanges, err := LoadJSONL(strings.NewReader(`
+{"method":"GET","url":"/users/1","headers":{"X-API-Version":["2024-01-01"]},"response":{"status":200,"body":{"id":1}}}
+
+{"method":"POST","url":"/users","body":{"name":"Jane"},"response":{"status":201}}
+`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exchanges).To(HaveLen(2))
+
+			Expect(exchanges[0].Header.Get("X-API-Version")).To(Equal("2024-01-01"))
+			Expect(string(exchanges[0].Response.Body)).To(Equal(`{"id":1}`))
+			Expect(string(exchanges[1].Body)).To(Equal(`{"name":"Jane"}`))
+			Expect(exchanges[1].Response.Status).To(Equal(201))
+		})
+
+		It("should report the failing line", func() {
+			_, err := LoadJSONL(strings.NewReader("{\"method\":\"GET\"}\nnot json\n"))
+			Expect(err).To(MatchError(ContainSubstring("line 2")))
+		})
+	})
+
+	Describe("LoadHAR", func() {
+		It("should convert entries to exchanges", func() {
+			body := base64.StdEncoding.EncodeToString([]byte(`{"id":1}`))
+			exchanges, err := LoadHAR(strings.NewReader(`{"log":{"entries":[{
+				"request":{"method":"POST","url":"https://api.example.com/users",
+					"headers":[{"name":":authority","value":"api.example.com"},{"name":"X-API-Version","value":"2024-01-01"}],
+					"postData":{"mimeType":"application/json","text":"{\"name\":\"Jane\"}"}},
+				"response":{"status":201,"headers":[{"name":"Content-Type","value":"application/json"}],
+					"content":{"mimeType":"application/json","text":"` + body + `","encoding":"base64"}}
+			}]}}`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exchanges).To(HaveLen(1))
+
+			exchange := exchanges[0]
+			Expect(exchange.Method).To(Equal("POST"))
+			Expect(exchange.URL).To(Equal("https://api.example.com/users"))
+			Expect(exchange.Header).NotTo(HaveKey(":authority"))
+			Expect(exchange.Header.Get("X-API-Version")).To(Equal("2024-01-01"))
+			Expect(string(exchange.Body)).To(Equal(`{"name":"Jane"}`))
+			Expect(exchange.Response.Status).To(Equal(201))
+			Expect(exchange.Response.Header.Get("Content-Type")).To(Equal("application/json"))
+			Expect(string(exchange.Response.Body)).To(Equal(`{"id":1}`))
+		})
+
+		It("should reject malformed archives", func() {
+			_, err := LoadHAR(strings.NewReader(`{"log":`))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,262 @@
+// Package replay runs recorded legacy traffic through a candidate Epoch configuration
+// and reports where its responses differ from the recorded ones, so new VersionChanges
+// can be validated against historical behavior before they ship.
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// missing renders a value that is absent on one side of a Difference
+const missing = "<missing>"
+
+// Replayer sends recorded requests to a handler and compares its responses with the recordings
+type Replayer struct {
+	handler        http.Handler
+	defaultHeaders http.Header
+	ignoredFields  map[string]bool
+	comparedHeader []string
+}
+
+// NewReplayer creates a replayer for handler, typically a Gin engine wired with the
+// candidate Epoch middleware and handlers
+func NewReplayer(handler http.Handler) *Replayer {
+	return &Replayer{
+		handler:        handler,
+		defaultHeaders: make(http.Header),
+		ignoredFields:  make(map[string]bool),
+	}
+}
+
+// WithHeader sets a request header on exchanges whose recording doesn't carry it,
+// e.g. the version header for traffic recorded before versioning was introduced
+func (r *Replayer) WithHeader(name, value string) *Replayer {
+	r.defaultHeaders.Set(name, value)
+	return r
+}
+
+// IgnoreFields skips body fields that legitimately change between runs (timestamps, IDs)
+// Paths use dots for objects and [] for any array index, e.g. "updated_at" or "items[].id".
+func (r *Replayer) IgnoreFields(paths ...string) *Replayer {
+	for _, path := range paths {
+		r.ignoredFields[path] = true
+	}
+	return r
+}
+
+// CompareHeaders adds response headers to the comparison; only status and body are compared by default
+func (r *Replayer) CompareHeaders(names ...string) *Replayer {
+	r.comparedHeader = append(r.comparedHeader, names...)
+	return r
+}
+
+// Result summarizes a replay run
+type Result struct {
+	Total      int
+	Mismatches []Mismatch
+}
+
+// Mismatch is a replayed exchange whose response differs from the recording
+type Mismatch struct {
+	Index       int
+	Method      string
+	URL         string
+	Differences []Difference
+}
+
+// Difference is one differing value; Expected is the recorded value and Actual the replayed one
+// Path is "status", "header <Name>", or the body path of the field ("" for the whole body).
+type Difference struct {
+	Path     string
+	Expected string
+	Actual   string
+}
+
+// Passed reports whether every exchange reproduced its recorded response
+func (res *Result) Passed() bool {
+	return len(res.Mismatches) == 0
+}
+
+// String renders the result for logs and CI output
+func (res *Result) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d/%d exchanges matched\n", res.Total-len(res.Mismatches), res.Total)
+	for _, mismatch := range res.Mismatches {
+		fmt.Fprintf(&b, "#%d %s %s\n", mismatch.Index, mismatch.Method, mismatch.URL)
+		for _, diff := range mismatch.Differences {
+			path := diff.Path
+			if path == "" {
+				path = "body"
+			}
+			fmt.Fprintf(&b, "  %s: expected %s, got %s\n", path, diff.Expected, diff.Actual)
+		}
+	}
+	return b.String()
+}
+
+// Run replays every exchange in order and collects the mismatches
+func (r *Replayer) Run(exchanges []Exchange) *Result {
+	result := &Result{Total: len(exchanges)}
+	for i, exchange := range exchanges {
+		if differences := r.replay(exchange); len(differences) > 0 {
+			result.Mismatches = append(result.Mismatches, Mismatch{
+				Index:       i,
+				Method:      exchange.Method,
+				URL:         exchange.URL,
+				Differences: differences,
+			})
+		}
+	}
+	return result
+}
+
+// replay sends one exchange to the handler and diffs the result against the recording
+func (r *Replayer) replay(exchange Exchange) []Difference {
+	req := httptest.NewRequest(exchange.Method, exchange.URL, bytes.NewReader(exchange.Body))
+	for name, values := range exchange.Header {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	for name, values := range r.defaultHeaders {
+		if req.Header.Get(name) == "" {
+			req.Header[name] = values
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	r.handler.ServeHTTP(recorder, req)
+
+	var differences []Difference
+	if recorder.Code != exchange.Response.Status {
+		differences = append(differences, Difference{
+			Path:     "status",
+			Expected: fmt.Sprint(exchange.Response.Status),
+			Actual:   fmt.Sprint(recorder.Code),
+		})
+	}
+	for _, name := range r.comparedHeader {
+		expected, actual := exchange.Response.Header.Get(name), recorder.Header().Get(name)
+		if expected != actual {
+			differences = append(differences, Difference{Path: "header " + name, Expected: quoteOrMissing(expected), Actual: quoteOrMissing(actual)})
+		}
+	}
+	return append(differences, r.diffBodies(exchange.Response.Body, recorder.Body.Bytes())...)
+}
+
+// diffBodies compares bodies structurally when both are JSON, byte-for-byte otherwise
+func (r *Replayer) diffBodies(expected, actual []byte) []Difference {
+	expected, actual = bytes.TrimSpace(expected), bytes.TrimSpace(actual)
+	if len(expected) == 0 && len(actual) == 0 {
+		return nil
+	}
+
+	expectedValue, expectedErr := decodeJSON(expected)
+	actualValue, actualErr := decodeJSON(actual)
+	if expectedErr != nil || actualErr != nil {
+		if bytes.Equal(expected, actual) {
+			return nil
+		}
+		return []Difference{{Path: "", Expected: quoteOrMissing(string(expected)), Actual: quoteOrMissing(string(actual))}}
+	}
+
+	var differences []Difference
+	r.diffValues("", expectedValue, actualValue, &differences)
+	return differences
+}
+
+// arrayIndexPattern matches concrete array indices in a body path
+var arrayIndexPattern = regexp.MustCompile(`\[\d+\]`)
+
+// diffValues walks two decoded JSON values and records every leaf that differs
+func (r *Replayer) diffValues(path string, expected, actual interface{}, differences *[]Difference) {
+	if r.ignoredFields[arrayIndexPattern.ReplaceAllString(path, "[]")] {
+		return
+	}
+
+	switch expectedValue := expected.(type) {
+	case map[string]interface{}:
+		if actualValue, ok := actual.(map[string]interface{}); ok {
+			keys := make(map[string]bool, len(expectedValue)+len(actualValue))
+			for key := range expectedValue {
+				keys[key] = true
+			}
+			for key := range actualValue {
+				keys[key] = true
+			}
+			sorted := make([]string, 0, len(keys))
+			for key := range keys {
+				sorted = append(sorted, key)
+			}
+			sort.Strings(sorted)
+
+			for _, key := range sorted {
+				childPath := key
+				if path != "" {
+					childPath = path + "." + key
+				}
+				expectedChild, inExpected := expectedValue[key]
+				actualChild, inActual := actualValue[key]
+				switch {
+				case !inExpected:
+					if !r.ignoredFields[arrayIndexPattern.ReplaceAllString(childPath, "[]")] {
+						*differences = append(*differences, Difference{Path: childPath, Expected: missing, Actual: encode(actualChild)})
+					}
+				case !inActual:
+					if !r.ignoredFields[arrayIndexPattern.ReplaceAllString(childPath, "[]")] {
+						*differences = append(*differences, Difference{Path: childPath, Expected: encode(expectedChild), Actual: missing})
+					}
+				default:
+					r.diffValues(childPath, expectedChild, actualChild, differences)
+				}
+			}
+			return
+		}
+	case []interface{}:
+		if actualValue, ok := actual.([]interface{}); ok && len(actualValue) == len(expectedValue) {
+			for i := range expectedValue {
+				r.diffValues(fmt.Sprintf("%s[%d]", path, i), expectedValue[i], actualValue[i], differences)
+			}
+			return
+		}
+	}
+
+	if encode(expected) != encode(actual) {
+		*differences = append(*differences, Difference{Path: path, Expected: encode(expected), Actual: encode(actual)})
+	}
+}
+
+// decodeJSON decodes a body, keeping numbers exact
+func decodeJSON(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// encode renders a decoded JSON value compactly for reports
+func encode(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// quoteOrMissing quotes a raw string value, or renders an empty one as missing
+func quoteOrMissing(value string) string {
+	if value == "" {
+		return missing
+	}
+	return fmt.Sprintf("%q", value)
+}
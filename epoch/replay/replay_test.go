@@ -0,0 +1,121 @@
+package replay
+
+import (
+	"encoding/json"
+
+	"github.com/astronomer/epoch/epoch"
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type replayUser struct {
+	ID        int    `json:"id"`
+	FullName  string `json:"full_name"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+var _ = Describe("Replayer", func() {
+	var router *gin.Engine
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		v1, _ := epoch.NewDateVersion("2024-01-01")
+		v2, _ := epoch.NewDateVersion("2024-06-01")
+
+		// Candidate change: legacy clients saw "name" instead of "full_name"
+		epochInstance, err := epoch.NewEpoch().
+			WithVersions(v1, v2).
+			WithHeadVersion().
+			WithChanges(epoch.NewVersionChangeBuilder(v1, v2).
+				ForType(replayUser{}).
+				ResponseToPreviousVersion().
+				RenameField("full_name", "name").
+				Build()).
+			Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		router = gin.New()
+		router.Use(epochInstance.Middleware())
+		router.GET("/users/:id", epochInstance.WrapHandler(func(c *gin.Context) {
+			c.JSON(200, replayUser{ID: 1, FullName: "Jane", UpdatedAt: "2025-03-01T00:00:00Z"})
+		}).Returns(replayUser{}).ToHandlerFunc("GET", "/users/:id"))
+	})
+
+	exchange := func(body string) Exchange {
+		return Exchange{
+			Method:   "GET",
+			URL:      "/users/1",
+			Response: RecordedResponse{Status: 200, Body: json.RawMessage(body)},
+		}
+	}
+
+	It("should pass when migrations reproduce the recording", func() {
+		report := NewReplayer(router).
+			WithHeader("X-API-Version", "2024-01-01").
+			IgnoreFields("updated_at").
+			Run([]Exchange{exchange(`{"id":1,"name":"Jane","updated_at":"2023-12-01T00:00:00Z"}`)})
+
+		Expect(report.Passed()).To(BeTrue(), report.String())
+		Expect(report.Total).To(Equal(1))
+	})
+
+	It("should report differing, missing and extra fields", func() {
+		report := NewReplayer(router).
+			WithHeader("X-API-Version", "2024-01-01").
+			IgnoreFields("updated_at").
+			Run([]Exchange{exchange(`{"id":2,"display_name":"Jane"}`)})
+
+		Expect(report.Passed()).To(BeFalse())
+		Expect(report.Mismatches).To(HaveLen(1))
+		Expect(report.Mismatches[0].Differences).To(Equal([]Difference{
+			{Path: "display_name", Expected: `"Jane"`, Actual: missing},
+			{Path: "id", Expected: "2", Actual: "1"},
+			{Path: "name", Expected: missing, Actual: `"Jane"`},
+		}))
+		Expect(report.String()).To(ContainSubstring("0/1 exchanges matched"))
+	})
+
+	It("should keep headers from the recording over defaults", func() {
+		recorded := exchange(`{"id":1,"full_name":"Jane"}`)
+		recorded.Header = map[string][]string{"X-Api-Version": {"2024-06-01"}}
+
+		report := NewReplayer(router).
+			WithHeader("X-API-Version", "2024-01-01").
+			IgnoreFields("updated_at").
+			Run([]Exchange{recorded})
+		Expect(report.Passed()).To(BeTrue(), report.String())
+	})
+
+	It("should compare status and selected headers", func() {
+		recorded := exchange(`{}`)
+		recorded.Response.Status = 404
+		recorded.Response.Header = map[string][]string{"Content-Type": {"text/plain"}}
+
+		report := NewReplayer(router).
+			CompareHeaders("Content-Type").
+			IgnoreFields("").
+			Run([]Exchange{recorded})
+
+		Expect(report.Mismatches[0].Differences).To(Equal([]Difference{
+			{Path: "status", Expected: "404", Actual: "200"},
+			{Path: "header Content-Type", Expected: `"text/plain"`, Actual: `"application/json; charset=utf-8"`},
+		}))
+	})
+
+	It("should ignore fields inside arrays", func() {
+		replayer := NewReplayer(router).IgnoreFields("items[].id")
+		var differences []Difference
+		replayer.diffValues("", map[string]interface{}{"items": []interface{}{map[string]interface{}{"id": 1}}},
+			map[string]interface{}{"items": []interface{}{map[string]interface{}{"id": 2}}}, &differences)
+		Expect(differences).To(BeEmpty())
+	})
+
+	It("should compare non-JSON bodies byte for byte", func() {
+		replayer := NewReplayer(router)
+		Expect(replayer.diffBodies([]byte("ok"), []byte("ok\n"))).To(BeEmpty())
+		Expect(replayer.diffBodies([]byte("ok"), []byte("nope"))).To(Equal([]Difference{
+			{Path: "", Expected: `"ok"`, Actual: `"nope"`},
+		}))
+	})
+})
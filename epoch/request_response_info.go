@@ -1,11 +1,12 @@
 package epoch
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"reflect"
 
-	"github.com/bytedance/sonic/ast"
+	"github.com/astronomer/epoch/epoch/jsonast"
 	"github.com/gin-gonic/gin"
 )
 
@@ -23,25 +24,31 @@ const (
 // This interface allows consolidation of transformation logic for nested objects and arrays
 type TransformableBody interface {
 	// GetBody returns the AST node representing the body
-	GetBody() *ast.Node
+	GetBody() *jsonast.Node
 	// GetStatusCode returns the HTTP status code (0 for requests)
 	GetStatusCode() int
 	// ShouldSkipInstruction returns true if the instruction should be skipped
 	// based on status code and MigrateHTTPErrors flag
 	ShouldSkipInstruction(migrateHTTPErrors bool) bool
 	// NewForNestedObject creates a new TransformableBody for a nested object
-	NewForNestedObject(body *ast.Node, objectType reflect.Type) TransformableBody
+	NewForNestedObject(body *jsonast.Node, objectType reflect.Type) TransformableBody
 	// NewForNestedArrayItem creates a new TransformableBody for a nested array item
-	NewForNestedArrayItem(body *ast.Node, itemType reflect.Type) TransformableBody
+	NewForNestedArrayItem(body *jsonast.Node, itemType reflect.Type) TransformableBody
 }
 
 // RequestInfo contains information about a Gin request for migration
 type RequestInfo struct {
-	Body        *ast.Node // Sonic AST Node preserves field order
-	Headers     http.Header
-	Cookies     map[string]string
-	QueryParams map[string]string
-	GinContext  *gin.Context
+	Body         *jsonast.Node // Sonic AST Node preserves field order
+	Headers      http.Header
+	Cookies      map[string]string
+	QueryParams  map[string]string // First value of each query parameter, as the client sent it
+	PathParams   map[string]string // Gin route parameters (e.g. ":id")
+	Version      *Version          // Version resolved by the middleware for this request
+	OriginalBody []byte            // Raw request body exactly as the client sent it (before migration)
+	GinContext   *gin.Context
+
+	// ctx is the migration context, bounded by the migration timeout (see Context)
+	ctx context.Context
 
 	// Chain-level schema matching context (prevents re-matching in multi-step migrations)
 	schemaMatched     bool
@@ -55,7 +62,7 @@ type RequestInfo struct {
 }
 
 // NewRequestInfo creates a new RequestInfo from a Gin context
-func NewRequestInfo(c *gin.Context, body *ast.Node) *RequestInfo {
+func NewRequestInfo(c *gin.Context, body *jsonast.Node) *RequestInfo {
 	// Copy headers
 	headers := make(http.Header)
 	if c.Request != nil && c.Request.Header != nil {
@@ -82,22 +89,34 @@ func NewRequestInfo(c *gin.Context, body *ast.Node) *RequestInfo {
 		}
 	}
 
+	// Copy path params
+	pathParams := make(map[string]string, len(c.Params))
+	for _, param := range c.Params {
+		pathParams[param.Key] = param.Value
+	}
+
 	return &RequestInfo{
 		Body:        body,
 		Headers:     headers,
 		Cookies:     cookies,
 		QueryParams: queryParams,
+		PathParams:  pathParams,
+		Version:     GetVersionFromContext(c),
 		GinContext:  c,
 	}
 }
 
 // ResponseInfo contains information about a Gin response for migration
 type ResponseInfo struct {
-	Body       *ast.Node // Sonic AST Node preserves field order
+	Body       *jsonast.Node // Sonic AST Node preserves field order
 	StatusCode int
 	Headers    http.Header
 	GinContext *gin.Context
 
+	// Request describes the request that produced this response (headers, path params,
+	// resolved version and original body). It may be nil outside of the middleware.
+	Request *RequestInfo
+
 	// Chain-level schema matching context (prevents re-matching in multi-step migrations)
 	schemaMatched     bool
 	matchedSchemaType reflect.Type
@@ -107,10 +126,20 @@ type ResponseInfo struct {
 
 	// Nested object type information for step-by-step transformations (NEW)
 	nestedObjectTypes map[string]reflect.Type
+
+	// references collects ExpandReference fields to fetch once the response has migrated
+	references *referenceBatch
+
+	// ctx is the migration context, bounded by the migration timeout (see Context)
+	ctx context.Context
+
+	// snapshots enables Snapshot; snapshot is the body before the current change, serialized
+	snapshots bool
+	snapshot  []byte
 }
 
 // NewResponseInfo creates a new ResponseInfo from a Gin context
-func NewResponseInfo(c *gin.Context, body *ast.Node) *ResponseInfo {
+func NewResponseInfo(c *gin.Context, body *jsonast.Node) *ResponseInfo {
 	// Copy headers
 	headers := make(http.Header)
 	for k, v := range c.Writer.Header() {
@@ -132,10 +161,41 @@ func (r *ResponseInfo) SetCookie(cookie *http.Cookie) {
 	}
 }
 
+// Context returns the context the response is migrated under. Custom transformers doing
+// slow work (lookups, fetches) should honor it: it's done when the client disconnects or
+// the migration timeout expires.
+func (r *ResponseInfo) Context() context.Context {
+	return migrationContext(r.ctx, r.GinContext)
+}
+
 // Helper methods for RequestInfo
 
+// Context returns the context the request is migrated under, done when the client
+// disconnects or the migration timeout expires
+func (r *RequestInfo) Context() context.Context {
+	return migrationContext(r.ctx, r.GinContext)
+}
+
+// SetMigrationValue stashes a value in the per-request migration context
+// so response transformers for the same request can read it back
+func (r *RequestInfo) SetMigrationValue(key string, value interface{}) {
+	SetMigrationValue(r.GinContext, key, value)
+}
+
+// GetMigrationValue reads a value from the per-request migration context
+func (r *RequestInfo) GetMigrationValue(key string) (interface{}, bool) {
+	return GetMigrationValue(r.GinContext, key)
+}
+
+// withBody returns a shallow copy of the RequestInfo scoped to a different body node
+func (r *RequestInfo) withBody(body *jsonast.Node) *RequestInfo {
+	scoped := *r
+	scoped.Body = body
+	return &scoped
+}
+
 // GetField gets a field from the request body
-func (r *RequestInfo) GetField(key string) *ast.Node {
+func (r *RequestInfo) GetField(key string) *jsonast.Node {
 	if r.Body == nil {
 		return nil
 	}
@@ -196,8 +256,52 @@ func (r *RequestInfo) HasField(key string) bool {
 	return field != nil && field.Exists()
 }
 
+// GetString gets a string value at a dotted path (e.g. "profile.name") in the request body
+func (r *RequestInfo) GetString(path string) (string, error) {
+	return GetNodeStringAtPath(r.Body, path)
+}
+
+// GetInt gets an int64 value at a dotted path in the request body
+func (r *RequestInfo) GetInt(path string) (int64, error) {
+	return GetNodeIntAtPath(r.Body, path)
+}
+
+// GetFloat gets a float64 value at a dotted path in the request body
+func (r *RequestInfo) GetFloat(path string) (float64, error) {
+	return GetNodeFloatAtPath(r.Body, path)
+}
+
+// GetBool gets a bool value at a dotted path in the request body
+func (r *RequestInfo) GetBool(path string) (bool, error) {
+	return GetNodeBoolAtPath(r.Body, path)
+}
+
+// SetAtPath sets a value at a dotted path, creating intermediate objects as needed
+func (r *RequestInfo) SetAtPath(path string, value interface{}) error {
+	if r.Body == nil {
+		return errors.New("body is nil")
+	}
+	return SetNodeAtPath(r.Body, path, value)
+}
+
+// DeleteAtPath deletes the field at a dotted path (no-op if missing)
+func (r *RequestInfo) DeleteAtPath(path string) error {
+	return DeleteNodeAtPath(r.Body, path)
+}
+
+// ForEachArrayItem calls fn for each item of the array at a dotted path
+// An empty path iterates the root body when it is an array
+func (r *RequestInfo) ForEachArrayItem(path string, fn func(index int, item *jsonast.Node) error) error {
+	return ForEachNodeArrayItem(r.Body, path, fn)
+}
+
+// MapObject calls fn for each key/value pair of the object at a dotted path
+func (r *RequestInfo) MapObject(path string, fn func(key string, value *jsonast.Node) error) error {
+	return MapNodeObject(r.Body, path, fn)
+}
+
 // GetBody returns the AST node representing the request body
-func (r *RequestInfo) GetBody() *ast.Node {
+func (r *RequestInfo) GetBody() *jsonast.Node {
 	return r.Body
 }
 
@@ -213,7 +317,7 @@ func (r *RequestInfo) ShouldSkipInstruction(migrateHTTPErrors bool) bool {
 }
 
 // NewForNestedObject creates a new RequestInfo for a nested object
-func (r *RequestInfo) NewForNestedObject(body *ast.Node, objectType reflect.Type) TransformableBody {
+func (r *RequestInfo) NewForNestedObject(body *jsonast.Node, objectType reflect.Type) TransformableBody {
 	// Discover nested types within the object type for recursive transformation
 	nestedArrays, nestedObjects := BuildNestedTypeMaps(objectType)
 	return &RequestInfo{
@@ -221,7 +325,11 @@ func (r *RequestInfo) NewForNestedObject(body *ast.Node, objectType reflect.Type
 		Headers:           r.Headers,
 		Cookies:           r.Cookies,
 		QueryParams:       r.QueryParams,
+		PathParams:        r.PathParams,
+		Version:           r.Version,
+		OriginalBody:      r.OriginalBody,
 		GinContext:        r.GinContext,
+		ctx:               r.ctx,
 		schemaMatched:     true,
 		matchedSchemaType: objectType,
 		nestedArrayTypes:  nestedArrays,
@@ -230,7 +338,7 @@ func (r *RequestInfo) NewForNestedObject(body *ast.Node, objectType reflect.Type
 }
 
 // NewForNestedArrayItem creates a new RequestInfo for a nested array item
-func (r *RequestInfo) NewForNestedArrayItem(body *ast.Node, itemType reflect.Type) TransformableBody {
+func (r *RequestInfo) NewForNestedArrayItem(body *jsonast.Node, itemType reflect.Type) TransformableBody {
 	// Discover nested types within the item type for recursive transformation
 	nestedArrays, nestedObjects := BuildNestedTypeMaps(itemType)
 	return &RequestInfo{
@@ -238,7 +346,11 @@ func (r *RequestInfo) NewForNestedArrayItem(body *ast.Node, itemType reflect.Typ
 		Headers:           r.Headers,
 		Cookies:           r.Cookies,
 		QueryParams:       r.QueryParams,
+		PathParams:        r.PathParams,
+		Version:           r.Version,
+		OriginalBody:      r.OriginalBody,
 		GinContext:        r.GinContext,
+		ctx:               r.ctx,
 		schemaMatched:     true,
 		matchedSchemaType: itemType,
 		nestedArrayTypes:  nestedArrays,
@@ -248,16 +360,16 @@ func (r *RequestInfo) NewForNestedArrayItem(body *ast.Node, itemType reflect.Typ
 
 // TransformArrayField applies a transformation to each item in an array field
 // If key is empty, transforms the root body if it's an array
-func (r *RequestInfo) TransformArrayField(key string, transformer func(*ast.Node) error) error {
+func (r *RequestInfo) TransformArrayField(key string, transformer func(*jsonast.Node) error) error {
 	if r.Body == nil {
 		return nil
 	}
 
-	var array *ast.Node
+	var array *jsonast.Node
 
 	if key == "" {
 		// Transform root body if it's an array
-		if r.Body.TypeSafe() == ast.V_ARRAY {
+		if r.Body.TypeSafe() == jsonast.V_ARRAY {
 			array = r.Body
 		} else {
 			// Not an array, nothing to do
@@ -266,7 +378,7 @@ func (r *RequestInfo) TransformArrayField(key string, transformer func(*ast.Node
 	} else {
 		// Transform named array field
 		array = r.Body.Get(key)
-		if array == nil || array.TypeSafe() != ast.V_ARRAY {
+		if array == nil || array.TypeSafe() != jsonast.V_ARRAY {
 			return nil
 		}
 	}
@@ -289,8 +401,25 @@ func (r *RequestInfo) TransformArrayField(key string, transformer func(*ast.Node
 
 // Helper methods for ResponseInfo
 
+// SetMigrationValue stashes a value in the per-request migration context
+func (r *ResponseInfo) SetMigrationValue(key string, value interface{}) {
+	SetMigrationValue(r.GinContext, key, value)
+}
+
+// GetMigrationValue reads a value stashed during request migration for the same request
+func (r *ResponseInfo) GetMigrationValue(key string) (interface{}, bool) {
+	return GetMigrationValue(r.GinContext, key)
+}
+
+// withBody returns a shallow copy of the ResponseInfo scoped to a different body node
+func (r *ResponseInfo) withBody(body *jsonast.Node) *ResponseInfo {
+	scoped := *r
+	scoped.Body = body
+	return &scoped
+}
+
 // GetField gets a field from the response body
-func (r *ResponseInfo) GetField(key string) *ast.Node {
+func (r *ResponseInfo) GetField(key string) *jsonast.Node {
 	if r.Body == nil {
 		return nil
 	}
@@ -351,8 +480,52 @@ func (r *ResponseInfo) HasField(key string) bool {
 	return field != nil && field.Exists()
 }
 
+// GetString gets a string value at a dotted path (e.g. "profile.name") in the response body
+func (r *ResponseInfo) GetString(path string) (string, error) {
+	return GetNodeStringAtPath(r.Body, path)
+}
+
+// GetInt gets an int64 value at a dotted path in the response body
+func (r *ResponseInfo) GetInt(path string) (int64, error) {
+	return GetNodeIntAtPath(r.Body, path)
+}
+
+// GetFloat gets a float64 value at a dotted path in the response body
+func (r *ResponseInfo) GetFloat(path string) (float64, error) {
+	return GetNodeFloatAtPath(r.Body, path)
+}
+
+// GetBool gets a bool value at a dotted path in the response body
+func (r *ResponseInfo) GetBool(path string) (bool, error) {
+	return GetNodeBoolAtPath(r.Body, path)
+}
+
+// SetAtPath sets a value at a dotted path, creating intermediate objects as needed
+func (r *ResponseInfo) SetAtPath(path string, value interface{}) error {
+	if r.Body == nil {
+		return errors.New("body is nil")
+	}
+	return SetNodeAtPath(r.Body, path, value)
+}
+
+// DeleteAtPath deletes the field at a dotted path (no-op if missing)
+func (r *ResponseInfo) DeleteAtPath(path string) error {
+	return DeleteNodeAtPath(r.Body, path)
+}
+
+// ForEachArrayItem calls fn for each item of the array at a dotted path
+// An empty path iterates the root body when it is an array
+func (r *ResponseInfo) ForEachArrayItem(path string, fn func(index int, item *jsonast.Node) error) error {
+	return ForEachNodeArrayItem(r.Body, path, fn)
+}
+
+// MapObject calls fn for each key/value pair of the object at a dotted path
+func (r *ResponseInfo) MapObject(path string, fn func(key string, value *jsonast.Node) error) error {
+	return MapNodeObject(r.Body, path, fn)
+}
+
 // GetBody returns the AST node representing the response body
-func (r *ResponseInfo) GetBody() *ast.Node {
+func (r *ResponseInfo) GetBody() *jsonast.Node {
 	return r.Body
 }
 
@@ -368,7 +541,7 @@ func (r *ResponseInfo) ShouldSkipInstruction(migrateHTTPErrors bool) bool {
 }
 
 // NewForNestedObject creates a new ResponseInfo for a nested object
-func (r *ResponseInfo) NewForNestedObject(body *ast.Node, objectType reflect.Type) TransformableBody {
+func (r *ResponseInfo) NewForNestedObject(body *jsonast.Node, objectType reflect.Type) TransformableBody {
 	// Discover nested types within the object type for recursive transformation
 	nestedArrays, nestedObjects := BuildNestedTypeMaps(objectType)
 	return &ResponseInfo{
@@ -376,6 +549,10 @@ func (r *ResponseInfo) NewForNestedObject(body *ast.Node, objectType reflect.Typ
 		StatusCode:        r.StatusCode,
 		Headers:           r.Headers,
 		GinContext:        r.GinContext,
+		Request:           r.Request,
+		references:        r.references,
+		ctx:               r.ctx,
+		snapshots:         r.snapshots,
 		schemaMatched:     true,
 		matchedSchemaType: objectType,
 		nestedArrayTypes:  nestedArrays,
@@ -384,7 +561,7 @@ func (r *ResponseInfo) NewForNestedObject(body *ast.Node, objectType reflect.Typ
 }
 
 // NewForNestedArrayItem creates a new ResponseInfo for a nested array item
-func (r *ResponseInfo) NewForNestedArrayItem(body *ast.Node, itemType reflect.Type) TransformableBody {
+func (r *ResponseInfo) NewForNestedArrayItem(body *jsonast.Node, itemType reflect.Type) TransformableBody {
 	// Discover nested types within the item type for recursive transformation
 	nestedArrays, nestedObjects := BuildNestedTypeMaps(itemType)
 	return &ResponseInfo{
@@ -392,6 +569,10 @@ func (r *ResponseInfo) NewForNestedArrayItem(body *ast.Node, itemType reflect.Ty
 		StatusCode:        r.StatusCode,
 		Headers:           r.Headers,
 		GinContext:        r.GinContext,
+		Request:           r.Request,
+		references:        r.references,
+		ctx:               r.ctx,
+		snapshots:         r.snapshots,
 		schemaMatched:     true,
 		matchedSchemaType: itemType,
 		nestedArrayTypes:  nestedArrays,
@@ -401,16 +582,16 @@ func (r *ResponseInfo) NewForNestedArrayItem(body *ast.Node, itemType reflect.Ty
 
 // TransformArrayField applies a transformation to each item in an array field
 // If key is empty, transforms the root body if it's an array
-func (r *ResponseInfo) TransformArrayField(key string, transformer func(*ast.Node) error) error {
+func (r *ResponseInfo) TransformArrayField(key string, transformer func(*jsonast.Node) error) error {
 	if r.Body == nil {
 		return nil
 	}
 
-	var array *ast.Node
+	var array *jsonast.Node
 
 	if key == "" {
 		// Transform root body if it's an array
-		if r.Body.TypeSafe() == ast.V_ARRAY {
+		if r.Body.TypeSafe() == jsonast.V_ARRAY {
 			array = r.Body
 		} else {
 			// Not an array, nothing to do
@@ -419,7 +600,7 @@ func (r *ResponseInfo) TransformArrayField(key string, transformer func(*ast.Nod
 	} else {
 		// Transform named array field
 		array = r.Body.Get(key)
-		if array == nil || array.TypeSafe() != ast.V_ARRAY {
+		if array == nil || array.TypeSafe() != jsonast.V_ARRAY {
 			return nil
 		}
 	}
@@ -446,13 +627,13 @@ func (r *ResponseInfo) TransformArrayField(key string, transformer func(*ast.Nod
 // nested array migrations use type-aware transformations (transformNestedArrayItemsForSingleStep)
 // which apply migrations step-by-step with proper type information. Use this method only for
 // custom migration logic in ResponseCustom operations where you need non-type-aware recursion.
-func (r *ResponseInfo) TransformNestedArrays(transformer func(*ast.Node) error) error {
+func (r *ResponseInfo) TransformNestedArrays(transformer func(*jsonast.Node) error) error {
 	if r.Body == nil {
 		return nil
 	}
 
 	// Only process if the body is an object
-	if r.Body.TypeSafe() != ast.V_OBJECT {
+	if r.Body.TypeSafe() != jsonast.V_OBJECT {
 		return nil
 	}
 
@@ -460,7 +641,7 @@ func (r *ResponseInfo) TransformNestedArrays(transformer func(*ast.Node) error)
 }
 
 // transformNestedArraysRecursive is a helper that recursively transforms arrays within a node
-func transformNestedArraysRecursive(node *ast.Node, transformer func(*ast.Node) error) error {
+func transformNestedArraysRecursive(node *jsonast.Node, transformer func(*jsonast.Node) error) error {
 	if node == nil {
 		return nil
 	}
@@ -468,7 +649,7 @@ func transformNestedArraysRecursive(node *ast.Node, transformer func(*ast.Node)
 	nodeType := node.TypeSafe()
 
 	switch nodeType {
-	case ast.V_OBJECT:
+	case jsonast.V_OBJECT:
 		// Convert to map to get all keys
 		objMap, err := node.Map()
 		if err != nil {
@@ -484,7 +665,7 @@ func transformNestedArraysRecursive(node *ast.Node, transformer func(*ast.Node)
 
 			valueType := value.TypeSafe()
 
-			if valueType == ast.V_ARRAY {
+			if valueType == jsonast.V_ARRAY {
 				// Found an array - transform each item
 				length, err := value.Len()
 				if err != nil {
@@ -503,13 +684,13 @@ func transformNestedArraysRecursive(node *ast.Node, transformer func(*ast.Node)
 					}
 
 					// Recursively process nested structures within the array item
-					if item.TypeSafe() == ast.V_OBJECT {
+					if item.TypeSafe() == jsonast.V_OBJECT {
 						if err := transformNestedArraysRecursive(item, transformer); err != nil {
 							return err
 						}
 					}
 				}
-			} else if valueType == ast.V_OBJECT {
+			} else if valueType == jsonast.V_OBJECT {
 				// Recursively process nested objects
 				if err := transformNestedArraysRecursive(value, transformer); err != nil {
 					return err
@@ -517,7 +698,7 @@ func transformNestedArraysRecursive(node *ast.Node, transformer func(*ast.Node)
 			}
 		}
 
-	case ast.V_ARRAY:
+	case jsonast.V_ARRAY:
 		// Handle arrays at the current level
 		length, err := node.Len()
 		if err != nil {
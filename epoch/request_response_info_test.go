@@ -5,8 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 
-	"github.com/bytedance/sonic"
-	"github.com/bytedance/sonic/ast"
+	"github.com/astronomer/epoch/epoch/jsonast"
 	"github.com/gin-gonic/gin"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -35,7 +34,7 @@ var _ = Describe("MigrationTypes", func() {
 			c.Request = req
 
 			bodyJSON := `{"name":"test","value":42}`
-			bodyNode, _ := sonic.Get([]byte(bodyJSON))
+			bodyNode, _ := getNode([]byte(bodyJSON))
 			requestInfo = NewRequestInfo(c, &bodyNode)
 		})
 
@@ -92,6 +91,19 @@ var _ = Describe("MigrationTypes", func() {
 				// Should take the first value when multiple values exist
 				Expect(requestInfo.QueryParams).To(HaveKeyWithValue("param", "value1"))
 			})
+
+			It("should copy path parameters and the resolved version", func() {
+				req := httptest.NewRequest("GET", "/users/42", nil)
+				c, _ := gin.CreateTestContext(httptest.NewRecorder())
+				c.Request = req
+				c.Params = gin.Params{{Key: "id", Value: "42"}}
+				v1, _ := NewSemverVersion("1.0.0")
+				c.Set(versionContextKey, v1)
+
+				requestInfo := NewRequestInfo(c, nil)
+				Expect(requestInfo.PathParams).To(HaveKeyWithValue("id", "42"))
+				Expect(requestInfo.Version).To(Equal(v1))
+			})
 		})
 	})
 
@@ -111,7 +123,7 @@ var _ = Describe("MigrationTypes", func() {
 			c.Header("X-Custom-Header", "custom-value")
 
 			bodyJSON := `{"id":1,"name":"test"}`
-			bodyNode, _ := sonic.Get([]byte(bodyJSON))
+			bodyNode, _ := getNode([]byte(bodyJSON))
 			responseInfo = NewResponseInfo(c, &bodyNode)
 		})
 
@@ -188,11 +200,11 @@ var _ = Describe("MigrationTypes", func() {
 						"total": 2
 					}
 				}`
-				bodyNode, _ := sonic.Get([]byte(bodyJSON))
+				bodyNode, _ := getNode([]byte(bodyJSON))
 				testResponseInfo := NewResponseInfo(c, &bodyNode)
 
 				// Transform function that renames "name" to "title"
-				transformer := func(node *ast.Node) error {
+				transformer := func(node *jsonast.Node) error {
 					if node.Get("name").Exists() {
 						nameValue := node.Get("name")
 						value, _ := nameValue.String()
@@ -236,11 +248,11 @@ var _ = Describe("MigrationTypes", func() {
 						}
 					]
 				}`
-				bodyNode, _ := sonic.Get([]byte(bodyJSON))
+				bodyNode, _ := getNode([]byte(bodyJSON))
 				testResponseInfo := NewResponseInfo(c, &bodyNode)
 
 				// Transform function that adds "processed" field
-				transformer := func(node *ast.Node) error {
+				transformer := func(node *jsonast.Node) error {
 					return SetNodeField(node, "processed", true)
 				}
 
@@ -264,11 +276,11 @@ var _ = Describe("MigrationTypes", func() {
 			It("should not process non-object bodies", func() {
 				// Test with array body (should not process)
 				bodyJSON := `[{"name": "Item 1"}, {"name": "Item 2"}]`
-				bodyNode, _ := sonic.Get([]byte(bodyJSON))
+				bodyNode, _ := getNode([]byte(bodyJSON))
 				testResponseInfo := NewResponseInfo(c, &bodyNode)
 
 				transformerCalled := false
-				transformer := func(node *ast.Node) error {
+				transformer := func(node *jsonast.Node) error {
 					transformerCalled = true
 					return nil
 				}
@@ -281,7 +293,7 @@ var _ = Describe("MigrationTypes", func() {
 			It("should handle nil body gracefully", func() {
 				testResponseInfo := &ResponseInfo{Body: nil}
 
-				transformer := func(node *ast.Node) error {
+				transformer := func(node *jsonast.Node) error {
 					return nil
 				}
 
@@ -295,10 +307,10 @@ var _ = Describe("MigrationTypes", func() {
 						{"name": "Item 1"}
 					]
 				}`
-				bodyNode, _ := sonic.Get([]byte(bodyJSON))
+				bodyNode, _ := getNode([]byte(bodyJSON))
 				testResponseInfo := NewResponseInfo(c, &bodyNode)
 
-				transformer := func(node *ast.Node) error {
+				transformer := func(node *jsonast.Node) error {
 					return errors.New("transformation failed")
 				}
 
@@ -0,0 +1,183 @@
+package epoch
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/astronomer/epoch/epoch/jsonast"
+)
+
+// ============================================================================
+// Structural Operations - change a field between a keyed object and an array
+// ============================================================================
+
+// RequestObjectToArray converts a keyed object into an array when request migrates from client to HEAD
+// Use case: older clients send {"phones": {"home": {...}}}, HEAD expects [{"type": "home", ...}]
+type RequestObjectToArray struct {
+	Name     string // Field holding the object/array
+	KeyField string // Item field that stores the object key
+}
+
+func (op *RequestObjectToArray) ApplyToRequest(node *jsonast.Node) error {
+	return objectToArray(node, op.Name, op.KeyField)
+}
+
+func (op *RequestObjectToArray) GetFieldMapping() map[string]string {
+	return nil // No field rename
+}
+
+// Inverse returns the opposite operation for schema generation
+func (op *RequestObjectToArray) Inverse() RequestToNextVersionOperation {
+	return &RequestArrayToObject{Name: op.Name, KeyField: op.KeyField}
+}
+
+// RequestArrayToObject converts an array into a keyed object when request migrates from client to HEAD
+// Use case: older clients send [{"type": "home", ...}], HEAD expects {"home": {...}}
+type RequestArrayToObject struct {
+	Name     string // Field holding the array/object
+	KeyField string // Item field whose value becomes the object key
+}
+
+func (op *RequestArrayToObject) ApplyToRequest(node *jsonast.Node) error {
+	return arrayToObject(node, op.Name, op.KeyField)
+}
+
+func (op *RequestArrayToObject) GetFieldMapping() map[string]string {
+	return nil // No field rename
+}
+
+// Inverse returns the opposite operation for schema generation
+func (op *RequestArrayToObject) Inverse() RequestToNextVersionOperation {
+	return &RequestObjectToArray{Name: op.Name, KeyField: op.KeyField}
+}
+
+// ResponseArrayToObject converts an array into a keyed object when response migrates from HEAD to client
+// Use case: HEAD returns [{"type": "home", ...}], older clients expect {"home": {...}}
+type ResponseArrayToObject struct {
+	Name     string // Field holding the array/object
+	KeyField string // Item field whose value becomes the object key
+}
+
+func (op *ResponseArrayToObject) ApplyToResponse(node *jsonast.Node) error {
+	return arrayToObject(node, op.Name, op.KeyField)
+}
+
+func (op *ResponseArrayToObject) GetFieldMapping() map[string]string {
+	return nil // No field rename
+}
+
+// ResponseObjectToArray converts a keyed object into an array when response migrates from HEAD to client
+// Use case: HEAD returns {"home": {...}}, older clients expect [{"type": "home", ...}]
+type ResponseObjectToArray struct {
+	Name     string // Field holding the object/array
+	KeyField string // Item field that stores the object key
+}
+
+func (op *ResponseObjectToArray) ApplyToResponse(node *jsonast.Node) error {
+	return objectToArray(node, op.Name, op.KeyField)
+}
+
+func (op *ResponseObjectToArray) GetFieldMapping() map[string]string {
+	return nil // No field rename
+}
+
+// arrayToObject replaces the array of objects at field with an object keyed by each item's keyField
+// The key field is removed from the items. Fields that aren't arrays are left alone.
+func arrayToObject(node *jsonast.Node, field, keyField string) error {
+	if node == nil {
+		return nil
+	}
+	array := node.Get(field)
+	if !IsNodeArray(array) {
+		return nil
+	}
+	if err := array.LoadAll(); err != nil {
+		return fmt.Errorf("failed to load field %s: %w", field, err)
+	}
+
+	length, err := array.Len()
+	if err != nil {
+		return err
+	}
+	pairs := make([]jsonast.Pair, 0, length)
+	seen := make(map[string]bool, length)
+	for i := 0; i < length; i++ {
+		item := array.Index(i)
+		if !IsNodeObject(item) {
+			return fmt.Errorf("field %s[%d] is not an object", field, i)
+		}
+		key, err := reshapeKey(item.Get(keyField))
+		if err != nil {
+			return fmt.Errorf("field %s[%d].%s: %w", field, i, keyField, err)
+		}
+		if seen[key] {
+			return fmt.Errorf("field %s has duplicate key %q", field, key)
+		}
+		seen[key] = true
+
+		if _, err := item.Unset(keyField); err != nil {
+			return fmt.Errorf("failed to remove field %s[%d].%s: %w", field, i, keyField, err)
+		}
+		pairs = append(pairs, jsonast.NewPair(key, *item))
+	}
+
+	_, err = node.Set(field, jsonast.NewObject(pairs))
+	return err
+}
+
+// objectToArray replaces the object of objects at field with an array, storing each key in keyField
+// The key field comes first in each item. Fields that aren't objects are left alone.
+func objectToArray(node *jsonast.Node, field, keyField string) error {
+	if node == nil {
+		return nil
+	}
+	object := node.Get(field)
+	if !IsNodeObject(object) {
+		return nil
+	}
+	if err := object.LoadAll(); err != nil {
+		return fmt.Errorf("failed to load field %s: %w", field, err)
+	}
+
+	iter, err := object.Properties()
+	if err != nil {
+		return err
+	}
+	var items []jsonast.Node
+	var pair jsonast.Pair
+	for iter.Next(&pair) {
+		if !IsNodeObject(&pair.Value) {
+			return fmt.Errorf("field %s.%s is not an object", field, pair.Key)
+		}
+		members := []jsonast.Pair{jsonast.NewPair(keyField, jsonast.NewString(pair.Key))}
+		memberIter, err := pair.Value.Properties()
+		if err != nil {
+			return err
+		}
+		var member jsonast.Pair
+		for memberIter.Next(&member) {
+			if member.Key != keyField {
+				members = append(members, member)
+			}
+		}
+		items = append(items, jsonast.NewObject(members))
+	}
+
+	_, err = node.Set(field, jsonast.NewArray(items))
+	return err
+}
+
+// reshapeKey returns the object key for an item's key field
+// Strings are used as is; numbers use their JSON representation.
+func reshapeKey(key *jsonast.Node) (string, error) {
+	if key == nil || !key.Exists() {
+		return "", errors.New("key is missing")
+	}
+	switch key.TypeSafe() {
+	case jsonast.V_STRING:
+		return key.String()
+	case jsonast.V_NUMBER:
+		return key.Raw()
+	}
+	return "", errors.New("key must be a string or number")
+}
@@ -0,0 +1,131 @@
+package epoch
+
+import (
+	"net/http/httptest"
+	"strings"
+
+	"github.com/astronomer/epoch/epoch/jsonast"
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type reshapePhone struct {
+	Type   string `json:"type"`
+	Number string `json:"number"`
+}
+
+type reshapeContact struct {
+	Name   string         `json:"name"`
+	Phones []reshapePhone `json:"phones"`
+}
+
+var _ = Describe("Structural operations", func() {
+	var v1, v2 *Version
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		v1, _ = NewDateVersion("2024-01-01")
+		v2, _ = NewDateVersion("2024-06-01")
+	})
+
+	apply := func(fn func(node *jsonast.Node) error, body string) (string, error) {
+		node, err := DefaultJSONEngine().Parse([]byte(body))
+		Expect(err).NotTo(HaveOccurred())
+		if err := fn(node); err != nil {
+			return "", err
+		}
+		out, err := node.MarshalJSON()
+		Expect(err).NotTo(HaveOccurred())
+		return string(out), nil
+	}
+
+	Describe("ArrayToObject", func() {
+		op := &ResponseArrayToObject{Name: "phones", KeyField: "type"}
+
+		It("should key items by the key field", func() {
+			out, err := apply(op.ApplyToResponse, `{"phones":[{"type":"home","number":"1"},{"number":"2","type":"work"}]}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal(`{"phones":{"home":{"number":"1"},"work":{"number":"2"}}}`))
+		})
+
+		It("should use numeric keys as strings", func() {
+			out, err := apply(op.ApplyToResponse, `{"phones":[{"type":7,"number":"1"}]}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal(`{"phones":{"7":{"number":"1"}}}`))
+		})
+
+		It("should leave missing and non-array fields alone", func() {
+			for _, body := range []string{`{"name":"Jane"}`, `{"phones":null}`, `{"phones":{"home":{}}}`} {
+				out, err := apply(op.ApplyToResponse, body)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(out).To(Equal(body))
+			}
+		})
+
+		It("should reject items without a usable key", func() {
+			_, err := apply(op.ApplyToResponse, `{"phones":[{"number":"1"}]}`)
+			Expect(err).To(MatchError(ContainSubstring("phones[0].type: key is missing")))
+
+			_, err = apply(op.ApplyToResponse, `{"phones":[{"type":"home"},{"type":"home"}]}`)
+			Expect(err).To(MatchError(ContainSubstring(`duplicate key "home"`)))
+
+			_, err = apply(op.ApplyToResponse, `{"phones":["home"]}`)
+			Expect(err).To(MatchError(ContainSubstring("phones[0] is not an object")))
+		})
+	})
+
+	Describe("ObjectToArray", func() {
+		op := &RequestObjectToArray{Name: "phones", KeyField: "type"}
+
+		It("should store each key in the key field", func() {
+			out, err := apply(op.ApplyToRequest, `{"phones":{"home":{"number":"1"},"work":{"number":"2","type":"stale"}}}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal(`{"phones":[{"type":"home","number":"1"},{"type":"work","number":"2"}]}`))
+		})
+
+		It("should reject values that aren't objects", func() {
+			_, err := apply(op.ApplyToRequest, `{"phones":{"home":"1"}}`)
+			Expect(err).To(MatchError(ContainSubstring("phones.home is not an object")))
+		})
+
+		It("should invert to ArrayToObject", func() {
+			Expect(op.Inverse()).To(Equal(&RequestArrayToObject{Name: "phones", KeyField: "type"}))
+		})
+	})
+
+	It("should migrate a keyed map in v1 to the HEAD array and back", func() {
+		change := NewVersionChangeBuilder(v1, v2).
+			ForType(reshapeContact{}).
+			RequestToNextVersion().
+			ObjectToArray("phones", "type").
+			ResponseToPreviousVersion().
+			ArrayToObject("phones", "type").
+			Build()
+
+		epochInstance, err := NewEpoch().WithVersions(v1, v2).WithHeadVersion().WithChanges(change).Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		var received reshapeContact
+		router := gin.New()
+		router.Use(epochInstance.Middleware())
+		router.POST("/contacts", epochInstance.WrapHandler(func(c *gin.Context) {
+			Expect(c.ShouldBindJSON(&received)).To(Succeed())
+			c.JSON(200, received)
+		}).Accepts(reshapeContact{}).Returns(reshapeContact{}).ToHandlerFunc("POST", "/contacts"))
+
+		req := httptest.NewRequest("POST", "/contacts", strings.NewReader(`{"name":"Jane","phones":{"home":{"number":"1"}}}`))
+		req.Header.Set("X-API-Version", "2024-01-01")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		Expect(recorder.Code).To(Equal(200))
+		Expect(received.Phones).To(Equal([]reshapePhone{{Type: "home", Number: "1"}}))
+		Expect(recorder.Body.String()).To(Equal(`{"name":"Jane","phones":{"home":{"number":"1"}}}`))
+	})
+
+	It("should describe the operations", func() {
+		Expect(DescribeOperation(&ResponseArrayToObject{Name: "phones", KeyField: "type"}).Description).
+			To(Equal("Convert field phones from an array to an object keyed by type"))
+	})
+})
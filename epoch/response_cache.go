@@ -0,0 +1,233 @@
+package epoch
+
+import (
+	"bytes"
+	"container/list"
+	"net/http"
+	"slices"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CachedResponse is a migrated response kept by a ResponseCache
+type CachedResponse struct {
+	Status int
+	Body   []byte
+
+	// Header holds the headers migrating the response set, changed or removed (nil values)
+	Header http.Header
+
+	// Generation is the migration chain generation the body was migrated with. Entries from
+	// another generation, e.g. before a RegisterChange, are treated as misses.
+	Generation uint64
+}
+
+// ResponseCache stores migrated responses for endpoints using HandlerWrapper.CacheResponses.
+// Implementations must be safe for concurrent use; NewMemoryResponseCache keeps them in process,
+// and shared stores such as Redis only need to serialize CachedResponse.
+type ResponseCache interface {
+	Get(key string) (CachedResponse, bool)
+	Set(key string, response CachedResponse)
+	Delete(key string)
+}
+
+// CacheKeyFunc derives a request's cache key, e.g. a catalog ID or tenant, so requests with the
+// same key share a migrated response. Returning false leaves the request uncached.
+type CacheKeyFunc func(c *gin.Context) (string, bool)
+
+// WithResponseCache stores the migrated responses of endpoints that opt in with
+// HandlerWrapper.CacheResponses, so expensive migrations of mostly static payloads, such as
+// catalogs or config blobs, run once per endpoint, version and key rather than per request
+func (cb *EpochBuilder) WithResponseCache(cache ResponseCache) *EpochBuilder {
+	cb.responseCache = cache
+	return cb
+}
+
+// CacheResponses caches this endpoint's successful migrated responses by key, in the Epoch's
+// WithResponseCache store. The handler still runs on every request, since its headers and
+// side effects aren't cached, but a hit skips migrating its body. Invalidate entries whose
+// HEAD payload changed with Epoch.InvalidateCachedResponse.
+func (hw *HandlerWrapper) CacheResponses(key CacheKeyFunc) *HandlerWrapper {
+	hw.cacheKey = key
+	return hw
+}
+
+// WithResponseCache caches the handler's successful migrated responses by key
+func (vah *VersionAwareHandler) WithResponseCache(cache ResponseCache, key CacheKeyFunc) *VersionAwareHandler {
+	vah.responseCache = cache
+	vah.cacheKey = key
+	return vah
+}
+
+// InvalidateCachedResponse drops the endpoint's cached responses for key in every version, e.g.
+// from the handler that updates the catalog the key names. It does nothing without
+// WithResponseCache.
+func (c *Epoch) InvalidateCachedResponse(method, pathPattern, key string) {
+	if c.responseCache == nil {
+		return
+	}
+	for _, version := range c.versionBundle.GetVersions() {
+		if !version.IsHead {
+			c.responseCache.Delete(responseCacheKey(method, pathPattern, version, key))
+		}
+	}
+}
+
+// responseCacheKey identifies an endpoint's response for a version and cache key
+func responseCacheKey(method, pathPattern string, version *Version, key string) string {
+	return endpointKey(method, pathPattern) + " " + version.String() + " " + key
+}
+
+// cachedResponseKey returns the store key of the request's migrated response, or false when
+// the response isn't cached: no cache, no key, an unsuccessful status or an awaited job result
+func (vah *VersionAwareHandler) cachedResponseKey(c *gin.Context, endpointDef *EndpointDefinition, version *Version, rc *ResponseCapture, awaited bool) (string, bool) {
+	if vah.responseCache == nil || vah.cacheKey == nil || awaited || rc.statusCode >= 400 || len(rc.body) == 0 {
+		return "", false
+	}
+	key, ok := vah.cacheKey(c)
+	if !ok {
+		return "", false
+	}
+	return responseCacheKey(endpointDef.Method, endpointDef.PathPattern, version, key), true
+}
+
+// serveCachedResponse writes the cached response for key, reporting false on a miss
+func (vah *VersionAwareHandler) serveCachedResponse(c *gin.Context, key string) bool {
+	cached, ok := vah.responseCache.Get(key)
+	if !ok || cached.Generation != vah.migrationChain.Generation() {
+		return false
+	}
+	header := c.Writer.Header()
+	for name, values := range cached.Header {
+		if values == nil {
+			header.Del(name)
+		} else {
+			header[name] = values
+		}
+	}
+	c.Writer.WriteHeader(cached.Status)
+	_, _ = c.Writer.Write(cached.Body)
+	return true
+}
+
+// cacheRecorder writes a migrated response through to the client while keeping what
+// migrating it wrote, to store once it succeeded
+type cacheRecorder struct {
+	gin.ResponseWriter
+	header http.Header // Headers before migrating
+	body   bytes.Buffer
+}
+
+// recordResponse makes the capture's writer keep what migrating the response writes
+func recordResponse(rc *ResponseCapture) *cacheRecorder {
+	recorder := &cacheRecorder{ResponseWriter: rc.ResponseWriter, header: rc.Header().Clone()}
+	rc.ResponseWriter = recorder
+	return recorder
+}
+
+func (w *cacheRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *cacheRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// store saves the recorded response under key if migrating it produced a successful one
+func (w *cacheRecorder) store(vah *VersionAwareHandler, key string) {
+	if w.Status() >= 400 {
+		return
+	}
+	header := make(http.Header)
+	current := w.Header()
+	for name, values := range current {
+		if before, ok := w.header[name]; !ok || !slices.Equal(before, values) {
+			header[name] = append([]string(nil), values...)
+		}
+	}
+	for name := range w.header {
+		if _, ok := current[name]; !ok {
+			header[name] = nil
+		}
+	}
+	vah.responseCache.Set(key, CachedResponse{
+		Status:     w.Status(),
+		Body:       bytes.Clone(w.body.Bytes()),
+		Header:     header,
+		Generation: vah.migrationChain.Generation(),
+	})
+}
+
+// MemoryResponseCache is an in-process ResponseCache that evicts the least recently used
+// response once it holds maxEntries
+type MemoryResponseCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List // Most recently used first
+	entries    map[string]*list.Element
+}
+
+// memoryCacheEntry is an element of MemoryResponseCache's order list
+type memoryCacheEntry struct {
+	key      string
+	response CachedResponse
+}
+
+// NewMemoryResponseCache creates an in-process cache holding up to maxEntries responses, or
+// any number when maxEntries is 0
+func NewMemoryResponseCache(maxEntries int) *MemoryResponseCache {
+	return &MemoryResponseCache{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Get returns the response stored under key
+func (m *MemoryResponseCache) Get(key string) (CachedResponse, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	element, ok := m.entries[key]
+	if !ok {
+		return CachedResponse{}, false
+	}
+	m.order.MoveToFront(element)
+	return element.Value.(*memoryCacheEntry).response, true
+}
+
+// Set stores response under key, evicting the least recently used response when full
+func (m *MemoryResponseCache) Set(key string, response CachedResponse) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if element, ok := m.entries[key]; ok {
+		element.Value.(*memoryCacheEntry).response = response
+		m.order.MoveToFront(element)
+		return
+	}
+	m.entries[key] = m.order.PushFront(&memoryCacheEntry{key: key, response: response})
+	if m.maxEntries > 0 && m.order.Len() > m.maxEntries {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryCacheEntry).key)
+	}
+}
+
+// Delete drops the response stored under key
+func (m *MemoryResponseCache) Delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if element, ok := m.entries[key]; ok {
+		m.order.Remove(element)
+		delete(m.entries, key)
+	}
+}
+
+// Len returns how many responses are stored
+func (m *MemoryResponseCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.order.Len()
+}
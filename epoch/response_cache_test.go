@@ -0,0 +1,123 @@
+package epoch
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type cachedCatalog struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+var _ = Describe("Response cache", func() {
+	var (
+		v1, v2        *Version
+		epochInstance *Epoch
+		router        *gin.Engine
+		cache         *MemoryResponseCache
+		migrations    int
+		served        int
+	)
+
+	BeforeEach(func() {
+		v1, _ = NewDateVersion("2024-01-01")
+		v2, _ = NewDateVersion("2025-01-01")
+		migrations, served = 0, 0
+		change := NewVersionChangeBuilder(v1, v2).
+			ForType(cachedCatalog{}).
+			ResponseToPreviousVersion().
+			RenameField("title", "name").
+			AddHeader("X-Catalog-Format", "legacy").
+			RemoveHeader("X-Etag").
+			Custom(func(*ResponseInfo) error {
+				migrations++
+				return nil
+			}).
+			Build()
+		cache = NewMemoryResponseCache(0)
+		var err error
+		epochInstance, err = NewEpoch().WithVersions(v1, v2).WithHeadVersion().WithChanges(change).
+			WithResponseCache(cache).Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		gin.SetMode(gin.TestMode)
+		router = gin.New()
+		router.Use(epochInstance.Middleware())
+		router.GET("/catalogs/:id", epochInstance.WrapHandler(func(c *gin.Context) {
+			served++
+			c.Header("X-Etag", "abc")
+			c.Header("X-Request", c.Query("n"))
+			c.JSON(http.StatusOK, cachedCatalog{ID: c.Param("id"), Title: "Spring"})
+		}).Returns(cachedCatalog{}).CacheResponses(func(c *gin.Context) (string, bool) {
+			return c.Param("id"), c.Query("nocache") == ""
+		}).ToHandlerFunc("GET", "/catalogs/:id"))
+		router.GET("/uncached", epochInstance.WrapHandler(func(c *gin.Context) {
+			c.JSON(http.StatusOK, cachedCatalog{ID: "u", Title: "Spring"})
+		}).Returns(cachedCatalog{}).ToHandlerFunc("GET", "/uncached"))
+	})
+
+	get := func(path, version string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-API-Version", version)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	It("should migrate a response once per endpoint, version and key", func() {
+		first := get("/catalogs/1?n=a", "2024-01-01")
+		second := get("/catalogs/1?n=b", "2024-01-01")
+
+		Expect(migrations).To(Equal(1))
+		Expect(served).To(Equal(2))
+		for _, recorder := range []*httptest.ResponseRecorder{first, second} {
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(MatchJSON(`{"id":"1","name":"Spring"}`))
+			Expect(recorder.Header().Get("X-Catalog-Format")).To(Equal("legacy"))
+			Expect(recorder.Header().Values("X-Etag")).To(BeEmpty())
+		}
+		Expect(second.Header().Get("X-Request")).To(Equal("b"))
+
+		get("/catalogs/2", "2024-01-01")
+		Expect(migrations).To(Equal(2))
+		Expect(cache.Len()).To(Equal(2))
+	})
+
+	It("should leave HEAD, unkeyed requests and other endpoints uncached", func() {
+		Expect(get("/catalogs/1", "head").Body.String()).To(MatchJSON(`{"id":"1","title":"Spring"}`))
+		get("/catalogs/1?nocache=1", "2024-01-01")
+		get("/catalogs/1?nocache=1", "2024-01-01")
+		get("/uncached", "2024-01-01")
+		get("/uncached", "2024-01-01")
+		Expect(migrations).To(Equal(4))
+		Expect(cache.Len()).To(BeZero())
+	})
+
+	It("should migrate again once the key is invalidated", func() {
+		get("/catalogs/1", "2024-01-01")
+		epochInstance.InvalidateCachedResponse("GET", "/catalogs/:id", "1")
+		Expect(cache.Len()).To(BeZero())
+
+		Expect(get("/catalogs/1", "2024-01-01").Body.String()).To(MatchJSON(`{"id":"1","name":"Spring"}`))
+		Expect(migrations).To(Equal(2))
+	})
+
+	It("should evict the least recently used response when full", func() {
+		small := NewMemoryResponseCache(2)
+		small.Set("a", CachedResponse{Body: []byte("a")})
+		small.Set("b", CachedResponse{Body: []byte("b")})
+		_, _ = small.Get("a")
+		small.Set("c", CachedResponse{Body: []byte("c")})
+
+		_, ok := small.Get("b")
+		Expect(ok).To(BeFalse())
+		_, ok = small.Get("a")
+		Expect(ok).To(BeTrue())
+		Expect(small.Len()).To(Equal(2))
+	})
+})
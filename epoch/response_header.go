@@ -0,0 +1,147 @@
+package epoch
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/astronomer/epoch/epoch/jsonast"
+)
+
+// ============================================================================
+// Response Header Operations - TO PREVIOUS VERSION (HEAD→Client)
+// ============================================================================
+// Header operations change ResponseInfo.Headers, which the middleware writes back to the
+// response. They run with the other operations of the endpoint's response type.
+
+// ResponseAddHeader sets a header when response migrates from HEAD to client
+// Use case: older clients expect a header HEAD no longer sends
+type ResponseAddHeader struct {
+	Name  string
+	Value string
+}
+
+func (op *ResponseAddHeader) ApplyToResponse(node *jsonast.Node) error {
+	return nil // Headers need the ResponseInfo
+}
+
+// ApplyToResponseInfo sets the header unless the handler already did
+func (op *ResponseAddHeader) ApplyToResponseInfo(resp *ResponseInfo) error {
+	if resp == nil || resp.Headers == nil || resp.Headers.Get(op.Name) != "" {
+		return nil
+	}
+	resp.Headers.Set(op.Name, op.Value)
+	return nil
+}
+
+func (op *ResponseAddHeader) GetFieldMapping() map[string]string {
+	return nil
+}
+
+// ResponseAddHeaderFromField sets a header from a body field when response migrates from HEAD to client
+// Use case: v1 clients read the total from X-Total-Count, HEAD returns it as "meta.total"
+type ResponseAddHeaderFromField struct {
+	Name  string
+	Field string // Dotted path of the body field
+}
+
+func (op *ResponseAddHeaderFromField) ApplyToResponse(node *jsonast.Node) error {
+	return nil // Headers need the ResponseInfo
+}
+
+// ApplyToResponseInfo copies a scalar field into the header; missing fields leave the header unset
+func (op *ResponseAddHeaderFromField) ApplyToResponseInfo(resp *ResponseInfo) error {
+	if resp == nil || resp.Headers == nil || resp.Headers.Get(op.Name) != "" {
+		return nil
+	}
+	field := GetNodeAtPath(resp.Body, op.Field)
+	if field == nil {
+		return nil
+	}
+
+	var value string
+	var err error
+	switch field.TypeSafe() {
+	case jsonast.V_STRING:
+		value, err = field.String()
+	case jsonast.V_NUMBER, jsonast.V_TRUE, jsonast.V_FALSE:
+		value, err = field.Raw()
+	default:
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read field %s for header %s: %w", op.Field, op.Name, err)
+	}
+	resp.Headers.Set(op.Name, value)
+	return nil
+}
+
+func (op *ResponseAddHeaderFromField) GetFieldMapping() map[string]string {
+	return nil
+}
+
+// ResponseRemoveHeader removes a header when response migrates from HEAD to client
+// Use case: HEAD added a header that old clients shouldn't see
+type ResponseRemoveHeader struct {
+	Name string
+}
+
+func (op *ResponseRemoveHeader) ApplyToResponse(node *jsonast.Node) error {
+	return nil // Headers need the ResponseInfo
+}
+
+// ApplyToResponseInfo removes the header
+func (op *ResponseRemoveHeader) ApplyToResponseInfo(resp *ResponseInfo) error {
+	if resp == nil || resp.Headers == nil {
+		return nil
+	}
+	resp.Headers.Del(op.Name)
+	return nil
+}
+
+func (op *ResponseRemoveHeader) GetFieldMapping() map[string]string {
+	return nil
+}
+
+// ResponseRenameHeader renames a header when response migrates from HEAD to client
+// Use case: HEAD renamed "X-Request-Id" to "Request-Id", rename back for old clients
+type ResponseRenameHeader struct {
+	NewerVersionName string // Header name in newer/HEAD version
+	OlderVersionName string // Header name in older/client version
+}
+
+func (op *ResponseRenameHeader) ApplyToResponse(node *jsonast.Node) error {
+	return nil // Headers need the ResponseInfo
+}
+
+// ApplyToResponseInfo moves all values of the newer header to the older name
+func (op *ResponseRenameHeader) ApplyToResponseInfo(resp *ResponseInfo) error {
+	if resp == nil || resp.Headers == nil {
+		return nil
+	}
+	values := resp.Headers.Values(op.NewerVersionName)
+	if len(values) == 0 {
+		return nil
+	}
+	resp.Headers.Del(op.NewerVersionName)
+	resp.Headers[http.CanonicalHeaderKey(op.OlderVersionName)] = values
+	return nil
+}
+
+func (op *ResponseRenameHeader) GetFieldMapping() map[string]string {
+	return nil
+}
+
+// syncResponseHeaders makes the writer's headers match the migrated ResponseInfo headers
+func syncResponseHeaders(target, migrated http.Header) {
+	if migrated == nil {
+		return
+	}
+	for name := range target {
+		if _, ok := migrated[name]; !ok {
+			delete(target, name)
+		}
+	}
+	for name, values := range migrated {
+		target[name] = values
+	}
+}
@@ -0,0 +1,101 @@
+package epoch
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type headerListMeta struct {
+	Total int `json:"total"`
+}
+
+type headerList struct {
+	Items []string       `json:"items"`
+	Meta  headerListMeta `json:"meta"`
+}
+
+var _ = Describe("Response header operations", func() {
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+	})
+
+	serve := func(version string, change func(v1, v2 *Version) *VersionChange) *httptest.ResponseRecorder {
+		v1, v2, _ := newTestVersions()
+		epochInstance, err := setupHeadEpoch([]*Version{v1, v2}, []*VersionChange{change(v1, v2)})
+		Expect(err).NotTo(HaveOccurred())
+
+		router := setupRouterWithMiddleware(epochInstance)
+		router.GET("/items", epochInstance.WrapHandler(func(c *gin.Context) {
+			c.Header("Request-Id", "abc")
+			c.Header("X-Rate-Limit", "100")
+			c.JSON(200, headerList{Items: []string{"a", "b"}, Meta: headerListMeta{Total: 42}})
+		}).Returns(headerList{}).ToHandlerFunc("GET", "/items"))
+
+		recorder := serveVersioned(router, httptest.NewRequest("GET", "/items", nil), version)
+		Expect(recorder.Code).To(Equal(200))
+		return recorder
+	}
+
+	It("should add a header from a body field for older clients only", func() {
+		change := func(v1, v2 *Version) *VersionChange {
+			return NewVersionChangeBuilder(v1, v2).
+				ForType(headerList{}).
+				ResponseToPreviousVersion().
+				AddHeaderFromField("X-Total-Count", "meta.total").
+				Build()
+		}
+
+		Expect(serve("2024-01-01", change).Header().Get("X-Total-Count")).To(Equal("42"))
+		Expect(serve("2024-06-01", change).Header().Values("X-Total-Count")).To(BeEmpty())
+	})
+
+	It("should add a constant header without overriding one the handler set", func() {
+		change := func(v1, v2 *Version) *VersionChange {
+			return NewVersionChangeBuilder(v1, v2).
+				ForType(headerList{}).
+				ResponseToPreviousVersion().
+				AddHeader("X-Legacy", "true").
+				AddHeader("X-Rate-Limit", "10").
+				Build()
+		}
+
+		recorder := serve("2024-01-01", change)
+		Expect(recorder.Header().Get("X-Legacy")).To(Equal("true"))
+		Expect(recorder.Header().Get("X-Rate-Limit")).To(Equal("100"))
+	})
+
+	It("should rename and remove headers", func() {
+		change := func(v1, v2 *Version) *VersionChange {
+			return NewVersionChangeBuilder(v1, v2).
+				ForType(headerList{}).
+				ResponseToPreviousVersion().
+				RenameHeader("Request-Id", "X-Request-Id").
+				RemoveHeader("X-Rate-Limit").
+				Build()
+		}
+
+		recorder := serve("2024-01-01", change)
+		Expect(recorder.Header().Get("X-Request-Id")).To(Equal("abc"))
+		Expect(recorder.Header().Values("Request-Id")).To(BeEmpty())
+		Expect(recorder.Header().Values("X-Rate-Limit")).To(BeEmpty())
+		Expect(recorder.Body.String()).To(ContainSubstring(`"total":42`))
+
+		recorder = serve("2024-06-01", change)
+		Expect(recorder.Header().Get("Request-Id")).To(Equal("abc"))
+		Expect(recorder.Header().Get("X-Rate-Limit")).To(Equal("100"))
+	})
+
+	It("should skip non-scalar fields", func() {
+		op := &ResponseAddHeaderFromField{Name: "X-Meta", Field: "meta"}
+		node, err := getNode([]byte(`{"meta":{"total":1}}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(node.LoadAll()).To(Succeed())
+		resp := &ResponseInfo{Body: &node, Headers: make(http.Header)}
+		Expect(op.ApplyToResponseInfo(resp)).To(Succeed())
+		Expect(resp.Headers).To(BeEmpty())
+	})
+})
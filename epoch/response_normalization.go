@@ -0,0 +1,223 @@
+package epoch
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/astronomer/epoch/epoch/jsonast"
+)
+
+// WithResponseNormalization reshapes successful handler output to its registered response type
+// before migrating it, for handlers that build bodies as gin.H or maps rather than the struct:
+//   - keys that differ from a declared field only in case or underscores (userId, UserID,
+//     user_id) are renamed to the declared name, unless the body also has that name
+//   - strings holding numbers or booleans become numbers or booleans for numeric and boolean
+//     fields, and numbers and booleans become strings for string fields
+//   - fields are ordered as the type declares them
+//
+// Nested structs, slices and maps are normalized too. Fields the type doesn't declare are kept;
+// WithSchemaMatchDiagnostics and WithStrictSchemaMatching see the normalized body, so they
+// report what normalization couldn't line up. Responses that need no migration for the
+// requested version are written as the handler produced them.
+func (cb *EpochBuilder) WithResponseNormalization() *EpochBuilder {
+	cb.normalizeResponses = true
+	return cb
+}
+
+// WithResponseNormalization reshapes this handler's output to its response type before migrating it
+func (vah *VersionAwareHandler) WithResponseNormalization() *VersionAwareHandler {
+	vah.normalizeResponses = true
+	return vah
+}
+
+// normalizeResponse rewrites a successful captured response body in its registered type's shape
+func (vah *VersionAwareHandler) normalizeResponse(capture *ResponseCapture, responseType reflect.Type) error {
+	if !vah.normalizeResponses || responseType == nil || capture.statusCode >= 400 || len(capture.body) == 0 {
+		return nil
+	}
+	node, err := vah.jsonEngine.Parse(capture.body)
+	if err != nil {
+		return nil // Bodies that aren't JSON are left to the usual handling
+	}
+	if err := normalizeNode(node, responseType); err != nil {
+		return fmt.Errorf("failed to normalize response: %w", err)
+	}
+	if err := orderFieldsByType(node, responseType, nil); err != nil {
+		return fmt.Errorf("failed to order fields: %w", err)
+	}
+	body, err := vah.jsonEngine.Serialize(node)
+	if err != nil {
+		return fmt.Errorf("failed to serialize normalized response: %w", err)
+	}
+	capture.body = append(capture.body[:0], body...)
+	return nil
+}
+
+// normalizeNode renames the keys of objects in node to the fields t declares and coerces
+// scalars to the kinds of their fields, recursively
+func normalizeNode(node *jsonast.Node, t reflect.Type) error {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if isBuiltinType(t) {
+		return nil
+	}
+
+	switch node.TypeSafe() {
+	case jsonast.V_ARRAY:
+		if (t.Kind() != reflect.Slice && t.Kind() != reflect.Array) || t.Elem().Kind() == reflect.Uint8 {
+			return nil
+		}
+		if err := node.LoadAll(); err != nil {
+			return fmt.Errorf("failed to load array: %w", err)
+		}
+		length, err := node.Len()
+		if err != nil {
+			return fmt.Errorf("failed to get array length: %w", err)
+		}
+		for i := 0; i < length; i++ {
+			if err := normalizeNode(node.Index(i), t.Elem()); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case jsonast.V_OBJECT:
+		if t.Kind() != reflect.Struct && t.Kind() != reflect.Map {
+			return nil
+		}
+		if err := node.LoadAll(); err != nil {
+			return fmt.Errorf("failed to load object: %w", err)
+		}
+		iter, err := node.Properties()
+		if err != nil {
+			return err
+		}
+		var pairs []jsonast.Pair
+		var pair jsonast.Pair
+		for iter.Next(&pair) {
+			pairs = append(pairs, pair)
+		}
+
+		if t.Kind() == reflect.Map {
+			for i := range pairs {
+				if err := normalizeNode(&pairs[i].Value, t.Elem()); err != nil {
+					return err
+				}
+			}
+		} else if err := normalizeFields(pairs, orderedJSONFields(t)); err != nil {
+			return err
+		}
+		*node = jsonast.NewObject(pairs)
+		return nil
+	}
+
+	coerced, err := coerceScalar(node, t)
+	if err != nil || coerced == nil {
+		return err
+	}
+	*node = *coerced
+	return nil
+}
+
+// normalizeFields renames pairs to the declared fields they loosely match and normalizes
+// the values of declared fields
+func normalizeFields(pairs []jsonast.Pair, fields []jsonField) error {
+	declared := make(map[string]reflect.Type, len(fields))
+	folded := make(map[string]string, len(fields))
+	for _, field := range fields {
+		declared[field.name] = field.fieldType
+		key := foldFieldName(field.name)
+		if _, exists := folded[key]; exists {
+			folded[key] = "" // Ambiguous, so keys matching it aren't renamed
+			continue
+		}
+		folded[key] = field.name
+	}
+
+	present := make(map[string]bool, len(pairs))
+	for _, pair := range pairs {
+		present[pair.Key] = true
+	}
+	for i := range pairs {
+		if _, ok := declared[pairs[i].Key]; !ok {
+			if name := folded[foldFieldName(pairs[i].Key)]; name != "" && !present[name] {
+				present[name] = true
+				pairs[i].Key = name
+			}
+		}
+		if fieldType, ok := declared[pairs[i].Key]; ok {
+			if err := normalizeNode(&pairs[i].Value, fieldType); err != nil {
+				return fmt.Errorf("field %s: %w", pairs[i].Key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// foldFieldName reduces a field name to the part case and word separators don't change
+func foldFieldName(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+}
+
+// coerceScalar returns node converted to the JSON kind t marshals to, or nil when it already
+// has that kind or can't be converted
+func coerceScalar(node *jsonast.Node, t reflect.Type) (*jsonast.Node, error) {
+	switch t.Kind() {
+	case reflect.String:
+		switch node.TypeSafe() {
+		case jsonast.V_NUMBER, jsonast.V_TRUE, jsonast.V_FALSE:
+			raw, err := node.Raw()
+			if err != nil {
+				return nil, err
+			}
+			coerced := jsonast.NewString(raw)
+			return &coerced, nil
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if s, ok := stringValue(node); ok {
+			if value, err := strconv.ParseInt(s, 10, t.Bits()); err == nil {
+				coerced := jsonast.NewNumber(strconv.FormatInt(value, 10))
+				return &coerced, nil
+			}
+		}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if s, ok := stringValue(node); ok {
+			if value, err := strconv.ParseUint(s, 10, t.Bits()); err == nil {
+				coerced := jsonast.NewNumber(strconv.FormatUint(value, 10))
+				return &coerced, nil
+			}
+		}
+
+	case reflect.Float32, reflect.Float64:
+		if s, ok := stringValue(node); ok {
+			if value, err := strconv.ParseFloat(s, t.Bits()); err == nil && !math.IsInf(value, 0) && !math.IsNaN(value) {
+				coerced := jsonast.NewNumber(strconv.FormatFloat(value, 'g', -1, t.Bits()))
+				return &coerced, nil
+			}
+		}
+
+	case reflect.Bool:
+		if s, ok := stringValue(node); ok {
+			if value, err := strconv.ParseBool(s); err == nil {
+				coerced := jsonast.NewBool(value)
+				return &coerced, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// stringValue returns the value of a string node
+func stringValue(node *jsonast.Node) (string, bool) {
+	if node.TypeSafe() != jsonast.V_STRING {
+		return "", false
+	}
+	s, err := node.String()
+	return s, err == nil
+}
@@ -0,0 +1,117 @@
+package epoch
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type normalizedAddress struct {
+	City    string `json:"city"`
+	ZipCode string `json:"zip_code"`
+}
+
+type normalizedUser struct {
+	ID        int                 `json:"id"`
+	FullName  string              `json:"full_name"`
+	Active    bool                `json:"active"`
+	Score     float64             `json:"score"`
+	Addresses []normalizedAddress `json:"addresses"`
+}
+
+var _ = Describe("Response normalization", func() {
+	var (
+		v1, v2 *Version
+		output gin.H
+	)
+
+	BeforeEach(func() {
+		v1, _ = NewDateVersion("2024-01-01")
+		v2, _ = NewDateVersion("2025-01-01")
+		output = gin.H{
+			"ID":       "42",
+			"fullName": "Ada",
+			"active":   "true",
+			"score":    "9.5",
+			"addresses": []gin.H{
+				{"City": "London", "zipCode": 12345},
+			},
+		}
+	})
+
+	newRouter := func(builder *EpochBuilder) *gin.Engine {
+		change := NewVersionChangeBuilder(v1, v2).
+			ForType(normalizedUser{}).
+			ResponseToPreviousVersion().
+			RenameField("full_name", "name").
+			ForType(normalizedAddress{}).
+			ResponseToPreviousVersion().
+			RenameField("city", "town").
+			Build()
+		epochInstance, err := builder.WithVersions(v1, v2).WithHeadVersion().WithChanges(change).Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.Use(epochInstance.Middleware())
+		router.GET("/users/1", epochInstance.WrapHandler(func(c *gin.Context) {
+			c.JSON(http.StatusOK, output)
+		}).Returns(normalizedUser{}).ToHandlerFunc("GET", "/users/:id"))
+		return router
+	}
+
+	get := func(router *gin.Engine, version string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+		req.Header.Set("X-API-Version", version)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	It("should leave map output that doesn't match the type unmigrated by default", func() {
+		recorder := get(newRouter(NewEpoch()), "2024-01-01")
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Body.String()).To(ContainSubstring(`"fullName"`))
+		Expect(recorder.Body.String()).NotTo(ContainSubstring(`"name"`))
+	})
+
+	It("should rename and coerce map output before migrating it", func() {
+		recorder := get(newRouter(NewEpoch().WithResponseNormalization()), "2024-01-01")
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Body.String()).To(MatchJSON(
+			`{"id":42,"name":"Ada","active":true,"score":9.5,"addresses":[{"town":"London","zip_code":"12345"}]}`))
+	})
+
+	It("should keep fields the type doesn't declare and report them", func() {
+		output["nickname"] = "ada"
+		var matches []SchemaMatch
+		router := newRouter(NewEpoch().WithResponseNormalization().
+			WithSchemaMatchDiagnostics(func(c *gin.Context, match SchemaMatch) {
+				matches = append(matches, match)
+			}))
+
+		recorder := get(router, "2024-01-01")
+		Expect(recorder.Body.String()).To(MatchJSON(
+			`{"id":42,"name":"Ada","active":true,"score":9.5,"addresses":[{"town":"London","zip_code":"12345"}],"nickname":"ada"}`))
+
+		Expect(matches).To(HaveLen(2))
+		Expect(matches[1].Direction).To(Equal(DirectionResponse))
+		Expect(matches[1].UnknownFields).To(Equal([]string{"nickname"}))
+	})
+
+	It("should not rename a key when the body already has the declared name", func() {
+		output["full_name"] = "Ada Lovelace"
+		recorder := get(newRouter(NewEpoch().WithResponseNormalization()), "2024-01-01")
+		Expect(recorder.Body.String()).To(MatchJSON(
+			`{"id":42,"name":"Ada Lovelace","active":true,"score":9.5,"addresses":[{"town":"London","zip_code":"12345"}],"fullName":"Ada"}`))
+	})
+
+	It("should leave strings that aren't numbers alone", func() {
+		output["ID"] = "abc"
+		recorder := get(newRouter(NewEpoch().WithResponseNormalization()), "2024-01-01")
+		Expect(recorder.Body.String()).To(ContainSubstring(`"id":"abc"`))
+	})
+})
@@ -0,0 +1,530 @@
+package epoch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/astronomer/epoch/epoch/jsonast"
+)
+
+// ResponseTemplate is a precomputed byte-level patch for one endpoint+version pair
+//
+// When every change between HEAD and a client version only adds or removes fields with
+// constant values, the migrated response can be produced by splicing the handler's JSON
+// bytes (dropping removed members, appending pre-encoded added members) instead of parsing
+// it into an AST, running the transformers and serializing it again.
+type ResponseTemplate struct {
+	root    *objectTemplate
+	isArray bool // the response body is a top-level array of root objects
+}
+
+// objectTemplate describes the patch for a single struct type
+type objectTemplate struct {
+	remove map[string]bool            // member keys to drop
+	add    []templateMember           // members appended after the existing ones, in order
+	nested map[string]*objectTemplate // first-level fields holding nested objects or arrays of objects
+}
+
+// templateMember is a pre-encoded `"key":value` member
+type templateMember struct {
+	name     string
+	encoded  []byte
+	ifAbsent bool // only append when the handler didn't write the key itself
+}
+
+// errTemplateIneligible is returned when an endpoint's changes can't be expressed as a template
+var errTemplateIneligible = errors.New("changes are not limited to constant AddField/RemoveField operations")
+
+// CompileResponseTemplate precomputes a byte-level patch for responses of the given endpoint
+// when migrated from HEAD to the given version. It returns nil when migration is a no-op, and
+// an error when any change on the path does more than add or remove constant fields (renames,
+// custom transformers, global instructions, runtime toggles, envelopes, etc.), in which case the full migration must run.
+func CompileResponseTemplate(
+	endpointDef *EndpointDefinition,
+	chain *MigrationChain,
+	headVersion, toVersion *Version,
+	engine JSONEngine,
+) (*ResponseTemplate, error) {
+	if endpointDef.ResponseType == nil {
+		return nil, errTemplateIneligible
+	}
+
+	// Order changes the way MigrationChain.MigrateResponse applies them: newest step first
+	path := chain.GetMigrationPath(headVersion, toVersion)
+	sort.SliceStable(path, func(i, j int) bool {
+		return path[i].ToVersion().IsNewerThan(path[j].ToVersion())
+	})
+
+	for _, change := range path {
+		if len(change.globalResponseInstructions) > 0 || change.IsToggled() ||
+			len(change.GetResponseEnvelopeOperations(endpointDef.Method, endpointDef.PathPattern)) > 0 {
+			return nil, errTemplateIneligible
+		}
+	}
+
+	compiler := &templateCompiler{
+		path:      path,
+		engine:    engine,
+		templates: make(map[reflect.Type]*objectTemplate),
+	}
+
+	rootType := endpointDef.ResponseType
+	template := &ResponseTemplate{}
+	if rootType.Kind() == reflect.Slice || rootType.Kind() == reflect.Array {
+		template.isArray = true
+		rootType = rootType.Elem()
+		root, err := compiler.compile(rootType, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		template.root = root
+	} else {
+		root, err := compiler.compile(rootType, endpointDef.ResponseNestedArrays, endpointDef.ResponseNestedObjects)
+		if err != nil {
+			return nil, err
+		}
+		template.root = root
+	}
+
+	if template.root == nil {
+		return nil, nil
+	}
+	return template, nil
+}
+
+// templateCompiler builds object templates for every type reachable from the response type
+type templateCompiler struct {
+	path      []*VersionChange
+	engine    JSONEngine
+	templates map[reflect.Type]*objectTemplate
+}
+
+// compile returns the template for a type, or nil when neither the type nor anything nested in
+// it is migrated. Recursive types share a single template instance.
+func (tc *templateCompiler) compile(t reflect.Type, nestedArrays, nestedObjects map[string]reflect.Type) (*objectTemplate, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if template, seen := tc.templates[t]; seen {
+		return template, nil
+	}
+
+	if nestedArrays == nil && nestedObjects == nil {
+		nestedArrays, nestedObjects = BuildNestedTypeMaps(t)
+	}
+
+	template := &objectTemplate{
+		remove: make(map[string]bool),
+		nested: make(map[string]*objectTemplate),
+	}
+	tc.templates[t] = template
+
+	if err := tc.compileOperations(t, template); err != nil {
+		return nil, err
+	}
+
+	nestedFields := make(map[string]reflect.Type, len(nestedArrays)+len(nestedObjects))
+	for fieldPath, itemType := range nestedArrays {
+		nestedFields[fieldPath] = itemType
+	}
+	for fieldPath, objectType := range nestedObjects {
+		nestedFields[fieldPath] = objectType
+	}
+
+	for fieldPath, nestedType := range nestedFields {
+		if strings.Contains(fieldPath, ".") {
+			return nil, errTemplateIneligible
+		}
+		nestedTemplate, err := tc.compile(nestedType, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		if nestedTemplate == nil {
+			continue
+		}
+		// A field the parent adds or removes can't also be patched in place: the full
+		// migration would interleave both per step, which a single splice can't reproduce
+		if template.touches(fieldPath) {
+			return nil, errTemplateIneligible
+		}
+		template.nested[fieldPath] = nestedTemplate
+	}
+
+	if template.isEmpty() {
+		// Nothing to patch for this type; later lookups skip it entirely
+		tc.templates[t] = nil
+		return nil, nil
+	}
+	return template, nil
+}
+
+// fieldState tracks the net effect of successive Add/Remove operations on one field
+type fieldState struct {
+	removed  bool
+	value    interface{}
+	hasValue bool
+	ifAbsent bool
+	order    int
+}
+
+// compileOperations folds the per-step operations for a type into a single remove/add patch
+func (tc *templateCompiler) compileOperations(t reflect.Type, template *objectTemplate) error {
+	states := make(map[string]*fieldState)
+	order := 0
+
+	for _, change := range tc.path {
+		instructions := change.responseInstructionsFor(t)
+		if len(instructions) == 0 {
+			continue
+		}
+
+		// Only builder-generated instructions are understood; they come with their operations
+		ops, hasOps := change.GetResponseOperationsByType(t)
+		if !hasOps || len(instructions) > 1 {
+			return errTemplateIneligible
+		}
+
+		for _, op := range ops {
+			switch typed := op.(type) {
+			case *ResponseRemoveField:
+				states[typed.Name] = &fieldState{removed: true}
+			case *ResponseAddField:
+				state, exists := states[typed.Name]
+				switch {
+				case !exists:
+					order++
+					states[typed.Name] = &fieldState{value: typed.Default, hasValue: true, ifAbsent: true, order: order}
+				case state.removed && !state.hasValue:
+					order++
+					state.value, state.hasValue, state.order = typed.Default, true, order
+				}
+			default:
+				return errTemplateIneligible
+			}
+		}
+	}
+
+	var added []*fieldState
+	names := make(map[*fieldState]string)
+	for name, state := range states {
+		if state.removed {
+			template.remove[name] = true
+		}
+		if state.hasValue {
+			added = append(added, state)
+			names[state] = name
+		}
+	}
+	sort.Slice(added, func(i, j int) bool { return added[i].order < added[j].order })
+
+	for _, state := range added {
+		encoded, err := tc.encodeMember(names[state], state.value)
+		if err != nil {
+			return err
+		}
+		template.add = append(template.add, templateMember{
+			name:     names[state],
+			encoded:  encoded,
+			ifAbsent: state.ifAbsent,
+		})
+	}
+	return nil
+}
+
+// encodeMember encodes `"name":value` exactly as the AST path would, via the JSON engine
+func (tc *templateCompiler) encodeMember(name string, value interface{}) ([]byte, error) {
+	wrapper := jsonast.NewObject(nil)
+	if err := SetNodeField(&wrapper, name, value); err != nil {
+		return nil, err
+	}
+	encoded, err := tc.engine.Serialize(&wrapper)
+	if err != nil {
+		return nil, err
+	}
+	// Strip the surrounding braces
+	return encoded[1 : len(encoded)-1], nil
+}
+
+func (ot *objectTemplate) touches(name string) bool {
+	if ot.remove[name] {
+		return true
+	}
+	for _, member := range ot.add {
+		if member.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (ot *objectTemplate) isEmpty() bool {
+	return len(ot.remove) == 0 && len(ot.add) == 0 && len(ot.nested) == 0
+}
+
+// Apply splices the template into a JSON response body and returns the migrated bytes
+func (rt *ResponseTemplate) Apply(data []byte) ([]byte, error) {
+	s := &jsonSplicer{data: data}
+	s.skipSpace()
+
+	out := make([]byte, 0, len(data)+64)
+	var err error
+	if rt.isArray {
+		out, err = s.spliceArray(out, rt.root)
+	} else {
+		out, err = s.spliceValue(out, rt.root)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.skipSpace()
+	if s.pos != len(s.data) {
+		return nil, errors.New("invalid JSON: unexpected data after top-level value")
+	}
+	return out, nil
+}
+
+// jsonSplicer walks raw JSON bytes, copying values through and patching templated objects
+type jsonSplicer struct {
+	data []byte
+	pos  int
+}
+
+var errSpliceSyntax = errors.New("invalid JSON")
+
+func (s *jsonSplicer) skipSpace() {
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case ' ', '\t', '\n', '\r':
+			s.pos++
+		default:
+			return
+		}
+	}
+}
+
+// spliceValue copies the next value, applying the template if it is an object
+func (s *jsonSplicer) spliceValue(out []byte, template *objectTemplate) ([]byte, error) {
+	if s.pos >= len(s.data) {
+		return nil, errSpliceSyntax
+	}
+	switch {
+	case template != nil && s.data[s.pos] == '{':
+		return s.spliceObject(out, template)
+	case template != nil && s.data[s.pos] == '[':
+		return s.spliceArray(out, template)
+	}
+
+	start := s.pos
+	if err := s.skipValue(); err != nil {
+		return nil, err
+	}
+	return append(out, s.data[start:s.pos]...), nil
+}
+
+// spliceArray copies an array, applying the template to each object element
+func (s *jsonSplicer) spliceArray(out []byte, template *objectTemplate) ([]byte, error) {
+	if s.pos >= len(s.data) || s.data[s.pos] != '[' {
+		return nil, errSpliceSyntax
+	}
+	s.pos++
+	out = append(out, '[')
+
+	s.skipSpace()
+	if s.pos < len(s.data) && s.data[s.pos] == ']' {
+		s.pos++
+		return append(out, ']'), nil
+	}
+
+	for {
+		s.skipSpace()
+		var err error
+		if s.pos < len(s.data) && s.data[s.pos] == '{' {
+			out, err = s.spliceObject(out, template)
+		} else {
+			out, err = s.spliceValue(out, nil)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		s.skipSpace()
+		if s.pos >= len(s.data) {
+			return nil, errSpliceSyntax
+		}
+		switch s.data[s.pos] {
+		case ',':
+			s.pos++
+			out = append(out, ',')
+		case ']':
+			s.pos++
+			return append(out, ']'), nil
+		default:
+			return nil, errSpliceSyntax
+		}
+	}
+}
+
+// spliceObject copies an object, dropping removed members, patching nested fields and
+// appending added members
+func (s *jsonSplicer) spliceObject(out []byte, template *objectTemplate) ([]byte, error) {
+	s.pos++ // '{'
+	out = append(out, '{')
+	wrote := false
+	var present map[string]bool
+
+	s.skipSpace()
+	if s.pos < len(s.data) && s.data[s.pos] == '}' {
+		s.pos++
+	} else {
+		for {
+			s.skipSpace()
+			keyStart := s.pos
+			key, err := s.readKey()
+			if err != nil {
+				return nil, err
+			}
+			keyEnd := s.pos
+
+			s.skipSpace()
+			if s.pos >= len(s.data) || s.data[s.pos] != ':' {
+				return nil, errSpliceSyntax
+			}
+			s.pos++
+			s.skipSpace()
+
+			if template.remove[key] {
+				if err := s.skipValue(); err != nil {
+					return nil, err
+				}
+			} else {
+				if len(template.add) > 0 {
+					if present == nil {
+						present = make(map[string]bool)
+					}
+					present[key] = true
+				}
+				if wrote {
+					out = append(out, ',')
+				}
+				wrote = true
+				out = append(out, s.data[keyStart:keyEnd]...)
+				out = append(out, ':')
+				if out, err = s.spliceValue(out, template.nested[key]); err != nil {
+					return nil, err
+				}
+			}
+
+			s.skipSpace()
+			if s.pos >= len(s.data) {
+				return nil, errSpliceSyntax
+			}
+			if s.data[s.pos] == ',' {
+				s.pos++
+				continue
+			}
+			if s.data[s.pos] == '}' {
+				s.pos++
+				break
+			}
+			return nil, errSpliceSyntax
+		}
+	}
+
+	for _, member := range template.add {
+		if member.ifAbsent && present[member.name] {
+			continue
+		}
+		if wrote {
+			out = append(out, ',')
+		}
+		wrote = true
+		out = append(out, member.encoded...)
+	}
+	return append(out, '}'), nil
+}
+
+// readKey reads a quoted object key and returns its decoded value
+func (s *jsonSplicer) readKey() (string, error) {
+	start := s.pos
+	if err := s.skipString(); err != nil {
+		return "", err
+	}
+	raw := s.data[start:s.pos]
+	if bytes.IndexByte(raw, '\\') < 0 {
+		return string(raw[1 : len(raw)-1]), nil
+	}
+	var key string
+	if err := json.Unmarshal(raw, &key); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+func (s *jsonSplicer) skipString() error {
+	if s.pos >= len(s.data) || s.data[s.pos] != '"' {
+		return errSpliceSyntax
+	}
+	for s.pos++; s.pos < len(s.data); s.pos++ {
+		switch s.data[s.pos] {
+		case '\\':
+			s.pos++
+		case '"':
+			s.pos++
+			return nil
+		}
+	}
+	return errSpliceSyntax
+}
+
+// skipValue advances past the next value without interpreting it
+func (s *jsonSplicer) skipValue() error {
+	if s.pos >= len(s.data) {
+		return errSpliceSyntax
+	}
+	switch s.data[s.pos] {
+	case '"':
+		return s.skipString()
+	case '{', '[':
+		depth := 0
+		for s.pos < len(s.data) {
+			switch s.data[s.pos] {
+			case '"':
+				if err := s.skipString(); err != nil {
+					return err
+				}
+				continue
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+				if depth == 0 {
+					s.pos++
+					return nil
+				}
+			}
+			s.pos++
+		}
+		return errSpliceSyntax
+	default:
+		start := s.pos
+		for s.pos < len(s.data) {
+			switch s.data[s.pos] {
+			case ',', '}', ']', ' ', '\t', '\n', '\r':
+				if s.pos == start {
+					return errSpliceSyntax
+				}
+				return nil
+			}
+			s.pos++
+		}
+		if s.pos == start {
+			return errSpliceSyntax
+		}
+		return nil
+	}
+}
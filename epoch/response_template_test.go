@@ -0,0 +1,190 @@
+package epoch
+
+import (
+	"net/http/httptest"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type templateAddress struct {
+	Street string `json:"street"`
+	Zip    string `json:"zip"`
+}
+
+type templateUser struct {
+	ID      int             `json:"id"`
+	Name    string          `json:"name"`
+	Email   string          `json:"email"`
+	Address templateAddress `json:"address"`
+}
+
+type templateUserList struct {
+	Users []templateUser `json:"users"`
+	Total int            `json:"total"`
+}
+
+var _ = Describe("ResponseTemplate", func() {
+	var v1, v2, v3, head *Version
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		v1, v2, v3 = newTestVersions()
+		head = NewHeadVersion()
+	})
+
+	constantChanges := func() []*VersionChange {
+		return []*VersionChange{
+			NewVersionChangeBuilder(v1, v2).
+				ForType(templateUser{}).
+				ResponseToPreviousVersion().
+				RemoveField("email").
+				AddField("legacy", true).
+				ForType(templateAddress{}).
+				ResponseToPreviousVersion().
+				RemoveField("zip").
+				Build(),
+			NewVersionChangeBuilder(v2, v3).
+				ForType(templateUser{}).
+				ResponseToPreviousVersion().
+				AddField("email", "hidden@example.com").
+				AddField("tier", "basic").
+				Build(),
+		}
+	}
+
+	compile := func(responseType interface{}, changes []*VersionChange, to *Version) (*ResponseTemplate, error) {
+		chain, err := NewMigrationChain(changes)
+		Expect(err).NotTo(HaveOccurred())
+
+		t := reflect.TypeOf(responseType)
+		nestedArrays, nestedObjects := BuildNestedTypeMaps(t)
+		def := &EndpointDefinition{
+			ResponseType:          t,
+			ResponseNestedArrays:  nestedArrays,
+			ResponseNestedObjects: nestedObjects,
+		}
+		return CompileResponseTemplate(def, chain, head, to, DefaultJSONEngine())
+	}
+
+	Describe("CompileResponseTemplate", func() {
+		It("should fold add and remove operations across versions", func() {
+			template, err := compile(templateUser{}, constantChanges(), v1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(template).NotTo(BeNil())
+
+			out, err := template.Apply([]byte(`{"id":1,"name":"Jane","email":"j@example.com","address":{"street":"Main","zip":"123"}}`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(out)).To(Equal(`{"id":1,"name":"Jane","address":{"street":"Main"},"tier":"basic","legacy":true}`))
+		})
+
+		It("should only append AddField members that the handler didn't write", func() {
+			template, err := compile(templateUser{}, constantChanges(), v2)
+			Expect(err).NotTo(HaveOccurred())
+
+			out, err := template.Apply([]byte(`{"id":1,"tier":"gold"}`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(out)).To(Equal(`{"id":1,"tier":"gold","email":"hidden@example.com"}`))
+		})
+
+		It("should patch nested arrays of migrated types", func() {
+			template, err := compile(templateUserList{}, constantChanges(), v1)
+			Expect(err).NotTo(HaveOccurred())
+
+			out, err := template.Apply([]byte(`{"users":[{"id":1,"email":"a"},{"id":2,"address":null}],"total":2}`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(out)).To(Equal(`{"users":[{"id":1,"tier":"basic","legacy":true},{"id":2,"address":null,"tier":"basic","legacy":true}],"total":2}`))
+		})
+
+		It("should patch top-level arrays", func() {
+			template, err := compile([]templateUser{}, constantChanges(), v1)
+			Expect(err).NotTo(HaveOccurred())
+
+			out, err := template.Apply([]byte(` [ {"id":1,"email":"a"} , {"id":2} ] `))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(out)).To(Equal(`[{"id":1,"tier":"basic","legacy":true},{"id":2,"tier":"basic","legacy":true}]`))
+		})
+
+		It("should return nil when no change affects the response type", func() {
+			template, err := compile(templateAddress{}, constantChanges()[1:], v2)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(template).To(BeNil())
+		})
+
+		It("should refuse changes that rename fields", func() {
+			change := NewVersionChangeBuilder(v1, v2).
+				ForType(templateUser{}).
+				ResponseToPreviousVersion().
+				RenameField("name", "full_name").
+				Build()
+
+			_, err := compile(templateUser{}, []*VersionChange{change}, v1)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should refuse global instructions", func() {
+			change := NewVersionChange("Global", v1, v2, &AlterResponseInstruction{
+				Transformer: func(*ResponseInfo) error { return nil },
+			})
+
+			_, err := compile(templateUser{}, []*VersionChange{change}, v1)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Apply", func() {
+		It("should handle escaped keys and strings containing structural characters", func() {
+			template, err := compile(templateUser{}, constantChanges(), v1)
+			Expect(err).NotTo(HaveOccurred())
+
+			out, err := template.Apply([]byte(`{"name":"a}\"b","email":"x,y]"}`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(out)).To(Equal(`{"name":"a}\"b","tier":"basic","legacy":true}`))
+		})
+
+		It("should reject invalid JSON", func() {
+			template, err := compile(templateUser{}, constantChanges(), v1)
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, body := range []string{`{"id":1`, `{"id" 1}`, `{"id":1}}`, `not json`} {
+				_, err := template.Apply([]byte(body))
+				Expect(err).To(HaveOccurred(), body)
+			}
+		})
+	})
+
+	Describe("Middleware integration", func() {
+		serve := func(templates bool, version string) string {
+			v1, v2, v3 = newTestVersions()
+			var options []func(*EpochBuilder) *EpochBuilder
+			if templates {
+				options = append(options, (*EpochBuilder).WithResponseTemplates)
+			}
+			epochInstance, err := setupHeadEpoch([]*Version{v1, v2, v3}, constantChanges(), options...)
+			Expect(err).NotTo(HaveOccurred())
+
+			router := setupRouterWithMiddleware(epochInstance)
+			router.GET("/users", epochInstance.WrapHandler(func(c *gin.Context) {
+				c.JSON(200, templateUserList{
+					Users: []templateUser{
+						{ID: 1, Name: "Jane", Email: "jane@example.com", Address: templateAddress{Street: "Main", Zip: "123"}},
+						{ID: 2, Name: "Bob", Email: "bob@example.com"},
+					},
+					Total: 2,
+				})
+			}).Returns(templateUserList{}).ToHandlerFunc("GET", "/users"))
+
+			recorder := serveVersioned(router, httptest.NewRequest("GET", "/users", nil), version)
+			Expect(recorder.Code).To(Equal(200))
+			return recorder.Body.String()
+		}
+
+		It("should produce the same body as the full migration for every version", func() {
+			for _, version := range []string{"2024-01-01", "2024-06-01", "2025-01-01"} {
+				Expect(serve(true, version)).To(Equal(serve(false, version)), version)
+			}
+		})
+	})
+})
@@ -0,0 +1,145 @@
+package epoch
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RetiredVersionStats reports a retired version and the calls rejected since it was retired
+type RetiredVersionStats struct {
+	Version        string
+	RetiredAt      time.Time
+	Rejected       int64
+	LastRejectedAt time.Time
+}
+
+// retiredVersions tracks retired versions; safe for concurrent use by the middleware
+type retiredVersions struct {
+	mu    sync.RWMutex
+	clock Clock
+	stats map[string]*RetiredVersionStats
+}
+
+func newRetiredVersions(clock Clock) *retiredVersions {
+	return &retiredVersions{clock: clock, stats: make(map[string]*RetiredVersionStats)}
+}
+
+// reject counts a rejected call and reports whether version is retired
+func (r *retiredVersions) reject(version *Version) bool {
+	if r == nil {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats, ok := r.stats[version.String()]
+	if !ok {
+		return false
+	}
+	stats.Rejected++
+	stats.LastRejectedAt = r.clock.Now()
+	return true
+}
+
+func (r *retiredVersions) isRetired(version string) bool {
+	if r == nil {
+		return false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.stats[version]
+	return ok
+}
+
+// RetireVersion rejects further calls to version with 410 Gone and a hint naming the version to
+// upgrade to. The version's changes stay in the migration chain, so MigrateRequest/MigrateResponse
+// and tests can still replay them. Retiring is idempotent and can be undone with RestoreVersion.
+func (c *Epoch) RetireVersion(version *Version) error {
+	if version == nil {
+		return fmt.Errorf("cannot retire a nil version")
+	}
+	if version.IsHead {
+		return fmt.Errorf("cannot retire the head version")
+	}
+	if !c.versionBundle.IsVersionDefined(version.String()) {
+		return fmt.Errorf("cannot retire %w '%s'", ErrUnknownVersion, version.String())
+	}
+	if def := c.versionConfig.DefaultVersion; def != nil && def.Equal(version) {
+		return fmt.Errorf("cannot retire the default version '%s': change the default first", version.String())
+	}
+
+	c.retired.mu.Lock()
+	defer c.retired.mu.Unlock()
+	if _, ok := c.retired.stats[version.String()]; !ok {
+		c.retired.stats[version.String()] = &RetiredVersionStats{Version: version.String(), RetiredAt: c.Now()}
+	}
+	return nil
+}
+
+// RestoreVersion serves a retired version again, e.g. to roll back a staged shutdown
+func (c *Epoch) RestoreVersion(version *Version) {
+	c.retired.mu.Lock()
+	defer c.retired.mu.Unlock()
+	delete(c.retired.stats, version.String())
+}
+
+// IsRetired reports whether version has been retired
+func (c *Epoch) IsRetired(version *Version) bool {
+	return c.retired.isRetired(version.String())
+}
+
+// RetiredVersions returns the stats of every retired version, oldest version first
+func (c *Epoch) RetiredVersions() []RetiredVersionStats {
+	c.retired.mu.RLock()
+	defer c.retired.mu.RUnlock()
+	stats := make([]RetiredVersionStats, 0, len(c.retired.stats))
+	for _, s := range c.retired.stats {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		vi, _ := c.versionBundle.ParseVersion(stats[i].Version)
+		vj, _ := c.versionBundle.ParseVersion(stats[j].Version)
+		return vi.IsOlderThan(vj)
+	})
+	return stats
+}
+
+// upgradeTarget is the oldest version newer than version that is still served to c
+func (vm *VersionMiddleware) upgradeTarget(c *gin.Context, version *Version) *Version {
+	cutoff := vm.hiddenFrom(c)
+	for _, v := range vm.versionBundle.GetVersions() {
+		if cutoff != nil && !v.IsOlderThan(cutoff) {
+			break
+		}
+		if v.IsNewerThan(version) && !vm.retired.isRetired(v.String()) {
+			return v
+		}
+	}
+	return vm.versionBundle.GetHeadVersion()
+}
+
+// rejectRetired aborts calls to retired versions with 410 Gone and an upgrade hint
+func (vm *VersionMiddleware) rejectRetired(c *gin.Context, version *Version) bool {
+	if !vm.retired.reject(version) {
+		return false
+	}
+	available := make([]string, 0, len(vm.versionBundle.GetVersions()))
+	for _, v := range vm.visibleVersionValues(c) {
+		if !vm.retired.isRetired(v) {
+			available = append(available, v)
+		}
+	}
+	upgrade := vm.upgradeTarget(c, version)
+	c.JSON(http.StatusGone, withCorrelationID(c, gin.H{
+		"error":              fmt.Sprintf("Version %s has been retired", version.String()),
+		"upgrade_to":         upgrade.String(),
+		"available_versions": available,
+		"hint":               fmt.Sprintf("Set the '%s' header to %s or newer", vm.parameterName, upgrade.String()),
+	}))
+	c.Abort()
+	return true
+}
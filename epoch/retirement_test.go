@@ -0,0 +1,135 @@
+package epoch
+
+import (
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type retiredUser struct {
+	ID       int    `json:"id"`
+	FullName string `json:"full_name"`
+}
+
+var _ = Describe("Version retirement", func() {
+	var (
+		epochInstance *Epoch
+		router        *gin.Engine
+		v1, v2, v3    *Version
+		now           time.Time
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		v1, _ = NewDateVersion("2024-01-01")
+		v2, _ = NewDateVersion("2024-06-01")
+		v3, _ = NewDateVersion("2025-01-01")
+		change := NewVersionChangeBuilder(v1, v2).
+			ForType(retiredUser{}).
+			ResponseToPreviousVersion().
+			RenameField("full_name", "name").
+			Build()
+		now = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+		var err error
+		epochInstance, err = NewEpoch().
+			WithVersions(v1, v2, v3).
+			WithHeadVersion().
+			WithChanges(change).
+			WithClock(ClockFunc(func() time.Time { return now })).
+			Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		router = gin.New()
+		router.Use(epochInstance.Middleware())
+		router.GET("/users/:id", epochInstance.WrapHandler(func(c *gin.Context) {
+			c.JSON(200, retiredUser{ID: 1, FullName: "Ada"})
+		}).Returns(retiredUser{}).ToHandlerFunc("GET", "/users/:id"))
+	})
+
+	get := func(version string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/users/1", nil)
+		req.Header.Set("X-API-Version", version)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	It("should reject retired versions with an upgrade hint", func() {
+		Expect(epochInstance.RetireVersion(v1)).To(Succeed())
+		Expect(epochInstance.IsRetired(v1)).To(BeTrue())
+
+		recorder := get("2024-01-01")
+		Expect(recorder.Code).To(Equal(410))
+		Expect(recorder.Body.String()).To(MatchJSON(`{
+			"error": "Version 2024-01-01 has been retired",
+			"upgrade_to": "2024-06-01",
+			"available_versions": ["2024-06-01", "2025-01-01", "head"],
+			"hint": "Set the 'X-API-Version' header to 2024-06-01 or newer"
+		}`))
+
+		Expect(get("2024-06-01").Code).To(Equal(200))
+	})
+
+	It("should reject versions that resolve to a retired version", func() {
+		Expect(epochInstance.RetireVersion(v1)).To(Succeed())
+		Expect(get("2024-03-15").Code).To(Equal(410))
+	})
+
+	It("should point past every retired version", func() {
+		Expect(epochInstance.RetireVersion(v1)).To(Succeed())
+		Expect(epochInstance.RetireVersion(v2)).To(Succeed())
+		Expect(get("2024-01-01").Body.String()).To(ContainSubstring(`"upgrade_to":"2025-01-01"`))
+
+		Expect(epochInstance.RetireVersion(v3)).To(Succeed())
+		Expect(get("2024-01-01").Body.String()).To(ContainSubstring(`"upgrade_to":"head"`))
+	})
+
+	It("should count rejected calls per retired version", func() {
+		retiredAt := now
+		Expect(epochInstance.RetireVersion(v2)).To(Succeed())
+		Expect(epochInstance.RetireVersion(v1)).To(Succeed())
+		get("2024-01-01")
+		now = now.Add(time.Hour)
+		get("2024-01-01")
+
+		stats := epochInstance.RetiredVersions()
+		Expect(stats).To(HaveLen(2))
+		Expect(stats[0]).To(Equal(RetiredVersionStats{
+			Version:        "2024-01-01",
+			RetiredAt:      retiredAt,
+			Rejected:       2,
+			LastRejectedAt: now,
+		}))
+		Expect(stats[1].Version).To(Equal("2024-06-01"))
+		Expect(stats[1].Rejected).To(BeZero())
+	})
+
+	It("should keep retired versions' changes in the chain", func() {
+		Expect(epochInstance.RetireVersion(v1)).To(Succeed())
+		Expect(epochInstance.migrationChain.GetChanges()).To(HaveLen(1))
+
+		epochInstance.RestoreVersion(v1)
+		Expect(epochInstance.IsRetired(v1)).To(BeFalse())
+		recorder := get("2024-01-01")
+		Expect(recorder.Code).To(Equal(200))
+		Expect(recorder.Body.String()).To(MatchJSON(`{"id":1,"name":"Ada"}`))
+	})
+
+	It("should refuse to retire head, unknown or default versions", func() {
+		Expect(epochInstance.RetireVersion(epochInstance.VersionBundle().GetHeadVersion())).
+			To(MatchError("cannot retire the head version"))
+
+		unknown, _ := NewDateVersion("2023-01-01")
+		Expect(epochInstance.RetireVersion(unknown)).To(MatchError(ContainSubstring("unknown version '2023-01-01'")))
+
+		d1, _ := NewDateVersion("2024-01-01")
+		d2, _ := NewDateVersion("2024-06-01")
+		withDefault, err := NewEpoch().WithVersions(d1, d2).WithHeadVersion().WithDefaultVersion(d1).Build()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(withDefault.RetireVersion(d1)).To(MatchError(ContainSubstring("default version")))
+	})
+})
@@ -0,0 +1,207 @@
+package epoch
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// RoundTripProblem is a field that doesn't survive a client's request migrating up to HEAD
+// and the response migrating back down through the same change
+type RoundTripProblem struct {
+	Type     reflect.Type // Type the change migrates
+	Change   string       // Description of the change
+	From, To *Version     // Versions the change connects
+	Field    string       // Field as the older version names it
+	Problem  string       // What doesn't round-trip
+}
+
+func (p RoundTripProblem) String() string {
+	return fmt.Sprintf("%s %s->%s (change '%s'): %s", p.Type, p.From, p.To, p.Change, p.Problem)
+}
+
+// RoundTripReport lists the fields whose request and response operations aren't inverses
+type RoundTripReport struct {
+	Problems []RoundTripProblem
+}
+
+// Empty reports whether every field round-trips
+func (r *RoundTripReport) Empty() bool {
+	return len(r.Problems) == 0
+}
+
+// Err returns an error listing every problem, or nil if there are none
+func (r *RoundTripReport) Err() error {
+	if r.Empty() {
+		return nil
+	}
+	problems := make([]string, len(r.Problems))
+	for i, problem := range r.Problems {
+		problems[i] = problem.String()
+	}
+	return errors.New("fields don't round-trip:\n  " + strings.Join(problems, "\n  "))
+}
+
+// WithRoundTripCheck makes Build() fail with a RoundTripReport error when a change's request
+// and response operations for a type aren't inverses (see Epoch.CheckRoundTrips). At Build()
+// no endpoints are registered yet, so only types the chain migrates in both directions are checked.
+func (cb *EpochBuilder) WithRoundTripCheck() *EpochBuilder {
+	cb.roundTripCheck = true
+	return cb
+}
+
+// CheckRoundTrips reports the fields that don't survive a request migrating up and its response
+// migrating back down, change by change: fields requests rename but responses don't rename back
+// (or the other way round), response defaults shadowing a value clients sent under a renamed
+// field, fields requests drop that responses don't return, and fields requests default that
+// responses return to clients that never sent them. Types are checked when the chain migrates
+// them in both directions or registered endpoints both accept and return them.
+func (c *Epoch) CheckRoundTrips() *RoundTripReport {
+	usage := make(map[reflect.Type]*typeUsage)
+	use := func(t reflect.Type) *typeUsage {
+		if usage[t] == nil {
+			usage[t] = &typeUsage{}
+		}
+		return usage[t]
+	}
+	changes := c.migrationChain.GetChanges()
+	for _, change := range changes {
+		for _, entry := range change.ChangelogEntries() {
+			if entry.Direction == DirectionRequest {
+				use(entry.Type).request = true
+			} else {
+				use(entry.Type).response = true
+			}
+		}
+	}
+	for _, def := range c.endpointRegistry.GetAll() {
+		if t := bodyItemType(def.RequestType); t != nil {
+			use(t).request = true
+		}
+		if t := bodyItemType(def.ResponseType); t != nil {
+			use(t).response = true
+		}
+	}
+
+	var types []reflect.Type
+	for t, u := range usage {
+		if u.request && u.response {
+			types = append(types, t)
+		}
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].String() < types[j].String() })
+
+	report := &RoundTripReport{}
+	for _, change := range changes {
+		for _, t := range types {
+			requestOps, _ := change.GetRequestOperationsByType(t)
+			responseOps, _ := change.GetResponseOperationsByType(t)
+			if len(requestOps) == 0 && len(responseOps) == 0 {
+				continue
+			}
+			for _, problem := range roundTripProblems(requestOps, responseOps) {
+				problem.Type, problem.Change = t, change.Description()
+				problem.From, problem.To = change.FromVersion(), change.ToVersion()
+				report.Problems = append(report.Problems, problem)
+			}
+		}
+	}
+	return report
+}
+
+// typeUsage records the directions a type is migrated in
+type typeUsage struct {
+	request, response bool
+}
+
+// bodyItemType returns the type a body of type t migrates as, unwrapping pointers and slices
+func bodyItemType(t reflect.Type) reflect.Type {
+	for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice) {
+		t = t.Elem()
+	}
+	return t
+}
+
+// roundTripProblems compares one change's request and response operations for a type
+func roundTripProblems(requestOps RequestToNextVersionOperationList, responseOps ResponseToPreviousVersionOperationList) []RoundTripProblem {
+	requestRenames := make(map[string]string) // older → newer
+	requestAdded := make(map[string]bool)
+	requestRemoved := make(map[string]bool)
+	for _, op := range requestOps {
+		switch op := op.(type) {
+		case *RequestRenameField:
+			requestRenames[op.OlderVersionName] = op.NewerVersionName
+		case *RequestMoveField:
+			requestRenames[op.OlderVersionPath] = op.NewerVersionPath
+		case *RequestAddField:
+			if op.Validation != FailValidation {
+				requestAdded[op.Name] = true
+			}
+		case *RequestAddFieldWithDefault:
+			requestAdded[op.Name] = true
+		case *RequestAddFieldFromContext:
+			requestAdded[op.Name] = true
+		case *RequestRemoveField:
+			requestRemoved[op.Name] = true
+		}
+	}
+
+	responseRenames := make(map[string]string) // newer → older
+	responseAdded := make(map[string]bool)
+	responseRemoved := make(map[string]bool)
+	for _, op := range responseOps {
+		switch op := op.(type) {
+		case *ResponseRenameField:
+			responseRenames[op.NewerVersionName] = op.OlderVersionName
+		case *ResponseMoveField:
+			responseRenames[op.NewerVersionPath] = op.OlderVersionPath
+		case *ResponseDualWriteField:
+			responseRenames[op.NewerVersionName] = op.OlderVersionName
+		case *ResponseAddField:
+			responseAdded[op.Name] = true
+		case *ResponseRemoveField:
+			responseRemoved[op.Name] = true
+		case *ResponseRemoveFieldIfDefault:
+			responseRemoved[op.Name] = true
+		}
+	}
+	returned := make(map[string]bool, len(responseRenames))
+	for _, older := range responseRenames {
+		returned[older] = true
+	}
+
+	var problems []RoundTripProblem
+	for _, older := range sortedKeys(requestRenames) {
+		newer := requestRenames[older]
+		if responseRenames[newer] == older {
+			continue
+		}
+		problem := fmt.Sprintf("requests rename '%s' to '%s' but responses don't rename it back", older, newer)
+		if responseAdded[older] {
+			problem = fmt.Sprintf("responses add '%s' with a default instead of renaming '%s' back, shadowing what clients sent", older, newer)
+		}
+		problems = append(problems, RoundTripProblem{Field: older, Problem: problem})
+	}
+	for _, newer := range sortedKeys(responseRenames) {
+		older := responseRenames[newer]
+		if _, ok := requestRenames[older]; !ok {
+			problems = append(problems, RoundTripProblem{Field: older,
+				Problem: fmt.Sprintf("responses rename '%s' to '%s' but requests don't rename it back", newer, older)})
+		}
+	}
+	for _, field := range sortedKeys(requestRemoved) {
+		if !responseAdded[field] && !returned[field] {
+			problems = append(problems, RoundTripProblem{Field: field,
+				Problem: fmt.Sprintf("requests drop '%s' but responses don't return it", field)})
+		}
+	}
+	for _, field := range sortedKeys(requestAdded) {
+		if _, renamed := responseRenames[field]; !responseRemoved[field] && !renamed {
+			problems = append(problems, RoundTripProblem{Field: field,
+				Problem: fmt.Sprintf("requests default '%s' but responses return it to clients that never sent it", field)})
+		}
+	}
+	return problems
+}
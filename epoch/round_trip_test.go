@@ -0,0 +1,96 @@
+package epoch
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type roundTripMember struct {
+	ID       int    `json:"id"`
+	FullName string `json:"full_name"`
+	Email    string `json:"email"`
+	Role     string `json:"role"`
+}
+
+type roundTripCreateMember struct {
+	FullName string `json:"full_name"`
+}
+
+var _ = Describe("Round trip checks", func() {
+	var v1, v2 *Version
+
+	BeforeEach(func() {
+		v1, _ = NewDateVersion("2024-01-01")
+		v2, _ = NewDateVersion("2024-06-01")
+	})
+
+	It("should accept changes whose request and response operations are inverses", func() {
+		change := NewVersionChangeBuilder(v1, v2).
+			ForType(roundTripMember{}).
+			RequestToNextVersion().RenameField("name", "full_name").AddField("role", "member").RemoveField("nickname").
+			ResponseToPreviousVersion().RenameField("full_name", "name").RemoveField("role").AddField("nickname", "").
+			Build()
+		epochInstance, err := NewEpoch().WithVersions(v1, v2).WithHeadVersion().WithChanges(change).WithRoundTripCheck().Build()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(epochInstance.CheckRoundTrips().Empty()).To(BeTrue())
+	})
+
+	It("should report fields that don't round-trip", func() {
+		// Build() attaches changes to versions, so each build gets its own
+		reworkUsers := func() (*Version, *Version, *VersionChange) {
+			v1, _ := NewDateVersion("2024-01-01")
+			v2, _ := NewDateVersion("2024-06-01")
+			return v1, v2, NewVersionChangeBuilder(v1, v2).
+				Description("Rework users").
+				ForType(roundTripMember{}).
+				RequestToNextVersion().RenameField("name", "full_name").RenameField("mail", "email").AddField("role", "member").RemoveField("nickname").
+				ResponseToPreviousVersion().AddField("name", "").RenameField("id", "user_id").
+				Build()
+		}
+		v1, v2, change := reworkUsers()
+		epochInstance, err := NewEpoch().WithVersions(v1, v2).WithHeadVersion().WithChanges(change).Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		report := epochInstance.CheckRoundTrips()
+		problems := make([]string, len(report.Problems))
+		for i, problem := range report.Problems {
+			Expect(problem.Change).To(Equal("Rework users"))
+			problems[i] = problem.Field + ": " + problem.Problem
+		}
+		Expect(problems).To(Equal([]string{
+			"mail: requests rename 'mail' to 'email' but responses don't rename it back",
+			"name: responses add 'name' with a default instead of renaming 'full_name' back, shadowing what clients sent",
+			"user_id: responses rename 'id' to 'user_id' but requests don't rename it back",
+			"nickname: requests drop 'nickname' but responses don't return it",
+			"role: requests default 'role' but responses return it to clients that never sent it",
+		}))
+		Expect(report.Err()).To(MatchError(ContainSubstring("epoch.roundTripMember 2024-01-01->2024-06-01 (change 'Rework users'): requests rename 'mail'")))
+
+		v1, v2, change = reworkUsers()
+		_, err = NewEpoch().WithVersions(v1, v2).WithHeadVersion().WithChanges(change).WithRoundTripCheck().Build()
+		Expect(err).To(MatchError(ContainSubstring("round trip check failed: fields don't round-trip")))
+	})
+
+	It("should check types endpoints both accept and return", func() {
+		change := NewVersionChangeBuilder(v1, v2).
+			ForType(roundTripCreateMember{}).
+			RequestToNextVersion().RenameField("name", "full_name").
+			Build()
+		epochInstance, err := NewEpoch().WithVersions(v1, v2).WithHeadVersion().WithChanges(change).WithRoundTripCheck().Build()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(epochInstance.CheckRoundTrips().Empty()).To(BeTrue())
+
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.PUT("/users/:id", epochInstance.WrapHandler(func(c *gin.Context) {
+			c.JSON(http.StatusOK, []roundTripCreateMember{})
+		}).Accepts(roundTripCreateMember{}).Returns([]roundTripCreateMember{}).ToHandlerFunc("PUT", "/users/:id"))
+
+		report := epochInstance.CheckRoundTrips()
+		Expect(report.Problems).To(HaveLen(1))
+		Expect(report.Problems[0].Problem).To(Equal("requests rename 'name' to 'full_name' but responses don't rename it back"))
+	})
+})
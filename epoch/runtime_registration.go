@@ -0,0 +1,136 @@
+package epoch
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// validHTTPMethods lists the methods accepted by RegisterEndpoint
+var validHTTPMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+}
+
+// errRuntimeRegistrationDisabled is returned when runtime registration wasn't enabled at build time
+var errRuntimeRegistrationDisabled = errors.New("runtime registration is disabled; enable it with EpochBuilder.WithRuntimeRegistration()")
+
+// RegisterEndpoint registers an endpoint definition after Build()
+// This lets plugin-style services that mount routes dynamically declare their types.
+// Nested type maps are derived from the request/response types when left nil.
+// Registering the same method and path twice is an error.
+func (c *Epoch) RegisterEndpoint(method, pathPattern string, def *EndpointDefinition) error {
+	if !c.runtimeRegistration {
+		return errRuntimeRegistrationDisabled
+	}
+	if def == nil {
+		return fmt.Errorf("endpoint definition for %s %s is nil", method, pathPattern)
+	}
+	if !validHTTPMethods[method] {
+		return fmt.Errorf("invalid HTTP method %q for %s", method, pathPattern)
+	}
+	if !strings.HasPrefix(pathPattern, "/") {
+		return fmt.Errorf("path pattern %q must start with '/'", pathPattern)
+	}
+	if def.Method != "" && def.Method != method {
+		return fmt.Errorf("endpoint definition method %s does not match %s", def.Method, method)
+	}
+	if def.PathPattern != "" && def.PathPattern != pathPattern {
+		return fmt.Errorf("endpoint definition path %s does not match %s", def.PathPattern, pathPattern)
+	}
+
+	normalized := *def
+	normalized.Method = method
+	normalized.PathPattern = pathPattern
+	normalized.RequestType = derefType(def.RequestType)
+	normalized.ResponseType = derefType(def.ResponseType)
+	if normalized.RequestNestedArrays == nil || normalized.RequestNestedObjects == nil {
+		arrays, objects := BuildNestedTypeMaps(normalized.RequestType)
+		if normalized.RequestNestedArrays == nil {
+			normalized.RequestNestedArrays = arrays
+		}
+		if normalized.RequestNestedObjects == nil {
+			normalized.RequestNestedObjects = objects
+		}
+	}
+	if normalized.ResponseNestedArrays == nil || normalized.ResponseNestedObjects == nil {
+		arrays, objects := BuildNestedTypeMaps(normalized.ResponseType)
+		if normalized.ResponseNestedArrays == nil {
+			normalized.ResponseNestedArrays = arrays
+		}
+		if normalized.ResponseNestedObjects == nil {
+			normalized.ResponseNestedObjects = objects
+		}
+	}
+
+	if err := c.checkPrunedTypes(&normalized); err != nil {
+		return err
+	}
+	return c.endpointRegistry.RegisterNew(method, pathPattern, &normalized)
+}
+
+// RegisterChange adds a version change after Build()
+// Both versions must belong to the bundle and the change must not introduce a cycle.
+// In-flight requests finish with the chain they started with; new requests see the change.
+func (c *Epoch) RegisterChange(change *VersionChange) error {
+	if !c.runtimeRegistration {
+		return errRuntimeRegistrationDisabled
+	}
+	if change == nil {
+		return fmt.Errorf("version change is nil")
+	}
+
+	if c.findVersion(change.FromVersion()) == nil {
+		return fmt.Errorf("change '%s': from-version %v is not part of this Epoch instance", change.Description(), change.FromVersion())
+	}
+	if c.findVersion(change.ToVersion()) == nil {
+		return fmt.Errorf("change '%s': to-version %v is not part of this Epoch instance", change.Description(), change.ToVersion())
+	}
+	if !change.FromVersion().IsOlderThan(change.ToVersion()) {
+		return fmt.Errorf("change '%s': from-version %s must be older than to-version %s",
+			change.Description(), change.FromVersion(), change.ToVersion())
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.migrationChain.AddChange(change); err != nil {
+		return fmt.Errorf("failed to register change '%s': %w", change.Description(), err)
+	}
+
+	// Associate with the from-version like Build() does, for schema generation
+	c.versionBundle.attachChange(change)
+	change.BindTypes(c.types...)
+	return nil
+}
+
+// findVersion returns the bundle's instance of the given version (HEAD included), or nil
+func (c *Epoch) findVersion(v *Version) *Version {
+	if v == nil {
+		return nil
+	}
+	if v.IsHead {
+		return c.versionBundle.GetHeadVersion()
+	}
+	for _, candidate := range c.versionBundle.GetVersions() {
+		if candidate.Equal(v) {
+			return candidate
+		}
+	}
+	return nil
+}
+
+// derefType returns the element type for pointer types
+func derefType(t reflect.Type) reflect.Type {
+	if t != nil && t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,186 @@
+package epoch
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type pluginWidget struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+var _ = Describe("Runtime registration", func() {
+	var v1, v2 *Version
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		v1, _ = NewDateVersion("2024-01-01")
+		v2, _ = NewDateVersion("2024-06-01")
+	})
+
+	build := func(options ...func(*EpochBuilder) *EpochBuilder) *Epoch {
+		builder := NewEpoch().WithVersions(v1, v2).WithHeadVersion()
+		for _, option := range options {
+			builder = option(builder)
+		}
+		epochInstance, err := builder.Build()
+		Expect(err).NotTo(HaveOccurred())
+		return epochInstance
+	}
+
+	enabled := func(b *EpochBuilder) *EpochBuilder { return b.WithRuntimeRegistration() }
+
+	removeColor := func() *VersionChange {
+		return NewVersionChangeBuilder(v1, v2).
+			Description("Remove color for old clients").
+			ForType(pluginWidget{}).
+			ResponseToPreviousVersion().
+			RemoveField("color").
+			Build()
+	}
+
+	It("should reject registration unless enabled at build time", func() {
+		epochInstance := build()
+
+		Expect(epochInstance.RegisterEndpoint("GET", "/widgets", &EndpointDefinition{})).To(MatchError(ContainSubstring("disabled")))
+		Expect(epochInstance.RegisterChange(removeColor())).To(MatchError(ContainSubstring("disabled")))
+	})
+
+	Describe("RegisterEndpoint", func() {
+		It("should register a definition and derive nested type maps", func() {
+			epochInstance := build(enabled)
+
+			type widgetList struct {
+				Widgets []pluginWidget `json:"widgets"`
+			}
+			Expect(epochInstance.RegisterEndpoint("GET", "/widgets", &EndpointDefinition{
+				ResponseType: reflect.TypeOf(&widgetList{}),
+			})).To(Succeed())
+
+			def, err := epochInstance.EndpointRegistry().Lookup("GET", "/widgets")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(def.Method).To(Equal("GET"))
+			Expect(def.PathPattern).To(Equal("/widgets"))
+			Expect(def.ResponseType).To(Equal(reflect.TypeOf(widgetList{})))
+			Expect(def.ResponseNestedArrays).To(HaveKeyWithValue("widgets", reflect.TypeOf(pluginWidget{})))
+		})
+
+		It("should validate its arguments", func() {
+			epochInstance := build(enabled)
+
+			Expect(epochInstance.RegisterEndpoint("GET", "/widgets", nil)).NotTo(Succeed())
+			Expect(epochInstance.RegisterEndpoint("FETCH", "/widgets", &EndpointDefinition{})).NotTo(Succeed())
+			Expect(epochInstance.RegisterEndpoint("GET", "widgets", &EndpointDefinition{})).NotTo(Succeed())
+			Expect(epochInstance.RegisterEndpoint("GET", "/widgets", &EndpointDefinition{Method: "POST"})).NotTo(Succeed())
+
+			Expect(epochInstance.RegisterEndpoint("GET", "/widgets", &EndpointDefinition{})).To(Succeed())
+			Expect(epochInstance.RegisterEndpoint("GET", "/widgets", &EndpointDefinition{})).To(MatchError(ContainSubstring("already registered")))
+		})
+	})
+
+	Describe("RegisterChange", func() {
+		It("should reject changes between unknown or misordered versions", func() {
+			epochInstance := build(enabled)
+			v3, _ := NewDateVersion("2025-01-01")
+
+			Expect(epochInstance.RegisterChange(nil)).NotTo(Succeed())
+			Expect(epochInstance.RegisterChange(NewVersionChange("Unknown", v2, v3))).To(MatchError(ContainSubstring("not part of this Epoch")))
+			Expect(epochInstance.RegisterChange(NewVersionChange("Backwards", v2, v1))).To(MatchError(ContainSubstring("must be older")))
+			Expect(epochInstance.GetMigrationChain().GetChanges()).To(BeEmpty())
+		})
+
+		It("should apply to routes that were already serving requests", func() {
+			for _, templates := range []bool{false, true} {
+				v1, _ = NewDateVersion("2024-01-01")
+				v2, _ = NewDateVersion("2024-06-01")
+				options := []func(*EpochBuilder) *EpochBuilder{enabled}
+				if templates {
+					options = append(options, func(b *EpochBuilder) *EpochBuilder { return b.WithResponseTemplates() })
+				}
+				epochInstance := build(options...)
+
+				router := gin.New()
+				router.Use(epochInstance.Middleware())
+				router.GET("/widgets/:id", epochInstance.WrapHandler(func(c *gin.Context) {
+					c.JSON(200, pluginWidget{ID: 1, Name: "Gear", Color: "red"})
+				}).Returns(pluginWidget{}).ToHandlerFunc("GET", "/widgets/:id"))
+
+				get := func() string {
+					req := httptest.NewRequest("GET", "/widgets/1", nil)
+					req.Header.Set("X-API-Version", "2024-01-01")
+					recorder := httptest.NewRecorder()
+					router.ServeHTTP(recorder, req)
+					Expect(recorder.Code).To(Equal(200))
+					return recorder.Body.String()
+				}
+
+				Expect(get()).To(Equal(`{"id":1,"name":"Gear","color":"red"}`))
+
+				Expect(epochInstance.RegisterChange(removeColor())).To(Succeed())
+				Expect(epochInstance.VersionBundle().VersionChanges(v1)).To(HaveLen(1))
+
+				Expect(get()).To(Equal(`{"id":1,"name":"Gear"}`))
+			}
+		})
+
+		It("should be safe to call while requests are being served", func() {
+			epochInstance := build(enabled)
+
+			router := gin.New()
+			router.Use(epochInstance.Middleware())
+			router.GET("/widgets/:id", epochInstance.WrapHandler(func(c *gin.Context) {
+				c.JSON(200, pluginWidget{ID: 1, Name: "Gear", Color: "red"})
+			}).Returns(pluginWidget{}).ToHandlerFunc("GET", "/widgets/:id"))
+
+			var wg sync.WaitGroup
+			for i := 0; i < 8; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					defer GinkgoRecover()
+					for j := 0; j < 50; j++ {
+						req := httptest.NewRequest("GET", "/widgets/1", nil)
+						req.Header.Set("X-API-Version", "2024-01-01")
+						recorder := httptest.NewRecorder()
+						router.ServeHTTP(recorder, req)
+						Expect(recorder.Code).To(Equal(200))
+					}
+				}()
+			}
+
+			Expect(epochInstance.RegisterChange(removeColor())).To(Succeed())
+			wg.Wait()
+		})
+
+		It("should let schema generation read version changes while registering", func() {
+			epochInstance := build(enabled)
+
+			var wg sync.WaitGroup
+			for i := 0; i < 8; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					defer GinkgoRecover()
+					for j := 0; j < 50; j++ {
+						for _, v := range epochInstance.VersionBundle().GetVersions() {
+							epochInstance.VersionBundle().VersionChanges(v)
+						}
+						_, err := epochInstance.AsOf(v2)
+						Expect(err).NotTo(HaveOccurred())
+					}
+				}()
+			}
+
+			Expect(epochInstance.RegisterChange(removeColor())).To(Succeed())
+			wg.Wait()
+			Expect(epochInstance.VersionBundle().VersionChanges(v1)).To(HaveLen(1))
+		})
+	})
+})
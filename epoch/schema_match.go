@@ -0,0 +1,238 @@
+package epoch
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/astronomer/epoch/epoch/jsonast"
+	"github.com/gin-gonic/gin"
+)
+
+// SchemaMatchesKey is the context key for the schema match diagnostics of a request
+const SchemaMatchesKey = "epoch_schema_matches"
+
+// SchemaMatch explains how a body relates to the type its migrations are routed by, e.g. why
+// operations declared for a type left a payload unchanged
+type SchemaMatch struct {
+	Direction       TransformDirection
+	Type            reflect.Type // Type registered with Accepts()/Returns(); nil when none is
+	Routed          bool         // Whether type-based migrations ran for the body
+	Score           float64      // Share of the body's top-level fields the type declares, from 0 to 1
+	MatchedFields   []string     // Body fields the type declares
+	MissingFields   []string     // Fields the type declares that the body lacks
+	UnknownFields   []string     // Body fields the type doesn't declare
+	Reason          string       // Why migrations didn't run or the body doesn't fit; empty for a full match
+	SkippedElements int          // Nulls and primitives in arrays of objects, which operations skip
+	CorrelationID   string       // The request's correlation ID (see WithCorrelationIDExtractor)
+}
+
+// MatchSchema compares a HEAD-shaped body with a type: an object against a struct's fields, or
+// the items of an array against a slice's element type. Fields are compared at the top level.
+func MatchSchema(body *jsonast.Node, t reflect.Type) SchemaMatch {
+	match := SchemaMatch{Type: t, Routed: t != nil}
+	if t == nil {
+		match.Reason = "no type registered"
+		return match
+	}
+	if body == nil || !body.Exists() {
+		match.Score = 1
+		match.Reason = "no body"
+		return match
+	}
+
+	elemType := t
+	for elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+
+	objects := []*jsonast.Node{body}
+	if elemType.Kind() == reflect.Slice || elemType.Kind() == reflect.Array {
+		if body.TypeSafe() != jsonast.V_ARRAY {
+			match.Reason = "expected an array but got " + nodeKind(body)
+			return match
+		}
+		// Len counts only loaded children, and sonic loads lazily
+		if err := body.LoadAll(); err != nil {
+			match.Reason = "invalid array: " + err.Error()
+			return match
+		}
+		length, _ := body.Len()
+		objects = objects[:0]
+		for i := 0; i < length; i++ {
+			if item := body.Index(i); !isSkippedElement(item) {
+				objects = append(objects, item)
+			}
+		}
+		elemType = elemType.Elem()
+		for elemType.Kind() == reflect.Ptr {
+			elemType = elemType.Elem()
+		}
+	}
+
+	if elemType.Kind() != reflect.Struct || isBuiltinType(elemType) {
+		match.Score = 1
+		return match
+	}
+	declared := jsonFieldNames(elemType)
+	match.SkippedElements = countSkippedElements(body, t)
+
+	actual := make(map[string]bool)
+	for _, object := range objects {
+		if object.TypeSafe() != jsonast.V_OBJECT {
+			match.Reason = "expected an object but got " + nodeKind(object)
+			return match
+		}
+		for _, name := range objectFields(object) {
+			actual[name] = true
+		}
+	}
+
+	for name := range actual {
+		if declared[name] {
+			match.MatchedFields = append(match.MatchedFields, name)
+		} else {
+			match.UnknownFields = append(match.UnknownFields, name)
+		}
+	}
+	for name := range declared {
+		if !actual[name] {
+			match.MissingFields = append(match.MissingFields, name)
+		}
+	}
+	sort.Strings(match.MatchedFields)
+	sort.Strings(match.UnknownFields)
+	sort.Strings(match.MissingFields)
+
+	match.Score = 1
+	if len(actual) > 0 {
+		match.Score = float64(len(match.MatchedFields)) / float64(len(actual))
+	}
+	if len(match.UnknownFields) > 0 {
+		match.Reason = "body has fields the type doesn't declare: " + strings.Join(match.UnknownFields, ", ")
+	}
+	return match
+}
+
+// GetSchemaMatches returns the schema match diagnostics recorded for the request, request first
+func GetSchemaMatches(c *gin.Context) []SchemaMatch {
+	if c == nil {
+		return nil
+	}
+	if val, exists := c.Get(SchemaMatchesKey); exists {
+		if matches, ok := val.([]SchemaMatch); ok {
+			return matches
+		}
+	}
+	return nil
+}
+
+// WithSchemaMatchDiagnostics records how request and response bodies match their registered
+// types, readable with GetSchemaMatches and passed to observer when it's not nil. Bodies are
+// compared in HEAD's shape: requests after migration, responses before.
+func (vah *VersionAwareHandler) WithSchemaMatchDiagnostics(observer func(*gin.Context, SchemaMatch)) *VersionAwareHandler {
+	vah.schemaDiagnostics = true
+	vah.schemaMatchObserver = observer
+	return vah
+}
+
+// recordSchemaMatch stores a diagnostic in the context and hands it to the observer
+func (vah *VersionAwareHandler) recordSchemaMatch(c *gin.Context, match SchemaMatch) {
+	match.CorrelationID = CorrelationID(c)
+	c.Set(SchemaMatchesKey, append(GetSchemaMatches(c), match))
+	if vah.schemaMatchObserver != nil {
+		vah.schemaMatchObserver(c, match)
+	}
+}
+
+// recordRequestSchemaMatch records how the migrated request body matches its registered type
+func (vah *VersionAwareHandler) recordRequestSchemaMatch(c *gin.Context, requestInfo *RequestInfo, requestType reflect.Type) {
+	if !vah.schemaDiagnostics {
+		return
+	}
+	match := MatchSchema(requestInfo.Body, requestType)
+	if requestType == nil {
+		match.Reason = "no request type registered with Accepts()"
+	}
+	match.Direction = DirectionRequest
+	vah.recordSchemaMatch(c, match)
+}
+
+// recordResponseSchemaMatch records how the handler's response body matches its registered type
+func (vah *VersionAwareHandler) recordResponseSchemaMatch(c *gin.Context, capture *ResponseCapture, responseType reflect.Type) {
+	if !vah.schemaDiagnostics {
+		return
+	}
+	var body *jsonast.Node
+	if len(capture.body) > 0 {
+		body, _ = vah.jsonEngine.Parse(capture.body)
+	}
+	match := MatchSchema(body, responseType)
+	if responseType == nil {
+		match.Reason = "no response type registered with Returns()"
+	}
+	match.Direction = DirectionResponse
+	vah.recordSchemaMatch(c, match)
+}
+
+// recordSkippedSchemaMatches records that no migration touches the endpoint's types for the version
+func (vah *VersionAwareHandler) recordSkippedSchemaMatches(c *gin.Context, endpointDef *EndpointDefinition) {
+	if !vah.schemaDiagnostics {
+		return
+	}
+	for _, match := range []SchemaMatch{
+		{Direction: DirectionRequest, Type: endpointDef.RequestType},
+		{Direction: DirectionResponse, Type: endpointDef.ResponseType},
+	} {
+		match.Reason = "no migrations target the endpoint's types for this version"
+		vah.recordSchemaMatch(c, match)
+	}
+}
+
+// countSkippedElements counts the elements migrations skip (see isSkippedElement) in the arrays
+// of objects a body of type t holds, at any depth
+func countSkippedElements(node *jsonast.Node, t reflect.Type) int {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if node == nil || t == nil {
+		return 0
+	}
+
+	switch {
+	case (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && node.TypeSafe() == jsonast.V_ARRAY:
+		elemType := t.Elem()
+		for elemType.Kind() == reflect.Ptr {
+			elemType = elemType.Elem()
+		}
+		if elemType.Kind() != reflect.Struct || isBuiltinType(elemType) {
+			return 0
+		}
+		if err := node.LoadAll(); err != nil {
+			return 0
+		}
+		count := 0
+		length, _ := node.Len()
+		for i := 0; i < length; i++ {
+			item := node.Index(i)
+			if isSkippedElement(item) {
+				count++
+				continue
+			}
+			count += countSkippedElements(item, elemType)
+		}
+		return count
+
+	case t.Kind() == reflect.Struct && node.TypeSafe() == jsonast.V_OBJECT:
+		count := 0
+		nestedArrays, nestedObjects := BuildNestedTypeMaps(t)
+		for path, itemType := range nestedArrays {
+			count += countSkippedElements(GetNodeAtPath(node, path), reflect.SliceOf(itemType))
+		}
+		for path, objectType := range nestedObjects {
+			count += countSkippedElements(GetNodeAtPath(node, path), objectType)
+		}
+		return count
+	}
+	return 0
+}
@@ -0,0 +1,141 @@
+package epoch
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type matchedUser struct {
+	ID       int    `json:"id"`
+	FullName string `json:"full_name"`
+	Email    string `json:"email,omitempty"`
+}
+
+var _ = Describe("Schema match diagnostics", func() {
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+	})
+
+	match := func(raw string, t reflect.Type) SchemaMatch {
+		node, err := getNode([]byte(raw))
+		Expect(err).NotTo(HaveOccurred())
+		return MatchSchema(&node, t)
+	}
+
+	It("should count the nulls and primitives migrations skip in arrays of objects", func() {
+		result := match(`{"id":1,"items":[null,{"sku":"A"},"legacy"]}`, reflect.TypeOf(webhookPayload{}))
+		Expect(result.SkippedElements).To(Equal(2))
+
+		result = match(`[null,{"id":1,"email":"a@b.c","items":[7]},false]`, reflect.TypeOf([]webhookPayload{}))
+		Expect(result.SkippedElements).To(Equal(3))
+		Expect(result.MatchedFields).To(Equal([]string{"email", "id", "items"}))
+		Expect(result.Reason).To(BeEmpty())
+	})
+
+	It("should score the share of body fields the type declares", func() {
+		result := match(`{"id":1,"name":"Ada","nickname":"A","email":"a@b.c"}`, reflect.TypeOf(matchedUser{}))
+		Expect(result.Routed).To(BeTrue())
+		Expect(result.Score).To(Equal(0.5))
+		Expect(result.MatchedFields).To(Equal([]string{"email", "id"}))
+		Expect(result.MissingFields).To(Equal([]string{"full_name"}))
+		Expect(result.UnknownFields).To(Equal([]string{"name", "nickname"}))
+		Expect(result.Reason).To(Equal("body has fields the type doesn't declare: name, nickname"))
+	})
+
+	It("should compare array items with the element type", func() {
+		result := match(`[{"id":1},{"full_name":"Ada"}]`, reflect.TypeOf([]matchedUser{}))
+		Expect(result.Score).To(Equal(1.0))
+		Expect(result.MatchedFields).To(Equal([]string{"full_name", "id"}))
+		Expect(result.Reason).To(BeEmpty())
+
+		result = match(`{"id":1}`, reflect.TypeOf([]matchedUser{}))
+		Expect(result.Score).To(BeZero())
+		Expect(result.Reason).To(Equal("expected an array but got an object"))
+	})
+
+	It("should explain bodies without a registered type", func() {
+		result := match(`{"completely":"different"}`, nil)
+		Expect(result.Routed).To(BeFalse())
+		Expect(result.Reason).To(Equal("no type registered"))
+	})
+
+	It("should record request and response matches through the middleware", func() {
+		v1, _ := NewDateVersion("2024-01-01")
+		v2, _ := NewDateVersion("2024-06-01")
+		change := NewVersionChangeBuilder(v1, v2).
+			ForType(matchedUser{}).
+			RequestToNextVersion().
+			RenameField("name", "full_name").
+			ResponseToPreviousVersion().
+			RenameField("full_name", "name").
+			Build()
+
+		var observed []SchemaMatch
+		epochInstance, err := NewEpoch().WithVersions(v1, v2).WithHeadVersion().WithChanges(change).
+			WithSchemaMatchDiagnostics(func(_ *gin.Context, match SchemaMatch) {
+				observed = append(observed, match)
+			}).
+			Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		var recorded []SchemaMatch
+		router := gin.New()
+		router.Use(epochInstance.Middleware())
+		router.POST("/users", epochInstance.WrapHandler(func(c *gin.Context) {
+			c.JSON(200, gin.H{"id": 1, "fullName": "Ada"})
+			recorded = GetSchemaMatches(c)
+		}).Accepts(matchedUser{}).Returns(matchedUser{}).ToHandlerFunc("POST", "/users"))
+
+		req := httptest.NewRequest("POST", "/users", strings.NewReader(`{"name":"Ada","nick":"A"}`))
+		req.Header.Set("X-API-Version", "2024-01-01")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		Expect(recorded).To(HaveLen(1))
+		Expect(recorded[0].Direction).To(Equal(DirectionRequest))
+		Expect(recorded[0].MatchedFields).To(Equal([]string{"full_name"}))
+		Expect(recorded[0].UnknownFields).To(Equal([]string{"nick"}))
+
+		Expect(observed).To(HaveLen(2))
+		Expect(observed[1].Direction).To(Equal(DirectionResponse))
+		Expect(observed[1].Type).To(Equal(reflect.TypeOf(matchedUser{})))
+		Expect(observed[1].UnknownFields).To(Equal([]string{"fullName"}))
+		Expect(observed[1].Score).To(Equal(0.5))
+	})
+
+	It("should explain endpoints no migration touches", func() {
+		v1, _ := NewDateVersion("2024-01-01")
+		v2, _ := NewDateVersion("2024-06-01")
+		change := NewVersionChangeBuilder(v1, v2).
+			ForType(matchedUser{}).
+			ResponseToPreviousVersion().
+			RemoveField("email").
+			Build()
+
+		var observed []SchemaMatch
+		epochInstance, err := NewEpoch().WithVersions(v1, v2).WithHeadVersion().WithChanges(change).
+			WithSchemaMatchDiagnostics(func(_ *gin.Context, match SchemaMatch) {
+				observed = append(observed, match)
+			}).
+			Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		router := gin.New()
+		router.Use(epochInstance.Middleware())
+		router.GET("/teams", epochInstance.WrapHandler(func(c *gin.Context) {
+			c.JSON(200, TestUser{ID: 1})
+		}).Returns(TestUser{}).ToHandlerFunc("GET", "/teams"))
+
+		req := httptest.NewRequest("GET", "/teams", nil)
+		req.Header.Set("X-API-Version", "2024-01-01")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		Expect(observed).To(HaveLen(2))
+		Expect(observed[1].Type).To(Equal(reflect.TypeOf(TestUser{})))
+		Expect(observed[1].Reason).To(Equal("no migrations target the endpoint's types for this version"))
+	})
+})
@@ -0,0 +1,173 @@
+package epoch
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/astronomer/epoch/epoch/jsonast"
+)
+
+// RedactedValue replaces the values of sensitive fields in diagnostic output
+const RedactedValue = "[REDACTED]"
+
+// Scrubber returns the value diagnostic output shows for a field. path is the field's dotted
+// path with array indices collapsed to [], e.g. "members[].email"; value is the decoded
+// scalar (string, json.Number, bool or nil).
+type Scrubber func(path string, value interface{}) interface{}
+
+// WithSensitiveFields redacts fields in every body Epoch logs or exposes for diagnostics (see
+// ScrubBody). A field name matches the field at any depth; a dotted path, like "user.email"
+// or "members[].ssn", only matches there. Objects and arrays are redacted whole.
+func (cb *EpochBuilder) WithSensitiveFields(fields ...string) *EpochBuilder {
+	cb.sensitiveFields = append(cb.sensitiveFields, fields...)
+	return cb
+}
+
+// WithScrubber runs scrubber on every scalar value of the bodies Epoch logs or exposes for
+// diagnostics, after sensitive fields were redacted, e.g. to mask anything shaped like a card
+// number. Scrubbers run in the order they were added.
+func (cb *EpochBuilder) WithScrubber(scrubber Scrubber) *EpochBuilder {
+	if scrubber != nil {
+		cb.scrubbers = append(cb.scrubbers, scrubber)
+	}
+	return cb
+}
+
+// ScrubBody returns a JSON body as diagnostic output may show it, with sensitive fields
+// redacted and scrubbers applied. Epoch scrubs migration log samples with it; audit logging of
+// OriginalRequestBody should too. Bodies that aren't valid JSON, e.g. ones cut at a size limit,
+// are redacted whole. Without sensitive fields or scrubbers, the body is returned as-is.
+func (c *Epoch) ScrubBody(body []byte) []byte {
+	return c.scrubber.scrubBody(body)
+}
+
+// bodyScrubber redacts sensitive fields and applies scrubbers; a nil scrubber changes nothing
+type bodyScrubber struct {
+	names     map[string]bool // Sensitive field names, matched at any depth
+	paths     map[string]bool // Sensitive dotted paths
+	scrubbers []Scrubber
+}
+
+// newBodyScrubber returns a scrubber, or nil when there is nothing to scrub
+func newBodyScrubber(fields []string, scrubbers []Scrubber) *bodyScrubber {
+	if len(fields) == 0 && len(scrubbers) == 0 {
+		return nil
+	}
+	s := &bodyScrubber{names: make(map[string]bool), paths: make(map[string]bool), scrubbers: scrubbers}
+	for _, field := range fields {
+		if strings.ContainsAny(field, ".[") {
+			s.paths[field] = true
+		} else {
+			s.names[field] = true
+		}
+	}
+	return s
+}
+
+// scrubBody scrubs a JSON body, redacting it whole when it can't be parsed
+func (s *bodyScrubber) scrubBody(body []byte) []byte {
+	if s == nil || len(body) == 0 {
+		return body
+	}
+	engine := DefaultJSONEngine()
+	node, err := engine.Parse(body)
+	if err != nil {
+		return []byte(RedactedValue)
+	}
+	changed, err := s.scrubNode(node, "")
+	if err != nil {
+		return []byte(RedactedValue)
+	}
+	if !changed {
+		return body
+	}
+	scrubbed, err := engine.Serialize(node)
+	if err != nil {
+		return []byte(RedactedValue)
+	}
+	return scrubbed
+}
+
+// then returns a Scrub function that applies the scrubber before scrub, which may be nil
+func (s *bodyScrubber) then(scrub func([]byte) []byte) func([]byte) []byte {
+	if s == nil {
+		return scrub
+	}
+	return func(body []byte) []byte {
+		body = s.scrubBody(body)
+		if scrub != nil {
+			body = scrub(body)
+		}
+		return body
+	}
+}
+
+// scrubNode scrubs a node in place, reporting whether anything changed
+func (s *bodyScrubber) scrubNode(node *jsonast.Node, path string) (bool, error) {
+	switch node.TypeSafe() {
+	case jsonast.V_ARRAY:
+		length, err := node.Len()
+		if err != nil {
+			return false, fmt.Errorf("failed to get array length: %w", err)
+		}
+		changed := false
+		for i := 0; i < length; i++ {
+			itemChanged, err := s.scrubNode(node.Index(i), path+"[]")
+			if err != nil {
+				return false, err
+			}
+			changed = changed || itemChanged
+		}
+		return changed, nil
+
+	case jsonast.V_OBJECT:
+		if err := node.LoadAll(); err != nil {
+			return false, fmt.Errorf("failed to load object: %w", err)
+		}
+		iter, err := node.Properties()
+		if err != nil {
+			return false, err
+		}
+		var pairs []jsonast.Pair
+		var pair jsonast.Pair
+		for iter.Next(&pair) {
+			pairs = append(pairs, pair)
+		}
+
+		changed := false
+		for i := range pairs {
+			childPath := joinCanaryPath(path, pairs[i].Key)
+			if s.names[pairs[i].Key] || s.paths[childPath] {
+				pairs[i].Value = jsonast.NewString(RedactedValue)
+				changed = true
+				continue
+			}
+			valueChanged, err := s.scrubNode(&pairs[i].Value, childPath)
+			if err != nil {
+				return false, err
+			}
+			changed = changed || valueChanged
+		}
+		if changed {
+			*node = jsonast.NewObject(pairs)
+		}
+		return changed, nil
+	}
+
+	if len(s.scrubbers) == 0 || !node.Exists() {
+		return false, nil
+	}
+	original, err := node.InterfaceUseNumber()
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	value := original
+	for _, scrubber := range s.scrubbers {
+		value = scrubber(path, value)
+	}
+	if value == original {
+		return false, nil
+	}
+	*node = jsonast.NewAny(value)
+	return true, nil
+}
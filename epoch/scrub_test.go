@@ -0,0 +1,93 @@
+package epoch
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Scrubbing diagnostic output", func() {
+	var v1 *Version
+
+	BeforeEach(func() {
+		v1, _ = NewDateVersion("2024-01-01")
+	})
+
+	It("should redact sensitive fields by name and by path", func() {
+		epochInstance, err := NewEpoch().WithVersions(v1).WithHeadVersion().
+			WithSensitiveFields("password", "members[].email", "owner.phone").
+			Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		scrubbed := epochInstance.ScrubBody([]byte(
+			`{"owner":{"email":"ada@example.com","phone":"555","password":{"hash":"x"}},"members":[{"email":"bob@example.com"}]}`))
+		Expect(string(scrubbed)).To(Equal(
+			`{"owner":{"email":"ada@example.com","phone":"[REDACTED]","password":"[REDACTED]"},"members":[{"email":"[REDACTED]"}]}`))
+	})
+
+	It("should run scrubbers on every scalar value", func() {
+		var paths []string
+		epochInstance, err := NewEpoch().WithVersions(v1).WithHeadVersion().
+			WithSensitiveFields("token").
+			WithScrubber(func(path string, value interface{}) interface{} {
+				paths = append(paths, path)
+				if s, ok := value.(string); ok && strings.HasPrefix(s, "4111") {
+					return "****"
+				}
+				return value
+			}).
+			Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		scrubbed := epochInstance.ScrubBody([]byte(`{"card":"4111111111111111","tags":["a"],"token":"t","id":1}`))
+		Expect(string(scrubbed)).To(Equal(`{"card":"****","tags":["a"],"token":"[REDACTED]","id":1}`))
+		Expect(paths).To(Equal([]string{"card", "tags[]", "id"}))
+	})
+
+	It("should redact bodies that aren't JSON and leave bodies alone without configuration", func() {
+		epochInstance, err := NewEpoch().WithVersions(v1).WithHeadVersion().WithSensitiveFields("ssn").Build()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(epochInstance.ScrubBody([]byte(`{"ssn":"123-`)))).To(Equal(RedactedValue))
+		Expect(string(epochInstance.ScrubBody([]byte(`{"name":"Ada"}`)))).To(Equal(`{"name":"Ada"}`))
+
+		plain, err := NewEpoch().WithVersions(v1).WithHeadVersion().Build()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(plain.ScrubBody([]byte(`not json`)))).To(Equal("not json"))
+	})
+
+	It("should scrub migration log samples", func() {
+		v2, _ := NewDateVersion("2025-01-01")
+		change := NewVersionChangeBuilder(v1, v2).
+			ForType(logUser{}).
+			ResponseToPreviousVersion().
+			RenameField("full_name", "name").
+			Build()
+		output := &bytes.Buffer{}
+		epochInstance, err := NewEpoch().WithVersions(v1, v2).WithHeadVersion().WithChanges(change).
+			WithSensitiveFields("email").
+			WithMigrationLog(MigrationLog{Writer: output}).
+			Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.Use(epochInstance.Middleware())
+		router.GET("/users/1", epochInstance.WrapHandler(func(c *gin.Context) {
+			c.JSON(http.StatusOK, logUser{ID: 1, FullName: "Ada", Email: "ada@example.com"})
+		}).Returns(logUser{}).ToHandlerFunc("GET", "/users/:id"))
+
+		req := httptest.NewRequest("GET", "/users/1", nil)
+		req.Header.Set("X-API-Version", "2024-01-01")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		Expect(recorder.Body.String()).To(ContainSubstring("ada@example.com"))
+		Expect(output.String()).To(ContainSubstring(`\"email\":\"[REDACTED]\"`))
+		Expect(output.String()).NotTo(ContainSubstring("ada@example.com"))
+	})
+})
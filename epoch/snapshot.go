@@ -0,0 +1,63 @@
+package epoch
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/astronomer/epoch/epoch/jsonast"
+)
+
+// errSnapshotsDisabled is returned by Snapshot unless response snapshots are enabled
+var errSnapshotsDisabled = errors.New("response snapshots are disabled, enable them with WithResponseSnapshots")
+
+// WithResponseSnapshots lets custom transformers call ResponseInfo.Snapshot to compare a body
+// with its state before the current change. Each snapshot is kept serialized, only for changes
+// with instructions for the body, and only decoded when a transformer asks for it.
+func (cb *EpochBuilder) WithResponseSnapshots() *EpochBuilder {
+	cb.snapshots = true
+	return cb
+}
+
+// WithResponseSnapshots enables ResponseInfo.Snapshot for this handler's responses
+func (vah *VersionAwareHandler) WithResponseSnapshots() *VersionAwareHandler {
+	vah.snapshots = true
+	return vah
+}
+
+// Snapshot returns a copy of the body as it was before the current change's operations ran.
+// Every call returns a fresh, lazily decoded copy, so changing it affects neither the body nor later calls.
+// It returns nil for responses without a body.
+func (r *ResponseInfo) Snapshot() (*jsonast.Node, error) {
+	if !r.snapshots {
+		return nil, errSnapshotsDisabled
+	}
+	if r.snapshot == nil {
+		return nil, nil
+	}
+	node := jsonast.NewRaw(string(r.snapshot))
+	return &node, nil
+}
+
+// takeSnapshot records the body before a change runs its instructions on it. Bodies that
+// haven't been modified yet serialize back to their raw input without re-encoding.
+func (r *ResponseInfo) takeSnapshot() error {
+	r.snapshot = nil
+	if !r.snapshots || r.Body == nil {
+		return nil
+	}
+	raw, err := r.Body.MarshalJSON()
+	if err != nil {
+		return fmt.Errorf("failed to snapshot response body: %w", err)
+	}
+	r.snapshot = raw
+	return nil
+}
+
+// snapshotNested records a nested object's or array item's snapshot before a change's
+// instructions for its type run
+func snapshotNested(info TransformableBody) error {
+	if resp, ok := info.(*ResponseInfo); ok {
+		return resp.takeSnapshot()
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package epoch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type snapshotAddress struct {
+	City string `json:"city"`
+}
+
+type snapshotUser struct {
+	ID        int               `json:"id"`
+	FullName  string            `json:"full_name"`
+	Addresses []snapshotAddress `json:"addresses"`
+}
+
+var _ = Describe("Response snapshots", func() {
+	var (
+		v1, v2 *Version
+		seen   []string
+	)
+
+	// recordSnapshot notes the snapshot next to the current body, then edits the snapshot
+	recordSnapshot := func(resp *ResponseInfo) error {
+		snapshot, err := resp.Snapshot()
+		if err != nil {
+			return err
+		}
+		before, _ := snapshot.Raw()
+		after, _ := resp.Body.Raw()
+		seen = append(seen, before, after)
+		return SetNodeField(snapshot, "tampered", true)
+	}
+
+	newChange := func() *VersionChange {
+		return NewVersionChangeBuilder(v1, v2).
+			ForType(snapshotUser{}).
+			ResponseToPreviousVersion().
+			RenameField("full_name", "name").
+			Custom(recordSnapshot).
+			ForType(snapshotAddress{}).
+			ResponseToPreviousVersion().
+			RenameField("city", "town").
+			Custom(recordSnapshot).
+			Build()
+	}
+
+	BeforeEach(func() {
+		v1, _ = NewDateVersion("2024-01-01")
+		v2, _ = NewDateVersion("2025-01-01")
+		seen = nil
+	})
+
+	It("should show custom transformers the body from before the change", func() {
+		epochInstance, err := NewEpoch().WithVersions(v1, v2).WithHeadVersion().
+			WithResponseSnapshots().WithChanges(newChange()).Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		migrated, err := epochInstance.Transform(context.Background(), reflect.TypeOf(snapshotUser{}), DirectionResponse,
+			epochInstance.VersionBundle().GetHeadVersion(), v1,
+			[]byte(`{"id":1,"full_name":"Ada","addresses":[{"city":"London"}]}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(migrated)).To(MatchJSON(`{"id":1,"name":"Ada","addresses":[{"town":"London"}]}`))
+
+		Expect(seen).To(HaveLen(4))
+		Expect(seen[0]).To(MatchJSON(`{"id":1,"full_name":"Ada","addresses":[{"city":"London"}]}`))
+		Expect(seen[1]).To(MatchJSON(`{"id":1,"name":"Ada","addresses":[{"city":"London"}]}`))
+		Expect(seen[2]).To(MatchJSON(`{"city":"London"}`))
+		Expect(seen[3]).To(MatchJSON(`{"town":"London"}`))
+	})
+
+	It("should serve snapshots to transformers in wrapped handlers", func() {
+		epochInstance, err := NewEpoch().WithVersions(v1, v2).WithHeadVersion().
+			WithResponseSnapshots().WithChanges(newChange()).Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.Use(epochInstance.Middleware())
+		router.GET("/users", epochInstance.WrapHandler(func(c *gin.Context) {
+			c.JSON(http.StatusOK, snapshotUser{ID: 1, FullName: "Ada", Addresses: []snapshotAddress{}})
+		}).Returns(snapshotUser{}).ToHandlerFunc("GET", "/users"))
+
+		req := httptest.NewRequest(http.MethodGet, "/users", nil)
+		req.Header.Set("X-API-Version", "2024-01-01")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Body.String()).To(MatchJSON(`{"id":1,"name":"Ada","addresses":[]}`))
+		Expect(seen).To(HaveLen(2))
+		Expect(seen[0]).To(MatchJSON(`{"id":1,"full_name":"Ada","addresses":[]}`))
+	})
+
+	It("should fail transformers that ask for snapshots when they're disabled", func() {
+		epochInstance, err := NewEpoch().WithVersions(v1, v2).WithHeadVersion().WithChanges(newChange()).Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = epochInstance.Transform(context.Background(), reflect.TypeOf(snapshotUser{}), DirectionResponse,
+			epochInstance.VersionBundle().GetHeadVersion(), v1, []byte(`{"id":1,"full_name":"Ada"}`))
+		Expect(err).To(MatchError(ContainSubstring("enable them with WithResponseSnapshots")))
+	})
+})
@@ -0,0 +1,404 @@
+package epoch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// WithSquashedVersions composes the changes from the oldest version up to upTo into a single
+// change, once the versions in between are sunset, so migrations cross one step instead of
+// many. Consecutive operations on a field collapse: renames chain into one rename, a response
+// field renamed then removed is removed under its HEAD name, and repeated adds and removes
+// merge. The versions between the oldest version and upTo are dropped; Epoch.SquashedVersions
+// reports them. Build() migrates every golden payload, a HEAD struct value as for WithExamples,
+// through the original and the squashed chain in both directions and fails if they disagree.
+// Only changes made of ForType operations can be squashed.
+func (cb *EpochBuilder) WithSquashedVersions(upTo *Version, goldens ...interface{}) *EpochBuilder {
+	cb.squashUpTo = upTo
+	cb.squashGoldens = append(cb.squashGoldens, goldens...)
+	return cb
+}
+
+// SquashedVersions returns the versions WithSquashedVersions dropped at Build(), oldest first
+func (c *Epoch) SquashedVersions() []*Version {
+	return append([]*Version(nil), c.squashedVersions...)
+}
+
+// squashedChainChanges returns the versions and changes to build the chain from, with the
+// changes up to the squash version composed into one, and the versions it dropped
+func (cb *EpochBuilder) squashedChainChanges(versions []*Version, changes []*VersionChange) ([]*Version, []*VersionChange, []*Version, error) {
+	upTo := cb.squashUpTo
+	if upTo == nil {
+		return versions, changes, nil, nil
+	}
+	if upTo.IsHead {
+		return nil, nil, nil, errors.New("versions can't be squashed up to head")
+	}
+	if len(cb.squashGoldens) == 0 {
+		return nil, nil, nil, errors.New("squashing versions needs golden payloads to verify the squashed chain")
+	}
+
+	var oldest *Version
+	registered := false
+	for _, v := range versions {
+		if v.IsHead {
+			continue
+		}
+		if oldest == nil || v.IsOlderThan(oldest) {
+			oldest = v
+		}
+		registered = registered || v.Equal(upTo)
+	}
+	if !registered {
+		return nil, nil, nil, fmt.Errorf("cannot squash up to %w '%s'", ErrUnknownVersion, upTo.String())
+	}
+	if !upTo.IsNewerThan(oldest) {
+		return nil, nil, nil, fmt.Errorf("nothing to squash: '%s' is the oldest version", upTo.String())
+	}
+
+	var squashed, kept []*VersionChange
+	for _, change := range changes {
+		switch {
+		case change.FromVersion().IsNewerThan(upTo) || change.FromVersion().Equal(upTo):
+			kept = append(kept, change)
+		case change.ToVersion().IsNewerThan(upTo):
+			return nil, nil, nil, fmt.Errorf("change '%s' crosses squash version '%s'", change.Description(), upTo.String())
+		default:
+			if err := change.checkSquashable(); err != nil {
+				return nil, nil, nil, err
+			}
+			squashed = append(squashed, change)
+		}
+	}
+
+	var keptVersions, dropped []*Version
+	for _, v := range versions {
+		if !v.IsHead && v.IsNewerThan(oldest) && v.IsOlderThan(upTo) {
+			dropped = append(dropped, v)
+			continue
+		}
+		keptVersions = append(keptVersions, v)
+	}
+	sort.SliceStable(dropped, func(i, j int) bool { return dropped[i].IsOlderThan(dropped[j]) })
+
+	return keptVersions, append([]*VersionChange{composeChanges(squashed, oldest, upTo)}, kept...), dropped, nil
+}
+
+// checkSquashable reports why the change can't be composed with others, if it can't
+func (vc *VersionChange) checkSquashable() error {
+	if len(vc.globalRequestInstructions) > 0 || len(vc.globalResponseInstructions) > 0 ||
+		len(vc.responseEnvelopeOps) > 0 || len(vc.requestQueryOps) > 0 || len(vc.requestPathParamOps) > 0 ||
+		len(vc.typeMatchers) > 0 || vc.enabledWhen != nil {
+		return fmt.Errorf("change '%s' can't be squashed: only changes made of ForType operations can", vc.description)
+	}
+	for t := range vc.alterRequestBySchemaInstructions {
+		if _, ok := vc.requestOperationsByType[t]; !ok {
+			if _, ok := vc.responseOperationsByType[t]; !ok {
+				return fmt.Errorf("change '%s' can't be squashed: %s has request instructions without operations", vc.description, t)
+			}
+		}
+	}
+	for t := range vc.alterResponseBySchemaInstructions {
+		if _, ok := vc.responseOperationsByType[t]; !ok {
+			if _, ok := vc.requestOperationsByType[t]; !ok {
+				return fmt.Errorf("change '%s' can't be squashed: %s has response instructions without operations", vc.description, t)
+			}
+		}
+	}
+	return nil
+}
+
+// composeChanges composes changes into one change from from to to. Request operations run
+// oldest change first and response operations newest change first, as the chain runs them.
+func composeChanges(changes []*VersionChange, from, to *Version) *VersionChange {
+	sorted := append([]*VersionChange(nil), changes...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].FromVersion().IsOlderThan(sorted[j].FromVersion()) })
+
+	var types []reflect.Type
+	seen := make(map[reflect.Type]bool)
+	descriptions := make([]string, 0, len(sorted))
+	hidden := len(sorted) > 0
+	for _, change := range sorted {
+		for _, t := range change.DeclaredTypes() {
+			if !seen[t] {
+				seen[t] = true
+				types = append(types, t)
+			}
+		}
+		descriptions = append(descriptions, change.Description())
+		hidden = hidden && change.IsHiddenFromChangelog()
+	}
+
+	requestOpsByType := make(map[reflect.Type]RequestToNextVersionOperationList)
+	responseOpsByType := make(map[reflect.Type]ResponseToPreviousVersionOperationList)
+	var instructions []interface{}
+	for _, t := range types {
+		var request RequestToNextVersionOperationList
+		var response ResponseToPreviousVersionOperationList
+		for i := range sorted {
+			requestOps, _ := sorted[i].GetRequestOperationsByType(t)
+			request = append(request, requestOps...)
+			responseOps, _ := sorted[len(sorted)-1-i].GetResponseOperationsByType(t)
+			response = append(response, responseOps...)
+		}
+		request = composeOps(request, requestComposer)
+		response = composeOps(response, responseComposer)
+		if len(request) > 0 {
+			requestOpsByType[t] = request
+		}
+		if len(response) > 0 {
+			responseOpsByType[t] = response
+		}
+		instructions = append(instructions, compileTypeInstructions(t, request, response)...)
+	}
+
+	description := fmt.Sprintf("Squashed %s to %s", from.String(), to.String())
+	if len(descriptions) > 0 {
+		description += ": " + strings.Join(descriptions, "; ")
+	}
+	vc := NewVersionChange(description, from, to, instructions...)
+	vc.isHiddenFromChangelog = hidden
+	for _, t := range types {
+		if ops, ok := requestOpsByType[t]; ok {
+			vc.requestOperationsByType[t] = ops
+		}
+		if ops, ok := responseOpsByType[t]; ok {
+			vc.responseOperationsByType[t] = ops
+		}
+	}
+	return vc
+}
+
+// squashKind is what composeOps knows about an operation
+type squashKind int
+
+const (
+	squashOther  squashKind = iota // touches fields composeOps can't tell
+	squashField                    // touches its fields in a way that doesn't compose
+	squashAdd                      // adds name if missing
+	squashRemove                   // removes name
+	squashRename                   // renames from to to
+	squashMove                     // moves the from path to the to path
+)
+
+// squashOp describes an operation to composeOps
+type squashOp struct {
+	kind     squashKind
+	name     string
+	from, to string
+}
+
+// fields returns the top-level fields the operation reads or writes
+func (op squashOp) fields() []string {
+	switch op.kind {
+	case squashRename:
+		return []string{op.from, op.to}
+	case squashMove:
+		return []string{topLevelField(op.from), topLevelField(op.to)}
+	default:
+		return []string{op.name}
+	}
+}
+
+func topLevelField(path string) string {
+	if segments := SplitPath(path); len(segments) > 0 {
+		return segments[0]
+	}
+	return path
+}
+
+// opComposer tells composeOps how to read and build one direction's operations
+type opComposer[T any] struct {
+	describe func(T) squashOp
+	rename   func(from, to string) T
+	move     func(from, to string) T
+	remove   func(name string) T
+	// dropRemoved merges operations followed by a removal of their field into the removal.
+	// Request removals capture the value responses restore, so only responses do.
+	dropRemoved bool
+}
+
+var requestComposer = opComposer[RequestToNextVersionOperation]{
+	describe: func(op RequestToNextVersionOperation) squashOp {
+		switch op := op.(type) {
+		case *RequestAddField:
+			if op.Validation == FailValidation {
+				break // Adds nothing
+			}
+			return squashOp{kind: squashAdd, name: op.Name}
+		case *RequestAddFieldWithDefault:
+			return squashOp{kind: squashAdd, name: op.Name}
+		case *RequestRemoveField:
+			return squashOp{kind: squashRemove, name: op.Name}
+		case *RequestRenameField:
+			return squashOp{kind: squashRename, from: op.OlderVersionName, to: op.NewerVersionName}
+		case *RequestMoveField:
+			return squashOp{kind: squashMove, from: op.OlderVersionPath, to: op.NewerVersionPath}
+		}
+		return squashOp{kind: squashOther}
+	},
+	rename: func(from, to string) RequestToNextVersionOperation {
+		return &RequestRenameField{OlderVersionName: from, NewerVersionName: to}
+	},
+	move: func(from, to string) RequestToNextVersionOperation {
+		return &RequestMoveField{OlderVersionPath: from, NewerVersionPath: to}
+	},
+	remove: func(name string) RequestToNextVersionOperation { return &RequestRemoveField{Name: name} },
+}
+
+var responseComposer = opComposer[ResponseToPreviousVersionOperation]{
+	describe: func(op ResponseToPreviousVersionOperation) squashOp {
+		switch op := op.(type) {
+		case *ResponseAddField:
+			return squashOp{kind: squashAdd, name: op.Name}
+		case *ResponseRemoveField:
+			return squashOp{kind: squashRemove, name: op.Name}
+		case *ResponseRemoveFieldIfDefault:
+			return squashOp{kind: squashField, name: op.Name}
+		case *ResponseRenameField:
+			return squashOp{kind: squashRename, from: op.NewerVersionName, to: op.OlderVersionName}
+		case *ResponseMoveField:
+			return squashOp{kind: squashMove, from: op.NewerVersionPath, to: op.OlderVersionPath}
+		}
+		return squashOp{kind: squashOther}
+	},
+	rename: func(from, to string) ResponseToPreviousVersionOperation {
+		return &ResponseRenameField{NewerVersionName: from, OlderVersionName: to}
+	},
+	move: func(from, to string) ResponseToPreviousVersionOperation {
+		return &ResponseMoveField{NewerVersionPath: from, OlderVersionPath: to}
+	},
+	remove:      func(name string) ResponseToPreviousVersionOperation { return &ResponseRemoveField{Name: name} },
+	dropRemoved: true,
+}
+
+// composeOps merges each operation with the next one touching its fields, as long as the
+// operations between them touch neither, until no pair merges. Custom operations end the search.
+func composeOps[T any](ops []T, composer opComposer[T]) []T {
+	ops = append([]T(nil), ops...)
+	for merged := true; merged; {
+		merged = false
+		for i := 0; i < len(ops) && !merged; i++ {
+			a := composer.describe(ops[i])
+			if a.kind == squashOther {
+				continue
+			}
+			var between []string
+			for j := i + 1; j < len(ops); j++ {
+				b := composer.describe(ops[j])
+				if b.kind == squashOther {
+					break
+				}
+				if !touchesAny(a.fields(), b.fields()) {
+					between = append(between, b.fields()...)
+					continue
+				}
+				if replacement, ok := composer.merge(ops[i], a, b); ok && !touchesAny(b.fields(), between) {
+					rest := append(replacement, ops[i+1:j]...)
+					ops = append(ops[:i], append(rest, ops[j+1:]...)...)
+					merged = true
+				}
+				break
+			}
+		}
+	}
+	return ops
+}
+
+// merge returns the operations equivalent to first (described by a) followed by the operation
+// described by b: none when they cancel out, and whether they merge at all
+func (c opComposer[T]) merge(first T, a, b squashOp) ([]T, bool) {
+	switch {
+	case a.kind == squashRename && b.kind == squashRename && a.to == b.from:
+		if a.from == b.to {
+			return nil, true
+		}
+		return []T{c.rename(a.from, b.to)}, true
+	case a.kind == squashMove && b.kind == squashMove && a.to == b.from:
+		if a.from == b.to {
+			return nil, true
+		}
+		return []T{c.move(a.from, b.to)}, true
+	case a.kind == b.kind && (a.kind == squashAdd || a.kind == squashRemove) && a.name == b.name:
+		// The second add finds the field, the second removal doesn't
+		return []T{first}, true
+	case c.dropRemoved && a.kind == squashRename && b.kind == squashRemove && a.to == b.name:
+		return []T{c.remove(a.from)}, true
+	case c.dropRemoved && a.kind == squashAdd && b.kind == squashRemove && a.name == b.name:
+		return []T{c.remove(a.name)}, true
+	}
+	return nil, false
+}
+
+func touchesAny(fields, others []string) bool {
+	for _, field := range fields {
+		for _, other := range others {
+			if field == other {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// verifySquash migrates every golden payload through the original chain and the squashed one:
+// responses from HEAD down to the oldest version, then the oldest version's payload back up as
+// a request. Both chains must produce the same documents.
+func (c *Epoch) verifySquash(original *MigrationChain, goldens []interface{}) error {
+	versions := c.exampleVersions()
+	oldest, head := versions[0], versions[len(versions)-1]
+	ctx := context.Background()
+
+	var problems []string
+	for _, golden := range goldens {
+		typ := reflect.TypeOf(golden)
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		data, err := json.Marshal(golden)
+		if err != nil {
+			return fmt.Errorf("failed to encode golden payload %s: %w", typ, err)
+		}
+
+		want, err := c.transformWith(ctx, original, typ, DirectionResponse, head, oldest, data)
+		if err != nil {
+			return fmt.Errorf("golden %s response doesn't migrate through the original chain: %w", typ, err)
+		}
+		got, err := c.Transform(ctx, typ, DirectionResponse, head, oldest, data)
+		if err != nil {
+			return fmt.Errorf("golden %s response doesn't migrate through the squashed chain: %w", typ, err)
+		}
+		if problem := diffSquashed(typ, "response", want, got); problem != "" {
+			problems = append(problems, problem)
+		}
+
+		request := want
+		if want, err = c.transformWith(ctx, original, typ, DirectionRequest, oldest, head, request); err != nil {
+			return fmt.Errorf("golden %s request doesn't migrate through the original chain: %w", typ, err)
+		}
+		if got, err = c.Transform(ctx, typ, DirectionRequest, oldest, head, request); err != nil {
+			return fmt.Errorf("golden %s request doesn't migrate through the squashed chain: %w", typ, err)
+		}
+		if problem := diffSquashed(typ, "request", want, got); problem != "" {
+			problems = append(problems, problem)
+		}
+	}
+	if len(problems) > 0 {
+		return errors.New("squashed chain migrates golden payloads differently:\n  " + strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// diffSquashed describes how the squashed chain's document differs from the original chain's
+func diffSquashed(typ reflect.Type, direction string, want, got []byte) string {
+	var wantValue, gotValue interface{}
+	if json.Unmarshal(want, &wantValue) == nil && json.Unmarshal(got, &gotValue) == nil && reflect.DeepEqual(wantValue, gotValue) {
+		return ""
+	}
+	return fmt.Sprintf("%s %s: got %s, want %s", typ, direction, got, want)
+}
@@ -0,0 +1,165 @@
+package epoch
+
+import (
+	"context"
+	"reflect"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type squashUser struct {
+	ID          int      `json:"id"`
+	DisplayName string   `json:"display_name"`
+	Email       string   `json:"email"`
+	Tags        []string `json:"tags"`
+}
+
+type squashAccount struct {
+	ID          int    `json:"id"`
+	DisplayName string `json:"display_name"`
+	FullName    string `json:"full_name"`
+}
+
+var _ = Describe("Version squashing", func() {
+	var v1, v2, v3 *Version
+
+	BeforeEach(func() {
+		v1, _ = NewDateVersion("2024-01-01")
+		v2, _ = NewDateVersion("2024-06-01")
+		v3, _ = NewDateVersion("2025-01-01")
+	})
+
+	userChanges := func() []*VersionChange {
+		return []*VersionChange{
+			NewVersionChangeBuilder(v1, v2).
+				Description("Rename name to full_name").
+				ForType(squashUser{}).
+				RequestToNextVersion().RenameField("name", "full_name").
+				ResponseToPreviousVersion().RenameField("full_name", "name").
+				Build(),
+			NewVersionChangeBuilder(v2, v3).
+				Description("Rename full_name to display_name, add email").
+				ForType(squashUser{}).
+				RequestToNextVersion().RenameField("full_name", "display_name").AddField("email", "").
+				ResponseToPreviousVersion().RenameField("display_name", "full_name").RemoveField("email").
+				Build(),
+			NewVersionChangeBuilder(v3, NewHeadVersion()).
+				Description("Add tags").
+				ForType(squashUser{}).
+				RequestToNextVersion().AddField("tags", []string{}).
+				ResponseToPreviousVersion().RemoveField("tags").
+				Build(),
+		}
+	}
+	golden := squashUser{ID: 1, DisplayName: "Ada", Email: "ada@example.com", Tags: []string{"admin"}}
+
+	It("should compose the changes up to the squash version into one", func() {
+		epochInstance, err := NewEpoch().
+			WithVersions(v1, v2, v3).
+			WithHeadVersion().
+			WithChanges(userChanges()...).
+			WithSquashedVersions(v3, golden).
+			Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(epochInstance.SquashedVersions()).To(Equal([]*Version{v2}))
+		Expect(epochInstance.VersionBundle().GetVersionValues()).To(Equal([]string{"2024-01-01", "2025-01-01", "head"}))
+
+		changes := epochInstance.GetMigrationChain().GetChanges()
+		Expect(changes).To(HaveLen(2))
+		squashed := changes[0]
+		Expect(squashed.FromVersion()).To(Equal(v1))
+		Expect(squashed.ToVersion()).To(Equal(v3))
+		Expect(squashed.Description()).To(Equal("Squashed 2024-01-01 to 2025-01-01: Rename name to full_name; Rename full_name to display_name, add email"))
+
+		requestOps, _ := squashed.GetRequestOperationsByType(reflect.TypeOf(squashUser{}))
+		Expect(requestOps).To(Equal(RequestToNextVersionOperationList{
+			&RequestRenameField{OlderVersionName: "name", NewerVersionName: "display_name"},
+			&RequestAddField{Name: "email", Default: ""},
+		}))
+		responseOps, _ := squashed.GetResponseOperationsByType(reflect.TypeOf(squashUser{}))
+		Expect(responseOps).To(Equal(ResponseToPreviousVersionOperationList{
+			&ResponseRenameField{NewerVersionName: "display_name", OlderVersionName: "name"},
+			&ResponseRemoveField{Name: "email"},
+		}))
+
+		migrated, err := epochInstance.Transform(context.Background(), reflect.TypeOf(squashUser{}), DirectionResponse,
+			v3, v1, []byte(`{"id":1,"display_name":"Ada","email":"ada@example.com"}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(migrated)).To(MatchJSON(`{"id":1,"name":"Ada"}`))
+	})
+
+	It("should compose renames followed by removals and adds followed by removals", func() {
+		Expect(composeOps(ResponseToPreviousVersionOperationList{
+			&ResponseAddField{Name: "legacy", Default: true},
+			&ResponseRenameField{NewerVersionName: "a", OlderVersionName: "b"},
+			&ResponseRemoveField{Name: "legacy"},
+			&ResponseRemoveField{Name: "b"},
+			&ResponseRenameField{NewerVersionName: "c", OlderVersionName: "d"},
+			&ResponseRenameField{NewerVersionName: "d", OlderVersionName: "c"},
+		}, responseComposer)).To(Equal([]ResponseToPreviousVersionOperation{
+			&ResponseRemoveField{Name: "legacy"},
+			&ResponseRemoveField{Name: "a"},
+		}))
+
+		// Custom operations and operations on the fields in between stop the search
+		custom := &ResponseCustom{}
+		Expect(composeOps(ResponseToPreviousVersionOperationList{
+			&ResponseRenameField{NewerVersionName: "a", OlderVersionName: "b"},
+			custom,
+			&ResponseRenameField{NewerVersionName: "b", OlderVersionName: "c"},
+			&ResponseRenameField{NewerVersionName: "g", OlderVersionName: "h"},
+			&ResponseAddField{Name: "i", Default: 1},
+			&ResponseRenameField{NewerVersionName: "h", OlderVersionName: "i"},
+		}, responseComposer)).To(HaveLen(6))
+
+		// Request removals capture values responses restore, so they aren't merged away
+		Expect(composeOps(RequestToNextVersionOperationList{
+			&RequestRenameField{OlderVersionName: "a", NewerVersionName: "b"},
+			&RequestRemoveField{Name: "b"},
+			&RequestRemoveField{Name: "b"},
+		}, requestComposer)).To(Equal([]RequestToNextVersionOperation{
+			&RequestRenameField{OlderVersionName: "a", NewerVersionName: "b"},
+			&RequestRemoveField{Name: "b"},
+		}))
+	})
+
+	It("should fail Build when the squashed chain migrates a golden payload differently", func() {
+		_, err := NewEpoch().
+			WithVersions(v1, v2, v3).
+			WithHeadVersion().
+			WithChanges(
+				NewVersionChangeBuilder(v1, v2).ForType(squashAccount{}).
+					ResponseToPreviousVersion().RemoveField("full_name").Build(),
+				NewVersionChangeBuilder(v2, v3).ForType(squashAccount{}).
+					ResponseToPreviousVersion().RenameField("display_name", "full_name").Build(),
+			).
+			WithSquashedVersions(v3, squashAccount{ID: 1, DisplayName: "Ada", FullName: "Ada Lovelace"}).
+			Build()
+		Expect(err).To(MatchError(ContainSubstring("squashed chain migrates golden payloads differently")))
+		Expect(err).To(MatchError(ContainSubstring(`epoch.squashAccount response: got {"id":1,"full_name":"Ada Lovelace"}, want {"id":1}`)))
+	})
+
+	It("should reject squashes it can't compose", func() {
+		build := func(upTo *Version, changes []*VersionChange, goldens ...interface{}) error {
+			_, err := NewEpoch().WithVersions(v1, v2, v3).WithHeadVersion().
+				WithChanges(changes...).WithSquashedVersions(upTo, goldens...).Build()
+			return err
+		}
+
+		Expect(build(v3, userChanges())).To(MatchError(ContainSubstring("needs golden payloads")))
+		Expect(build(NewHeadVersion(), userChanges(), golden)).To(MatchError(ContainSubstring("can't be squashed up to head")))
+		Expect(build(v1, userChanges(), golden)).To(MatchError(ContainSubstring("'2024-01-01' is the oldest version")))
+
+		unknown, _ := NewDateVersion("2030-01-01")
+		Expect(build(unknown, userChanges(), golden)).To(MatchError(ErrUnknownVersion))
+
+		crossing := NewVersionChangeBuilder(v1, v3).ForType(squashUser{}).
+			ResponseToPreviousVersion().RemoveField("email").Build()
+		Expect(build(v2, []*VersionChange{crossing}, golden)).To(MatchError(ContainSubstring("crosses squash version '2024-06-01'")))
+
+		custom := NewVersionChangeBuilder(v1, v2).CustomResponse(func(*ResponseInfo) error { return nil }).Build()
+		Expect(build(v3, append(userChanges(), custom), golden)).To(MatchError(ContainSubstring("only changes made of ForType operations can")))
+	})
+})
@@ -0,0 +1,229 @@
+package epoch
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// EndpointStats reports the migrations of one endpoint for one version since stats were last reset
+type EndpointStats struct {
+	Method            string
+	Path              string    // Endpoint route pattern
+	Version           string    // Version the requests asked for
+	Requests          int64     // Requests that took the migration path
+	MigrationsApplied int64     // Version changes applied to those requests and their responses
+	RequestBytes      int64     // Request body bytes migrated
+	ResponseBytes     int64     // Response body bytes migrated
+	MigrationTime     Histogram // Seconds spent migrating each request and its response
+	ResponseSize      Histogram // Bytes of each migrated response body, as the handler wrote it
+}
+
+// Histogram counts observations per bucket. Counts[i] counts the observations above Bounds[i-1]
+// and up to Bounds[i]; the last count is for observations above every bound.
+type Histogram struct {
+	Bounds []float64
+	Counts []int64
+	Count  int64   // Number of observations
+	Sum    float64 // Sum of the observations
+}
+
+// Mean returns the average observation, or 0 without observations
+func (h Histogram) Mean() float64 {
+	if h.Count == 0 {
+		return 0
+	}
+	return h.Sum / float64(h.Count)
+}
+
+// observe adds an observation to its bucket
+func (h *Histogram) observe(value float64) {
+	bucket := sort.SearchFloat64s(h.Bounds, value)
+	h.Counts[bucket]++
+	h.Count++
+	h.Sum += value
+}
+
+// clone returns a copy that doesn't share the counts
+func (h Histogram) clone() Histogram {
+	h.Counts = append([]int64(nil), h.Counts...)
+	return h
+}
+
+// newHistogram returns an empty histogram over bounds, which it shares
+func newHistogram(bounds []float64) Histogram {
+	return Histogram{Bounds: bounds, Counts: make([]int64, len(bounds)+1)}
+}
+
+var (
+	// migrationTimeBounds are the MigrationTime buckets, from 100µs to 250ms
+	migrationTimeBounds = []float64{0.0001, 0.00025, 0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25}
+
+	// responseSizeBounds are the ResponseSize buckets, from 1KiB to 4MiB
+	responseSizeBounds = []float64{1 << 10, 4 << 10, 16 << 10, 64 << 10, 256 << 10, 1 << 20, 4 << 20}
+)
+
+// WithMigrationStats records per-endpoint, per-version migration counters and histograms for
+// wrapped handlers, readable at runtime with Stats() and cleared with ResetStats(). Only
+// requests that take the migration path are counted: HEAD requests and versions no change
+// touches the endpoint's types for are served without migrating and cost nothing to record.
+func (cb *EpochBuilder) WithMigrationStats() *EpochBuilder {
+	cb.stats = true
+	return cb
+}
+
+// Stats returns the recorded stats of every endpoint and version, ordered by path, method and
+// version, oldest first. It returns nil unless WithMigrationStats was used.
+func (c *Epoch) Stats() []EndpointStats {
+	if c.stats == nil {
+		return nil
+	}
+	c.stats.mu.Lock()
+	stats := make([]EndpointStats, 0, len(c.stats.endpoints))
+	for _, s := range c.stats.endpoints {
+		snapshot := *s
+		snapshot.MigrationTime = s.MigrationTime.clone()
+		snapshot.ResponseSize = s.ResponseSize.clone()
+		stats = append(stats, snapshot)
+	}
+	c.stats.mu.Unlock()
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Path != stats[j].Path {
+			return stats[i].Path < stats[j].Path
+		}
+		if stats[i].Method != stats[j].Method {
+			return stats[i].Method < stats[j].Method
+		}
+		vi, _ := c.versionBundle.ParseVersion(stats[i].Version)
+		vj, _ := c.versionBundle.ParseVersion(stats[j].Version)
+		return vi != nil && vj != nil && vi.IsOlderThan(vj)
+	})
+	return stats
+}
+
+// ResetStats clears the recorded stats, e.g. at the start of a capacity planning window
+func (c *Epoch) ResetStats() {
+	if c.stats == nil {
+		return
+	}
+	c.stats.mu.Lock()
+	defer c.stats.mu.Unlock()
+	c.stats.endpoints = make(map[statsKey]*EndpointStats)
+}
+
+// migrationStats holds the stats of every endpoint and version; safe for concurrent use
+type migrationStats struct {
+	mu        sync.Mutex
+	endpoints map[statsKey]*EndpointStats
+}
+
+func newMigrationStats() *migrationStats {
+	return &migrationStats{endpoints: make(map[statsKey]*EndpointStats)}
+}
+
+// statsKey identifies the stats of an endpoint+version
+type statsKey struct {
+	method, path, version string
+}
+
+// statsSample accumulates what migrating one request costs until it's recorded. A nil sample
+// records nothing.
+type statsSample struct {
+	stats            *migrationStats
+	key              statsKey
+	changes          int64
+	requestBytes     int64
+	responseBytes    int64
+	responseMigrated bool
+	elapsed          time.Duration
+}
+
+// withMigrationStats records this handler's migrations into stats
+func (vah *VersionAwareHandler) withMigrationStats(stats *migrationStats) *VersionAwareHandler {
+	vah.stats = stats
+	return vah
+}
+
+// startStatsSample starts sampling a request on the migration path, or returns nil without stats
+func (vah *VersionAwareHandler) startStatsSample(endpointDef *EndpointDefinition, version *Version) *statsSample {
+	if vah.stats == nil {
+		return nil
+	}
+	return &statsSample{
+		stats:   vah.stats,
+		key:     statsKey{method: endpointDef.Method, path: endpointDef.PathPattern, version: version.String()},
+		changes: vah.appliedChangeCount(endpointDef, version),
+	}
+}
+
+// appliedChangeCount counts the changes that touch an endpoint's types for a version, in both
+// directions. The count is cached alongside the bypass decision since it depends on the same inputs.
+func (vah *VersionAwareHandler) appliedChangeCount(endpointDef *EndpointDefinition, version *Version) int64 {
+	cacheKey := bypassCacheKey{
+		endpoint:   endpointDef,
+		version:    version.String(),
+		generation: vah.migrationChain.Generation(),
+	}
+	if cached, ok := vah.changeCountCache.Load(cacheKey); ok {
+		return cached.(int64)
+	}
+
+	headVersion := vah.versionBundle.GetHeadVersion()
+	requestTypes := CollectMigratableTypes(endpointDef.RequestType)
+	responseTypes := append(CollectMigratableTypes(endpointDef.ResponseType), requestTypes...)
+	count := int64(len(vah.migrationChain.DescribeMigrations(version, headVersion, DirectionRequest, requestTypes)) +
+		len(vah.migrationChain.DescribeMigrations(headVersion, version, DirectionResponse, responseTypes)))
+	vah.changeCountCache.Store(cacheKey, count)
+	return count
+}
+
+// addTime adds time spent migrating
+func (s *statsSample) addTime(elapsed time.Duration) {
+	if s != nil {
+		s.elapsed += elapsed
+	}
+}
+
+// addRequest notes the size of the migrated request body
+func (s *statsSample) addRequest(body []byte) {
+	if s != nil {
+		s.requestBytes += int64(len(body))
+	}
+}
+
+// addResponse notes the size of the response body handed to migration
+func (s *statsSample) addResponse(body []byte) {
+	if s != nil {
+		s.responseBytes += int64(len(body))
+		s.responseMigrated = true
+	}
+}
+
+// record adds the sample to its endpoint's stats
+func (s *statsSample) record() {
+	if s == nil {
+		return
+	}
+	s.stats.mu.Lock()
+	defer s.stats.mu.Unlock()
+	endpoint, ok := s.stats.endpoints[s.key]
+	if !ok {
+		endpoint = &EndpointStats{
+			Method:        s.key.method,
+			Path:          s.key.path,
+			Version:       s.key.version,
+			MigrationTime: newHistogram(migrationTimeBounds),
+			ResponseSize:  newHistogram(responseSizeBounds),
+		}
+		s.stats.endpoints[s.key] = endpoint
+	}
+	endpoint.Requests++
+	endpoint.MigrationsApplied += s.changes
+	endpoint.RequestBytes += s.requestBytes
+	endpoint.ResponseBytes += s.responseBytes
+	endpoint.MigrationTime.observe(s.elapsed.Seconds())
+	if s.responseMigrated {
+		endpoint.ResponseSize.observe(float64(s.responseBytes))
+	}
+}
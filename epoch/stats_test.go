@@ -0,0 +1,121 @@
+package epoch
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type statsUser struct {
+	ID       int    `json:"id"`
+	FullName string `json:"full_name"`
+}
+
+var _ = Describe("Migration stats", func() {
+	var (
+		v1, v2 *Version
+		router *gin.Engine
+	)
+
+	const userJSON = `{"id":1,"full_name":"Ada"}`
+
+	build := func(builder *EpochBuilder) *Epoch {
+		change := NewVersionChangeBuilder(v1, v2).
+			ForType(statsUser{}).
+			RequestToNextVersion().
+			RenameField("name", "full_name").
+			ResponseToPreviousVersion().
+			RenameField("full_name", "name").
+			Build()
+		epochInstance, err := builder.WithVersions(v1, v2).WithHeadVersion().WithChanges(change).Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		gin.SetMode(gin.TestMode)
+		router = gin.New()
+		router.Use(epochInstance.Middleware())
+		router.GET("/users/1", epochInstance.WrapHandler(func(c *gin.Context) {
+			c.Data(http.StatusOK, "application/json", []byte(userJSON))
+		}).Returns(statsUser{}).ToHandlerFunc("GET", "/users/:id"))
+		router.POST("/users", epochInstance.WrapHandler(func(c *gin.Context) {
+			c.Data(http.StatusCreated, "application/json", []byte(userJSON))
+		}).Accepts(statsUser{}).Returns(statsUser{}).ToHandlerFunc("POST", "/users"))
+		return epochInstance
+	}
+
+	send := func(method, path, version, body string) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("X-API-Version", version)
+		req.Header.Set("Content-Type", "application/json")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		Expect(recorder.Code).To(BeNumerically("<", 400))
+	}
+
+	BeforeEach(func() {
+		v1, _ = NewDateVersion("2024-01-01")
+		v2, _ = NewDateVersion("2025-01-01")
+	})
+
+	It("should count migrated requests per endpoint and version", func() {
+		epochInstance := build(NewEpoch().WithMigrationStats())
+		send("GET", "/users/1", "2024-01-01", "")
+		send("GET", "/users/1", "2024-01-01", "")
+		send("GET", "/users/1", "head", "")
+		send("POST", "/users", "2024-01-01", `{"id":1,"name":"Ada"}`)
+
+		stats := epochInstance.Stats()
+		Expect(stats).To(HaveLen(2))
+
+		Expect(stats[0].Method).To(Equal("POST"))
+		Expect(stats[0].Path).To(Equal("/users"))
+		Expect(stats[0].Version).To(Equal("2024-01-01"))
+		Expect(stats[0].Requests).To(Equal(int64(1)))
+		Expect(stats[0].MigrationsApplied).To(Equal(int64(2)))
+		Expect(stats[0].RequestBytes).To(Equal(int64(len(`{"id":1,"name":"Ada"}`))))
+		Expect(stats[0].ResponseBytes).To(Equal(int64(len(userJSON))))
+
+		Expect(stats[1].Method).To(Equal("GET"))
+		Expect(stats[1].Path).To(Equal("/users/:id"))
+		Expect(stats[1].Requests).To(Equal(int64(2)))
+		Expect(stats[1].MigrationsApplied).To(Equal(int64(2)))
+		Expect(stats[1].RequestBytes).To(BeZero())
+		Expect(stats[1].ResponseBytes).To(Equal(int64(2 * len(userJSON))))
+		Expect(stats[1].MigrationTime.Count).To(Equal(int64(2)))
+		Expect(stats[1].MigrationTime.Counts).To(HaveLen(len(stats[1].MigrationTime.Bounds) + 1))
+		Expect(stats[1].ResponseSize.Count).To(Equal(int64(2)))
+		Expect(stats[1].ResponseSize.Counts[0]).To(Equal(int64(2)))
+		Expect(stats[1].ResponseSize.Mean()).To(Equal(float64(len(userJSON))))
+	})
+
+	It("should return copies that later requests don't change", func() {
+		epochInstance := build(NewEpoch().WithMigrationStats())
+		send("GET", "/users/1", "2024-01-01", "")
+		stats := epochInstance.Stats()
+
+		send("GET", "/users/1", "2024-01-01", "")
+		Expect(stats[0].Requests).To(Equal(int64(1)))
+		Expect(stats[0].MigrationTime.Count).To(Equal(int64(1)))
+		Expect(stats[0].ResponseSize.Counts[0]).To(Equal(int64(1)))
+	})
+
+	It("should start over after a reset", func() {
+		epochInstance := build(NewEpoch().WithMigrationStats())
+		send("GET", "/users/1", "2024-01-01", "")
+		epochInstance.ResetStats()
+		Expect(epochInstance.Stats()).To(BeEmpty())
+
+		send("GET", "/users/1", "2024-01-01", "")
+		Expect(epochInstance.Stats()).To(HaveLen(1))
+		Expect(epochInstance.Stats()[0].Requests).To(Equal(int64(1)))
+	})
+
+	It("should record nothing unless enabled", func() {
+		epochInstance := build(NewEpoch())
+		send("GET", "/users/1", "2024-01-01", "")
+		Expect(epochInstance.Stats()).To(BeNil())
+	})
+})
@@ -0,0 +1,182 @@
+package epoch
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/astronomer/epoch/epoch/jsonast"
+	"github.com/gin-gonic/gin"
+)
+
+// SchemaMismatchError describes a response body that doesn't match the type registered for
+// its endpoint, so migrations targeting that type would silently do nothing
+type SchemaMismatchError struct {
+	ExpectedType  reflect.Type // Type registered with Returns()
+	ActualFields  []string     // Top-level fields of the body (of the first mismatching item for arrays)
+	UnknownFields []string     // Fields the expected type doesn't declare
+	Reason        string       // Why the body doesn't match
+}
+
+func (e *SchemaMismatchError) Error() string {
+	return fmt.Sprintf("response body doesn't match registered type %s: %s", e.ExpectedType, e.Reason)
+}
+
+// Is matches ErrSchemaMismatch
+func (e *SchemaMismatchError) Is(target error) bool {
+	return target == ErrSchemaMismatch
+}
+
+// WithStrictSchemaMatching fails responses whose body doesn't match the registered response
+// type with a 500 and diagnostics, instead of shipping them unmigrated. Meant for CI and
+// staging: enable it outside production.
+func (vah *VersionAwareHandler) WithStrictSchemaMatching() *VersionAwareHandler {
+	vah.strictSchemaMatching = true
+	return vah
+}
+
+// checkResponseSchema verifies a successful captured response against its registered type
+func (vah *VersionAwareHandler) checkResponseSchema(capture *ResponseCapture, responseType reflect.Type) *SchemaMismatchError {
+	if !vah.strictSchemaMatching || responseType == nil || capture.statusCode >= 400 || len(capture.body) == 0 {
+		return nil
+	}
+	node, err := vah.jsonEngine.Parse(capture.body)
+	if err != nil {
+		return &SchemaMismatchError{ExpectedType: responseType, Reason: "body is not valid JSON"}
+	}
+	return checkSchemaMatch(node, responseType)
+}
+
+// abortSchemaMismatch answers 500 with the mismatch diagnostics
+func abortSchemaMismatch(c *gin.Context, mismatch *SchemaMismatchError) {
+	c.AbortWithStatusJSON(500, withCorrelationID(c, gin.H{
+		"error":          "Response does not match registered type",
+		"details":        mismatch.Error(),
+		"expected_type":  mismatch.ExpectedType.String(),
+		"actual_fields":  mismatch.ActualFields,
+		"unknown_fields": mismatch.UnknownFields,
+	}))
+}
+
+// checkSchemaMatch reports whether node has the shape of t: an object for structs, whose
+// top-level fields t declares, or an array of those for slices. Other types always match.
+func checkSchemaMatch(node *jsonast.Node, t reflect.Type) *SchemaMismatchError {
+	expected := t
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return nil // []byte marshals to a string
+		}
+		if node.TypeSafe() != jsonast.V_ARRAY {
+			return &SchemaMismatchError{ExpectedType: expected, ActualFields: objectFields(node),
+				Reason: "expected an array but got " + nodeKind(node)}
+		}
+		if err := node.LoadAll(); err != nil {
+			return &SchemaMismatchError{ExpectedType: expected, Reason: "body is not valid JSON"}
+		}
+		length, _ := node.Len()
+		for i := 0; i < length; i++ {
+			if mismatch := checkSchemaMatch(node.Index(i), t.Elem()); mismatch != nil {
+				mismatch.ExpectedType = expected
+				mismatch.Reason = fmt.Sprintf("item %d: %s", i, mismatch.Reason)
+				return mismatch
+			}
+		}
+		return nil
+
+	case t.Kind() == reflect.Struct && !isBuiltinType(t):
+		if node.TypeSafe() == jsonast.V_NULL {
+			return nil
+		}
+		if node.TypeSafe() != jsonast.V_OBJECT {
+			return &SchemaMismatchError{ExpectedType: expected, Reason: "expected an object but got " + nodeKind(node)}
+		}
+		declared := jsonFieldNames(t)
+		actual := objectFields(node)
+		var unknown []string
+		for _, name := range actual {
+			if !declared[name] {
+				unknown = append(unknown, name)
+			}
+		}
+		if len(unknown) > 0 {
+			return &SchemaMismatchError{ExpectedType: expected, ActualFields: actual, UnknownFields: unknown,
+				Reason: "unknown fields " + strings.Join(unknown, ", ")}
+		}
+	}
+	return nil
+}
+
+// jsonFieldNames returns the JSON names encoding/json uses for a struct's fields,
+// including those promoted from embedded structs
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		name := strings.Split(tag, ",")[0]
+		if tag == "-" {
+			continue
+		}
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			for promoted := range jsonFieldNames(fieldType) {
+				names[promoted] = true
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names[name] = true
+	}
+	return names
+}
+
+// objectFields returns the sorted keys of an object node, or nil for other nodes
+func objectFields(node *jsonast.Node) []string {
+	if node.TypeSafe() != jsonast.V_OBJECT {
+		return nil
+	}
+	var fields []string
+	_ = node.ForEach(func(path jsonast.Sequence, child *jsonast.Node) bool {
+		if path.Key != nil {
+			fields = append(fields, *path.Key)
+		}
+		return true
+	})
+	sort.Strings(fields)
+	return fields
+}
+
+// nodeKind names a node's JSON kind for diagnostics
+func nodeKind(node *jsonast.Node) string {
+	switch node.TypeSafe() {
+	case jsonast.V_OBJECT:
+		return "an object"
+	case jsonast.V_ARRAY:
+		return "an array"
+	case jsonast.V_STRING:
+		return "a string"
+	case jsonast.V_NUMBER:
+		return "a number"
+	case jsonast.V_TRUE, jsonast.V_FALSE:
+		return "a boolean"
+	case jsonast.V_NULL:
+		return "null"
+	default:
+		return "an unknown value"
+	}
+}
@@ -0,0 +1,115 @@
+package epoch
+
+import (
+	"net/http/httptest"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type strictBase struct {
+	ID int `json:"id"`
+}
+
+type strictUser struct {
+	strictBase
+	Name     string `json:"name"`
+	Password string `json:"-"`
+	Email    string
+}
+
+type strictUserResponse struct {
+	ID       int    `json:"id"`
+	FullName string `json:"full_name"`
+}
+
+var _ = Describe("Strict schema matching", func() {
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+	})
+
+	check := func(raw string, t interface{}) *SchemaMismatchError {
+		node, err := getNode([]byte(raw))
+		Expect(err).NotTo(HaveOccurred())
+		return checkSchemaMatch(&node, reflect.TypeOf(t))
+	}
+
+	It("should accept bodies using the fields the type declares", func() {
+		Expect(check(`{"id":1,"name":"Ada","Email":"a@b.c"}`, strictUser{})).To(BeNil())
+		Expect(check(`{"id":1}`, strictUser{})).To(BeNil())
+		Expect(check(`[{"id":1},{"name":"Ada"}]`, []strictUser{})).To(BeNil())
+		Expect(check(`{"anything":1}`, map[string]int{})).To(BeNil())
+	})
+
+	It("should report unknown fields and wrong shapes", func() {
+		mismatch := check(`{"id":1,"full_name":"Ada","Password":"x"}`, strictUser{})
+		Expect(mismatch).NotTo(BeNil())
+		Expect(mismatch.ActualFields).To(Equal([]string{"Password", "full_name", "id"}))
+		Expect(mismatch.UnknownFields).To(Equal([]string{"Password", "full_name"}))
+
+		mismatch = check(`{"id":1}`, []strictUser{})
+		Expect(mismatch.Error()).To(ContainSubstring("expected an array but got an object"))
+
+		mismatch = check(`[{"id":1},{"nickname":"A"}]`, []strictUser{})
+		Expect(mismatch.Error()).To(ContainSubstring("item 1: unknown fields nickname"))
+	})
+
+	serve := func(strict bool, handler gin.HandlerFunc) *httptest.ResponseRecorder {
+		v1, v2, _ := newTestVersions()
+		change := NewVersionChangeBuilder(v1, v2).
+			ForType(strictUserResponse{}).
+			ResponseToPreviousVersion().
+			RenameField("full_name", "name").
+			Build()
+		var options []func(*EpochBuilder) *EpochBuilder
+		if strict {
+			options = append(options, (*EpochBuilder).WithStrictSchemaMatching)
+		}
+		epochInstance, err := setupHeadEpoch([]*Version{v1, v2}, []*VersionChange{change}, options...)
+		Expect(err).NotTo(HaveOccurred())
+
+		router := setupRouterWithMiddleware(epochInstance)
+		router.GET("/users/:id", epochInstance.WrapHandler(handler).
+			Returns(strictUserResponse{}).ToHandlerFunc("GET", "/users/:id"))
+
+		return serveVersioned(router, httptest.NewRequest("GET", "/users/1", nil), "2024-01-01")
+	}
+
+	// The handler returns a different struct than it registered, so RenameField never applies
+	wrongType := func(c *gin.Context) {
+		c.JSON(200, gin.H{"id": 1, "fullName": "Ada"})
+	}
+
+	It("should ship mismatching responses unmigrated by default", func() {
+		recorder := serve(false, wrongType)
+		Expect(recorder.Code).To(Equal(200))
+		Expect(recorder.Body.String()).To(MatchJSON(`{"id":1,"fullName":"Ada"}`))
+	})
+
+	It("should fail mismatching responses with diagnostics in strict mode", func() {
+		recorder := serve(true, wrongType)
+		Expect(recorder.Code).To(Equal(500))
+		Expect(recorder.Body.String()).To(MatchJSON(`{
+			"error": "Response does not match registered type",
+			"details": "response body doesn't match registered type epoch.strictUserResponse: unknown fields fullName",
+			"expected_type": "epoch.strictUserResponse",
+			"actual_fields": ["fullName", "id"],
+			"unknown_fields": ["fullName"]
+		}`))
+	})
+
+	It("should migrate matching responses and leave error responses alone in strict mode", func() {
+		recorder := serve(true, func(c *gin.Context) {
+			c.JSON(200, strictUserResponse{ID: 1, FullName: "Ada"})
+		})
+		Expect(recorder.Code).To(Equal(200))
+		Expect(recorder.Body.String()).To(MatchJSON(`{"id":1,"name":"Ada"}`))
+
+		recorder = serve(true, func(c *gin.Context) {
+			c.JSON(404, gin.H{"error": "not found"})
+		})
+		Expect(recorder.Code).To(Equal(404))
+	})
+})
@@ -0,0 +1,256 @@
+package epoch
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/astronomer/epoch/epoch/jsonast"
+	"github.com/gin-gonic/gin"
+)
+
+// structuredErrorsKey marks a request whose error responses use structured error transformation
+const structuredErrorsKey = "epoch_structured_errors"
+
+// StructuredErrors describes the machine-readable errors array of error responses
+// With it configured, Epoch renames the field of each error instead of substring-replacing
+// field names in messages, and re-renders messages in the client's locale.
+type StructuredErrors struct {
+	ErrorsField string // Field holding the errors array, default "errors"
+	FieldKey    string // Key of the offending field's name in each error, default "field"
+	CodeKey     string // Key of the machine-readable error code, default "code"
+	MessageKey  string // Key of the human-readable message, default "message"
+
+	// Renderers render messages per locale (e.g. "en", "fr-CA"), matched against Accept-Language
+	Renderers map[string]ErrorRenderer
+	// DefaultLocale picks the renderer when no requested locale has one; empty keeps messages as is
+	DefaultLocale string
+}
+
+// ErrorRenderer renders the message of a structured error for a locale
+type ErrorRenderer func(err FieldError) string
+
+// FieldError is one entry of a structured errors array, as seen by the client's version
+type FieldError struct {
+	Field         string                 // Field name in the client's version
+	HeadField     string                 // Field name in HEAD, as the handler reported it
+	Code          string                 // Machine-readable error code
+	Message       string                 // Message as the handler wrote it
+	Params        map[string]interface{} // The error's other keys, e.g. {"min": 3}
+	Locale        string                 // Locale the message is rendered for
+	CorrelationID string                 // The request's correlation ID (see WithCorrelationIDExtractor)
+}
+
+// WithStructuredErrors transforms error responses through their structured errors array
+func (vah *VersionAwareHandler) WithStructuredErrors(config StructuredErrors) *VersionAwareHandler {
+	if config.ErrorsField == "" {
+		config.ErrorsField = "errors"
+	}
+	if config.FieldKey == "" {
+		config.FieldKey = "field"
+	}
+	if config.CodeKey == "" {
+		config.CodeKey = "code"
+	}
+	if config.MessageKey == "" {
+		config.MessageKey = "message"
+	}
+	vah.structuredErrors = &config
+	return vah
+}
+
+// transformStructuredErrors renames the fields of a migrated error response's structured
+// errors to the client's version and re-renders their messages in the client's locale
+func (vah *VersionAwareHandler) transformStructuredErrors(resp *ResponseInfo, errorType reflect.Type, version *Version) error {
+	config := vah.structuredErrors
+	if config == nil || resp.StatusCode < 400 || !IsNodeObject(resp.Body) {
+		return nil
+	}
+	errorsNode := resp.Body.Get(config.ErrorsField)
+	if !IsNodeArray(errorsNode) {
+		return nil
+	}
+	correlationID := CorrelationID(resp.GinContext)
+	if correlationID != "" && !HasNodeField(resp.Body, correlationIDField) {
+		if err := SetNodeField(resp.Body, correlationIDField, correlationID); err != nil {
+			return err
+		}
+		// Setting a field may reallocate the object, so look the array up again
+		errorsNode = resp.Body.Get(config.ErrorsField)
+	}
+
+	var fieldNames map[string]string
+	if errorType != nil {
+		fieldNames = vah.migrationChain.errorFieldNames(errorType, vah.versionBundle.GetHeadVersion(), version)
+	}
+	var locale string
+	var render ErrorRenderer
+	if resp.GinContext != nil {
+		locale, render = config.renderer(resp.GinContext.GetHeader("Accept-Language"))
+	}
+
+	return ForEachNodeArrayItem(errorsNode, "", func(index int, item *jsonast.Node) error {
+		if !IsNodeObject(item) {
+			return nil
+		}
+		fieldError, err := config.fieldError(item)
+		if err != nil {
+			return fmt.Errorf("failed to read %s.%d: %w", config.ErrorsField, index, err)
+		}
+		fieldError.Field = renameErrorField(fieldError.HeadField, fieldNames)
+		if fieldError.Field != fieldError.HeadField {
+			if err := SetNodeField(item, config.FieldKey, fieldError.Field); err != nil {
+				return err
+			}
+		}
+		if render == nil {
+			return nil
+		}
+		fieldError.Locale = locale
+		fieldError.CorrelationID = correlationID
+		return SetNodeField(item, config.MessageKey, render(fieldError))
+	})
+}
+
+// fieldError reads an entry of the errors array
+func (config *StructuredErrors) fieldError(item *jsonast.Node) (FieldError, error) {
+	var fieldError FieldError
+	if err := item.LoadAll(); err != nil {
+		return fieldError, err
+	}
+	iter, err := item.Properties()
+	if err != nil {
+		return fieldError, err
+	}
+	var pair jsonast.Pair
+	for iter.Next(&pair) {
+		switch pair.Key {
+		case config.FieldKey:
+			fieldError.HeadField, _ = pair.Value.String()
+		case config.CodeKey:
+			fieldError.Code, _ = pair.Value.String()
+		case config.MessageKey:
+			fieldError.Message, _ = pair.Value.String()
+		default:
+			value, err := pair.Value.InterfaceUseNumber()
+			if err != nil {
+				return fieldError, err
+			}
+			if fieldError.Params == nil {
+				fieldError.Params = make(map[string]interface{})
+			}
+			fieldError.Params[pair.Key] = value
+		}
+	}
+	return fieldError, nil
+}
+
+// renderer picks the renderer for the most preferred locale of an Accept-Language header
+// Each locale is tried as is and then by its base language ("fr-CA", then "fr").
+func (config *StructuredErrors) renderer(acceptLanguage string) (string, ErrorRenderer) {
+	for _, locale := range acceptedLocales(acceptLanguage) {
+		if render, ok := config.Renderers[locale]; ok {
+			return locale, render
+		}
+		if base, _, found := strings.Cut(locale, "-"); found {
+			if render, ok := config.Renderers[base]; ok {
+				return base, render
+			}
+		}
+	}
+	if render, ok := config.Renderers[config.DefaultLocale]; ok {
+		return config.DefaultLocale, render
+	}
+	return "", nil
+}
+
+// acceptedLocales returns the locales of an Accept-Language header, most preferred first
+func acceptedLocales(header string) []string {
+	type weighted struct {
+		locale string
+		q      float64
+	}
+	var locales []weighted
+	for _, part := range strings.Split(header, ",") {
+		locale, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if locale == "" || locale == "*" {
+			continue
+		}
+		q := 1.0
+		if value, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q > 0 {
+			locales = append(locales, weighted{locale: locale, q: q})
+		}
+	}
+	sort.SliceStable(locales, func(i, j int) bool { return locales[i].q > locales[j].q })
+
+	result := make([]string, len(locales))
+	for i, l := range locales {
+		result[i] = l.locale
+	}
+	return result
+}
+
+// renameErrorField returns the client's name of a HEAD field named by an error
+// Dotted fields ("address.city") are renamed by their first key.
+func renameErrorField(field string, fieldNames map[string]string) string {
+	if name, ok := fieldNames[field]; ok {
+		return name
+	}
+	segments := SplitPath(field)
+	if name, ok := fieldNames[segments[0]]; ok && len(segments) > 1 {
+		segments[0] = name
+		escaped := make([]string, len(segments))
+		for i, segment := range segments {
+			escaped[i] = EscapePathKey(segment)
+		}
+		return strings.Join(escaped, ".")
+	}
+	return field
+}
+
+// errorFieldNames maps t's field names at from to their names at to, following the field
+// mappings operations between the versions report for error messages
+func (mc *MigrationChain) errorFieldNames(t reflect.Type, from, to *Version) map[string]string {
+	path := mc.GetMigrationPath(from, to)
+	sort.SliceStable(path, func(i, j int) bool {
+		return path[i].FromVersion().IsNewerThan(path[j].FromVersion())
+	})
+
+	names := make(map[string]string)
+	for _, change := range path {
+		requestOps, _ := change.GetRequestOperationsByType(t)
+		responseOps, _ := change.GetResponseOperationsByType(t)
+		mapping := requestOps.GetFieldMappings()
+		for newer, older := range responseOps.GetFieldMappings() {
+			mapping[newer] = older
+		}
+		for newer, older := range mapping {
+			found := false
+			for name, current := range names {
+				if current == newer {
+					names[name], found = older, true
+				}
+			}
+			if !found {
+				names[newer] = older
+			}
+		}
+	}
+	return names
+}
+
+// usesStructuredErrors reports whether a request's error responses use structured error transformation
+func usesStructuredErrors(c *gin.Context) bool {
+	if c == nil {
+		return false
+	}
+	_, exists := c.Get(structuredErrorsKey)
+	return exists
+}
@@ -0,0 +1,94 @@
+package epoch
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type signupRequest struct {
+	FullName string `json:"full_name"`
+	Address  string `json:"address"`
+}
+
+var _ = Describe("Structured errors", func() {
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+	})
+
+	const errorBody = `{"detail":"full_name is invalid","errors":[` +
+		`{"field":"full_name","code":"required","message":"full_name is required"},` +
+		`{"field":"address.zip","code":"too_short","message":"address.zip is too short","min":5}]}`
+
+	serve := func(config *StructuredErrors, acceptLanguage string) string {
+		v1, v2, _ := newTestVersions()
+		change := NewVersionChangeBuilder(v1, v2).
+			ForType(signupRequest{}).
+			RequestToNextVersion().
+			RenameField("name", "full_name").
+			RenameField("addr", "address").
+			Build()
+		var options []func(*EpochBuilder) *EpochBuilder
+		if config != nil {
+			options = append(options, func(builder *EpochBuilder) *EpochBuilder {
+				return builder.WithStructuredErrors(*config)
+			})
+		}
+		epochInstance, err := setupHeadEpoch([]*Version{v1, v2}, []*VersionChange{change}, options...)
+		Expect(err).NotTo(HaveOccurred())
+
+		router := setupRouterWithMiddleware(epochInstance)
+		router.POST("/signups", epochInstance.WrapHandler(func(c *gin.Context) {
+			c.Data(400, "application/json", []byte(errorBody))
+		}).Accepts(signupRequest{}).ToHandlerFunc("POST", "/signups"))
+
+		req := httptest.NewRequest("POST", "/signups", strings.NewReader(`{"name":"Ada"}`))
+		req.Header.Set("Content-Type", "application/json")
+		if acceptLanguage != "" {
+			req.Header.Set("Accept-Language", acceptLanguage)
+		}
+		recorder := serveVersioned(router, req, "2024-01-01")
+		Expect(recorder.Code).To(Equal(400))
+		return recorder.Body.String()
+	}
+
+	render := func(prefix string) ErrorRenderer {
+		return func(err FieldError) string {
+			if err.Code == "too_short" {
+				return fmt.Sprintf("%s %s: %s (min %v, HEAD %s)", prefix, err.Locale, err.Field, err.Params["min"], err.HeadField)
+			}
+			return fmt.Sprintf("%s %s: %s", prefix, err.Locale, err.Field)
+		}
+	}
+
+	It("should replace field names in messages by default", func() {
+		Expect(serve(nil, "")).To(ContainSubstring(`"detail":"name is invalid"`))
+	})
+
+	It("should rename error fields and leave messages alone", func() {
+		Expect(serve(&StructuredErrors{}, "")).To(MatchJSON(`{"detail":"full_name is invalid","errors":[
+			{"field":"name","code":"required","message":"full_name is required"},
+			{"field":"addr.zip","code":"too_short","message":"address.zip is too short","min":5}]}`))
+	})
+
+	It("should render messages in the client's preferred locale", func() {
+		config := &StructuredErrors{
+			Renderers:     map[string]ErrorRenderer{"fr": render("Erreur"), "en": render("Error")},
+			DefaultLocale: "en",
+		}
+		Expect(serve(config, "de;q=0.9, fr-CA, en;q=0.5")).To(MatchJSON(`{"detail":"full_name is invalid","errors":[
+			{"field":"name","code":"required","message":"Erreur fr: name"},
+			{"field":"addr.zip","code":"too_short","message":"Erreur fr: addr.zip (min 5, HEAD address.zip)","min":5}]}`))
+
+		Expect(serve(config, "ja")).To(ContainSubstring(`"message":"Error en: name"`))
+	})
+
+	It("should order Accept-Language locales by preference", func() {
+		Expect(acceptedLocales("de;q=0.5, fr-CA, *;q=0.1, en;q=0.8, it;q=0")).To(Equal([]string{"fr-CA", "en", "de"}))
+		Expect(acceptedLocales("")).To(BeEmpty())
+	})
+})
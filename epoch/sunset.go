@@ -0,0 +1,116 @@
+package epoch
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultSunsetWarningHeader carries sunset warnings unless SunsetWarning.Header says otherwise
+const DefaultSunsetWarningHeader = "Warning"
+
+// sunsetVersion is a deprecated version and the time it stops being served
+type sunsetVersion struct {
+	version *Version
+	sunset  time.Time
+}
+
+// SunsetWarning configures warnings for requests served deprecated versions (see WithSunsetWarning)
+type SunsetWarning struct {
+	Days   int    // Warn this many days before a version's sunset, and after it
+	Header string // Defaults to DefaultSunsetWarningHeader
+}
+
+// WithVersionSunset deprecates a version registered with WithVersions, announcing the time it
+// stops being served. Retire it with RetireVersion once the time comes.
+func (cb *EpochBuilder) WithVersionSunset(version *Version, sunset time.Time) *EpochBuilder {
+	cb.sunsets = append(cb.sunsets, sunsetVersion{version: version, sunset: sunset})
+	return cb
+}
+
+// WithSunsetWarning warns requests served a deprecated version within config.Days of its sunset,
+// so client teams see it coming in their own telemetry. The Warning header gets an RFC 7234
+// warning, e.g. 299 - "API version 2024-01-01 is deprecated and will be sunset in 12 days, on
+// 2025-06-01"; any other header gets the number of days left, 0 once the sunset has passed.
+func (cb *EpochBuilder) WithSunsetWarning(config SunsetWarning) *EpochBuilder {
+	if config.Days <= 0 {
+		cb.errors = append(cb.errors, fmt.Errorf("sunset warning needs a positive number of days, got %d", config.Days))
+		return cb
+	}
+	if config.Header == "" {
+		config.Header = DefaultSunsetWarningHeader
+	}
+	cb.sunsetWarning = &config
+	return cb
+}
+
+// checkSunsets validates the WithVersionSunset versions against the registered versions
+func (cb *EpochBuilder) checkSunsets() error {
+	seen := make(map[string]bool, len(cb.sunsets))
+	for _, s := range cb.sunsets {
+		if s.version == nil || s.version.IsHead {
+			return fmt.Errorf("sunset version must be a registered non-head version")
+		}
+		if seen[s.version.String()] {
+			return fmt.Errorf("version '%s' has more than one sunset", s.version.String())
+		}
+		seen[s.version.String()] = true
+		registered := false
+		for _, v := range cb.versions {
+			registered = registered || v.Equal(s.version)
+		}
+		if !registered {
+			return fmt.Errorf("sunset version '%s' is not registered with WithVersions", s.version.String())
+		}
+	}
+	return nil
+}
+
+// Sunset returns the time version stops being served, and whether it is deprecated
+func (c *Epoch) Sunset(version *Version) (time.Time, bool) {
+	return sunsetOf(c.sunsets, version)
+}
+
+func sunsetOf(sunsets []sunsetVersion, version *Version) (time.Time, bool) {
+	for _, s := range sunsets {
+		if s.version.Equal(version) {
+			return s.sunset, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// warnSunset adds the sunset warning to responses for deprecated versions near their sunset
+func (vm *VersionMiddleware) warnSunset(c *gin.Context, version *Version) {
+	if vm.sunsetWarning == nil {
+		return
+	}
+	sunset, ok := sunsetOf(vm.sunsets, version)
+	if !ok {
+		return
+	}
+	days := int(math.Ceil(sunset.Sub(vm.clock.Now()).Hours() / 24))
+	if days > vm.sunsetWarning.Days {
+		return
+	}
+	if days < 0 {
+		days = 0
+	}
+
+	if http.CanonicalHeaderKey(vm.sunsetWarning.Header) != DefaultSunsetWarningHeader {
+		c.Header(vm.sunsetWarning.Header, strconv.Itoa(days))
+		return
+	}
+	date := sunset.UTC().Format("2006-01-02")
+	text := fmt.Sprintf("API version %s is deprecated and was sunset on %s", version.String(), date)
+	if days == 1 {
+		text = fmt.Sprintf("API version %s is deprecated and will be sunset in 1 day, on %s", version.String(), date)
+	} else if days > 1 {
+		text = fmt.Sprintf("API version %s is deprecated and will be sunset in %d days, on %s", version.String(), days, date)
+	}
+	c.Header(DefaultSunsetWarningHeader, fmt.Sprintf("299 - %q", text))
+}
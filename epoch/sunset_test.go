@@ -0,0 +1,90 @@
+package epoch
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Sunset warnings", func() {
+	var (
+		v1, v2 *Version
+		now    time.Time
+	)
+
+	serve := func(builder *EpochBuilder, version string) http.Header {
+		epochInstance, err := builder.WithVersions(v1, v2).WithHeadVersion().WithClock(FixedClock(now)).Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		gin.SetMode(gin.TestMode)
+		router := setupRouterWithMiddleware(epochInstance)
+		router.GET("/users", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		recorder := serveVersioned(router, httptest.NewRequest("GET", "/users", nil), version)
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		return recorder.Header()
+	}
+
+	BeforeEach(func() {
+		v1, _, v2 = newTestVersions()
+		now = time.Date(2025, 5, 20, 12, 0, 0, 0, time.UTC)
+	})
+
+	It("should warn requests for deprecated versions within the window", func() {
+		builder := NewEpoch().
+			WithVersionSunset(v1, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)).
+			WithSunsetWarning(SunsetWarning{Days: 30})
+
+		header := serve(builder, "2024-01-01")
+		Expect(header.Get("Warning")).To(Equal(
+			`299 - "API version 2024-01-01 is deprecated and will be sunset in 12 days, on 2025-06-01"`))
+	})
+
+	It("should stay quiet outside the window and for other versions", func() {
+		builder := NewEpoch().
+			WithVersionSunset(v1, time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)).
+			WithSunsetWarning(SunsetWarning{Days: 30})
+		Expect(serve(builder, "2024-01-01").Get("Warning")).To(BeEmpty())
+
+		builder = NewEpoch().
+			WithVersionSunset(v1, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)).
+			WithSunsetWarning(SunsetWarning{Days: 30})
+		Expect(serve(builder, "2025-01-01").Get("Warning")).To(BeEmpty())
+	})
+
+	It("should write the days left to a custom header", func() {
+		builder := NewEpoch().
+			WithVersionSunset(v1, time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)).
+			WithSunsetWarning(SunsetWarning{Days: 30, Header: "X-API-Sunset-Days"})
+		header := serve(builder, "2024-01-01")
+		Expect(header.Get("X-API-Sunset-Days")).To(Equal("0"))
+		Expect(header.Get("Warning")).To(BeEmpty())
+
+		builder = NewEpoch().
+			WithVersionSunset(v1, time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)).
+			WithSunsetWarning(SunsetWarning{Days: 30})
+		Expect(serve(builder, "2024-01-01").Get("Warning")).To(ContainSubstring("was sunset on 2025-05-01"))
+	})
+
+	It("should report sunsets and validate them", func() {
+		sunset := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+		epochInstance, err := NewEpoch().WithVersions(v1, v2).WithVersionSunset(v1, sunset).Build()
+		Expect(err).NotTo(HaveOccurred())
+		at, ok := epochInstance.Sunset(v1)
+		Expect(ok).To(BeTrue())
+		Expect(at).To(Equal(sunset))
+		_, ok = epochInstance.Sunset(v2)
+		Expect(ok).To(BeFalse())
+
+		v3, _ := NewDateVersion("2026-01-01")
+		_, err = NewEpoch().WithVersions(v1).WithVersionSunset(v3, sunset).Build()
+		Expect(err).To(MatchError(ContainSubstring("is not registered with WithVersions")))
+
+		_, err = NewEpoch().WithVersions(v1).WithSunsetWarning(SunsetWarning{}).Build()
+		Expect(err).To(MatchError(ContainSubstring("sunset warning needs a positive number of days")))
+	})
+})
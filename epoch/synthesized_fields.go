@@ -0,0 +1,91 @@
+package epoch
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// FieldValidation says how a field AddField adds to requests of older clients meets HEAD's
+// validation, such as gin's binding:"required"
+type FieldValidation int
+
+const (
+	// SatisfyValidation adds the default, so HEAD's validation passes as if the client sent it
+	SatisfyValidation FieldValidation = iota
+
+	// FailValidation leaves the field missing, so HEAD's validation rejects clients that omit it
+	// with the error a HEAD client would get. Use it for fields HEAD made required that have no
+	// safe default; older clients that already send the field are unaffected, and older versions'
+	// OpenAPI schemas keep it. The default isn't used.
+	FailValidation
+)
+
+// String returns the validation's name
+func (v FieldValidation) String() string {
+	if v == FailValidation {
+		return "fail"
+	}
+	return "satisfy"
+}
+
+// SynthesizedFieldsKey is the context key for the names of request fields migration added
+const SynthesizedFieldsKey = "epoch_synthesized_fields"
+
+// GetSynthesizedFields returns the names of the request fields migration added because the
+// client didn't send them (AddField, AddFieldWithDefault, AddFieldFromContext), in the order
+// they were added. Like captured fields, nested fields are keyed by their name only.
+func GetSynthesizedFields(c *gin.Context) []string {
+	if c == nil {
+		return nil
+	}
+	if val, exists := c.Get(SynthesizedFieldsKey); exists {
+		if fields, ok := val.([]string); ok {
+			return fields
+		}
+	}
+	return nil
+}
+
+// IsFieldSynthesized reports whether migration added the request field, so handlers can tell
+// a default from a value the client chose
+func IsFieldSynthesized(c *gin.Context, fieldName string) bool {
+	for _, field := range GetSynthesizedFields(c) {
+		if field == fieldName {
+			return true
+		}
+	}
+	return false
+}
+
+// markFieldSynthesized records that migration added a request field
+func markFieldSynthesized(c *gin.Context, fieldName string) {
+	if c == nil || IsFieldSynthesized(c, fieldName) {
+		return
+	}
+	c.Set(SynthesizedFieldsKey, append(GetSynthesizedFields(c), fieldName))
+}
+
+// ApplyToRequestInfo adds the field like ApplyToRequest and records it as synthesized
+func (op *RequestAddField) ApplyToRequestInfo(req *RequestInfo) error {
+	return applySynthesizedField(req, op.Name, op)
+}
+
+// ApplyToRequestInfo adds the field like ApplyToRequest and records it as synthesized
+func (op *RequestAddFieldWithDefault) ApplyToRequestInfo(req *RequestInfo) error {
+	return applySynthesizedField(req, op.Name, op)
+}
+
+// applySynthesizedField applies an operation adding a missing field and, when it did add it,
+// records the field on the request's Gin context
+func applySynthesizedField(req *RequestInfo, name string, op RequestToNextVersionOperation) error {
+	if req == nil || req.Body == nil {
+		return nil
+	}
+	existed := req.Body.Get(name).Exists()
+	if err := op.ApplyToRequest(req.Body); err != nil {
+		return err
+	}
+	if !existed && req.Body.Get(name).Exists() {
+		markFieldSynthesized(req.GinContext, name)
+	}
+	return nil
+}
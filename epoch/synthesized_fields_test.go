@@ -0,0 +1,91 @@
+package epoch
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type synthesizedOrder struct {
+	Product  string `json:"product" binding:"required"`
+	Currency string `json:"currency" binding:"required"`
+	Region   string `json:"region" binding:"required"`
+	Channel  string `json:"channel"`
+}
+
+var _ = Describe("Synthesized fields", func() {
+	var (
+		v1, v2      *Version
+		router      *gin.Engine
+		synthesized []string
+		bound       synthesizedOrder
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		v1, _ = NewDateVersion("2024-01-01")
+		v2, _ = NewDateVersion("2024-06-01")
+		change := NewVersionChangeBuilder(v1, v2).
+			ForType(synthesizedOrder{}).
+			RequestToNextVersion().
+			AddField("currency", "USD").
+			AddField("region", nil, FailValidation).
+			AddFieldWithDefault("channel", "web").
+			Build()
+		epochInstance, err := NewEpoch().WithVersions(v1, v2).WithHeadVersion().WithChanges(change).Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		synthesized = nil
+		bound = synthesizedOrder{}
+		router = gin.New()
+		router.Use(epochInstance.Middleware())
+		router.POST("/orders", epochInstance.WrapHandler(func(c *gin.Context) {
+			synthesized = GetSynthesizedFields(c)
+			if err := c.ShouldBindJSON(&bound); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusCreated, gin.H{"currency_defaulted": IsFieldSynthesized(c, "currency")})
+		}).Accepts(synthesizedOrder{}).ToHandlerFunc("POST", "/orders"))
+	})
+
+	send := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/orders", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Version", "2024-01-01")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	It("should satisfy HEAD validation with defaults and report them", func() {
+		recorder := send(`{"product":"book","region":"eu"}`)
+		Expect(recorder.Code).To(Equal(http.StatusCreated))
+		Expect(recorder.Body.String()).To(MatchJSON(`{"currency_defaulted":true}`))
+		Expect(bound).To(Equal(synthesizedOrder{Product: "book", Currency: "USD", Region: "eu", Channel: "web"}))
+		Expect(synthesized).To(Equal([]string{"currency", "channel"}))
+	})
+
+	It("should fail HEAD validation for fields added with FailValidation", func() {
+		recorder := send(`{"product":"book"}`)
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		Expect(recorder.Body.String()).To(ContainSubstring("Region"))
+		Expect(synthesized).NotTo(ContainElement("region"))
+	})
+
+	It("should not report fields the client sent", func() {
+		recorder := send(`{"product":"book","currency":"EUR","region":"eu","channel":"api"}`)
+		Expect(recorder.Code).To(Equal(http.StatusCreated))
+		Expect(recorder.Body.String()).To(MatchJSON(`{"currency_defaulted":false}`))
+		Expect(synthesized).To(BeEmpty())
+	})
+
+	It("should keep fields added with FailValidation in older schemas", func() {
+		Expect(DescribeOperation(&RequestAddField{Name: "region", Validation: FailValidation}).AddedFields).To(BeEmpty())
+		Expect(DescribeOperation(&RequestAddField{Name: "currency", Default: "USD"}).AddedFields).To(HaveKey("currency"))
+	})
+})
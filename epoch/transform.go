@@ -0,0 +1,115 @@
+package epoch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+)
+
+// Transform migrates a JSON document of type typ between two versions without an HTTP request,
+// running the same chain the middleware would. DirectionRequest migrates an older document up
+// (from older to newer), DirectionResponse migrates a newer document down (from newer to older).
+// Batch jobs and CLI tools use it to reshape stored payloads, e.g. persisted webhook bodies.
+// Operations that read the request (headers, path parameters, gin context) see nil.
+func (c *Epoch) Transform(ctx context.Context, typ reflect.Type, direction TransformDirection, from, to *Version, data []byte) ([]byte, error) {
+	return c.transformWith(ctx, c.migrationChain, typ, direction, from, to, data)
+}
+
+// transformWith is Transform running the given chain instead of the Epoch's
+func (c *Epoch) transformWith(ctx context.Context, chain *MigrationChain, typ reflect.Type, direction TransformDirection, from, to *Version, data []byte) ([]byte, error) {
+	if from == nil || to == nil {
+		return nil, fmt.Errorf("transform needs both a from and a to version")
+	}
+	switch direction {
+	case DirectionRequest:
+		if from.IsNewerThan(to) {
+			return nil, fmt.Errorf("request transforms migrate from older to newer versions, got %s to %s", from, to)
+		}
+	case DirectionResponse:
+		if from.IsOlderThan(to) {
+			return nil, fmt.Errorf("response transforms migrate from newer to older versions, got %s to %s", from, to)
+		}
+	default:
+		return nil, fmt.Errorf("unknown transform direction %v", direction)
+	}
+
+	node, err := c.jsonEngine.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse document: %w", err)
+	}
+
+	opts := MigrateOptions{From: from, To: to, Type: typ}
+	if direction == DirectionRequest {
+		info := &RequestInfo{Body: node, Headers: http.Header{}}
+		if err := chain.Migrate(ctx, info, opts); err != nil {
+			return nil, fmt.Errorf("failed to migrate document: %w", err)
+		}
+		node = info.Body
+	} else {
+		info := &ResponseInfo{Body: node, StatusCode: http.StatusOK, Headers: http.Header{}, snapshots: c.snapshots}
+		if err := chain.Migrate(ctx, info, opts); err != nil {
+			return nil, fmt.Errorf("failed to migrate document: %w", err)
+		}
+		node = info.Body
+	}
+
+	migrated, err := c.jsonEngine.Serialize(node)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize document: %w", err)
+	}
+	return migrated, nil
+}
+
+// TransformToVersions renders one HEAD response document into several older versions, keyed by
+// version string. The document is parsed once and migrated down newest version first,
+// serializing a copy at each requested version, so later versions reuse the earlier steps.
+// References expanded by ExpandReference are fetched after each version rather than once.
+func (c *Epoch) TransformToVersions(ctx context.Context, typ reflect.Type, data []byte, versions ...*Version) (map[string][]byte, error) {
+	targets := make([]*Version, 0, len(versions))
+	seen := make(map[string]bool, len(versions))
+	for _, v := range versions {
+		if v == nil {
+			return nil, fmt.Errorf("transform needs a version for every rendering")
+		}
+		if !seen[v.String()] {
+			seen[v.String()] = true
+			targets = append(targets, v)
+		}
+	}
+	sort.SliceStable(targets, func(i, j int) bool {
+		return targets[i].IsNewerThan(targets[j])
+	})
+
+	node, err := c.jsonEngine.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse document: %w", err)
+	}
+
+	var nestedArrays, nestedObjects map[string]reflect.Type
+	if typ != nil {
+		if typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		// Discovered once, for every version
+		nestedArrays, nestedObjects = BuildNestedTypeMaps(typ)
+	}
+
+	rendered := make(map[string][]byte, len(targets))
+	info := &ResponseInfo{Body: node, StatusCode: http.StatusOK, Headers: http.Header{}, snapshots: c.snapshots}
+	from := c.versionBundle.GetHeadVersion()
+	for _, to := range targets {
+		opts := MigrateOptions{From: from, To: to, Type: typ, NestedArrays: nestedArrays, NestedObjects: nestedObjects}
+		if err := c.migrationChain.Migrate(ctx, info, opts); err != nil {
+			return nil, fmt.Errorf("failed to migrate document to %s: %w", to, err)
+		}
+		migrated, err := c.jsonEngine.Serialize(info.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize document for %s: %w", to, err)
+		}
+		rendered[to.String()] = migrated
+		from = to
+	}
+	return rendered, nil
+}
@@ -0,0 +1,156 @@
+package epoch
+
+import (
+	"context"
+	"reflect"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type webhookItem struct {
+	SKU      string `json:"sku"`
+	Quantity int    `json:"quantity"`
+}
+
+type webhookPayload struct {
+	ID    int64         `json:"id"`
+	Email string        `json:"email"`
+	Items []webhookItem `json:"items"`
+}
+
+var _ = Describe("Transform", func() {
+	var (
+		epochInstance *Epoch
+		v1, v2, head  *Version
+		payloadType   = reflect.TypeOf(webhookPayload{})
+	)
+
+	BeforeEach(func() {
+		v1, _ = NewDateVersion("2024-01-01")
+		v2, _ = NewDateVersion("2024-06-01")
+		payload := NewVersionChangeBuilder(v1, v2).
+			ForType(webhookPayload{}).
+			RequestToNextVersion().
+			RenameField("mail", "email").
+			ResponseToPreviousVersion().
+			RenameField("email", "mail").
+			Build()
+		item := NewVersionChangeBuilder(v1, v2).
+			ForType(webhookItem{}).
+			RequestToNextVersion().
+			RenameField("qty", "quantity").
+			ResponseToPreviousVersion().
+			RenameField("quantity", "qty").
+			Build()
+
+		var err error
+		epochInstance, err = NewEpoch().WithVersions(v1, v2).WithHeadVersion().WithChanges(payload, item).Build()
+		Expect(err).NotTo(HaveOccurred())
+		head = epochInstance.VersionBundle().GetHeadVersion()
+	})
+
+	It("should bring stored documents up to HEAD", func() {
+		migrated, err := epochInstance.Transform(context.Background(), payloadType, DirectionRequest, v1, head,
+			[]byte(`{"id":9007199254740993,"mail":"a@b.c","items":[{"sku":"A","qty":2}]}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(migrated)).To(Equal(`{"id":9007199254740993,"email":"a@b.c","items":[{"sku":"A","quantity":2}]}`))
+	})
+
+	It("should take HEAD documents down to older versions", func() {
+		migrated, err := epochInstance.Transform(context.Background(), reflect.TypeOf([]webhookPayload{}), DirectionResponse, head, v1,
+			[]byte(`[{"id":1,"email":"a@b.c","items":[]}]`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(migrated)).To(MatchJSON(`[{"id":1,"mail":"a@b.c","items":[]}]`))
+	})
+
+	It("should leave documents alone between equal versions", func() {
+		migrated, err := epochInstance.Transform(context.Background(), payloadType, DirectionRequest, v2, head, []byte(`{"email":"a@b.c"}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(migrated)).To(Equal(`{"email":"a@b.c"}`))
+	})
+
+	It("should skip nulls and primitives in arrays of objects", func() {
+		migrated, err := epochInstance.Transform(context.Background(), payloadType, DirectionRequest, v1, head,
+			[]byte(`{"id":1,"mail":"a@b.c","items":[null,{"sku":"A","qty":2},"legacy",3]}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(migrated)).To(MatchJSON(`{"id":1,"email":"a@b.c","items":[null,{"sku":"A","quantity":2},"legacy",3]}`))
+
+		migrated, err = epochInstance.Transform(context.Background(), reflect.TypeOf([]webhookPayload{}), DirectionResponse, head, v1,
+			[]byte(`[null,{"id":1,"email":"a@b.c","items":[]},true]`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(migrated)).To(MatchJSON(`[null,{"id":1,"mail":"a@b.c","items":[]},true]`))
+	})
+
+	It("should reject versions in the wrong order and malformed documents", func() {
+		_, err := epochInstance.Transform(context.Background(), payloadType, DirectionRequest, head, v1, []byte(`{}`))
+		Expect(err).To(MatchError(ContainSubstring("from older to newer")))
+
+		_, err = epochInstance.Transform(context.Background(), payloadType, DirectionResponse, v1, head, []byte(`{}`))
+		Expect(err).To(MatchError(ContainSubstring("from newer to older")))
+
+		_, err = epochInstance.Transform(context.Background(), payloadType, DirectionRequest, v1, head, []byte(`{"id":`))
+		Expect(err).To(MatchError(ContainSubstring("failed to parse document")))
+	})
+	Describe("TransformToVersions", func() {
+		var v0 *Version
+
+		BeforeEach(func() {
+			v0, _ = NewDateVersion("2023-01-01")
+			v1, _ = NewDateVersion("2024-01-01")
+			v2, _ = NewDateVersion("2024-06-01")
+			split := NewVersionChangeBuilder(v0, v1).
+				ForType(webhookPayload{}).
+				ResponseToPreviousVersion().
+				RenameField("mail", "contact").
+				Build()
+			rename := NewVersionChangeBuilder(v1, v2).
+				ForType(webhookPayload{}).
+				ResponseToPreviousVersion().
+				RenameField("email", "mail").
+				Build()
+			item := NewVersionChangeBuilder(v1, v2).
+				ForType(webhookItem{}).
+				ResponseToPreviousVersion().
+				RenameField("quantity", "qty").
+				Build()
+
+			var err error
+			epochInstance, err = NewEpoch().WithVersions(v0, v1, v2).WithHeadVersion().WithChanges(split, rename, item).Build()
+			Expect(err).NotTo(HaveOccurred())
+			head = epochInstance.VersionBundle().GetHeadVersion()
+		})
+
+		It("should render a HEAD document into every requested version", func() {
+			rendered, err := epochInstance.TransformToVersions(context.Background(), payloadType,
+				[]byte(`{"id":1,"email":"a@b.c","items":[{"sku":"A","quantity":2}]}`), v0, head, v1, v2, v1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rendered).To(HaveLen(4))
+			Expect(string(rendered["head"])).To(MatchJSON(`{"id":1,"email":"a@b.c","items":[{"sku":"A","quantity":2}]}`))
+			Expect(string(rendered["2024-06-01"])).To(MatchJSON(`{"id":1,"email":"a@b.c","items":[{"sku":"A","quantity":2}]}`))
+			Expect(string(rendered["2024-01-01"])).To(MatchJSON(`{"id":1,"mail":"a@b.c","items":[{"sku":"A","qty":2}]}`))
+			Expect(string(rendered["2023-01-01"])).To(MatchJSON(`{"id":1,"contact":"a@b.c","items":[{"sku":"A","qty":2}]}`))
+		})
+
+		It("should match rendering each version separately", func() {
+			data := []byte(`[{"id":1,"email":"a@b.c","items":[{"sku":"A","quantity":2}]}]`)
+			listType := reflect.TypeOf([]webhookPayload{})
+			rendered, err := epochInstance.TransformToVersions(context.Background(), listType, data, v1, v0)
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, v := range []*Version{v0, v1} {
+				single, err := epochInstance.Transform(context.Background(), listType, DirectionResponse, head, v, data)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(rendered[v.String()])).To(MatchJSON(string(single)))
+			}
+		})
+
+		It("should reject missing versions and malformed documents", func() {
+			_, err := epochInstance.TransformToVersions(context.Background(), payloadType, []byte(`{}`), v1, nil)
+			Expect(err).To(MatchError(ContainSubstring("needs a version for every rendering")))
+
+			_, err = epochInstance.TransformToVersions(context.Background(), payloadType, []byte(`{"id":`), v1)
+			Expect(err).To(MatchError(ContainSubstring("failed to parse document")))
+		})
+	})
+})
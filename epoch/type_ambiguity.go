@@ -0,0 +1,80 @@
+package epoch
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// AmbiguousTypePair is two types with identical JSON fields, e.g. Skill and SkillRequest.
+// Nothing in a body or an inline schema tells them apart, so the OpenAPI generator chooses
+// between them by where each is registered: the Go field an inline schema comes from, then
+// whether it's nested in a request or a response.
+type AmbiguousTypePair struct {
+	A, B   reflect.Type // In name order
+	Fields []string     // The JSON fields both have, sorted
+}
+
+// String describes the pair, for logging
+func (p AmbiguousTypePair) String() string {
+	return fmt.Sprintf("%s and %s have identical JSON fields (%s)", p.A, p.B, strings.Join(p.Fields, ", "))
+}
+
+// AmbiguousTypes returns the pairs of types Build() found with identical JSON fields, among
+// the WithTypes types, their nested types and the types changes target with ForType. Log them
+// at startup, or fail a test on them, to catch types that can be mistaken for one another.
+func (c *Epoch) AmbiguousTypes() []AmbiguousTypePair {
+	return append([]AmbiguousTypePair(nil), c.ambiguousTypes...)
+}
+
+// ambiguousTypes returns the pairs of registered and targeted struct types with identical JSON
+// fields, ordered by type name
+func (cb *EpochBuilder) ambiguousTypes(types []reflect.Type) []AmbiguousTypePair {
+	candidates := append([]reflect.Type(nil), types...)
+	for _, change := range cb.changes {
+		candidates = append(candidates, change.DeclaredTypes()...)
+	}
+
+	seen := make(map[reflect.Type]bool, len(candidates))
+	bySignature := make(map[string][]reflect.Type)
+	fields := make(map[string][]string)
+	for _, t := range candidates {
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t == nil || seen[t] || t.Kind() != reflect.Struct || isBuiltinType(t) {
+			continue
+		}
+		seen[t] = true
+
+		names := make([]string, 0, t.NumField())
+		for _, field := range orderedJSONFields(t) {
+			names = append(names, field.name)
+		}
+		if len(names) == 0 {
+			continue
+		}
+		sort.Strings(names)
+		signature := strings.Join(names, "\x00")
+		bySignature[signature] = append(bySignature[signature], t)
+		fields[signature] = names
+	}
+
+	var pairs []AmbiguousTypePair
+	for signature, group := range bySignature {
+		sort.Slice(group, func(i, j int) bool { return group[i].String() < group[j].String() })
+		for i := range group {
+			for j := i + 1; j < len(group); j++ {
+				pairs = append(pairs, AmbiguousTypePair{A: group[i], B: group[j], Fields: fields[signature]})
+			}
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].A != pairs[j].A {
+			return pairs[i].A.String() < pairs[j].A.String()
+		}
+		return pairs[i].B.String() < pairs[j].B.String()
+	})
+	return pairs
+}
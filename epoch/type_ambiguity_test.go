@@ -0,0 +1,72 @@
+package epoch
+
+import (
+	"reflect"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type ambiguousSkill struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type ambiguousSkillRequest struct {
+	Name string `json:"name"`
+	ID   int    `json:"id"`
+}
+
+type ambiguousProfile struct {
+	ID     int              `json:"id"`
+	Skills []ambiguousSkill `json:"skills"`
+}
+
+type ambiguousTimestamps struct {
+	Created string `json:"created"`
+}
+
+// ambiguousAudit has ambiguousCreated's fields through embedding
+type ambiguousAudit struct {
+	ambiguousTimestamps
+	internal string
+}
+
+type ambiguousCreated struct {
+	Created string `json:"created"`
+}
+
+var _ = Describe("Ambiguous types", func() {
+	var v1, v2 *Version
+
+	BeforeEach(func() {
+		v1, _ = NewDateVersion("2024-01-01")
+		v2, _ = NewDateVersion("2025-01-01")
+	})
+
+	It("should report registered and targeted types with identical JSON fields", func() {
+		change := NewVersionChangeBuilder(v1, v2).
+			ForType(ambiguousSkillRequest{}).
+			RequestToNextVersion().
+			AddField("level", 1).
+			Build()
+		epochInstance, err := NewEpoch().WithVersions(v1, v2).WithHeadVersion().
+			WithTypes(ambiguousProfile{}, ambiguousAudit{}, ambiguousCreated{}).WithChanges(change).Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		pairs := epochInstance.AmbiguousTypes()
+		Expect(pairs).To(HaveLen(2))
+		Expect(pairs[0].A).To(Equal(reflect.TypeOf(ambiguousAudit{})))
+		Expect(pairs[0].B).To(Equal(reflect.TypeOf(ambiguousCreated{})))
+		Expect(pairs[0].Fields).To(Equal([]string{"created"}))
+		Expect(pairs[1].A).To(Equal(reflect.TypeOf(ambiguousSkill{})))
+		Expect(pairs[1].B).To(Equal(reflect.TypeOf(ambiguousSkillRequest{})))
+		Expect(pairs[1].String()).To(Equal("epoch.ambiguousSkill and epoch.ambiguousSkillRequest have identical JSON fields (id, name)"))
+	})
+
+	It("should report nothing when every type's fields differ", func() {
+		epochInstance, err := NewEpoch().WithVersions(v1).WithHeadVersion().WithTypes(ambiguousProfile{}).Build()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(epochInstance.AmbiguousTypes()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,414 @@
+package epoch
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RenameGuess tells how DiffTypes matched a renamed field
+type RenameGuess string
+
+const (
+	// RenameGuessTag matches fields whose Go name stayed the same while their JSON tag changed
+	RenameGuessTag RenameGuess = "tag"
+	// RenameGuessSimilarity matches fields with alike names and the same kind of value
+	RenameGuessSimilarity RenameGuess = "similarity"
+)
+
+const (
+	// renameSimilarityThreshold is the lowest name similarity DiffTypes treats as a rename
+	renameSimilarityThreshold = 0.5
+	// respellingThreshold is the lowest edit similarity that counts toward name similarity
+	respellingThreshold = 0.7
+)
+
+// FieldRename is a field DiffTypes guessed was renamed
+type FieldRename struct {
+	Older, Newer string // JSON names
+	Guess        RenameGuess
+}
+
+// DiffField is a field only one of the diffed types has
+type DiffField struct {
+	Name    string
+	Default interface{} // Zero value of its kind for AddField: "", 0, false, or nil for others
+}
+
+// TypeDiff is a suggested migration between an older and a newer shape of a type, to
+// bootstrap changes for frozen structs that predate Epoch. Renames are guesses: review
+// them, and the Retyped fields, before adopting the change.
+type TypeDiff struct {
+	TypeName string        // Newer type's name, for ForType
+	Renamed  []FieldRename // In the newer type's field order
+	Added    []DiffField   // Fields only the newer type has
+	Removed  []DiffField   // Fields only the older type has
+	Retyped  []string      // Fields both have with a different kind of value; they need a Custom operation
+
+	newer reflect.Type
+}
+
+// FieldShape describes a JSON field for DiffFields
+type FieldShape struct {
+	Name     string // JSON name
+	GoName   string // Name of the Go field it comes from, when known
+	Kind     string // JSON kind of its value: "string", "number", "boolean", "array", "object", or "" when unknown
+	Nullable bool   // Whether it may be null, which makes nil its AddField default
+}
+
+// DiffTypes compares the JSON fields of two struct types, e.g. DiffTypes(UserV2{}, UserHEAD{}).
+// A field that kept its Go name but changed its JSON tag is a rename, as is a field whose name
+// is similar to one the other type lost and holds the same kind of value. Other fields are
+// added or removed.
+func DiffTypes(older, newer interface{}) (*TypeDiff, error) {
+	olderType, err := diffStructType(older)
+	if err != nil {
+		return nil, err
+	}
+	newerType, err := diffStructType(newer)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := DiffFields(newerType.Name(), fieldShapes(olderType), fieldShapes(newerType))
+	diff.newer = newerType
+	return diff, nil
+}
+
+// DiffFields is DiffTypes for fields described some other way, e.g. by OpenAPI schemas. The
+// diff has no type to build a VersionChange for; its Code uses typeName.
+func DiffFields(typeName string, older, newer []FieldShape) *TypeDiff {
+	diff := &TypeDiff{TypeName: typeName}
+
+	olderByName := make(map[string]FieldShape, len(older))
+	for _, field := range older {
+		olderByName[field.Name] = field
+	}
+	matchedOlder := make(map[string]bool)
+	var unmatched []int // Indexes of newer fields the older ones don't have
+	for i, field := range newer {
+		previous, ok := olderByName[field.Name]
+		if !ok {
+			unmatched = append(unmatched, i)
+			continue
+		}
+		matchedOlder[field.Name] = true
+		if !sameJSONKind(previous.Kind, field.Kind) {
+			diff.Retyped = append(diff.Retyped, field.Name)
+		}
+	}
+
+	renamedTo := make(map[int]FieldRename)
+	// A Go field that kept its name only had its tag changed
+	for _, i := range unmatched {
+		if newer[i].GoName == "" {
+			continue
+		}
+		for _, previous := range older {
+			if !matchedOlder[previous.Name] && previous.GoName == newer[i].GoName {
+				renamedTo[i] = FieldRename{Older: previous.Name, Newer: newer[i].Name, Guess: RenameGuessTag}
+				matchedOlder[previous.Name] = true
+				break
+			}
+		}
+	}
+
+	// Then the most similar names first
+	type candidate struct {
+		newer, older int
+		score        float64
+	}
+	var candidates []candidate
+	for _, i := range unmatched {
+		if _, ok := renamedTo[i]; ok {
+			continue
+		}
+		for j, previous := range older {
+			if matchedOlder[previous.Name] || !sameJSONKind(previous.Kind, newer[i].Kind) {
+				continue
+			}
+			if score := nameSimilarity(previous.Name, newer[i].Name); score >= renameSimilarityThreshold {
+				candidates = append(candidates, candidate{newer: i, older: j, score: score})
+			}
+		}
+	}
+	sort.SliceStable(candidates, func(a, b int) bool { return candidates[a].score > candidates[b].score })
+	for _, c := range candidates {
+		previous := older[c.older]
+		if _, ok := renamedTo[c.newer]; ok || matchedOlder[previous.Name] {
+			continue
+		}
+		renamedTo[c.newer] = FieldRename{Older: previous.Name, Newer: newer[c.newer].Name, Guess: RenameGuessSimilarity}
+		matchedOlder[previous.Name] = true
+	}
+
+	for _, i := range unmatched {
+		if rename, ok := renamedTo[i]; ok {
+			diff.Renamed = append(diff.Renamed, rename)
+			continue
+		}
+		diff.Added = append(diff.Added, DiffField{Name: newer[i].Name, Default: newer[i].zeroDefault()})
+	}
+	for _, field := range older {
+		if !matchedOlder[field.Name] {
+			diff.Removed = append(diff.Removed, DiffField{Name: field.Name, Default: field.zeroDefault()})
+		}
+	}
+	return diff
+}
+
+// fieldShapes describes the JSON fields of a struct type in declaration order
+func fieldShapes(t reflect.Type) []FieldShape {
+	fields := orderedJSONFields(t)
+	shapes := make([]FieldShape, len(fields))
+	for i, field := range fields {
+		shapes[i] = FieldShape{
+			Name:     field.name,
+			GoName:   field.goName,
+			Kind:     jsonKind(field.fieldType),
+			Nullable: field.fieldType.Kind() == reflect.Ptr,
+		}
+	}
+	return shapes
+}
+
+// diffStructType returns the struct type of a DiffTypes argument
+func diffStructType(v interface{}) (reflect.Type, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("DiffTypes compares structs, got %T", v)
+	}
+	return t, nil
+}
+
+// IsEmpty reports whether the types have the same fields
+func (d *TypeDiff) IsEmpty() bool {
+	return len(d.Renamed) == 0 && len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Retyped) == 0
+}
+
+// VersionChange builds the suggested change between two versions: requests rename fields to
+// their newer names, drop removed fields and default added ones; responses do the reverse.
+// Retyped fields aren't migrated. Only diffs made by DiffTypes know the type to build it for.
+func (d *TypeDiff) VersionChange(from, to *Version) *VersionChange {
+	if d.newer == nil {
+		panic("epoch: TypeDiff.VersionChange needs a diff made by DiffTypes")
+	}
+	builder := NewVersionChangeBuilder(from, to).Description("Update " + d.TypeName)
+	forType := builder.ForType(reflect.New(d.newer).Elem().Interface())
+
+	request := forType.RequestToNextVersion()
+	for _, rename := range d.Renamed {
+		request.RenameField(rename.Older, rename.Newer)
+	}
+	for _, field := range d.Added {
+		request.AddField(field.Name, field.Default)
+	}
+	for _, field := range d.Removed {
+		request.RemoveField(field.Name)
+	}
+
+	response := forType.ResponseToPreviousVersion()
+	for _, rename := range d.Renamed {
+		response.RenameField(rename.Newer, rename.Older)
+	}
+	for _, field := range d.Added {
+		response.RemoveField(field.Name)
+	}
+	for _, field := range d.Removed {
+		response.AddField(field.Name, field.Default)
+	}
+	return builder.Build()
+}
+
+// Code renders the suggested change as VersionChangeBuilder code to paste and review, with from
+// and to as the Go expressions of its versions (see ChangeCode)
+func (d *TypeDiff) Code(from, to string) string {
+	return ChangeCode(from, to, d)
+}
+
+// ChangeCode renders one VersionChangeBuilder with a ForType block per diff, with from and to as
+// the Go expressions of its versions. Guessed renames and retyped fields are marked with comments.
+func ChangeCode(from, to string, diffs ...*TypeDiff) string {
+	names := make([]string, len(diffs))
+	for i, d := range diffs {
+		names[i] = d.TypeName
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "epoch.NewVersionChangeBuilder(%s, %s).\n", from, to)
+	fmt.Fprintf(&b, "\tDescription(%s).\n", strconv.Quote("Update "+strings.Join(names, ", ")))
+	for _, d := range diffs {
+		d.writeForType(&b)
+	}
+	b.WriteString("\tBuild()\n")
+	return b.String()
+}
+
+// writeForType renders the diff's ForType block
+func (d *TypeDiff) writeForType(b *strings.Builder) {
+	fmt.Fprintf(b, "\tForType(%s{}).\n", d.TypeName)
+
+	line := func(format string, args ...interface{}) {
+		fmt.Fprintf(b, "\t\t\t"+format+"\n", args...)
+	}
+	retyped := func() {
+		for _, name := range d.Retyped {
+			line("// TODO: %s changed type; migrate it with Custom", strconv.Quote(name))
+		}
+	}
+
+	b.WriteString("\t\tRequestToNextVersion().\n")
+	retyped()
+	for _, rename := range d.Renamed {
+		line("RenameField(%s, %s).%s", strconv.Quote(rename.Older), strconv.Quote(rename.Newer), rename.comment())
+	}
+	for _, field := range d.Added {
+		line("AddField(%s, %s).", strconv.Quote(field.Name), goLiteral(field.Default))
+	}
+	for _, field := range d.Removed {
+		line("RemoveField(%s).", strconv.Quote(field.Name))
+	}
+
+	b.WriteString("\t\tResponseToPreviousVersion().\n")
+	retyped()
+	for _, rename := range d.Renamed {
+		line("RenameField(%s, %s).%s", strconv.Quote(rename.Newer), strconv.Quote(rename.Older), rename.comment())
+	}
+	for _, field := range d.Added {
+		line("RemoveField(%s).", strconv.Quote(field.Name))
+	}
+	for _, field := range d.Removed {
+		line("AddField(%s, %s).", strconv.Quote(field.Name), goLiteral(field.Default))
+	}
+}
+
+// comment marks renames guessed from similar names, which are the likeliest to be wrong
+func (r FieldRename) comment() string {
+	if r.Guess == RenameGuessSimilarity {
+		return " // guessed from similar names"
+	}
+	return ""
+}
+
+// goLiteral renders a DiffField default as Go source
+func goLiteral(value interface{}) string {
+	if value == nil {
+		return "nil"
+	}
+	return fmt.Sprintf("%#v", value)
+}
+
+// jsonKind names the kind of JSON value a type marshals to, or "" when it can't tell
+// (interfaces, and builtin types like time.Time that marshal themselves)
+func jsonKind(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "string" // []byte marshals to a string
+		}
+		return "array"
+	case reflect.Map:
+		return "object"
+	case reflect.Struct:
+		if isBuiltinType(t) {
+			return ""
+		}
+		return "object"
+	}
+	return ""
+}
+
+// sameJSONKind reports whether two fields hold the same kind of JSON value, as far as either can tell
+func sameJSONKind(a, b string) bool {
+	return a == "" || b == "" || a == b
+}
+
+// zeroDefault returns the AddField default for the field: the zero value of strings, numbers
+// and booleans, nil for others and nullable fields
+func (f FieldShape) zeroDefault() interface{} {
+	if f.Nullable {
+		return nil
+	}
+	switch f.Kind {
+	case "string":
+		return ""
+	case "number":
+		return 0
+	case "boolean":
+		return false
+	}
+	return nil
+}
+
+// nameSimilarity scores how alike two field names are from 0 to 1: the share of words they
+// have in common (full_name and name share half), or their edit similarity when it's high
+// enough to be a respelling (color and colour). Short names are too alike by edits alone.
+func nameSimilarity(a, b string) float64 {
+	wordsA, wordsB := nameWords(a), nameWords(b)
+	common := 0
+	for word := range wordsA {
+		if wordsB[word] {
+			common++
+		}
+	}
+	score := 0.0
+	if union := len(wordsA) + len(wordsB) - common; union > 0 {
+		score = float64(common) / float64(union)
+	}
+
+	a, b = foldFieldName(a), foldFieldName(b)
+	longest := max(len([]rune(a)), len([]rune(b)))
+	if longest > 0 {
+		if edit := 1 - float64(editDistance(a, b))/float64(longest); edit >= respellingThreshold && edit > score {
+			score = edit
+		}
+	}
+	return score
+}
+
+// nameWords splits a snake_case, kebab-case or camelCase name into its lowercase words
+func nameWords(name string) map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(camelToSnake(name), func(r rune) bool { return r == '_' || r == '-' }) {
+		words[word] = true
+	}
+	return words
+}
+
+// editDistance returns the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	runesA, runesB := []rune(a), []rune(b)
+	previous := make([]int, len(runesB)+1)
+	current := make([]int, len(runesB)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(runesA); i++ {
+		current[0] = i
+		for j := 1; j <= len(runesB); j++ {
+			cost := 1
+			if runesA[i-1] == runesB[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(runesB)]
+}
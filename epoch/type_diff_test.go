@@ -0,0 +1,112 @@
+package epoch
+
+import (
+	"context"
+	"reflect"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type diffUserV2 struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Email    string `json:"email_address"`
+	Nickname string `json:"nickname"`
+	Age      string `json:"age"`
+}
+
+type diffUserHEAD struct {
+	ID       int    `json:"id"`
+	FullName string `json:"full_name"`
+	Email    string `json:"email"`
+	Age      int    `json:"age"`
+	Verified bool   `json:"verified"`
+}
+
+var _ = Describe("DiffTypes", func() {
+	It("should guess renames and list added, removed and retyped fields", func() {
+		diff, err := DiffTypes(diffUserV2{}, &diffUserHEAD{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(diff.TypeName).To(Equal("diffUserHEAD"))
+		Expect(diff.Renamed).To(Equal([]FieldRename{
+			{Older: "name", Newer: "full_name", Guess: RenameGuessSimilarity},
+			{Older: "email_address", Newer: "email", Guess: RenameGuessTag},
+		}))
+		Expect(diff.Added).To(Equal([]DiffField{{Name: "verified", Default: false}}))
+		Expect(diff.Removed).To(Equal([]DiffField{{Name: "nickname", Default: ""}}))
+		Expect(diff.Retyped).To(Equal([]string{"age"}))
+		Expect(diff.IsEmpty()).To(BeFalse())
+	})
+
+	It("should not guess renames between unlike names or kinds of value", func() {
+		type older struct {
+			IP    string `json:"ip"`
+			Count int    `json:"count"`
+		}
+		type newer struct {
+			ID    string `json:"id"`
+			Total string `json:"count_total"`
+		}
+		diff, err := DiffTypes(older{}, newer{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(diff.Renamed).To(BeEmpty())
+		Expect(diff.Added).To(HaveLen(2))
+		Expect(diff.Removed).To(HaveLen(2))
+	})
+
+	It("should report identical types as empty", func() {
+		diff, err := DiffTypes(diffUserHEAD{}, diffUserHEAD{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(diff.IsEmpty()).To(BeTrue())
+	})
+
+	It("should build a change that migrates both ways", func() {
+		v1, _ := NewDateVersion("2024-01-01")
+		v2, _ := NewDateVersion("2025-01-01")
+		diff, err := DiffTypes(diffUserV2{}, diffUserHEAD{})
+		Expect(err).NotTo(HaveOccurred())
+
+		epochInstance, err := NewEpoch().WithVersions(v1, v2).WithHeadVersion().
+			WithChanges(diff.VersionChange(v1, v2)).Build()
+		Expect(err).NotTo(HaveOccurred())
+		userType := reflect.TypeOf(diffUserHEAD{})
+
+		migrated, err := epochInstance.Transform(context.Background(), userType, DirectionRequest, v1, epochInstance.GetHeadVersion(),
+			[]byte(`{"id":1,"name":"Ada","email_address":"ada@example.com","nickname":"ada","age":36}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(migrated)).To(MatchJSON(`{"id":1,"full_name":"Ada","email":"ada@example.com","age":36,"verified":false}`))
+
+		migrated, err = epochInstance.Transform(context.Background(), userType, DirectionResponse, epochInstance.GetHeadVersion(), v1,
+			[]byte(`{"id":1,"full_name":"Ada","email":"ada@example.com","age":36,"verified":true}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(migrated)).To(MatchJSON(`{"id":1,"name":"Ada","email_address":"ada@example.com","age":36,"nickname":""}`))
+	})
+
+	It("should render builder code to review", func() {
+		diff, err := DiffTypes(diffUserV2{}, diffUserHEAD{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(diff.Code("v1", "v2")).To(Equal(`epoch.NewVersionChangeBuilder(v1, v2).
+	Description("Update diffUserHEAD").
+	ForType(diffUserHEAD{}).
+		RequestToNextVersion().
+			// TODO: "age" changed type; migrate it with Custom
+			RenameField("name", "full_name"). // guessed from similar names
+			RenameField("email_address", "email").
+			AddField("verified", false).
+			RemoveField("nickname").
+		ResponseToPreviousVersion().
+			// TODO: "age" changed type; migrate it with Custom
+			RenameField("full_name", "name"). // guessed from similar names
+			RenameField("email", "email_address").
+			RemoveField("verified").
+			AddField("nickname", "").
+	Build()
+`))
+	})
+
+	It("should only compare structs", func() {
+		_, err := DiffTypes("user", diffUserHEAD{})
+		Expect(err).To(MatchError(ContainSubstring("DiffTypes compares structs, got string")))
+	})
+})
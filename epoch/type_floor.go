@@ -0,0 +1,81 @@
+package epoch
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TypeFloor is a type registered with the earliest version it exists in (see SinceVersion)
+type TypeFloor struct {
+	Example interface{}
+	Since   *Version
+}
+
+// SinceVersion declares that a type passed to WithTypes only exists from version on:
+//
+//	WithTypes(UserResponse{}, epoch.SinceVersion(Invoice{}, v3))
+//
+// Endpoints accepting or returning the type, directly or nested, answer clients of older
+// versions with 404 Not Found and are left out of their OpenAPI specs.
+func SinceVersion(example interface{}, version *Version) TypeFloor {
+	return TypeFloor{Example: example, Since: version}
+}
+
+// TypeSince returns the earliest version a type exists in, or nil if it exists in every version
+func (c *Epoch) TypeSince(example interface{}) *Version {
+	return c.typeFloors[typeOf(example)]
+}
+
+// registerTypeFloor records a SinceVersion declaration from WithTypes
+func (cb *EpochBuilder) registerTypeFloor(floor TypeFloor) reflect.Type {
+	t := typeOf(floor.Example)
+	if t == nil || floor.Since == nil {
+		cb.errors = append(cb.errors, errors.New("SinceVersion needs a type and a version"))
+		return nil
+	}
+	if cb.typeFloors == nil {
+		cb.typeFloors = make(map[reflect.Type]*Version)
+	}
+	cb.typeFloors[t] = floor.Since
+	return t
+}
+
+// checkTypeFloors validates SinceVersion declarations against the versions at Build()
+func (cb *EpochBuilder) checkTypeFloors(bundle *VersionBundle) error {
+	for t, since := range cb.typeFloors {
+		if !since.IsHead && !bundle.IsVersionDefined(since.String()) {
+			return fmt.Errorf("type %s is declared since %w '%s'", t, ErrUnknownVersion, since.String())
+		}
+	}
+	return nil
+}
+
+// endpointSince returns the newest floor among the types an endpoint accepts or returns,
+// directly or nested, or nil if none of them has one
+func (c *Epoch) endpointSince(def *EndpointDefinition) *Version {
+	if len(c.typeFloors) == 0 {
+		return nil
+	}
+	var since *Version
+	for _, root := range []reflect.Type{def.RequestType, def.ResponseType} {
+		for _, t := range CollectMigratableTypes(root) {
+			if floor := c.typeFloors[t]; floor != nil && (since == nil || floor.IsNewerThan(since)) {
+				since = floor
+			}
+		}
+	}
+	return since
+}
+
+// rejectUnavailable answers a request for an endpoint its version doesn't have yet
+func rejectUnavailable(c *gin.Context, endpointDef *EndpointDefinition, version *Version) {
+	c.JSON(http.StatusNotFound, withCorrelationID(c, gin.H{
+		"error":           fmt.Sprintf("%s %s is not available in version %s", endpointDef.Method, endpointDef.PathPattern, version.String()),
+		"available_since": endpointDef.Since.String(),
+	}))
+	c.Abort()
+}
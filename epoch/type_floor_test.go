@@ -0,0 +1,95 @@
+package epoch
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type flooredInvoice struct {
+	ID    int `json:"id"`
+	Total int `json:"total"`
+}
+
+type flooredAccount struct {
+	ID       int              `json:"id"`
+	Invoices []flooredInvoice `json:"invoices"`
+}
+
+type flooredUser struct {
+	ID int `json:"id"`
+}
+
+var _ = Describe("Type version floors", func() {
+	var (
+		v1, v2, v3    *Version
+		epochInstance *Epoch
+		router        *gin.Engine
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		v1, _ = NewDateVersion("2024-01-01")
+		v2, _ = NewDateVersion("2024-06-01")
+		v3, _ = NewDateVersion("2025-01-01")
+		var err error
+		epochInstance, err = NewEpoch().
+			WithVersions(v1, v2, v3).
+			WithHeadVersion().
+			WithTypes(flooredUser{}, SinceVersion(flooredInvoice{}, v2)).
+			Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		router = gin.New()
+		router.Use(epochInstance.Middleware())
+		router.GET("/accounts/:id", epochInstance.WrapHandler(func(c *gin.Context) {
+			c.JSON(http.StatusOK, flooredAccount{ID: 1})
+		}).Returns(flooredAccount{}).ToHandlerFunc("GET", "/accounts/:id"))
+		router.GET("/users/:id", epochInstance.WrapHandler(func(c *gin.Context) {
+			c.JSON(http.StatusOK, flooredUser{ID: 1})
+		}).Returns(flooredUser{}).ToHandlerFunc("GET", "/users/:id"))
+	})
+
+	get := func(path, version string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-API-Version", version)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	It("should reject versions older than a type the endpoint returns", func() {
+		recorder := get("/accounts/1", "2024-01-01")
+		Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		Expect(recorder.Body.String()).To(MatchJSON(`{
+			"error": "GET /accounts/:id is not available in version 2024-01-01",
+			"available_since": "2024-06-01"
+		}`))
+
+		Expect(get("/accounts/1", "2024-06-01").Code).To(Equal(http.StatusOK))
+		Expect(get("/accounts/1", "head").Code).To(Equal(http.StatusOK))
+		Expect(get("/users/1", "2024-01-01").Code).To(Equal(http.StatusOK))
+	})
+
+	It("should record the floor on the type and its endpoints", func() {
+		Expect(epochInstance.TypeSince(flooredInvoice{})).To(Equal(v2))
+		Expect(epochInstance.TypeSince(&flooredUser{})).To(BeNil())
+		Expect(epochInstance.Types()).To(ContainElement(typeOf(flooredInvoice{})))
+
+		def, err := epochInstance.EndpointRegistry().Lookup("GET", "/accounts/1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(def.Since).To(Equal(v2))
+	})
+
+	It("should fail Build for versions it doesn't know", func() {
+		unknown, _ := NewDateVersion("2030-01-01")
+		_, err := NewEpoch().WithVersions(v1).WithHeadVersion().WithTypes(SinceVersion(flooredInvoice{}, unknown)).Build()
+		Expect(err).To(MatchError(ErrUnknownVersion))
+
+		_, err = NewEpoch().WithVersions(v1).WithHeadVersion().WithTypes(SinceVersion(nil, v1)).Build()
+		Expect(err).To(MatchError(ContainSubstring("SinceVersion needs a type and a version")))
+	})
+})
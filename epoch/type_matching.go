@@ -0,0 +1,185 @@
+package epoch
+
+import (
+	"reflect"
+)
+
+// typeMatcher holds operations for every type a predicate selects
+type typeMatcher struct {
+	match       func(reflect.Type) bool
+	requestOps  RequestToNextVersionOperationList
+	responseOps ResponseToPreviousVersionOperationList
+}
+
+// ForTypesMatching starts building operations for every type the predicate selects, for
+// cross-cutting changes such as renaming audit fields on all response types. Types are
+// matched as Epoch meets them: registered endpoint types, their nested types and WithTypes.
+func (b *versionChangeBuilder) ForTypesMatching(predicate func(reflect.Type) bool) *typeBuilder {
+	tb := &typeBuilder{
+		parent:                       b,
+		match:                        predicate,
+		requestToNextVersionOps:      make(RequestToNextVersionOperationList, 0),
+		responseToPreviousVersionOps: make(ResponseToPreviousVersionOperationList, 0),
+	}
+	b.typeMatchers = append(b.typeMatchers, tb)
+	return tb
+}
+
+// ForTypesImplementing starts building operations for every type implementing the interface,
+// e.g. reflect.TypeOf((*Auditable)(nil)).Elem(). Types whose pointer implements it match too.
+func (b *versionChangeBuilder) ForTypesImplementing(iface reflect.Type) *typeBuilder {
+	if iface == nil || iface.Kind() != reflect.Interface {
+		panic("epoch: ForTypesImplementing requires an interface type, e.g. reflect.TypeOf((*Auditable)(nil)).Elem()")
+	}
+	return b.ForTypesMatching(func(t reflect.Type) bool {
+		return t.Implements(iface) || reflect.PointerTo(t).Implements(iface)
+	})
+}
+
+// ForAllTypes starts building operations for every type except those listed, for API-wide
+// convention changes such as renaming fields from snake_case to camelCase in one version
+func (b *versionChangeBuilder) ForAllTypes(except ...interface{}) *typeBuilder {
+	excluded := make(map[reflect.Type]bool, len(except))
+	for _, t := range except {
+		excluded[typeOf(t)] = true
+	}
+	return b.ForTypesMatching(func(t reflect.Type) bool {
+		return !excluded[t]
+	})
+}
+
+// typeOf returns the struct type of an example value, dereferencing pointers
+func typeOf(example interface{}) reflect.Type {
+	t := reflect.TypeOf(example)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// ForAllTypes returns to the parent and starts a new builder for all types
+func (tb *typeBuilder) ForAllTypes(except ...interface{}) *typeBuilder {
+	return tb.parent.ForAllTypes(except...)
+}
+
+// ForTypesMatching returns to the parent and starts a new predicate-based type builder
+func (tb *typeBuilder) ForTypesMatching(predicate func(reflect.Type) bool) *typeBuilder {
+	return tb.parent.ForTypesMatching(predicate)
+}
+
+// ForTypesImplementing returns to the parent and starts a new interface-based type builder
+func (tb *typeBuilder) ForTypesImplementing(iface reflect.Type) *typeBuilder {
+	return tb.parent.ForTypesImplementing(iface)
+}
+
+// BindTypes applies the change's ForTypesMatching operations to the given types. The chain
+// binds types as it migrates them; call it before listing ChangelogEntries for known types.
+func (vc *VersionChange) BindTypes(types ...reflect.Type) {
+	for _, t := range types {
+		vc.bindType(t)
+	}
+}
+
+// bindType compiles the operations of every matcher selecting t into t's instructions, once
+func (vc *VersionChange) bindType(t reflect.Type) {
+	if len(vc.typeMatchers) == 0 || t == nil {
+		return
+	}
+	vc.typesMu.RLock()
+	bound := vc.boundTypes[t]
+	vc.typesMu.RUnlock()
+	if bound {
+		return
+	}
+
+	vc.typesMu.Lock()
+	defer vc.typesMu.Unlock()
+	if vc.boundTypes[t] {
+		return
+	}
+	vc.boundTypes[t] = true
+
+	for _, matcher := range vc.typeMatchers {
+		if !matcher.match(t) {
+			continue
+		}
+		for _, instruction := range compileTypeInstructions(t, matcher.requestOps, matcher.responseOps) {
+			switch inst := instruction.(type) {
+			case *AlterRequestInstruction:
+				vc.alterRequestBySchemaInstructions[t] = append(vc.alterRequestBySchemaInstructions[t], inst)
+			case *AlterResponseInstruction:
+				vc.alterResponseBySchemaInstructions[t] = append(vc.alterResponseBySchemaInstructions[t], inst)
+			}
+		}
+		// Matched operations run after those declared with ForType
+		if len(matcher.requestOps) > 0 {
+			ops := append(RequestToNextVersionOperationList{}, vc.requestOperationsByType[t]...)
+			vc.requestOperationsByType[t] = append(ops, matcher.requestOps...)
+		}
+		if len(matcher.responseOps) > 0 {
+			ops := append(ResponseToPreviousVersionOperationList{}, vc.responseOperationsByType[t]...)
+			vc.responseOperationsByType[t] = append(ops, matcher.responseOps...)
+		}
+	}
+}
+
+// requestInstructionsFor returns the type-specific request instructions for t
+func (vc *VersionChange) requestInstructionsFor(t reflect.Type) []*AlterRequestInstruction {
+	vc.bindType(t)
+	vc.typesMu.RLock()
+	defer vc.typesMu.RUnlock()
+	return vc.alterRequestBySchemaInstructions[t]
+}
+
+// responseInstructionsFor returns the type-specific response instructions for t
+func (vc *VersionChange) responseInstructionsFor(t reflect.Type) []*AlterResponseInstruction {
+	vc.bindType(t)
+	vc.typesMu.RLock()
+	defer vc.typesMu.RUnlock()
+	return vc.alterResponseBySchemaInstructions[t]
+}
+
+// ForAllTypes allows chaining to a builder for all types
+func (b *requestToNextVersionBuilder) ForAllTypes(except ...interface{}) *typeBuilder {
+	return b.parent.ForAllTypes(except...)
+}
+
+// ForTypesMatching allows chaining to a predicate-based type builder
+func (b *requestToNextVersionBuilder) ForTypesMatching(predicate func(reflect.Type) bool) *typeBuilder {
+	return b.parent.ForTypesMatching(predicate)
+}
+
+// ForTypesImplementing allows chaining to an interface-based type builder
+func (b *requestToNextVersionBuilder) ForTypesImplementing(iface reflect.Type) *typeBuilder {
+	return b.parent.ForTypesImplementing(iface)
+}
+
+// ForAllTypes allows chaining to a builder for all types
+func (b *responseToPreviousVersionBuilder) ForAllTypes(except ...interface{}) *typeBuilder {
+	return b.parent.ForAllTypes(except...)
+}
+
+// ForTypesMatching allows chaining to a predicate-based type builder
+func (b *responseToPreviousVersionBuilder) ForTypesMatching(predicate func(reflect.Type) bool) *typeBuilder {
+	return b.parent.ForTypesMatching(predicate)
+}
+
+// ForTypesImplementing allows chaining to an interface-based type builder
+func (b *responseToPreviousVersionBuilder) ForTypesImplementing(iface reflect.Type) *typeBuilder {
+	return b.parent.ForTypesImplementing(iface)
+}
+
+// ForAllTypes allows chaining from endpoint operations to a builder for all types
+func (eb *endpointBuilder) ForAllTypes(except ...interface{}) *typeBuilder {
+	return eb.parent.ForAllTypes(except...)
+}
+
+// ForTypesMatching allows chaining from endpoint operations to a predicate-based type builder
+func (eb *endpointBuilder) ForTypesMatching(predicate func(reflect.Type) bool) *typeBuilder {
+	return eb.parent.ForTypesMatching(predicate)
+}
+
+// ForTypesImplementing allows chaining from endpoint operations to an interface-based type builder
+func (eb *endpointBuilder) ForTypesImplementing(iface reflect.Type) *typeBuilder {
+	return eb.parent.ForTypesImplementing(iface)
+}
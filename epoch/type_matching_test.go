@@ -0,0 +1,177 @@
+package epoch
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// auditable is implemented by types carrying audit timestamps
+type auditable interface {
+	auditTimestamp() string
+}
+
+type auditedProject struct {
+	ID        int    `json:"id"`
+	CreatedAt string `json:"created_at"`
+}
+
+func (p auditedProject) auditTimestamp() string { return p.CreatedAt }
+
+type auditedMember struct {
+	Name      string `json:"name"`
+	CreatedAt string `json:"created_at"`
+}
+
+func (m *auditedMember) auditTimestamp() string { return m.CreatedAt }
+
+type auditedTeam struct {
+	ID        int             `json:"id"`
+	CreatedAt string          `json:"created_at"`
+	Members   []auditedMember `json:"members"`
+}
+
+func (t auditedTeam) auditTimestamp() string { return t.CreatedAt }
+
+type unauditedSettings struct {
+	CreatedAt string `json:"created_at"`
+}
+
+var auditableType = reflect.TypeOf((*auditable)(nil)).Elem()
+
+var _ = Describe("Changes for types matching a predicate", func() {
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+	})
+
+	migrate := func(change *VersionChange, v1, v2 *Version, t interface{}, raw string) string {
+		chain, err := NewMigrationChain([]*VersionChange{change})
+		Expect(err).NotTo(HaveOccurred())
+
+		body, err := getNode([]byte(raw))
+		Expect(err).NotTo(HaveOccurred())
+		info := &ResponseInfo{Body: &body, StatusCode: 200}
+		Expect(chain.MigrateResponseForType(context.Background(), info, reflect.TypeOf(t), nil, v2, v1)).To(Succeed())
+
+		out, err := info.Body.Raw()
+		Expect(err).NotTo(HaveOccurred())
+		return out
+	}
+
+	It("should apply operations to every type implementing an interface", func() {
+		v1, _ := NewDateVersion("2024-01-01")
+		v2, _ := NewDateVersion("2024-06-01")
+		change := NewVersionChangeBuilder(v1, v2).
+			ForTypesImplementing(auditableType).
+			ResponseToPreviousVersion().
+			RenameField("created_at", "created_ts").
+			Build()
+
+		Expect(migrate(change, v1, v2, auditedProject{}, `{"id":1,"created_at":"2024"}`)).
+			To(Equal(`{"id":1,"created_ts":"2024"}`))
+		// Pointer receivers count as implementing the interface
+		Expect(migrate(change, v1, v2, auditedMember{}, `{"name":"Ada","created_at":"2024"}`)).
+			To(Equal(`{"name":"Ada","created_ts":"2024"}`))
+		Expect(migrate(change, v1, v2, unauditedSettings{}, `{"created_at":"2024"}`)).
+			To(Equal(`{"created_at":"2024"}`))
+	})
+
+	It("should apply operations to types selected by a predicate", func() {
+		v1, _ := NewDateVersion("2024-01-01")
+		v2, _ := NewDateVersion("2024-06-01")
+		change := NewVersionChangeBuilder(v1, v2).
+			ForTypesMatching(func(t reflect.Type) bool { return strings.HasPrefix(t.Name(), "unaudited") }).
+			ResponseToPreviousVersion().
+			RemoveField("created_at").
+			Build()
+
+		Expect(migrate(change, v1, v2, unauditedSettings{}, `{"created_at":"2024"}`)).To(Equal(`{}`))
+		Expect(migrate(change, v1, v2, auditedProject{}, `{"id":1,"created_at":"2024"}`)).
+			To(Equal(`{"id":1,"created_at":"2024"}`))
+	})
+
+	It("should run matched operations after those declared for the type", func() {
+		v1, _ := NewDateVersion("2024-01-01")
+		v2, _ := NewDateVersion("2024-06-01")
+		change := NewVersionChangeBuilder(v1, v2).
+			ForType(auditedProject{}).
+			ResponseToPreviousVersion().
+			RenameField("id", "project_id").
+			ForTypesImplementing(auditableType).
+			ResponseToPreviousVersion().
+			RenameField("created_at", "created_ts").
+			Build()
+
+		Expect(migrate(change, v1, v2, auditedProject{}, `{"id":1,"created_at":"2024"}`)).
+			To(Equal(`{"project_id":1,"created_ts":"2024"}`))
+
+		ops, ok := change.GetResponseOperationsByType(reflect.TypeOf(auditedProject{}))
+		Expect(ok).To(BeTrue())
+		Expect(ops).To(HaveLen(2))
+	})
+
+	It("should migrate endpoint types and their nested types through the middleware", func() {
+		v1, _ := NewDateVersion("2024-01-01")
+		v2, _ := NewDateVersion("2024-06-01")
+		change := NewVersionChangeBuilder(v1, v2).
+			ForTypesImplementing(auditableType).
+			RequestToNextVersion().
+			RenameField("created_ts", "created_at").
+			ResponseToPreviousVersion().
+			RenameField("created_at", "created_ts").
+			Build()
+		epochInstance, err := NewEpoch().WithVersions(v1, v2).WithHeadVersion().WithChanges(change).Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		var received auditedTeam
+		router := gin.New()
+		router.Use(epochInstance.Middleware())
+		router.POST("/teams", epochInstance.WrapHandler(func(c *gin.Context) {
+			Expect(c.ShouldBindJSON(&received)).To(Succeed())
+			c.JSON(200, received)
+		}).Accepts(auditedTeam{}).Returns(auditedTeam{}).ToHandlerFunc("POST", "/teams"))
+
+		req := httptest.NewRequest("POST", "/teams", strings.NewReader(
+			`{"id":1,"created_ts":"2024","members":[{"name":"Ada","created_ts":"2023"}]}`))
+		req.Header.Set("X-API-Version", "2024-01-01")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		Expect(recorder.Code).To(Equal(200))
+		Expect(received.CreatedAt).To(Equal("2024"))
+		Expect(received.Members[0].CreatedAt).To(Equal("2023"))
+		Expect(recorder.Body.String()).To(MatchJSON(
+			`{"id":1,"created_ts":"2024","members":[{"name":"Ada","created_ts":"2023"}]}`))
+	})
+
+	It("should list matched operations in the changelog once types are bound", func() {
+		v1, _ := NewDateVersion("2024-01-01")
+		v2, _ := NewDateVersion("2024-06-01")
+		change := NewVersionChangeBuilder(v1, v2).
+			ForTypesImplementing(auditableType).
+			ResponseToPreviousVersion().
+			RenameField("created_at", "created_ts").
+			Build()
+
+		Expect(change.ChangelogEntries()).To(BeEmpty())
+
+		change.BindTypes(reflect.TypeOf(auditedProject{}), reflect.TypeOf(unauditedSettings{}))
+		entries := change.ChangelogEntries()
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Type).To(Equal(reflect.TypeOf(auditedProject{})))
+		Expect(entries[0].Doc.Name).To(Equal("rename_field"))
+	})
+
+	It("should reject non-interface types", func() {
+		v1, _ := NewDateVersion("2024-01-01")
+		v2, _ := NewDateVersion("2024-06-01")
+		Expect(func() {
+			NewVersionChangeBuilder(v1, v2).ForTypesImplementing(reflect.TypeOf(auditedProject{}))
+		}).To(Panic())
+	})
+})
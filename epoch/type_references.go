@@ -0,0 +1,101 @@
+package epoch
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// UnusedChangeTarget is a type a change targets with ForType that nothing else references
+type UnusedChangeTarget struct {
+	Change string       // Description of the change
+	Type   reflect.Type // The targeted type
+}
+
+// TypeReferenceReport lists registered types and change targets no endpoint references,
+// which usually means a typo such as targeting UserProfile{} when the endpoint returns
+// UserProfileResponse{}
+type TypeReferenceReport struct {
+	UnreferencedTypes []reflect.Type       // WithTypes types (and their nested types) no endpoint references
+	UnusedTargets     []UnusedChangeTarget // ForType targets no endpoint accepts or returns
+}
+
+// Empty reports whether every type is referenced
+func (r *TypeReferenceReport) Empty() bool {
+	return len(r.UnreferencedTypes) == 0 && len(r.UnusedTargets) == 0
+}
+
+// Err returns an error listing every unreferenced type and change target, or nil if there are none
+func (r *TypeReferenceReport) Err() error {
+	if r.Empty() {
+		return nil
+	}
+	var problems []string
+	for _, t := range r.UnreferencedTypes {
+		problems = append(problems, fmt.Sprintf("type %s is registered but no endpoint references it", t))
+	}
+	for _, target := range r.UnusedTargets {
+		problems = append(problems, fmt.Sprintf("change '%s' targets %s, which is never accepted or returned", target.Change, target.Type))
+	}
+	return errors.New("unreferenced types:\n  " + strings.Join(problems, "\n  "))
+}
+
+// CheckTypeReferences reports registered types and change targets that no registered endpoint
+// accepts or returns, directly or nested. Call it once routes are registered, e.g. in a test.
+func (c *Epoch) CheckTypeReferences() *TypeReferenceReport {
+	referenced := make(map[reflect.Type]bool)
+	for _, def := range c.endpointRegistry.GetAll() {
+		for _, t := range []reflect.Type{def.RequestType, def.ResponseType} {
+			for _, nested := range CollectMigratableTypes(t) {
+				referenced[nested] = true
+			}
+		}
+	}
+
+	report := &TypeReferenceReport{}
+	for _, t := range c.types {
+		if !referenced[t] {
+			report.UnreferencedTypes = append(report.UnreferencedTypes, t)
+		}
+	}
+	report.UnusedTargets = unusedChangeTargets(c.migrationChain.GetChanges(), referenced)
+	return report
+}
+
+// unusedChangeTargets returns the ForType targets of changes that aren't known types
+func unusedChangeTargets(changes []*VersionChange, known map[reflect.Type]bool) []UnusedChangeTarget {
+	var unused []UnusedChangeTarget
+	for _, change := range changes {
+		for _, t := range change.DeclaredTypes() {
+			if !known[t] {
+				unused = append(unused, UnusedChangeTarget{Change: change.Description(), Type: t})
+			}
+		}
+	}
+	return unused
+}
+
+// WithTypeReferenceCheck makes Build() fail when a change targets a type with ForType that
+// isn't among the WithTypes types or their nested types. Endpoints are registered after
+// Build(), so use Epoch.CheckTypeReferences() to check against them.
+func (cb *EpochBuilder) WithTypeReferenceCheck() *EpochBuilder {
+	cb.checkTypeReferences = true
+	return cb
+}
+
+// checkChangeTargets validates change targets against the registered types at Build()
+func (cb *EpochBuilder) checkChangeTargets(types []reflect.Type) error {
+	if !cb.checkTypeReferences {
+		return nil
+	}
+	if len(types) == 0 {
+		return errors.New("type reference check requires types registered with WithTypes")
+	}
+	known := make(map[reflect.Type]bool, len(types))
+	for _, t := range types {
+		known[t] = true
+	}
+	report := &TypeReferenceReport{UnusedTargets: unusedChangeTargets(cb.changes, known)}
+	return report.Err()
+}
@@ -0,0 +1,113 @@
+package epoch
+
+import (
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type referencedProfileResponse struct {
+	ID     int                  `json:"id"`
+	Skills []referencedSkillDTO `json:"skills"`
+}
+
+type referencedSkillDTO struct {
+	Name string `json:"name"`
+}
+
+// referencedProfile is the typo'd target: endpoints return referencedProfileResponse
+type referencedProfile struct {
+	ID int `json:"id"`
+}
+
+var _ = Describe("Type references", func() {
+	var v1, v2 *Version
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		v1, _ = NewDateVersion("2024-01-01")
+		v2, _ = NewDateVersion("2024-06-01")
+	})
+
+	changeFor := func(description string, t interface{}) *VersionChange {
+		return NewVersionChangeBuilder(v1, v2).
+			Description(description).
+			ForType(t).
+			ResponseToPreviousVersion().
+			RemoveField("id").
+			Build()
+	}
+
+	It("should report change targets and registered types no endpoint references", func() {
+		epochInstance, err := NewEpoch().WithVersions(v1, v2).WithHeadVersion().
+			WithTypes(referencedProfileResponse{}, referencedProfile{}).
+			WithChanges(changeFor("Nested skill", referencedSkillDTO{}), changeFor("Typo", referencedProfile{})).
+			Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		router := gin.New()
+		router.GET("/profile", epochInstance.WrapHandler(func(c *gin.Context) {}).
+			Returns(referencedProfileResponse{}).ToHandlerFunc("GET", "/profile"))
+
+		report := epochInstance.CheckTypeReferences()
+		Expect(report.UnreferencedTypes).To(Equal([]reflect.Type{reflect.TypeOf(referencedProfile{})}))
+		Expect(report.UnusedTargets).To(Equal([]UnusedChangeTarget{
+			{Change: "Typo", Type: reflect.TypeOf(referencedProfile{})},
+		}))
+		Expect(report.Err()).To(MatchError(ContainSubstring("change 'Typo' targets epoch.referencedProfile, which is never accepted or returned")))
+	})
+
+	It("should report nothing when every type is referenced", func() {
+		epochInstance, err := NewEpoch().WithVersions(v1, v2).WithHeadVersion().
+			WithChanges(changeFor("Nested skill", referencedSkillDTO{})).
+			Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		router := gin.New()
+		router.GET("/profiles", epochInstance.WrapHandler(func(c *gin.Context) {}).
+			Returns([]referencedProfileResponse{}).ToHandlerFunc("GET", "/profiles"))
+
+		report := epochInstance.CheckTypeReferences()
+		Expect(report.Empty()).To(BeTrue())
+		Expect(report.Err()).NotTo(HaveOccurred())
+	})
+
+	It("should fail Build for change targets outside the registered types when checking", func() {
+		_, err := NewEpoch().WithVersions(v1, v2).WithHeadVersion().
+			WithTypes(referencedProfileResponse{}).
+			WithChanges(changeFor("Typo", referencedProfile{})).
+			WithTypeReferenceCheck().
+			Build()
+		Expect(err).To(MatchError(ContainSubstring("type reference check failed")))
+		Expect(err).To(MatchError(ContainSubstring("change 'Typo' targets epoch.referencedProfile")))
+		Expect(v1.Changes).To(BeEmpty())
+
+		_, err = NewEpoch().WithVersions(v1, v2).WithHeadVersion().
+			WithTypes(referencedProfileResponse{}).
+			WithChanges(changeFor("Nested skill", referencedSkillDTO{})).
+			WithTypeReferenceCheck().
+			Build()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should require registered types to check at Build", func() {
+		_, err := NewEpoch().WithVersions(v1, v2).WithHeadVersion().
+			WithChanges(changeFor("Typo", referencedProfile{})).
+			WithTypeReferenceCheck().
+			Build()
+		Expect(err).To(MatchError(ContainSubstring("requires types registered with WithTypes")))
+	})
+
+	It("should list declared types in order", func() {
+		change := NewVersionChangeBuilder(v1, v2).
+			ForType(referencedProfile{}, referencedSkillDTO{}).
+			ResponseToPreviousVersion().
+			RemoveField("id").
+			Build()
+		Expect(change.DeclaredTypes()).To(Equal([]reflect.Type{
+			reflect.TypeOf(referencedProfile{}), reflect.TypeOf(referencedSkillDTO{}),
+		}))
+	})
+})
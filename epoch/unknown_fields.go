@@ -0,0 +1,154 @@
+package epoch
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/astronomer/epoch/epoch/jsonast"
+)
+
+// WithUnknownFieldStripping removes fields the registered response type doesn't declare from
+// responses migrated to the given versions. By default unknown fields pass through untouched.
+func (vah *VersionAwareHandler) WithUnknownFieldStripping(versions ...*Version) *VersionAwareHandler {
+	if vah.strippedVersions == nil {
+		vah.strippedVersions = make(map[string]bool)
+	}
+	for _, version := range versions {
+		vah.strippedVersions[version.String()] = true
+	}
+	return vah
+}
+
+// stripsFields reports whether responses migrated to version lose unknown or unlisted fields
+func (vah *VersionAwareHandler) stripsFields(version *Version) bool {
+	return vah.strippedVersions[version.String()] || vah.fieldAllowlists[version.String()] != nil
+}
+
+// stripUnknownResponseFields strips a successful migrated response when its version is strict,
+// or down to the allowlisted fields of types with an allowlist for its version
+func (vah *VersionAwareHandler) stripUnknownResponseFields(resp *ResponseInfo, responseType reflect.Type, version *Version) error {
+	if !vah.stripsFields(version) || responseType == nil || resp.Body == nil || resp.StatusCode >= 400 {
+		return nil
+	}
+	strict := vah.strippedVersions[version.String()]
+	allowlists := vah.fieldAllowlists[version.String()]
+	headVersion := vah.versionBundle.GetHeadVersion()
+	known := func(t reflect.Type) (map[string]reflect.Type, bool) {
+		fields := vah.migrationChain.responseFieldsAt(t, headVersion, version)
+		if allowed, ok := allowlists[t]; ok {
+			return allowlistedFields(fields, allowed), true
+		}
+		return fields, strict
+	}
+	if err := stripUnknownFields(resp.Body, responseType, known); err != nil {
+		return fmt.Errorf("failed to strip unknown fields: %w", err)
+	}
+	return nil
+}
+
+// stripUnknownFields removes the fields of objects in node that known doesn't report for their
+// type, recursively. known maps field names to their types; a nil type isn't descended into.
+// Types known doesn't mark strict keep their unknown fields.
+func stripUnknownFields(node *jsonast.Node, t reflect.Type, known func(reflect.Type) (map[string]reflect.Type, bool)) error {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch node.TypeSafe() {
+	case jsonast.V_ARRAY:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return nil
+		}
+		if err := node.LoadAll(); err != nil {
+			return fmt.Errorf("failed to load array: %w", err)
+		}
+		length, err := node.Len()
+		if err != nil {
+			return fmt.Errorf("failed to get array length: %w", err)
+		}
+		for i := 0; i < length; i++ {
+			if err := stripUnknownFields(node.Index(i), t.Elem(), known); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case jsonast.V_OBJECT:
+		if t.Kind() != reflect.Struct {
+			return nil
+		}
+		if err := node.LoadAll(); err != nil {
+			return fmt.Errorf("failed to load object: %w", err)
+		}
+		fields, strict := known(t)
+
+		iter, err := node.Properties()
+		if err != nil {
+			return err
+		}
+		var pairs []jsonast.Pair
+		// Pairs are copies, so the object is rebuilt when a field went or a value may have changed
+		rebuild := false
+		var pair jsonast.Pair
+		for iter.Next(&pair) {
+			fieldType, ok := fields[pair.Key]
+			if !ok && strict {
+				rebuild = true
+				continue
+			}
+			if fieldType != nil {
+				if err := stripUnknownFields(&pair.Value, fieldType, known); err != nil {
+					return err
+				}
+				rebuild = true
+			}
+			pairs = append(pairs, pair)
+		}
+		if rebuild {
+			*node = jsonast.NewObject(pairs)
+		}
+	}
+	return nil
+}
+
+// responseFieldsAt returns the fields t's responses have once migrated from one version to
+// another: its declared fields under their renamed names, and fields response operations add
+func (mc *MigrationChain) responseFieldsAt(t reflect.Type, from, to *Version) map[string]reflect.Type {
+	renamed := mc.renamedResponseFields(t, from, to)
+	fields := make(map[string]reflect.Type)
+	for _, field := range orderedJSONFields(t) {
+		name := field.name
+		if older, ok := renamed[name]; ok {
+			name = older
+		}
+		fields[name] = field.fieldType
+	}
+
+	path := mc.GetMigrationPath(from, to)
+	sort.SliceStable(path, func(i, j int) bool {
+		return path[i].FromVersion().IsNewerThan(path[j].FromVersion())
+	})
+	for _, change := range path {
+		ops, _ := change.GetResponseOperationsByType(t)
+		for _, op := range ops {
+			for name := range DescribeOperation(op).AddedFields {
+				addKnownField(fields, name)
+			}
+			switch operation := op.(type) {
+			case *ResponseMoveField:
+				addKnownField(fields, SplitPath(operation.OlderVersionPath)[0])
+			case *ResponseExpandReference:
+				addKnownField(fields, operation.Name)
+			}
+		}
+	}
+	return fields
+}
+
+// addKnownField adds a field of unknown type, keeping the type of a declared field
+func addKnownField(fields map[string]reflect.Type, name string) {
+	if _, exists := fields[name]; !exists {
+		fields[name] = nil
+	}
+}
@@ -0,0 +1,75 @@
+package epoch
+
+import (
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type roundTripAddress struct {
+	City string `json:"city"`
+}
+
+type roundTripUser struct {
+	ID       int                `json:"id"`
+	FullName string             `json:"full_name"`
+	Address  roundTripAddress   `json:"address"`
+	Previous []roundTripAddress `json:"previous"`
+}
+
+var _ = Describe("Unknown fields", func() {
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+	})
+
+	const handlerBody = `{"zeta":true,"id":1,"full_name":"Ada","internal":{"a":1},` +
+		`"address":{"city":"Oslo","geo":"x"},"previous":[{"city":"Rome","geo":"y"}]}`
+
+	serve := func(version string, strict ...string) string {
+		v1, v2, v3 := newTestVersions()
+		changes := []*VersionChange{
+			NewVersionChangeBuilder(v1, v2).
+				ForType(roundTripUser{}).
+				ResponseToPreviousVersion().
+				RenameField("full_name", "name").
+				AddField("status", "active").
+				Build(),
+		}
+		versions := map[string]*Version{"2024-01-01": v1, "2024-06-01": v2, "2025-01-01": v3}
+		var stripped []*Version
+		for _, s := range strict {
+			stripped = append(stripped, versions[s])
+		}
+		epochInstance, err := setupHeadEpoch([]*Version{v1, v2, v3}, changes, func(builder *EpochBuilder) *EpochBuilder {
+			return builder.WithUnknownFieldStripping(stripped...)
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		router := setupRouterWithMiddleware(epochInstance)
+		router.GET("/users/:id", epochInstance.WrapHandler(func(c *gin.Context) {
+			c.Data(200, "application/json", []byte(handlerBody))
+		}).Returns(roundTripUser{}).ToHandlerFunc("GET", "/users/:id"))
+
+		recorder := serveVersioned(router, httptest.NewRequest("GET", "/users/1", nil), version)
+		Expect(recorder.Code).To(Equal(200))
+		return recorder.Body.String()
+	}
+
+	It("should keep unknown fields and key order, renaming fields in place", func() {
+		Expect(serve("2024-01-01")).To(Equal(`{"zeta":true,"id":1,"name":"Ada","internal":{"a":1},` +
+			`"address":{"city":"Oslo","geo":"x"},"previous":[{"city":"Rome","geo":"y"}],"status":"active"}`))
+	})
+
+	It("should strip fields the type doesn't declare for strict versions", func() {
+		Expect(serve("2024-01-01", "2024-01-01")).To(Equal(`{"id":1,"name":"Ada",` +
+			`"address":{"city":"Oslo"},"previous":[{"city":"Rome"}],"status":"active"}`))
+	})
+
+	It("should strip strict versions that need no migration", func() {
+		Expect(serve("2024-06-01", "2024-06-01")).To(Equal(`{"id":1,"full_name":"Ada",` +
+			`"address":{"city":"Oslo"},"previous":[{"city":"Rome"}]}`))
+		Expect(serve("2024-06-01", "2024-01-01")).To(Equal(handlerBody))
+	})
+})
@@ -0,0 +1,108 @@
+package epoch
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unreleasedVersion is a version only resolvable by requests carrying previewHeader
+type unreleasedVersion struct {
+	version       *Version
+	previewHeader string
+}
+
+// WithUnreleasedVersion soft-launches a version registered with WithVersions: requests only
+// resolve to it (or anything newer, including head) when they carry a non-empty previewHeader.
+// Other requests are served the newest released version instead, exactly as if the unreleased
+// version didn't exist. Date versions release themselves once the clock reaches their date.
+func (cb *EpochBuilder) WithUnreleasedVersion(version *Version, previewHeader string) *EpochBuilder {
+	cb.unreleased = append(cb.unreleased, unreleasedVersion{version: version, previewHeader: previewHeader})
+	return cb
+}
+
+// checkUnreleased validates the WithUnreleasedVersion versions against the registered versions
+func (cb *EpochBuilder) checkUnreleased() error {
+	for _, u := range cb.unreleased {
+		if u.version == nil || u.version.IsHead {
+			return fmt.Errorf("unreleased version must be a registered non-head version")
+		}
+		if u.previewHeader == "" {
+			return fmt.Errorf("unreleased version '%s' needs a preview header", u.version.String())
+		}
+		registered, fallback := false, false
+		for _, v := range cb.versions {
+			registered = registered || v.Equal(u.version)
+			fallback = fallback || (!v.IsHead && v.IsOlderThan(u.version))
+		}
+		if !registered {
+			return fmt.Errorf("unreleased version '%s' is not registered with WithVersions", u.version.String())
+		}
+		if !fallback {
+			return fmt.Errorf("unreleased version '%s' needs an older released version to fall back to", u.version.String())
+		}
+	}
+	return nil
+}
+
+// IsUnreleased reports whether version is still hidden from requests without its preview header
+func (c *Epoch) IsUnreleased(version *Version) bool {
+	for _, u := range c.unreleased {
+		if u.version.Equal(version) && !u.releasedAt(c.clock) {
+			return true
+		}
+	}
+	return false
+}
+
+// releasedAt reports whether a date version's date has arrived on clock
+func (u unreleasedVersion) releasedAt(clock Clock) bool {
+	return u.version.Type == VersionTypeDate && !NewDateVersionFromTime(clock.Now()).IsOlderThan(u.version)
+}
+
+// hiddenFrom returns the oldest unreleased version c's request can't see, or nil
+func (vm *VersionMiddleware) hiddenFrom(c *gin.Context) *Version {
+	var cutoff *Version
+	for _, u := range vm.unreleased {
+		if u.releasedAt(vm.clock) || c.GetHeader(u.previewHeader) != "" {
+			continue
+		}
+		if cutoff == nil || u.version.IsOlderThan(cutoff) {
+			cutoff = u.version
+		}
+	}
+	return cutoff
+}
+
+// releasedVersion replaces a version the request can't see with the newest version it can
+func (vm *VersionMiddleware) releasedVersion(c *gin.Context, version *Version) *Version {
+	cutoff := vm.hiddenFrom(c)
+	if cutoff == nil || version.IsOlderThan(cutoff) {
+		return version
+	}
+	var released *Version
+	for _, v := range vm.versionBundle.GetVersions() {
+		if !v.IsHead && v.IsOlderThan(cutoff) && (released == nil || v.IsNewerThan(released)) {
+			released = v
+		}
+	}
+	return released
+}
+
+// visibleVersionValues lists the version values a request can see
+func (vm *VersionMiddleware) visibleVersionValues(c *gin.Context) []string {
+	cutoff := vm.hiddenFrom(c)
+	if cutoff == nil && !vm.internalHead {
+		return vm.versionBundle.GetVersionValues()
+	}
+	values := make([]string, 0, len(vm.versionBundle.GetVersions()))
+	for _, v := range vm.versionBundle.GetVersions() {
+		if vm.internalHead && v.IsHead {
+			continue
+		}
+		if cutoff == nil || v.IsOlderThan(cutoff) {
+			values = append(values, v.String())
+		}
+	}
+	return values
+}
@@ -0,0 +1,118 @@
+package epoch
+
+import (
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type previewUser struct {
+	ID          int    `json:"id"`
+	DisplayName string `json:"display_name"`
+}
+
+var _ = Describe("Unreleased versions", func() {
+	var (
+		v1, v2, v3 *Version
+		now        time.Time
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		v1, _ = NewDateVersion("2024-01-01")
+		v2, _ = NewDateVersion("2024-06-01")
+		v3, _ = NewDateVersion("2025-01-01")
+		now = time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+	})
+
+	build := func() (*Epoch, *gin.Engine) {
+		// v3 renamed name to display_name
+		change := NewVersionChangeBuilder(v2, v3).
+			ForType(previewUser{}).
+			ResponseToPreviousVersion().
+			RenameField("display_name", "name").
+			Build()
+		epochInstance, err := NewEpoch().
+			WithVersions(v1, v2, v3).
+			WithHeadVersion().
+			WithChanges(change).
+			WithUnreleasedVersion(v3, "X-API-Preview").
+			WithClock(ClockFunc(func() time.Time { return now })).
+			Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		router := gin.New()
+		router.Use(epochInstance.Middleware())
+		router.GET("/users/:id", epochInstance.WrapHandler(func(c *gin.Context) {
+			c.JSON(200, previewUser{ID: 1, DisplayName: "Ada"})
+		}).Returns(previewUser{}).ToHandlerFunc("GET", "/users/:id"))
+		return epochInstance, router
+	}
+
+	get := func(router *gin.Engine, version string, preview bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/users/1", nil)
+		if version != "" {
+			req.Header.Set("X-API-Version", version)
+		}
+		if preview {
+			req.Header.Set("X-API-Preview", "partner-token")
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	It("should hide the unreleased version from requests without the preview header", func() {
+		epochInstance, router := build()
+		Expect(epochInstance.IsUnreleased(v3)).To(BeTrue())
+
+		for _, version := range []string{"2025-01-01", "2025-06-01", "head", ""} {
+			recorder := get(router, version, false)
+			Expect(recorder.Code).To(Equal(200))
+			Expect(recorder.Header().Get("X-API-Version")).To(Equal("2024-06-01"))
+			Expect(recorder.Body.String()).To(MatchJSON(`{"id":1,"name":"Ada"}`))
+		}
+
+		Expect(get(router, "2024-01-01", false).Header().Get("X-API-Version")).To(Equal("2024-01-01"))
+	})
+
+	It("should serve the unreleased version and head to previewing requests", func() {
+		_, router := build()
+
+		recorder := get(router, "2025-01-01", true)
+		Expect(recorder.Header().Get("X-API-Version")).To(Equal("2025-01-01"))
+		Expect(recorder.Body.String()).To(MatchJSON(`{"id":1,"display_name":"Ada"}`))
+
+		Expect(get(router, "", true).Header().Get("X-API-Version")).To(Equal("head"))
+		Expect(get(router, "2024-06-01", true).Body.String()).To(MatchJSON(`{"id":1,"name":"Ada"}`))
+	})
+
+	It("should release date versions once the clock reaches their date", func() {
+		now = time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+		epochInstance, router := build()
+		Expect(epochInstance.IsUnreleased(v3)).To(BeFalse())
+		Expect(get(router, "2025-01-01", false).Header().Get("X-API-Version")).To(Equal("2025-01-01"))
+	})
+
+	It("should leave the unreleased version out of error listings", func() {
+		_, router := build()
+		recorder := get(router, "2023-01-01", false)
+		Expect(recorder.Code).To(Equal(400))
+		Expect(recorder.Body.String()).NotTo(ContainSubstring("2025-01-01"))
+	})
+
+	It("should reject unreleased versions that aren't registered or have nothing to fall back to", func() {
+		v4, _ := NewDateVersion("2026-01-01")
+		_, err := NewEpoch().WithVersions(v1, v2).WithHeadVersion().WithUnreleasedVersion(v4, "X-API-Preview").Build()
+		Expect(err).To(MatchError(ContainSubstring("'2026-01-01' is not registered")))
+
+		_, err = NewEpoch().WithVersions(v1, v2).WithHeadVersion().WithUnreleasedVersion(v1, "X-API-Preview").Build()
+		Expect(err).To(MatchError(ContainSubstring("needs an older released version")))
+
+		_, err = NewEpoch().WithVersions(v1, v2).WithHeadVersion().WithUnreleasedVersion(v2, "").Build()
+		Expect(err).To(MatchError(ContainSubstring("needs a preview header")))
+	})
+})
@@ -0,0 +1,117 @@
+package epoch
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/astronomer/epoch/epoch/jsonast"
+)
+
+// URLRewriter maps a HEAD resource URL to the URL a client of version expects,
+// e.g. "/v2/orgs/1/users/7" to "/users/7" for versions before the org-scoped layout.
+// Returning the URL unchanged leaves it as is.
+type URLRewriter func(version *Version, url string) string
+
+// urlHeaders are the standard response headers whose values are URLs
+var urlHeaders = []string{"Location", "Content-Location"}
+
+// WithURLRewriter rewrites the URLs in Location, Content-Location and Link headers for
+// clients of older versions. bodyFields are dotted paths of URL-valued response fields
+// (in HEAD's structure) to rewrite as well; for array responses they apply to each item.
+// HEAD responses are never rewritten.
+func (vah *VersionAwareHandler) WithURLRewriter(rewriter URLRewriter, bodyFields ...string) *VersionAwareHandler {
+	vah.urlRewriter = rewriter
+	vah.urlBodyFields = bodyFields
+	return vah
+}
+
+// rewriteURLHeaders rewrites the URL-valued headers of a response for version
+func (vah *VersionAwareHandler) rewriteURLHeaders(header http.Header, version *Version) {
+	if vah.urlRewriter == nil {
+		return
+	}
+	for _, name := range urlHeaders {
+		values := header.Values(name)
+		for i, value := range values {
+			values[i] = vah.urlRewriter(version, value)
+		}
+	}
+	links := header.Values("Link")
+	for i, value := range links {
+		links[i] = rewriteLinkHeader(value, func(url string) string { return vah.urlRewriter(version, url) })
+	}
+}
+
+// rewriteLinkHeader rewrites every <url> reference of an RFC 8288 Link header value
+func rewriteLinkHeader(value string, rewrite func(string) string) string {
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(value, '<')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(value[start:], '>')
+		if end < 0 {
+			break
+		}
+		end += start
+		b.WriteString(value[:start+1])
+		b.WriteString(rewrite(value[start+1 : end]))
+		b.WriteByte('>')
+		value = value[end+1:]
+	}
+	b.WriteString(value)
+	return b.String()
+}
+
+// rewriteURLFields rewrites the configured URL-valued body fields for version
+func (vah *VersionAwareHandler) rewriteURLFields(body *jsonast.Node, version *Version) error {
+	if vah.urlRewriter == nil || len(vah.urlBodyFields) == 0 || body == nil {
+		return nil
+	}
+	if IsNodeArray(body) {
+		return ForEachNodeArrayItem(body, "", func(_ int, item *jsonast.Node) error {
+			return vah.rewriteURLFields(item, version)
+		})
+	}
+	if !IsNodeObject(body) {
+		return nil
+	}
+	for _, field := range vah.urlBodyFields {
+		node := GetNodeAtPath(body, field)
+		if node == nil || node.TypeSafe() != jsonast.V_STRING {
+			continue
+		}
+		url, err := node.String()
+		if err != nil {
+			return fmt.Errorf("failed to read URL field %s: %w", field, err)
+		}
+		if rewritten := vah.urlRewriter(version, url); rewritten != url {
+			if err := SetNodeAtPath(body, field, rewritten); err != nil {
+				return fmt.Errorf("failed to rewrite URL field %s: %w", field, err)
+			}
+		}
+	}
+	return nil
+}
+
+// rewriteURLBody rewrites the URL-valued fields of a raw JSON body that needs no other migration
+// Bodies that aren't JSON are returned unchanged.
+func (vah *VersionAwareHandler) rewriteURLBody(body []byte, version *Version) ([]byte, error) {
+	if vah.urlRewriter == nil || len(vah.urlBodyFields) == 0 {
+		return body, nil
+	}
+	node, err := vah.jsonEngine.Parse(body)
+	if err != nil {
+		return body, nil
+	}
+	if err := vah.rewriteURLFields(node, version); err != nil {
+		return nil, err
+	}
+	rewritten, err := vah.jsonEngine.Serialize(node)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize response with rewritten URLs: %w", err)
+	}
+	return rewritten, nil
+}
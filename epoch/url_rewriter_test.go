@@ -0,0 +1,95 @@
+package epoch
+
+import (
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type urlOrder struct {
+	ID   int    `json:"id"`
+	Href string `json:"href"`
+}
+
+var _ = Describe("URL rewriting", func() {
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+	})
+
+	// Versions before 2024-06-01 used /orders/:id instead of /stores/1/orders/:id
+	rewriter := func(version *Version, url string) string {
+		if version.String() >= "2024-06-01" {
+			return url
+		}
+		return strings.Replace(url, "/stores/1/orders/", "/orders/", 1)
+	}
+
+	serve := func(version string, returns interface{}, bodyFields ...string) *httptest.ResponseRecorder {
+		v1, v2, _ := newTestVersions()
+		epochInstance, err := setupHeadEpoch([]*Version{v1, v2}, nil, func(builder *EpochBuilder) *EpochBuilder {
+			return builder.WithURLRewriter(rewriter, bodyFields...)
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		wrapper := epochInstance.WrapHandler(func(c *gin.Context) {
+			c.Header("Location", "/stores/1/orders/7")
+			c.Header("Link", `</stores/1/orders/8>; rel="next", </stores/1/orders/6>; rel="prev"`)
+			c.JSON(201, urlOrder{ID: 7, Href: "/stores/1/orders/7"})
+		})
+		if returns != nil {
+			wrapper = wrapper.Returns(returns)
+		}
+
+		router := setupRouterWithMiddleware(epochInstance)
+		router.POST("/orders", wrapper.ToHandlerFunc("POST", "/orders"))
+
+		recorder := serveVersioned(router, httptest.NewRequest("POST", "/orders", nil), version)
+		Expect(recorder.Code).To(Equal(201))
+		return recorder
+	}
+
+	It("should rewrite Location and Link headers for older versions", func() {
+		recorder := serve("2024-01-01", urlOrder{})
+		Expect(recorder.Header().Get("Location")).To(Equal("/orders/7"))
+		Expect(recorder.Header().Get("Link")).To(Equal(`</orders/8>; rel="next", </orders/6>; rel="prev"`))
+		Expect(recorder.Body.String()).To(ContainSubstring(`"href":"/stores/1/orders/7"`))
+	})
+
+	It("should leave newer versions and HEAD alone", func() {
+		for _, version := range []string{"2024-06-01", "head"} {
+			recorder := serve(version, urlOrder{})
+			Expect(recorder.Header().Get("Location")).To(Equal("/stores/1/orders/7"))
+		}
+	})
+
+	It("should rewrite URL-valued body fields", func() {
+		recorder := serve("2024-01-01", urlOrder{}, "href")
+		Expect(recorder.Body.String()).To(Equal(`{"id":7,"href":"/orders/7"}`))
+	})
+
+	It("should rewrite body fields for endpoints without a response type", func() {
+		recorder := serve("2024-01-01", nil, "href")
+		Expect(recorder.Body.String()).To(Equal(`{"id":7,"href":"/orders/7"}`))
+		Expect(recorder.Header().Get("Location")).To(Equal("/orders/7"))
+	})
+
+	It("should rewrite each item of array bodies", func() {
+		body, err := DefaultJSONEngine().Parse([]byte(`[{"href":"/stores/1/orders/1"},{"href":"/stores/1/orders/2"},{"id":3}]`))
+		Expect(err).NotTo(HaveOccurred())
+		vah := (&VersionAwareHandler{}).WithURLRewriter(rewriter, "href")
+		v1, _ := NewDateVersion("2024-01-01")
+		Expect(vah.rewriteURLFields(body, v1)).To(Succeed())
+		out, err := DefaultJSONEngine().Serialize(body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(out)).To(Equal(`[{"href":"/orders/1"},{"href":"/orders/2"},{"id":3}]`))
+	})
+
+	It("should keep Link parameters and malformed values intact", func() {
+		upper := strings.ToUpper
+		Expect(rewriteLinkHeader(`<a>; rel="x"; title="<b"`, upper)).To(Equal(`<A>; rel="x"; title="<b"`))
+		Expect(rewriteLinkHeader(`no links`, upper)).To(Equal(`no links`))
+	})
+})
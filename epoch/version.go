@@ -18,7 +18,7 @@ type Version struct {
 	Type VersionType
 	// IsHead indicates if this is the latest version
 	IsHead bool
-	// Changes associated with this version
+	// Changes associated with this version; once built, read them with VersionBundle.VersionChanges
 	Changes []VersionChangeInterface
 }
 
@@ -91,6 +91,19 @@ func NewDateVersion(dateStr string) (*Version, error) {
 	}, nil
 }
 
+// NewDateVersionFromTime creates a date-based version for the calendar date of t in t's location
+// Pick the zone with t.In(loc): 23:30 on 2024-06-01 in New York is version 2024-06-01, although
+// it's already 2024-06-02 in UTC.
+func NewDateVersionFromTime(t time.Time) *Version {
+	date := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return &Version{
+		Raw:    date.Format("2006-01-02"),
+		Date:   &date,
+		Type:   VersionTypeDate,
+		IsHead: false,
+	}
+}
+
 // NewSemverVersion creates a new semantic version
 // Supports both major.minor.patch and major.minor formats
 func NewSemverVersion(semverStr string) (*Version, error) {
@@ -2,6 +2,7 @@ package epoch
 
 import (
 	"fmt"
+	"sync"
 )
 
 // VersionBundle manages a collection of versions and their changes
@@ -18,6 +19,9 @@ type VersionBundle struct {
 
 	// Set of version values for quick lookup
 	versionValuesSet map[string]bool
+
+	// changesMu guards the versions' Changes, which RegisterChange extends after Build()
+	changesMu sync.RWMutex
 }
 
 // NewVersionBundle creates a new version bundle
@@ -101,6 +105,26 @@ func (vb *VersionBundle) GetVersions() []*Version {
 	return vb.versions
 }
 
+// VersionChanges returns the changes migrating from v, safe to call while changes are
+// registered at runtime. Read them through here rather than v.Changes once the Epoch is built.
+func (vb *VersionBundle) VersionChanges(v *Version) []VersionChangeInterface {
+	vb.changesMu.RLock()
+	defer vb.changesMu.RUnlock()
+	return append([]VersionChangeInterface(nil), v.Changes...)
+}
+
+// attachChange records change on its from-version, for schema generation
+func (vb *VersionBundle) attachChange(change VersionChangeInterface) {
+	vb.changesMu.Lock()
+	defer vb.changesMu.Unlock()
+	for _, v := range vb.allVersions {
+		if v.Equal(change.FromVersion()) {
+			v.Changes = append(v.Changes, change)
+			return
+		}
+	}
+}
+
 // GetVersionValues returns all version values as strings
 func (vb *VersionBundle) GetVersionValues() []string {
 	return vb.versionValues
@@ -120,7 +144,7 @@ func (vb *VersionBundle) ParseVersion(versionStr string) (*Version, error) {
 		}
 	}
 
-	return nil, fmt.Errorf("unknown version '%s': available versions are %v", versionStr, vb.versionValues)
+	return nil, fmt.Errorf("%w '%s': available versions are %v", ErrUnknownVersion, versionStr, vb.versionValues)
 }
 
 // IsVersionDefined checks if a version is defined in this bundle
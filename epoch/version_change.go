@@ -6,8 +6,9 @@ import (
 	"reflect"
 	"sort"
 	"strings"
+	"sync"
 
-	"github.com/bytedance/sonic/ast"
+	"github.com/astronomer/epoch/epoch/jsonast"
 )
 
 // AlterRequestInstruction defines how to modify a request during migration
@@ -43,9 +44,33 @@ type VersionChange struct {
 	requestOperationsByType  map[reflect.Type]RequestToNextVersionOperationList
 	responseOperationsByType map[reflect.Type]ResponseToPreviousVersionOperationList
 
+	// Endpoint-level envelope operations keyed by "METHOD /path/pattern"
+	responseEnvelopeOps map[string][]ResponseEnvelopeOperation
+
+	// Endpoint-level query parameter operations keyed by "METHOD /path/pattern"
+	requestQueryOps map[string][]RequestQueryOperation
+
+	// Endpoint-level path parameter mappings keyed by "METHOD /path/pattern"
+	requestPathParamOps map[string][]*RequestMapPathParam
+
 	// Version information
 	fromVersion *Version
 	toVersion   *Version
+
+	// enabledWhen gates the change at runtime (see EnabledWhen); nil means always enabled
+	enabledWhen func(ctx context.Context) bool
+
+	// typeMatchers hold ForTypesMatching operations. Matched types are bound into the
+	// per-type containers above as the chain meets them, so those are guarded by typesMu.
+	typeMatchers []typeMatcher
+	typesMu      sync.RWMutex
+	boundTypes   map[reflect.Type]bool
+
+	// declaredTypes are the types the change's instructions name explicitly (ForType)
+	declaredTypes []reflect.Type
+
+	// namespace is the ChangeSet the change was published in, "" for the service's own
+	namespace string
 }
 
 // NewVersionChange creates a new version change with the given description and instructions
@@ -61,12 +86,32 @@ func NewVersionChange(description string, fromVersion, toVersion *Version, instr
 		globalResponseInstructions:             make([]*AlterResponseInstruction, 0),
 		requestOperationsByType:                make(map[reflect.Type]RequestToNextVersionOperationList),
 		responseOperationsByType:               make(map[reflect.Type]ResponseToPreviousVersionOperationList),
+		responseEnvelopeOps:                    make(map[string][]ResponseEnvelopeOperation),
+		requestQueryOps:                        make(map[string][]RequestQueryOperation),
+		requestPathParamOps:                    make(map[string][]*RequestMapPathParam),
+		boundTypes:                             make(map[reflect.Type]bool),
 	}
 
 	vc.extractInstructionsIntoContainers()
 	return vc
 }
 
+// declareType records a type the change names explicitly, once
+func (vc *VersionChange) declareType(t reflect.Type) {
+	for _, declared := range vc.declaredTypes {
+		if declared == t {
+			return
+		}
+	}
+	vc.declaredTypes = append(vc.declaredTypes, t)
+}
+
+// DeclaredTypes returns the types the change targets explicitly with ForType, in declaration
+// order. Types selected by ForTypesMatching aren't included.
+func (vc *VersionChange) DeclaredTypes() []reflect.Type {
+	return append([]reflect.Type(nil), vc.declaredTypes...)
+}
+
 // extractInstructionsIntoContainers organizes instructions by type
 func (vc *VersionChange) extractInstructionsIntoContainers() {
 	for _, instruction := range vc.instructionsToMigrateToPreviousVersion {
@@ -85,6 +130,7 @@ func (vc *VersionChange) extractInstructionsIntoContainers() {
 
 					vc.alterRequestBySchemaInstructions[schemaType] = append(
 						vc.alterRequestBySchemaInstructions[schemaType], inst)
+					vc.declareType(schemaType)
 				}
 			}
 
@@ -102,6 +148,7 @@ func (vc *VersionChange) extractInstructionsIntoContainers() {
 
 					vc.alterResponseBySchemaInstructions[schemaType] = append(
 						vc.alterResponseBySchemaInstructions[schemaType], inst)
+					vc.declareType(schemaType)
 				}
 			}
 		}
@@ -109,10 +156,22 @@ func (vc *VersionChange) extractInstructionsIntoContainers() {
 }
 
 // MigrateRequest applies request migrations for this version change using explicit types
+// Panics in transformers are returned as a *MigrationPanicError.
 func (vc *VersionChange) MigrateRequest(ctx context.Context, requestInfo *RequestInfo) error {
+	return vc.recoverChange(DirectionRequest, func() error {
+		return vc.migrateRequest(ctx, requestInfo)
+	})
+}
+
+// migrateRequest applies request migrations for this version change
+func (vc *VersionChange) migrateRequest(ctx context.Context, requestInfo *RequestInfo) error {
 	if requestInfo.Body == nil {
 		return nil // No body to migrate
 	}
+	if !vc.isEnabled(ctx, requestInfo.GinContext) {
+		return nil
+	}
+	requestInfo.ctx = ctx
 
 	// First, apply global instructions (apply to all requests)
 	for _, instruction := range vc.globalRequestInstructions {
@@ -133,7 +192,7 @@ func (vc *VersionChange) MigrateRequest(ctx context.Context, requestInfo *Reques
 
 	// Apply type-specific instructions using the matched type
 	if matchedType != nil {
-		if instructions, exists := vc.alterRequestBySchemaInstructions[matchedType]; exists {
+		if instructions := vc.requestInstructionsFor(matchedType); len(instructions) > 0 {
 			for _, instruction := range instructions {
 				if err := instruction.Transformer(requestInfo); err != nil {
 					return fmt.Errorf("type-based request migration failed for change '%s' (type: %s): %w",
@@ -171,7 +230,28 @@ func (vc *VersionChange) MigrateRequest(ctx context.Context, requestInfo *Reques
 }
 
 // MigrateResponse applies response migrations for this version change using explicit types
+// Panics in transformers are returned as a *MigrationPanicError.
 func (vc *VersionChange) MigrateResponse(ctx context.Context, responseInfo *ResponseInfo) error {
+	return vc.recoverChange(DirectionResponse, func() error {
+		return vc.migrateResponse(ctx, responseInfo)
+	})
+}
+
+// migrateResponse applies response migrations for this version change
+func (vc *VersionChange) migrateResponse(ctx context.Context, responseInfo *ResponseInfo) error {
+	if !vc.isEnabled(ctx, responseInfo.GinContext) {
+		return nil
+	}
+	responseInfo.ctx = ctx
+
+	if len(vc.globalResponseInstructions) > 0 ||
+		(responseInfo.matchedSchemaType != nil && len(vc.responseInstructionsFor(responseInfo.matchedSchemaType)) > 0) {
+		if err := responseInfo.takeSnapshot(); err != nil {
+			return err
+		}
+		defer func() { responseInfo.snapshot = nil }()
+	}
+
 	// First, apply global instructions (apply to all responses)
 	for _, instruction := range vc.globalResponseInstructions {
 		// Check if we should migrate error responses
@@ -199,7 +279,7 @@ func (vc *VersionChange) MigrateResponse(ctx context.Context, responseInfo *Resp
 
 	// Apply type-specific instructions using the matched type
 	if matchedType != nil {
-		if instructions, exists := vc.alterResponseBySchemaInstructions[matchedType]; exists {
+		if instructions := vc.responseInstructionsFor(matchedType); len(instructions) > 0 {
 			for _, instruction := range instructions {
 				// Check if we should migrate error responses
 				if responseInfo.StatusCode >= 400 && !instruction.MigrateHTTPErrors {
@@ -268,6 +348,9 @@ func (vc *VersionChange) SetHiddenFromChangelog(hidden bool) {
 // GetRequestOperationsByType returns the request operations for a specific type
 // This is used by OpenAPI schema generation to apply field operations to schemas
 func (vc *VersionChange) GetRequestOperationsByType(targetType reflect.Type) (RequestToNextVersionOperationList, bool) {
+	vc.bindType(targetType)
+	vc.typesMu.RLock()
+	defer vc.typesMu.RUnlock()
 	ops, exists := vc.requestOperationsByType[targetType]
 	return ops, exists
 }
@@ -275,10 +358,24 @@ func (vc *VersionChange) GetRequestOperationsByType(targetType reflect.Type) (Re
 // GetResponseOperationsByType returns the response operations for a specific type
 // This is used by OpenAPI schema generation to apply field operations to schemas
 func (vc *VersionChange) GetResponseOperationsByType(targetType reflect.Type) (ResponseToPreviousVersionOperationList, bool) {
+	vc.bindType(targetType)
+	vc.typesMu.RLock()
+	defer vc.typesMu.RUnlock()
 	ops, exists := vc.responseOperationsByType[targetType]
 	return ops, exists
 }
 
+// hasInstructionsFor reports whether this change has type-specific instructions for the given type
+func (vc *VersionChange) hasInstructionsFor(targetType reflect.Type, direction TransformDirection) bool {
+	switch direction {
+	case DirectionRequest:
+		return len(vc.requestInstructionsFor(targetType)) > 0
+	case DirectionResponse:
+		return len(vc.responseInstructionsFor(targetType)) > 0
+	}
+	return false
+}
+
 // InstructionApplier is a function that applies an instruction to a transformable body
 type InstructionApplier func(body TransformableBody) error
 
@@ -292,7 +389,7 @@ func (vc *VersionChange) getInstructionAppliers(
 
 	switch direction {
 	case DirectionRequest:
-		if instructions, exists := vc.alterRequestBySchemaInstructions[targetType]; exists {
+		if instructions := vc.requestInstructionsFor(targetType); len(instructions) > 0 {
 			for _, inst := range instructions {
 				// Capture instruction in closure
 				instruction := inst
@@ -310,7 +407,7 @@ func (vc *VersionChange) getInstructionAppliers(
 			}
 		}
 	case DirectionResponse:
-		if instructions, exists := vc.alterResponseBySchemaInstructions[targetType]; exists {
+		if instructions := vc.responseInstructionsFor(targetType); len(instructions) > 0 {
 			for _, inst := range instructions {
 				// Capture instruction in closure
 				instruction := inst
@@ -332,8 +429,16 @@ func (vc *VersionChange) getInstructionAppliers(
 }
 
 // MigrationChain manages a sequence of version changes
+// It is safe for concurrent use: AddChange swaps in a new slice under a write lock,
+// and migrations work on the snapshot taken when they start.
 type MigrationChain struct {
-	changes []*VersionChange
+	mu         sync.RWMutex
+	changes    []*VersionChange
+	generation uint64 // incremented on every successful AddChange
+
+	// sparse chains hold an endpoint's bound changes (HandlerWrapper.WithChanges), which
+	// needn't cover every version, so responses skip the versions no change connects
+	sparse bool
 }
 
 // NewMigrationChain creates a new migration chain with cycle detection
@@ -357,7 +462,7 @@ func NewMigrationChain(changes []*VersionChange) (*MigrationChain, error) {
 	}
 
 	// Detect cycles in the version graph
-	if err := mc.detectCycles(); err != nil {
+	if err := detectCycles(mc.changes); err != nil {
 		return nil, err
 	}
 
@@ -366,6 +471,8 @@ func NewMigrationChain(changes []*VersionChange) (*MigrationChain, error) {
 
 // MigrateRequest applies all changes in the chain for request migration
 func (mc *MigrationChain) MigrateRequest(ctx context.Context, requestInfo *RequestInfo, from, to *Version) error {
+	changes := mc.snapshot()
+
 	// If from and to are the same, no migration needed
 	if from.Equal(to) {
 		return nil
@@ -380,7 +487,7 @@ func (mc *MigrationChain) MigrateRequest(ctx context.Context, requestInfo *Reque
 	if targetVersion.IsHead {
 		// Find the latest non-HEAD version
 		var latestVersion *Version
-		for _, change := range mc.changes {
+		for _, change := range changes {
 			if change.ToVersion() != nil && !change.ToVersion().IsHead {
 				if latestVersion == nil || change.ToVersion().IsNewerThan(latestVersion) {
 					latestVersion = change.ToVersion()
@@ -404,7 +511,7 @@ func (mc *MigrationChain) MigrateRequest(ctx context.Context, requestInfo *Reque
 	// A change is included if:
 	// 1. Its FromVersion >= from (don't apply changes from before our starting version)
 	// 2. Its ToVersion <= targetVersion (don't apply changes past our target)
-	for _, change := range mc.changes {
+	for _, change := range changes {
 		// Skip changes that start before our starting version
 		if change.FromVersion().IsOlderThan(from) {
 			continue
@@ -415,6 +522,10 @@ func (mc *MigrationChain) MigrateRequest(ctx context.Context, requestInfo *Reque
 			break
 		}
 
+		if err := checkMigrationContext(ctx); err != nil {
+			return err
+		}
+
 		// Apply this change - it's in the migration path [from, targetVersion]
 		if err := change.MigrateRequest(ctx, requestInfo); err != nil {
 			return fmt.Errorf("migration failed at %s->%s: %w",
@@ -427,6 +538,8 @@ func (mc *MigrationChain) MigrateRequest(ctx context.Context, requestInfo *Reque
 
 // MigrateResponse applies all changes in reverse for response migration
 func (mc *MigrationChain) MigrateResponse(ctx context.Context, responseInfo *ResponseInfo, from, to *Version) error {
+	changes := mc.snapshot()
+
 	// If from and to are the same, no migration needed
 	if from.Equal(to) {
 		return nil
@@ -441,7 +554,7 @@ func (mc *MigrationChain) MigrateResponse(ctx context.Context, responseInfo *Res
 	if currentVersion.IsHead {
 		// Find the latest non-HEAD version
 		var latestVersion *Version
-		for _, change := range mc.changes {
+		for _, change := range changes {
 			if change.ToVersion() != nil && !change.ToVersion().IsHead {
 				if latestVersion == nil || change.ToVersion().IsNewerThan(latestVersion) {
 					latestVersion = change.ToVersion()
@@ -455,6 +568,10 @@ func (mc *MigrationChain) MigrateResponse(ctx context.Context, responseInfo *Res
 		}
 	}
 
+	if mc.sparse {
+		return migrateResponseThroughChanges(ctx, responseInfo, changes, currentVersion, to)
+	}
+
 	// Build the migration path from 'from' to 'to' (going backward through versions)
 	// For example, from v3 to v1: v3→v2→v1
 	// We need to apply changes at each step in reverse
@@ -462,7 +579,7 @@ func (mc *MigrationChain) MigrateResponse(ctx context.Context, responseInfo *Res
 	iterationCount := 0
 	// Safety limit derived from the number of changes + 1.
 	// Cycles are already detected at construction time, so this is a defensive safeguard.
-	maxIterations := len(mc.changes) + 1
+	maxIterations := len(changes) + 1
 
 	for !currentVersion.Equal(to) {
 		iterationCount++
@@ -476,7 +593,7 @@ func (mc *MigrationChain) MigrateResponse(ctx context.Context, responseInfo *Res
 		var stepChanges []*VersionChange
 		var nextVersion *Version
 
-		for _, change := range mc.changes {
+		for _, change := range changes {
 			// We want changes like v2→v3 to step back from v3→v2
 			if change.ToVersion().Equal(currentVersion) && change.FromVersion().IsOlderThan(currentVersion) {
 				// Pick the change that gets us closer to target 'to'
@@ -494,7 +611,7 @@ func (mc *MigrationChain) MigrateResponse(ctx context.Context, responseInfo *Res
 		}
 
 		// Collect ALL changes at this level (from nextVersion to currentVersion)
-		for _, change := range mc.changes {
+		for _, change := range changes {
 			if change.FromVersion().Equal(nextVersion) && change.ToVersion().Equal(currentVersion) {
 				stepChanges = append(stepChanges, change)
 			}
@@ -502,6 +619,9 @@ func (mc *MigrationChain) MigrateResponse(ctx context.Context, responseInfo *Res
 
 		// Apply all changes at this level in reverse
 		for _, change := range stepChanges {
+			if err := checkMigrationContext(ctx); err != nil {
+				return err
+			}
 			if err := change.MigrateResponse(ctx, responseInfo); err != nil {
 				return fmt.Errorf("reverse migration failed at %s->%s: %w",
 					change.ToVersion().String(), change.FromVersion().String(), err)
@@ -519,39 +639,57 @@ func (mc *MigrationChain) MigrateResponse(ctx context.Context, responseInfo *Res
 // The change is inserted in sorted order (by FromVersion, then ToVersion) and
 // cycle detection is re-run to ensure the chain remains valid.
 func (mc *MigrationChain) AddChange(change *VersionChange) error {
-	mc.changes = append(mc.changes, change)
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	// Copy-on-write so in-flight migrations keep iterating their own snapshot
+	updated := make([]*VersionChange, len(mc.changes), len(mc.changes)+1)
+	copy(updated, mc.changes)
+	updated = append(updated, change)
 
 	// Re-sort to maintain the ordering invariant required by MigrateRequest
-	sort.Slice(mc.changes, func(i, j int) bool {
-		cmp := mc.changes[i].FromVersion().Compare(mc.changes[j].FromVersion())
+	sort.Slice(updated, func(i, j int) bool {
+		cmp := updated[i].FromVersion().Compare(updated[j].FromVersion())
 		if cmp != 0 {
 			return cmp < 0
 		}
-		return mc.changes[i].ToVersion().Compare(mc.changes[j].ToVersion()) < 0
+		return updated[i].ToVersion().Compare(updated[j].ToVersion()) < 0
 	})
 
-	// Re-run cycle detection to ensure the new change doesn't introduce a cycle
-	if err := mc.detectCycles(); err != nil {
-		// Rollback: remove the newly added change
-		for i, c := range mc.changes {
-			if c == change {
-				mc.changes = append(mc.changes[:i], mc.changes[i+1:]...)
-				break
-			}
-		}
+	// Re-run cycle detection to ensure the new change doesn't introduce a cycle.
+	// On failure the chain is left untouched.
+	if err := detectCycles(updated); err != nil {
 		return err
 	}
 
+	mc.changes = updated
+	mc.generation++
 	return nil
 }
 
+// snapshot returns the current changes; callers must not modify the returned slice
+func (mc *MigrationChain) snapshot() []*VersionChange {
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+	return mc.changes
+}
+
+// Generation returns a counter that changes whenever a change is added to the chain.
+// Callers caching per-chain results (bypass decisions, response templates) compare it
+// to detect that their cache is stale.
+func (mc *MigrationChain) Generation() uint64 {
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+	return mc.generation
+}
+
 // detectCycles uses depth-first search to find cycles in the version graph
-func (mc *MigrationChain) detectCycles() error {
+func detectCycles(changes []*VersionChange) error {
 	// Build adjacency list: version string -> list of target versions
 	graph := make(map[string][]string)
 	versionSet := make(map[string]bool)
 
-	for _, change := range mc.changes {
+	for _, change := range changes {
 		from := change.FromVersion().String()
 		to := change.ToVersion().String()
 
@@ -616,7 +754,7 @@ func dfs(node string, graph map[string][]string, visited map[string]int, path []
 
 // GetChanges returns all changes in the chain
 func (mc *MigrationChain) GetChanges() []*VersionChange {
-	return mc.changes
+	return mc.snapshot()
 }
 
 // GetMigrationPath returns the changes needed to migrate from one version to another
@@ -625,11 +763,12 @@ func (mc *MigrationChain) GetMigrationPath(from, to *Version) []*VersionChange {
 		return []*VersionChange{}
 	}
 
+	changes := mc.snapshot()
 	var path []*VersionChange
 
 	// If migrating forward (from older to newer)
 	if from.IsOlderThan(to) {
-		for _, change := range mc.changes {
+		for _, change := range changes {
 			// Include changes that are in the path from 'from' to 'to'
 			// A change is included if:
 			// 1. Its FromVersion is >= from (Equal or IsNewerThan)
@@ -642,7 +781,7 @@ func (mc *MigrationChain) GetMigrationPath(from, to *Version) []*VersionChange {
 	} else {
 		// If migrating backward (from newer to older)
 		// We need to reverse the changes that got us from 'to' to 'from'
-		for _, change := range mc.changes {
+		for _, change := range changes {
 			// Include changes that are in the path from 'to' to 'from'
 			// A change is included if:
 			// 1. Its FromVersion is >= to (Equal or IsNewerThan)
@@ -657,6 +796,26 @@ func (mc *MigrationChain) GetMigrationPath(from, to *Version) []*VersionChange {
 	return path
 }
 
+// HasMigrationsForTypes reports whether any change between two versions alters one of the
+// given types in the given direction. Used to bypass body capture and parsing entirely when
+// migrating would be a no-op.
+func (mc *MigrationChain) HasMigrationsForTypes(from, to *Version, direction TransformDirection, types []reflect.Type) bool {
+	for _, change := range mc.GetMigrationPath(from, to) {
+		if direction == DirectionRequest && len(change.globalRequestInstructions) > 0 {
+			return true
+		}
+		if direction == DirectionResponse && len(change.globalResponseInstructions) > 0 {
+			return true
+		}
+		for _, t := range types {
+			if change.hasInstructionsFor(t, direction) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // MigrateRequestForType applies request migrations for a known type (NO runtime matching)
 // This is used by the endpoint registry system where types are explicitly declared at setup time
 func (mc *MigrationChain) MigrateRequestForType(
@@ -718,7 +877,8 @@ func (mc *MigrationChain) MigrateResponseForType(
 }
 
 // MigrateResponseForTypeWithNestedObjects applies response migrations for a known type
-// with full support for nested arrays and nested objects
+// with full support for nested arrays and nested objects. References expanded along the way
+// (ExpandReference) are fetched in batches once all changes have run.
 func (mc *MigrationChain) MigrateResponseForTypeWithNestedObjects(
 	ctx context.Context,
 	responseInfo *ResponseInfo,
@@ -726,6 +886,25 @@ func (mc *MigrationChain) MigrateResponseForTypeWithNestedObjects(
 	nestedArrays map[string]reflect.Type,
 	nestedObjects map[string]reflect.Type,
 	from, to *Version,
+) error {
+	if responseInfo.references == nil {
+		responseInfo.references = &referenceBatch{}
+		defer func() { responseInfo.references = nil }()
+	}
+	if err := mc.migrateResponseForKnownType(ctx, responseInfo, knownType, nestedArrays, nestedObjects, from, to); err != nil {
+		return err
+	}
+	return responseInfo.references.resolve(ctx)
+}
+
+// migrateResponseForKnownType applies the response migrations for a known type
+func (mc *MigrationChain) migrateResponseForKnownType(
+	ctx context.Context,
+	responseInfo *ResponseInfo,
+	knownType reflect.Type,
+	nestedArrays map[string]reflect.Type,
+	nestedObjects map[string]reflect.Type,
+	from, to *Version,
 ) error {
 	// If knownType is nil, skip type-specific processing and just apply migrations
 	if knownType == nil {
@@ -764,7 +943,7 @@ func (mc *MigrationChain) transformTopLevelArray(
 		return nil
 	}
 
-	if body.TypeSafe() != ast.V_ARRAY {
+	if body.TypeSafe() != jsonast.V_ARRAY {
 		return fmt.Errorf("expected array but got %v", body.TypeSafe())
 	}
 
@@ -774,10 +953,13 @@ func (mc *MigrationChain) transformTopLevelArray(
 		return fmt.Errorf("failed to get array length: %w", err)
 	}
 
-	// Transform each item
+	// Transform each item, stopping as soon as the context is done
 	for i := 0; i < arrayLen; i++ {
+		if err := checkMigrationContext(ctx); err != nil {
+			return err
+		}
 		item := body.Index(i)
-		if item == nil {
+		if isSkippedElement(item) {
 			continue
 		}
 
@@ -809,27 +991,18 @@ func (mc *MigrationChain) transformTopLevelArray(
 	return nil
 }
 
-// getNodeAtPath navigates to a nested node using dot-notation path
-// Returns nil if any part of the path doesn't exist
-func getNodeAtPath(root *ast.Node, path string) *ast.Node {
-	if root == nil || path == "" {
-		return root
+// isSkippedElement reports whether migrations skip an array element: legacy payloads mix
+// nulls and primitives into arrays of objects, and type operations only apply to objects
+// (and arrays, for nested slices). SchemaMatch.SkippedElements counts the skipped elements.
+func isSkippedElement(item *jsonast.Node) bool {
+	if item == nil {
+		return true
 	}
-
-	parts := strings.Split(path, ".")
-	current := root
-
-	for _, part := range parts {
-		if current == nil {
-			return nil
-		}
-		current = current.Get(part)
-		if current == nil || !current.Exists() {
-			return nil
-		}
+	switch item.TypeSafe() {
+	case jsonast.V_OBJECT, jsonast.V_ARRAY:
+		return false
 	}
-
-	return current
+	return true
 }
 
 // transformNestedArrayItems applies THIS version change's migrations to items in a nested array field
@@ -848,8 +1021,8 @@ func (vc *VersionChange) transformNestedArrayItems(
 	}
 
 	// Navigate to the array field using dot-notation path
-	arrayField := getNodeAtPath(body, fieldPath)
-	if arrayField == nil || !arrayField.Exists() || arrayField.TypeSafe() != ast.V_ARRAY {
+	arrayField := GetNodeAtPath(body, fieldPath)
+	if arrayField == nil || !arrayField.Exists() || arrayField.TypeSafe() != jsonast.V_ARRAY {
 		return nil
 	}
 
@@ -866,13 +1039,21 @@ func (vc *VersionChange) transformNestedArrayItems(
 	itemNestedArrays, itemNestedObjects := BuildNestedTypeMaps(itemType)
 
 	for i := 0; i < length; i++ {
+		if err := checkMigrationContext(ctx); err != nil {
+			return err
+		}
 		item := arrayField.Index(i)
-		if item == nil {
+		if isSkippedElement(item) {
 			continue
 		}
 
 		// Create a new TransformableBody for the array item
 		itemInfo := info.NewForNestedArrayItem(item, itemType)
+		if len(appliers) > 0 {
+			if err := snapshotNested(itemInfo); err != nil {
+				return err
+			}
+		}
 
 		// Apply only THIS version change's instructions (single step)
 		for _, applier := range appliers {
@@ -914,8 +1095,8 @@ func (vc *VersionChange) transformNestedObject(
 	}
 
 	// Navigate to the object field using dot-notation path
-	objectField := getNodeAtPath(body, fieldPath)
-	if objectField == nil || !objectField.Exists() || objectField.TypeSafe() != ast.V_OBJECT {
+	objectField := GetNodeAtPath(body, fieldPath)
+	if objectField == nil || !objectField.Exists() || objectField.TypeSafe() != jsonast.V_OBJECT {
 		return nil
 	}
 
@@ -924,6 +1105,11 @@ func (vc *VersionChange) transformNestedObject(
 
 	// Create a new TransformableBody for the nested object
 	objectInfo := info.NewForNestedObject(objectField, objectType)
+	if len(appliers) > 0 {
+		if err := snapshotNested(objectInfo); err != nil {
+			return err
+		}
+	}
 
 	// Apply only THIS version change's instructions (single step)
 	for _, applier := range appliers {
@@ -4,7 +4,7 @@ import (
 	"reflect"
 	"strings"
 
-	"github.com/bytedance/sonic/ast"
+	"github.com/astronomer/epoch/epoch/jsonast"
 	"github.com/gin-gonic/gin"
 )
 
@@ -21,6 +21,10 @@ type versionChangeBuilder struct {
 	fromVersion    *Version
 	toVersion      *Version
 	typeOps        map[reflect.Type]*typeBuilder
+	typeMatchers   []*typeBuilder
+	envelopeOps    map[string][]ResponseEnvelopeOperation
+	queryOps       map[string][]RequestQueryOperation
+	pathParamOps   map[string][]*RequestMapPathParam
 	customRequest  func(*RequestInfo) error
 	customResponse func(*ResponseInfo) error
 }
@@ -28,9 +32,12 @@ type versionChangeBuilder struct {
 // NewVersionChangeBuilder creates a new type-based version change builder
 func NewVersionChangeBuilder(fromVersion, toVersion *Version) *versionChangeBuilder {
 	return &versionChangeBuilder{
-		fromVersion: fromVersion,
-		toVersion:   toVersion,
-		typeOps:     make(map[reflect.Type]*typeBuilder),
+		fromVersion:  fromVersion,
+		toVersion:    toVersion,
+		typeOps:      make(map[reflect.Type]*typeBuilder),
+		envelopeOps:  make(map[string][]ResponseEnvelopeOperation),
+		queryOps:     make(map[string][]RequestQueryOperation),
+		pathParamOps: make(map[string][]*RequestMapPathParam),
 	}
 }
 
@@ -68,6 +75,30 @@ func (b *versionChangeBuilder) ForType(types ...interface{}) *typeBuilder {
 	return tb
 }
 
+// ForEndpoint starts building envelope and query operations for an endpoint, identified by the
+// method and path pattern it was registered with (e.g. "GET", "/users/:id")
+func (b *versionChangeBuilder) ForEndpoint(method, pathPattern string) *endpointBuilder {
+	return &endpointBuilder{parent: b, method: method, pathPattern: pathPattern}
+}
+
+// addEnvelopeOperation records an envelope operation for an endpoint
+func (b *versionChangeBuilder) addEnvelopeOperation(method, pathPattern string, op ResponseEnvelopeOperation) {
+	key := endpointKey(method, pathPattern)
+	b.envelopeOps[key] = append(b.envelopeOps[key], op)
+}
+
+// addQueryOperation records a query parameter operation for an endpoint
+func (b *versionChangeBuilder) addQueryOperation(method, pathPattern string, op RequestQueryOperation) {
+	key := endpointKey(method, pathPattern)
+	b.queryOps[key] = append(b.queryOps[key], op)
+}
+
+// addPathParamOperation records a path parameter mapping for an endpoint
+func (b *versionChangeBuilder) addPathParamOperation(method, pathPattern string, op *RequestMapPathParam) {
+	key := endpointKey(method, pathPattern)
+	b.pathParamOps[key] = append(b.pathParamOps[key], op)
+}
+
 // CustomRequest adds a global custom request transformer
 func (b *versionChangeBuilder) CustomRequest(fn func(*RequestInfo) error) *versionChangeBuilder {
 	b.customRequest = fn
@@ -86,117 +117,18 @@ func (b *versionChangeBuilder) Build() *VersionChange {
 		b.description = "Migration from " + b.fromVersion.String() + " to " + b.toVersion.String()
 	}
 
-	// Validate: require at least one type or custom transformer
-	if len(b.typeOps) == 0 && b.customRequest == nil && b.customResponse == nil {
-		panic("epoch: VersionChange must specify at least one type using ForType() or custom transformers")
+	// Validate: require at least one type, endpoint or custom transformer
+	if len(b.typeOps) == 0 && len(b.typeMatchers) == 0 && len(b.envelopeOps) == 0 && len(b.queryOps) == 0 && len(b.pathParamOps) == 0 && b.customRequest == nil && b.customResponse == nil {
+		panic("epoch: VersionChange must specify at least one type using ForType(), endpoint using ForEndpoint() or custom transformers")
 	}
 
 	var instructions []interface{}
 
 	// Compile type-based operations into instructions
 	for _, tb := range b.typeOps {
-		if len(tb.requestToNextVersionOps) == 0 &&
-			len(tb.responseToPreviousVersionOps) == 0 {
-			continue
-		}
-
-		// CRITICAL: Create local copies to avoid closure variable capture bug
-		// Without this, all closures would reference the same (last) tb
-		tbCopy := tb
-
-		// Get field mappings for error transformation
-		fieldMappings := make(map[string]string)
-
-		// Combine field mappings from both operation types
-		for k, v := range tbCopy.requestToNextVersionOps.GetFieldMappings() {
-			fieldMappings[k] = v
-		}
-		for k, v := range tbCopy.responseToPreviousVersionOps.GetFieldMappings() {
-			fieldMappings[k] = v
-		}
-
-		// Create request instruction for each type
-		for _, targetType := range tbCopy.targetTypes {
-			// CRITICAL: Create local copy for closure
-			targetTypeCopy := targetType
-			requestOpsCopy := tbCopy.requestToNextVersionOps
-
-			requestInst := &AlterRequestInstruction{
-				Schemas: []interface{}{reflect.New(targetTypeCopy).Interface()},
-				Transformer: func(req *RequestInfo) error {
-					if req.Body == nil {
-						return nil
-					}
-
-					// Before applying RemoveField operations, capture the field values
-					for _, op := range requestOpsCopy {
-						if removeOp, ok := op.(*RequestRemoveField); ok {
-							fieldNode := req.Body.Get(removeOp.Name)
-							if fieldNode != nil && fieldNode.Exists() {
-								// Capture the field value before removal
-								value, err := fieldNode.Interface()
-								if err == nil && req.GinContext != nil {
-									SetCapturedField(req.GinContext, removeOp.Name, value)
-								}
-							}
-						}
-					}
-
-					// Request migration is always FROM client version TO HEAD version
-					// Apply "to next version" operations (Client→HEAD)
-					return requestOpsCopy.Apply(req.Body)
-				},
-			}
-			instructions = append(instructions, requestInst)
-
-			// Create response instruction
-			responseOpsCopy := tbCopy.responseToPreviousVersionOps
-			fieldMappingsCopy := make(map[string]string)
-			for k, v := range fieldMappings {
-				fieldMappingsCopy[k] = v
-			}
-
-			responseInst := &AlterResponseInstruction{
-				Schemas:           []interface{}{reflect.New(targetTypeCopy).Interface()},
-				MigrateHTTPErrors: true,
-				Transformer: func(resp *ResponseInfo) error {
-					if resp.Body != nil {
-						// Handle arrays and objects separately
-						if resp.Body.TypeSafe() == ast.V_ARRAY {
-							// For arrays, apply operations to each item
-							if err := resp.TransformArrayField("", func(node *ast.Node) error {
-								// Pre-populate captured values before AddField operations
-								restoreCapturedFieldsToNode(resp.GinContext, targetTypeCopy, responseOpsCopy, node)
-
-								// Response migration is always FROM HEAD version TO client version
-								// Apply "to previous version" operations (HEAD→Client)
-								return responseOpsCopy.Apply(node)
-							}); err != nil {
-								return err
-							}
-						} else {
-							// Pre-populate captured values before AddField operations
-							restoreCapturedFieldsToNode(resp.GinContext, targetTypeCopy, responseOpsCopy, resp.Body)
-
-							// For objects, apply operations to the object
-							// Response migration is always FROM HEAD version TO client version
-							if err := responseOpsCopy.Apply(resp.Body); err != nil {
-								return err
-							}
-							// Note: Nested arrays are now handled by VersionChange.MigrateResponse
-							// using type-aware transformNestedArrayItemsForSingleStep at each migration step
-						}
-					}
-
-					// Additionally transform field names in error messages for validation errors
-					if len(fieldMappingsCopy) > 0 {
-						return transformErrorFieldNamesInResponse(resp, fieldMappingsCopy)
-					}
-
-					return nil
-				},
-			}
-			instructions = append(instructions, responseInst)
+		for _, targetType := range tb.targetTypes {
+			instructions = append(instructions,
+				compileTypeInstructions(targetType, tb.requestToNextVersionOps, tb.responseToPreviousVersionOps)...)
 		}
 	}
 
@@ -233,13 +165,137 @@ func (b *versionChangeBuilder) Build() *VersionChange {
 		}
 	}
 
+	for _, tb := range b.typeMatchers {
+		if len(tb.requestToNextVersionOps) > 0 || len(tb.responseToPreviousVersionOps) > 0 {
+			vc.typeMatchers = append(vc.typeMatchers, typeMatcher{
+				match:       tb.match,
+				requestOps:  tb.requestToNextVersionOps,
+				responseOps: tb.responseToPreviousVersionOps,
+			})
+		}
+	}
+
+	for key, ops := range b.envelopeOps {
+		vc.responseEnvelopeOps[key] = ops
+	}
+	for key, ops := range b.queryOps {
+		vc.requestQueryOps[key] = ops
+	}
+	for key, ops := range b.pathParamOps {
+		vc.requestPathParamOps[key] = ops
+	}
+
 	return vc
 }
 
+// compileTypeInstructions compiles a type's operations into its request and response instructions
+func compileTypeInstructions(
+	targetType reflect.Type,
+	requestOps RequestToNextVersionOperationList,
+	responseOps ResponseToPreviousVersionOperationList,
+) []interface{} {
+	if len(requestOps) == 0 && len(responseOps) == 0 {
+		return nil
+	}
+
+	var instructions []interface{}
+
+	// Get field mappings for error transformation
+	fieldMappings := make(map[string]string)
+
+	// Combine field mappings from both operation types
+	for k, v := range requestOps.GetFieldMappings() {
+		fieldMappings[k] = v
+	}
+	for k, v := range responseOps.GetFieldMappings() {
+		fieldMappings[k] = v
+	}
+
+	requestInst := &AlterRequestInstruction{
+		Schemas: []interface{}{reflect.New(targetType).Interface()},
+		Transformer: func(req *RequestInfo) error {
+			if req.Body == nil {
+				return nil
+			}
+
+			// Before applying RemoveField operations, capture the field values
+			for _, op := range requestOps {
+				if removeOp, ok := op.(*RequestRemoveField); ok {
+					fieldNode := req.Body.Get(removeOp.Name)
+					if fieldNode != nil && fieldNode.Exists() {
+						// Capture the field value before removal, numbers as json.Number so they restore exactly
+						value, err := fieldNode.InterfaceUseNumber()
+						if err == nil && req.GinContext != nil {
+							SetCapturedField(req.GinContext, removeOp.Name, value)
+						}
+					}
+				}
+			}
+
+			// Request migration is always FROM client version TO HEAD version
+			// Apply "to next version" operations (Client→HEAD)
+			return requestOps.ApplyToInfo(req)
+		},
+	}
+	// Types without request operations get no request instruction, so the chain
+	// can tell that requests of this type never need migrating
+	if len(requestOps) > 0 {
+		instructions = append(instructions, requestInst)
+	}
+
+	responseInst := &AlterResponseInstruction{
+		Schemas:           []interface{}{reflect.New(targetType).Interface()},
+		MigrateHTTPErrors: true,
+		Transformer: func(resp *ResponseInfo) error {
+			if resp.Body != nil {
+				// Handle arrays and objects separately
+				if resp.Body.TypeSafe() == jsonast.V_ARRAY {
+					// For arrays, apply operations to each item
+					if err := resp.TransformArrayField("", func(node *jsonast.Node) error {
+						// Pre-populate captured values before AddField operations
+						restoreCapturedFieldsToNode(resp.GinContext, targetType, responseOps, node)
+
+						// Response migration is always FROM HEAD version TO client version
+						// Apply "to previous version" operations (HEAD→Client)
+						return responseOps.ApplyToInfo(resp.withBody(node))
+					}); err != nil {
+						return err
+					}
+				} else {
+					// Pre-populate captured values before AddField operations
+					restoreCapturedFieldsToNode(resp.GinContext, targetType, responseOps, resp.Body)
+
+					// For objects, apply operations to the object
+					// Response migration is always FROM HEAD version TO client version
+					if err := responseOps.ApplyToInfo(resp); err != nil {
+						return err
+					}
+					// Note: Nested arrays are now handled by VersionChange.MigrateResponse
+					// using type-aware transformNestedArrayItemsForSingleStep at each migration step
+				}
+			}
+
+			// Additionally transform field names in error messages for validation errors
+			if len(fieldMappings) > 0 {
+				return transformErrorFieldNamesInResponse(resp, fieldMappings)
+			}
+
+			return nil
+		},
+	}
+	// Likewise for responses, unless error field names still need translating
+	if len(responseOps) > 0 || len(fieldMappings) > 0 {
+		instructions = append(instructions, responseInst)
+	}
+
+	return instructions
+}
+
 // typeBuilder builds operations for specific types
 type typeBuilder struct {
 	parent                       *versionChangeBuilder
 	targetTypes                  []reflect.Type
+	match                        func(reflect.Type) bool // Set by ForTypesMatching instead of targetTypes
 	requestToNextVersionOps      RequestToNextVersionOperationList
 	responseToPreviousVersionOps ResponseToPreviousVersionOperationList
 }
@@ -261,6 +317,11 @@ func (tb *typeBuilder) ForType(types ...interface{}) *typeBuilder {
 	return tb.parent.ForType(types...)
 }
 
+// ForEndpoint allows chaining to endpoint envelope operations
+func (tb *typeBuilder) ForEndpoint(method, pathPattern string) *endpointBuilder {
+	return tb.parent.ForEndpoint(method, pathPattern)
+}
+
 // Build is a convenience method that calls the parent's Build()
 func (tb *typeBuilder) Build() *VersionChange {
 	return tb.parent.Build()
@@ -271,12 +332,17 @@ type requestToNextVersionBuilder struct {
 }
 
 // AddField adds a field when request migrates from client to HEAD
-func (b *requestToNextVersionBuilder) AddField(name string, defaultValue interface{}) *requestToNextVersionBuilder {
-	b.parent.requestToNextVersionOps = append(b.parent.requestToNextVersionOps,
-		&RequestAddField{
-			Name:    name,
-			Default: defaultValue,
-		})
+// By default the field satisfies HEAD's validation; pass FailValidation to leave it missing
+// so binding:"required" rejects requests of clients that omit it (see FieldValidation)
+func (b *requestToNextVersionBuilder) AddField(name string, defaultValue interface{}, validation ...FieldValidation) *requestToNextVersionBuilder {
+	op := &RequestAddField{
+		Name:    name,
+		Default: defaultValue,
+	}
+	if len(validation) > 0 {
+		op.Validation = validation[0]
+	}
+	b.parent.requestToNextVersionOps = append(b.parent.requestToNextVersionOps, op)
 	return b
 }
 
@@ -309,16 +375,65 @@ func (b *requestToNextVersionBuilder) RenameField(olderVersionName, newerVersion
 	return b
 }
 
+// MoveField moves a field between nesting levels when request migrates from client to HEAD
+// Paths use dot notation, e.g. MoveField("theme", "settings.theme"); missing objects are created
+func (b *requestToNextVersionBuilder) MoveField(olderVersionPath, newerVersionPath string) *requestToNextVersionBuilder {
+	b.parent.requestToNextVersionOps = append(b.parent.requestToNextVersionOps,
+		&RequestMoveField{
+			OlderVersionPath: olderVersionPath,
+			NewerVersionPath: newerVersionPath,
+		})
+	return b
+}
+
+// ObjectToArray converts a keyed object field into an array when request migrates from client to HEAD
+// Each object key is stored in the item's keyField, e.g. {"home": {...}} → [{"type": "home", ...}]
+func (b *requestToNextVersionBuilder) ObjectToArray(name, keyField string) *requestToNextVersionBuilder {
+	b.parent.requestToNextVersionOps = append(b.parent.requestToNextVersionOps,
+		&RequestObjectToArray{
+			Name:     name,
+			KeyField: keyField,
+		})
+	return b
+}
+
+// ArrayToObject converts an array field into an object keyed by each item's keyField
+// when request migrates from client to HEAD, e.g. [{"type": "home", ...}] → {"home": {...}}
+func (b *requestToNextVersionBuilder) ArrayToObject(name, keyField string) *requestToNextVersionBuilder {
+	b.parent.requestToNextVersionOps = append(b.parent.requestToNextVersionOps,
+		&RequestArrayToObject{
+			Name:     name,
+			KeyField: keyField,
+		})
+	return b
+}
+
+// CollapseReference replaces an embedded object with its id when request migrates from client to HEAD,
+// e.g. CollapseReference("organization", "id"): {"organization": {"id": 7}} → {"organization_id": 7}
+func (b *requestToNextVersionBuilder) CollapseReference(name, idField string) *requestToNextVersionBuilder {
+	b.parent.requestToNextVersionOps = append(b.parent.requestToNextVersionOps,
+		&RequestCollapseReference{
+			Name:    name,
+			IDField: idField,
+		})
+	return b
+}
+
 // Custom applies a custom transformation function to the request
 func (b *requestToNextVersionBuilder) Custom(fn func(*RequestInfo) error) *requestToNextVersionBuilder {
-	// Wrap RequestInfo function to work with ast.Node
+	// InfoFn receives the live RequestInfo so the Gin and migration contexts are available
 	b.parent.requestToNextVersionOps = append(b.parent.requestToNextVersionOps,
 		&RequestCustom{
-			Fn: func(node *ast.Node) error {
-				// Create a temporary RequestInfo wrapper
-				req := &RequestInfo{Body: node}
-				return fn(req)
-			},
+			InfoFn: fn,
+		})
+	return b
+}
+
+// CustomOperation applies a reusable plugin Operation to the request
+func (b *requestToNextVersionBuilder) CustomOperation(op Operation) *requestToNextVersionBuilder {
+	b.parent.requestToNextVersionOps = append(b.parent.requestToNextVersionOps,
+		&RequestOperation{
+			Op: op,
 		})
 	return b
 }
@@ -333,6 +448,11 @@ func (b *requestToNextVersionBuilder) ForType(types ...interface{}) *typeBuilder
 	return b.parent.ForType(types...)
 }
 
+// ForEndpoint allows chaining to endpoint envelope operations
+func (b *requestToNextVersionBuilder) ForEndpoint(method, pathPattern string) *endpointBuilder {
+	return b.parent.ForEndpoint(method, pathPattern)
+}
+
 // Build completes the builder chain
 func (b *requestToNextVersionBuilder) Build() *VersionChange {
 	return b.parent.Build()
@@ -381,16 +501,129 @@ func (b *responseToPreviousVersionBuilder) RenameField(newerVersionName, olderVe
 	return b
 }
 
+// AddHeader sets a response header when response migrates from HEAD to client
+// A header the handler already set is kept
+func (b *responseToPreviousVersionBuilder) AddHeader(name, value string) *responseToPreviousVersionBuilder {
+	b.parent.responseToPreviousVersionOps = append(b.parent.responseToPreviousVersionOps,
+		&ResponseAddHeader{
+			Name:  name,
+			Value: value,
+		})
+	return b
+}
+
+// AddHeaderFromField sets a response header from a body field (dotted path) when response
+// migrates from HEAD to client, e.g. AddHeaderFromField("X-Total-Count", "meta.total")
+func (b *responseToPreviousVersionBuilder) AddHeaderFromField(name, fieldPath string) *responseToPreviousVersionBuilder {
+	b.parent.responseToPreviousVersionOps = append(b.parent.responseToPreviousVersionOps,
+		&ResponseAddHeaderFromField{
+			Name:  name,
+			Field: fieldPath,
+		})
+	return b
+}
+
+// RemoveHeader removes a response header when response migrates from HEAD to client
+func (b *responseToPreviousVersionBuilder) RemoveHeader(name string) *responseToPreviousVersionBuilder {
+	b.parent.responseToPreviousVersionOps = append(b.parent.responseToPreviousVersionOps,
+		&ResponseRemoveHeader{
+			Name: name,
+		})
+	return b
+}
+
+// RenameHeader renames a response header when response migrates from HEAD to client
+func (b *responseToPreviousVersionBuilder) RenameHeader(newerVersionName, olderVersionName string) *responseToPreviousVersionBuilder {
+	b.parent.responseToPreviousVersionOps = append(b.parent.responseToPreviousVersionOps,
+		&ResponseRenameHeader{
+			NewerVersionName: newerVersionName,
+			OlderVersionName: olderVersionName,
+		})
+	return b
+}
+
+// ExpandReference replaces an id field with the object it references when response migrates
+// from HEAD to client, e.g. "organization_id": 7 → "organization": {...}. fetch is called once
+// per response with all ids collected during the migration.
+func (b *responseToPreviousVersionBuilder) ExpandReference(idField string, fetch ReferenceFetcher) *responseToPreviousVersionBuilder {
+	b.parent.responseToPreviousVersionOps = append(b.parent.responseToPreviousVersionOps,
+		&ResponseExpandReference{
+			IDField: idField,
+			Name:    ReferenceName(idField),
+			Fetch:   fetch,
+		})
+	return b
+}
+
+// RenameLinkRel renames a relation in the _links object when response migrates from HEAD to client
+func (b *responseToPreviousVersionBuilder) RenameLinkRel(newerVersionRel, olderVersionRel string) *responseToPreviousVersionBuilder {
+	b.parent.responseToPreviousVersionOps = append(b.parent.responseToPreviousVersionOps,
+		&ResponseRenameLinkRel{
+			NewerVersionRel: newerVersionRel,
+			OlderVersionRel: olderVersionRel,
+		})
+	return b
+}
+
+// RewriteLinkHref rewrites the href of a _links relation (or AllLinkRels) when response migrates
+// from HEAD to client, e.g. to map HEAD's /accounts/{id} links back to /users/{id}
+func (b *responseToPreviousVersionBuilder) RewriteLinkHref(rel string, rewrite func(href string) string) *responseToPreviousVersionBuilder {
+	b.parent.responseToPreviousVersionOps = append(b.parent.responseToPreviousVersionOps,
+		&ResponseRewriteLinkHref{
+			Rel:     rel,
+			Rewrite: rewrite,
+		})
+	return b
+}
+
+// MoveField moves a field between nesting levels when response migrates from HEAD to client
+// Paths use dot notation, e.g. MoveField("settings.theme", "theme"); objects left empty are removed
+func (b *responseToPreviousVersionBuilder) MoveField(newerVersionPath, olderVersionPath string) *responseToPreviousVersionBuilder {
+	b.parent.responseToPreviousVersionOps = append(b.parent.responseToPreviousVersionOps,
+		&ResponseMoveField{
+			NewerVersionPath: newerVersionPath,
+			OlderVersionPath: olderVersionPath,
+		})
+	return b
+}
+
+// ArrayToObject converts an array field into an object keyed by each item's keyField
+// when response migrates from HEAD to client, e.g. [{"type": "home", ...}] → {"home": {...}}
+func (b *responseToPreviousVersionBuilder) ArrayToObject(name, keyField string) *responseToPreviousVersionBuilder {
+	b.parent.responseToPreviousVersionOps = append(b.parent.responseToPreviousVersionOps,
+		&ResponseArrayToObject{
+			Name:     name,
+			KeyField: keyField,
+		})
+	return b
+}
+
+// ObjectToArray converts a keyed object field into an array when response migrates from HEAD to client
+// Each object key is stored in the item's keyField, e.g. {"home": {...}} → [{"type": "home", ...}]
+func (b *responseToPreviousVersionBuilder) ObjectToArray(name, keyField string) *responseToPreviousVersionBuilder {
+	b.parent.responseToPreviousVersionOps = append(b.parent.responseToPreviousVersionOps,
+		&ResponseObjectToArray{
+			Name:     name,
+			KeyField: keyField,
+		})
+	return b
+}
+
 // Custom applies a custom transformation function to the response
 func (b *responseToPreviousVersionBuilder) Custom(fn func(*ResponseInfo) error) *responseToPreviousVersionBuilder {
-	// Wrap ResponseInfo function to work with ast.Node
+	// InfoFn receives the live ResponseInfo so the Gin and migration contexts are available
 	b.parent.responseToPreviousVersionOps = append(b.parent.responseToPreviousVersionOps,
 		&ResponseCustom{
-			Fn: func(node *ast.Node) error {
-				// Create a temporary ResponseInfo wrapper
-				resp := &ResponseInfo{Body: node}
-				return fn(resp)
-			},
+			InfoFn: fn,
+		})
+	return b
+}
+
+// CustomOperation applies a reusable plugin Operation to the response
+func (b *responseToPreviousVersionBuilder) CustomOperation(op Operation) *responseToPreviousVersionBuilder {
+	b.parent.responseToPreviousVersionOps = append(b.parent.responseToPreviousVersionOps,
+		&ResponseOperation{
+			Op: op,
 		})
 	return b
 }
@@ -405,6 +638,11 @@ func (b *responseToPreviousVersionBuilder) ForType(types ...interface{}) *typeBu
 	return b.parent.ForType(types...)
 }
 
+// ForEndpoint allows chaining to endpoint envelope operations
+func (b *responseToPreviousVersionBuilder) ForEndpoint(method, pathPattern string) *endpointBuilder {
+	return b.parent.ForEndpoint(method, pathPattern)
+}
+
 // Build completes the builder chain
 func (b *responseToPreviousVersionBuilder) Build() *VersionChange {
 	return b.parent.Build()
@@ -418,7 +656,7 @@ func restoreCapturedFieldsToNode(
 	ctx *gin.Context,
 	targetType reflect.Type,
 	responseOps ResponseToPreviousVersionOperationList,
-	node *ast.Node,
+	node *jsonast.Node,
 ) {
 	if ctx == nil || node == nil {
 		return
@@ -446,8 +684,8 @@ func restoreCapturedFieldsToNode(
 // transformErrorFieldNamesInResponse transforms field names in error messages
 // Works with any error response format by recursively processing all string fields
 func transformErrorFieldNamesInResponse(resp *ResponseInfo, fieldMapping map[string]string) error {
-	// Only transform validation errors (400 Bad Request)
-	if resp.StatusCode != 400 || resp.Body == nil {
+	// Only transform validation errors (400 Bad Request), unless they're transformed structurally
+	if resp.StatusCode != 400 || resp.Body == nil || usesStructuredErrors(resp.GinContext) {
 		return nil
 	}
 
@@ -457,7 +695,7 @@ func transformErrorFieldNamesInResponse(resp *ResponseInfo, fieldMapping map[str
 
 // transformStringsInNode recursively transforms all string fields in an AST node
 // This works with any error format: {"error": "..."}, {"message": "..."}, RFC 7807, etc.
-func transformStringsInNode(node *ast.Node, fieldMapping map[string]string) error {
+func transformStringsInNode(node *jsonast.Node, fieldMapping map[string]string) error {
 	if node == nil || !node.Exists() {
 		return nil
 	}
@@ -465,7 +703,7 @@ func transformStringsInNode(node *ast.Node, fieldMapping map[string]string) erro
 	nodeType := node.TypeSafe()
 
 	switch nodeType {
-	case ast.V_OBJECT:
+	case jsonast.V_OBJECT:
 		// Get all keys and recursively transform each field
 		objMap, err := node.Map()
 		if err != nil {
@@ -480,25 +718,25 @@ func transformStringsInNode(node *ast.Node, fieldMapping map[string]string) erro
 
 			fieldType := fieldNode.TypeSafe()
 			switch fieldType {
-			case ast.V_STRING:
+			case jsonast.V_STRING:
 				// Transform string value
 				strVal, _ := fieldNode.String()
 				transformed := replaceFieldNamesInErrorString(strVal, fieldMapping)
 				node.SetAny(key, transformed)
 
-			case ast.V_ARRAY:
+			case jsonast.V_ARRAY:
 				// Check if array contains strings that need transformation
 				if err := transformStringsInArrayField(node, key, fieldNode, fieldMapping); err != nil {
 					return err
 				}
 
-			case ast.V_OBJECT:
+			case jsonast.V_OBJECT:
 				// Recursively process nested objects
 				transformStringsInNode(fieldNode, fieldMapping)
 			}
 		}
 
-	case ast.V_ARRAY:
+	case jsonast.V_ARRAY:
 		// For arrays not in an object (root arrays), transform each element
 		length, err := node.Len()
 		if err != nil {
@@ -507,7 +745,7 @@ func transformStringsInNode(node *ast.Node, fieldMapping map[string]string) erro
 
 		for i := 0; i < length; i++ {
 			item := node.Index(i)
-			if item != nil && item.Exists() && item.TypeSafe() == ast.V_OBJECT {
+			if item != nil && item.Exists() && item.TypeSafe() == jsonast.V_OBJECT {
 				transformStringsInNode(item, fieldMapping)
 			}
 		}
@@ -517,7 +755,7 @@ func transformStringsInNode(node *ast.Node, fieldMapping map[string]string) erro
 }
 
 // transformStringsInArrayField handles transformation of array fields that may contain strings
-func transformStringsInArrayField(parentNode *ast.Node, key string, arrayNode *ast.Node, fieldMapping map[string]string) error {
+func transformStringsInArrayField(parentNode *jsonast.Node, key string, arrayNode *jsonast.Node, fieldMapping map[string]string) error {
 	length, err := arrayNode.Len()
 	if err != nil {
 		return err
@@ -534,22 +772,22 @@ func transformStringsInArrayField(parentNode *ast.Node, key string, arrayNode *a
 		}
 
 		itemType := item.TypeSafe()
-		if itemType == ast.V_STRING {
+		if itemType == jsonast.V_STRING {
 			strVal, _ := item.String()
 			transformed := replaceFieldNamesInErrorString(strVal, fieldMapping)
 			newArray[i] = transformed
 			if transformed != strVal {
 				needsTransform = true
 			}
-		} else if itemType == ast.V_OBJECT {
+		} else if itemType == jsonast.V_OBJECT {
 			// Recursively transform objects in arrays
 			transformStringsInNode(item, fieldMapping)
 			// Keep the original object node
-			val, _ := item.Interface()
+			val, _ := item.InterfaceUseNumber()
 			newArray[i] = val
 		} else {
 			// Keep other types as-is
-			val, _ := item.Interface()
+			val, _ := item.InterfaceUseNumber()
 			newArray[i] = val
 		}
 	}
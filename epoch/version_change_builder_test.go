@@ -4,8 +4,7 @@ import (
 	"net/http/httptest"
 	"reflect"
 
-	"github.com/bytedance/sonic"
-	"github.com/bytedance/sonic/ast"
+	"github.com/astronomer/epoch/epoch/jsonast"
 	"github.com/gin-gonic/gin"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -111,7 +110,7 @@ var _ = Describe("SchemaVersionChangeBuilder", func() {
 	})
 
 	Describe("Direction-Specific Operations", func() {
-		var testNode *ast.Node
+		var testNode *jsonast.Node
 
 		BeforeEach(func() {
 			jsonData := `{
@@ -122,7 +121,7 @@ var _ = Describe("SchemaVersionChangeBuilder", func() {
 				"phone": "+1-555-0100",
 				"status": "active"
 			}`
-			node, err := sonic.Get([]byte(jsonData))
+			node, err := getNode([]byte(jsonData))
 			Expect(err).NotTo(HaveOccurred())
 			err = node.Load()
 			Expect(err).NotTo(HaveOccurred())
@@ -187,6 +186,33 @@ var _ = Describe("SchemaVersionChangeBuilder", func() {
 			legacyNode := testNode.Get("legacy_field")
 			Expect(legacyNode.Exists()).To(BeTrue())
 		})
+		It("should move fields between nesting levels in both directions", func() {
+			migration := NewVersionChangeBuilder(v1, v2).
+				ForType(BuilderTestUser{}).
+				RequestToNextVersion().
+				MoveField("phone", "contact.phone").
+				ResponseToPreviousVersion().
+				MoveField("contact.phone", "phone").
+				Build()
+
+			for _, instruction := range migration.instructionsToMigrateToPreviousVersion {
+				if reqInst, ok := instruction.(*AlterRequestInstruction); ok {
+					Expect(reqInst.Transformer(&RequestInfo{Body: testNode})).To(Succeed())
+				}
+			}
+			Expect(testNode.Get("phone").Exists()).To(BeFalse())
+			phone, err := GetNodeStringAtPath(testNode, "contact.phone")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(phone).To(Equal("+1-555-0100"))
+
+			for _, instruction := range migration.instructionsToMigrateToPreviousVersion {
+				if respInst, ok := instruction.(*AlterResponseInstruction); ok {
+					Expect(respInst.Transformer(&ResponseInfo{Body: testNode, StatusCode: 200})).To(Succeed())
+				}
+			}
+			Expect(testNode.Get("contact").Exists()).To(BeFalse())
+			Expect(testNode.Get("phone").Exists()).To(BeTrue())
+		})
 	})
 
 	Describe("Builder Fluency", func() {
@@ -322,7 +348,7 @@ var _ = Describe("SchemaVersionChangeBuilder", func() {
 		Describe("transformStringsInNode", func() {
 			It("should transform string fields in simple objects", func() {
 				jsonData := `{"error": "Field BetterNewName is required"}`
-				node, err := sonic.Get([]byte(jsonData))
+				node, err := getNode([]byte(jsonData))
 				Expect(err).NotTo(HaveOccurred())
 				err = node.Load()
 				Expect(err).NotTo(HaveOccurred())
@@ -347,7 +373,7 @@ var _ = Describe("SchemaVersionChangeBuilder", func() {
 						"details": "The BetterNewName field cannot be empty"
 					}
 				}`
-				node, err := sonic.Get([]byte(jsonData))
+				node, err := getNode([]byte(jsonData))
 				Expect(err).NotTo(HaveOccurred())
 				err = node.Load()
 				Expect(err).NotTo(HaveOccurred())
@@ -370,7 +396,7 @@ var _ = Describe("SchemaVersionChangeBuilder", func() {
 
 			It("should transform strings in arrays", func() {
 				jsonData := `{"fields": ["BetterNewName", "OtherField"]}`
-				node, err := sonic.Get([]byte(jsonData))
+				node, err := getNode([]byte(jsonData))
 				Expect(err).NotTo(HaveOccurred())
 				err = node.Load()
 				Expect(err).NotTo(HaveOccurred())
@@ -399,7 +425,7 @@ var _ = Describe("SchemaVersionChangeBuilder", func() {
 					"detail": "The field BetterNewName is required",
 					"instance": "/api/users"
 				}`
-				node, err := sonic.Get([]byte(jsonData))
+				node, err := getNode([]byte(jsonData))
 				Expect(err).NotTo(HaveOccurred())
 				err = node.Load()
 				Expect(err).NotTo(HaveOccurred())
@@ -424,7 +450,7 @@ var _ = Describe("SchemaVersionChangeBuilder", func() {
 					"timestamp": 1234567890,
 					"success": false
 				}`
-				node, err := sonic.Get([]byte(jsonData))
+				node, err := getNode([]byte(jsonData))
 				Expect(err).NotTo(HaveOccurred())
 				err = node.Load()
 				Expect(err).NotTo(HaveOccurred())
@@ -458,7 +484,7 @@ var _ = Describe("SchemaVersionChangeBuilder", func() {
 		Describe("transformErrorFieldNamesInResponse", func() {
 			It("should transform 400 error responses", func() {
 				jsonData := `{"error": "Field BetterNewName is required"}`
-				node, err := sonic.Get([]byte(jsonData))
+				node, err := getNode([]byte(jsonData))
 				Expect(err).NotTo(HaveOccurred())
 				err = node.Load()
 				Expect(err).NotTo(HaveOccurred())
@@ -482,7 +508,7 @@ var _ = Describe("SchemaVersionChangeBuilder", func() {
 
 			It("should not transform non-400 responses", func() {
 				jsonData := `{"error": "Field BetterNewName is required"}`
-				node, err := sonic.Get([]byte(jsonData))
+				node, err := getNode([]byte(jsonData))
 				Expect(err).NotTo(HaveOccurred())
 				err = node.Load()
 				Expect(err).NotTo(HaveOccurred())
@@ -521,7 +547,7 @@ var _ = Describe("SchemaVersionChangeBuilder", func() {
 
 			It("should handle empty field mapping", func() {
 				jsonData := `{"error": "Some error message"}`
-				node, err := sonic.Get([]byte(jsonData))
+				node, err := getNode([]byte(jsonData))
 				Expect(err).NotTo(HaveOccurred())
 				err = node.Load()
 				Expect(err).NotTo(HaveOccurred())
@@ -546,7 +572,7 @@ var _ = Describe("SchemaVersionChangeBuilder", func() {
 		Describe("transformArrayField", func() {
 			It("should transform string arrays", func() {
 				jsonData := `{"fields": ["BetterNewName", "OtherField", "Name"]}`
-				node, err := sonic.Get([]byte(jsonData))
+				node, err := getNode([]byte(jsonData))
 				Expect(err).NotTo(HaveOccurred())
 				err = node.Load()
 				Expect(err).NotTo(HaveOccurred())
@@ -568,7 +594,7 @@ var _ = Describe("SchemaVersionChangeBuilder", func() {
 
 			It("should handle mixed-type arrays", func() {
 				jsonData := `{"data": ["BetterNewName", 123, true, {"field": "value"}]}`
-				node, err := sonic.Get([]byte(jsonData))
+				node, err := getNode([]byte(jsonData))
 				Expect(err).NotTo(HaveOccurred())
 				err = node.Load()
 				Expect(err).NotTo(HaveOccurred())
@@ -591,7 +617,7 @@ var _ = Describe("SchemaVersionChangeBuilder", func() {
 
 			It("should handle empty arrays", func() {
 				jsonData := `{"fields": []}`
-				node, err := sonic.Get([]byte(jsonData))
+				node, err := getNode([]byte(jsonData))
 				Expect(err).NotTo(HaveOccurred())
 				err = node.Load()
 				Expect(err).NotTo(HaveOccurred())
@@ -612,7 +638,7 @@ var _ = Describe("SchemaVersionChangeBuilder", func() {
 
 			It("should recursively transform objects in arrays", func() {
 				jsonData := `{"items": [{"name": "BetterNewName"}, {"name": "OtherField"}]}`
-				node, err := sonic.Get([]byte(jsonData))
+				node, err := getNode([]byte(jsonData))
 				Expect(err).NotTo(HaveOccurred())
 				err = node.Load()
 				Expect(err).NotTo(HaveOccurred())
@@ -720,7 +746,7 @@ var _ = Describe("SchemaVersionChangeBuilder", func() {
 				}
 
 				// Create a response node without the email field
-				node, _ := sonic.Get([]byte(`{"id": 1, "name": "Test"}`))
+				node, _ := getNode([]byte(`{"id": 1, "name": "Test"}`))
 				_ = node.Load()
 
 				// Restore captured fields
@@ -744,7 +770,7 @@ var _ = Describe("SchemaVersionChangeBuilder", func() {
 				}
 
 				// Response already has email from handler
-				node, _ := sonic.Get([]byte(`{"id": 1, "email": "handler@example.com"}`))
+				node, _ := getNode([]byte(`{"id": 1, "email": "handler@example.com"}`))
 				_ = node.Load()
 
 				restoreCapturedFieldsToNode(ginContext, targetType, responseOps, &node)
@@ -760,7 +786,7 @@ var _ = Describe("SchemaVersionChangeBuilder", func() {
 					&ResponseAddField{Name: "email", Default: "default@example.com"},
 				}
 
-				node, _ := sonic.Get([]byte(`{"id": 1}`))
+				node, _ := getNode([]byte(`{"id": 1}`))
 				_ = node.Load()
 
 				// Should not panic with nil context
@@ -781,7 +807,7 @@ var _ = Describe("SchemaVersionChangeBuilder", func() {
 					&ResponseAddField{Name: "phone", Default: "000-000-0000"},
 				}
 
-				node, _ := sonic.Get([]byte(`{"id": 1}`))
+				node, _ := getNode([]byte(`{"id": 1}`))
 				_ = node.Load()
 
 				restoreCapturedFieldsToNode(ginContext, targetType, responseOps, &node)
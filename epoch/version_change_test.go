@@ -6,7 +6,6 @@ import (
 	"net/http/httptest"
 	"reflect"
 
-	"github.com/bytedance/sonic"
 	"github.com/gin-gonic/gin"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -346,6 +345,43 @@ var _ = Describe("VersionChange - Schema-Based", func() {
 			// TestUser would be registered via WrapHandler().ForType()
 		})
 	})
+	Describe("HasMigrationsForTypes", func() {
+		type MigratedType struct {
+			Name string `json:"name"`
+		}
+		type UntouchedType struct {
+			ID int `json:"id"`
+		}
+
+		It("should report only the types altered between two versions", func() {
+			change := NewVersionChangeBuilder(v1, v2).
+				ForType(MigratedType{}).
+				ResponseToPreviousVersion().
+				RemoveField("name").
+				Build()
+			chain, err := NewMigrationChain([]*VersionChange{change})
+			Expect(err).NotTo(HaveOccurred())
+
+			migrated := []reflect.Type{reflect.TypeOf(MigratedType{})}
+			untouched := []reflect.Type{reflect.TypeOf(UntouchedType{})}
+
+			Expect(chain.HasMigrationsForTypes(v2, v1, DirectionResponse, migrated)).To(BeTrue())
+			Expect(chain.HasMigrationsForTypes(v2, v1, DirectionResponse, untouched)).To(BeFalse())
+			Expect(chain.HasMigrationsForTypes(v1, v2, DirectionRequest, migrated)).To(BeFalse())
+			Expect(chain.HasMigrationsForTypes(v2, v2, DirectionResponse, migrated)).To(BeFalse())
+		})
+
+		It("should treat global instructions as affecting every type", func() {
+			change := NewVersionChange("Global", v1, v2, &AlterResponseInstruction{
+				Transformer: func(*ResponseInfo) error { return nil },
+			})
+			chain, err := NewMigrationChain([]*VersionChange{change})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(chain.HasMigrationsForTypes(v2, v1, DirectionResponse, nil)).To(BeTrue())
+			Expect(chain.HasMigrationsForTypes(v1, v2, DirectionRequest, nil)).To(BeFalse())
+		})
+	})
 })
 
 var _ = Describe("Nested Array Multi-Step Migrations", func() {
@@ -1302,7 +1338,7 @@ var _ = Describe("Nested Array Multi-Step Migrations", func() {
 
 // Helper functions
 func createTestRequestInfo(jsonStr string) *RequestInfo {
-	node, err := sonic.Get([]byte(jsonStr))
+	node, err := getNode([]byte(jsonStr))
 	Expect(err).NotTo(HaveOccurred())
 	err = node.Load()
 	Expect(err).NotTo(HaveOccurred())
@@ -1317,7 +1353,7 @@ func createTestRequestInfo(jsonStr string) *RequestInfo {
 }
 
 func createTestResponseInfo(jsonStr string, statusCode int) *ResponseInfo {
-	node, err := sonic.Get([]byte(jsonStr))
+	node, err := getNode([]byte(jsonStr))
 	Expect(err).NotTo(HaveOccurred())
 	err = node.Load()
 	Expect(err).NotTo(HaveOccurred())
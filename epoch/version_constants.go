@@ -0,0 +1,103 @@
+package epoch
+
+import (
+	"fmt"
+	"go/format"
+	"go/token"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var versionConstantPattern = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// VersionConstantName returns the Go identifier GenerateVersionConstants declares for version
+// e.g. 2024-06-01 → V2024_06_01, 1.2.0 → V1_2_0, head → Head
+func VersionConstantName(version *Version) string {
+	if version.IsHead {
+		return "Head"
+	}
+	suffix := strings.Trim(versionConstantPattern.ReplaceAllString(version.String(), "_"), "_")
+	if suffix == "" {
+		return ""
+	}
+	return "V" + strings.ToUpper(suffix[:1]) + suffix[1:]
+}
+
+// GenerateVersionConstants emits the Go source of package pkg declaring a typed constant per
+// version in the bundle, so migrations and handler branches reference compile-time-checked
+// identifiers (versions.V2024_06_01) rather than re-parsing version strings.
+func GenerateVersionConstants(bundle *VersionBundle, pkg string) ([]byte, error) {
+	if !token.IsIdentifier(pkg) {
+		return nil, fmt.Errorf("invalid package name %q", pkg)
+	}
+
+	versions := bundle.GetVersions()
+	names := make([]string, len(versions))
+	seen := map[string]string{"Head": "head"}
+	for i, version := range versions {
+		name := VersionConstantName(version)
+		if name == "" {
+			return nil, fmt.Errorf("version %q has no characters usable in a Go identifier", version.String())
+		}
+		if other, ok := seen[name]; ok {
+			return nil, fmt.Errorf("versions %q and %q both map to constant %s", other, version.String(), name)
+		}
+		seen[name] = version.String()
+		names[i] = name
+	}
+
+	var b strings.Builder
+	b.WriteString("// Code generated by epoch. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	b.WriteString("import \"github.com/astronomer/epoch/epoch\"\n\n")
+	b.WriteString("// Version is an API version registered with epoch\n")
+	b.WriteString("type Version string\n\n")
+	b.WriteString("// Registered API versions, oldest first\n")
+	b.WriteString("const (\n")
+	for i, version := range versions {
+		fmt.Fprintf(&b, "\t%s Version = %q\n", names[i], version.String())
+	}
+	b.WriteString("\tHead Version = \"head\"\n")
+	b.WriteString(")\n\n")
+	b.WriteString("// All lists the registered versions, oldest first, without head\n")
+	fmt.Fprintf(&b, "var All = []Version{%s}\n\n", strings.Join(names, ", "))
+	b.WriteString(`// String returns the version as clients send it
+func (v Version) String() string {
+	return string(v)
+}
+
+// Epoch returns v as an *epoch.Version, e.g. for epoch.NewVersionChangeBuilder
+func (v Version) Epoch() *epoch.Version {
+	if v == Head {
+		return epoch.NewHeadVersion()
+	}
+	version, _ := epoch.NewVersion(string(v))
+	return version
+}
+
+// Is reports whether version, e.g. epoch.GetVersionFromContext(c), is v
+func (v Version) Is(version *epoch.Version) bool {
+	return version != nil && version.String() == string(v)
+}
+`)
+
+	source, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to format version constants: %w", err)
+	}
+	return source, nil
+}
+
+// WriteVersionConstants generates the version constants of package pkg and writes them to path,
+// e.g. from a go:generate directive
+func WriteVersionConstants(bundle *VersionBundle, pkg, path string) error {
+	data, err := GenerateVersionConstants(bundle, pkg)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write version constants: %w", err)
+	}
+	return nil
+}
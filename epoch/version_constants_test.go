@@ -0,0 +1,63 @@
+package epoch
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Version constants", func() {
+	It("should name versions as Go identifiers", func() {
+		v1, _ := NewDateVersion("2024-06-01")
+		v2, _ := NewSemverVersion("1.2.0")
+		Expect(VersionConstantName(v1)).To(Equal("V2024_06_01"))
+		Expect(VersionConstantName(v2)).To(Equal("V1_2_0"))
+		Expect(VersionConstantName(NewStringVersion("beta"))).To(Equal("VBeta"))
+		Expect(VersionConstantName(NewHeadVersion())).To(Equal("Head"))
+	})
+
+	It("should generate a typed constant per version", func() {
+		bundle, err := NewVersionBundle([]*Version{
+			NewStringVersion("2024-01-01"), NewStringVersion("2024-06-01"),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		source, err := GenerateVersionConstants(bundle, "versions")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = parser.ParseFile(token.NewFileSet(), "versions.go", source, parser.AllErrors)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(string(source)).To(HavePrefix("// Code generated by epoch. DO NOT EDIT.\n\npackage versions\n"))
+		Expect(string(source)).To(ContainSubstring(`V2024_01_01 Version = "2024-01-01"`))
+		Expect(string(source)).To(ContainSubstring(`V2024_06_01 Version = "2024-06-01"`))
+		Expect(string(source)).To(ContainSubstring(`Head        Version = "head"`))
+		Expect(string(source)).To(ContainSubstring("var All = []Version{V2024_01_01, V2024_06_01}"))
+	})
+
+	It("should write the constants to a file", func() {
+		bundle, _ := NewVersionBundle([]*Version{NewStringVersion("2024-01-01")})
+		path := filepath.Join(GinkgoT().TempDir(), "versions.go")
+		Expect(WriteVersionConstants(bundle, "versions", path)).To(Succeed())
+
+		data, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring("V2024_01_01"))
+	})
+
+	It("should reject versions mapping to the same constant", func() {
+		bundle, _ := NewVersionBundle([]*Version{NewStringVersion("1.0-beta"), NewStringVersion("1.0.beta")})
+		_, err := GenerateVersionConstants(bundle, "versions")
+		Expect(err).To(MatchError(`versions "1.0-beta" and "1.0.beta" both map to constant V1_0_beta`))
+
+		bundle, _ = NewVersionBundle([]*Version{NewStringVersion("--")})
+		_, err = GenerateVersionConstants(bundle, "versions")
+		Expect(err).To(MatchError(ContainSubstring("no characters usable in a Go identifier")))
+
+		_, err = GenerateVersionConstants(bundle, "my-versions")
+		Expect(err).To(MatchError(`invalid package name "my-versions"`))
+	})
+})
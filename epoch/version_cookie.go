@@ -0,0 +1,87 @@
+package epoch
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// DefaultVersionCookieName is the version cookie's name unless VersionCookie.Name says otherwise
+	DefaultVersionCookieName = "epoch_api_version"
+
+	// DefaultVersionCookieTTL is how long the version cookie lasts unless VersionCookie.TTL says otherwise
+	DefaultVersionCookieTTL = 30 * 24 * time.Hour
+)
+
+// VersionCookie configures version affinity by cookie (see WithVersionCookie)
+type VersionCookie struct {
+	Name   string        // Defaults to DefaultVersionCookieName
+	TTL    time.Duration // Defaults to DefaultVersionCookieTTL
+	Secure bool          // Only send the cookie over HTTPS
+	Path   string        // Defaults to "/"
+	Domain string        // Defaults to the request's host
+}
+
+// Cookie reads the version from a cookie. Like Path(), it only matches registered versions, so
+// a cookie naming a version that was since removed is ignored rather than rejected.
+func Cookie(name string) VersionSource {
+	return cookieVersionSource(name)
+}
+
+type cookieVersionSource string
+
+func (s cookieVersionSource) Version(c *gin.Context, known func(string) bool) string {
+	value, err := c.Cookie(string(s))
+	if err != nil || !known(value) {
+		return ""
+	}
+	return value
+}
+
+func (s cookieVersionSource) String() string {
+	return fmt.Sprintf("'%s' cookie", string(s))
+}
+
+// WithVersionCookie makes versions sticky for browser clients: once a request names a version,
+// the middleware sets a cookie with it, and later requests naming no version use the cookie's.
+// The cookie is checked after every other version source, so naming a version switches it.
+func (cb *EpochBuilder) WithVersionCookie(config VersionCookie) *EpochBuilder {
+	if config.TTL < 0 {
+		cb.errors = append(cb.errors, fmt.Errorf("version cookie TTL must not be negative, got %s", config.TTL))
+		return cb
+	}
+	if config.Name == "" {
+		config.Name = DefaultVersionCookieName
+	}
+	if config.TTL == 0 {
+		config.TTL = DefaultVersionCookieTTL
+	}
+	if config.Path == "" {
+		config.Path = "/"
+	}
+	cb.versionConfig.VersionCookie = &config
+	return cb
+}
+
+// setVersionCookie remembers the version a request named, unless the cookie already has it
+func (vm *VersionMiddleware) setVersionCookie(c *gin.Context, version *Version, defaultUsed bool) {
+	if vm.cookie == nil || defaultUsed {
+		return
+	}
+	if current, err := c.Cookie(vm.cookie.Name); err == nil && current == version.String() {
+		return
+	}
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     vm.cookie.Name,
+		Value:    version.String(),
+		Path:     vm.cookie.Path,
+		Domain:   vm.cookie.Domain,
+		MaxAge:   int(vm.cookie.TTL / time.Second),
+		Secure:   vm.cookie.Secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
@@ -0,0 +1,92 @@
+package epoch
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Version cookie", func() {
+	var (
+		v1, v2 *Version
+		router *gin.Engine
+	)
+
+	build := func(config VersionCookie) {
+		epochInstance, err := NewEpoch().WithVersions(v1, v2).WithHeadVersion().
+			WithDefaultVersion(v2).WithVersionCookie(config).Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		gin.SetMode(gin.TestMode)
+		router = gin.New()
+		router.Use(epochInstance.Middleware())
+		router.GET("/users", func(c *gin.Context) {
+			c.String(http.StatusOK, GetVersionFromContext(c).String())
+		})
+	}
+
+	send := func(header string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/users", nil)
+		if header != "" {
+			req.Header.Set("X-API-Version", header)
+		}
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	BeforeEach(func() {
+		v1, _ = NewDateVersion("2024-01-01")
+		v2, _ = NewDateVersion("2025-01-01")
+	})
+
+	It("should stick to the version a client negotiated", func() {
+		build(VersionCookie{Secure: true})
+
+		first := send("2024-01-01")
+		cookies := first.Result().Cookies()
+		Expect(cookies).To(HaveLen(1))
+		Expect(cookies[0].Name).To(Equal(DefaultVersionCookieName))
+		Expect(cookies[0].Value).To(Equal("2024-01-01"))
+		Expect(cookies[0].MaxAge).To(Equal(int(DefaultVersionCookieTTL / time.Second)))
+		Expect(cookies[0].Secure).To(BeTrue())
+		Expect(cookies[0].HttpOnly).To(BeTrue())
+
+		second := send("", cookies[0])
+		Expect(second.Body.String()).To(Equal("2024-01-01"))
+		Expect(second.Result().Cookies()).To(BeEmpty())
+	})
+
+	It("should switch versions when a request names another one", func() {
+		build(VersionCookie{Name: "api_version", TTL: time.Hour})
+
+		recorder := send("2025-01-01", &http.Cookie{Name: "api_version", Value: "2024-01-01"})
+		Expect(recorder.Body.String()).To(Equal("2025-01-01"))
+		cookies := recorder.Result().Cookies()
+		Expect(cookies).To(HaveLen(1))
+		Expect(cookies[0].Value).To(Equal("2025-01-01"))
+		Expect(cookies[0].MaxAge).To(Equal(3600))
+	})
+
+	It("should not set a cookie for the default version and ignore unknown versions", func() {
+		build(VersionCookie{})
+
+		Expect(send("").Result().Cookies()).To(BeEmpty())
+
+		recorder := send("", &http.Cookie{Name: DefaultVersionCookieName, Value: "1999-01-01"})
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Body.String()).To(Equal("2025-01-01"))
+	})
+
+	It("should reject a negative TTL", func() {
+		_, err := NewEpoch().WithVersions(v1).WithVersionCookie(VersionCookie{TTL: -time.Second}).Build()
+		Expect(err).To(MatchError(ContainSubstring("version cookie TTL must not be negative")))
+	})
+})
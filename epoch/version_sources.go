@@ -0,0 +1,101 @@
+package epoch
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VersionSource is a place requests can name their version in (see WithVersionSources)
+type VersionSource interface {
+	// Version returns the version the request names here, or "" when it names none. known
+	// reports whether a value names a registered version, for sources that can't otherwise
+	// tell versions apart from other values, like path segments.
+	Version(c *gin.Context, known func(string) bool) string
+
+	// String describes the source for error hints, e.g. "'X-API-Version' header"
+	String() string
+}
+
+// Header reads the version from a request header
+func Header(name string) VersionSource {
+	return headerVersionSource(name)
+}
+
+// Query reads the version from a query parameter, e.g. Query("api-version") for ?api-version=2024-01-01
+func Query(name string) VersionSource {
+	return queryVersionSource(name)
+}
+
+// Path reads the version from a path segment naming a registered version, like /v1/ or /2024-01-01/
+func Path() VersionSource {
+	return pathVersionSource{}
+}
+
+type headerVersionSource string
+
+func (s headerVersionSource) Version(c *gin.Context, _ func(string) bool) string {
+	return c.GetHeader(string(s))
+}
+
+func (s headerVersionSource) String() string {
+	return fmt.Sprintf("'%s' header", string(s))
+}
+
+type queryVersionSource string
+
+func (s queryVersionSource) Version(c *gin.Context, _ func(string) bool) string {
+	if c.Request == nil || c.Request.URL == nil {
+		return ""
+	}
+	return c.Request.URL.Query().Get(string(s))
+}
+
+func (s queryVersionSource) String() string {
+	return fmt.Sprintf("'%s' query parameter", string(s))
+}
+
+// pathVersionPattern matches version-like path segments: /v1/, /v1.0/, /v1.0.0/, /1/, /1.0/,
+// /1.0.0/, /2024-01-01/, /v1.0-beta/, etc. Only segments naming a known version count.
+var pathVersionPattern = regexp.MustCompile(`/([vV]?\d+(?:[\.\-]\w+)*)/`)
+
+type pathVersionSource struct{}
+
+func (pathVersionSource) Version(c *gin.Context, known func(string) bool) string {
+	if c.Request == nil || c.Request.URL == nil {
+		return ""
+	}
+	matches := pathVersionPattern.FindStringSubmatch(c.Request.URL.Path)
+	if len(matches) > 1 && known(matches[1]) {
+		return matches[1]
+	}
+	return ""
+}
+
+func (pathVersionSource) String() string {
+	return "URL path (e.g., /v1/resource)"
+}
+
+// WithVersionSources sets where requests name their version, in order of precedence: the first
+// source a request names a version in wins. The default is Header(<version parameter>), Path().
+// Legacy integrations sending ?api-version= can be served next to header-based clients:
+//
+//	WithVersionSources(epoch.Header("X-API-Version"), epoch.Query("api-version"), epoch.Path())
+func (cb *EpochBuilder) WithVersionSources(sources ...VersionSource) *EpochBuilder {
+	cb.versionConfig.VersionSources = append([]VersionSource(nil), sources...)
+	return cb
+}
+
+// describeVersionSources lists sources for error hints: "a, b or c"
+func describeVersionSources(sources []VersionSource) string {
+	descriptions := make([]string, len(sources))
+	for i, source := range sources {
+		descriptions[i] = "the " + source.String()
+	}
+	if len(descriptions) == 1 {
+		return descriptions[0]
+	}
+	return strings.Join(descriptions[:len(descriptions)-1], ", ") + " or " + descriptions[len(descriptions)-1]
+}
@@ -0,0 +1,73 @@
+package epoch
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Version sources", func() {
+	var router *gin.Engine
+
+	build := func(builder *EpochBuilder) {
+		v1, _ := NewDateVersion("2024-01-01")
+		v2, _ := NewDateVersion("2025-01-01")
+		epochInstance, err := builder.WithVersions(v1, v2).WithHeadVersion().
+			WithDefaultVersion(v2).Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		gin.SetMode(gin.TestMode)
+		router = gin.New()
+		router.Use(epochInstance.Middleware())
+		handler := func(c *gin.Context) {
+			c.String(http.StatusOK, GetVersionFromContext(c).String())
+		}
+		router.GET("/users", handler)
+		router.GET("/2024-01-01/users", handler)
+	}
+
+	send := func(target string, header string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", target, nil)
+		if header != "" {
+			req.Header.Set("X-API-Version", header)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	It("should read the version from a query parameter", func() {
+		build(NewEpoch().WithVersionSources(Header("X-API-Version"), Query("api-version"), Path()))
+
+		Expect(send("/users?api-version=2024-01-01", "").Body.String()).To(Equal("2024-01-01"))
+		Expect(send("/users?api-version=2024-01-01", "head").Body.String()).To(Equal("head"))
+		Expect(send("/2024-01-01/users", "").Body.String()).To(Equal("2024-01-01"))
+		Expect(send("/users", "").Body.String()).To(Equal("2025-01-01"))
+	})
+
+	It("should check sources in the order given", func() {
+		build(NewEpoch().WithVersionSources(Query("api-version"), Header("X-API-Version")))
+
+		Expect(send("/users?api-version=2024-01-01", "head").Body.String()).To(Equal("2024-01-01"))
+		Expect(send("/2024-01-01/users", "").Body.String()).To(Equal("2025-01-01"))
+	})
+
+	It("should ignore the query string by default", func() {
+		build(NewEpoch())
+
+		Expect(send("/users?api-version=2024-01-01", "").Body.String()).To(Equal("2025-01-01"))
+		Expect(send("/2024-01-01/users", "").Body.String()).To(Equal("2024-01-01"))
+	})
+
+	It("should name the configured sources in the unknown version hint", func() {
+		build(NewEpoch().WithVersionSources(Header("X-API-Version"), Query("api-version")))
+
+		recorder := send("/users?api-version=bogus", "")
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		Expect(recorder.Body.String()).To(ContainSubstring(
+			`Specify version using the 'X-API-Version' header or the 'api-version' query parameter`))
+	})
+})
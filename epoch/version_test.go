@@ -37,6 +37,27 @@ var _ = Describe("Version", func() {
 		})
 	})
 
+	Describe("NewDateVersionFromTime", func() {
+		It("should use the calendar date in the time's location", func() {
+			newYork, err := time.LoadLocation("America/New_York")
+			Expect(err).NotTo(HaveOccurred())
+			evening := time.Date(2024, time.June, 1, 23, 30, 0, 0, newYork)
+
+			version := NewDateVersionFromTime(evening)
+			Expect(version.Raw).To(Equal("2024-06-01"))
+			Expect(version.Type).To(Equal(VersionTypeDate))
+			Expect(NewDateVersionFromTime(evening.UTC()).Raw).To(Equal("2024-06-02"))
+		})
+
+		It("should equal the version parsed from the same date", func() {
+			parsed, err := NewDateVersion("2024-06-01")
+			Expect(err).NotTo(HaveOccurred())
+			version := NewDateVersionFromTime(time.Date(2024, time.June, 1, 8, 0, 0, 0, time.FixedZone("CEST", 2*3600)))
+			Expect(version.Equal(parsed)).To(BeTrue())
+			Expect(version.Date.Equal(*parsed.Date)).To(BeTrue())
+		})
+	})
+
 	Describe("NewSemverVersion", func() {
 		Context("with valid semver strings", func() {
 			It("should create a semantic version with major.minor.patch", func() {